@@ -7,7 +7,6 @@ import (
 	"os"
 	"path/filepath"
 
-	cfg "github.com/divijg19/rig/internal/config"
 	core "github.com/divijg19/rig/internal/rig"
 	"github.com/spf13/cobra"
 )
@@ -41,16 +40,9 @@ var buildCmd = &cobra.Command{
 		}
 
 		// Apply profile if specified and exists
-		var prof cfg.BuildProfile
-		if buildProfile != "" {
-			if conf.Profiles == nil {
-				return fmt.Errorf("profile %q requested, but no [profile.*] defined in %s", buildProfile, path)
-			}
-			p, ok := conf.Profiles[buildProfile]
-			if !ok {
-				return fmt.Errorf("profile %q not found in %s", buildProfile, path)
-			}
-			prof = p
+		prof, err := core.LookupProfile(conf, path, buildProfile)
+		if err != nil {
+			return err
 		}
 
 		// Determine effective output and ensure output directory exists
@@ -71,20 +63,21 @@ var buildCmd = &cobra.Command{
 			Gcflags: buildGcflags,
 		})
 		// Ensure local .rig/bin is preferred on PATH
-		env = envWithLocalBin(path, env, false)
+		env = envWithLocalBin(path, append(core.GoCacheEnv(conf, path), env...), false)
 
 		if buildDryRun {
-			fmt.Printf("🧪 Dry run: would execute -> %s\n", cmdline)
+			newStyledWriter(os.Stdout).linef(ansiYellow, "🧪 Dry run: would execute -> %s", cmdline)
 			return nil
 		}
 
-		fmt.Printf("🔨 Building (profile=%q) using config %s\n", buildProfile, path)
+		newStyledWriter(os.Stdout).linef(ansiBoldCyan, "🔨 Building (profile=%q) using config %s", buildProfile, path)
 		return core.ExecuteShell(cmdline, core.ExecOptions{Dir: buildDir, Env: env})
 	},
 }
 
 func init() {
 	buildCmd.Flags().StringVar(&buildProfile, "profile", "", "build profile from rig.toml [profile.<name>]")
+	_ = buildCmd.RegisterFlagCompletionFunc("profile", completeProfileNames)
 	buildCmd.Flags().StringVarP(&buildOutput, "output", "o", "", "output binary path")
 	buildCmd.Flags().StringSliceVarP(&buildTags, "tags", "t", nil, "comma-separated build tags")
 	buildCmd.Flags().StringVar(&buildLdflags, "ldflags", "", "custom -ldflags (overrides profile)")
@@ -0,0 +1,161 @@
+// internal/cli/bundle.go
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bundleOutput   string
+	bundlePlatform string
+	bundleRigBin   string
+
+	bundleInstallDir   string
+	bundleInstallRig   string
+	bundleInstallForce bool
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Pack rig, the locked tools, and rig.toml/rig.lock into one archive",
+	Long: `Write a single .tar.gz holding the rig binary, every tool in rig.lock built
+for one platform, rig.toml, and rig.lock, for machines without internet
+access. bundle.json in the archive records the sha256 of every file, and
+'rig bundle install' checks them before writing anything.
+
+For the current platform the tools come from .rig/bin and must match
+rig.lock. With --platform, go tools are cross-compiled at their locked
+versions, OCI tools are pulled for that platform, and rig is downloaded
+from the matching release (or taken from --rig-binary).`,
+	Args: cobra.NoArgs,
+	Example: `
+  rig bundle
+  rig bundle --platform linux/arm64 -o app-linux-arm64.tar.gz
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, configPath, err := loadConfigOrFail()
+		if err != nil {
+			return err
+		}
+		goos, goarch := runtime.GOOS, runtime.GOARCH
+		if p := strings.TrimSpace(bundlePlatform); p != "" {
+			var ok bool
+			if goos, goarch, ok = strings.Cut(p, "/"); !ok || goos == "" || goarch == "" {
+				return fmt.Errorf("--platform %q: expected <os>/<arch>, e.g. linux/amd64", p)
+			}
+		}
+		cmd.SilenceUsage = true
+
+		rigBin, err := bundleRigBinary(goos, goarch)
+		if err != nil {
+			return err
+		}
+		m, err := core.BuildBundle(configPath, core.BundleOptions{GOOS: goos, GOARCH: goarch, RigVersion: version, RigBinary: rigBin})
+		if err != nil {
+			return err
+		}
+		out := strings.TrimSpace(bundleOutput)
+		if out == "" {
+			out = fmt.Sprintf("rig-bundle-%s_%s.tar.gz", goos, goarch)
+		}
+		if err := core.WriteBundle(out, m); err != nil {
+			return err
+		}
+		tools := 0
+		for _, f := range m.Files {
+			if f.Kind == core.BundleTool {
+				tools++
+			}
+		}
+		newStyledWriter(os.Stdout).linef(ansiGreen, "✅ wrote %s (%s/%s, rig %s, %d tool(s))", out, goos, goarch, version, tools)
+		return nil
+	},
+}
+
+// bundleRigBinary returns the rig executable to bundle for goos/goarch: this
+// one for the current platform, else --rig-binary or the release of this
+// version for that platform.
+func bundleRigBinary(goos, goarch string) (string, error) {
+	if p := strings.TrimSpace(bundleRigBin); p != "" {
+		return p, nil
+	}
+	if goos == runtime.GOOS && goarch == runtime.GOARCH {
+		return os.Executable()
+	}
+	if !core.IsReleaseVersion(version) {
+		return "", fmt.Errorf("rig %s is not a release, so no rig binary for %s/%s can be downloaded; pass --rig-binary", version, goos, goarch)
+	}
+	cacheDir, err := core.RigVersionCacheDir()
+	if err != nil {
+		return "", err
+	}
+	target, err := core.EnsureRigVersion(core.LaunchOptions{
+		Constraint: "=" + strings.TrimPrefix(version, "v"),
+		CacheDir:   filepath.Join(cacheDir, goos+"_"+goarch),
+		GOOS:       goos,
+		GOARCH:     goarch,
+	})
+	if err != nil {
+		return "", fmt.Errorf("download rig %s for %s/%s: %w", version, goos, goarch, err)
+	}
+	return target.Path, nil
+}
+
+var bundleInstallCmd = &cobra.Command{
+	Use:   "install <bundle>",
+	Short: "Unpack a rig bundle on this machine",
+	Long: `Check every file of a bundle (a .tar.gz or an unpacked bundle directory)
+against its bundle.json, then write rig.toml and rig.lock to --dir, the
+tools to its tool bin directory (.rig/bin), and rig to --rig-path (default:
+the tool bin directory).
+
+On a machine without rig, unpack the archive and run the rig inside it:
+  tar xzf rig-bundle-linux_amd64.tar.gz
+  ./rig-bundle-linux_amd64/rig bundle install rig-bundle-linux_amd64`,
+	Args: cobra.ExactArgs(1),
+	Example: `
+  rig bundle install rig-bundle-linux_amd64.tar.gz
+  rig bundle install rig-bundle-linux_amd64.tar.gz --dir ~/src/app --rig-path ~/.local/bin/rig
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		m, err := core.ReadBundle(args[0])
+		if err != nil {
+			return err
+		}
+		dir := strings.TrimSpace(bundleInstallDir)
+		if dir == "" {
+			return errors.New("--dir must not be empty")
+		}
+		rigPath, err := core.InstallBundle(m, core.BundleInstallOptions{Dir: dir, RigPath: strings.TrimSpace(bundleInstallRig), Force: bundleInstallForce})
+		if err != nil {
+			return err
+		}
+		out := newStyledWriter(os.Stdout)
+		for _, f := range m.Files {
+			out.linef(ansiDim, "  %s  %s", f.SHA256[:12], f.Path)
+		}
+		out.linef(ansiGreen, "✅ installed bundle into %s (rig %s at %s)", dir, m.RigVersion, rigPath)
+		return nil
+	},
+}
+
+func init() {
+	bundleCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "archive to write (default: rig-bundle-<os>_<arch>.tar.gz)")
+	bundleCmd.Flags().StringVar(&bundlePlatform, "platform", "", "target <os>/<arch> (default: this machine)")
+	bundleCmd.Flags().StringVar(&bundleRigBin, "rig-binary", "", "rig executable to bundle instead of this one or a downloaded release")
+	bundleInstallCmd.Flags().StringVar(&bundleInstallDir, "dir", ".", "project directory for rig.toml and rig.lock")
+	bundleInstallCmd.Flags().StringVar(&bundleInstallRig, "rig-path", "", "where to write the rig binary (default: the tool bin directory)")
+	bundleInstallCmd.Flags().BoolVar(&bundleInstallForce, "force", false, "overwrite an existing rig.toml or rig.lock")
+	bundleCmd.AddCommand(bundleInstallCmd)
+	rootCmd.AddCommand(bundleCmd)
+}
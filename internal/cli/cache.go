@@ -0,0 +1,112 @@
+// internal/cli/cache.go
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheCleanGo   bool
+	cacheStatsGo   bool
+	cacheStatsScan bool
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clean build caches",
+	Long:  "Inspect and clean the caches used by commands rig launches. Set [cache].go in rig.toml to point GOCACHE at a project location (e.g. .rig/cache/go) that CI can save and restore.",
+	Args:  cobra.NoArgs,
+	Example: `
+  rig cache stats --go
+  rig cache --clean-go-cache
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cacheCleanGo {
+			return cmd.Help()
+		}
+		conf, path, err := loadConfigOrFail()
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(path)
+		env := core.GoCacheEnv(conf, path)
+		gocache, err := core.EffectiveGoCache(dir, env)
+		if err != nil {
+			return err
+		}
+		before, _ := core.MeasureGoCache(gocache)
+		if err := core.CleanGoCache(dir, env); err != nil {
+			return err
+		}
+		after, _ := core.MeasureGoCache(gocache)
+		fmt.Printf("cleaned Go build cache %s (freed %s)\n", gocache, core.FormatBytes(before.Bytes-after.Bytes))
+		return nil
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache location, size, and hit/miss data",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cacheStatsGo {
+			return errors.New("usage: rig cache stats --go")
+		}
+		conf, path, err := loadConfigOrFail()
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(path)
+		env := core.GoCacheEnv(conf, path)
+		gocache, err := core.EffectiveGoCache(dir, env)
+		if err != nil {
+			return err
+		}
+		usage, err := core.MeasureGoCache(gocache)
+		if err != nil {
+			return fmt.Errorf("measure %s: %w", gocache, err)
+		}
+
+		source := "go env"
+		if env != nil {
+			source = "[cache].go"
+		}
+		fmt.Printf("Go build cache: %s (from %s)\n", gocache, source)
+		fmt.Printf("  size:     %s in %d file(s)\n", core.FormatBytes(usage.Bytes), usage.Files)
+		if !cacheStatsScan {
+			return nil
+		}
+
+		cmd.SilenceUsage = true
+		st, err := core.AnalyzeGoBuildCache(dir, env, "./...")
+		if err != nil {
+			return err
+		}
+		rate := 0.0
+		if st.Packages > 0 {
+			rate = 100 * float64(st.Hits()) / float64(st.Packages)
+		}
+		stdout := newStyledWriter(os.Stdout)
+		color := ansiGreen
+		if len(st.Compiled) > 0 {
+			color = ansiYellow
+		}
+		stdout.linef(color, "  build:    %d package(s): %d cached, %d compiled (%.0f%% hit rate)", st.Packages, st.Hits(), len(st.Compiled), rate)
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.Flags().BoolVar(&cacheCleanGo, "clean-go-cache", false, "remove everything in the Go build cache (go clean -cache)")
+	cacheStatsCmd.Flags().BoolVar(&cacheStatsGo, "go", false, "report on the Go build cache")
+	cacheStatsCmd.Flags().BoolVar(&cacheStatsScan, "build", true, "build ./... with -x to measure cache hits and misses")
+	cacheCmd.AddCommand(cacheStatsCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
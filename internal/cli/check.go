@@ -3,19 +3,85 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	core "github.com/divijg19/rig/internal/rig"
 	"github.com/spf13/cobra"
 )
 
+var (
+	checkQuiet bool
+	checkFix   bool
+	checkYes   bool
+	checkFmt   string
+	checkPorc  string
+)
+
 var checkCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Verify rig.lock and installed tools",
-	Args:  cobra.NoArgs,
+	Long: `Verify rig.lock and installed tools.
+
+With --quiet nothing is printed; the exit code is 0 when in sync, 1 when out
+of sync, and 2 on error.
+
+With --fix, tools that are missing from .rig/bin or do not match rig.lock are
+reinstalled at their locked versions after printing a plan. rig.toml and
+rig.lock must agree; otherwise run 'rig sync'.
+
+--format json (the default) prints stable JSON; --format table prints the
+aligned tool table of 'rig tools check', and --format gha prints GitHub Actions
+::error/::warning annotations.`,
+	Args: cobra.NoArgs,
+	Example: `
+  rig check
+  rig check --quiet && echo in sync
+  rig check --fix --yes
+  rig check --format gha
+`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if checkFix && checkQuiet {
+			return errors.New("--fix cannot be combined with --quiet")
+		}
+		format, err := parseCheckFormat(checkFmt, false)
+		if err != nil {
+			return err
+		}
+		usePorcelain, err := porcelainEnabled(cmd, checkPorc, "quiet", "format")
+		if err != nil {
+			return err
+		}
 		rep, err := core.Check("")
-		if b, mErr := rep.MarshalJSONStable(); mErr == nil {
-			fmt.Println(string(b))
+		if checkQuiet {
+			return quietExit(cmd, rep.OK, err)
+		}
+		if checkFix && err == nil && !rep.OK {
+			fixed, ferr := fixCheckedTools(rep, checkYes)
+			if ferr != nil {
+				return ferr
+			}
+			if fixed {
+				rep, err = core.Check("")
+			}
+		}
+		switch {
+		case usePorcelain:
+			printCheckPorcelain(os.Stdout, rep)
+		case format == formatTable:
+			printCheckTable(newStyledWriter(os.Stdout), rep)
+		case format == formatGHA:
+			printCheckAnnotations(rep)
+		default:
+			if b, mErr := rep.MarshalJSONStable(); mErr == nil {
+				fmt.Println(string(b))
+			}
+		}
+		if format != formatGHA && !usePorcelain {
+			printStaleBins(newStyledWriter(os.Stderr), rep.StaleBins)
+			printGoModToolDrifts(newStyledWriter(os.Stderr), rep.GoModTools)
 		}
 		if err != nil {
 			return err
@@ -27,6 +93,231 @@ var checkCmd = &cobra.Command{
 	},
 }
 
+// fixCheckedTools reinstalls the tools rep reports as missing, mismatched, or
+// stale at their rig.lock versions and refreshes their checksums. Progress goes
+// to stderr so stdout stays JSON.
+func fixCheckedTools(rep core.CheckReport, yes bool) (bool, error) {
+	if rep.Error != "" {
+		return false, fmt.Errorf("refusing to fix: %s (run 'rig sync' to reconcile rig.toml and rig.lock)", rep.Error)
+	}
+	lock, err := core.ReadLockfile(rep.LockPath)
+	if err != nil {
+		return false, err
+	}
+	byName := map[string]int{}
+	for i, lt := range lock.Tools {
+		if name, _, perr := core.ParseRequested(lt.Requested); perr == nil {
+			byName[name] = i
+		}
+	}
+
+	stderr := newStyledWriter(os.Stderr)
+	var broken []core.LockedTool
+	stderr.linef(ansiBoldCyan, "🔧 Fix plan:")
+	for _, row := range rep.Tools {
+		if row.Status == string(core.ToolOK) {
+			continue
+		}
+		i, ok := byName[row.Name]
+		if !ok {
+			return false, fmt.Errorf("refusing to fix: %s is not in rig.lock (run 'rig sync')", row.Name)
+		}
+		reason := row.Status
+		if row.Have != "" {
+			reason += ", have " + row.Have
+		}
+		stderr.linef(ansiYellow, "  rebuild  %-20s %-12s (%s)", row.Name, row.Want, reason)
+		broken = append(broken, lock.Tools[i])
+	}
+	if len(broken) == 0 {
+		fmt.Fprintln(os.Stderr, "  nothing to reinstall; remaining issues are outside .rig/bin")
+		return false, nil
+	}
+	if !yes {
+		if !isTTY(os.Stdin) {
+			return false, fmt.Errorf("refusing to reinstall %d tool(s) without confirmation; pass --yes", len(broken))
+		}
+		if !confirmPrompt(os.Stderr, fmt.Sprintf("Reinstall %d tool(s) into .rig/bin?", len(broken))) {
+			fmt.Fprintln(os.Stderr, "Fix cancelled")
+			return false, nil
+		}
+	}
+
+	release, err := lockProject(rep.ConfigPath, "fixing tools")
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	env := envWithLocalBin(rep.ConfigPath, nil, true)
+	known := map[string]string{}
+	for _, lt := range lock.Tools {
+		known[lt.Resolved] = lt.Checksum
+	}
+	if err := core.VerifyToolSums(broken, known, filepath.Dir(rep.ConfigPath), env, false); err != nil {
+		return false, err
+	}
+	if err := installLockedTools(rep.ConfigPath, broken, env, stderr, nil); err != nil {
+		return false, err
+	}
+	for _, lt := range broken {
+		name, _, _ := core.ParseRequested(lt.Requested)
+		prev := &lock.Tools[byName[name]]
+		prev.SHA256, prev.Go = lt.SHA256, lt.Go
+		if strings.TrimSpace(lt.Checksum) != "" {
+			prev.Checksum = lt.Checksum
+		}
+	}
+	if err := core.WriteLockfile(rep.LockPath, lock); err != nil {
+		return false, fmt.Errorf("write rig.lock: %w", err)
+	}
+	stderr.linef(ansiGreen, "🔒 Fixed %d tool(s) (rig.lock: %s)", len(broken), rep.LockPath)
+	return true, nil
+}
+
+// checkTableRows is the tool table for a check report, go toolchain last.
+func checkTableRows(rep core.CheckReport) []toolTableRow {
+	rows := toolTableRows(rep.Tools)
+	if rep.Go != nil {
+		rows = append(rows, goTableRow(rep.Go))
+	}
+	return rows
+}
+
+// checkProblems lists the report's non-tool failures as one sentence each.
+func checkProblems(rep core.CheckReport) []string {
+	var out []string
+	if rep.Error != "" {
+		out = append(out, rep.Error)
+	}
+	if ws := rep.Workspace; ws != nil && !ws.InSync {
+		msg := "go.work out of sync with [workspace]"
+		if len(ws.Missing) > 0 {
+			msg += "; missing: " + strings.Join(ws.Missing, ", ")
+		}
+		if len(ws.Extra) > 0 {
+			msg += "; extra: " + strings.Join(ws.Extra, ", ")
+		}
+		out = append(out, msg)
+	}
+	for _, r := range rep.Requires {
+		if r.OK() {
+			continue
+		}
+		msg := fmt.Sprintf("requires %s %s: %s", r.Name, r.Constraint, r.Status)
+		if r.Version != "" {
+			msg += " (have " + r.Version + ")"
+		}
+		if r.Hint != "" {
+			msg += "; " + r.Hint
+		}
+		out = append(out, msg)
+	}
+	for _, u := range rep.Undeclared {
+		out = append(out, u.String())
+	}
+	return out
+}
+
+// printCheckTable prints a check report for people: the tool table, then
+// one line per other problem.
+func printCheckTable(out *styledWriter, rep core.CheckReport) {
+	printToolTable(out, checkTableRows(rep))
+	for _, msg := range checkProblems(rep) {
+		out.linef(ansiRed, "❌ %s", msg)
+	}
+	if rep.OK {
+		out.linef(ansiGreen, "✅ rig.lock and tools in sync")
+	}
+}
+
+// printCheckAnnotations prints a check report as GitHub Actions annotations;
+// stale .rig/bin files are warnings.
+func printCheckAnnotations(rep core.CheckReport) {
+	var warnings []string
+	for _, s := range rep.StaleBins {
+		warnings = append(warnings, fmt.Sprintf(".rig/bin/%s: %s (run 'rig sync --prune')", s.Bin, s.Reason))
+	}
+	for _, d := range rep.GoModTools {
+		warnings = append(warnings, fmt.Sprintf("go.mod tool %s: %s (run 'rig tools import')", d.Package, d.Reason))
+	}
+	printGHAnnotations(os.Stdout, "rig check", checkTableRows(rep), checkProblems(rep), warnings)
+}
+
+// printCheckPorcelain prints rep as porcelain v1 records:
+//
+//	check <ok|fail> <error>
+//	tool <bin> <want> <lock> <have> <status>
+//	go <requested> <locked> <have> <status>
+//	stale <bin> <reason>
+//	gomod-tool <tool> <package> <gomod> <tools>
+//	workspace <in-sync|out-of-sync>
+//	require <name> <constraint> <version> <status>
+//	undeclared <task> <tool>
+func printCheckPorcelain(w io.Writer, rep core.CheckReport) {
+	state := "fail"
+	if rep.OK {
+		state = "ok"
+	}
+	porcelainLine(w, "check", state, rep.Error)
+	for _, r := range toolTableRows(rep.Tools) {
+		porcelainLine(w, "tool", r.Name, r.Want, r.Lock, r.Have, r.Status)
+	}
+	if g := rep.Go; g != nil {
+		porcelainLine(w, "go", g.Requested, g.Locked, g.Have, g.Status)
+	}
+	for _, s := range rep.StaleBins {
+		porcelainLine(w, "stale", s.Bin, s.Reason)
+	}
+	for _, d := range rep.GoModTools {
+		porcelainLine(w, "gomod-tool", d.Tool, d.Package, d.GoMod, d.Tools)
+	}
+	if ws := rep.Workspace; ws != nil {
+		state := "in-sync"
+		if !ws.InSync {
+			state = "out-of-sync"
+		}
+		porcelainLine(w, "workspace", state)
+	}
+	for _, r := range rep.Requires {
+		porcelainLine(w, "require", r.Name, r.Constraint, r.Version, r.Status)
+	}
+	for _, u := range rep.Undeclared {
+		porcelainLine(w, "undeclared", u.Task, u.Tool)
+	}
+}
+
+// printStaleBins explains each stale or orphaned .rig/bin file on one line
+// and points at the command that fixes them all.
+func printStaleBins(out *styledWriter, stale []core.StaleBin) {
+	if len(stale) == 0 {
+		return
+	}
+	for _, s := range stale {
+		out.linef(ansiYellow, "⚠️  .rig/bin/%s: %s", s.Bin, s.Reason)
+	}
+	fmt.Fprintln(out.w, "run 'rig sync --prune' to reinstall from rig.lock and remove orphans")
+}
+
+// printGoModToolDrifts warns about go.mod tool directives [tools] does not
+// match and points at the command that imports them.
+func printGoModToolDrifts(out *styledWriter, drifts []core.GoModToolDrift) {
+	if len(drifts) == 0 {
+		return
+	}
+	for _, d := range drifts {
+		out.linef(ansiYellow, "⚠️  go.mod tool %s: %s", d.Package, d.Reason)
+	}
+	fmt.Fprintln(out.w, "run 'rig tools import' to copy go.mod tool directives into [tools]")
+}
+
 func init() {
+	checkCmd.Flags().BoolVarP(&checkQuiet, "quiet", "q", false, "print nothing; report through the exit code (0 ok, 1 out of sync, 2 error)")
+	checkCmd.Flags().BoolVar(&checkFix, "fix", false, "reinstall missing or mismatched tools at their rig.lock versions")
+	checkCmd.Flags().BoolVarP(&checkYes, "yes", "y", false, "do not ask for confirmation before fixing")
+	checkCmd.Flags().StringVar(&checkFmt, "format", formatJSON, "output format: json, table, or gha (GitHub Actions annotations)")
+	_ = checkCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{formatJSON, formatTable, formatGHA}, cobra.ShellCompDirectiveNoFileComp))
+	addPorcelainFlag(checkCmd, &checkPorc)
+	checkCmd.Flags().BoolVar(&projectLockWait, "wait", false, "with --fix, wait for another rig process that is syncing this project instead of failing")
 	rootCmd.AddCommand(checkCmd)
 }
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	core "github.com/divijg19/rig/internal/rig"
+)
+
+// Output formats shared by `rig check` and `rig tools check`.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatGHA   = "gha"
+)
+
+// parseCheckFormat validates --format; a set --json means json and only
+// conflicts with another explicit format.
+func parseCheckFormat(format string, jsonFlag bool) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case formatTable, formatJSON, formatGHA:
+	default:
+		return "", fmt.Errorf("invalid --format %q (expected table|json|gha)", format)
+	}
+	if jsonFlag {
+		return formatJSON, nil
+	}
+	return format, nil
+}
+
+// toolTableRow is one line of the tool status table: what rig.toml asks for,
+// what rig.lock pins, and what is installed.
+type toolTableRow struct {
+	Name, Want, Lock, Have, Status string
+}
+
+func toolTableRows(rows []core.ToolStatusRow) []toolTableRow {
+	out := make([]toolTableRow, 0, len(rows))
+	for _, r := range rows {
+		want := r.Requested
+		if want == "" {
+			want = r.Want
+		}
+		have := r.Have
+		switch r.Status {
+		case string(core.ToolOK):
+			if have == "" {
+				have = r.Want
+			}
+		case string(core.ToolMismatch):
+			if have == "" {
+				have = "≠ lock"
+			}
+		}
+		out = append(out, toolTableRow{Name: r.Bin, Want: want, Lock: r.Want, Have: have, Status: r.Status})
+	}
+	return out
+}
+
+func goTableRow(g *core.GoStatusRow) toolTableRow {
+	return toolTableRow{Name: "go", Want: g.Requested, Lock: g.Locked, Have: g.Have, Status: g.Status}
+}
+
+// printToolTable prints rows as aligned columns, each row colored by status.
+func printToolTable(out *styledWriter, rows []toolTableRow) {
+	if len(rows) == 0 {
+		return
+	}
+	cells := func(r toolTableRow) []string {
+		return []string{r.Name, orDash(r.Want), orDash(r.Lock), orDash(r.Have)}
+	}
+	widths := []int{len("TOOL"), len("WANT"), len("LOCK"), len("HAVE")}
+	for _, r := range rows {
+		for i, c := range cells(r) {
+			widths[i] = max(widths[i], len([]rune(c)))
+		}
+	}
+	line := func(c []string, status string) string {
+		var b strings.Builder
+		b.WriteString("  ")
+		for i, s := range c {
+			b.WriteString(s)
+			b.WriteString(strings.Repeat(" ", widths[i]-len([]rune(s))+2))
+		}
+		b.WriteString(status)
+		return b.String()
+	}
+	out.linef(ansiBoldCyan, "%s", line([]string{"TOOL", "WANT", "LOCK", "HAVE"}, "STATUS"))
+	for _, r := range rows {
+		color, mark, word := ansiRed, "❌ ", "FAIL"
+		switch r.Status {
+		case string(core.ToolOK):
+			color, mark, word = ansiGreen, "✅ ", "OK"
+		case string(core.ToolStale):
+			color, mark, word = ansiYellow, "⚠️  ", "WARN"
+		}
+		if core.Accessible {
+			mark = word + " "
+		}
+		out.linef(color, "%s", line(cells(r), mark+r.Status))
+	}
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
+
+// toolProblem describes a failing row in one sentence, or "" when it is ok.
+func toolProblem(r toolTableRow) string {
+	switch r.Status {
+	case string(core.ToolOK):
+		return ""
+	case string(core.ToolMissing):
+		return fmt.Sprintf("%s not found (want %s)", r.Name, orDash(r.Lock))
+	case string(core.ToolStale):
+		return fmt.Sprintf("%s %s built with %s; run 'rig tools sync' to rebuild", r.Name, orDash(r.Lock), r.Have)
+	default:
+		return fmt.Sprintf("%s version mismatch (have %s, lock %s)", r.Name, orDash(r.Have), orDash(r.Lock))
+	}
+}
+
+// printGHAnnotations prints GitHub Actions workflow commands: an error per
+// failing tool and per error, and a warning per warning.
+func printGHAnnotations(w io.Writer, title string, rows []toolTableRow, errs, warnings []string) {
+	for _, r := range rows {
+		if msg := toolProblem(r); msg != "" {
+			fmt.Fprintf(w, "::error title=%s::%s\n", ghaEscapeProperty(title), ghaEscape(msg))
+		}
+	}
+	for _, msg := range errs {
+		fmt.Fprintf(w, "::error title=%s::%s\n", ghaEscapeProperty(title), ghaEscape(msg))
+	}
+	for _, msg := range warnings {
+		fmt.Fprintf(w, "::warning title=%s::%s\n", ghaEscapeProperty(title), ghaEscape(msg))
+	}
+}
+
+// ghaEscape escapes a workflow command message.
+func ghaEscape(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// ghaEscapeProperty escapes a workflow command property such as title.
+func ghaEscapeProperty(s string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(ghaEscape(s))
+}
@@ -2,14 +2,20 @@ package cli
 
 import (
 	"bytes"
+	"compress/gzip"
+	stdjson "encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -113,6 +119,11 @@ func TestRootHelpOutputUnified(t *testing.T) {
 	if !strings.Contains(outDefault, "Run \"rig version\" for build information.") {
 		t.Fatalf("expected help footer note, got: %q", outDefault)
 	}
+	for _, name := range []string{"logs", "plan"} {
+		if !strings.Contains(outDefault, "\n  "+name+" ") {
+			t.Fatalf("expected %q under Available Commands, got: %q", name, outDefault)
+		}
+	}
 }
 
 func TestVersionOutputUnified(t *testing.T) {
@@ -266,6 +277,183 @@ noop = "true"
 	}
 }
 
+func TestQuietCheckAndStatusExitCodes(t *testing.T) {
+	exitCode := func(err error) int {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			return ee.ExitCode()
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return 0
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+mockery = "2.0.0"
+`, 0o644)
+	for _, name := range []string{"check", "status"} {
+		out, err := runRigCmdInDir(t, dir, name, "--quiet")
+		if code := exitCode(err); code != 1 {
+			t.Fatalf("%s --quiet without rig.lock: exit %d, want 1. output=%s", name, code, out)
+		}
+		if out != "" {
+			t.Fatalf("%s --quiet printed output: %q", name, out)
+		}
+	}
+
+	empty := t.TempDir()
+	for _, name := range []string{"check", "status"} {
+		out, err := runRigCmdInDir(t, empty, name, "-q")
+		if code := exitCode(err); code != 2 {
+			t.Fatalf("%s -q without rig.toml: exit %d, want 2. output=%s", name, code, out)
+		}
+		if out != "" {
+			t.Fatalf("%s -q printed output: %q", name, out)
+		}
+	}
+}
+
+func TestStatusAllSummarizesProjects(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "api", "rig.toml"), "[project]\nname = \"api\"\n", 0o644)
+	writeFile(t, filepath.Join(root, "api", "rig.lock"), "schema = 0\n", 0o644)
+	writeFile(t, filepath.Join(root, "web", "rig.toml"), "[tools]\nmockery = \"2.0.0\"\n", 0o644)
+
+	out, err := runRigCmdInDir(t, root, "status", "--all")
+	if err != nil {
+		t.Fatalf("status --all: %v\n%s", err, out)
+	}
+	for _, want := range []string{"api  lock fresh", "web  lock missing", "2 project(s): 1 in sync, 1 need attention"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+	out, err = runRigCmdInDir(t, t.TempDir(), "status", "--all", "--porcelain", root)
+	if err != nil {
+		t.Fatalf("status --all --porcelain: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "project\tapi\tok\tfresh\t0\t0\t\t\n") || !strings.Contains(out, "project\tweb\tout-of-sync\tmissing\t") {
+		t.Fatalf("unexpected porcelain output:\n%s", out)
+	}
+	if out, err := runRigCmdInDir(t, root, "status", "--all", "-q"); err == nil {
+		t.Fatalf("status --all -q should fail when a project is out of sync: %s", out)
+	}
+	if out, err := runRigCmdInDir(t, root, "status", "web"); err == nil {
+		t.Fatalf("status without --all takes no arguments: %s", out)
+	}
+}
+
+func TestCheckFixRefusals(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+mockery = "2.0.0"
+`, 0o644)
+	lock := `schema = 0
+
+[[tools]]
+kind = "go-binary"
+requested = "mockery@%s"
+resolved = "github.com/vektra/mockery/v2@v%s"
+module = "github.com/vektra/mockery/v2"
+bin = "mockery"
+sha256 = "deadbeef"
+`
+	writeFile(t, filepath.Join(dir, "rig.lock"), fmt.Sprintf(lock, "1.0.0", "1.0.0"), 0o644)
+	out, err := runRigCmdInDir(t, dir, "check", "--fix")
+	if err == nil || !strings.Contains(out, "refusing to fix") || !strings.Contains(out, "rig sync") {
+		t.Fatalf("expected refusal when rig.toml and rig.lock disagree, err=%v output=%s", err, out)
+	}
+
+	// Lock matches, binary missing: the plan is shown but nothing runs without --yes.
+	writeFile(t, filepath.Join(dir, "rig.lock"), fmt.Sprintf(lock, "2.0.0", "2.0.0"), 0o644)
+	out, err = runRigCmdInDir(t, dir, "check", "--fix")
+	if err == nil {
+		t.Fatalf("expected error without --yes, output=%s", out)
+	}
+	if !strings.Contains(out, "rebuild  mockery") {
+		t.Fatalf("expected fix plan, got: %s", out)
+	}
+	if !strings.Contains(out, "pass --yes") && !strings.Contains(out, "Fix cancelled") {
+		t.Fatalf("expected fix to stop without confirmation, got: %s", out)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, ".rig", "bin")); !os.IsNotExist(statErr) {
+		t.Fatalf("check --fix without confirmation must not install (stat err=%v)", statErr)
+	}
+}
+
+func TestSyncFromLockRequiresLock(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+mockery = "2.0.0"
+`, 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "sync", "--from-lock")
+	if err == nil {
+		t.Fatalf("expected error, got none. output=%s", out)
+	}
+	if !strings.Contains(out, "--from-lock needs an existing lock") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, ".rig")); !os.IsNotExist(statErr) {
+		t.Fatalf("--from-lock without rig.lock must not create .rig (stat err=%v)", statErr)
+	}
+}
+
+func TestToolsImportFromGoModToolDirectives(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), `module example.com/x
+
+go 1.24
+
+tool github.com/golangci/golangci-lint/cmd/golangci-lint
+
+tool golang.org/x/tools/cmd/stringer
+
+require (
+	github.com/golangci/golangci-lint v1.59.1 // indirect
+	golang.org/x/tools v0.30.0 // indirect
+)
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "rig.toml"), `[tools]
+golangci-lint = "1.58.0" # lint
+`, 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "sync", "--check")
+	if !strings.Contains(out, "go.mod tool golang.org/x/tools/cmd/stringer: in go.mod but not in [tools]") ||
+		!strings.Contains(out, "go.mod requires v1.59.1, [tools] has 1.58.0") {
+		t.Fatalf("sync should warn about go.mod tool drift (err=%v): %s", err, out)
+	}
+
+	out, _ = runRigCmdInDir(t, dir, "tools", "import")
+	if !strings.Contains(out, "pass --yes") && !strings.Contains(out, "Import cancelled") {
+		t.Fatalf("expected import to stop without confirmation, got: %s", out)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "rig.toml")); !strings.Contains(string(b), `"1.58.0"`) {
+		t.Fatalf("import without confirmation must not edit rig.toml:\n%s", b)
+	}
+	out, err = runRigCmdInDir(t, dir, "tools", "import", "--yes")
+	if err != nil {
+		t.Fatalf("tools import --yes: %v\n%s", err, out)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "rig.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[tools]\ngolangci-lint = \"v1.59.1\" # lint\n\"golang.org/x/tools/cmd/stringer\" = \"v0.30.0\"\n"
+	if string(b) != want {
+		t.Fatalf("rig.toml after import:\n%s\nwant:\n%s", b, want)
+	}
+	out, err = runRigCmdInDir(t, dir, "tools", "import")
+	if err != nil || !strings.Contains(out, "nothing to import") {
+		t.Fatalf("second import should be a no-op (err=%v): %s", err, out)
+	}
+}
+
 func TestCheckOKWithLockAndInstalledTools(t *testing.T) {
 	dir := t.TempDir()
 	writeFile(t, filepath.Join(dir, "rig.toml"), `
@@ -301,6 +489,154 @@ sha256 = %q
 	}
 }
 
+func TestToolsCheckFormats(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+mockery = "2.0.0"
+golangci-lint = "1.55.0"
+`, 0o644)
+	bin := filepath.Join(dir, ".rig", "bin", "mockery")
+	writeFile(t, bin, "#!/bin/sh\necho mockery v2.0.0\n", 0o755)
+	sha, err := core.ComputeFileSHA256(bin)
+	if err != nil {
+		t.Fatalf("sha256 mockery: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "rig.lock"), fmt.Sprintf(`schema = 0
+
+[[tools]]
+kind = "go-binary"
+requested = "mockery@2.0.0"
+resolved = "github.com/vektra/mockery/v2@v2.0.0"
+module = "github.com/vektra/mockery/v2"
+bin = "mockery"
+sha256 = %q
+
+[[tools]]
+kind = "go-binary"
+requested = "golangci-lint@1.55.0"
+resolved = "github.com/golangci/golangci-lint@v1.55.0"
+module = "github.com/golangci/golangci-lint"
+bin = "golangci-lint"
+sha256 = "abc"
+`, sha), 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "tools", "check")
+	if err == nil {
+		t.Fatalf("expected tools check to fail:\n%s", out)
+	}
+	for _, want := range []string{"TOOL           WANT    LOCK    HAVE   STATUS", "golangci-lint  1.55.0  1.55.0  -      ❌ missing", "mockery        2.0.0   2.0.0   2.0.0  ✅ ok"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in table:\n%s", want, out)
+		}
+	}
+
+	for _, args := range [][]string{{"tools", "check", "--format", "gha"}, {"check", "--format", "gha"}} {
+		out, _ := runRigCmdInDir(t, dir, args...)
+		if !strings.Contains(out, "::error title=rig "+strings.Join(args[:len(args)-2], " ")+"::golangci-lint not found (want 1.55.0)") || strings.Contains(out, "mockery") {
+			t.Fatalf("%v: expected one annotation for golangci-lint:\n%s", args, out)
+		}
+	}
+
+	out, _ = runRigCmdInDir(t, dir, "tools", "check", "--format", "json")
+	if !strings.Contains(out, `"requested": "1.55.0"`) || !strings.Contains(out, `"missing": 1`) {
+		t.Fatalf("expected JSON summary:\n%s", out)
+	}
+	if out, err := runRigCmdInDir(t, dir, "check", "--format", "xml"); err == nil || !strings.Contains(out, `invalid --format "xml"`) {
+		t.Fatalf("expected invalid format error, err=%v\n%s", err, out)
+	}
+}
+
+func TestPorcelainOutput(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+mockery = "2.0.0"
+
+[tasks]
+build = { command = "go build ./...", description = "Build\tall" }
+vet = "go vet ./..."
+`, 0o644)
+	bin := filepath.Join(dir, ".rig", "bin", "mockery")
+	writeFile(t, bin, "#!/bin/sh\necho mockery v2.0.0\n", 0o755)
+	sha, err := core.ComputeFileSHA256(bin)
+	if err != nil {
+		t.Fatalf("sha256 mockery: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "rig.lock"), fmt.Sprintf(`schema = 0
+
+[[tools]]
+kind = "go-binary"
+requested = "mockery@2.0.0"
+resolved = "github.com/vektra/mockery/v2@v2.0.0"
+module = "github.com/vektra/mockery/v2"
+bin = "mockery"
+sha256 = %q
+`, sha), 0o644)
+
+	for _, tc := range []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"run", "--list", "--porcelain"}, []string{"task\tbuild\tBuild\\tall", "task\tvet\t"}},
+		{[]string{"status", "--porcelain=v1"}, []string{"config\t" + filepath.Join(dir, "rig.toml"), "lock\t" + filepath.Join(dir, "rig.lock") + "\tpresent", "tools\tok\t0\t0\t0"}},
+		{[]string{"check", "--porcelain"}, []string{"check\tok\t", "tool\tmockery\t2.0.0\t2.0.0\t2.0.0\tok"}},
+		{[]string{"tools", "ls", "--porcelain"}, []string{"tool\tmockery\tmockery@2.0.0\tgithub.com/vektra/mockery/v2@v2.0.0\tok\t" + bin}},
+	} {
+		out, err := runRigCmdInDir(t, dir, tc.args...)
+		if err != nil {
+			t.Fatalf("%v failed: %v\n%s", tc.args, err, out)
+		}
+		lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+		if len(lines) != len(tc.want) {
+			t.Fatalf("%v: got %d lines, want %d:\n%s", tc.args, len(lines), len(tc.want), out)
+		}
+		for i, want := range tc.want {
+			if lines[i] != want {
+				t.Fatalf("%v line %d = %q, want %q", tc.args, i, lines[i], want)
+			}
+		}
+	}
+
+	if out, err := runRigCmdInDir(t, dir, "status", "--porcelain=v9"); err == nil || !strings.Contains(out, `unsupported --porcelain version "v9"`) {
+		t.Fatalf("expected unsupported version error, err=%v\n%s", err, out)
+	}
+	if out, err := runRigCmdInDir(t, dir, "tools", "ls", "--porcelain", "--json"); err == nil || !strings.Contains(out, "--porcelain cannot be combined with --json") {
+		t.Fatalf("expected conflict error, err=%v\n%s", err, out)
+	}
+}
+
+func TestGuardWarnsAboutDistantConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tasks]\nhi = \"true\"\n", 0o644)
+	writeRigLock(t, dir, nil)
+	deep := filepath.Join(dir, "a", "b", "c")
+	if err := os.MkdirAll(deep, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	userCfg := filepath.Join(t.TempDir(), "config.toml")
+	writeFile(t, userCfg, "[guard]\nmax_config_depth = 2\n", 0o644)
+	env := append(os.Environ(), "RIG_USER_CONFIG="+userCfg)
+
+	out, err := runRigCmdInDirWithEnv(t, deep, env, "run", "hi")
+	if err != nil {
+		t.Fatalf("rig run failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "3 directories above the current directory") {
+		t.Fatalf("expected distant config warning:\n%s", out)
+	}
+
+	out, err = runRigCmdInDirWithEnv(t, filepath.Join(dir, "a", "b"), env, "run", "hi")
+	if err != nil || strings.Contains(out, "directories above") {
+		t.Fatalf("depth 2 should not warn (err=%v):\n%s", err, out)
+	}
+
+	writeFile(t, userCfg, "[guard]\nmax_config_depth = 0\n", 0o644)
+	if out, err := runRigCmdInDirWithEnv(t, deep, env, "run", "hi"); err != nil || strings.Contains(out, "directories above") {
+		t.Fatalf("max_config_depth = 0 should disable the warning (err=%v):\n%s", err, out)
+	}
+}
+
 func TestRunRequiresLock(t *testing.T) {
 	dir := t.TempDir()
 	writeFile(t, filepath.Join(dir, "rig.toml"), `
@@ -363,653 +699,2117 @@ sha256 = %q
 	}
 }
 
-func TestEntrypointRirMatchesRigRunList(t *testing.T) {
-	work := t.TempDir()
-	writeFile(t, filepath.Join(work, "rig.toml"), `
+func TestRunDefaultArgsAndArgsPlaceholder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "append"), "#!/bin/sh\nprintf '%s\\n' \"$*\" >> out.txt\n", 0o755)
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
 [tasks]
-a = "true"
-b = "true"
+gen = { command = "./append gen", default_args = ["--all"] }
+t = { command = "./append test ${args} ./...", default_args = ["-run", "TestFoo"], depends_on = ["gen"] }
+label = "./append -label=${args} end"
 `, 0o644)
+	writeRigLock(t, dir, nil)
+
+	for _, args := range [][]string{
+		{"run", "t"},
+		{"run", "t", "--", "-v"},
+		{"run", "label", "--", "a", "b"},
+	} {
+		if out, err := runRigCmdInDir(t, dir, args...); err != nil {
+			t.Fatalf("rig %v failed: %v\n%s", args, err, out)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if err != nil {
+		t.Fatalf("read out.txt: %v", err)
+	}
+	want := "gen --all\ntest -run TestFoo ./...\ngen --all\ntest -v ./...\n-label=a b end"
+	if got := strings.TrimSpace(string(b)); got != want {
+		t.Fatalf("unexpected args:\n%s\nwant:\n%s", got, want)
+	}
 
-	rigBin := buildRigBinary(t, t.TempDir())
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tasks]\na = \"echo a\"\nall = { steps = [\"a\"], default_args = [\"-v\"] }\n", 0o644)
+	if out, err := runRigCmdInDir(t, dir, "run", "a"); err == nil || !strings.Contains(out, "default_args requires a command") {
+		t.Fatalf("expected default_args on composite task to be rejected, got err=%v\n%s", err, out)
+	}
+}
 
-	cmd1 := exec.Command(rigBin, "run", "--list")
-	cmd1.Dir = work
-	out1, err := cmd1.CombinedOutput()
-	if err != nil {
-		t.Fatalf("rig run --list failed: %v\n%s", err, out1)
+func TestRunExpandsEnvInCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
 	}
-	out2, err := runWithInvocationName(t, rigBin, work, "rir", "--list")
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "args"), "#!/bin/sh\nfor a in \"$@\"; do echo \"<$a>\"; done\n", 0o755)
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks]
+show = { command = "./args $GREETING ${TARGET}/out $$HOME ${args}", env = { GREETING = "hello world", TARGET = "dist" } }
+`, 0o644)
+	writeRigLock(t, dir, nil)
+
+	out, err := runRigCmdInDir(t, dir, "run", "show", "--", "$TARGET")
 	if err != nil {
-		t.Fatalf("rir --list failed: %v\n%s", err, out2)
+		t.Fatalf("rig run failed: %v\n%s", err, out)
 	}
-	if strings.TrimSpace(string(out1)) != strings.TrimSpace(string(out2)) {
-		t.Fatalf("outputs differ\nrig: %q\nrir: %q", strings.TrimSpace(string(out1)), strings.TrimSpace(string(out2)))
+	// Expanded values stay one argument; passthrough args are not expanded.
+	if want := "<hello world>\n<dist/out>\n<$HOME>\n<$TARGET>\n"; out != want {
+		t.Fatalf("output=%q, want %q", out, want)
 	}
 }
 
-func TestRigRunListShowsDescriptionsAligned(t *testing.T) {
-	work := t.TempDir()
-	writeFile(t, filepath.Join(work, "rig.toml"), `
+func TestRunHeartbeat(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "slow"), "#!/bin/sh\necho started\nsleep 0.6\necho finished\n", 0o755)
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
 [tasks]
-a = { command = "true", description = "Alpha" }
-b = "true"
-longtask = { command = "true", description = "Long description" }
+quiet = "sleep 0.5"
+slow = { command = "./slow", depends_on = ["quiet"] }
 `, 0o644)
+	writeRigLock(t, dir, nil)
 
-	rigBin := buildRigBinary(t, t.TempDir())
-
-	cmd := exec.Command(rigBin, "run", "--list")
-	cmd.Dir = work
-	out, err := cmd.CombinedOutput()
+	out, err := runRigCmdInDir(t, dir, "run", "slow", "--heartbeat", "200ms")
 	if err != nil {
-		t.Fatalf("rig run --list failed: %v\n%s", err, out)
+		t.Fatalf("rig run failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"⏳ quiet still running (", "— no output yet", "⏳ slow still running (", "— last output ", "finished"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
 	}
 
-	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
-	if len(lines) != 3 {
-		t.Fatalf("expected 3 lines, got %d: %q", len(lines), string(out))
+	if out, err := runRigCmdInDir(t, dir, "run", "quiet"); err != nil || strings.Contains(out, "still running") {
+		t.Fatalf("expected no heartbeat by default, err=%v\n%s", err, out)
 	}
-	if !strings.HasPrefix(lines[0], "a") {
-		t.Fatalf("expected first line to start with task name 'a', got: %q", lines[0])
+
+	t.Setenv("RIG_HEARTBEAT", "soon")
+	if out, err := runRigCmdInDir(t, dir, "run", "quiet"); err == nil || !strings.Contains(out, "invalid RIG_HEARTBEAT") {
+		t.Fatalf("expected invalid RIG_HEARTBEAT error, err=%v\n%s", err, out)
 	}
-	if lines[1] != "b" {
-		t.Fatalf("expected second line to be 'b', got: %q", lines[1])
+}
+
+func TestRunAcrossDirs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
 	}
-	if !strings.HasPrefix(lines[2], "longtask") {
-		t.Fatalf("expected third line to start with task name 'longtask', got: %q", lines[2])
+	dir := t.TempDir()
+	for _, svc := range []string{"a", "b", "c"} {
+		writeFile(t, filepath.Join(dir, "svc", svc, "name"), svc+"\n", 0o644)
 	}
+	writeFile(t, filepath.Join(dir, "svc", "README"), "not a dir\n", 0o644)
+	writeFile(t, filepath.Join(dir, "svc", "b", "fail"), "", 0o644)
+	writeFile(t, filepath.Join(dir, "check"), "#!/bin/sh\necho \"checking $(cat name)\"\n[ ! -e fail ]\n", 0o755)
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks]
+check = { command = "../../check", dirs = ["svc/*"] }
+`, 0o644)
+	writeRigLock(t, dir, nil)
 
-	idxA := strings.Index(lines[0], "Alpha")
-	idxL := strings.Index(lines[2], "Long description")
-	if idxA < 0 || idxL < 0 {
-		t.Fatalf("expected descriptions in output, got: %q", string(out))
+	out, err := runRigCmdInDir(t, dir, "run", "check")
+	if err == nil {
+		t.Fatalf("expected failure in svc/b\n%s", out)
 	}
-	if strings.TrimSpace(lines[0][:idxA]) != "a" {
-		t.Fatalf("unexpected prefix for 'a' line: %q", lines[0])
+	for _, want := range []string{"[svc/a] checking a", "✓ check in svc/a", "✗ check in svc/b", "[svc/c] checking c", "✓ check in svc/c", "1 of 3 directories failed: svc/b"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
 	}
-	if strings.TrimSpace(lines[2][:idxL]) != "longtask" {
-		t.Fatalf("unexpected prefix for 'longtask' line: %q", lines[2])
+
+	out, err = runRigCmdInDir(t, dir, "run", "check", "-C", "svc/a", "-C", "svc/c")
+	if err != nil {
+		t.Fatalf("rig run -C failed: %v\n%s", err, out)
 	}
-	if idxA != idxL {
-		t.Fatalf("expected aligned descriptions, got positions %d and %d\n%s", idxA, idxL, string(out))
+	if strings.Contains(out, "svc/b") || !strings.Contains(out, "✓ check in svc/c") {
+		t.Fatalf("-C should replace dirs:\n%s", out)
 	}
-}
 
-func TestEntrypointRicMatchesRigCheck(t *testing.T) {
-	work := t.TempDir()
-	writeFile(t, filepath.Join(work, "rig.toml"), `
-[tools]
-mockery = "2.0.0"
+	if out, err := runRigCmdInDir(t, dir, "run", "check", "-C", "svc/missing*"); err == nil || !strings.Contains(out, `dirs pattern "svc/missing*" matched no directories`) {
+		t.Fatalf("expected unmatched pattern error, err=%v\n%s", err, out)
+	}
+
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tasks]\nx = { command = \"true\", cwd = \"svc\", dirs = [\"svc/*\"] }\n", 0o644)
+	if out, err := runRigCmdInDir(t, dir, "run", "x"); err == nil || !strings.Contains(out, "dirs and cwd are mutually exclusive") {
+		t.Fatalf("expected dirs/cwd conflict, err=%v\n%s", err, out)
+	}
+}
 
+func TestRunTriggers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
 [tasks]
-noop = "true"
+gen = "sh -c 'mkdir -p dist && echo spec > dist/openapi.json'"
+lint = "true"
+client = { command = "sh -c 'echo client >> client.log'", triggers = ["dist/*.json"] }
+all = { steps = ["gen", "client"] }
 `, 0o644)
+	writeRigLock(t, dir, nil)
+	runs := func() int {
+		b, _ := os.ReadFile(filepath.Join(dir, "client.log"))
+		return strings.Count(string(b), "client")
+	}
 
-	rigBin := buildRigBinary(t, t.TempDir())
-
-	cmd1 := exec.Command(rigBin, "check")
-	cmd1.Dir = work
-	out1, err := cmd1.CombinedOutput()
-	if err == nil {
-		t.Fatalf("expected rig check to fail without lock, got none. output=%s", out1)
+	out, err := runRigCmdInDir(t, dir, "run", "gen")
+	if err != nil {
+		t.Fatalf("rig run gen failed: %v\n%s", err, out)
 	}
-	if !strings.Contains(string(out1), "\"ok\":false") {
-		t.Fatalf("expected JSON ok=false, got: %s", out1)
+	if !strings.Contains(out, "client triggered: dist/openapi.json changed (after gen)") || runs() != 1 {
+		t.Fatalf("expected client to be triggered once (runs=%d):\n%s", runs(), out)
 	}
 
-	out2, err := runWithInvocationName(t, rigBin, work, "ric")
-	if err == nil {
-		t.Fatalf("expected ric invocation to fail without lock, got none. output=%s", out2)
+	if out, err := runRigCmdInDir(t, dir, "run", "lint"); err != nil || strings.Contains(out, "triggered") || runs() != 1 {
+		t.Fatalf("unchanged files must not trigger (runs=%d, err=%v):\n%s", runs(), err, out)
 	}
-	if strings.TrimSpace(string(out1)) != strings.TrimSpace(string(out2)) {
-		t.Fatalf("outputs differ\nrig check: %q\nric: %q", strings.TrimSpace(string(out1)), strings.TrimSpace(string(out2)))
+
+	if out, err := runRigCmdInDir(t, dir, "run", "all"); err != nil || strings.Contains(out, "triggered") || runs() != 2 {
+		t.Fatalf("a task already in the plan must run once (runs=%d, err=%v):\n%s", runs(), err, out)
 	}
 }
 
-func TestEntrypointRidMatchesRigDev(t *testing.T) {
-	work := t.TempDir()
-	writeFile(t, filepath.Join(work, "rig.toml"), `
-[tools]
-reflex = "latest"
-
-[tasks.dev]
-command = "go run ."
-watch = ["**/*.go"]
+func TestRunTaskNice(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses the nice utility")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks]
+gen = { command = "sh -c 'nice; sh -c nice'", nice = 7 }
 `, 0o644)
+	writeRigLock(t, dir, nil)
+	out, err := runRigCmdInDir(t, dir, "run", "gen")
+	if err != nil {
+		t.Fatalf("rig run gen failed: %v\n%s", err, out)
+	}
+	if got := strings.Fields(out); len(got) != 2 || got[0] != "7" || got[1] != "7" {
+		t.Fatalf("expected the task and its children at nice 7, got:\n%s", out)
+	}
+}
 
-	rigBin := buildRigBinary(t, t.TempDir())
+func TestRunIsolate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks]
+gen = { command = "sh -c 'mkdir -p dist && cat a.txt > dist/out.txt && echo junk > scratch.log'", cwd = "api", sources = ["api/*.txt"], outputs = ["api/dist"] }
+sneaky = { command = "cat undeclared.txt", sources = ["api"] }
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "api", "a.txt"), "spec\n", 0o644)
+	writeFile(t, filepath.Join(dir, "undeclared.txt"), "oops\n", 0o644)
+	writeRigLock(t, dir, nil)
 
-	cmd1 := exec.Command(rigBin, "dev")
-	cmd1.Dir = work
-	out1, err := cmd1.CombinedOutput()
-	if err == nil {
-		t.Fatalf("expected rig dev to fail without lock, got none. output=%s", out1)
+	out, err := runRigCmdInDir(t, dir, "run", "--isolate", "gen")
+	if err != nil {
+		t.Fatalf("rig run --isolate gen failed: %v\n%s", err, out)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "api", "dist", "out.txt")); err != nil || string(b) != "spec\n" {
+		t.Fatalf("expected outputs copied back (err=%v):\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "api", "scratch.log")); !os.IsNotExist(err) {
+		t.Fatalf("undeclared output must not be copied back:\n%s", out)
+	}
+	if !strings.Contains(out, "gen wrote files not in outputs (discarded): api/scratch.log") {
+		t.Fatalf("expected undeclared output warning:\n%s", out)
 	}
 
-	out2, err := runWithInvocationName(t, rigBin, work, "rid")
-	if err == nil {
-		t.Fatalf("expected rid invocation to fail without lock, got none. output=%s", out2)
+	// The failed task's workspace is kept; keep it inside the test's temp dir.
+	env := append(os.Environ(), "TMPDIR="+t.TempDir())
+	out, err = runRigCmdInDirWithEnv(t, dir, env, "run", "--isolate", "sneaky")
+	if err == nil || !strings.Contains(out, "isolated workspace kept at") {
+		t.Fatalf("expected reading an undeclared file to fail (err=%v):\n%s", err, out)
 	}
-	if strings.TrimSpace(string(out1)) != strings.TrimSpace(string(out2)) {
-		t.Fatalf("outputs differ\nrig dev: %q\nrid: %q", strings.TrimSpace(string(out1)), strings.TrimSpace(string(out2)))
+	if out, err := runRigCmdInDir(t, dir, "run", "sneaky"); err != nil {
+		t.Fatalf("without --isolate the task sees the whole tree: %v\n%s", err, out)
 	}
 }
 
-func TestEntrypointRisMatchesRigStartStub(t *testing.T) {
-	work := t.TempDir()
-	rigBin := buildRigBinary(t, t.TempDir())
+func TestRunFailedReplaysOnlyFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks]
+gen = { command = "sh -c 'echo x >> gen.log'" }
+check = { command = "sh -c 'basename $(pwd) >> ../../check.log; test -f ok'", dirs = ["svc/*"] }
+ci = { depends_on = ["gen", "check"], command = "sh -c 'echo \"$@\" >> ci.log' ci" }
+`, 0o644)
+	for _, d := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(dir, "svc", d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(dir, "svc", "a", "ok"), "", 0o644)
+	writeRigLock(t, dir, nil)
 
-	cmd1 := exec.Command(rigBin, "start")
-	cmd1.Dir = work
-	out1, err := cmd1.CombinedOutput()
-	if err == nil {
-		t.Fatalf("expected rig start to fail (stub), got none. output=%s", out1)
+	if out, err := runRigCmdInDir(t, dir, "run", "--failed"); err == nil || !strings.Contains(out, "no previous run recorded") {
+		t.Fatalf("expected --failed without a previous run to fail (err=%v):\n%s", err, out)
+	}
+	out, err := runRigCmdInDir(t, dir, "run", "ci", "--", "fast")
+	if err == nil || !strings.Contains(out, "rig run --failed") {
+		t.Fatalf("expected ci to fail and suggest --failed (err=%v):\n%s", err, out)
 	}
 
-	out2, err := runWithInvocationName(t, rigBin, work, "ris")
-	if err == nil {
-		t.Fatalf("expected ris invocation to fail (stub), got none. output=%s", out2)
+	writeFile(t, filepath.Join(dir, "svc", "b", "ok"), "", 0o644)
+	out, err = runRigCmdInDir(t, dir, "run", "--failed")
+	if err != nil {
+		t.Fatalf("rig run --failed failed: %v\n%s", err, out)
 	}
-	if strings.TrimSpace(string(out1)) != strings.TrimSpace(string(out2)) {
-		t.Fatalf("outputs differ\nrig start: %q\nris: %q", strings.TrimSpace(string(out1)), strings.TrimSpace(string(out2)))
+	if b, _ := os.ReadFile(filepath.Join(dir, "check.log")); string(b) != "a\nb\nb\n" {
+		t.Fatalf("expected only the failed directory to rerun, check.log=%q", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "gen.log")); string(b) != "x\n" {
+		t.Fatalf("gen succeeded before and must not rerun, gen.log=%q", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "ci.log")); string(b) != "fast\n" {
+		t.Fatalf("ci should run once with the original args, ci.log=%q", b)
+	}
+	if out, err := runRigCmdInDir(t, dir, "run", "--failed"); err != nil || !strings.Contains(out, "nothing failed") {
+		t.Fatalf("expected nothing left to rerun (err=%v):\n%s", err, out)
 	}
 }
 
-func TestAliasCommandOutputIsStable(t *testing.T) {
-	work := t.TempDir()
-	rigBin := buildRigBinary(t, t.TempDir())
+func TestDoctorReportBundle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tasks]\ndeploy = { command = \"true\", env = { DEPLOY_TOKEN = \"tok-123\" } }\n", 0o644)
+	writeRigLock(t, dir, nil)
 
-	cmd := exec.Command(rigBin, "alias")
-	cmd.Dir = work
-	out, err := cmd.CombinedOutput()
+	out, _ := runRigCmdInDir(t, dir, "doctor", "--report", "-o", "report.tar.gz")
+	if !strings.Contains(out, "rig.toml") || !strings.Contains(out, "pass --yes") && !strings.Contains(out, "Report cancelled") {
+		t.Fatalf("expected preview and no report without --yes, got: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "report.tar.gz")); err == nil {
+		t.Fatalf("report written without confirmation")
+	}
+
+	out, err := runRigCmdInDir(t, dir, "doctor", "--report", "--yes", "-o", "report.tar.gz")
 	if err != nil {
-		t.Fatalf("rig alias failed: %v\n%s", err, out)
+		t.Fatalf("doctor --report failed: %v\n%s", err, out)
 	}
-	got := string(out)
-	want := aliasInfoText
-	if got != want {
-		t.Fatalf("unexpected rig alias output\nwant:\n%s\n\ngot:\n%s", want, got)
+	for _, want := range []string{"versions.txt", "doctor.txt", "rig.lock", "(1 value(s) redacted)", "Wrote report.tar.gz"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "report.tar.gz"))
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("gunzip: %v", err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("read archive: %v", err)
+	}
+	if bytes.Contains(raw, []byte("tok-123")) || !bytes.Contains(raw, []byte("has_config: true")) {
+		t.Fatalf("report must include doctor output and no secrets")
 	}
 }
 
-func TestRunRejectsUnsupportedTaskFields(t *testing.T) {
+func TestFixRefusesWhileAnotherRigHoldsTheProjectLock(t *testing.T) {
 	dir := t.TempDir()
-	writeFile(t, filepath.Join(dir, "rig.toml"), `
-[tools]
-mockery = "2.0.0"
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tools]\nmockery = \"2.0.0\"\n", 0o644)
+	writeFile(t, filepath.Join(dir, "rig.lock"), `schema = 0
+
+[[tools]]
+kind = "go-binary"
+requested = "mockery@2.0.0"
+resolved = "github.com/vektra/mockery/v2@v2.0.0"
+module = "github.com/vektra/mockery/v2"
+bin = "mockery"
+sha256 = "deadbeef"
+`, 0o644)
+	// This test process stands in for a concurrent `rig sync`.
+	holder := fmt.Sprintf("%d\nsyncing\n%s\n", os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	writeFile(t, filepath.Join(dir, ".rig", "lock"), holder, 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "check", "--fix", "--yes")
+	if err == nil || !strings.Contains(out, fmt.Sprintf("another rig process (PID %d) is syncing", os.Getpid())) || !strings.Contains(out, "--wait") {
+		t.Fatalf("expected project lock error, err=%v\n%s", err, out)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, ".rig", "lock")); string(b) != holder {
+		t.Fatalf("lock held by another process was modified: %q", b)
+	}
+}
 
+func TestAccessibleOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
 [tasks]
-bad = { command = "echo hi", shell = "bash" }
+gen = "true"
+bad = "false"
+main = { command = "true", depends_on = ["gen"] }
+broken = { command = "true", depends_on = ["bad"] }
 `, 0o644)
-	out, err := runRigCmdInDir(t, dir, "run", "bad")
+	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "run", "--accessible", "--output", "errors-only", "main")
+	if err != nil || !strings.Contains(out, "OK: gen (") || strings.Contains(out, "✓") {
+		t.Fatalf("expected an OK status line, got %v\n%s", err, out)
+	}
+
+	userCfg := filepath.Join(t.TempDir(), "config.toml")
+	writeFile(t, userCfg, "accessible = true\n", 0o644)
+	env := append(os.Environ(), "RIG_USER_CONFIG="+userCfg, "FORCE_COLOR=1")
+	out, err = runRigCmdInDirWithEnv(t, dir, env, "run", "--output", "errors-only", "--continue-on-error", "broken")
 	if err == nil {
-		t.Fatalf("expected error, got none. output=%s", out)
+		t.Fatalf("expected failure, got success:\n%s", out)
 	}
-	var ee *exec.ExitError
-	if !errors.As(err, &ee) {
-		t.Fatalf("expected ExitError, got %T", err)
+	for _, want := range []string{"FAIL: bad (", "SKIP: broken skipped (bad failed)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q from the user config default:\n%s", want, out)
+		}
 	}
-	if !strings.Contains(out, "unsupported field") {
-		t.Fatalf("expected unsupported field error, got: %s", out)
+	if strings.Contains(out, "\x1b[") {
+		t.Fatalf("accessible output must not be colored:\n%q", out)
+	}
+
+	out, _ = runRigCmdInDirWithEnv(t, dir, env, "run", "--accessible=false", "--output", "errors-only", "main")
+	if !strings.Contains(out, "✓ gen (") {
+		t.Fatalf("--accessible=false should override the user config:\n%s", out)
 	}
 }
 
-func TestToolAuthority_PositiveCheckAndDev(t *testing.T) {
+func TestRunOutputModesForDependencies(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("shell-script based test")
 	}
 	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "say"), "#!/bin/sh\necho \"$1 says hi\"\nif [ \"$2\" = fail ]; then echo \"$1 broke\" >&2; exit 1; fi\n", 0o755)
 	writeFile(t, filepath.Join(dir, "rig.toml"), `
-[tools]
-reflex = "latest"
-
-[tasks.dev]
-command = "./ok"
-watch = ["**/*.go"]
+[tasks]
+gen = "./say gen"
+bad = "./say bad fail"
+main = { command = "./say main", depends_on = ["gen"] }
+broken = { command = "./say broken", depends_on = ["bad"] }
 `, 0o644)
-	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
-	reflexBin := filepath.Join(dir, ".rig", "bin", "reflex")
-	writeFile(t, reflexBin, "#!/bin/sh\n# minimal reflex stub for tests\n# must NOT be invoked with --version by rig\nif [ \"$1\" = \"--version\" ]; then\n  echo unexpected --version >&2\n  exit 2\nfi\n# run args after -- once, then exit\nwhile [ $# -gt 0 ]; do\n  if [ \"$1\" = \"--\" ]; then\n    shift\n    exec \"$@\"\n  fi\n  shift\ndone\nexit 0\n", 0o755)
-	sha, err := core.ComputeFileSHA256(reflexBin)
+	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "run", "--output", "errors-only", "main")
 	if err != nil {
-		t.Fatalf("sha256 reflex: %v", err)
+		t.Fatalf("expected success, got %v\n%s", err, out)
+	}
+	if strings.Contains(out, "gen says hi") || !strings.Contains(out, "✓ gen (") || !strings.Contains(out, "main says hi") {
+		t.Fatalf("errors-only should collapse successful deps only:\n%s", out)
 	}
-	writeFile(t, filepath.Join(dir, "rig.lock"), fmt.Sprintf(`schema = 0
 
-[[tools]]
-kind = "go-binary"
-requested = "reflex@latest"
-resolved = "github.com/cespare/reflex@v9.9.9"
-module = "github.com/cespare/reflex"
-bin = "reflex"
-sha256 = %q
-`, sha), 0o644)
+	out, err = runRigCmdInDir(t, dir, "run", "--output", "errors-only", "broken")
+	if err == nil {
+		t.Fatalf("expected failure, got success:\n%s", out)
+	}
+	for _, want := range []string{"✗ bad (", "bad says hi", "bad broke"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in failed dependency output:\n%s", want, out)
+		}
+	}
 
-	out, err := runRigCmdInDir(t, dir, "check")
+	out, err = runRigCmdInDir(t, dir, "run", "--output", "prefixed", "main")
 	if err != nil {
-		t.Fatalf("expected check success, got error: %v\n%s", err, out)
+		t.Fatalf("expected success, got %v\n%s", err, out)
 	}
-	if !strings.Contains(out, "\"ok\":true") {
-		t.Fatalf("expected JSON ok=true, got: %s", out)
+	if !strings.Contains(out, "[gen] gen says hi") || strings.Contains(out, "[main]") {
+		t.Fatalf("prefixed should label dependency lines only:\n%s", out)
 	}
 
-	out, err = runRigCmdInDir(t, dir, "dev")
-	if err != nil {
-		t.Fatalf("expected dev success, got error: %v\n%s", err, out)
+	if out, err := runRigCmdInDir(t, dir, "run", "--output", "loud", "main"); err == nil || !strings.Contains(out, "invalid --output") {
+		t.Fatalf("expected invalid --output error, got %v\n%s", err, out)
 	}
 }
 
-func TestToolAuthority_GlobalToolDoesNotCount(t *testing.T) {
+func TestRunContinueOnErrorAndAllowFailure(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("shell-script based test")
 	}
 	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "step"), "#!/bin/sh\necho \"$1\" >> ran.txt\n[ \"$2\" != fail ]\n", 0o755)
 	writeFile(t, filepath.Join(dir, "rig.toml"), `
-[tools]
-reflex = "latest"
-
-[tasks.dev]
-command = "./ok"
-watch = ["**/*.go"]
-`, 0o644)
-	writeFile(t, filepath.Join(dir, "rig.lock"), `schema = 0
-
-[[tools]]
-kind = "go-binary"
-requested = "reflex@latest"
-resolved = "github.com/cespare/reflex@v9.9.9"
-module = "github.com/cespare/reflex"
-bin = "reflex"
-sha256 = "00"
+[tasks]
+lint = "./step lint fail"
+vet = { command = "./step vet fail", allow_failure = true }
+test = "./step test"
+ci = { command = "./step ci", depends_on = ["lint", "vet", "test"] }
 `, 0o644)
-	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
-
-	globalBin := filepath.Join(dir, "global-bin")
-	writeFile(t, filepath.Join(globalBin, "reflex"), "#!/bin/sh\n# global reflex should be ignored\nif [ \"$1\" = \"--version\" ]; then\n  echo unexpected --version >&2\n  exit 2\nfi\nexit 0\n", 0o755)
-	env := envWithPrependedPath(globalBin)
+	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+	ran := func() string {
+		b, _ := os.ReadFile(filepath.Join(dir, "ran.txt"))
+		_ = os.Remove(filepath.Join(dir, "ran.txt"))
+		return strings.Join(strings.Fields(string(b)), ",")
+	}
 
-	out, err := runRigCmdInDirWithEnv(t, dir, env, "check")
+	out, err := runRigCmdInDir(t, dir, "run", "ci")
 	if err == nil {
-		t.Fatalf("expected check to fail (no .rig/bin/reflex), got none. output=%s", out)
+		t.Fatalf("expected failure, got success:\n%s", out)
 	}
-	if !strings.Contains(out, "\"ok\":false") {
-		t.Fatalf("expected JSON ok=false, got: %s", out)
+	if got := ran(); got != "lint" {
+		t.Fatalf("without --continue-on-error the run should stop at lint; ran %q\n%s", got, out)
 	}
 
-	out, err = runRigCmdInDirWithEnv(t, dir, env, "dev")
+	out, err = runRigCmdInDir(t, dir, "run", "--continue-on-error", "ci")
 	if err == nil {
-		t.Fatalf("expected dev to fail (no .rig/bin/reflex), got none. output=%s", out)
+		t.Fatalf("expected failure, got success:\n%s", out)
 	}
-	// The exact error string is UX, but it must fail even if reflex exists globally.
-	if !strings.Contains(out, "dev watcher 'reflex' missing in .rig/bin") {
-		t.Fatalf("expected missing-reflex failure, got: %s", out)
+	if got := ran(); got != "lint,vet,test" {
+		t.Fatalf("expected lint, vet, and test to run; ran %q\n%s", got, out)
+	}
+	for _, want := range []string{"(allow_failure)", "1 task(s) failed: lint (skipped: ci)", "ci skipped (lint failed)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
 	}
 }
 
-func TestDevRestartOnChildExit(t *testing.T) {
+func TestRunCompositeSteps(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("shell-script based test")
 	}
 	dir := t.TempDir()
-	state := filepath.Join(dir, "state")
+	writeFile(t, filepath.Join(dir, "step"), "#!/bin/sh\necho \"$1\" >> ran.txt\n", 0o755)
+	// Each side waits for the other to start, so only a concurrent run passes.
+	writeFile(t, filepath.Join(dir, "meet"), "#!/bin/sh\ntouch \"$1.started\"\nfor i in $(seq 50); do [ -f \"$2.started\" ] && exit 0; sleep 0.1; done\nexit 1\n", 0o755)
 	writeFile(t, filepath.Join(dir, "rig.toml"), `
-[tools]
-reflex = "latest"
-
-[tasks.dev]
-command = "./ok"
-watch = ["**/*.go"]
+[tasks]
+fmt = "./step fmt"
+lint = "./step lint"
+test = "./step test"
+check = { steps = ["fmt", "lint", "test"], depends_on = ["fmt"] }
+a = "./meet a b"
+b = "./meet b a"
+both = { steps = ["a", "b"], mode = "parallel" }
 `, 0o644)
-	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
-	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\nstate=\"$RIG_TEST_STATE\"\nif [ ! -f \"$state\" ]; then\n  echo first > \"$state\"\n  exit 1\nfi\nexit 0\n")
-	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
 
-	bin := buildRigBinary(t, t.TempDir())
-	cmd := exec.Command(bin, "dev")
-	cmd.Dir = dir
-	cmd.Env = append(os.Environ(), "RIG_TEST_STATE="+state)
-	out, err := cmd.CombinedOutput()
+	out, err := runRigCmdInDir(t, dir, "run", "check")
 	if err != nil {
-		t.Fatalf("expected dev success, got error: %v\n%s", err, out)
+		t.Fatalf("rig run check failed: %v\n%s", err, out)
 	}
-	if !strings.Contains(string(out), "▶ restarting…") {
-		t.Fatalf("expected restart log, got: %s", out)
+	b, _ := os.ReadFile(filepath.Join(dir, "ran.txt"))
+	if got := strings.Join(strings.Fields(string(b)), ","); got != "fmt,lint,test" {
+		t.Fatalf("expected steps to run once each in order; ran %q\n%s", got, out)
 	}
-}
 
-func TestDevRejectsUnsupportedDevTaskFields(t *testing.T) {
-	if runtime.GOOS == "windows" {
+	out, err = runRigCmdInDir(t, dir, "run", "both")
+	if err != nil {
+		t.Fatalf("parallel steps did not run concurrently: %v\n%s", err, out)
+	}
+
+	if out, err := runRigCmdInDir(t, dir, "run", "check", "--", "-v"); err == nil || !strings.Contains(out, "cannot take extra arguments") {
+		t.Fatalf("expected composite task to reject passthrough args, got err=%v\n%s", err, out)
+	}
+}
+
+func TestRunAndTestApplyProfile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[profile.race]
+tags = ["integration"]
+flags = ["-race"]
+env = { MODE = "race" }
+
+[tasks]
+show = "sh -c 'echo goflags=$GOFLAGS mode=$MODE profile=$RIG_PROFILE'"
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+
+	out, err := runRigCmdInDirWithEnv(t, dir, append(os.Environ(), "GOFLAGS="), "run", "--profile", "race", "show")
+	if err != nil {
+		t.Fatalf("rig run --profile failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "goflags=-tags=integration -race mode=race profile=race") {
+		t.Fatalf("expected profile env in task output:\n%s", out)
+	}
+
+	out, err = runRigCmdInDir(t, dir, "test", "--profile", "race", "--dry-run", "./pkg/...")
+	if err != nil {
+		t.Fatalf("rig test --dry-run failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, `go test -tags "integration" -race "./pkg/..."`) {
+		t.Fatalf("expected composed go test command:\n%s", out)
+	}
+
+	if out, err := runRigCmdInDir(t, dir, "run", "--profile", "nope", "show"); err == nil || !strings.Contains(out, `profile "nope" not found`) {
+		t.Fatalf("expected unknown profile error, got err=%v\n%s", err, out)
+	}
+}
+
+func TestEntrypointRirMatchesRigRunList(t *testing.T) {
+	work := t.TempDir()
+	writeFile(t, filepath.Join(work, "rig.toml"), `
+[tasks]
+a = "true"
+b = "true"
+`, 0o644)
+
+	rigBin := buildRigBinary(t, t.TempDir())
+
+	cmd1 := exec.Command(rigBin, "run", "--list")
+	cmd1.Dir = work
+	out1, err := cmd1.CombinedOutput()
+	if err != nil {
+		t.Fatalf("rig run --list failed: %v\n%s", err, out1)
+	}
+	out2, err := runWithInvocationName(t, rigBin, work, "rir", "--list")
+	if err != nil {
+		t.Fatalf("rir --list failed: %v\n%s", err, out2)
+	}
+	if strings.TrimSpace(string(out1)) != strings.TrimSpace(string(out2)) {
+		t.Fatalf("outputs differ\nrig: %q\nrir: %q", strings.TrimSpace(string(out1)), strings.TrimSpace(string(out2)))
+	}
+}
+
+func TestRigRunListShowsDescriptionsAligned(t *testing.T) {
+	work := t.TempDir()
+	writeFile(t, filepath.Join(work, "rig.toml"), `
+[tasks]
+a = { command = "true", description = "Alpha" }
+b = "true"
+longtask = { command = "true", description = "Long description" }
+`, 0o644)
+
+	rigBin := buildRigBinary(t, t.TempDir())
+
+	cmd := exec.Command(rigBin, "run", "--list")
+	cmd.Dir = work
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("rig run --list failed: %v\n%s", err, out)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), string(out))
+	}
+	if !strings.HasPrefix(lines[0], "a") {
+		t.Fatalf("expected first line to start with task name 'a', got: %q", lines[0])
+	}
+	if lines[1] != "b" {
+		t.Fatalf("expected second line to be 'b', got: %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "longtask") {
+		t.Fatalf("expected third line to start with task name 'longtask', got: %q", lines[2])
+	}
+
+	idxA := strings.Index(lines[0], "Alpha")
+	idxL := strings.Index(lines[2], "Long description")
+	if idxA < 0 || idxL < 0 {
+		t.Fatalf("expected descriptions in output, got: %q", string(out))
+	}
+	if strings.TrimSpace(lines[0][:idxA]) != "a" {
+		t.Fatalf("unexpected prefix for 'a' line: %q", lines[0])
+	}
+	if strings.TrimSpace(lines[2][:idxL]) != "longtask" {
+		t.Fatalf("unexpected prefix for 'longtask' line: %q", lines[2])
+	}
+	if idxA != idxL {
+		t.Fatalf("expected aligned descriptions, got positions %d and %d\n%s", idxA, idxL, string(out))
+	}
+}
+
+func TestRigRunNamespacedTasks(t *testing.T) {
+	work := t.TempDir()
+	writeFile(t, filepath.Join(work, "rig.toml"), `
+[tasks]
+build = { command = "true", description = "Build" }
+
+[tasks.db.migrate]
+command = "echo migrating"
+description = "Apply migrations"
+
+[tasks.db]
+seed = { command = "true", depends_on = ["db:migrate"] }
+`, 0o644)
+	writeFile(t, filepath.Join(work, "rig.lock"), "schema = 0\n", 0o644)
+
+	out, err := runRigCmdInDir(t, work, "run", "--list")
+	if err != nil {
+		t.Fatalf("rig run --list failed: %v\n%s", err, out)
+	}
+	want := "(no namespace):\n  build       Build\n\ndb:\n  db:migrate  Apply migrations\n  db:seed\n"
+	if out != want {
+		t.Fatalf("namespaced tasks should be listed by namespace:\n%q\nwant:\n%q", out, want)
+	}
+
+	out, err = runRigCmdInDir(t, work, "run", "db:seed")
+	if err != nil || !strings.Contains(out, "migrating") {
+		t.Fatalf("rig run db:seed failed: %v\n%s", err, out)
+	}
+}
+
+func TestRigRunWarnsWhenLockPredatesManifest(t *testing.T) {
+	work := t.TempDir()
+	writeFile(t, filepath.Join(work, "rig.toml"), "[tasks]\nhello = \"echo hi\"\n", 0o644)
+	writeFile(t, filepath.Join(work, "rig.lock"), "schema = 0\n\n[meta]\nrig_version = \"v1.0.0\"\ngenerated = \"2024-01-01T00:00:00Z\"\nmanifest_sha256 = \"0000\"\n", 0o644)
+
+	out, err := runRigCmdInDir(t, work, "run", "hello")
+	if err != nil || !strings.Contains(out, "hi") || !strings.Contains(out, "rig.lock predates the current [tools] or [tool-aliases] in rig.toml") {
+		t.Fatalf("expected the task to run with a stale rig.lock warning (err=%v):\n%s", err, out)
+	}
+}
+
+func TestRigRunListFilterGroupAndInternal(t *testing.T) {
+	work := t.TempDir()
+	writeFile(t, filepath.Join(work, "rig.toml"), `
+[tasks]
+build = { command = "true", description = "Build" }
+"db:migrate" = { command = "true", description = "Apply migrations" }
+"db:seed" = "true"
+"db:reset-internal" = { command = "true", internal = true }
+`, 0o644)
+
+	out, err := runRigCmdInDir(t, work, "run", "--list", "--group-by", "namespace")
+	if err != nil {
+		t.Fatalf("rig run --list --group-by namespace failed: %v\n%s", err, out)
+	}
+	want := "(no namespace):\n  build       Build\n\ndb:\n  db:migrate  Apply migrations\n  db:seed\n"
+	if out != want {
+		t.Fatalf("grouped list:\n%q\nwant:\n%q", out, want)
+	}
+
+	out, err = runRigCmdInDir(t, work, "run", "--list", "--filter", "db:*", "--all")
+	if err != nil {
+		t.Fatalf("rig run --list --filter failed: %v\n%s", err, out)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[1], "db:reset-internal") {
+		t.Fatalf("expected the three db tasks, including the internal one:\n%s", out)
+	}
+
+	if out, err := runRigCmdInDir(t, work, "run", "--filter", "db:*"); err == nil || !strings.Contains(out, "--filter requires --list") {
+		t.Fatalf("expected --filter without --list to fail, got %v\n%s", err, out)
+	}
+}
+
+func TestRigRunListJSONIncludesLastRun(t *testing.T) {
+	work := t.TempDir()
+	writeFile(t, filepath.Join(work, "rig.toml"), `
+[tasks]
+build = { command = "true", description = "Build" }
+fail = "sh -c 'exit 3'"
+gen = { command = "true", sources = ["api"], outputs = ["out"] }
+`, 0o644)
+	writeFile(t, filepath.Join(work, "rig.lock"), "schema = 0\n", 0o644)
+	if out, err := runRigCmdInDir(t, work, "run", "build"); err != nil {
+		t.Fatalf("rig run build failed: %v\n%s", err, out)
+	}
+	if out, err := runRigCmdInDir(t, work, "run", "fail"); err == nil {
+		t.Fatalf("expected rig run fail to fail:\n%s", out)
+	}
+
+	out, err := runRigCmdInDir(t, work, "run", "--list", "--json")
+	if err != nil {
+		t.Fatalf("rig run --list --json failed: %v\n%s", err, out)
+	}
+	var items []struct {
+		Name         string     `json:"name"`
+		Description  string     `json:"description"`
+		Cacheable    bool       `json:"cacheable"`
+		LastRun      *time.Time `json:"lastRun"`
+		LastDuration *int64     `json:"lastDuration"`
+		LastExitCode *int       `json:"lastExitCode"`
+	}
+	if err := stdjson.Unmarshal([]byte(out), &items); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(items) != 3 || items[0].Name != "build" || items[1].Name != "fail" || items[2].Name != "gen" {
+		t.Fatalf("unexpected tasks:\n%s", out)
+	}
+	if b := items[0]; b.Description != "Build" || b.LastRun == nil || b.LastDuration == nil || b.LastExitCode == nil || *b.LastExitCode != 0 || b.Cacheable {
+		t.Fatalf("unexpected build entry:\n%s", out)
+	}
+	if f := items[1]; f.LastExitCode == nil || *f.LastExitCode != 3 {
+		t.Fatalf("expected fail to report exit code 3:\n%s", out)
+	}
+	if g := items[2]; g.LastRun != nil || g.LastExitCode != nil || !g.Cacheable {
+		t.Fatalf("expected gen to be cacheable and never run:\n%s", out)
+	}
+
+	if out, err := runRigCmdInDir(t, work, "run", "--list", "--json", "--porcelain"); err == nil || !strings.Contains(out, "--porcelain cannot be combined with --json") {
+		t.Fatalf("expected --porcelain with --json to fail, got %v\n%s", err, out)
+	}
+}
+
+func TestEntrypointRicMatchesRigCheck(t *testing.T) {
+	work := t.TempDir()
+	writeFile(t, filepath.Join(work, "rig.toml"), `
+[tools]
+mockery = "2.0.0"
+
+[tasks]
+noop = "true"
+`, 0o644)
+
+	rigBin := buildRigBinary(t, t.TempDir())
+
+	cmd1 := exec.Command(rigBin, "check")
+	cmd1.Dir = work
+	out1, err := cmd1.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected rig check to fail without lock, got none. output=%s", out1)
+	}
+	if !strings.Contains(string(out1), "\"ok\":false") {
+		t.Fatalf("expected JSON ok=false, got: %s", out1)
+	}
+
+	out2, err := runWithInvocationName(t, rigBin, work, "ric")
+	if err == nil {
+		t.Fatalf("expected ric invocation to fail without lock, got none. output=%s", out2)
+	}
+	if strings.TrimSpace(string(out1)) != strings.TrimSpace(string(out2)) {
+		t.Fatalf("outputs differ\nrig check: %q\nric: %q", strings.TrimSpace(string(out1)), strings.TrimSpace(string(out2)))
+	}
+}
+
+func TestEntrypointRidMatchesRigDev(t *testing.T) {
+	work := t.TempDir()
+	writeFile(t, filepath.Join(work, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "go run ."
+watch = ["**/*.go"]
+`, 0o644)
+
+	rigBin := buildRigBinary(t, t.TempDir())
+
+	cmd1 := exec.Command(rigBin, "dev")
+	cmd1.Dir = work
+	out1, err := cmd1.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected rig dev to fail without lock, got none. output=%s", out1)
+	}
+
+	out2, err := runWithInvocationName(t, rigBin, work, "rid")
+	if err == nil {
+		t.Fatalf("expected rid invocation to fail without lock, got none. output=%s", out2)
+	}
+	if strings.TrimSpace(string(out1)) != strings.TrimSpace(string(out2)) {
+		t.Fatalf("outputs differ\nrig dev: %q\nrid: %q", strings.TrimSpace(string(out1)), strings.TrimSpace(string(out2)))
+	}
+}
+
+func TestEntrypointRisMatchesRigStartStub(t *testing.T) {
+	work := t.TempDir()
+	rigBin := buildRigBinary(t, t.TempDir())
+
+	cmd1 := exec.Command(rigBin, "start")
+	cmd1.Dir = work
+	out1, err := cmd1.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected rig start to fail (stub), got none. output=%s", out1)
+	}
+
+	out2, err := runWithInvocationName(t, rigBin, work, "ris")
+	if err == nil {
+		t.Fatalf("expected ris invocation to fail (stub), got none. output=%s", out2)
+	}
+	if strings.TrimSpace(string(out1)) != strings.TrimSpace(string(out2)) {
+		t.Fatalf("outputs differ\nrig start: %q\nris: %q", strings.TrimSpace(string(out1)), strings.TrimSpace(string(out2)))
+	}
+}
+
+func TestAliasCommandOutputIsStable(t *testing.T) {
+	work := t.TempDir()
+	rigBin := buildRigBinary(t, t.TempDir())
+
+	cmd := exec.Command(rigBin, "alias")
+	cmd.Dir = work
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("rig alias failed: %v\n%s", err, out)
+	}
+	got := string(out)
+	want := aliasInfoText
+	if got != want {
+		t.Fatalf("unexpected rig alias output\nwant:\n%s\n\ngot:\n%s", want, got)
+	}
+}
+
+func TestRunRejectsUnsupportedTaskFields(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+mockery = "2.0.0"
+
+[tasks]
+bad = { command = "echo hi", shell = "bash" }
+`, 0o644)
+	out, err := runRigCmdInDir(t, dir, "run", "bad")
+	if err == nil {
+		t.Fatalf("expected error, got none. output=%s", out)
+	}
+	var ee *exec.ExitError
+	if !errors.As(err, &ee) {
+		t.Fatalf("expected ExitError, got %T", err)
+	}
+	if !strings.Contains(out, "unsupported field") {
+		t.Fatalf("expected unsupported field error, got: %s", out)
+	}
+}
+
+func TestToolAuthority_PositiveCheckAndDev(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "./ok"
+watch = ["**/*.go"]
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
+	reflexBin := filepath.Join(dir, ".rig", "bin", "reflex")
+	writeFile(t, reflexBin, "#!/bin/sh\n# minimal reflex stub for tests\n# must NOT be invoked with --version by rig\nif [ \"$1\" = \"--version\" ]; then\n  echo unexpected --version >&2\n  exit 2\nfi\n# run args after -- once, then exit\nwhile [ $# -gt 0 ]; do\n  if [ \"$1\" = \"--\" ]; then\n    shift\n    exec \"$@\"\n  fi\n  shift\ndone\nexit 0\n", 0o755)
+	sha, err := core.ComputeFileSHA256(reflexBin)
+	if err != nil {
+		t.Fatalf("sha256 reflex: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "rig.lock"), fmt.Sprintf(`schema = 0
+
+[[tools]]
+kind = "go-binary"
+requested = "reflex@latest"
+resolved = "github.com/cespare/reflex@v9.9.9"
+module = "github.com/cespare/reflex"
+bin = "reflex"
+sha256 = %q
+`, sha), 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "check")
+	if err != nil {
+		t.Fatalf("expected check success, got error: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "\"ok\":true") {
+		t.Fatalf("expected JSON ok=true, got: %s", out)
+	}
+
+	out, err = runRigCmdInDir(t, dir, "dev")
+	if err != nil {
+		t.Fatalf("expected dev success, got error: %v\n%s", err, out)
+	}
+}
+
+func TestToolAuthority_GlobalToolDoesNotCount(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "./ok"
+watch = ["**/*.go"]
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "rig.lock"), `schema = 0
+
+[[tools]]
+kind = "go-binary"
+requested = "reflex@latest"
+resolved = "github.com/cespare/reflex@v9.9.9"
+module = "github.com/cespare/reflex"
+bin = "reflex"
+sha256 = "00"
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
+
+	globalBin := filepath.Join(dir, "global-bin")
+	writeFile(t, filepath.Join(globalBin, "reflex"), "#!/bin/sh\n# global reflex should be ignored\nif [ \"$1\" = \"--version\" ]; then\n  echo unexpected --version >&2\n  exit 2\nfi\nexit 0\n", 0o755)
+	env := envWithPrependedPath(globalBin)
+
+	out, err := runRigCmdInDirWithEnv(t, dir, env, "check")
+	if err == nil {
+		t.Fatalf("expected check to fail (no .rig/bin/reflex), got none. output=%s", out)
+	}
+	if !strings.Contains(out, "\"ok\":false") {
+		t.Fatalf("expected JSON ok=false, got: %s", out)
+	}
+
+	out, err = runRigCmdInDirWithEnv(t, dir, env, "dev")
+	if err == nil {
+		t.Fatalf("expected dev to fail (no .rig/bin/reflex), got none. output=%s", out)
+	}
+	// The exact error string is UX, but it must fail even if reflex exists globally.
+	if !strings.Contains(out, "dev watcher 'reflex' missing in .rig/bin") {
+		t.Fatalf("expected missing-reflex failure, got: %s", out)
+	}
+}
+
+func TestDevRestartOnChildExit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	state := filepath.Join(dir, "state")
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "./ok"
+watch = ["**/*.go"]
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\nstate=\"$RIG_TEST_STATE\"\nif [ ! -f \"$state\" ]; then\n  echo first > \"$state\"\n  exit 1\nfi\nexit 0\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "dev")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "RIG_TEST_STATE="+state)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("expected dev success, got error: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "▶ restarting…") {
+		t.Fatalf("expected restart log, got: %s", out)
+	}
+}
+
+func TestDevRejectsUnsupportedDevTaskFields(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "./ok"
+watch = ["**/*.go"]
+description = "nope"
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
+
+	// Even with a valid lock + installed reflex, strict parsing must reject extra fields.
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\nexit 0\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+
+	out, err := runRigCmdInDir(t, dir, "dev")
+	if err == nil {
+		t.Fatalf("expected error, got none. output=%s", out)
+	}
+	if !strings.Contains(out, "unsupported field") {
+		t.Fatalf("expected unsupported field error, got: %s", out)
+	}
+}
+
+func TestDevRestartsOnGoFileChange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	state := filepath.Join(dir, "state")
+	ready := filepath.Join(dir, "ready")
+	watchFile := filepath.Join(dir, "watchme.go")
+
+	writeFile(t, watchFile, "package main\n", 0o644)
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "./ok"
+watch = ["**/*.go"]
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
+
+	reflexStub := "#!/bin/sh\n" +
+		"seen_r=0\n" +
+		"regex=\"\"\n" +
+		"while [ $# -gt 0 ]; do\n" +
+		"  if [ \"$1\" = \"-g\" ]; then\n" +
+		"    echo 'glob mode disallowed' >&2\n" +
+		"    exit 3\n" +
+		"  fi\n" +
+		"  if [ \"$1\" = \"-r\" ]; then\n" +
+		"    seen_r=1\n" +
+		"    shift\n" +
+		"    if [ $# -eq 0 ]; then\n" +
+		"      echo 'missing regex argument' >&2\n" +
+		"      exit 4\n" +
+		"    fi\n" +
+		"    regex=\"$1\"\n" +
+		"  fi\n" +
+		"  shift\n" +
+		"done\n" +
+		"if [ \"$seen_r\" -ne 1 ]; then\n" +
+		"  echo 'missing -r mode' >&2\n" +
+		"  exit 5\n" +
+		"fi\n" +
+		"if [ \"$regex\" != '\\.go$' ]; then\n" +
+		"  echo \"unexpected regex: $regex\" >&2\n" +
+		"  exit 6\n" +
+		"fi\n" +
+		"state=\"$RIG_TEST_STATE\"\n" +
+		"ready=\"$RIG_TEST_READY\"\n" +
+		"watch=\"$RIG_TEST_WATCH\"\n" +
+		"mtime() { stat -c %Y \"$1\" 2>/dev/null || stat -f %m \"$1\"; }\n" +
+		"if [ ! -f \"$state\" ]; then\n" +
+		"  echo first > \"$state\"\n" +
+		"  if [ -n \"$ready\" ]; then echo ready > \"$ready\"; fi\n" +
+		"  base=\"$(mtime \"$watch\")\"\n" +
+		"  while true; do\n" +
+		"    now=\"$(mtime \"$watch\")\"\n" +
+		"    if [ \"$now\" != \"$base\" ]; then\n" +
+		"      exit 1\n" +
+		"    fi\n" +
+		"    sleep 0.05\n" +
+		"  done\n" +
+		"fi\n" +
+		"exit 0\n"
+
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", reflexStub)
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "dev", "--color=never")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "RIG_TEST_STATE="+state, "RIG_TEST_READY="+ready, "RIG_TEST_WATCH="+watchFile)
+	var buf bytes.Buffer
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start dev: %v", err)
+	}
+	defer func() { _ = cmd.Process.Signal(syscall.SIGTERM) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(ready); err == nil {
+			break
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+	if _, err := os.Stat(ready); err != nil {
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+		_ = cmd.Wait()
+		t.Fatalf("timeout waiting for reflex ready; output so far: %s", buf.String())
+	}
+
+	// Ensure we cross a second boundary before rewriting (stub uses seconds mtime).
+	time.Sleep(1200 * time.Millisecond)
+	writeFile(t, watchFile, "package main\n// changed\n", 0o644)
+
+	waitCh := make(chan error, 1)
+	go func() { waitCh <- cmd.Wait() }()
+	select {
+	case err := <-waitCh:
+		if err != nil {
+			t.Fatalf("expected clean exit, got: %v\noutput=%s", err, buf.String())
+		}
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+		<-waitCh
+		t.Fatalf("timeout waiting for dev to exit; output so far: %s", buf.String())
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "🔁 change detected") {
+		t.Fatalf("expected change detected log, got: %s", out)
+	}
+	if !strings.Contains(out, "▶ restarting…") {
+		t.Fatalf("expected restarting log, got: %s", out)
+	}
+}
+
+func TestDevCtrlCExitsWithoutRestart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "./ok"
+watch = ["**/*.go"]
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nsleep 2\n", 0o755)
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\ntrap 'exit 0' INT TERM\nsleep 5\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "dev", "--color=never")
+	cmd.Dir = dir
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		t.Fatalf("start pty: %v", err)
+	}
+	defer func() { _ = ptmx.Close() }()
+
+	_ = readUntilContains(t, ptmx, "🚀 dev started", 2*time.Second)
+	_, _ = ptmx.Write([]byte{0x03})
+	out := readUntilContains(t, ptmx, "🛑 dev stopped", 2*time.Second)
+
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("expected clean exit, got: %v", err)
+	}
+	if strings.Contains(out, "▶ restarting…") || strings.Contains(out, "🔁 change detected") {
+		t.Fatalf("unexpected restart after Ctrl+C: %s", out)
+	}
+}
+
+func TestDevCtrlRTriggersRestart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	lockFile := filepath.Join(dir, "child.lock")
+	parallel := filepath.Join(dir, "parallel")
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "./ok"
+watch = ["**/*.go"]
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\nlock=\"$RIG_TEST_LOCK\"\nparallel=\"$RIG_TEST_PARALLEL\"\nif [ -f \"$lock\" ]; then\n  echo parallel > \"$parallel\"\n  exit 42\nfi\necho $$ > \"$lock\"\ntrap 'rm -f \"$lock\"; exit 0' INT TERM\nsleep 10\nrm -f \"$lock\"\nexit 0\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "dev", "--color=never")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "RIG_TEST_LOCK="+lockFile, "RIG_TEST_PARALLEL="+parallel)
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		t.Fatalf("start pty: %v", err)
+	}
+	defer func() { _ = ptmx.Close() }()
+
+	_ = readUntilContains(t, ptmx, "🚀 dev started", 2*time.Second)
+	_, _ = ptmx.Write([]byte{0x12})
+	out := readUntilContains(t, ptmx, "🔄 manual reload", 2*time.Second)
+	if !strings.Contains(out, "▶ restarting…") {
+		_ = readUntilContains(t, ptmx, "▶ restarting…", 2*time.Second)
+	}
+
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+	_ = cmd.Wait()
+
+	if _, err := os.Stat(parallel); err == nil {
+		t.Fatalf("detected parallel child process")
+	}
+}
+
+func TestDevSigtermStopsCleanly(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "./ok"
+watch = ["**/*.go"]
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\ntrap 'exit 0' INT TERM\nsleep 2\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "dev", "--color=never")
+	cmd.Dir = dir
+	var buf strings.Builder
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start dev: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+	_ = cmd.Wait()
+	if !strings.Contains(buf.String(), "🛑 dev stopped") {
+		t.Fatalf("expected stop log, got: %s", buf.String())
+	}
+}
+
+func TestDevNoColorWhenNotTTY(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "./ok"
+watch = ["**/*.go"]
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\nexit 0\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "dev", "--color=always")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("expected dev success, got error: %v\n%s", err, out)
+	}
+	if strings.Contains(string(out), "\x1b[") {
+		t.Fatalf("expected no ANSI color in non-TTY output, got: %q", string(out))
+	}
+}
+
+func TestDevStopsAfterMaxRestarts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "./ok"
+watch = ["**/*.go"]
+max_restarts = 3
+restart_window = "1m"
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\necho \"boom $$\" >&2\nexit 3\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "dev")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected dev to fail after repeated crashes, got success:\n%s", out)
+	}
+	s := string(out)
+	if got := strings.Count(s, "▶ restarting"); got != 2 {
+		t.Fatalf("expected 2 restarts before giving up, got %d:\n%s", got, s)
+	}
+	for _, want := range []string{"3 failed starts within 1m0s", "--- stderr of last attempt ---", "keeps crashing"} {
+		if !strings.Contains(s, want) {
+			t.Fatalf("expected %q in output:\n%s", want, s)
+		}
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to read while a child process writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDevTestOnSaveRunsChangedPackage(t *testing.T) {
+	if runtime.GOOS == "windows" {
 		t.Skip("shell-script based test")
 	}
 	dir := t.TempDir()
 	writeFile(t, filepath.Join(dir, "rig.toml"), `
-[tools]
-reflex = "latest"
+[tasks.dev]
+command = "exec sleep 30"
+watch = ["web"]
+watch_mode = "poll"
+poll_interval = "100ms"
+test_on_save = true
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+	writeFile(t, filepath.Join(dir, "pkg", "a.go"), "package pkg\n", 0o644)
+	// A fake go records its arguments and fails for the bad package.
+	fakeBin := t.TempDir()
+	writeFile(t, filepath.Join(fakeBin, "go"), "#!/bin/sh\necho \"$@\" >> \"$RIG_TEST_GO_LOG\"\ncase \"$*\" in *bad*) echo '--- FAIL: TestBad (0.00s)'; exit 1;; esac\n", 0o755)
+	goLog := filepath.Join(dir, "go.log")
+
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "dev", "--color=never")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "PATH="+fakeBin+string(os.PathListSeparator)+os.Getenv("PATH"), "RIG_TEST_GO_LOG="+goLog)
+	var buf syncBuffer
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start dev: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+		_ = cmd.Wait()
+	}()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if strings.Contains(buf.String(), want) {
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("timeout waiting for %q; output so far: %s", want, buf.String())
+	}
+	waitFor("dev started")
+	time.Sleep(300 * time.Millisecond)
+	writeFile(t, filepath.Join(dir, "pkg", "a.go"), "package pkg\n// changed\n", 0o644)
+	waitFor("🧪 ok   ./pkg/...")
+	writeFile(t, filepath.Join(dir, "bad", "b.go"), "package bad\n", 0o644)
+	waitFor("🧪 FAIL ./bad/...")
+	if !strings.Contains(buf.String(), "TestBad") {
+		t.Fatalf("expected failing test name in status line, got: %s", buf.String())
+	}
+	// The dev command only watches web/, so Go edits must not restart it.
+	if strings.Contains(buf.String(), "restarting") {
+		t.Fatalf("test-on-save must not restart the dev command: %s", buf.String())
+	}
+}
 
+func TestDevStatusEndpoint(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
 [tasks.dev]
-command = "./ok"
-watch = ["**/*.go"]
-description = "nope"
+command = "echo hello from dev; exec sleep 30"
+watch = ["web"]
+watch_mode = "poll"
+poll_interval = "100ms"
 `, 0o644)
-	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
+	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+	writeFile(t, filepath.Join(dir, "web", "index.html"), "v1\n", 0o644)
 
-	// Even with a valid lock + installed reflex, strict parsing must reject extra fields.
-	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\nexit 0\n")
-	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "dev", "--color=never", "--status-addr", "127.0.0.1:0")
+	cmd.Dir = dir
+	var buf syncBuffer
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start dev: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+		_ = cmd.Wait()
+	}()
+
+	status := func() core.DevStatusReport {
+		t.Helper()
+		var url string
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if _, rest, ok := strings.Cut(line, "📡 status: "); ok {
+				url = strings.TrimSpace(rest)
+			}
+		}
+		if url == "" {
+			return core.DevStatusReport{}
+		}
+		resp, err := http.Get(url)
+		if err != nil {
+			return core.DevStatusReport{}
+		}
+		defer resp.Body.Close()
+		var rep core.DevStatusReport
+		_ = stdjson.NewDecoder(resp.Body).Decode(&rep)
+		return rep
+	}
+	waitFor := func(desc string, ok func(core.DevProcessStatus) bool) core.DevProcessStatus {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if rep := status(); len(rep.Processes) == 1 && ok(rep.Processes[0]) {
+				return rep.Processes[0]
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("timeout waiting for %s; last status %+v; output: %s", desc, status(), buf.String())
+		return core.DevProcessStatus{}
+	}
 
-	out, err := runRigCmdInDir(t, dir, "dev")
+	p := waitFor("running", func(p core.DevProcessStatus) bool {
+		return p.State == core.DevStateRunning && slices.Contains(p.Logs, "hello from dev")
+	})
+	if p.Name != "dev" || p.PID == 0 || p.Restarts != 0 {
+		t.Fatalf("unexpected status: %+v", p)
+	}
+	writeFile(t, filepath.Join(dir, "web", "index.html"), "v2\n", 0o644)
+	p = waitFor("a restart", func(p core.DevProcessStatus) bool {
+		return p.Restarts == 1 && p.State == core.DevStateRunning
+	})
+	if p.LastReason != "change" {
+		t.Fatalf("last_restart_reason=%q, want change", p.LastReason)
+	}
+
+	if out, err := runRigCmdInDir(t, dir, "dev", "reload"); err != nil || !strings.Contains(out, "reload requested") {
+		t.Fatalf("rig dev reload: %v\n%s", err, out)
+	}
+	p = waitFor("a reload", func(p core.DevProcessStatus) bool {
+		return p.Restarts == 2 && p.State == core.DevStateRunning
+	})
+	if p.LastReason != "reload" {
+		t.Fatalf("last_restart_reason=%q, want reload", p.LastReason)
+	}
+	if !strings.Contains(buf.String(), "🔄 manual reload") {
+		t.Fatalf("expected manual reload line, got: %s", buf.String())
+	}
+
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+	_ = cmd.Wait()
+	if _, err := os.Stat(filepath.Join(dir, ".rig", "dev.addr")); !os.IsNotExist(err) {
+		t.Fatalf(".rig/dev.addr should be removed when dev stops, got %v", err)
+	}
+	if out, err := runRigCmdInDir(t, dir, "dev", "reload"); err == nil || !strings.Contains(out, "rig dev is not running") {
+		t.Fatalf("expected not-running error, got %v\n%s", err, out)
+	}
+}
+
+func TestEmojiAbsentOutsideDevAndJSONUnaffected(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[project]\nname = \"t\"\nversion = \"0.0.0\"\n", 0o644)
+	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "check")
+	if err != nil {
+		t.Fatalf("expected check success, got error: %v\n%s", err, out)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(out), "{") {
+		t.Fatalf("expected JSON output, got: %s", out)
+	}
+	if strings.Contains(out, "🚀") || strings.Contains(out, "🔁") || strings.Contains(out, "🛑") {
+		t.Fatalf("unexpected emoji outside dev: %s", out)
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Fatalf("unexpected ANSI in JSON output: %q", out)
+	}
+}
+
+func TestRunNoGoModFailsCleanly(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks]
+test = "go test ./..."
+`, 0o644)
+	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "run", "test")
 	if err == nil {
 		t.Fatalf("expected error, got none. output=%s", out)
 	}
-	if !strings.Contains(out, "unsupported field") {
-		t.Fatalf("expected unsupported field error, got: %s", out)
+	// Ensure we failed due to Go/module state, not due to rig tool sync state.
+	if strings.Contains(out, "tools are out of sync") {
+		t.Fatalf("unexpected tooling-state error; got: %s", out)
+	}
+	outLower := strings.ToLower(out)
+	if !strings.Contains(outLower, "go.mod") && !strings.Contains(outLower, "main module") {
+		t.Fatalf("expected output to mention go.mod/module state, got: %s", out)
 	}
 }
 
-func TestDevRestartsOnGoFileChange(t *testing.T) {
+func TestCacheStatsAndCleanUseConfiguredGoCache(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/cachetest\n\ngo 1.25\n", 0o644)
+	writeFile(t, filepath.Join(dir, "a.go"), "package cachetest\n\nfunc A() int { return 1 }\n", 0o644)
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[cache]\ngo = \".rig/cache/go\"\n", 0o644)
+	gocache := filepath.Join(dir, ".rig", "cache", "go")
+
+	out, err := runRigCmdInDir(t, dir, "cache", "stats", "--go")
+	if err != nil {
+		t.Fatalf("rig cache stats --go failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{gocache + " (from [cache].go)", "0 cached, 1 compiled"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	out, err = runRigCmdInDir(t, dir, "cache", "stats", "--go")
+	if err != nil || !strings.Contains(out, "1 cached, 0 compiled (100% hit rate)") {
+		t.Fatalf("expected a cache hit on the second run, got err=%v\n%s", err, out)
+	}
+
+	out, err = runRigCmdInDir(t, dir, "cache", "--clean-go-cache")
+	if err != nil || !strings.Contains(out, "cleaned Go build cache "+gocache) {
+		t.Fatalf("rig cache --clean-go-cache: err=%v\n%s", err, out)
+	}
+	out, err = runRigCmdInDir(t, dir, "cache", "stats", "--go")
+	if err != nil || !strings.Contains(out, "0 cached, 1 compiled") {
+		t.Fatalf("expected a miss after cleaning, got err=%v\n%s", err, out)
+	}
+}
+
+func TestCheckAndDoctorReportStaleBins(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("shell-script based test")
 	}
 	dir := t.TempDir()
-	state := filepath.Join(dir, "state")
-	ready := filepath.Join(dir, "ready")
-	watchFile := filepath.Join(dir, "watchme.go")
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tools]\nreflex = \"latest\"\n", 0o644)
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+	writeTool(t, dir, "leftover", "#!/bin/sh\n")
+
+	out, err := runRigCmdInDir(t, dir, "check")
+	if err != nil {
+		t.Fatalf("orphans alone should not fail check: %v\n%s", err, out)
+	}
+	for _, want := range []string{`"staleBins":[{"bin":"leftover","reason":"orphaned`, "⚠️  .rig/bin/leftover: orphaned", "rig sync --prune"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	out, _ = runRigCmdInDir(t, dir, "doctor")
+	if !strings.Contains(out, "stale_bin_leftover: orphaned") {
+		t.Fatalf("expected stale_bin line from doctor:\n%s", out)
+	}
+}
+
+func TestDynamicCompletionForAllShells(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `[tasks]
+lint = { command = "echo lint", description = "Run linters" }
+greet = { command = "echo hi", inputs = [{ name = "who", prompt = "Who?" }] }
 
-	writeFile(t, watchFile, "package main\n", 0o644)
-	writeFile(t, filepath.Join(dir, "rig.toml"), `
 [tools]
-reflex = "latest"
+golangci-lint = "v1.60.0"
 
-[tasks.dev]
-command = "./ok"
-watch = ["**/*.go"]
+[profile.race]
+tags = ["race"]
 `, 0o644)
-	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
 
-	reflexStub := "#!/bin/sh\n" +
-		"seen_r=0\n" +
-		"regex=\"\"\n" +
-		"while [ $# -gt 0 ]; do\n" +
-		"  if [ \"$1\" = \"-g\" ]; then\n" +
-		"    echo 'glob mode disallowed' >&2\n" +
-		"    exit 3\n" +
-		"  fi\n" +
-		"  if [ \"$1\" = \"-r\" ]; then\n" +
-		"    seen_r=1\n" +
-		"    shift\n" +
-		"    if [ $# -eq 0 ]; then\n" +
-		"      echo 'missing regex argument' >&2\n" +
-		"      exit 4\n" +
-		"    fi\n" +
-		"    regex=\"$1\"\n" +
-		"  fi\n" +
-		"  shift\n" +
-		"done\n" +
-		"if [ \"$seen_r\" -ne 1 ]; then\n" +
-		"  echo 'missing -r mode' >&2\n" +
-		"  exit 5\n" +
-		"fi\n" +
-		"if [ \"$regex\" != '\\.go$' ]; then\n" +
-		"  echo \"unexpected regex: $regex\" >&2\n" +
-		"  exit 6\n" +
-		"fi\n" +
-		"state=\"$RIG_TEST_STATE\"\n" +
-		"ready=\"$RIG_TEST_READY\"\n" +
-		"watch=\"$RIG_TEST_WATCH\"\n" +
-		"mtime() { stat -c %Y \"$1\" 2>/dev/null || stat -f %m \"$1\"; }\n" +
-		"if [ ! -f \"$state\" ]; then\n" +
-		"  echo first > \"$state\"\n" +
-		"  if [ -n \"$ready\" ]; then echo ready > \"$ready\"; fi\n" +
-		"  base=\"$(mtime \"$watch\")\"\n" +
-		"  while true; do\n" +
-		"    now=\"$(mtime \"$watch\")\"\n" +
-		"    if [ \"$now\" != \"$base\" ]; then\n" +
-		"      exit 1\n" +
-		"    fi\n" +
-		"    sleep 0.05\n" +
-		"  done\n" +
-		"fi\n" +
-		"exit 0\n"
+	cases := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"__complete", "run", ""}, []string{"greet\n", "lint\tRun linters"}},
+		{[]string{"__complete", "run", "greet", "--input", ""}, []string{"who=\tWho?"}},
+		{[]string{"__complete", "test", "--profile", ""}, []string{"race\ttags: race"}},
+		{[]string{"__complete", "build", "--profile", "r"}, []string{"race"}},
+		{[]string{"__complete", "x", "golang"}, []string{"golangci-lint\tv1.60.0"}},
+	}
+	for _, tc := range cases {
+		out, err := runRigCmdInDir(t, dir, tc.args...)
+		if err != nil {
+			t.Fatalf("%v: %v\n%s", tc.args, err, out)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(out, want) {
+				t.Fatalf("%v: expected %q in:\n%s", tc.args, want, out)
+			}
+		}
+	}
 
-	reflexPath, reflexSHA := writeTool(t, dir, "reflex", reflexStub)
+	// fish and PowerShell scripts resolve candidates through the same
+	// __complete entry point as bash and zsh.
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		out, err := runRigCmdInDir(t, dir, "completion", shell)
+		if err != nil {
+			t.Fatalf("completion %s: %v", shell, err)
+		}
+		if !strings.Contains(out, "__complete") {
+			t.Fatalf("completion %s does not use __complete:\n%s", shell, out)
+		}
+	}
+}
+
+func TestDidYouMeanForTasksAndTools(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tasks]\nbuild = \"echo build\"\ntest = \"echo test\"\n\n[tools]\nreflex = \"latest\"\n", 0o644)
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\n")
 	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
 
-	bin := buildRigBinary(t, t.TempDir())
-	cmd := exec.Command(bin, "dev", "--color=never")
-	cmd.Dir = dir
-	cmd.Env = append(os.Environ(), "RIG_TEST_STATE="+state, "RIG_TEST_READY="+ready, "RIG_TEST_WATCH="+watchFile)
-	var buf bytes.Buffer
-	cmd.Stdout = &buf
-	cmd.Stderr = &buf
+	out, err := runRigCmdInDir(t, dir, "run", "biuld")
+	if err == nil || !strings.Contains(out, `task "biuld" not found; did you mean "build"?`) {
+		t.Fatalf("expected task suggestion, got err=%v\n%s", err, out)
+	}
+
+	out, err = runRigCmdInDir(t, dir, "x", "reflx")
+	if err == nil || !strings.Contains(out, `did you mean "reflex"?`) {
+		t.Fatalf("expected tool suggestion from rig x, got err=%v\n%s", err, out)
+	}
+
+	out, err = runRigCmdInDir(t, dir, "tools", "why", "reflx")
+	if err == nil || !strings.Contains(out, `tool "reflx" not found in rig.lock; did you mean "reflex"?`) {
+		t.Fatalf("expected tool suggestion from tools why, got err=%v\n%s", err, out)
+	}
+}
+
+func TestErrorCodesAndExplain(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tasks]\nbuild = \"echo build\"\n", 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "run", "build")
+	if err == nil || !strings.Contains(out, "Error [RIG001]: rig.lock required") || !strings.Contains(out, "rig explain RIG001") {
+		t.Fatalf("expected RIG001 for missing rig.lock, got err=%v\n%s", err, out)
+	}
+
+	out, err = runRigCmdInDir(t, dir, "explain", "RIG001")
+	if err != nil {
+		t.Fatalf("explain failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"RIG001: rig.lock missing", "Cause:", "Resolution:", "Related commands:", "rig sync"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in explain output:\n%s", want, out)
+		}
+	}
+
+	out, err = runRigCmdInDir(t, dir, "explain")
+	if err != nil || !strings.Contains(out, "RIG014  tool binary sha256 does not match rig.lock") {
+		t.Fatalf("expected code listing, got err=%v\n%s", err, out)
+	}
+
+	if out, err := runRigCmdInDir(t, dir, "explain", "RIG999"); err == nil {
+		t.Fatalf("expected unknown code to fail:\n%s", out)
+	}
+}
+
+func TestScheduleList(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tasks]\ncodegen = \"echo gen\"\n\n[schedules]\ncodegen = \"*/15 * * * *\"\n", 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "schedule", "--list")
+	if err != nil || !strings.Contains(out, "codegen") || !strings.Contains(out, "*/15 * * * *") || !strings.Contains(out, "next ") {
+		t.Fatalf("expected schedule listing, got err=%v\n%s", err, out)
+	}
+
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tasks]\ncodegen = \"echo gen\"\n\n[schedules]\ncodegen = \"61 * * * *\"\n", 0o644)
+	if out, err := runRigCmdInDir(t, dir, "schedule", "--list"); err == nil || !strings.Contains(out, "schedules.codegen") {
+		t.Fatalf("expected invalid schedule error, got err=%v\n%s", err, out)
+	}
+}
+
+func TestIncludeConflictsAndAllowOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), "include = [\"extra.toml\"]\n\n[tasks]\nhello = \"echo base\"\n", 0o644)
+	writeFile(t, filepath.Join(dir, "extra.toml"), "[tasks]\nhello = \"echo extra\"\n", 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "run", "--list")
+	if err == nil || !strings.Contains(out, "tasks.hello is defined in both rig.toml and extra.toml") {
+		t.Fatalf("expected conflict error, got err=%v\n%s", err, out)
+	}
+
+	out, err = runRigCmdInDir(t, dir, "run", "--list", "--allow-override")
+	if err != nil || !strings.Contains(out, "⚠️  tasks.hello is defined in both rig.toml and extra.toml; the later file wins") {
+		t.Fatalf("expected override warning, got err=%v\n%s", err, out)
+	}
+}
+
+func TestToolsLsTableJSONAndStatusFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tools]\nreflex = \"latest\"\nmockery = \"latest\"\n", 0o644)
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\n")
+	mockeryPath, mockerySHA := writeTool(t, dir, "mockery", "#!/bin/sh\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA), lockToolEntry("mockery", mockeryPath, mockerySHA)})
+	if err := os.Remove(mockeryPath); err != nil {
+		t.Fatal(err)
+	}
 
-	if err := cmd.Start(); err != nil {
-		t.Fatalf("start dev: %v", err)
+	out, err := runRigCmdInDir(t, dir, "tools", "ls")
+	if err != nil || !strings.Contains(out, "NAME") || !strings.Contains(out, "STATUS") {
+		t.Fatalf("expected table header, got err=%v\n%s", err, out)
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "mockery ") || !strings.Contains(lines[1], " missing ") || !strings.HasPrefix(lines[2], "reflex ") {
+		t.Fatalf("unexpected table:\n%s", out)
+	}
+	if strings.Index(lines[0], "STATUS") != strings.Index(lines[1], "missing") {
+		t.Fatalf("expected aligned columns:\n%s", out)
 	}
-	defer func() { _ = cmd.Process.Signal(syscall.SIGTERM) }()
 
-	deadline := time.Now().Add(2 * time.Second)
-	for time.Now().Before(deadline) {
-		if _, err := os.Stat(ready); err == nil {
-			break
-		}
-		time.Sleep(25 * time.Millisecond)
+	out, err = runRigCmdInDir(t, dir, "tools", "ls", "--json", "--status", "missing")
+	if err != nil {
+		t.Fatalf("tools ls --json failed: %v\n%s", err, out)
 	}
-	if _, err := os.Stat(ready); err != nil {
-		_ = cmd.Process.Signal(syscall.SIGTERM)
-		_ = cmd.Wait()
-		t.Fatalf("timeout waiting for reflex ready; output so far: %s", buf.String())
+	var items []map[string]string
+	if err := stdjson.Unmarshal([]byte(out), &items); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(items) != 1 || items[0]["name"] != "mockery" || items[0]["status"] != "missing" || items[0]["path"] != mockeryPath {
+		t.Fatalf("unexpected filtered JSON: %#v", items)
 	}
 
-	// Ensure we cross a second boundary before rewriting (stub uses seconds mtime).
-	time.Sleep(1200 * time.Millisecond)
-	writeFile(t, watchFile, "package main\n// changed\n", 0o644)
+	if out, err := runRigCmdInDir(t, dir, "tools", "ls", "--status", "broken"); err == nil || !strings.Contains(out, "invalid --status") {
+		t.Fatalf("expected invalid status error, got err=%v\n%s", err, out)
+	}
+}
 
-	waitCh := make(chan error, 1)
-	go func() { waitCh <- cmd.Wait() }()
-	select {
-	case err := <-waitCh:
-		if err != nil {
-			t.Fatalf("expected clean exit, got: %v\noutput=%s", err, buf.String())
-		}
-	case <-time.After(5 * time.Second):
-		_ = cmd.Process.Signal(syscall.SIGTERM)
-		<-waitCh
-		t.Fatalf("timeout waiting for dev to exit; output so far: %s", buf.String())
+func TestToolsDoctorJSONRemedies(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tools]\nreflex = \"latest\"\nmockery = \"latest\"\n", 0o644)
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\n")
+	mockeryPath, mockerySHA := writeTool(t, dir, "mockery", "#!/bin/sh\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA), lockToolEntry("mockery", mockeryPath, mockerySHA)})
+	if err := os.Remove(mockeryPath); err != nil {
+		t.Fatal(err)
 	}
 
-	out := buf.String()
-	if !strings.Contains(out, "🔁 change detected") {
-		t.Fatalf("expected change detected log, got: %s", out)
+	out, err := runRigCmdInDir(t, dir, "tools", "doctor", "--json")
+	if err != nil {
+		t.Fatalf("tools doctor --json failed: %v\n%s", err, out)
 	}
-	if !strings.Contains(out, "▶ restarting…") {
-		t.Fatalf("expected restarting log, got: %s", out)
+	var reports []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+		Remedy string `json:"remedy"`
+		Probe  struct {
+			Status string `json:"status"`
+		} `json:"version_probe"`
+	}
+	if err := stdjson.Unmarshal([]byte(out), &reports); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(reports) != 2 || reports[0].Name != "mockery" || reports[0].Status != "missing" || reports[0].Remedy != "rig sync" || reports[0].Probe.Status != "skipped" {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+	if reports[1].Name != "reflex" || reports[1].Status != "ok" || reports[1].Remedy != "" {
+		t.Fatalf("unexpected reflex report: %+v", reports[1])
+	}
+
+	out, err = runRigCmdInDir(t, dir, "tools", "doctor", "mockery")
+	if err != nil || !strings.Contains(out, "remedy: rig sync\n") {
+		t.Fatalf("expected remedy line, got err=%v\n%s", err, out)
 	}
 }
 
-func TestDevCtrlCExitsWithoutRestart(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("shell-script based test")
+func TestLogsReplaysTaskLog(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[project]\nname = \"demo\"\n\n[tasks]\ndev = \"go run .\"\n", 0o644)
+	writeFile(t, filepath.Join(dir, ".rig", "logs", "dev.log"), strings.Join([]string{
+		"2026-01-01T10:00:00Z [dev#1] listening on :8080",
+		"2026-01-01T10:00:05Z [rig] ♻️  restarting (change)",
+		"2026-01-01T10:00:06Z [dev#2:err] panic: boom",
+	}, "\n")+"\n", 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "logs", "dev", "--tail", "2")
+	if err != nil {
+		t.Fatalf("rig logs failed: %v\n%s", err, out)
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "rig | ♻️  restarting (change)") || !strings.HasSuffix(lines[1], "dev#2:err | panic: boom") {
+		t.Fatalf("unexpected logs output:\n%s", out)
+	}
+
+	out, err = runRigCmdInDir(t, dir, "logs", "api")
+	if err == nil || !strings.Contains(out, `no log for "api"`) || !strings.Contains(out, "have: dev") {
+		t.Fatalf("expected missing log error, got err=%v\n%s", err, out)
 	}
+	if out, err := runRigCmdInDir(t, dir, "logs", "dev", "--since", "yesterday"); err == nil || !strings.Contains(out, "invalid --since") {
+		t.Fatalf("expected invalid --since error, got err=%v\n%s", err, out)
+	}
+}
+
+func TestPathsOverrideBinAndCache(t *testing.T) {
 	dir := t.TempDir()
-	writeFile(t, filepath.Join(dir, "rig.toml"), `
+	cache := filepath.Join(t.TempDir(), "rig-cache")
+	writeFile(t, filepath.Join(dir, "rig.toml"), fmt.Sprintf(`
 [tools]
-reflex = "latest"
+mockery = "2.0.0"
 
-[tasks.dev]
-command = "./ok"
-watch = ["**/*.go"]
-`, 0o644)
-	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nsleep 2\n", 0o755)
-	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\ntrap 'exit 0' INT TERM\nsleep 5\n")
-	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+[tasks]
+ver = "mockery --version"
 
-	bin := buildRigBinary(t, t.TempDir())
-	cmd := exec.Command(bin, "dev", "--color=never")
-	cmd.Dir = dir
-	ptmx, err := pty.Start(cmd)
+[paths]
+bin = ".tooling/bin"
+cache = %q
+`, cache), 0o644)
+	bin := filepath.Join(dir, ".tooling", "bin", "mockery")
+	writeFile(t, bin, "#!/bin/sh\necho mockery v2.0.0\n", 0o755)
+	sha, err := core.ComputeFileSHA256(bin)
 	if err != nil {
-		t.Fatalf("start pty: %v", err)
+		t.Fatalf("sha256 mockery: %v", err)
 	}
-	defer func() { _ = ptmx.Close() }()
+	writeFile(t, filepath.Join(dir, "rig.lock"), fmt.Sprintf(`schema = 0
 
-	_ = readUntilContains(t, ptmx, "🚀 dev started", 2*time.Second)
-	_, _ = ptmx.Write([]byte{0x03})
-	out := readUntilContains(t, ptmx, "🛑 dev stopped", 2*time.Second)
+[[tools]]
+kind = "go-binary"
+requested = "mockery@2.0.0"
+resolved = "github.com/vektra/mockery/v2@v2.0.0"
+module = "github.com/vektra/mockery/v2"
+bin = "mockery"
+sha256 = %q
+`, sha), 0o644)
 
-	if err := cmd.Wait(); err != nil {
-		t.Fatalf("expected clean exit, got: %v", err)
+	if out, err := runRigCmdInDir(t, dir, "check"); err != nil || !strings.Contains(out, "\"ok\":true") {
+		t.Fatalf("check should find the tool in .tooling/bin: err=%v\n%s", err, out)
 	}
-	if strings.Contains(out, "▶ restarting…") || strings.Contains(out, "🔁 change detected") {
-		t.Fatalf("unexpected restart after Ctrl+C: %s", out)
+	out, err := runRigCmdInDir(t, dir, "run", "ver")
+	if err != nil || !strings.Contains(out, "mockery v2.0.0") {
+		t.Fatalf("run should put .tooling/bin on PATH: err=%v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(cache, "history.json")); err != nil {
+		t.Fatalf("expected run history under [paths].cache: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".rig")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing written to .rig, stat err=%v", err)
 	}
 }
 
-func TestDevCtrlRTriggersRestart(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("shell-script based test")
-	}
+func TestRunHermeticEnvAllowlist(t *testing.T) {
 	dir := t.TempDir()
-	lockFile := filepath.Join(dir, "child.lock")
-	parallel := filepath.Join(dir, "parallel")
+	writeFile(t, filepath.Join(dir, "show"), "#!/bin/sh\necho \"home=$HOME secret=$MY_SECRET own=$OWN\"\n", 0o755)
 	writeFile(t, filepath.Join(dir, "rig.toml"), `
-[tools]
-reflex = "latest"
+[tasks]
+show = { command = "./show", env = { OWN = "declared" } }
 
-[tasks.dev]
-command = "./ok"
-watch = ["**/*.go"]
+[ci]
+env_allowlist = ["HOME", "PATH"]
 `, 0o644)
-	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
-	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\nlock=\"$RIG_TEST_LOCK\"\nparallel=\"$RIG_TEST_PARALLEL\"\nif [ -f \"$lock\" ]; then\n  echo parallel > \"$parallel\"\n  exit 42\nfi\necho $$ > \"$lock\"\ntrap 'rm -f \"$lock\"; exit 0' INT TERM\nsleep 10\nrm -f \"$lock\"\nexit 0\n")
-	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
 
-	bin := buildRigBinary(t, t.TempDir())
-	cmd := exec.Command(bin, "dev", "--color=never")
-	cmd.Dir = dir
-	cmd.Env = append(os.Environ(), "RIG_TEST_LOCK="+lockFile, "RIG_TEST_PARALLEL="+parallel)
-	ptmx, err := pty.Start(cmd)
-	if err != nil {
-		t.Fatalf("start pty: %v", err)
+	base := append(os.Environ(), "MY_SECRET=leaked", "HOME=/home/dev")
+	out, err := runRigCmdInDirWithEnv(t, dir, append(slices.Clone(base), "CI="), "run", "show")
+	if err != nil || !strings.Contains(out, "home=/home/dev secret=leaked own=declared") {
+		t.Fatalf("outside CI the env should be untouched: err=%v\n%s", err, out)
 	}
-	defer func() { _ = ptmx.Close() }()
-
-	_ = readUntilContains(t, ptmx, "🚀 dev started", 2*time.Second)
-	_, _ = ptmx.Write([]byte{0x12})
-	out := readUntilContains(t, ptmx, "🔄 manual reload", 2*time.Second)
-	if !strings.Contains(out, "▶ restarting…") {
-		_ = readUntilContains(t, ptmx, "▶ restarting…", 2*time.Second)
+	out, err = runRigCmdInDirWithEnv(t, dir, append(slices.Clone(base), "CI=true"), "run", "show")
+	if err != nil || !strings.Contains(out, "home=/home/dev secret= own=declared") || !strings.Contains(out, "hermetic env") {
+		t.Fatalf("under CI only allowlisted variables should pass: err=%v\n%s", err, out)
+	}
+	out, err = runRigCmdInDirWithEnv(t, dir, append(slices.Clone(base), "CI="), "run", "--hermetic", "show")
+	if err != nil || !strings.Contains(out, "secret= own=declared") {
+		t.Fatalf("--hermetic should apply the allowlist locally: err=%v\n%s", err, out)
 	}
+}
 
-	_ = cmd.Process.Signal(syscall.SIGTERM)
-	_ = cmd.Wait()
+func TestExportComposeWritesAndChecks(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+go = "1.25.1"
 
-	if _, err := os.Stat(parallel); err == nil {
-		t.Fatalf("detected parallel child process")
+[tasks]
+api = { command = "go run ./cmd/api", ports = ["8080:8080"] }
+`, 0o644)
+	compose := filepath.Join(dir, "docker-compose.yml")
+	writeFile(t, compose, "services: {}\n", 0o644)
+	if out, err := runRigCmdInDir(t, dir, "export", "compose"); err == nil || !strings.Contains(out, "pass --force") {
+		t.Fatalf("expected a hand-written compose file to be kept: err=%v\n%s", err, out)
+	}
+	if out, err := runRigCmdInDir(t, dir, "export", "compose", "--force"); err != nil || !strings.Contains(out, "(1 service(s))") {
+		t.Fatalf("export compose --force: err=%v\n%s", err, out)
+	}
+	b, err := os.ReadFile(compose)
+	if err != nil || !strings.Contains(string(b), "  api:\n") || !strings.Contains(string(b), `- "8080:8080"`) {
+		t.Fatalf("unexpected compose file (err=%v):\n%s", err, b)
+	}
+	if out, err := runRigCmdInDir(t, dir, "export", "compose", "--check"); err != nil {
+		t.Fatalf("export compose --check after export: err=%v\n%s", err, out)
+	}
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+go = "1.25.1"
+
+[tasks]
+api = { command = "go run ./cmd/api", ports = ["9090:8080"] }
+`, 0o644)
+	if out, err := runRigCmdInDir(t, dir, "export", "compose", "--check"); err == nil || !strings.Contains(out, "out of sync") {
+		t.Fatalf("expected --check to catch the changed port: err=%v\n%s", err, out)
+	}
+	if out, err := runRigCmdInDir(t, dir, "export", "compose"); err != nil || !strings.Contains(out, "wrote docker-compose.yml") {
+		t.Fatalf("regenerating should not need --force: err=%v\n%s", err, out)
 	}
 }
 
-func TestDevSigtermStopsCleanly(t *testing.T) {
+func TestRunNormalizesOutputPermissions(t *testing.T) {
 	if runtime.GOOS == "windows" {
-		t.Skip("shell-script based test")
+		t.Skip("unix permissions")
 	}
 	dir := t.TempDir()
 	writeFile(t, filepath.Join(dir, "rig.toml"), `
-[tools]
-reflex = "latest"
-
-[tasks.dev]
-command = "./ok"
-watch = ["**/*.go"]
+[tasks]
+gen = { command = "sh -c 'mkdir -p dist && echo hi > dist/out.txt && chmod 600 dist/out.txt && chmod 700 dist'", outputs = ["dist"], output_umask = "022", output_owner = "project" }
 `, 0o644)
-	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
-	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\ntrap 'exit 0' INT TERM\nsleep 2\n")
-	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+	writeRigLock(t, dir, nil)
 
-	bin := buildRigBinary(t, t.TempDir())
-	cmd := exec.Command(bin, "dev", "--color=never")
-	cmd.Dir = dir
-	var buf strings.Builder
-	cmd.Stdout = &buf
-	cmd.Stderr = &buf
-	if err := cmd.Start(); err != nil {
-		t.Fatalf("start dev: %v", err)
+	out, err := runRigCmdInDir(t, dir, "run", "gen")
+	if err != nil || !strings.Contains(out, "🔒 gen: normalized 2 output(s) (umask 022, owner project)") {
+		t.Fatalf("rig run gen: err=%v\n%s", err, out)
 	}
-	time.Sleep(200 * time.Millisecond)
-	_ = cmd.Process.Signal(syscall.SIGTERM)
-	_ = cmd.Wait()
-	if !strings.Contains(buf.String(), "🛑 dev stopped") {
-		t.Fatalf("expected stop log, got: %s", buf.String())
+	for rel, want := range map[string]os.FileMode{"dist": 0o755, "dist/out.txt": 0o644} {
+		info, err := os.Stat(filepath.Join(dir, rel))
+		if err != nil || info.Mode().Perm() != want {
+			t.Fatalf("%s: %v (err=%v), want %v", rel, info, err, want)
+		}
 	}
 }
 
-func TestDevNoColorWhenNotTTY(t *testing.T) {
+func TestRunTimeoutCancelsRun(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("shell-script based test")
 	}
 	dir := t.TempDir()
 	writeFile(t, filepath.Join(dir, "rig.toml"), `
-[tools]
-reflex = "latest"
-
-[tasks.dev]
-command = "./ok"
-watch = ["**/*.go"]
+[tasks]
+slow = "sleep 30"
 `, 0o644)
-	writeFile(t, filepath.Join(dir, "ok"), "#!/bin/sh\nexit 0\n", 0o755)
-	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\nexit 0\n")
-	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+	writeRigLock(t, dir, nil)
+	start := time.Now()
+	out, err := runRigCmdInDir(t, dir, "run", "slow", "--timeout", "300ms")
+	if err == nil || !strings.Contains(out, "run canceled: --timeout 300ms exceeded") {
+		t.Fatalf("expected the run to time out, got %v\n%s", err, out)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Second {
+		t.Fatalf("sleep was not interrupted (took %s)", elapsed)
+	}
+}
 
-	bin := buildRigBinary(t, t.TempDir())
-	cmd := exec.Command(bin, "dev", "--color=always")
-	cmd.Dir = dir
-	out, err := cmd.CombinedOutput()
+func TestRigEnvShowsSources(t *testing.T) {
+	work := t.TempDir()
+	writeFile(t, filepath.Join(work, "rig.toml"), "[paths]\nbin = \"tools/bin\"\n", 0o644)
+	env := append(os.Environ(), "RIG_HEARTBEAT=2m", "RIG_USER_CONFIG="+filepath.Join(work, "user.toml"), "NO_COLOR=1")
+
+	out, err := runRigCmdInDirWithEnv(t, work, env, "env")
 	if err != nil {
-		t.Fatalf("expected dev success, got error: %v\n%s", err, out)
+		t.Fatalf("rig env failed: %v\n%s", err, out)
 	}
-	if strings.Contains(string(out), "\x1b[") {
-		t.Fatalf("expected no ANSI color in non-TTY output, got: %q", string(out))
+	for _, want := range []string{"RIG_HEARTBEAT", "2m (environment)", filepath.Join(work, "tools", "bin") + " (rig, for tool installs: [paths].bin)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("rig env output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "RIG_AUTO_SWITCH") {
+		t.Fatalf("rig env without --describe should skip unset variables:\n%s", out)
+	}
+
+	out, err = runRigCmdInDirWithEnv(t, work, env, "env", "--describe")
+	if err != nil {
+		t.Fatalf("rig env --describe failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "RIG_AUTO_SWITCH") || !strings.Contains(out, "(unset)") || !strings.Contains(out, "rig sets it for tool installs.") {
+		t.Fatalf("rig env --describe should list every variable:\n%s", out)
 	}
 }
 
-func TestEmojiAbsentOutsideDevAndJSONUnaffected(t *testing.T) {
-	dir := t.TempDir()
-	writeFile(t, filepath.Join(dir, "rig.toml"), "[project]\nname = \"t\"\nversion = \"0.0.0\"\n", 0o644)
-	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+func TestRigEnvPathShowsResolution(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	work := t.TempDir()
+	writeFile(t, filepath.Join(work, "rig.toml"), "[tools]\nmockery = \"2.0.0\"\ngolangci-lint = \"1.59.0\"\n", 0o644)
+	writeFile(t, filepath.Join(work, ".rig", "bin", "mockery"), "#!/bin/sh\n", 0o755)
+	other := t.TempDir()
+	writeFile(t, filepath.Join(other, "mockery"), "#!/bin/sh\n", 0o755)
+	writeFile(t, filepath.Join(other, "golangci-lint"), "#!/bin/sh\n", 0o755)
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not on PATH")
+	}
+	env := []string{"PATH=" + strings.Join([]string{other, filepath.Dir(goBin), other}, string(os.PathListSeparator)), "HOME=" + work, "RIG_USER_CONFIG=" + filepath.Join(work, "user.toml"), "NO_COLOR=1"}
 
-	out, err := runRigCmdInDir(t, dir, "check")
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "env", "path", "--json")
+	cmd.Dir = work
+	cmd.Env = env
+	b, err := cmd.Output()
 	if err != nil {
-		t.Fatalf("expected check success, got error: %v\n%s", err, out)
+		t.Fatalf("rig env path --json failed: %v\n%s", err, b)
 	}
-	if !strings.HasPrefix(strings.TrimSpace(out), "{") {
-		t.Fatalf("expected JSON output, got: %s", out)
+	var rep core.PathReport
+	if err := stdjson.Unmarshal(b, &rep); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, b)
 	}
-	if strings.Contains(out, "🚀") || strings.Contains(out, "🔁") || strings.Contains(out, "🛑") {
-		t.Fatalf("unexpected emoji outside dev: %s", out)
+	if len(rep.Dirs) != 3 || rep.Dirs[0].Source != "rig" || rep.Dirs[1].Dir != other || len(rep.Dropped) != 1 {
+		t.Fatalf("unexpected PATH: %+v dropped=%v", rep.Dirs, rep.Dropped)
 	}
-	if strings.Contains(out, "\x1b[") {
-		t.Fatalf("unexpected ANSI in JSON output: %q", out)
+	tools := map[string]core.PathTool{}
+	for _, tool := range rep.Tools {
+		tools[tool.Name] = tool
+	}
+	if m := tools["mockery"]; !m.Managed || !m.FromBin || len(m.Shadowed) != 1 || m.Shadowed[0] != filepath.Join(other, "mockery") {
+		t.Fatalf("mockery should resolve from .rig/bin and shadow %s: %+v", other, m)
+	}
+	if g := tools["golangci-lint"]; !g.Managed || g.FromBin || g.Path != filepath.Join(other, "golangci-lint") {
+		t.Fatalf("golangci-lint should resolve outside .rig/bin: %+v", g)
+	}
+
+	cmd = exec.Command(bin, "env", "path")
+	cmd.Dir = work
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("rig env path failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"(rig tool bin)", "(duplicate, dropped)", "not the pinned copy in the tool bin; run 'rig sync'", "shadows " + filepath.Join(other, "mockery")} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("rig env path output missing %q:\n%s", want, out)
+		}
 	}
 }
 
-func TestRunNoGoModFailsCleanly(t *testing.T) {
+func TestLockSignAndVerifyWithEnvPinnedSigner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
 	dir := t.TempDir()
-	writeFile(t, filepath.Join(dir, "rig.toml"), `
-[tasks]
-test = "go test ./..."
-`, 0o644)
+	writeFile(t, filepath.Join(dir, "rig.toml"), "[tools]\nmockery = \"2.0.0\"\n", 0o644)
 	writeFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
-
-	out, err := runRigCmdInDir(t, dir, "run", "test")
-	if err == nil {
-		t.Fatalf("expected error, got none. output=%s", out)
+	fakeBin := t.TempDir()
+	writeFile(t, filepath.Join(fakeBin, "minisign"), `#!/bin/sh
+echo "$@" >> "$MINISIGN_LOG"
+if [ "$1" = "-S" ]; then
+  while [ $# -gt 0 ]; do
+    if [ "$1" = "-x" ]; then echo sig > "$2"; fi
+    shift
+  done
+fi
+exit 0
+`, 0o755)
+	logPath := filepath.Join(t.TempDir(), "minisign.log")
+	env := append(envWithPrependedPath(fakeBin),
+		"RIG_USER_CONFIG="+filepath.Join(t.TempDir(), "missing.toml"),
+		"RIG_LOCK_SIGNATURE=minisign",
+		"RIG_LOCK_PUBLIC_KEY="+filepath.Join(dir, "trusted.pub"),
+		"MINISIGN_LOG="+logPath,
+	)
+
+	out, err := runRigCmdInDirWithEnv(t, dir, env, "lock", "sign")
+	if err != nil {
+		t.Fatalf("rig lock sign failed: %v\n%s", err, out)
 	}
-	// Ensure we failed due to Go/module state, not due to rig tool sync state.
-	if strings.Contains(out, "tools are out of sync") {
-		t.Fatalf("unexpected tooling-state error; got: %s", out)
+	if strings.Contains(out, "Add [lock] signature") {
+		t.Fatalf("sign should not suggest [lock] when the environment pins a signer:\n%s", out)
 	}
-	outLower := strings.ToLower(out)
-	if !strings.Contains(outLower, "go.mod") && !strings.Contains(outLower, "main module") {
-		t.Fatalf("expected output to mention go.mod/module state, got: %s", out)
+	out, err = runRigCmdInDirWithEnv(t, dir, env, "lock", "verify")
+	if err != nil || !strings.Contains(out, "verified (minisign)") {
+		t.Fatalf("rig lock verify with only RIG_LOCK_SIGNATURE: err=%v\n%s", err, out)
+	}
+	if b, _ := os.ReadFile(logPath); !strings.Contains(string(b), "-p "+filepath.Join(dir, "trusted.pub")) {
+		t.Fatalf("verify did not use the pinned key:\n%s", b)
 	}
 }
@@ -2,7 +2,12 @@ package cli
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"strings"
+
+	core "github.com/divijg19/rig/internal/rig"
 )
 
 type colorMode string
@@ -13,6 +18,11 @@ const (
 	colorNever  colorMode = "never"
 )
 
+// resolveColorEnabled decides whether out gets ANSI color. never always wins.
+// NO_COLOR (any non-empty value) disables color unless mode is always;
+// FORCE_COLOR (unless 0/false) and CLICOLOR_FORCE (unless 0) enable it even
+// when out is not a terminal. Otherwise color needs a terminal, and auto also
+// requires CI unset and TERM other than dumb.
 func resolveColorEnabled(mode string, out *os.File) (bool, error) {
 	if mode == "" {
 		mode = string(colorAuto)
@@ -23,25 +33,39 @@ func resolveColorEnabled(mode string, out *os.File) (bool, error) {
 	default:
 		return false, errors.New("error: invalid --color value (expected auto|always|never)")
 	}
+	always := colorMode(mode) == colorAlways
 	if colorMode(mode) == colorNever {
 		return false, nil
 	}
-	if out == nil {
+	if !always && os.Getenv("NO_COLOR") != "" {
 		return false, nil
 	}
-	if !isTTY(out) {
-		return false, nil
+	if v, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "0", "false":
+			return false, nil
+		}
+		return true, nil
 	}
-	if os.Getenv("CI") != "" {
-		return false, nil
+	if v := strings.TrimSpace(os.Getenv("CLICOLOR_FORCE")); v != "" && v != "0" {
+		return true, nil
 	}
-	if os.Getenv("NO_COLOR") != "" {
+	if out == nil || !isTTY(out) {
 		return false, nil
 	}
-	if colorMode(mode) == colorAlways {
+	if always {
 		return true, nil
 	}
-	return true, nil
+	return os.Getenv("CI") == "" && os.Getenv("TERM") != "dumb", nil
+}
+
+// preferredColorMode is the color mode from user config; plain and
+// accessible imply never.
+func preferredColorMode() string {
+	if userConf.Plain || core.Accessible {
+		return string(colorNever)
+	}
+	return firstNonEmpty(strings.TrimSpace(userConf.Color), string(colorAuto))
 }
 
 func isTTY(f *os.File) bool {
@@ -55,6 +79,41 @@ func isTTY(f *os.File) bool {
 const (
 	ansiReset    = "\x1b[0m"
 	ansiBoldCyan = "\x1b[1;36m"
+	ansiGreen    = "\x1b[32m"
 	ansiYellow   = "\x1b[33m"
 	ansiRed      = "\x1b[31m"
+	ansiDim      = "\x1b[2m"
 )
+
+// styledWriter prints human status lines to one stream, colored only when
+// color is enabled for that stream. Commands share it so every one honors
+// NO_COLOR, FORCE_COLOR, CLICOLOR_FORCE, and the user config the same way.
+type styledWriter struct {
+	w     io.Writer
+	color bool
+}
+
+// newStyledWriter returns a styledWriter for f using the user's color mode.
+func newStyledWriter(f *os.File) *styledWriter {
+	on, err := resolveColorEnabled(preferredColorMode(), f)
+	return &styledWriter{w: f, color: on && err == nil}
+}
+
+// paint wraps msg in color when enabled.
+func (s *styledWriter) paint(color, msg string) string {
+	if !s.color || color == "" {
+		return msg
+	}
+	return color + msg + ansiReset
+}
+
+// linef prints one formatted line in color, spelled out in accessible mode.
+func (s *styledWriter) linef(color, format string, args ...any) {
+	fmt.Fprintln(s.w, s.paint(color, core.StatusText(fmt.Sprintf(format, args...))))
+}
+
+// statusf prints one uncolored status line to w, spelled out in accessible
+// mode.
+func statusf(w io.Writer, format string, args ...any) {
+	fmt.Fprintln(w, core.StatusText(fmt.Sprintf(format, args...)))
+}
@@ -0,0 +1,119 @@
+// internal/cli/completion.go
+
+package cli
+
+import (
+	"sort"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// Dynamic completions read rig.toml through cobra's hidden __complete
+// command, which the generated bash, zsh, fish, and PowerShell scripts all
+// call, so every shell gets the same task, tool, and profile candidates.
+// Config errors yield no candidates rather than noise on the prompt line.
+
+// completionConfig loads rig.toml for completion, or nil when unavailable.
+func completionConfig() *cfg.Config {
+	conf, _, err := loadConfigOptional()
+	if err != nil {
+		return nil
+	}
+	return conf
+}
+
+// describedCandidates renders sorted "name\tdescription" candidates that
+// start with prefix.
+func describedCandidates(descs map[string]string, prefix string) []string {
+	names := make([]string, 0, len(descs))
+	for name := range descs {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		if d := strings.TrimSpace(descs[name]); d != "" {
+			out = append(out, name+"\t"+d)
+			continue
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+// completeTaskNames completes the task argument of `rig run`, leaving out
+// internal tasks.
+func completeTaskNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	conf := completionConfig()
+	if conf == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	descs := make(map[string]string, len(conf.Tasks))
+	for name, t := range conf.Tasks {
+		if !t.Internal {
+			descs[name] = t.Description
+		}
+	}
+	return describedCandidates(descs, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeToolNames completes a single [tools] name, described by its version.
+func completeToolNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	conf := completionConfig()
+	if conf == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	descs := make(map[string]string, len(conf.Tools))
+	for name, ver := range conf.Tools {
+		descs[name] = ver
+	}
+	return describedCandidates(descs, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfileNames completes --profile from [profile.<name>] tables.
+func completeProfileNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	conf := completionConfig()
+	if conf == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	descs := make(map[string]string, len(conf.Profiles))
+	for name, p := range conf.Profiles {
+		if len(p.Tags) > 0 {
+			descs[name] = "tags: " + strings.Join(p.Tags, ",")
+		} else {
+			descs[name] = ""
+		}
+	}
+	return describedCandidates(descs, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTaskInputs completes `--input name=` for the inputs declared by
+// the task already on the command line.
+func completeTaskInputs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 || strings.Contains(toComplete, "=") {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	conf := completionConfig()
+	if conf == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	task, ok := conf.Tasks[args[0]]
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	descs := make(map[string]string, len(task.Inputs))
+	for _, in := range task.Inputs {
+		descs[in.Name+"="] = in.Prompt
+	}
+	return describedCandidates(descs, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
@@ -0,0 +1,208 @@
+// internal/cli/config.go
+
+package cli
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// userConf holds user-level defaults (~/.config/rig/config.toml), loaded
+	// before every command.
+	userConf     cfg.UserConfig
+	userConfPath string
+	userConfErr  error
+	// userProxyOverridden records proxy variables the environment already set.
+	userProxyOverridden = map[string]struct{}{}
+
+	configJSON     bool
+	configShowPath bool
+)
+
+// loadUserConfig reads the user config, applies its accessible default
+// unless --accessible was given, hands its [lock] trust settings to core, and
+// exports its proxy settings for rig and the commands it runs. Variables
+// already set in the environment win.
+func loadUserConfig(cmd *cobra.Command) {
+	userConf, userConfPath, userConfErr = cfg.LoadUserConfig()
+	if f := cmd.Flags().Lookup("accessible"); f == nil || !f.Changed {
+		core.Accessible = userConf.Accessible
+	}
+	if userConfErr != nil {
+		if !flagSet(cmd, "quiet") {
+			statusf(os.Stderr, "⚠️  ignoring user config: %v", userConfErr)
+		}
+		userConf = cfg.UserConfig{}
+		core.UserLock = cfg.UserLock{}
+		return
+	}
+	core.UserLock = userConf.Lock
+	if k := strings.TrimSpace(userConf.Lock.PublicKey); k != "" && !filepath.IsAbs(k) {
+		core.UserLock.PublicKey = filepath.Join(filepath.Dir(userConfPath), k)
+	}
+	for _, kv := range userConf.Proxy.Env() {
+		k, v, _ := strings.Cut(kv, "=")
+		if _, set := os.LookupEnv(k); set {
+			userProxyOverridden[k] = struct{}{}
+			continue
+		}
+		_ = os.Setenv(k, v)
+	}
+}
+
+// configSetting is one row of `rig config` output.
+type configSetting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show user-level defaults and where they come from",
+	Long: `Show the effective user-level settings from the user config file
+(~/.config/rig/config.toml, or $RIG_USER_CONFIG) layered with the environment
+and the project rig.toml.`,
+	Args: cobra.NoArgs,
+	Example: `
+  rig config
+  rig config --path
+  rig config --json
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configShowPath {
+			fmt.Println(userConfPath)
+			return nil
+		}
+		if userConfErr != nil {
+			return userConfErr
+		}
+		settings := collectConfigSettings()
+		if configJSON {
+			payload := struct {
+				Path     string          `json:"path"`
+				Exists   bool            `json:"exists"`
+				Settings []configSetting `json:"settings"`
+			}{Path: userConfPath, Settings: settings}
+			_, err := os.Stat(userConfPath)
+			payload.Exists = err == nil
+			b, err := stdjson.MarshalIndent(payload, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+		if _, err := os.Stat(userConfPath); err != nil {
+			fmt.Printf("user config: %s (not found)\n", userConfPath)
+		} else {
+			fmt.Printf("user config: %s\n", userConfPath)
+		}
+		width := 0
+		for _, s := range settings {
+			width = max(width, len(s.Key))
+		}
+		for _, s := range settings {
+			fmt.Printf("  %-*s  %-30s (%s)\n", width, s.Key, s.Value, s.Source)
+		}
+		return nil
+	},
+}
+
+func collectConfigSettings() []configSetting {
+	uc := userConf
+	pick := func(key, v, def string) configSetting {
+		if strings.TrimSpace(v) != "" {
+			return configSetting{Key: key, Value: v, Source: "user"}
+		}
+		return configSetting{Key: key, Value: def, Source: "default"}
+	}
+	plain, accessible := "", ""
+	if uc.Plain {
+		plain = "true"
+	}
+	if uc.Accessible {
+		accessible = "true"
+	}
+	out := []configSetting{
+		pick("color", uc.Color, string(colorAuto)),
+		pick("plain", plain, "false"),
+		pick("accessible", accessible, "false"),
+		pick("init.template", uc.Init.Template, "app"),
+		pick("init.license", uc.Init.License, "MIT"),
+	}
+	update := ""
+	if uc.Notify.UpdateCheck != nil {
+		update = fmt.Sprint(*uc.Notify.UpdateCheck)
+	}
+	out = append(out, pick("notify.update_check", update, "false"), pick("notify.interval", uc.Notify.Interval, "24h"))
+	allowRoot, depth := "", ""
+	if uc.Guard.AllowRoot {
+		allowRoot = "true"
+	}
+	if uc.Guard.MaxConfigDepth != nil {
+		depth = fmt.Sprint(*uc.Guard.MaxConfigDepth)
+	}
+	out = append(out, pick("guard.allow_root", allowRoot, "false"), pick("guard.max_config_depth", depth, fmt.Sprint(core.DefaultMaxConfigDepth)))
+	for _, l := range []struct{ key, value, env string }{
+		{"lock.signature", uc.Lock.Signature, "RIG_LOCK_SIGNATURE"},
+		{"lock.public_key", uc.Lock.PublicKey, "RIG_LOCK_PUBLIC_KEY"},
+	} {
+		s := pick(l.key, l.value, "")
+		if v := strings.TrimSpace(os.Getenv(l.env)); v != "" {
+			s.Value, s.Source = v, "env "+l.env
+		}
+		out = append(out, s)
+	}
+
+	for _, kv := range uc.Proxy.Env() {
+		k, v, _ := strings.Cut(kv, "=")
+		s := configSetting{Key: "proxy." + strings.ToLower(k), Value: v, Source: "user"}
+		if _, ok := userProxyOverridden[k]; ok {
+			s.Value, s.Source = os.Getenv(k), "env "+k
+		}
+		out = append(out, s)
+	}
+
+	// Tool aliases: the project's [tool-aliases] override the user's.
+	var project map[string]cfg.ToolAlias
+	if conf, _, err := cfg.Load(""); err == nil {
+		project = conf.ToolAliases
+	}
+	names := make([]string, 0, len(uc.ToolAliases)+len(project))
+	for n := range uc.ToolAliases {
+		names = append(names, n)
+	}
+	for n := range project {
+		if _, ok := uc.ToolAliases[n]; !ok {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		s := configSetting{Key: "tool-aliases." + n}
+		a, ok := project[n]
+		s.Source = "project"
+		if !ok {
+			a, s.Source = uc.ToolAliases[n], "user"
+		}
+		s.Value = firstNonEmpty(a.Install, a.Module)
+		out = append(out, s)
+	}
+	return out
+}
+
+func init() {
+	configCmd.Flags().BoolVar(&configJSON, "json", false, "print machine-readable JSON")
+	configCmd.Flags().BoolVar(&configShowPath, "path", false, "print the user config file path and exit")
+	rootCmd.AddCommand(configCmd)
+}
@@ -10,6 +10,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -19,23 +20,76 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var devColorMode string
+var (
+	devColorMode    string
+	devWatchMode    string
+	devPollInterval string
+	devTestOnSave   bool
+	devProfile      string
+	devStatusAddr   string
+	devMembers      string
+)
 
 var devCmd = &cobra.Command{
 	Use:   "dev",
 	Short: "Run the dev loop (watch + restart)",
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		rt, err := loadDevRuntime(devColorMode, os.Stdout, os.Stderr)
+		colorMode := devColorMode
+		if !cmd.Flags().Changed("color") {
+			colorMode = preferredColorMode()
+		}
+		if strings.TrimSpace(devMembers) != "" {
+			return runDevMembersCmd(cmd, colorMode)
+		}
+		rt, err := loadDevRuntime(colorMode, os.Stdout, os.Stderr)
 		if err != nil {
 			return err
 		}
+		rt.plain = userConf.Plain
+		// Failures from here on are runtime errors, not usage errors.
+		cmd.SilenceUsage = true
 		return rt.Run()
 	},
 }
 
+// devReloadCmd restarts the process of a running `rig dev` from another
+// terminal, an editor, a test script, or a git hook.
+var devReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Restart the process of a running rig dev (like Ctrl+R)",
+	Long: `Ask the 'rig dev' running in this project to restart its process, as
+Ctrl+R does. 'rig dev' records the address of its reload endpoint in
+.rig/dev.addr; editors and scripts can also POST to http://<addr>/reload.`,
+	Args: cobra.NoArgs,
+	Example: `
+  rig dev reload
+  curl -X POST "http://$(cat .rig/dev.addr)/reload"
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, confPath, err := loadConfigOrFail()
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+		if err := core.RequestDevReload(confPath); err != nil {
+			return fmt.Errorf("error: %s", err)
+		}
+		statusf(os.Stdout, "🔄 reload requested")
+		return nil
+	},
+}
+
 func init() {
 	devCmd.Flags().StringVar(&devColorMode, "color", "auto", "color output: auto|always|never")
+	devCmd.Flags().StringVar(&devWatchMode, "watch-mode", "", "change detection: auto|poll (default: [tasks.dev].watch_mode)")
+	devCmd.Flags().StringVar(&devPollInterval, "poll-interval", "", "poll interval, e.g. 500ms (default: [tasks.dev].poll_interval or 1s)")
+	devCmd.Flags().BoolVar(&devTestOnSave, "test-on-save", false, "run go test for the package of each changed .go file (default: [tasks.dev].test_on_save)")
+	devCmd.Flags().StringVar(&devProfile, "profile", "", "apply env, tags, and flags from rig.toml [profile.<name>] (default: [tasks.dev].profile)")
+	devCmd.Flags().StringVar(&devStatusAddr, "status-addr", "", "serve process state, restart counts, and log tails as JSON at http://<addr>/status, e.g. 127.0.0.1:7777")
+	devCmd.Flags().StringVar(&devMembers, "members", "", "run the dev task of these workspace members (comma-separated, or all) together")
+	_ = devCmd.RegisterFlagCompletionFunc("profile", completeProfileNames)
+	devCmd.AddCommand(devReloadCmd)
 	rootCmd.AddCommand(devCmd)
 }
 
@@ -46,7 +100,12 @@ type DevRuntime struct {
 	Lock      core.Lockfile
 	Toolchain core.GoToolchainLock
 
+	// name identifies the dev process in the status report: "dev", or the
+	// member name under --members.
+	name string
+
 	configPath  string
+	conf        *cfg.Config
 	tools       map[string]string
 	watchGlobs  []string
 	command     string
@@ -58,8 +117,52 @@ type DevRuntime struct {
 	colorOn     bool
 	out         io.Writer
 	errOut      io.Writer
+
+	// plain (user config) drops color and status symbols.
+	plain bool
+
+	// poll mode runs the command directly and detects changes with a PollWatcher.
+	poll         bool
+	pollInterval time.Duration
+	ignore       *core.IgnoreMatcher
+	// testOnSave runs `go test` for changed packages next to the command.
+	testOnSave bool
+	// profile names the applied [profile.<name>]; profileEnv is its
+	// environment (env, RIG_PROFILE, GOFLAGS).
+	profile    string
+	profileEnv map[string]string
+	// env_file support: envReloadSignal is nil for "restart".
+	envFile         string
+	envReloadSignal os.Signal
+
+	// stop is how the command is stopped before a restart or on exit:
+	// [tasks.dev].stop_signal and stop_timeout over core.DefaultDevStop.
+	stop core.StopPolicy
+
+	// crashes limits automatic restarts after failed starts; lastStderr keeps
+	// the tail of the most recent attempt's stderr for the crash summary.
+	crashes    core.CrashTracker
+	lastStderr core.TailBuffer
+
+	// statusAddr enables the status endpoint (--status-addr); status tracks
+	// the dev process for it and is nil when disabled.
+	statusAddr string
+	status     *core.DevStatus
+	// requestReload restarts the dev process like Ctrl+R; the status
+	// endpoint serves it as POST /reload. Nil until Run sets it.
+	requestReload func()
+
+	// log keeps every start of the command (dev#<n>), test-on-save failures,
+	// and status lines in .rig/logs/dev.log for `rig logs dev`.
+	log            *core.TaskLog
+	starts         int
+	logOut, logErr io.Writer
 }
 
+// devProcessName names the dev command in .rig/logs and, without --members,
+// in the status report.
+const devProcessName = "dev"
+
 // Supervisor manages a single child process at a time.
 type Supervisor struct {
 	cmd    *exec.Cmd
@@ -67,13 +170,22 @@ type Supervisor struct {
 }
 
 func loadDevRuntime(colorMode string, out io.Writer, errOut io.Writer) (*DevRuntime, error) {
-	conf, confPath, err := core.LoadConfig("")
+	return loadDevRuntimeIn("", colorMode, out, errOut)
+}
+
+// loadDevRuntimeIn loads the dev runtime of the project containing startDir.
+func loadDevRuntimeIn(startDir, colorMode string, out io.Writer, errOut io.Writer) (*DevRuntime, error) {
+	conf, confPath, err := core.LoadConfig(startDir)
 	if err != nil {
 		if errors.Is(err, cfg.ErrConfigNotFound) {
 			return nil, errors.New(msgNoConfig)
 		}
 		return nil, err
 	}
+	// A sync in progress is rewriting .rig/bin and rig.lock; let it finish.
+	core.WaitProjectUnlocked(confPath, func(h core.ProjectLockHolder) {
+		statusf(errOut, "⏳ waiting for another rig process (PID %d) that is %s", h.PID, h.Action)
+	})
 	lockPath := filepath.Join(filepath.Dir(confPath), "rig.lock")
 	lock, err := core.ReadLockfile(lockPath)
 	if err != nil {
@@ -82,6 +194,9 @@ func loadDevRuntime(colorMode string, out io.Writer, errOut io.Writer) (*DevRunt
 		}
 		return nil, err
 	}
+	if err := core.LockMatchesManifest(lock, conf); err != nil {
+		statusf(errOut, "⚠️  %v", err)
+	}
 
 	colorOn, err := resolveColorEnabled(colorMode, os.Stdout)
 	if err != nil {
@@ -102,17 +217,48 @@ func loadDevRuntime(colorMode string, out io.Writer, errOut io.Writer) (*DevRunt
 	rt := &DevRuntime{
 		Task:       devTask,
 		Lock:       lock,
+		name:       devProcessName,
 		configPath: confPath,
+		conf:       conf,
 		tools:      conf.Tools,
 		watchGlobs: devTask.Watch,
 		colorMode:  colorMode,
 		colorOn:    colorOn,
 		out:        out,
 		errOut:     errOut,
+		statusAddr: strings.TrimSpace(devStatusAddr),
 	}
 	if lock.Toolchain != nil && lock.Toolchain.Go != nil {
 		rt.Toolchain = *lock.Toolchain.Go
 	}
+	mode, err := core.ParseWatchMode(firstNonEmpty(devWatchMode, devTask.WatchMode))
+	if err != nil {
+		return nil, fmt.Errorf("error: %s", err)
+	}
+	rt.poll = mode == core.WatchModePoll
+	if rt.pollInterval, err = core.ParsePollInterval(firstNonEmpty(devPollInterval, devTask.PollInterval)); err != nil {
+		return nil, fmt.Errorf("error: %s", err)
+	}
+	rt.testOnSave = devTestOnSave || devTask.TestOnSave
+	if name := firstNonEmpty(devProfile, devTask.Profile); name != "" {
+		prof, err := core.LookupProfile(conf, confPath, name)
+		if err != nil {
+			return nil, fmt.Errorf("error: %s", err)
+		}
+		rt.profile = name
+		rt.profileEnv = core.ProfileTaskEnv(name, prof, os.Getenv("GOFLAGS"))
+	}
+	if rt.stop, err = core.TaskStopPolicy(devTask, core.DefaultDevStop); err != nil {
+		return nil, fmt.Errorf("error: %s", err)
+	}
+	rt.crashes.Max = core.DefaultMaxRestarts
+	if devTask.MaxRestarts != nil {
+		rt.crashes.Max = *devTask.MaxRestarts
+	}
+	if rt.crashes.Window, err = core.ParseRestartWindow(devTask.RestartWindow); err != nil {
+		return nil, fmt.Errorf("error: %s", err)
+	}
+	rt.lastStderr.Max = 4 << 10
 	if err := rt.Validate(); err != nil {
 		return nil, err
 	}
@@ -132,8 +278,8 @@ func (r *DevRuntime) Validate() error {
 		}
 	}
 
-	if !hasTool(r.tools, "reflex") {
-		return errors.New("error: dev watcher 'reflex' must be declared in [tools]")
+	if !r.poll && !hasTool(r.tools, "reflex") {
+		return errors.New("error: dev watcher 'reflex' must be declared in [tools] (or set watch_mode = \"poll\")")
 	}
 
 	if goRow, ok := core.CheckGoToolchainAgainstLock(r.tools, r.Lock, r.configPath); !ok {
@@ -152,14 +298,18 @@ func (r *DevRuntime) Validate() error {
 	}
 	_ = rows
 	if missing > 0 || mismatched > 0 {
-		if err := r.ensureWatcherInstalled(); err != nil {
-			return err
+		if !r.poll {
+			if err := r.ensureWatcherInstalled(); err != nil {
+				return err
+			}
 		}
 		return fmt.Errorf("error: tools are out of sync with rig.lock (missing=%d mismatched=%d extras=%d)", missing, mismatched, len(extras))
 	}
 
-	if err := r.ensureWatcherInstalled(); err != nil {
-		return err
+	if !r.poll {
+		if err := r.ensureWatcherInstalled(); err != nil {
+			return err
+		}
 	}
 	if err := ensureShellAvailable(); err != nil {
 		return err
@@ -168,32 +318,222 @@ func (r *DevRuntime) Validate() error {
 	if err != nil {
 		return err
 	}
+	if info, err := os.Stat(cmdCwd); err != nil || !info.IsDir() {
+		return fmt.Errorf("error: [tasks.dev].cwd %q is not a directory", r.Task.Cwd)
+	}
+	if r.conf != nil {
+		if _, err := core.TaskDependencies(r.conf.Tasks, "dev"); err != nil {
+			return fmt.Errorf("error: [tasks.dev].depends_on: %s", err)
+		}
+	}
 
 	r.command = strings.TrimSpace(r.Task.Command)
 	r.cwd = cmdCwd
-	r.env = buildDevEnv(r.configPath, r.Task.Env)
-	r.watcherPath = core.ToolBinPath(r.configPath, "reflex")
-	r.watcherArgs = buildWatcherArgs(r.Task.Watch, r.command)
+	taskEnv := mergeEnv(r.baseEnv(), r.Task.Env)
+	if f := strings.TrimSpace(r.Task.EnvFile); f != "" {
+		if !filepath.IsAbs(f) {
+			f = filepath.Join(filepath.Dir(r.configPath), f)
+		}
+		fileEnv, err := core.ReadEnvFile(f)
+		if err != nil {
+			return fmt.Errorf("error: env_file: %s", err)
+		}
+		r.envFile = f
+		taskEnv = mergeEnv(mergeEnv(r.baseEnv(), fileEnv), r.Task.Env)
+		if r.envReloadSignal, err = parseEnvReload(r.Task.EnvReload); err != nil {
+			return fmt.Errorf("error: %s", err)
+		}
+	}
+	r.env = buildDevEnv(r.configPath, taskEnv)
+	useGitignore := r.Task.Gitignore == nil || *r.Task.Gitignore
+	r.ignore = core.LoadIgnoreMatcher(filepath.Dir(r.configPath), useGitignore, r.Task.Ignore)
+	if r.poll {
+		r.watcherPath, r.watcherArgs = shellCommand(r.command)
+	} else {
+		// reflex watches its working directory, so it runs at the project root
+		// and the command changes into cwd itself.
+		root, err := filepath.Abs(filepath.Dir(r.configPath))
+		if err != nil {
+			return err
+		}
+		command := r.command
+		if cmdCwd != root {
+			command = "cd " + posixQuote(cmdCwd) + " && " + command
+		}
+		r.cwd = root
+		r.watcherPath = core.ToolBinPath(r.configPath, "reflex")
+		r.watcherArgs = buildWatcherArgs(r.Task.Watch, command, r.ignore.Regexps()...)
+	}
 
 	return nil
 }
 
 func (r *DevRuntime) Run() error {
-	reloadCh, exitCh, cleanup := r.startKeyListener()
+	keyCh, exitCh, cleanup := r.startKeyListener()
 	defer cleanup()
+	reloadCh, request, stop := startDevReload(r.configPath, keyCh, r.errOut)
+	defer stop()
+	r.requestReload = request
+	return r.run(reloadCh, exitCh)
+}
 
+// startDevReload merges Ctrl+R (keys, may be nil) with reload requests from
+// `rig dev reload`, which reaches the endpoint recorded in .rig/dev.addr.
+// request triggers a reload directly; stop closes the endpoint.
+func startDevReload(configPath string, keys <-chan struct{}, errOut io.Writer) (reloadCh <-chan struct{}, request func(), stop func()) {
+	ch := make(chan struct{}, 1)
+	request = func() {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-keys:
+				request()
+			}
+		}
+	}()
+	stopServer, err := core.ServeDevReload(configPath, request)
+	if err != nil {
+		statusf(errOut, "⚠️  rig dev reload unavailable: %v", err)
+		stopServer = func() {}
+	}
+	return ch, request, func() {
+		close(done)
+		stopServer()
+	}
+}
+
+// run starts the dev loop with the given reload and exit channels; both may
+// be nil.
+func (r *DevRuntime) run(reloadCh, exitCh <-chan struct{}) error {
+	log, err := core.OpenTaskLog(r.configPath, devProcessName)
+	if err != nil {
+		statusf(r.errOut, "⚠️  not logging to .rig/logs: %v", err)
+	}
+	r.log = log
+	defer func() { _ = r.log.Close() }()
+
+	var changeCh chan struct{}
+	if r.poll {
+		changeCh = make(chan struct{}, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w := core.PollWatcher{Root: filepath.Dir(r.configPath), Globs: r.Task.Watch, Interval: r.pollInterval, Ignore: r.ignore}
+		go func() { _ = w.Run(ctx, changeCh) }()
+	}
+
+	var envCh chan struct{}
+	if r.envFile != "" {
+		envCh = make(chan struct{}, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() { _ = core.WatchFile(ctx, r.envFile, 500*time.Millisecond, envCh) }()
+	}
+
+	if r.statusAddr != "" {
+		r.status = core.NewDevStatus()
+		r.status.Add(r.name, r.command)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		addr, err := core.ServeDevStatus(ctx, r.statusAddr, r.status, r.requestReload)
+		if err != nil {
+			return fmt.Errorf("error: --status-addr: %s", err)
+		}
+		r.emit(ansiBoldCyan, fmt.Sprintf("📡 status: http://%s/status", addr))
+	}
+
+	if err := r.runDependencies(); err != nil {
+		return err
+	}
+	if r.testOnSave {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go r.testOnSaveLoop(ctx, slices.Clone(r.env))
+	}
 	r.logStart()
-	err := r.supervise(reloadCh, exitCh)
+	err = r.supervise(reloadCh, exitCh, changeCh, envCh)
 	r.logStop()
 	return err
 }
 
-func (r *DevRuntime) supervise(reloadCh <-chan struct{}, exitCh <-chan struct{}) error {
+// runDependencies runs [tasks.dev].depends_on once before the loop starts.
+func (r *DevRuntime) runDependencies() error {
+	if r.conf == nil {
+		return nil
+	}
+	deps, err := core.TaskDependencies(r.conf.Tasks, "dev")
+	if err != nil || len(deps) == 0 {
+		return err
+	}
+	r.emit(ansiBoldCyan, "🔧 depends_on: "+strings.Join(deps, ", "))
+	if err := core.RunTasks(context.Background(), r.conf, r.configPath, r.Lock, deps, core.RunOptions{Profile: r.profile}); err != nil {
+		return fmt.Errorf("error: %s", err)
+	}
+	return nil
+}
+
+// testOnSaveLoop polls for changed .go files and runs `go test` for their
+// packages with env, printing one status line per run. It runs beside the dev
+// command and never restarts or signals it.
+func (r *DevRuntime) testOnSaveLoop(ctx context.Context, env []string) {
+	root := filepath.Dir(r.configPath)
+	changes := make(chan []string)
+	w := core.PollWatcher{Root: root, Globs: []string{"**/*.go"}, Interval: r.pollInterval, Ignore: r.ignore}
+	go func() { _ = w.RunFiles(ctx, changes) }()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case files := <-changes:
+			pkgs := core.TestPackagesFor(files)
+			if len(pkgs) == 0 {
+				continue
+			}
+			start := time.Now()
+			cmd := exec.CommandContext(ctx, "go", append([]string{"test"}, pkgs...)...)
+			cmd.Dir = root
+			cmd.Env = env
+			out, err := cmd.CombinedOutput()
+			if ctx.Err() != nil {
+				return
+			}
+			target := strings.Join(pkgs, " ")
+			elapsed := time.Since(start).Round(10 * time.Millisecond)
+			if err == nil {
+				r.status.TestResult(r.name, "ok "+target)
+				r.emit(ansiGreen, fmt.Sprintf("🧪 ok   %s (%s)", target, elapsed))
+				continue
+			}
+			detail := strings.Join(core.FailedTests(out), ", ")
+			if detail == "" {
+				detail = firstLine(string(out))
+			}
+			r.status.TestResult(r.name, "FAIL "+target+": "+detail)
+			testLog := r.log.Writer("test")
+			_, _ = testLog.Write(out)
+			core.FlushTaskLogWriter(testLog)
+			r.emit(ansiRed, fmt.Sprintf("🧪 FAIL %s (%s): %s", target, elapsed, detail))
+		}
+	}
+}
+
+// supervise restarts the child on reload, on a poll-detected change, on an
+// env_file change, or (with reflex) when the watcher exits with an error.
+// changeCh and envCh are nil unless polling / env_file is set.
+func (r *DevRuntime) supervise(reloadCh <-chan struct{}, exitCh <-chan struct{}, changeCh <-chan struct{}, envCh <-chan struct{}) error {
 	sigCh := make(chan os.Signal, 2)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 	defer signal.Stop(sigCh)
 	manualExit := false
 
+restart:
 	for {
 		s := &Supervisor{}
 		ctx, cancel := context.WithCancel(context.Background())
@@ -208,57 +548,197 @@ func (r *DevRuntime) supervise(reloadCh <-chan struct{}, exitCh <-chan struct{})
 			cancel()
 			return err
 		}
+		r.status.Started(r.name, cmd.Process.Pid)
 
 		waitCh := make(chan error, 1)
-		go func() { waitCh <- cmd.Wait() }()
+		logOut, logErr := r.logOut, r.logErr
+		go func() {
+			err := cmd.Wait()
+			core.FlushTaskLogWriter(logOut)
+			core.FlushTaskLogWriter(logErr)
+			waitCh <- err
+		}()
 
-		select {
-		case <-exitCh:
-			manualExit = true
-			s.stop(syscall.SIGTERM)
-			waitForExit(waitCh, cancel)
-			return nil
-		case <-reloadCh:
-			r.logManualReload()
-			r.logRestarting()
-			s.stop(syscall.SIGTERM)
-			waitForExit(waitCh, cancel)
-			continue
-		case sig := <-sigCh:
-			switch sig {
-			case os.Interrupt:
+		for {
+			select {
+			case <-exitCh:
 				manualExit = true
-				s.stop(syscall.SIGTERM)
-				waitForExit(waitCh, cancel)
-				return nil
-			default:
-				s.stop(syscall.SIGTERM)
-				waitForExit(waitCh, cancel)
-				return nil
-			}
-		case err := <-waitCh:
-			if err == nil {
-				return nil
-			}
-			if errors.Is(err, context.Canceled) || manualExit {
+				s.stop(r.stop.Signal)
+				waitForExit(waitCh, cancel, r.stop.Timeout)
 				return nil
+			case <-reloadCh:
+				r.crashes.Reset()
+				r.logManualReload()
+				r.status.Restarting(r.name, "reload")
+				r.logRestarting()
+				s.stop(r.stop.Signal)
+				waitForExit(waitCh, cancel, r.stop.Timeout)
+				continue restart
+			case <-changeCh:
+				r.crashes.Reset()
+				r.logChangeDetected()
+				r.status.Restarting(r.name, "change")
+				r.logRestarting()
+				s.stop(r.stop.Signal)
+				waitForExit(waitCh, cancel, r.stop.Timeout)
+				continue restart
+			case <-envCh:
+				if !r.reloadEnv() {
+					continue
+				}
+				if r.envReloadSignal != nil {
+					r.logEnvSignal()
+					if cmd.Process != nil {
+						_ = cmd.Process.Signal(r.envReloadSignal)
+					}
+					continue
+				}
+				r.status.Restarting(r.name, "env")
+				r.logRestarting()
+				s.stop(r.stop.Signal)
+				waitForExit(waitCh, cancel, r.stop.Timeout)
+				continue restart
+			case sig := <-sigCh:
+				switch sig {
+				case os.Interrupt:
+					manualExit = true
+					s.stop(r.stop.Signal)
+					waitForExit(waitCh, cancel, r.stop.Timeout)
+					return nil
+				default:
+					s.stop(r.stop.Signal)
+					waitForExit(waitCh, cancel, r.stop.Timeout)
+					return nil
+				}
+			case err := <-waitCh:
+				cancel()
+				if r.poll && !manualExit {
+					// The command exited on its own; wait for the next change.
+					r.status.Exited(r.name, err)
+					select {
+					case <-changeCh:
+					case <-envCh:
+						r.reloadEnv()
+					case <-reloadCh:
+						r.logManualReload()
+						r.status.Restarting(r.name, "reload")
+						r.logRestarting()
+						continue restart
+					case <-exitCh:
+						return nil
+					case <-sigCh:
+						return nil
+					}
+					r.logChangeDetected()
+					r.status.Restarting(r.name, "change")
+					r.logRestarting()
+					continue restart
+				}
+				if err == nil {
+					return nil
+				}
+				if errors.Is(err, context.Canceled) || manualExit {
+					return nil
+				}
+				if r.crashes.Fail(time.Now()) {
+					r.status.SetState(r.name, core.DevStateCrashed)
+					return r.crashSummary(err)
+				}
+				r.logChangeDetected()
+				r.status.Restarting(r.name, "exit: "+err.Error())
+				r.logRestarting()
+				continue restart
 			}
-			r.logChangeDetected()
-			r.logRestarting()
-			continue
 		}
 	}
 }
 
+// crashSummary reports a crash loop: the failure count, the last exit error,
+// and the captured stderr of the final attempt.
+func (r *DevRuntime) crashSummary(last error) error {
+	r.emit(ansiRed, fmt.Sprintf("💥 %d failed starts within %s; not restarting", r.crashes.Max, r.crashes.Window))
+	if tail := strings.TrimRight(r.lastStderr.String(), "\n"); tail != "" {
+		fmt.Fprintln(r.errOut, "--- stderr of last attempt ---")
+		fmt.Fprintln(r.errOut, tail)
+		fmt.Fprintln(r.errOut, "---")
+	}
+	return fmt.Errorf("error: [tasks.dev] keeps crashing (%v); fix it and rerun 'rig dev', or raise max_restarts", last)
+}
+
+// reloadEnv re-reads env_file into r.env. It reports false (keeping the
+// previous environment) when the file cannot be parsed.
+func (r *DevRuntime) reloadEnv() bool {
+	fileEnv, err := core.ReadEnvFile(r.envFile)
+	if err != nil {
+		statusf(r.errOut, "⚠️  env_file not reloaded: %v", err)
+		return false
+	}
+	r.env = buildDevEnv(r.configPath, mergeEnv(mergeEnv(r.baseEnv(), fileEnv), r.Task.Env))
+	r.emit(ansiYellow, "🔁 env changed: "+filepath.Base(r.envFile))
+	return true
+}
+
+func (r *DevRuntime) logEnvSignal() {
+	r.emit(ansiYellow, fmt.Sprintf("📨 sent %s", r.envReloadSignal))
+}
+
+// baseEnv is the environment beneath env_file and [tasks.dev].env: GOCACHE
+// from [cache].go, when set, and the dev profile's environment.
+func (r *DevRuntime) baseEnv() map[string]string {
+	env := map[string]string{}
+	if r.conf != nil {
+		if dir := core.GoCacheDir(r.conf, r.configPath); dir != "" {
+			env["GOCACHE"] = dir
+		}
+	}
+	return mergeEnv(env, r.profileEnv)
+}
+
+// mergeEnv overlays b on a (task env wins over env_file).
+
+func mergeEnv(a, b map[string]string) map[string]string {
+	out := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		out[k] = v
+	}
+	return out
+}
+
+// firstLine returns the first non-blank line of s.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
 func (r *DevRuntime) spawn(ctx context.Context) (*exec.Cmd, error) {
 	cmd := exec.CommandContext(ctx, r.watcherPath, r.watcherArgs...)
 	cmd.Dir = r.cwd
 	cmd.Env = r.env
 	cmd.Stdout = r.out
+	// Stderr is copied through a pipe; don't let a grandchild that inherited
+	// it keep Wait from returning after the command exits.
+	cmd.WaitDelay = time.Second
+	r.lastStderr.Reset()
 	if r.colorOn {
-		cmd.Stderr = &ansiWriter{w: r.errOut, prefix: ansiRed, suffix: ansiReset}
+		cmd.Stderr = io.MultiWriter(&ansiWriter{w: r.errOut, prefix: ansiRed, suffix: ansiReset}, &r.lastStderr)
 	} else {
-		cmd.Stderr = r.errOut
+		cmd.Stderr = io.MultiWriter(r.errOut, &r.lastStderr)
+	}
+	r.starts++
+	r.logOut = r.log.Writer(fmt.Sprintf("%s#%d", devProcessName, r.starts))
+	r.logErr = r.log.Writer(fmt.Sprintf("%s#%d:err", devProcessName, r.starts))
+	cmd.Stdout = io.MultiWriter(cmd.Stdout, r.logOut)
+	cmd.Stderr = io.MultiWriter(cmd.Stderr, r.logErr)
+	if logs := r.status.Logs(r.name); logs != nil {
+		cmd.Stdout = io.MultiWriter(cmd.Stdout, logs)
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, logs)
 	}
 	cmd.Stdin = os.Stdin
 	return cmd, nil
@@ -266,48 +746,49 @@ func (r *DevRuntime) spawn(ctx context.Context) (*exec.Cmd, error) {
 
 func (r *DevRuntime) logStart() {
 	start := "🚀 dev started"
+	if r.profile != "" {
+		start += fmt.Sprintf(" (profile %s)", r.profile)
+	}
 	watch := fmt.Sprintf("👀 watching: %s", strings.Join(r.Task.Watch, ", "))
-	cmd := fmt.Sprintf("▶ %s", r.command)
-	if r.colorOn {
-		start = ansiBoldCyan + start + ansiReset
-		watch = ansiBoldCyan + watch + ansiReset
-		cmd = ansiBoldCyan + cmd + ansiReset
+	if r.poll {
+		watch += fmt.Sprintf(" (polling every %s)", r.pollInterval)
 	}
-	fmt.Fprintln(r.out, start)
-	fmt.Fprintln(r.out, watch)
-	fmt.Fprintln(r.out, cmd)
+	r.emit(ansiBoldCyan, start)
+	r.emit(ansiBoldCyan, watch)
+	r.emit(ansiBoldCyan, fmt.Sprintf("▶ %s", r.command))
 }
 
-func (r *DevRuntime) logChangeDetected() {
-	msg := "🔁 change detected"
-	if r.colorOn {
-		msg = ansiYellow + msg + ansiReset
+// emit prints a status line, colored when enabled. Plain mode drops the
+// leading symbol; accessible mode spells it out.
+func (r *DevRuntime) emit(color, msg string) {
+	r.log.Printf("rig", "%s", msg)
+	if core.Accessible {
+		msg = core.StatusText(msg)
+	} else if r.plain {
+		if _, rest, ok := strings.Cut(msg, " "); ok {
+			msg = rest
+		}
+	} else if r.colorOn {
+		msg = color + msg + ansiReset
 	}
 	fmt.Fprintln(r.out, msg)
 }
 
+func (r *DevRuntime) logChangeDetected() {
+	r.emit(ansiYellow, "🔁 change detected")
+}
+
 func (r *DevRuntime) logManualReload() {
-	msg := "🔄 manual reload"
-	if r.colorOn {
-		msg = ansiYellow + msg + ansiReset
-	}
-	fmt.Fprintln(r.out, msg)
+	r.emit(ansiYellow, "🔄 manual reload")
 }
 
 func (r *DevRuntime) logRestarting() {
-	msg := "▶ restarting…"
-	if r.colorOn {
-		msg = ansiYellow + msg + ansiReset
-	}
-	fmt.Fprintln(r.out, msg)
+	r.emit(ansiYellow, "▶ restarting…")
 }
 
 func (r *DevRuntime) logStop() {
-	msg := "🛑 dev stopped"
-	if r.colorOn {
-		msg = ansiRed + msg + ansiReset
-	}
-	fmt.Fprintln(r.out, msg)
+	r.status.SetState(r.name, core.DevStateStopped)
+	r.emit(ansiRed, "🛑 dev stopped")
 }
 
 func (r *DevRuntime) startKeyListener() (<-chan struct{}, <-chan struct{}, func()) {
@@ -372,22 +853,21 @@ func (s *Supervisor) stop(sig os.Signal) {
 	_ = s.cmd.Process.Signal(sig)
 }
 
-func waitForExit(waitCh <-chan error, cancel context.CancelFunc) {
+// waitForExit waits up to timeout for a stopped command to exit, then
+// kills it through cancel.
+func waitForExit(waitCh <-chan error, cancel context.CancelFunc, timeout time.Duration) {
 	select {
 	case <-waitCh:
 		return
-	case <-time.After(200 * time.Millisecond):
+	case <-time.After(timeout):
 		cancel()
 		<-waitCh
 	}
 }
 
 func ensureShellAvailable() error {
-	if runtime.GOOS == "windows" {
-		return nil
-	}
-	if _, err := exec.LookPath("sh"); err != nil {
-		return errors.New("error: sh not found on PATH")
+	if _, _, err := core.ShellCommand(""); err != nil {
+		return fmt.Errorf("error: %w", err)
 	}
 	return nil
 }
@@ -400,10 +880,29 @@ func (r *DevRuntime) ensureWatcherInstalled() error {
 	return nil
 }
 
-func buildWatcherArgs(globs []string, command string) []string {
+// posixQuote single-quotes s for sh.
+func posixQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellCommand runs command through the platform shell (see
+// core.ShellCommand); ensureShellAvailable has already checked one exists.
+func shellCommand(command string) (string, []string) {
+	name, args, _ := core.ShellCommand(command)
+	return name, args
+}
+
+// buildWatcherArgs builds reflex arguments; each exclude regex is passed as -R.
+// reflex runs command through the same shell as tasks (see shellCommand).
+func buildWatcherArgs(globs []string, command string, excludes ...string) []string {
 	regex := computeWatchRegex(globs)
-	args := []string{"-s", "-r", regex, "--", "sh", "-c", command}
-	return args
+	args := []string{"-s", "-r", regex}
+	for _, x := range excludes {
+		args = append(args, "-R", x)
+	}
+	name, shArgs := shellCommand(command)
+	args = append(args, "--", name)
+	return append(args, shArgs...)
 }
 
 func computeWatchRegex(globs []string) string {
@@ -443,7 +942,7 @@ func resolveDevCwd(configPath string, taskCwd string) (string, error) {
 	if strings.TrimSpace(taskCwd) == "" {
 		return filepath.Abs(baseDir)
 	}
-	cwd := taskCwd
+	cwd := core.NormalizeTaskPath(taskCwd)
 	if !filepath.IsAbs(cwd) {
 		cwd = filepath.Join(baseDir, cwd)
 	}
@@ -460,7 +959,7 @@ func buildDevEnv(configPath string, taskEnv map[string]string) []string {
 		base[k] = v
 	}
 
-	localBin := filepath.Join(filepath.Dir(configPath), ".rig", "bin")
+	localBin := core.BinDir(configPath)
 
 	basePath := base["PATH"]
 	parts := []string{}
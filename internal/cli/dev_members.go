@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+// runDevMembersCmd runs `rig dev --members`: the [tasks.dev] of each named
+// workspace member, concurrently, with output prefixed by the member name.
+func runDevMembersCmd(cmd *cobra.Command, colorMode string) error {
+	conf, path, err := loadConfigOrFail()
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, n := range strings.Split(devMembers, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	members, err := core.ResolveDevMembers(conf, path, names)
+	if err != nil {
+		return err
+	}
+	rts := make([]*DevRuntime, 0, len(members))
+	for _, m := range members {
+		prefix := "[" + m.Name + "] "
+		rt, err := loadDevRuntimeIn(m.Dir, colorMode, core.NewPrefixWriter(os.Stdout, prefix), core.NewPrefixWriter(os.Stderr, prefix))
+		if err != nil {
+			return fmt.Errorf("member %s: %w", m.Name, err)
+		}
+		rt.name = m.Name
+		rt.plain = userConf.Plain
+		rt.statusAddr = ""
+		rts = append(rts, rt)
+	}
+	cmd.SilenceUsage = true
+
+	keyCh, exitCh, cleanup := rts[0].startKeyListener()
+	defer cleanup()
+	reloadCh, request, stop := startDevReload(path, keyCh, os.Stderr)
+	defer stop()
+
+	if addr := strings.TrimSpace(devStatusAddr); addr != "" {
+		status := core.NewDevStatus()
+		for _, rt := range rts {
+			rt.status = status
+			status.Add(rt.name, rt.command)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		bound, err := core.ServeDevStatus(ctx, addr, status, request)
+		if err != nil {
+			return fmt.Errorf("error: --status-addr: %s", err)
+		}
+		newStyledWriter(os.Stdout).linef(ansiBoldCyan, "📡 status: http://%s/status", bound)
+	}
+
+	return runDevMembers(rts, reloadCh, exitCh)
+}
+
+// runDevMembers runs each member's dev loop concurrently. A reload (Ctrl+R
+// or `rig dev reload`) reloads every member; Ctrl+C, or any member's loop ending (a crash loop, or a
+// signal), stops them all. It returns the members' errors.
+func runDevMembers(rts []*DevRuntime, reloadCh, exitCh <-chan struct{}) error {
+	stop := make(chan struct{})
+	var once sync.Once
+	shutdown := func() { once.Do(func() { close(stop) }) }
+
+	reloads := make([]chan struct{}, len(rts))
+	for i := range reloads {
+		reloads[i] = make(chan struct{}, 1)
+	}
+	go func() {
+		for {
+			select {
+			case <-reloadCh:
+				for _, ch := range reloads {
+					select {
+					case ch <- struct{}{}:
+					default:
+					}
+				}
+			case <-exitCh:
+				shutdown()
+				return
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	errs := make([]error, len(rts))
+	var wg sync.WaitGroup
+	for i, rt := range rts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer shutdown()
+			if err := rt.run(reloads[i], stop); err != nil {
+				errs[i] = fmt.Errorf("member %s: %w", rt.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
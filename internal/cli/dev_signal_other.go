@@ -0,0 +1,32 @@
+//go:build !windows
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// parseEnvReload maps [tasks.dev].env_reload to a signal; "restart" (or
+// empty) returns nil, meaning the process is restarted instead.
+func parseEnvReload(s string) (os.Signal, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	if v == "" || v == "RESTART" {
+		return nil, nil
+	}
+	switch strings.TrimPrefix(v, "SIG") {
+	case "HUP":
+		return syscall.SIGHUP, nil
+	case "USR1":
+		return syscall.SIGUSR1, nil
+	case "USR2":
+		return syscall.SIGUSR2, nil
+	case "INT":
+		return syscall.SIGINT, nil
+	case "TERM":
+		return syscall.SIGTERM, nil
+	}
+	return nil, fmt.Errorf("invalid env_reload %q (expected restart|SIGHUP|SIGUSR1|SIGUSR2|SIGINT|SIGTERM)", s)
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// parseEnvReload only supports "restart" on Windows, which has no SIGHUP.
+func parseEnvReload(s string) (os.Signal, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	if v == "" || v == "restart" {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("invalid env_reload %q (only restart is supported on Windows)", s)
+}
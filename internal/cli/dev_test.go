@@ -3,10 +3,13 @@ package cli
 import (
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	core "github.com/divijg19/rig/internal/rig"
 )
@@ -137,6 +140,211 @@ watch = ["*.go", "cmd/**/*.go"]
 	}
 }
 
+func TestDevPollModeDoesNotRequireReflex(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks.dev]
+command = "go run ."
+watch = ["**/*.go"]
+watch_mode = "poll"
+poll_interval = "250ms"
+`, 0o644)
+	writeRigLock(t, dir, []core.LockedTool{})
+
+	t.Chdir(dir)
+	rt, err := loadDevRuntime("never", io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rt.poll || rt.pollInterval != 250*time.Millisecond {
+		t.Fatalf("expected poll mode at 250ms, got poll=%t interval=%s", rt.poll, rt.pollInterval)
+	}
+	want := []string{"-c", "go run ."}
+	if rt.watcherPath != "sh" || !equalStrings(rt.watcherArgs, want) {
+		t.Fatalf("unexpected command: %s %#v", rt.watcherPath, rt.watcherArgs)
+	}
+}
+
+func TestDevWatcherExcludesGitignored(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks.dev]
+command = "go run ."
+watch = ["**/*.go"]
+ignore = ["gen/"]
+`, 0o644)
+	writeFile(t, filepath.Join(dir, ".gitignore"), "dist\n", 0o644)
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\nexit 0\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+
+	t.Chdir(dir)
+	rt, err := loadDevRuntime("never", io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"-s", "-r", `\.go$`, "-R", `^(?:.*/)?dist(?:/.*)?$`, "-R", `^(?:.*/)?gen/.*$`, "--", "sh", "-c", "go run ."}
+	if !equalStrings(rt.watcherArgs, want) {
+		t.Fatalf("unexpected watcher args: %#v", rt.watcherArgs)
+	}
+}
+
+func TestDevEnvFileLoadedIntoEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks.dev]
+command = "go run ."
+watch = ["**/*.go"]
+watch_mode = "poll"
+env_file = ".env"
+env_reload = "SIGHUP"
+`, 0o644)
+	writeFile(t, filepath.Join(dir, ".env"), "APP_SECRET=s3cr3t\n", 0o644)
+	writeRigLock(t, dir, []core.LockedTool{})
+
+	t.Chdir(dir)
+	rt, err := loadDevRuntime("never", io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rt.envReloadSignal == nil || rt.envReloadSignal.String() != "hangup" {
+		t.Fatalf("expected SIGHUP reload signal, got %v", rt.envReloadSignal)
+	}
+	found := false
+	for _, kv := range rt.env {
+		if kv == "APP_SECRET=s3cr3t" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected env_file value in env: %v", rt.env)
+	}
+
+	writeFile(t, filepath.Join(dir, ".env"), "APP_SECRET=rotated\n", 0o644)
+	if !rt.reloadEnv() {
+		t.Fatalf("reloadEnv failed")
+	}
+	for _, kv := range rt.env {
+		if kv == "APP_SECRET=rotated" {
+			return
+		}
+	}
+	t.Fatalf("expected reloaded env_file value in env: %v", rt.env)
+}
+
+func TestDevAppliesDevProfile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	t.Setenv("GOFLAGS", "")
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks.dev]
+command = "go run ."
+watch = ["**/*.go"]
+watch_mode = "poll"
+profile = "dev"
+env = { APP_MODE = "task" }
+
+[profile.dev]
+tags = ["dev"]
+flags = ["-race"]
+env = { APP_MODE = "profile", DEBUG = "1" }
+
+[profile.ci]
+tags = ["ci"]
+`, 0o644)
+	writeRigLock(t, dir, []core.LockedTool{})
+
+	t.Chdir(dir)
+	rt, err := loadDevRuntime("never", io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	env := strings.Join(rt.env, "\n")
+	for _, want := range []string{"GOFLAGS=-tags=dev -race", "RIG_PROFILE=dev", "DEBUG=1", "APP_MODE=task"} {
+		if !strings.Contains(env, want) {
+			t.Fatalf("expected %q in dev env:\n%s", want, env)
+		}
+	}
+
+	devProfile = "ci"
+	t.Cleanup(func() { devProfile = "" })
+	rt, err = loadDevRuntime("never", io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env := strings.Join(rt.env, "\n"); !strings.Contains(env, "GOFLAGS=-tags=ci") || strings.Contains(env, "DEBUG=1") {
+		t.Fatalf("--profile should override [tasks.dev].profile:\n%s", env)
+	}
+
+	devProfile = "missing"
+	if _, err := loadDevRuntime("never", io.Discard, io.Discard); err == nil || !strings.Contains(err.Error(), `profile "missing" not found`) {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
+
+func TestDevCwdEnvAndDependsOn(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+reflex = "latest"
+
+[tasks]
+generate = "touch generated.txt"
+
+[tasks.dev]
+command = "go run ."
+watch = ["**/*.go"]
+cwd = "cmd/api"
+depends_on = ["generate"]
+env = { APP_ENV = "dev" }
+`, 0o644)
+	if err := os.MkdirAll(filepath.Join(dir, "cmd", "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	reflexPath, reflexSHA := writeTool(t, dir, "reflex", "#!/bin/sh\nexit 0\n")
+	writeRigLock(t, dir, []core.LockedTool{lockToolEntry("reflex", reflexPath, reflexSHA)})
+
+	t.Chdir(dir)
+	rt, err := loadDevRuntime("never", io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root, _ := filepath.Abs(dir)
+	if rt.cwd != root {
+		t.Fatalf("reflex should run at the project root, got %s", rt.cwd)
+	}
+	wantCmd := "cd '" + filepath.Join(root, "cmd", "api") + "' && go run ."
+	if got := rt.watcherArgs[len(rt.watcherArgs)-1]; got != wantCmd {
+		t.Fatalf("unexpected command %q, want %q", got, wantCmd)
+	}
+	if !slices.Contains(rt.env, "APP_ENV=dev") {
+		t.Fatalf("expected task env in dev env")
+	}
+
+	if err := rt.runDependencies(); err != nil {
+		t.Fatalf("runDependencies: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "generated.txt")); err != nil {
+		t.Fatalf("expected depends_on task to run: %v", err)
+	}
+}
+
 func TestComputeWatchRegexOnlyDot(t *testing.T) {
 	got := computeWatchRegex([]string{"."})
 	if got != "." {
@@ -151,6 +359,20 @@ func TestComputeWatchRegexAlternation(t *testing.T) {
 	}
 }
 
+func TestBuildWatcherArgsUsesPowerShellWithoutSh(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sh fallback is not used on windows")
+	}
+	bin := t.TempDir()
+	writeFile(t, filepath.Join(bin, "pwsh"), "#!/bin/sh\nexit 0\n", 0o755)
+	t.Setenv("PATH", bin)
+	args := buildWatcherArgs([]string{"*.go"}, "go run .")
+	i := slices.Index(args, "--")
+	if i < 0 || i+1 >= len(args) || args[i+1] != "pwsh" || args[len(args)-1] != "go run ." {
+		t.Fatalf("expected reflex to run the command through pwsh, got %#v", args)
+	}
+}
+
 func TestDevCommandIsPassedVerbatim(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("shell-script based test")
@@ -244,3 +466,76 @@ func equalStrings(a []string, b []string) bool {
 	}
 	return true
 }
+
+func TestDevMembersRunTogether(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rig.toml"), `
+[workspace]
+members = ["./svc/api", "./svc/worker", "./lib"]
+`, 0o644)
+	for _, name := range []string{"api", "worker"} {
+		member := filepath.Join(dir, "svc", name)
+		writeFile(t, filepath.Join(member, "rig.toml"), fmt.Sprintf(`
+[tasks.dev]
+command = "echo hello-%s"
+watch = ["**/*.go"]
+watch_mode = "poll"
+`, name), 0o644)
+		writeRigLock(t, member, nil)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	conf, path, err := core.LoadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := core.ResolveDevMembers(conf, path, []string{"lib"}); err == nil || !strings.Contains(err.Error(), "has no rig.toml") {
+		t.Fatalf("expected a member without rig.toml to be rejected, got %v", err)
+	}
+	if _, err := core.ResolveDevMembers(conf, path, []string{"apu"}); err == nil || !strings.Contains(err.Error(), `did you mean "api"`) {
+		t.Fatalf("expected a suggestion for a misspelled member, got %v", err)
+	}
+	members, err := core.ResolveDevMembers(conf, path, []string{"all"})
+	if err != nil || len(members) != 2 || members[0].Name != "api" || members[1].Name != "worker" {
+		t.Fatalf("ResolveDevMembers(all) = %+v, %v", members, err)
+	}
+
+	out := &syncBuffer{}
+	var rts []*DevRuntime
+	for _, m := range members {
+		prefix := "[" + m.Name + "] "
+		rt, err := loadDevRuntimeIn(m.Dir, "never", core.NewPrefixWriter(out, prefix), core.NewPrefixWriter(out, prefix))
+		if err != nil {
+			t.Fatalf("member %s: %v", m.Name, err)
+		}
+		rt.name = m.Name
+		rts = append(rts, rt)
+	}
+	exitCh := make(chan struct{})
+	done := make(chan error, 1)
+	go func() { done <- runDevMembers(rts, nil, exitCh) }()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !strings.Contains(out.String(), "[api] hello-api") || !strings.Contains(out.String(), "[worker] hello-worker") {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected prefixed output from both members:\n%s", out)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	close(exitCh)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("runDevMembers: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("members did not stop together:\n%s", out)
+	}
+	if got := strings.Count(out.String(), "🛑 dev stopped"); got != 2 {
+		t.Fatalf("expected both members to stop, got %d:\n%s", got, out)
+	}
+}
@@ -3,24 +3,42 @@
 package cli
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	core "github.com/divijg19/rig/internal/rig"
 	"github.com/spf13/cobra"
 )
 
+var (
+	doctorReport    bool
+	doctorReportOut string
+	doctorYes       bool
+)
+
 var doctorCmd = &cobra.Command{
 	Use:   "doctor [name]",
 	Short: "Check your development environment and tooling",
-	Long:  "Diagnose the toolchain and rig-managed tools.",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Diagnose the toolchain and rig-managed tools.
+
+With --report, bundle the results for a bug report: versions, OS, doctor
+output, rig.toml and rig.lock, go env, and recent .rig/logs, with secrets
+redacted, written as a .tar.gz after a preview of what it contains.`,
+	Args: cobra.MaximumNArgs(1),
 	Example: `
 	rig doctor
+	rig doctor --report
+	rig doctor --report --yes -o report.tar.gz
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 1 {
+			if doctorReport {
+				return fmt.Errorf("--report does not take a tool name")
+			}
 			return toolsDoctorCmd.RunE(toolsDoctorCmd, args)
 		}
 
@@ -29,32 +47,96 @@ var doctorCmd = &cobra.Command{
 			return err
 		}
 		rep, err := core.Doctor("", version, exePath)
+		if doctorReport {
+			var text bytes.Buffer
+			if err != nil {
+				fmt.Fprintf(&text, "error: %v\n", err)
+			} else {
+				printDoctorReport(&text, rep)
+			}
+			return writeDoctorBundle(rep.ConfigPath, text.String())
+		}
 		if err != nil {
 			return err
 		}
-		fmt.Printf("version_present: %t\n", rep.VersionPresent)
-		fmt.Printf("go_available: %t\n", rep.GoAvailable)
-		fmt.Printf("go_version: %s\n", rep.GoVersion)
-		fmt.Printf("go_matches_lock: %t\n", rep.GoMatchesLock)
-		fmt.Printf("config_path: %s\n", rep.ConfigPath)
-		fmt.Printf("lock_path: %s\n", rep.LockPath)
-		fmt.Printf("has_config: %t\n", rep.HasConfig)
-		fmt.Printf("has_lock: %t\n", rep.HasLock)
-		fmt.Printf("lock_valid: %t\n", rep.LockValid)
-		fmt.Printf("bin_dir: %s\n", rep.BinDir)
-		fmt.Printf("bin_dir_exists: %t\n", rep.BinDirExists)
-		fmt.Printf("bin_dir_writable: %t\n", rep.BinWritable)
-		fmt.Printf("executable_path: %s\n", rep.ExecutablePath)
-		fmt.Printf("executable_writable: %t\n", rep.ExecutableWritable)
-		for _, e := range rep.Errors {
-			if strings.TrimSpace(e) != "" {
-				fmt.Printf("error: %s\n", e)
-			}
-		}
+		printDoctorReport(os.Stdout, rep)
 		return nil
 	},
 }
 
+func printDoctorReport(w io.Writer, rep core.DoctorReport) {
+	fmt.Fprintf(w, "version_present: %t\n", rep.VersionPresent)
+	fmt.Fprintf(w, "go_available: %t\n", rep.GoAvailable)
+	fmt.Fprintf(w, "go_version: %s\n", rep.GoVersion)
+	fmt.Fprintf(w, "go_matches_lock: %t\n", rep.GoMatchesLock)
+	fmt.Fprintf(w, "config_path: %s\n", rep.ConfigPath)
+	fmt.Fprintf(w, "lock_path: %s\n", rep.LockPath)
+	fmt.Fprintf(w, "has_config: %t\n", rep.HasConfig)
+	fmt.Fprintf(w, "has_lock: %t\n", rep.HasLock)
+	fmt.Fprintf(w, "lock_valid: %t\n", rep.LockValid)
+	fmt.Fprintf(w, "bin_dir: %s\n", rep.BinDir)
+	fmt.Fprintf(w, "bin_dir_exists: %t\n", rep.BinDirExists)
+	fmt.Fprintf(w, "bin_dir_writable: %t\n", rep.BinWritable)
+	fmt.Fprintf(w, "cache_dir: %s\n", rep.CacheDir)
+	fmt.Fprintf(w, "executable_path: %s\n", rep.ExecutablePath)
+	fmt.Fprintf(w, "executable_writable: %t\n", rep.ExecutableWritable)
+	for _, r := range rep.Requires {
+		fmt.Fprintf(w, "requires_%s: %s\n", r.Name, r.Status)
+	}
+	for _, c := range rep.GoEnv {
+		fmt.Fprintf(w, "goenv_%s: %s (%s)\n", c.Var, c.Detail, c.Value)
+	}
+	for _, b := range rep.StaleBins {
+		fmt.Fprintf(w, "stale_bin_%s: %s\n", b.Bin, b.Reason)
+	}
+	for _, e := range rep.Errors {
+		if strings.TrimSpace(e) != "" {
+			fmt.Fprintf(w, "error: %s\n", e)
+		}
+	}
+}
+
+// writeDoctorBundle previews the report files, asks for confirmation unless
+// --yes, and writes the archive.
+func writeDoctorBundle(configPath, doctorText string) error {
+	files := core.BuildReport(configPath, version, doctorText)
+	out := doctorReportOut
+	if out == "" {
+		out = "rig-report-" + time.Now().Format("20060102-150405") + ".tar.gz"
+	}
+
+	stderr := newStyledWriter(os.Stderr)
+	stderr.linef(ansiBoldCyan, "📋 %s will contain:", out)
+	redacted := 0
+	for _, f := range files {
+		note := ""
+		if f.Redactions > 0 {
+			note = fmt.Sprintf("  (%d value(s) redacted)", f.Redactions)
+		}
+		fmt.Fprintf(os.Stderr, "  %-24s %7d bytes%s\n", f.Name, len(f.Data), note)
+		redacted += f.Redactions
+	}
+	fmt.Fprintf(os.Stderr, "  %d secret-looking value(s) redacted; review the archive before sharing it\n", redacted)
+
+	if !doctorYes {
+		if !isTTY(os.Stdin) {
+			return fmt.Errorf("refusing to write %s without confirmation; pass --yes", out)
+		}
+		if !confirmPrompt(os.Stderr, "Write report?") {
+			fmt.Fprintln(os.Stderr, "Report cancelled")
+			return nil
+		}
+	}
+	if err := core.WriteReportArchive(out, files); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	stderr.linef(ansiGreen, "📦 Wrote %s; attach it to your GitHub issue", out)
+	return nil
+}
+
 func init() {
+	doctorCmd.Flags().BoolVar(&doctorReport, "report", false, "write a redacted diagnostic bundle (.tar.gz) for bug reports")
+	doctorCmd.Flags().StringVarP(&doctorReportOut, "output", "o", "", "report path (default rig-report-<timestamp>.tar.gz)")
+	doctorCmd.Flags().BoolVarP(&doctorYes, "yes", "y", false, "write the report without asking for confirmation")
 	rootCmd.AddCommand(doctorCmd)
 }
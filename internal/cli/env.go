@@ -0,0 +1,203 @@
+// internal/cli/env.go
+
+package cli
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	envDescribe bool
+	envJSON     bool
+	envPathJSON bool
+)
+
+// envSetting is one row of `rig env` output.
+type envSetting struct {
+	core.EnvVarDoc
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Show the environment variables rig reads and sets",
+	Long: `Show the environment variables rig reads or sets for the commands it runs,
+with their current values and where each value comes from. Without
+--describe only variables that have a value are listed.`,
+	Args: cobra.NoArgs,
+	Example: `
+  rig env
+  rig env --describe
+  rig env --json
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings := collectEnvSettings()
+		if envJSON {
+			b, err := stdjson.MarshalIndent(settings, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+		if !envDescribe {
+			settings = slices.DeleteFunc(settings, func(s envSetting) bool { return s.Value == "" })
+		}
+		width := 0
+		for _, s := range settings {
+			width = max(width, len(s.Name))
+		}
+		out := newStyledWriter(os.Stdout)
+		for _, s := range settings {
+			value := s.Value + " " + out.paint(ansiDim, "("+s.Source+")")
+			if s.Value == "" {
+				value = out.paint(ansiDim, "(unset)")
+			}
+			fmt.Printf("%s  %s\n", out.paint(ansiBoldCyan, fmt.Sprintf("%-*s", width, s.Name)), value)
+			if !envDescribe {
+				continue
+			}
+			fmt.Printf("%*s  %s\n", width, "", s.Summary)
+			if s.Sets != "" {
+				fmt.Printf("%*s  rig sets it for %s.\n", width, "", s.Sets)
+			}
+		}
+		return nil
+	},
+}
+
+// collectEnvSettings resolves every core.EnvVarDocs entry. Values rig sets
+// for the current project (the tool bin directory, [cache].go, the user
+// config [proxy]) win over the inherited environment.
+func collectEnvSettings() []envSetting {
+	var conf *cfg.Config
+	configPath := ""
+	if c, p, err := cfg.Load(""); err == nil {
+		conf, configPath = c, p
+	}
+	proxy := map[string]string{}
+	for _, kv := range userConf.Proxy.Env() {
+		k, v, _ := strings.Cut(kv, "=")
+		if _, overridden := userProxyOverridden[k]; !overridden {
+			proxy[k] = v
+		}
+	}
+
+	out := make([]envSetting, 0, len(core.EnvVarDocs))
+	for _, d := range core.EnvVarDocs {
+		s := envSetting{EnvVarDoc: d}
+		switch {
+		case d.Name == "GOBIN" && configPath != "":
+			s.Value, s.Source = core.BinDir(configPath), "rig, for tool installs: "+core.BinDirSource(configPath)
+		case d.Name == "PATH" && configPath != "":
+			s.Value, s.Source = core.BinDir(configPath)+string(os.PathListSeparator)+"$PATH", "rig, for tasks and tools"
+		case d.Name == "GOWORK" && configPath != "":
+			s.Value, s.Source = "off", "rig, for tool installs"
+		case d.Name == "GOCACHE" && conf != nil && core.GoCacheDir(conf, configPath) != "":
+			s.Value, s.Source = core.GoCacheDir(conf, configPath), "[cache].go"
+		case d.Name == "RIG_PLUGIN_COOKIE":
+			s.Value, s.Source = core.PluginCookie, "rig, for plugins"
+		case d.Name == "RIG_PLUGIN_PROTOCOL":
+			s.Value, s.Source = fmt.Sprint(core.PluginProtocolVersion), "rig, for plugins"
+		case proxy[d.Name] != "":
+			s.Value, s.Source = proxy[d.Name], "user config [proxy]"
+		default:
+			if v, ok := os.LookupEnv(d.Name); ok {
+				s.Value, s.Source = v, "environment"
+			} else {
+				s.Source = "unset"
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// envPathCmd shows the PATH rig gives commands and where tools resolve on it.
+var envPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show the PATH rig uses and where each tool resolves from",
+	Long: `Show the PATH rig gives tasks, tools, and dev commands, in search order:
+the project's tool bin directory first, then the inherited PATH without empty
+entries and repeats. Then show which directory go and each [tools] binary
+resolve from, and which other copies further down the PATH they shadow.`,
+	Args: cobra.NoArgs,
+	Example: `
+  rig env path
+  rig env path --json
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, configPath, err := loadConfigOrFail()
+		if err != nil {
+			return err
+		}
+		rep := core.InspectPath(conf, configPath, os.Environ())
+		if envPathJSON {
+			b, err := stdjson.MarshalIndent(rep, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+		printPathReport(newStyledWriter(os.Stdout), rep)
+		return nil
+	},
+}
+
+func printPathReport(out *styledWriter, rep core.PathReport) {
+	fmt.Fprintln(out.w, out.paint(ansiBoldCyan, "PATH"))
+	width := len(fmt.Sprint(len(rep.Dirs)))
+	for i, d := range rep.Dirs {
+		note := ""
+		switch {
+		case d.Source == "rig":
+			note = " " + out.paint(ansiDim, "(rig tool bin)")
+		case d.Missing:
+			note = " " + out.paint(ansiDim, "(missing)")
+		}
+		fmt.Fprintf(out.w, "  %*d  %s%s\n", width, i+1, d.Dir, note)
+	}
+	for _, d := range rep.Dropped {
+		fmt.Fprintf(out.w, "  %*s  %s\n", width, "", out.paint(ansiDim, d+" (duplicate, dropped)"))
+	}
+
+	fmt.Fprintln(out.w)
+	fmt.Fprintln(out.w, out.paint(ansiBoldCyan, "tools"))
+	nameWidth := 0
+	for _, t := range rep.Tools {
+		nameWidth = max(nameWidth, len(t.Name))
+	}
+	for _, t := range rep.Tools {
+		switch {
+		case t.Path == "" && t.Managed:
+			out.linef(ansiRed, "  %-*s  not found (run 'rig sync')", nameWidth, t.Name)
+		case t.Path == "":
+			out.linef(ansiRed, "  %-*s  not found", nameWidth, t.Name)
+		case t.Managed && !t.FromBin:
+			out.linef(ansiYellow, "  %-*s  %s (not the pinned copy in the tool bin; run 'rig sync')", nameWidth, t.Name, t.Path)
+		default:
+			fmt.Fprintf(out.w, "  %-*s  %s\n", nameWidth, t.Name, t.Path)
+		}
+		for _, p := range t.Shadowed {
+			fmt.Fprintf(out.w, "  %-*s  %s\n", nameWidth, "", out.paint(ansiDim, "shadows "+p))
+		}
+	}
+}
+
+func init() {
+	envPathCmd.Flags().BoolVar(&envPathJSON, "json", false, "print machine-readable JSON")
+	envCmd.AddCommand(envPathCmd)
+	envCmd.Flags().BoolVar(&envDescribe, "describe", false, "list every variable with what it does")
+	envCmd.Flags().BoolVar(&envJSON, "json", false, "print machine-readable JSON")
+	rootCmd.AddCommand(envCmd)
+}
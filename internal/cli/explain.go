@@ -0,0 +1,88 @@
+// internal/cli/explain.go
+
+package cli
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"os"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var explainJSON bool
+
+// explainCmd documents the error codes printed as "Error [RIGnnn]: ...".
+var explainCmd = &cobra.Command{
+	Use:   "explain [code]",
+	Short: "Explain an error code (cause, fix, related commands)",
+	Long:  "Print the cause, resolution steps, and related commands for a rig error code such as RIG014. Without a code, list every code.",
+	Args:  cobra.MaximumNArgs(1),
+	Example: `
+  rig explain RIG014
+  rig explain 1
+  rig explain
+`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		descs := make(map[string]string, len(core.ErrorDocs))
+		for _, d := range core.ErrorDocs {
+			descs[string(d.Code)] = d.Title
+		}
+		return describedCandidates(descs, toComplete), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			if explainJSON {
+				b, err := stdjson.MarshalIndent(core.ErrorDocs, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+			for _, d := range core.ErrorDocs {
+				fmt.Printf("%s  %s\n", d.Code, d.Title)
+			}
+			return nil
+		}
+		d, ok := core.ExplainError(args[0])
+		if !ok {
+			return fmt.Errorf("unknown error code %q (run 'rig explain' to list codes)", args[0])
+		}
+		if explainJSON {
+			b, err := stdjson.MarshalIndent(d, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+		stdout := newStyledWriter(os.Stdout)
+		stdout.linef(ansiBoldCyan, "%s: %s", d.Code, d.Title)
+		fmt.Println()
+		fmt.Println("Cause:")
+		fmt.Printf("  %s\n", d.Cause)
+		fmt.Println()
+		fmt.Println("Resolution:")
+		for i, step := range d.Resolution {
+			fmt.Printf("  %d. %s\n", i+1, step)
+		}
+		if len(d.Related) > 0 {
+			fmt.Println()
+			fmt.Println("Related commands:")
+			for _, r := range d.Related {
+				fmt.Printf("  %s\n", r)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	explainCmd.Flags().BoolVar(&explainJSON, "json", false, "print machine-readable JSON")
+	rootCmd.AddCommand(explainCmd)
+}
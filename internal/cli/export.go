@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutput string
+	exportImage  string
+	exportCheck  bool
+	exportForce  bool
+)
+
+// composeHeader starts every file `rig export compose` writes; a file without
+// it is not overwritten unless --force is given.
+const composeHeader = "# Generated by `rig export compose`"
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Generate files for other tools from rig.toml",
+}
+
+var exportComposeCmd = &cobra.Command{
+	Use:   "compose [task...]",
+	Short: "Generate docker-compose.yml from service tasks",
+	Long: `Write a docker-compose.yml with one service per task, so containerized runs
+use the commands, env, and ports already declared in rig.toml.
+
+Without arguments [tasks.dev] and every task that declares ports are
+exported; composite tasks stand for their steps. Each service runs the
+project's Go image with the project mounted at /app.`,
+	Example: `
+  rig export compose
+  rig export compose api worker -o deploy/docker-compose.yml
+  rig export compose --check
+`,
+	ValidArgsFunction: completeTaskNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, path, err := loadConfigOrFail()
+		if err != nil {
+			return err
+		}
+		out := strings.TrimSpace(exportOutput)
+		if out == "" {
+			out = filepath.Join(filepath.Dir(path), "docker-compose.yml")
+		}
+		projectDir := "."
+		if out != "-" {
+			if out, err = filepath.Abs(out); err != nil {
+				return err
+			}
+			if projectDir, err = filepath.Rel(filepath.Dir(out), filepath.Dir(path)); err != nil {
+				return err
+			}
+		}
+		content, err := core.ExportCompose(conf, path, core.ComposeOptions{Tasks: args, Image: exportImage, ProjectDir: projectDir})
+		if err != nil {
+			return err
+		}
+		if out == "-" {
+			fmt.Print(content)
+			return nil
+		}
+		name := getRelativePath(out)
+		old, err := os.ReadFile(out)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if exportCheck {
+			if string(old) != content {
+				return fmt.Errorf("%s is out of sync with rig.toml; run 'rig export compose'", name)
+			}
+			fmt.Printf("%s in sync\n", name)
+			return nil
+		}
+		if string(old) == content {
+			fmt.Printf("%s already up to date\n", name)
+			return nil
+		}
+		if len(old) > 0 && !strings.HasPrefix(string(old), composeHeader) && !exportForce {
+			return fmt.Errorf("%s was not generated by rig; pass --force to overwrite it", name)
+		}
+		if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(out, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+		fmt.Printf("wrote %s (%d service(s))\n", name, strings.Count(content, "\n    image: "))
+		return nil
+	},
+}
+
+func init() {
+	exportComposeCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "file to write (default: docker-compose.yml next to rig.toml; - for stdout)")
+	exportComposeCmd.Flags().StringVar(&exportImage, "image", "", "image for every service (default: golang:<project Go version>)")
+	exportComposeCmd.Flags().BoolVar(&exportCheck, "check", false, "fail if the file differs from rig.toml without writing")
+	exportComposeCmd.Flags().BoolVar(&exportForce, "force", false, "overwrite a compose file rig did not generate")
+	exportCmd.AddCommand(exportComposeCmd)
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fmtCheck  bool
+	fmtStaged bool
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "Format Go sources (gofumpt when pinned, otherwise gofmt)",
+	Long:  "Format Go files under the project root. Uses gofumpt from .rig/bin when declared in [tools], otherwise gofmt from the Go toolchain.",
+	Args:  cobra.NoArgs,
+	Example: `
+  rig fmt
+  rig fmt --check
+  rig fmt --staged
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		res, err := core.Fmt("", core.FmtOptions{Check: fmtCheck, Staged: fmtStaged})
+		if err != nil {
+			return err
+		}
+		if !fmtCheck {
+			fmt.Printf("formatted %d file(s) with %s\n", res.Files, res.Formatter)
+			return nil
+		}
+		for _, f := range res.Unformatted {
+			fmt.Println(f)
+		}
+		if len(res.Unformatted) > 0 {
+			return fmt.Errorf("%d file(s) need formatting; run 'rig fmt'", len(res.Unformatted))
+		}
+		return nil
+	},
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "list unformatted files and fail without rewriting")
+	fmtCmd.Flags().BoolVar(&fmtStaged, "staged", false, "only format git-staged .go files")
+	rootCmd.AddCommand(fmtCmd)
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+// guardExempt lists commands that never print the root/directory warnings.
+var guardExempt = map[string]struct{}{
+	"__complete": {},
+	"completion": {},
+	"explain":    {},
+	"help":       {},
+	"init":       {},
+	"version":    {},
+}
+
+// maxConfigDepth is the user's guard.max_config_depth, or the default.
+func maxConfigDepth() int {
+	if d := userConf.Guard.MaxConfigDepth; d != nil {
+		return *d
+	}
+	return core.DefaultMaxConfigDepth
+}
+
+// warnUnsafeContext warns before a command when rig runs as root in a
+// project owned by another user, or when the rig.toml it found is far above
+// the working directory. Both lead to confusing permission and path errors
+// later, so the warning comes first.
+func warnUnsafeContext(cmd *cobra.Command) {
+	if cmd == rootCmd || flagSet(cmd, "quiet") || strings.TrimSpace(os.Getenv("RIG_LAUNCHED")) != "" {
+		return
+	}
+	for c := cmd; c != nil && c != rootCmd; c = c.Parent() {
+		if _, ok := guardExempt[c.Name()]; ok {
+			return
+		}
+	}
+	path, err := cfg.LocateConfig("")
+	if err != nil {
+		return
+	}
+	stderr := newStyledWriter(os.Stderr)
+	if uid, ok := core.RootInUserProject(path); ok && !userConf.Guard.AllowRoot {
+		stderr.linef(ansiYellow, "⚠️  running as root in a project owned by uid %d; files rig installs (.rig/bin, rig.lock) will be owned by root", uid)
+		stderr.linef(ansiYellow, "   run rig as that user, or set guard.allow_root = true in the user config")
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	if limit := maxConfigDepth(); limit > 0 {
+		if depth := core.ConfigDepth(path, cwd); depth > limit {
+			stderr.linef(ansiYellow, "⚠️  using %s, %d directories above the current directory", path, depth)
+			stderr.linef(ansiYellow, "   run rig from %s if that is the project you meant (guard.max_config_depth = %d)", filepath.Dir(path), limit)
+		}
+	}
+}
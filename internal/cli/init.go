@@ -3,14 +3,20 @@
 package cli
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/divijg19/rig/internal/config"
+	core "github.com/divijg19/rig/internal/rig"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +34,8 @@ var (
 	initName      string
 	initVersion   string
 	initLicense   string
+	initFrom      string
+	initAnswersIn string
 )
 
 // initCmd represents the init command
@@ -44,11 +52,23 @@ Use --dev to add a watcher-backed dev task and reflex tool support.`,
   rig init --dev --ci
   rig init --minimal
   rig init --monorepo -C ./workspace
+  rig init --from .
+  rig init --answers answers.toml
+  echo '{"name": "api", "dev_watcher": "poll"}' | rig init --answers -
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		applyInitUserDefaults(cmd)
+		var answers *initAnswers
+		if initAnswersIn != "" {
+			var err error
+			if answers, err = loadInitAnswers(initAnswersIn, os.Stdin); err != nil {
+				return err
+			}
+			answers.applyLayout(cmd.Flags().Changed)
+		}
 		targetDirectory := initDirectory
 		if targetDirectory == "" {
-			targetDirectory = "."
+			targetDirectory = firstNonEmpty(initFrom, ".")
 		}
 		if err := os.MkdirAll(targetDirectory, 0o755); err != nil {
 			return fmt.Errorf("create target dir: %w", err)
@@ -65,32 +85,60 @@ Use --dev to add a watcher-backed dev task and reflex tool support.`,
 		if initMinimal && initCI {
 			return fmt.Errorf("--minimal and --ci are mutually exclusive")
 		}
+		if initFrom != "" {
+			for _, f := range []string{"dev", "minimal", "ci", "monorepo"} {
+				if cmd.Flags().Changed(f) {
+					return fmt.Errorf("--from cannot be combined with --%s", f)
+				}
+			}
+		}
 
-		if !initYes {
+		cmd.SilenceUsage = true
+		var err error
+		var ask *prompter
+		if !initYes && answers == nil {
 			fmt.Printf("Create rig.toml in %s\n\n", targetDirectory)
+			ask = newPrompter(os.Stdin, os.Stdout)
 		}
 
 		projectName := initName
+		if projectName == "" && answers != nil {
+			projectName = answers.Name
+		}
 		if projectName == "" {
 			base := filepath.Base(targetDirectory)
+			if mod := goModuleName(initFrom); mod != "" {
+				base = mod
+			}
 			if base == "." || base == string(os.PathSeparator) || base == "" {
 				projectName = config.GetDefaultProjectName()
 			} else {
 				projectName = strings.ToLower(base)
 			}
-			if !initYes {
-				projectName = askString(fmt.Sprintf("? project name: (%s)", projectName), projectName)
+			if ask != nil {
+				if projectName, err = ask.Input("project name", projectName, validateInitValue); err != nil {
+					return initPromptError(err)
+				}
 			}
 		}
 
 		version := firstNonEmpty(initVersion, "0.1.0")
-		if !initYes {
-			version = askString(fmt.Sprintf("? version: (%s)", version), version)
-		}
-
 		license := firstNonEmpty(initLicense, "MIT")
-		if !initYes {
-			license = askString(fmt.Sprintf("? license: (%s)", license), license)
+		if answers != nil {
+			if !cmd.Flags().Changed("version") {
+				version = firstNonEmpty(answers.Version, version)
+			}
+			if !cmd.Flags().Changed("license") {
+				license = firstNonEmpty(answers.License, license)
+			}
+		}
+		if ask != nil {
+			if version, err = ask.Input("version", version, validateInitVersion); err != nil {
+				return initPromptError(err)
+			}
+			if license, err = askInitLicense(ask, license); err != nil {
+				return initPromptError(err)
+			}
 		}
 
 		goVersion := getGoVersion()
@@ -98,59 +146,95 @@ Use --dev to add a watcher-backed dev task and reflex tool support.`,
 			goVersion = strings.TrimPrefix(runtime.Version(), "go")
 		}
 
+		if initFrom != "" {
+			return initFromExisting(ask, targetDirectory, configPath, projectName, version, license, goVersion)
+		}
+
+		watcher := ""
+		if initDev {
+			watcher = "reflex"
+		}
+		var profiles []string
+		if answers != nil && !initMinimal {
+			if !cmd.Flags().Changed("dev") {
+				watcher = answers.watcher(watcher)
+			}
+			profiles = answers.Profiles
+		}
+		if ask != nil {
+			if watcher, profiles, err = askInitTemplate(cmd, ask, watcher); err != nil {
+				return initPromptError(err)
+			}
+		}
+
 		mainToml := buildMainConfig(projectName, version, license)
+		members, err := detectGoWorkMembers(targetDirectory)
+		if err != nil {
+			return err
+		}
 		var includes []string
 		var tasksToml, toolsToml string
 		includeTasks := !initMinimal
 		if initMonorepo {
 			if includeTasks {
-				tasksToml = buildTasksConfig(initDev, initCI)
+				tasksToml = buildTasksConfig(watcher, initCI) + buildProfilesConfig(profiles)
 				includes = append(includes, "rig.tasks.toml")
 			}
-			toolsToml = buildToolsConfig(goVersion, initDev)
+			toolsToml = buildToolsConfig(goVersion, watcher)
 			includes = append(includes, "rig.tools.toml")
 			if len(includes) > 0 {
 				mainToml = injectInclude(mainToml, includes)
 			}
 		} else {
 			if includeTasks {
-				mainToml += "\n" + buildTasksConfig(initDev, initCI)
+				mainToml += "\n" + buildTasksConfig(watcher, initCI)
 			}
-			mainToml += "\n" + buildToolsConfig(goVersion, initDev)
+			mainToml += "\n" + buildToolsConfig(goVersion, watcher)
+			mainToml += buildProfilesConfig(profiles)
+		}
+		if len(members) > 0 {
+			mainToml += "\n" + buildWorkspaceConfig(members)
 		}
 
-		// Write files
-		if err := os.WriteFile(configPath, []byte(mainToml), 0o644); err != nil {
-			return fmt.Errorf("write %s: %w", configPath, err)
+		writes := []plannedWrite{{Path: configPath, Content: mainToml}}
+		rigDirectory := filepath.Join(targetDirectory, ".rig")
+		if tasksToml != "" {
+			writes = append(writes, plannedWrite{Path: filepath.Join(rigDirectory, "rig.tasks.toml"), Content: tasksToml})
+		}
+		if toolsToml != "" {
+			writes = append(writes, plannedWrite{Path: filepath.Join(rigDirectory, "rig.tools.toml"), Content: toolsToml})
+		}
+		if initForce {
+			ok, err := confirmOverwrite(ask, writes)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("Aborted; nothing written.")
+				return nil
+			}
 		}
-		wrote := []string{getRelativePath(configPath)}
+
+		// Write files
 		if initMonorepo {
-			rigDirectory := filepath.Join(targetDirectory, ".rig")
 			if err := os.MkdirAll(rigDirectory, 0o755); err != nil {
 				return fmt.Errorf("create .rig dir: %w", err)
 			}
-			if tasksToml != "" {
-				p := filepath.Join(rigDirectory, "rig.tasks.toml")
-				if err := os.WriteFile(p, []byte(tasksToml), 0o644); err != nil {
-					return fmt.Errorf("write %s: %w", p, err)
-				}
-				wrote = append(wrote, getRelativePath(p))
-			}
-			if toolsToml != "" {
-				p := filepath.Join(rigDirectory, "rig.tools.toml")
-				if err := os.WriteFile(p, []byte(toolsToml), 0o644); err != nil {
-					return fmt.Errorf("write %s: %w", p, err)
-				}
-				wrote = append(wrote, getRelativePath(p))
+		}
+		var wrote []string
+		for _, w := range writes {
+			if err := os.WriteFile(w.Path, []byte(w.Content), 0o644); err != nil {
+				return fmt.Errorf("write %s: %w", w.Path, err)
 			}
+			wrote = append(wrote, getRelativePath(w.Path))
 		}
 
 		if err := ensureRigIgnored(targetDirectory); err != nil {
 			return err
 		}
 
-		fmt.Printf("✅ rig.toml created successfully!\n")
-		fmt.Println("📋 Created:")
+		statusf(os.Stdout, "✅ rig.toml created successfully!")
+		statusf(os.Stdout, "📋 Created:")
 		for _, p := range wrote {
 			fmt.Printf("  • %s\n", p)
 		}
@@ -169,23 +253,249 @@ func init() {
 	initCmd.Flags().StringVar(&initLicense, "license", "MIT", "Project license")
 	initCmd.Flags().StringVar(&initVersion, "version", "0.1.0", "Project version")
 	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "Accept defaults (non-interactive)")
+	initCmd.Flags().StringVar(&initFrom, "from", "", "Scan an existing project and propose a rig.toml for it")
+	initCmd.Flags().Lookup("from").NoOptDefVal = "."
+	initCmd.Flags().StringVar(&initAnswersIn, "answers", "", "Answer every prompt from a TOML or JSON file (- reads JSON from stdin)")
 
 	rootCmd.AddCommand(initCmd)
 }
 
-// Helper functions
-func askString(prompt, defaultValue string) string {
-	if initYes {
-		return defaultValue
+// initFromExisting scans the project in initFrom, previews the proposed
+// rig.toml as a diff against any existing one, and writes it on confirmation.
+func initFromExisting(ask *prompter, targetDirectory, configPath, name, version, license, goVersion string) error {
+	scan, err := core.ScanProject(initFrom)
+	if err != nil {
+		return fmt.Errorf("scan %s: %w", initFrom, err)
+	}
+	if scan.GoVersion == "" {
+		scan.GoVersion = goVersion
+	}
+	proposed := scan.Manifest(name, version, license)
+
+	stdout := newStyledWriter(os.Stdout)
+	stdout.linef(ansiBoldCyan, "🔎 Scanned %s", initFrom)
+	fmt.Printf("  main packages: %s\n", listOrNone(scan.Mains))
+	fmt.Printf("  tests:         %t\n", scan.HasTests)
+	fmt.Printf("  dockerfiles:   %s\n", listOrNone(scan.Dockerfiles))
+	fmt.Printf("  ci:            %s\n", listOrNone(scan.CIFiles))
+	linters := make([]string, 0, len(scan.LinterConfigs))
+	for tool, file := range scan.LinterConfigs {
+		linters = append(linters, tool+" ("+file+")")
+	}
+	sort.Strings(linters)
+	fmt.Printf("  linters:       %s\n", listOrNone(linters))
+	fmt.Println()
+
+	existing, _ := os.ReadFile(configPath)
+	fmt.Printf("Proposed %s:\n", getRelativePath(configPath))
+	for _, line := range core.DiffLines(string(existing), proposed) {
+		switch line[0] {
+		case '+':
+			stdout.linef(ansiGreen, "%s", line)
+		case '-':
+			stdout.linef(ansiRed, "%s", line)
+		default:
+			fmt.Println(line)
+		}
+	}
+	fmt.Println()
+	if ask != nil {
+		ok, err := ask.Confirm("write rig.toml", true)
+		if err != nil && !errors.Is(err, errPromptAborted) {
+			return err
+		}
+		if !ok {
+			fmt.Println("Aborted; nothing written.")
+			return nil
+		}
+	}
+
+	if err := os.WriteFile(configPath, []byte(proposed), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", configPath, err)
+	}
+	if err := ensureRigIgnored(targetDirectory); err != nil {
+		return err
+	}
+	statusf(os.Stdout, "✅ rig.toml created successfully!")
+	fmt.Println("Next: run 'rig sync' to install the detected tools.")
+	return nil
+}
+
+// goModuleName returns the last element of the module path declared in
+// dir/go.mod, or "" when dir is empty or has no go.mod.
+func goModuleName(dir string) string {
+	if dir == "" {
+		return ""
 	}
-	fmt.Printf("%s ", prompt)
-	reader := bufio.NewReader(os.Stdin)
-	line, _ := reader.ReadString('\n')
-	line = strings.TrimSpace(line)
-	if line == "" {
-		return defaultValue
+	b, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
 	}
-	return line
+	mod, err := core.ParseGoMod(b)
+	if err != nil || mod.Module == "" {
+		return ""
+	}
+	base := path.Base(mod.Module)
+	if _, err := strconv.Atoi(strings.TrimPrefix(base, "v")); err == nil && strings.HasPrefix(base, "v") {
+		base = path.Base(path.Dir(mod.Module))
+	}
+	return base
+}
+
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
+
+// applyInitUserDefaults applies [init] from the user config. The template
+// presets are used only when no layout flag was given.
+func applyInitUserDefaults(cmd *cobra.Command) {
+	if !cmd.Flags().Changed("license") && strings.TrimSpace(userConf.Init.License) != "" {
+		initLicense = userConf.Init.License
+	}
+	for _, f := range []string{"dev", "minimal", "ci", "monorepo"} {
+		if cmd.Flags().Changed(f) {
+			return
+		}
+	}
+	for _, p := range userConf.Init.Presets() {
+		switch p {
+		case "dev":
+			initDev = true
+		case "minimal":
+			initMinimal = true
+		case "ci":
+			initCI = true
+		case "monorepo":
+			initMonorepo = true
+		}
+	}
+}
+
+// initLicenses are offered by the license prompt, which also accepts any
+// other identifier.
+var initLicenses = []string{"MIT", "Apache-2.0", "BSD-3-Clause", "GPL-3.0-or-later", "MPL-2.0", "Unlicense"}
+
+// initProfiles are the build profiles the profiles prompt can add.
+var initProfiles = []struct {
+	name, hint, toml string
+}{
+	{"release", "stripped, reproducible binaries", "ldflags = \"-s -w\"\nflags = [\"-trimpath\"]\n"},
+	{"debug", "optimizations off for delve", "gcflags = \"all=-N -l\"\n"},
+	{"race", "race detector", "flags = [\"-race\"]\n"},
+}
+
+var initVersionRe = regexp.MustCompile(`^\d+\.\d+\.\d+([-+][0-9A-Za-z.+-]+)?$`)
+
+// validateInitValue rejects values that cannot be written as a TOML string
+// as-is.
+func validateInitValue(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return errors.New("must not be empty")
+	}
+	if strings.ContainsAny(s, "\"\\\n") {
+		return errors.New("must not contain quotes or backslashes")
+	}
+	return nil
+}
+
+func validateInitVersion(s string) error {
+	if !initVersionRe.MatchString(s) {
+		return errors.New("expected a semantic version like 0.1.0")
+	}
+	return nil
+}
+
+// initPromptError turns Ctrl+C in a prompt into a clean exit message.
+func initPromptError(err error) error {
+	if errors.Is(err, errPromptAborted) {
+		return errors.New("init aborted; nothing written")
+	}
+	return err
+}
+
+// askInitLicense offers initLicenses (with def first when it is not one of
+// them) and "other" for any identifier.
+func askInitLicense(ask *prompter, def string) (string, error) {
+	names := slices.Clone(initLicenses)
+	if !slices.Contains(names, def) {
+		names = append([]string{def}, names...)
+	}
+	opts := make([]promptOption, 0, len(names)+1)
+	for _, n := range names {
+		opts = append(opts, promptOption{Value: n})
+	}
+	opts = append(opts, promptOption{Value: "other", Hint: "type an SPDX identifier"})
+	i, err := ask.Select("license", opts, slices.Index(names, def))
+	if err != nil {
+		return "", err
+	}
+	if i < len(names) {
+		return names[i], nil
+	}
+	return ask.Input("license identifier", def, validateInitValue)
+}
+
+// askInitTemplate asks for the template, dev watcher, and build profiles,
+// skipping what layout flags already decided. It may set initMinimal.
+func askInitTemplate(cmd *cobra.Command, ask *prompter, watcher string) (string, []string, error) {
+	flagged := false
+	for _, f := range []string{"dev", "minimal", "ci", "monorepo"} {
+		flagged = flagged || cmd.Flags().Changed(f)
+	}
+	if !flagged {
+		def := 0
+		if initMinimal {
+			def = 1
+		}
+		i, err := ask.Select("template", []promptOption{
+			{Value: "app", Hint: "build, test, and run tasks"},
+			{Value: "minimal", Hint: "project and Go pin only"},
+		}, def)
+		if err != nil {
+			return "", nil, err
+		}
+		initMinimal = i == 1
+	}
+	if initMinimal {
+		return "", nil, nil
+	}
+	if !cmd.Flags().Changed("dev") {
+		watchers := []promptOption{
+			{Value: "none", Hint: "no dev task"},
+			{Value: "reflex", Hint: "rig dev restarts on file events (installs reflex)"},
+			{Value: "poll", Hint: "rig dev polls for changes; for NFS, Docker mounts, WSL"},
+		}
+		def := 0
+		if watcher == "reflex" {
+			def = 1
+		}
+		i, err := ask.Select("dev watcher", watchers, def)
+		if err != nil {
+			return "", nil, err
+		}
+		watcher = ""
+		if i > 0 {
+			watcher = watchers[i].Value
+		}
+	}
+	opts := make([]promptOption, len(initProfiles))
+	for i, p := range initProfiles {
+		opts[i] = promptOption{Value: p.name, Hint: p.hint}
+	}
+	picked, err := ask.MultiSelect("build profiles", opts, make([]bool, len(opts)))
+	if err != nil {
+		return "", nil, err
+	}
+	var profiles []string
+	for i, ok := range picked {
+		if ok {
+			profiles = append(profiles, initProfiles[i].name)
+		}
+	}
+	return watcher, profiles, nil
 }
 
 func getGoVersion() string {
@@ -216,7 +526,9 @@ func buildMainConfig(name, version, license string) string {
 	return b.String()
 }
 
-func buildTasksConfig(includeDev bool, includeCI bool) string {
+// buildTasksConfig renders [tasks]; watcher is "" (no dev task), "reflex",
+// or "poll".
+func buildTasksConfig(watcher string, includeCI bool) string {
 	var builder strings.Builder
 	builder.WriteString("[tasks]\n")
 	builder.WriteString("build = \"go build ./...\"\n")
@@ -226,24 +538,68 @@ func buildTasksConfig(includeDev bool, includeCI bool) string {
 		builder.WriteString("\n[tasks.ci]\n")
 		builder.WriteString("command = \"rig check && rig run test\"\n")
 	}
-	if includeDev {
+	if watcher != "" {
 		builder.WriteString("\n[tasks.dev]\n")
 		builder.WriteString("command = \"go run .\"\n")
 		builder.WriteString("watch = [\"**/*.go\"]\n")
+		if watcher == "poll" {
+			builder.WriteString("watch_mode = \"poll\"\n")
+		}
 	}
 	return builder.String()
 }
 
-func buildToolsConfig(goVersion string, includeDev bool) string {
+func buildToolsConfig(goVersion string, watcher string) string {
 	var builder strings.Builder
 	builder.WriteString("[tools]\n")
 	fmt.Fprintf(&builder, "go = \"%s\"\n", goVersion)
-	if includeDev {
+	if watcher == "reflex" {
 		builder.WriteString("reflex = \"latest\"\n")
 	}
 	return builder.String()
 }
 
+// buildProfilesConfig renders the chosen initProfiles.
+func buildProfilesConfig(names []string) string {
+	var builder strings.Builder
+	for _, p := range initProfiles {
+		if slices.Contains(names, p.name) {
+			fmt.Fprintf(&builder, "\n[profile.%s]\n%s", p.name, p.toml)
+		}
+	}
+	return builder.String()
+}
+
+// detectGoWorkMembers returns the use directives of an existing go.work so that
+// rig.toml starts out mirroring the workspace.
+func detectGoWorkMembers(targetDirectory string) ([]string, error) {
+	b, err := os.ReadFile(filepath.Join(targetDirectory, "go.work"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read go.work: %w", err)
+	}
+	w, err := core.ParseGoWork(b)
+	if err != nil {
+		return nil, err
+	}
+	return w.Use, nil
+}
+
+func buildWorkspaceConfig(members []string) string {
+	var builder strings.Builder
+	builder.WriteString("[workspace]\nmembers = [")
+	for i, m := range members {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		fmt.Fprintf(&builder, "%q", m)
+	}
+	builder.WriteString("]\n")
+	return builder.String()
+}
+
 func injectInclude(mainToml string, files []string) string {
 	// Place include after [project] block
 	var b strings.Builder
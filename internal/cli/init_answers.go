@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	core "github.com/divijg19/rig/internal/rig"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// initAnswers answers every `rig init` prompt, read from `--answers` so
+// bootstrapping scripts get customized manifests without a terminal.
+// Unset keys keep the defaults --yes would use; flags win over answers.
+type initAnswers struct {
+	Name     string   `toml:"name" json:"name"`
+	Version  string   `toml:"version" json:"version"`
+	License  string   `toml:"license" json:"license"`
+	Template string   `toml:"template" json:"template"`       // app | minimal
+	Watcher  string   `toml:"dev_watcher" json:"dev_watcher"` // none | reflex | poll
+	Profiles []string `toml:"profiles" json:"profiles"`
+	CI       *bool    `toml:"ci" json:"ci"`
+	Monorepo *bool    `toml:"monorepo" json:"monorepo"`
+}
+
+// loadInitAnswers reads answers from path: JSON from stdin for "-" or a
+// .json file, TOML otherwise. Unknown keys are errors, so a typo does not
+// silently fall back to a default.
+func loadInitAnswers(path string, stdin io.Reader) (*initAnswers, error) {
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(stdin)
+		path = "stdin"
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("--answers: %w", err)
+	}
+	var a initAnswers
+	if path == "stdin" || strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&a)
+	} else {
+		dec := toml.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err = dec.Decode(&a); err != nil {
+			var strict *toml.StrictMissingError
+			if errors.As(err, &strict) {
+				return nil, fmt.Errorf("parse %s: unknown key(s):\n%s", path, strict.String())
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := a.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &a, nil
+}
+
+// validate applies the checks the prompts would.
+func (a *initAnswers) validate() error {
+	for _, f := range []struct {
+		key, val string
+		check    func(string) error
+	}{{"name", a.Name, validateInitValue}, {"version", a.Version, validateInitVersion}, {"license", a.License, validateInitValue}} {
+		if f.val == "" {
+			continue
+		}
+		if err := f.check(f.val); err != nil {
+			return fmt.Errorf("%s: %w", f.key, err)
+		}
+	}
+	switch a.Template {
+	case "", "app", "minimal":
+	default:
+		return fmt.Errorf("template: %q is not app or minimal", a.Template)
+	}
+	switch a.Watcher {
+	case "", "none", "reflex", "poll":
+	default:
+		return fmt.Errorf("dev_watcher: %q is not none, reflex, or poll", a.Watcher)
+	}
+	names := make([]string, len(initProfiles))
+	for i, p := range initProfiles {
+		names[i] = p.name
+	}
+	for _, p := range a.Profiles {
+		if !slices.Contains(names, p) {
+			return fmt.Errorf("profiles: unknown profile %q (expected %s)%s", p, strings.Join(names, ", "), core.DidYouMean(p, names))
+		}
+	}
+	if a.Template == "minimal" && (a.Watcher != "" && a.Watcher != "none" || len(a.Profiles) > 0 || a.CI != nil && *a.CI) {
+		return errors.New("template = \"minimal\" cannot be combined with dev_watcher, profiles, or ci")
+	}
+	return nil
+}
+
+// applyLayout sets the layout flags the user did not pass on the command
+// line from the answers.
+func (a *initAnswers) applyLayout(changed func(string) bool) {
+	if a.Template != "" && !changed("minimal") {
+		initMinimal = a.Template == "minimal"
+	}
+	if a.CI != nil && !changed("ci") {
+		initCI = *a.CI
+	}
+	if a.Monorepo != nil && !changed("monorepo") {
+		initMonorepo = *a.Monorepo
+	}
+}
+
+// watcher returns the dev watcher answer, or def when there is none.
+func (a *initAnswers) watcher(def string) string {
+	switch a.Watcher {
+	case "":
+		return def
+	case "none":
+		return ""
+	}
+	return a.Watcher
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -241,3 +242,204 @@ func TestInitAppendsGitignoreRigEntryWithoutDuplicates(t *testing.T) {
 		t.Fatalf("expected no duplicate .rig/ entry, got:\n%s", got)
 	}
 }
+
+func TestInitSeedsWorkspaceFromGoWork(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.work"), "go 1.22.3\n\nuse (\n\t./svc/api\n\t./svc/worker\n)\n", 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "init", "--yes")
+	if err != nil {
+		t.Fatalf("init failed: %v\n%s", err, out)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "rig.toml"))
+	if err != nil {
+		t.Fatalf("read rig.toml: %v", err)
+	}
+	content := string(b)
+	if !strings.Contains(content, "[workspace]\nmembers = [\"./svc/api\", \"./svc/worker\"]\n") {
+		t.Fatalf("expected [workspace] members from go.work, got:\n%s", content)
+	}
+}
+
+func TestInitUsesUserConfigDefaults(t *testing.T) {
+	dir := t.TempDir()
+	userCfg := filepath.Join(t.TempDir(), "config.toml")
+	writeFile(t, userCfg, "[init]\ntemplate = \"dev,ci\"\nlicense = \"Apache-2.0\"\n", 0o644)
+	env := append(os.Environ(), "RIG_USER_CONFIG="+userCfg)
+
+	out, err := runRigCmdInDirWithEnv(t, dir, env, "init", "--yes")
+	if err != nil {
+		t.Fatalf("init failed: %v\n%s", err, out)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "rig.toml"))
+	if err != nil {
+		t.Fatalf("read rig.toml: %v", err)
+	}
+	content := string(b)
+	for _, want := range []string{"[tasks.dev]", "[tasks.ci]", "license = \"Apache-2.0\""} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("expected %q from user config, got:\n%s", want, content)
+		}
+	}
+
+	// Explicit layout flags replace the user's template.
+	out, err = runRigCmdInDirWithEnv(t, dir, env, "init", "--yes", "--force", "--minimal")
+	if err != nil {
+		t.Fatalf("init --minimal failed: %v\n%s", err, out)
+	}
+	b, _ = os.ReadFile(filepath.Join(dir, "rig.toml"))
+	if strings.Contains(string(b), "[tasks.") {
+		t.Fatalf("--minimal should override init.template, got:\n%s", b)
+	}
+
+	out, err = runRigCmdInDirWithEnv(t, dir, env, "config", "--json")
+	if err != nil {
+		t.Fatalf("config failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, `"key": "init.template"`) || !strings.Contains(out, `"value": "dev,ci"`) || !strings.Contains(out, `"source": "user"`) {
+		t.Fatalf("unexpected rig config output:\n%s", out)
+	}
+}
+
+func TestInitFromExistingProject(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/acme/widget/v2\n\ngo 1.22\n", 0o644)
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n\nfunc main() {}\n", 0o644)
+	writeFile(t, filepath.Join(dir, "main_test.go"), "package main\n", 0o644)
+
+	out, err := runRigCmdInDir(t, dir, "init", "--from", "--yes")
+	if err != nil {
+		t.Fatalf("init --from failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"main packages: .", "+ build = \"go build -o bin/widget .\""} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in preview:\n%s", want, out)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "rig.toml"))
+	if err != nil {
+		t.Fatalf("read rig.toml: %v", err)
+	}
+	if content := string(b); !strings.Contains(content, `name = "widget"`) || !strings.Contains(content, `test = "go test ./..."`) {
+		t.Fatalf("unexpected rig.toml:\n%s", content)
+	}
+
+	out, err = runRigCmdInDir(t, dir, "init", "--from", "--dev", "--force")
+	if err == nil || !strings.Contains(out, "--from cannot be combined with --dev") {
+		t.Fatalf("expected --from/--dev conflict, got err=%v\n%s", err, out)
+	}
+}
+
+func TestInitPromptsFromPipedAnswers(t *testing.T) {
+	dir := t.TempDir()
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "init")
+	cmd.Dir = dir
+	// name, an invalid then a valid version, license #3, default template,
+	// poll watcher, profiles 1 and 3.
+	cmd.Stdin = strings.NewReader("demo\n1.2\n1.2.0\n3\n\n3\n1,3\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("init failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "expected a semantic version") || !strings.Contains(string(out), "2) Apache-2.0") {
+		t.Fatalf("expected validation and numbered choices, got:\n%s", out)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "rig.toml"))
+	if err != nil {
+		t.Fatalf("read rig.toml: %v", err)
+	}
+	content := string(b)
+	for _, want := range []string{`name = "demo"`, `version = "1.2.0"`, `license = "BSD-3-Clause"`, "[tasks.dev]", `watch_mode = "poll"`, "[profile.release]", "[profile.race]"} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("expected %q in rig.toml, got:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "reflex") || strings.Contains(content, "[profile.debug]") {
+		t.Fatalf("unexpected reflex or debug profile:\n%s", content)
+	}
+}
+
+func TestInitForceShowsDiffAndAsks(t *testing.T) {
+	dir := t.TempDir()
+	edited := "[project]\nname = \"demo\"\nversion = \"0.1.0\"\nlicense = \"MIT\"\n\n[tasks]\nbuild = \"make build\" # hand-tuned\n"
+	writeFile(t, filepath.Join(dir, "rig.toml"), edited, 0o644)
+
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "init", "--force", "--name", "demo")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader("") // every prompt keeps its default; overwrite defaults to no
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("init failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"--- rig.toml", "+++ rig.toml (new)", "-build = \"make build\" # hand-tuned", "+build = \"go build ./...\"", "overwrite 1 file(s)", "Aborted; nothing written."} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "rig.toml")); string(b) != edited {
+		t.Fatalf("declining must keep the file, got:\n%s", b)
+	}
+
+	out2, err := runRigCmdInDir(t, dir, "init", "--yes", "--force")
+	if err != nil || !strings.Contains(out2, "-build = \"make build\" # hand-tuned") {
+		t.Fatalf("--yes --force should print the diff and write: err=%v\n%s", err, out2)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "rig.toml")); strings.Contains(string(b), "hand-tuned") {
+		t.Fatalf("--yes --force should overwrite, got:\n%s", b)
+	}
+}
+
+func TestInitAnswersFile(t *testing.T) {
+	dir := t.TempDir()
+	answers := filepath.Join(t.TempDir(), "answers.toml")
+	writeFile(t, answers, `
+name = "billing"
+version = "2.0.0"
+license = "Apache-2.0"
+dev_watcher = "reflex"
+profiles = ["release"]
+ci = true
+`, 0o644)
+	out, err := runRigCmdInDir(t, dir, "init", "--answers", answers, "--license", "MPL-2.0")
+	if err != nil {
+		t.Fatalf("init failed: %v\n%s", err, out)
+	}
+	if strings.Contains(out, "Create rig.toml in") {
+		t.Fatalf("--answers must not prompt:\n%s", out)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "rig.toml"))
+	if err != nil {
+		t.Fatalf("read rig.toml: %v", err)
+	}
+	content := string(b)
+	for _, want := range []string{`name = "billing"`, `version = "2.0.0"`, `license = "MPL-2.0"`, "[tasks.ci]", "[tasks.dev]", `reflex = "latest"`, "[profile.release]"} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("expected %q in rig.toml, got:\n%s", want, content)
+		}
+	}
+
+	// JSON on stdin; a typo in a key is an error, not a silent default.
+	dir2 := t.TempDir()
+	bin := buildRigBinary(t, t.TempDir())
+	cmd := exec.Command(bin, "init", "--answers", "-")
+	cmd.Dir = dir2
+	cmd.Stdin = strings.NewReader(`{"name": "worker", "template": "minimal"}`)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("init --answers - failed: %v\n%s", err, out)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir2, "rig.toml")); !strings.Contains(string(b), `name = "worker"`) || strings.Contains(string(b), "[tasks]") {
+		t.Fatalf("expected a minimal manifest for worker, got:\n%s", b)
+	}
+	cmd = exec.Command(bin, "init", "--answers", "-", "--force")
+	cmd.Dir = dir2
+	cmd.Stdin = strings.NewReader(`{"nmae": "worker"}`)
+	if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), `unknown field "nmae"`) {
+		t.Fatalf("expected an unknown-key error, got %v\n%s", err, out)
+	}
+	writeFile(t, answers, "profiles = [\"relase\"]\n", 0o644)
+	if out, err := runRigCmdInDir(t, dir2, "init", "--answers", answers, "--force"); err == nil || !strings.Contains(out, `did you mean "release"`) {
+		t.Fatalf("expected a profile suggestion, got %v\n%s", err, out)
+	}
+}
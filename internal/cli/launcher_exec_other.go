@@ -0,0 +1,10 @@
+//go:build !windows
+
+package cli
+
+import "syscall"
+
+// execRigBinary replaces the current process with the rig binary at path.
+func execRigBinary(path string, args []string, env []string) error {
+	return syscall.Exec(path, append([]string{path}, args...), env)
+}
@@ -0,0 +1,29 @@
+//go:build windows
+
+package cli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// execRigBinary runs the rig binary at path and exits with its status, since
+// Windows has no exec(2).
+func execRigBinary(path string, args []string, env []string) error {
+	c := exec.Command(path, args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = env
+	err := c.Run()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		os.Exit(ee.ExitCode())
+	}
+	if err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
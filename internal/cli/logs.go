@@ -0,0 +1,145 @@
+// internal/cli/logs.go
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsFollow bool
+	logsSince  string
+	logsTail   int
+)
+
+// logsCmd replays and follows the logs rig keeps in .rig/logs.
+var logsCmd = &cobra.Command{
+	Use:   "logs <task>",
+	Short: "Show or follow the logs of rig dev and rig schedule",
+	Long: `Print the log rig keeps for a task in .rig/logs/<task>.log.
+
+'rig dev' logs every start of its command (dev#1, dev#2, ... across restarts,
+with :err for stderr), test-on-save failures, and its own status lines;
+'rig schedule' logs to schedule.log. Each process gets its own color.`,
+	Args: cobra.ExactArgs(1),
+	Example: `
+  rig logs dev
+  rig logs dev -f
+  rig logs dev --since 10m
+  rig logs schedule --since 2026-10-01T00:00:00Z --tail 0
+`,
+	ValidArgsFunction: completeLogNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, confPath, err := loadConfigOrFail()
+		if err != nil {
+			return err
+		}
+		since, err := parseLogsSince(logsSince, time.Now())
+		if err != nil {
+			return err
+		}
+		tail := logsTail
+		if since.IsZero() || cmd.Flags().Changed("tail") {
+			tail = max(tail, 0)
+		} else {
+			tail = 0
+		}
+		task := args[0]
+		lines, offset, err := core.ReadTaskLog(confPath, task, since, tail)
+		if errors.Is(err, os.ErrNotExist) {
+			msg := fmt.Sprintf("no log for %q in .rig/logs", task)
+			if names := core.TaskLogNames(confPath); len(names) > 0 {
+				msg += " (have: " + strings.Join(names, ", ") + ")"
+			} else {
+				msg += "; logs are written by 'rig dev' and 'rig schedule'"
+			}
+			return errors.New(msg)
+		}
+		if err != nil {
+			return err
+		}
+		stdout := newStyledWriter(os.Stdout)
+		for _, ll := range lines {
+			printLogLine(stdout, ll)
+		}
+		if !logsFollow {
+			return nil
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return core.FollowTaskLog(ctx, confPath, task, offset, 250*time.Millisecond, func(ll core.LogLine) {
+			printLogLine(stdout, ll)
+		})
+	},
+}
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "keep printing new lines as they are written (including after rig dev restarts)")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "only lines newer than a duration ago (10m, 2h) or an RFC3339 time")
+	logsCmd.Flags().IntVarP(&logsTail, "tail", "n", 100, "print only the last N lines (0 for all; default all with --since)")
+	rootCmd.AddCommand(logsCmd)
+}
+
+// parseLogsSince reads --since as a duration before now or an RFC3339 time.
+func parseLogsSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (want a duration like 10m or an RFC3339 time)", s)
+}
+
+// logProcessColors color log lines by process; a process keeps its color
+// across runs of `rig logs`.
+var logProcessColors = []string{"\x1b[36m", "\x1b[32m", "\x1b[35m", "\x1b[34m", "\x1b[33m", "\x1b[96m", "\x1b[92m", "\x1b[95m"}
+
+// printLogLine prints "2006-01-02 15:04:05 dev#2 | text" with the process colored and
+// stderr text in red.
+func printLogLine(w *styledWriter, ll core.LogLine) {
+	stamp := ll.At.Local().Format(time.DateTime)
+	proc, stream, _ := strings.Cut(ll.Process, ":")
+	text := ll.Text
+	if stream == "err" {
+		text = w.paint(ansiRed, text)
+	}
+	if ll.Process == "" {
+		fmt.Fprintf(w.w, "%s %s\n", stamp, text)
+		return
+	}
+	color := ansiBoldCyan
+	if proc != "rig" {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(proc))
+		color = logProcessColors[h.Sum32()%uint32(len(logProcessColors))]
+	}
+	fmt.Fprintf(w.w, "%s %s %s\n", stamp, w.paint(color, ll.Process+" |"), text)
+}
+
+// completeLogNames completes the task logs present in .rig/logs.
+func completeLogNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	_, confPath, err := loadConfigOptional()
+	if err != nil || confPath == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return core.TaskLogNames(confPath), cobra.ShellCompDirectiveNoFileComp
+}
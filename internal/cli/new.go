@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	newTemplatesDir string
+	newForce        bool
+	newDryRun       bool
+	newList         bool
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new <template> <name>",
+	Short: "Generate files from a project template",
+	Long: `Render a template directory into the project root.
+
+Templates live in .rig/templates/<template>/ (or templates/<template>/), or in
+--templates / $RIG_TEMPLATES_DIR (e.g. a checkout of a shared template repo).
+File paths and contents are Go templates with {{.Name}}, {{.Pascal}},
+{{.Camel}}, {{.Snake}}, {{.Kebab}}, {{.Lower}}, {{.Project}}, and {{.Module}}.
+A trailing .tmpl is stripped from output paths.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if newList {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	Example: `
+  rig new --list
+  rig new handler users
+  rig new handler users --dry-run
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if newList {
+			names, err := core.ListTemplates("", newTemplatesDir)
+			if err != nil {
+				return err
+			}
+			for _, n := range names {
+				fmt.Println(n)
+			}
+			return nil
+		}
+		res, err := core.Scaffold("", core.ScaffoldOptions{
+			Template:     args[0],
+			Name:         args[1],
+			TemplatesDir: newTemplatesDir,
+			Force:        newForce,
+			DryRun:       newDryRun,
+		})
+		if err != nil {
+			return err
+		}
+		verb := "created"
+		if newDryRun {
+			verb = "would create"
+		}
+		for _, f := range res.Written {
+			fmt.Printf("%s %s\n", verb, f)
+		}
+		for _, f := range res.Skipped {
+			fmt.Printf("skipped %s (exists; use --force to overwrite)\n", f)
+		}
+		return nil
+	},
+}
+
+func init() {
+	newCmd.Flags().StringVar(&newTemplatesDir, "templates", "", "templates directory (default: .rig/templates, then templates)")
+	newCmd.Flags().BoolVar(&newForce, "force", false, "overwrite existing files")
+	newCmd.Flags().BoolVar(&newDryRun, "dry-run", false, "print the files that would be created without writing")
+	newCmd.Flags().BoolVar(&newList, "list", false, "list available templates and exit")
+	rootCmd.AddCommand(newCmd)
+}
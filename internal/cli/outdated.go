@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	core "github.com/divijg19/rig/internal/rig"
+)
+
+// outdatedToolItems turns tool status rows into outdated items.
+func outdatedToolItems(rows []core.ToolStatusRow) []core.OutdatedItem {
+	items := make([]core.OutdatedItem, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, core.OutdatedItem{Kind: "tool", Name: r.Name, Have: r.Have, Want: r.Want, Status: r.Status, Severity: core.ToolSeverity(r.Status)})
+	}
+	return items
+}
+
+// outdatedGoItem checks the project's Go, tools.go when pinned and the go on
+// PATH otherwise, against the supported Go releases.
+func outdatedGoItem(tools map[string]string, configPath string, offline bool) (core.OutdatedItem, error) {
+	goVer := strings.TrimPrefix(strings.TrimSpace(tools["go"]), "go")
+	if !core.IsReleaseVersion(goVer) {
+		detected, err := core.DetectGoToolchainVersion(filepath.Dir(configPath), nil)
+		if err != nil {
+			return core.OutdatedItem{}, err
+		}
+		goVer = detected
+	}
+	stable, err := core.GoStableReleases(core.GoReleaseOptions{Interval: updateCheckOptions().Interval, Offline: offline})
+	if err != nil {
+		return core.OutdatedItem{}, err
+	}
+	return core.GoOutdatedItem(goVer, stable)
+}
+
+// outdatedRigItem reports whether a newer rig release exists, refreshing the
+// cached release check when it is due. Development builds have no item.
+func outdatedRigItem(offline bool) (core.OutdatedItem, bool, error) {
+	if !core.IsReleaseVersion(version) {
+		return core.OutdatedItem{}, false, nil
+	}
+	opts := updateCheckOptions()
+	if !offline && core.UpdateCheckDue(opts) {
+		if _, err := core.RefreshUpdateCheck(opts); err != nil {
+			return core.OutdatedItem{}, false, err
+		}
+	}
+	item := core.OutdatedItem{Kind: "rig", Name: "rig", Have: version, Status: "ok", Severity: core.SeverityNone}
+	if latest, ok := core.AvailableUpdate(opts); ok {
+		item.Want, item.Status, item.Severity = latest, "update-available", core.SeverityInfo
+	}
+	return item, true, nil
+}
+
+// outdatedReport collects the tool rows, Go, and rig into one report. The Go
+// and rig lookups need release data; when it cannot be had (offline, no
+// cache) the item is left out and the reason goes to stderr.
+func outdatedReport(tools map[string]string, configPath string, rows []core.ToolStatusRow, offline bool) core.OutdatedReport {
+	rep := core.OutdatedReport{Items: outdatedToolItems(rows)}
+	stderr := newStyledWriter(os.Stderr)
+	if item, err := outdatedGoItem(tools, configPath, offline); err != nil {
+		stderr.linef(ansiYellow, "⚠️  could not check for Go releases: %v", err)
+	} else {
+		rep.Items = append(rep.Items, item)
+	}
+	if item, ok, err := outdatedRigItem(offline); err != nil {
+		stderr.linef(ansiYellow, "⚠️  could not check for rig releases: %v", err)
+	} else if ok {
+		rep.Items = append(rep.Items, item)
+	}
+	return rep
+}
+
+// printOutdatedReleases prints the Go and rig items that need attention.
+func printOutdatedReleases(out *styledWriter, items []core.OutdatedItem) {
+	for _, it := range items {
+		switch {
+		case it.Kind == "go" && it.Status == "patch-available":
+			out.linef(ansiYellow, "⚠️  go %s: go%s is available (patch releases carry security fixes)", it.Have, it.Want)
+		case it.Kind == "go" && it.Status == "unsupported":
+			out.linef(ansiYellow, "⚠️  go %s: this minor release no longer gets security fixes (latest is go%s)", it.Have, it.Want)
+		case it.Kind == "rig" && it.Status == "update-available":
+			out.linef(ansiBoldCyan, "💡 rig %s available (have %s), run rig upgrade", it.Want, it.Have)
+		}
+	}
+}
+
+// outdatedToolIssues counts the tool items that fail `rig outdated`.
+func outdatedToolIssues(items []core.OutdatedItem) int {
+	n := 0
+	for _, it := range items {
+		if it.Kind == "tool" && it.Severity == core.SeverityError {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,74 @@
+// internal/cli/overwrite.go
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	core "github.com/divijg19/rig/internal/rig"
+)
+
+// plannedWrite is a file a command is about to write.
+type plannedWrite struct {
+	Path    string
+	Content string
+}
+
+// confirmOverwrite shows a colored unified diff for each planned write that
+// would change an existing file, then asks before overwriting. It reports
+// true when there is nothing to lose or the user agreed; with ask nil
+// (--yes) the diff is printed and the write goes ahead.
+func confirmOverwrite(ask *prompter, writes []plannedWrite) (bool, error) {
+	stdout := newStyledWriter(os.Stdout)
+	changed := 0
+	for _, pw := range writes {
+		old, err := os.ReadFile(pw.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		name := getRelativePath(pw.Path)
+		diff := core.UnifiedDiff(name, name+" (new)", string(old), pw.Content, 3)
+		if len(diff) == 0 {
+			continue
+		}
+		if changed == 0 {
+			stdout.linef(ansiYellow, "⚠️  overwriting changes existing files:")
+		}
+		changed++
+		printUnifiedDiff(stdout, diff)
+	}
+	if changed == 0 || ask == nil {
+		return true, nil
+	}
+	fmt.Println()
+	ok, err := ask.Confirm(fmt.Sprintf("overwrite %d file(s)", changed), false)
+	if errors.Is(err, errPromptAborted) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// printUnifiedDiff prints core.UnifiedDiff output with removals red,
+// additions green, and hunk headers cyan.
+func printUnifiedDiff(w *styledWriter, lines []string) {
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "---"), strings.HasPrefix(l, "+++"):
+			fmt.Fprintln(w.w, l)
+		case strings.HasPrefix(l, "@@"):
+			w.linef(ansiBoldCyan, "%s", l)
+		case strings.HasPrefix(l, "-"):
+			w.linef(ansiRed, "%s", l)
+		case strings.HasPrefix(l, "+"):
+			w.linef(ansiGreen, "%s", l)
+		default:
+			fmt.Fprintln(w.w, l)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// internal/cli/plan.go
+
+package cli
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	planJSON    bool
+	planProfile string
+)
+
+// planCmd prints what `rig run <task>` would do without running anything.
+var planCmd = &cobra.Command{
+	Use:   "plan <task>",
+	Short: "Show the execution plan of a task without running it",
+	Long:  "Print the tasks 'rig run <task>' would run, in order, with each command's cwd and env, whether its outputs are up to date (cache hit), and a duration estimate from recent runs recorded in .rig/history.json.",
+	Args:  cobra.ExactArgs(1),
+	Example: `
+  rig plan ci
+  rig plan build --profile release
+  rig plan ci --json | jq '.steps[] | select(.cache != "hit") | .task'
+`,
+	ValidArgsFunction: completeTaskNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plan, err := core.PlanTask("", args[0], planProfile)
+		if err != nil {
+			return err
+		}
+		if planJSON {
+			b, err := stdjson.MarshalIndent(plan, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+		printPlan(plan)
+		return nil
+	},
+}
+
+func printPlan(plan core.Plan) {
+	stdout := newStyledWriter(os.Stdout)
+	total := "no history yet"
+	if plan.EstimateMS > 0 {
+		total = "~" + planDuration(plan.EstimateMS) + " from recent runs"
+		if len(plan.Unestimated) > 0 {
+			total += fmt.Sprintf(", %d step(s) unknown", len(plan.Unestimated))
+		}
+	}
+	stdout.linef(ansiBoldCyan, "Plan for %s (%d step(s), %s)", plan.Task, len(plan.Steps), total)
+	width := 0
+	for _, st := range plan.Steps {
+		width = max(width, len(st.Task))
+	}
+	for i, st := range plan.Steps {
+		fmt.Println()
+		if st.Kind == core.PlanSteps {
+			fmt.Printf("%2d. %-*s  steps: %s (%s)\n", i+1, width, st.Task, strings.Join(st.Steps, ", "), st.Mode)
+			continue
+		}
+		est := "?"
+		if st.Samples > 0 {
+			est = "~" + planDuration(st.EstimateMS)
+		}
+		line := fmt.Sprintf("%2d. %-*s  %-8s", i+1, width, st.Task, est)
+		switch st.Cache {
+		case "hit":
+			stdout.linef(ansiGreen, "%s  cache hit (%s)", line, st.CacheReason)
+		case "miss":
+			stdout.linef(ansiYellow, "%s  cache miss (%s)", line, st.CacheReason)
+		default:
+			fmt.Println(strings.TrimRight(line, " "))
+		}
+		fmt.Printf("    $ %s\n", st.Command)
+		if len(st.Dirs) > 0 {
+			fmt.Printf("    dirs: %s\n", strings.Join(st.Dirs, ", "))
+		} else {
+			fmt.Printf("    cwd: %s\n", st.Cwd)
+		}
+		if len(st.Env) > 0 {
+			keys := make([]string, 0, len(st.Env))
+			for k := range st.Env {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			pairs := make([]string, len(keys))
+			for j, k := range keys {
+				pairs[j] = k + "=" + st.Env[k]
+			}
+			fmt.Printf("    env: %s\n", strings.Join(pairs, " "))
+		}
+		if st.AllowFailure {
+			fmt.Println("    allow_failure: true")
+		}
+	}
+}
+
+// planDuration rounds an estimate for display.
+func planDuration(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	switch {
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(100 * time.Millisecond).String()
+	default:
+		return d.Round(time.Second).String()
+	}
+}
+
+func init() {
+	planCmd.Flags().BoolVar(&planJSON, "json", false, "print the plan as JSON")
+	planCmd.Flags().StringVar(&planProfile, "profile", "", "apply env from rig.toml [profile.<name>] as 'rig run --profile' would")
+	_ = planCmd.RegisterFlagCompletionFunc("profile", completeProfileNames)
+	rootCmd.AddCommand(planCmd)
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// porcelainV1 is the only porcelain format so far. Porcelain output is for
+// scripts: one tab-separated record per line, its first field naming the
+// record. A version's records and columns never change; new columns or
+// records mean a new version.
+const porcelainV1 = "v1"
+
+// addPorcelainFlag registers --porcelain[=v1] on cmd.
+func addPorcelainFlag(cmd *cobra.Command, target *string) {
+	cmd.Flags().StringVar(target, "porcelain", "", "print stable tab-separated output for scripts (format version: v1)")
+	cmd.Flags().Lookup("porcelain").NoOptDefVal = porcelainV1
+	_ = cmd.RegisterFlagCompletionFunc("porcelain", cobra.FixedCompletions([]string{porcelainV1}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// porcelainEnabled validates --porcelain and reports whether it was given;
+// conflicting names other output flags that are set.
+func porcelainEnabled(cmd *cobra.Command, version string, conflicting ...string) (bool, error) {
+	if !cmd.Flags().Changed("porcelain") {
+		return false, nil
+	}
+	if version != porcelainV1 {
+		return false, fmt.Errorf("unsupported --porcelain version %q (supported: %s)", version, porcelainV1)
+	}
+	for _, name := range conflicting {
+		if cmd.Flags().Changed(name) {
+			return false, fmt.Errorf("--porcelain cannot be combined with --%s", name)
+		}
+	}
+	return true, nil
+}
+
+var porcelainEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// porcelainLine writes one record. Backslashes, tabs, and newlines inside a
+// field are escaped as \\, \t, and \n so every record stays on one line.
+func porcelainLine(w io.Writer, record string, fields ...string) {
+	var b strings.Builder
+	b.WriteString(record)
+	for _, f := range fields {
+		b.WriteByte('\t')
+		b.WriteString(porcelainEscaper.Replace(f))
+	}
+	b.WriteByte('\n')
+	_, _ = io.WriteString(w, b.String())
+}
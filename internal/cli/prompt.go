@@ -0,0 +1,370 @@
+// internal/cli/prompt.go
+
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"golang.org/x/term"
+)
+
+// errPromptAborted is returned when the user presses Ctrl+C (or Ctrl+D) in
+// a prompt.
+var errPromptAborted = errors.New("aborted")
+
+// promptOption is one choice of a select prompt.
+type promptOption struct {
+	Value string
+	// Hint is shown dimmed after the value, e.g. what a template contains.
+	Hint string
+}
+
+// prompter asks interactive questions. On a terminal, selects are arrow-key
+// lists (↑/↓ or j/k to move, space to toggle, enter to accept); otherwise
+// (piped stdin, or accessible mode) it falls back to numbered choices read
+// line by line, so scripted answers and screen readers keep working.
+type prompter struct {
+	in    *os.File
+	out   *styledWriter
+	lines *bufio.Reader
+	raw   bool
+}
+
+func newPrompter(in, out *os.File) *prompter {
+	return &prompter{
+		in:    in,
+		out:   newStyledWriter(out),
+		lines: bufio.NewReader(in),
+		raw:   isTTY(in) && isTTY(out) && !core.Accessible,
+	}
+}
+
+// readLine reads one answer; at EOF it returns what was typed so far, so an
+// exhausted script accepts the remaining defaults.
+func (p *prompter) readLine() (string, error) {
+	line, err := p.lines.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if err != nil && line == "" {
+		return "", io.EOF
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Input asks for free text, showing def and returning it for an empty
+// answer. validate, when set, rejects an answer and asks again.
+func (p *prompter) Input(label, def string, validate func(string) error) (string, error) {
+	for {
+		fmt.Fprintf(p.out.w, "%s %s: %s ", p.out.paint(ansiGreen, "?"), label, p.out.paint(ansiDim, "("+def+")"))
+		answer, err := p.readLine()
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(p.out.w)
+			answer, err = "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = def
+		}
+		if validate == nil {
+			return answer, nil
+		}
+		verr := validate(answer)
+		if verr == nil {
+			return answer, nil
+		}
+		if answer == def {
+			return "", verr
+		}
+		fmt.Fprintf(p.out.w, "  %s\n", p.out.paint(ansiRed, core.StatusText("✗ "+verr.Error())))
+	}
+}
+
+// Select asks for one of options and returns its index; def is preselected.
+func (p *prompter) Select(label string, options []promptOption, def int) (int, error) {
+	m := &selectModel{options: options, cursor: def, picked: make([]bool, len(options))}
+	if err := p.ask(label, m); err != nil {
+		return 0, err
+	}
+	return m.cursor, nil
+}
+
+// MultiSelect asks for any number of options; picked holds the defaults and
+// the result.
+func (p *prompter) MultiSelect(label string, options []promptOption, picked []bool) ([]bool, error) {
+	m := &selectModel{options: options, multi: true, picked: slices.Clone(picked)}
+	if err := p.ask(label, m); err != nil {
+		return nil, err
+	}
+	return m.picked, nil
+}
+
+func (p *prompter) ask(label string, m *selectModel) error {
+	if p.raw {
+		if restore, err := makePromptRaw(p.in); err == nil {
+			defer restore()
+			return p.askRaw(label, m)
+		}
+	}
+	return p.askLines(label, m)
+}
+
+// askRaw draws m below the question and redraws it after every key.
+func (p *prompter) askRaw(label string, m *selectModel) error {
+	w := p.out.w
+	fmt.Fprint(w, "\x1b[?25l")
+	defer fmt.Fprint(w, "\x1b[?25h")
+	drawn := 0
+	draw := func(lines []string) {
+		if drawn > 0 {
+			fmt.Fprintf(w, "\x1b[%dA\x1b[J", drawn)
+		}
+		for _, l := range lines {
+			fmt.Fprint(w, l+"\r\n")
+		}
+		drawn = len(lines)
+	}
+	draw(m.render(p.out, label))
+	buf := make([]byte, 16)
+	for {
+		n, err := p.in.Read(buf)
+		if err != nil {
+			draw(nil)
+			return errPromptAborted
+		}
+		for _, k := range decodePromptKeys(buf[:n]) {
+			done, err := m.apply(k)
+			if err != nil {
+				draw(nil)
+				return err
+			}
+			if done {
+				draw([]string{fmt.Sprintf("%s %s: %s", p.out.paint(ansiGreen, "?"), label, p.out.paint(ansiBoldCyan, m.answer()))})
+				return nil
+			}
+		}
+		draw(m.render(p.out, label))
+	}
+}
+
+// askLines lists numbered options and reads the choice as a line: a number
+// or value for a select, a comma- or space-separated list (or "none") for a
+// multi-select. An empty answer keeps the defaults.
+func (p *prompter) askLines(label string, m *selectModel) error {
+	w := p.out.w
+	fmt.Fprintf(w, "%s %s:\n", p.out.paint(ansiGreen, "?"), label)
+	var defs []string
+	for i, o := range m.options {
+		line := fmt.Sprintf("  %d) %s", i+1, o.Value)
+		if o.Hint != "" {
+			line += " " + p.out.paint(ansiDim, "— "+o.Hint)
+		}
+		fmt.Fprintln(w, line)
+		if (m.multi && m.picked[i]) || (!m.multi && i == m.cursor) {
+			defs = append(defs, strconv.Itoa(i+1))
+		}
+	}
+	for {
+		if m.multi {
+			fmt.Fprintf(w, "  choose any of 1-%d, or none %s ", len(m.options), p.out.paint(ansiDim, "("+firstNonEmpty(strings.Join(defs, ","), "none")+")"))
+		} else {
+			fmt.Fprintf(w, "  choose 1-%d %s ", len(m.options), p.out.paint(ansiDim, "("+strings.Join(defs, ",")+")"))
+		}
+		answer, err := p.readLine()
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(w)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if answer == "" {
+			return nil
+		}
+		if err := m.choose(answer); err != nil {
+			fmt.Fprintf(w, "  %s\n", p.out.paint(ansiRed, core.StatusText("✗ "+err.Error())))
+			continue
+		}
+		return nil
+	}
+}
+
+// makePromptRaw puts the terminal in raw mode so keys arrive one by one and
+// Ctrl+C reaches the prompt instead of killing rig.
+func makePromptRaw(f *os.File) (func(), error) {
+	fd := int(f.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() { _ = term.Restore(fd, state) }, nil
+}
+
+// promptKey is a decoded keypress.
+type promptKey int
+
+const (
+	keyOther promptKey = iota
+	keyUp
+	keyDown
+	keyToggle
+	keyEnter
+	keyAbort
+)
+
+// decodePromptKeys maps raw terminal input to keys: arrow keys (CSI and SS3
+// forms), j/k, space, enter, and Ctrl+C/Ctrl+D.
+func decodePromptKeys(b []byte) []promptKey {
+	var keys []promptKey
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; {
+		case c == 0x1b && i+2 < len(b) && (b[i+1] == '[' || b[i+1] == 'O'):
+			switch b[i+2] {
+			case 'A':
+				keys = append(keys, keyUp)
+			case 'B':
+				keys = append(keys, keyDown)
+			default:
+				keys = append(keys, keyOther)
+			}
+			i += 2
+		case c == 'k':
+			keys = append(keys, keyUp)
+		case c == 'j':
+			keys = append(keys, keyDown)
+		case c == ' ':
+			keys = append(keys, keyToggle)
+		case c == '\r' || c == '\n':
+			keys = append(keys, keyEnter)
+		case c == 0x03 || c == 0x04:
+			keys = append(keys, keyAbort)
+		default:
+			keys = append(keys, keyOther)
+		}
+	}
+	return keys
+}
+
+// selectModel is the state of a select or multi-select prompt.
+type selectModel struct {
+	options []promptOption
+	cursor  int
+	multi   bool
+	picked  []bool
+}
+
+// apply handles one key and reports whether the prompt is answered.
+func (m *selectModel) apply(k promptKey) (bool, error) {
+	switch k {
+	case keyUp:
+		m.cursor = (m.cursor + len(m.options) - 1) % len(m.options)
+	case keyDown:
+		m.cursor = (m.cursor + 1) % len(m.options)
+	case keyToggle:
+		if m.multi {
+			m.picked[m.cursor] = !m.picked[m.cursor]
+		}
+	case keyEnter:
+		return true, nil
+	case keyAbort:
+		return false, errPromptAborted
+	}
+	return false, nil
+}
+
+// choose applies a typed answer (see askLines).
+func (m *selectModel) choose(answer string) error {
+	pick := func(s string) (int, error) {
+		for i, o := range m.options {
+			if strings.EqualFold(s, o.Value) {
+				return i, nil
+			}
+		}
+		if n, err := strconv.Atoi(s); err == nil && n >= 1 && n <= len(m.options) {
+			return n - 1, nil
+		}
+		return 0, fmt.Errorf("%q is not one of the choices", s)
+	}
+	if !m.multi {
+		i, err := pick(answer)
+		if err != nil {
+			return err
+		}
+		m.cursor = i
+		return nil
+	}
+	picked := make([]bool, len(m.options))
+	if !strings.EqualFold(answer, "none") {
+		for _, f := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+			i, err := pick(f)
+			if err != nil {
+				return err
+			}
+			picked[i] = true
+		}
+	}
+	m.picked = picked
+	return nil
+}
+
+// answer summarizes the choice for the collapsed prompt line.
+func (m *selectModel) answer() string {
+	if !m.multi {
+		return m.options[m.cursor].Value
+	}
+	var vals []string
+	for i, o := range m.options {
+		if m.picked[i] {
+			vals = append(vals, o.Value)
+		}
+	}
+	return firstNonEmpty(strings.Join(vals, ", "), "none")
+}
+
+// render draws the question and options with the cursor on the current one.
+func (m *selectModel) render(sw *styledWriter, label string) []string {
+	help := "↑/↓ to move, enter to select"
+	if m.multi {
+		help = "↑/↓ to move, space to toggle, enter to accept"
+	}
+	lines := []string{fmt.Sprintf("%s %s: %s", sw.paint(ansiGreen, "?"), label, sw.paint(ansiDim, help))}
+	for i, o := range m.options {
+		mark := ""
+		if m.multi {
+			mark = "◯ "
+			if m.picked[i] {
+				mark = "◉ "
+			}
+		}
+		hint := ""
+		if o.Hint != "" {
+			hint = " " + sw.paint(ansiDim, "— "+o.Hint)
+		}
+		if i == m.cursor {
+			lines = append(lines, sw.paint(ansiBoldCyan, "❯ "+mark+o.Value)+hint)
+		} else {
+			lines = append(lines, "  "+mark+o.Value+hint)
+		}
+	}
+	return lines
+}
+
+// Confirm asks a yes/no question.
+func (p *prompter) Confirm(label string, def bool) (bool, error) {
+	i := 1
+	if def {
+		i = 0
+	}
+	i, err := p.Select(label, []promptOption{{Value: "yes"}, {Value: "no"}}, i)
+	return err == nil && i == 0, err
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSelectModelKeys(t *testing.T) {
+	keys := decodePromptKeys([]byte("\x1b[B\x1bOBk \r\x03x"))
+	want := []promptKey{keyDown, keyDown, keyUp, keyToggle, keyEnter, keyAbort, keyOther}
+	if !slices.Equal(keys, want) {
+		t.Fatalf("keys=%v, want %v", keys, want)
+	}
+
+	opts := []promptOption{{Value: "none"}, {Value: "reflex"}, {Value: "poll"}}
+	m := &selectModel{options: opts, picked: make([]bool, 3)}
+	for _, k := range decodePromptKeys([]byte("\x1b[A\x1b[A")) {
+		_, _ = m.apply(k)
+	}
+	if done, _ := m.apply(keyEnter); !done || m.answer() != "reflex" {
+		t.Fatalf("up twice from the first option should wrap to reflex, got %q", m.answer())
+	}
+
+	m = &selectModel{options: opts, multi: true, picked: make([]bool, 3)}
+	for _, k := range decodePromptKeys([]byte(" jj ")) {
+		_, _ = m.apply(k)
+	}
+	if m.answer() != "none, poll" {
+		t.Fatalf("multi answer=%q", m.answer())
+	}
+	if _, err := m.apply(keyAbort); !errors.Is(err, errPromptAborted) {
+		t.Fatalf("Ctrl+C should abort, got %v", err)
+	}
+
+	if err := m.choose("reflex, 3"); err != nil || !slices.Equal(m.picked, []bool{false, true, true}) {
+		t.Fatalf("choose: picked=%v err=%v", m.picked, err)
+	}
+	if err := m.choose("9"); err == nil {
+		t.Fatalf("expected an out-of-range choice to be rejected")
+	}
+}
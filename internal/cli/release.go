@@ -0,0 +1,138 @@
+// internal/cli/release.go
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	releaseDist        string
+	releaseOut         string
+	releaseName        string
+	releaseVersion     string
+	releaseURL         string
+	releaseHomepage    string
+	releaseDescription string
+	releaseLicense     string
+	releaseFormats     []string
+)
+
+// releaseFormatNames are the manifests `rig release manifests` can write.
+var releaseFormatNames = []string{"brew", "scoop", "aur"}
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Prepare release artifacts for publishing",
+}
+
+var releaseManifestsCmd = &cobra.Command{
+	Use:   "manifests",
+	Short: "Generate Homebrew, Scoop, and AUR manifests from release archives",
+	Long: `Write package manager manifests for the release archives in --dist: a
+Homebrew formula (<name>.rb), a Scoop manifest (<name>.json), and an AUR
+PKGBUILD for <name>-bin.
+
+Archives are found by the name rig upgrade expects,
+<name>_<os>_<arch>.tar.gz or .zip, and checked against their .sha256
+files. Name, version, and license default to [project] in rig.toml.`,
+	Args: cobra.NoArgs,
+	Example: `
+  rig release manifests --url https://github.com/me/app/releases/download/v{version}
+  rig release manifests --dist build --format brew,scoop -o packaging --version 1.2.0
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		meta := core.ReleaseMeta{
+			Name:        strings.TrimSpace(releaseName),
+			Version:     strings.TrimSpace(releaseVersion),
+			Description: strings.TrimSpace(releaseDescription),
+			Homepage:    strings.TrimSpace(releaseHomepage),
+			License:     strings.TrimSpace(releaseLicense),
+			URL:         strings.TrimSpace(releaseURL),
+		}
+		if conf, _, err := cfg.Load(""); err == nil {
+			meta.Name = firstNonEmpty(meta.Name, conf.Project.Name)
+			meta.Version = firstNonEmpty(meta.Version, conf.Project.Version)
+			meta.License = firstNonEmpty(meta.License, conf.Project.License)
+		} else if !errors.Is(err, cfg.ErrConfigNotFound) {
+			return core.WithCode(core.CodeConfigInvalid, err)
+		}
+		meta.Version = strings.TrimPrefix(meta.Version, "v")
+		switch {
+		case meta.Name == "":
+			return errors.New("no project name: set [project].name or pass --name")
+		case meta.Version == "":
+			return errors.New("no version: set [project].version or pass --version")
+		case meta.URL == "":
+			return errors.New("--url is required (where the archives are downloaded from)")
+		}
+		for _, f := range releaseFormats {
+			if !slices.Contains(releaseFormatNames, f) {
+				return fmt.Errorf("--format %q: expected %s%s", f, strings.Join(releaseFormatNames, ", "), core.DidYouMean(f, releaseFormatNames))
+			}
+		}
+
+		artifacts, err := core.ScanReleaseArtifacts(releaseDist, meta.Name)
+		if err != nil {
+			return err
+		}
+		meta.Artifacts = artifacts
+		cmd.SilenceUsage = true
+
+		out := firstNonEmpty(strings.TrimSpace(releaseOut), releaseDist)
+		if err := os.MkdirAll(out, 0o755); err != nil {
+			return err
+		}
+		stdout := newStyledWriter(os.Stdout)
+		for _, f := range releaseFormats {
+			var file, content string
+			switch f {
+			case "brew":
+				file = meta.Name + ".rb"
+				content, err = core.HomebrewFormula(meta)
+			case "scoop":
+				file = meta.Name + ".json"
+				var b []byte
+				b, err = core.ScoopManifest(meta)
+				content = string(b)
+			case "aur":
+				file = "PKGBUILD"
+				content, err = core.AURPKGBUILD(meta)
+			}
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(out, file)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				return err
+			}
+			stdout.linef(ansiGreen, "✅ wrote %s", path)
+		}
+		return nil
+	},
+}
+
+func init() {
+	f := releaseManifestsCmd.Flags()
+	f.StringVar(&releaseDist, "dist", "dist", "directory holding the release archives and .sha256 files")
+	f.StringVarP(&releaseOut, "output", "o", "", "directory to write the manifests to (default: --dist)")
+	f.StringVar(&releaseName, "name", "", "binary and package name (default: [project].name)")
+	f.StringVar(&releaseVersion, "version", "", "release version (default: [project].version)")
+	f.StringVar(&releaseURL, "url", "", "download URL of the archives; {version} is replaced and the archive name appended")
+	f.StringVar(&releaseHomepage, "homepage", "", "project homepage")
+	f.StringVar(&releaseDescription, "description", "", "one-line package description")
+	f.StringVar(&releaseLicense, "license", "", "SPDX license (default: [project].license)")
+	f.StringSliceVar(&releaseFormats, "format", releaseFormatNames, "manifests to write: brew, scoop, aur")
+	_ = releaseManifestsCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(releaseFormatNames, cobra.ShellCompDirectiveNoFileComp))
+	releaseCmd.AddCommand(releaseManifestsCmd)
+	rootCmd.AddCommand(releaseCmd)
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+// versionCheckExempt lists commands that must keep working when the project
+// pins an incompatible rig (so users can inspect, upgrade, or re-init).
+var versionCheckExempt = map[string]struct{}{
+	"alias":      {},
+	"completion": {},
+	"explain":    {},
+	"help":       {},
+	"init":       {},
+	"upgrade":    {},
+	"version":    {},
+}
+
+// enforceRigVersion fails the command when rig.toml pins a rig version that the
+// running binary does not satisfy. Config load errors are ignored here; the
+// command itself reports them.
+func enforceRigVersion(cmd *cobra.Command) error {
+	if strings.TrimSpace(os.Getenv("RIG_SKIP_VERSION_CHECK")) != "" {
+		return nil
+	}
+	for c := cmd; c != nil && c != rootCmd; c = c.Parent() {
+		if _, ok := versionCheckExempt[c.Name()]; ok {
+			return nil
+		}
+	}
+	if cmd == rootCmd {
+		return nil
+	}
+	conf, path, err := core.LoadConfig("")
+	if err != nil {
+		return nil
+	}
+	err = core.CheckRigVersionRequirement(conf, path, version)
+	var mm *core.RigVersionMismatchError
+	if errors.As(err, &mm) && autoSwitchEnabled() {
+		return launchPinnedRig(mm)
+	}
+	return err
+}
+
+// autoSwitchEnabled reports whether RIG_AUTO_SWITCH opts into launcher mode.
+// RIG_LAUNCHED is set on the child so a launched rig never switches again.
+func autoSwitchEnabled() bool {
+	if strings.TrimSpace(os.Getenv("RIG_LAUNCHED")) != "" {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("RIG_AUTO_SWITCH"))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// launchPinnedRig fetches (or reuses from the cache) a rig release satisfying
+// the project's requirement and execs it with the current arguments.
+func launchPinnedRig(mm *core.RigVersionMismatchError) error {
+	target, err := core.EnsureRigVersion(core.LaunchOptions{Constraint: mm.Required})
+	if err != nil {
+		return fmt.Errorf("%w (auto-switch failed: %v)", mm, err)
+	}
+	if target.Downloaded {
+		statusf(os.Stderr, "⬇️  rig %s cached at %s", target.Version, target.Path)
+	}
+	env := append(os.Environ(), "RIG_LAUNCHED="+target.Version)
+	return execRigBinary(target.Path, os.Args[1:], env)
+}
+
+func init() {
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		loadUserConfig(cmd)
+		warnUnsafeContext(cmd)
+		warnIncludeOverrides()
+		startUpdateCheck(cmd)
+		return enforceRigVersion(cmd)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"strings"
 
 	cfg "github.com/divijg19/rig/internal/config"
+	core "github.com/divijg19/rig/internal/rig"
 	"github.com/spf13/cobra"
 )
 
@@ -53,11 +54,66 @@ var versionCmd = &cobra.Command{
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		stderr := newStyledWriter(os.Stderr)
+		var ee *exitCodeError
+		if errors.As(err, &ee) {
+			if ee.err != nil {
+				printError(stderr, ee.err)
+			}
+			os.Exit(ee.code)
+		}
+		printError(stderr, err)
 		os.Exit(1)
 	}
 }
 
+// printError prints err, tagged with its error code and a pointer to
+// `rig explain` when it has one.
+func printError(out *styledWriter, err error) {
+	code := core.CodeOf(err)
+	if code == "" {
+		out.linef(ansiRed, "Error: %s", err)
+		return
+	}
+	out.linef(ansiRed, "Error [%s]: %s", code, err)
+	fmt.Fprintf(out.w, "Run 'rig explain %s' for causes and fixes.\n", code)
+}
+
+// Exit codes for scriptable commands (e.g. `rig check --quiet`).
+const (
+	exitOutOfSync = 1
+	exitFailure   = 2
+)
+
+// exitCodeError ends the process with code. A nil err exits without printing.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// quietExit silences cobra's error and usage output for cmd and maps the
+// outcome to an exit code: nil err and ok -> 0, !ok -> 1, err -> 2.
+func quietExit(cmd *cobra.Command, ok bool, err error) error {
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	switch {
+	case err != nil:
+		return &exitCodeError{code: exitFailure}
+	case !ok:
+		return &exitCodeError{code: exitOutOfSync}
+	}
+	return nil
+}
+
 // ExecuteWithArgs runs the CLI with an explicit argv (excluding argv[0]).
 // This is used by wrapper binaries that forward to a specific subcommand.
 func ExecuteWithArgs(args []string) {
@@ -67,6 +123,8 @@ func ExecuteWithArgs(args []string) {
 
 func init() {
 	rootCmd.Flags().BoolVarP(&rootShowVersion, "version", "v", false, "print version information")
+	rootCmd.PersistentFlags().BoolVar(&cfg.AllowIncludeOverride, "allow-override", false, "let a later included file redefine a task, tool, or profile (warn instead of failing)")
+	rootCmd.PersistentFlags().BoolVar(&core.Accessible, "accessible", false, "screen-reader friendly output: status words (OK, FAIL, WARN, SKIP) instead of symbols, no color, numbered prompts (default: user config accessible)")
 	defaultHelp := rootCmd.HelpFunc()
 
 	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
@@ -98,7 +156,7 @@ func init() {
 		fmt.Fprintln(out, "  rig [command]")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Available Commands:")
-		allowed := []string{"alias", "build", "check", "completion", "dev", "doctor", "help", "init", "run", "start", "status", "sync", "tools", "upgrade", "version", "x"}
+		allowed := []string{"alias", "build", "bundle", "cache", "check", "completion", "config", "dev", "doctor", "env", "explain", "export", "fmt", "help", "init", "lock", "logs", "new", "plan", "release", "run", "schedule", "start", "status", "sync", "test", "tools", "upgrade", "version", "workspace", "x"}
 		for _, name := range allowed {
 			c, _, err := cmd.Find([]string{name})
 			if err != nil || c == nil || c.Name() != name || c.Hidden {
@@ -108,8 +166,19 @@ func init() {
 		}
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Flags:")
-		fmt.Fprintln(out, "  -h, --help      help for rig")
-		fmt.Fprintln(out, "  -v, --version   print version information")
+		flags := [][2]string{
+			{"-h, --help", "help for rig"},
+			{"-v, --version", cmd.Flags().Lookup("version").Usage},
+			{"    --allow-override", cmd.PersistentFlags().Lookup("allow-override").Usage},
+			{"    --accessible", cmd.PersistentFlags().Lookup("accessible").Usage},
+		}
+		width := 0
+		for _, f := range flags {
+			width = max(width, len(f[0]))
+		}
+		for _, f := range flags {
+			fmt.Fprintf(out, "  %-*s   %s\n", width, f[0], f[1])
+		}
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Run \"rig help [command]\" for details.")
 		fmt.Fprintln(out, "Run \"rig tools\" to view tool subcommands.")
@@ -153,17 +222,43 @@ func loadConfigOrFail() (*cfg.Config, string, error) {
 	conf, path, err := cfg.Load("")
 	if err != nil {
 		if errors.Is(err, cfg.ErrConfigNotFound) {
-			return nil, "", errors.New(msgNoConfig)
+			return nil, "", core.WithCode(core.CodeConfigNotFound, errors.New(msgNoConfig))
 		}
-		return nil, "", err
+		return nil, "", core.WithCode(core.CodeConfigInvalid, err)
+	}
+	if err := core.ApplyToolAliases(conf); err != nil {
+		return nil, "", core.WithCode(core.CodeConfigInvalid, err)
 	}
 	return conf, path, nil
 }
 
+// warnIncludeOverrides prints the task, tool, and profile definitions that
+// --allow-override let a later config file replace. Without the flag such
+// conflicts fail the config load instead.
+func warnIncludeOverrides() {
+	if !cfg.AllowIncludeOverride {
+		return
+	}
+	conf, _, err := cfg.Load("")
+	if err != nil {
+		return
+	}
+	for _, c := range conf.IncludeOverrides {
+		statusf(os.Stderr, "⚠️  %s; the later file wins", c)
+	}
+}
+
 // loadConfigOptional loads the config but allows ErrConfigNotFound to be handled by caller.
 // Used by commands like doctor that can work without a config file.
 func loadConfigOptional() (*cfg.Config, string, error) {
-	return cfg.Load("")
+	conf, path, err := cfg.Load("")
+	if err != nil {
+		return conf, path, err
+	}
+	if err := core.ApplyToolAliases(conf); err != nil {
+		return nil, "", err
+	}
+	return conf, path, nil
 }
 
 // firstNonEmpty returns a if a != "", otherwise b.
@@ -190,12 +285,24 @@ func execCommand(name string, args ...string) (string, error) {
 
 // localBinDirFor returns the project-local tool bin directory based on rig.toml path.
 func localBinDirFor(configPath string) string {
-	base := filepath.Dir(configPath)
-	return filepath.Join(base, ".rig", "bin")
+	return core.BinDir(configPath)
+}
+
+// projectLockWait (--wait) makes commands that write .rig/bin or rig.lock
+// wait for another rig process holding the project lock instead of failing.
+var projectLockWait bool
+
+// lockProject takes the project lock for action, noting on stderr when it
+// has to wait for another rig process.
+func lockProject(configPath, action string) (func(), error) {
+	return core.AcquireProjectLock(configPath, action, projectLockWait, func(h core.ProjectLockHolder) {
+		statusf(os.Stderr, "⏳ waiting for another rig process (PID %d) that is %s", h.PID, h.Action)
+	})
 }
 
 // envWithLocalBin returns env entries that ensure the local .rig/bin is preferred on PATH.
-// If includeGOBIN is true, also sets GOBIN to .rig/bin so `go install` writes there.
+// If includeGOBIN is true, also sets GOBIN to .rig/bin so `go install` writes there,
+// with GOWORK and GOFLAGS adjusted so they cannot break installs (see core.GoInstallEnv).
 // Any extra entries provided are preserved and PATH/GOBIN are appended last to win on duplicates.
 func envWithLocalBin(configPath string, extra []string, includeGOBIN bool) []string {
 	localBin := localBinDirFor(configPath)
@@ -251,7 +358,7 @@ func envWithLocalBin(configPath string, extra []string, includeGOBIN bool) []str
 	}
 	// Append GOBIN first or PATH first? Order doesn't matter between them, but both should be last overall.
 	if includeGOBIN {
-		env = append(env, "GOBIN="+localBin)
+		env = append(env, core.GoInstallEnv(localBin, os.Getenv)...)
 	}
 	env = append(env, "PATH="+newPath)
 	return env
@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"bytes"
+	"os"
+	"strings"
 	"testing"
 
 	core "github.com/divijg19/rig/internal/rig"
@@ -46,3 +49,81 @@ func TestMinHelper(t *testing.T) {
 		}
 	}
 }
+
+func TestResolveColorEnabledEnv(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	cases := []struct {
+		name string
+		mode string
+		env  map[string]string
+		want bool
+	}{
+		{"auto pipe", "auto", nil, false},
+		{"force color", "auto", map[string]string{"FORCE_COLOR": "1"}, true},
+		{"force color in ci", "auto", map[string]string{"FORCE_COLOR": "1", "CI": "true"}, true},
+		{"force color zero", "auto", map[string]string{"FORCE_COLOR": "0", "CLICOLOR_FORCE": "1"}, false},
+		{"clicolor force", "auto", map[string]string{"CLICOLOR_FORCE": "1"}, true},
+		{"clicolor force zero", "auto", map[string]string{"CLICOLOR_FORCE": "0"}, false},
+		{"no color beats force", "auto", map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, false},
+		{"always piped", "always", nil, false},
+		{"always forced despite no color", "always", map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, true},
+		{"never beats force", "never", map[string]string{"FORCE_COLOR": "1"}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, k := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR_FORCE", "CI"} {
+				t.Setenv(k, "")
+				os.Unsetenv(k)
+			}
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			got, err := resolveColorEnabled(tc.mode, w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Fatalf("resolveColorEnabled(%q) = %v, want %v", tc.mode, got, tc.want)
+			}
+		})
+	}
+	if _, err := resolveColorEnabled("sometimes", w); err == nil {
+		t.Fatal("expected error for invalid mode")
+	}
+}
+
+func TestRootHelpFlagsAligned(t *testing.T) {
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	t.Cleanup(func() { rootCmd.SetOut(nil) })
+	rootCmd.HelpFunc()(rootCmd, nil)
+	_, block, _ := strings.Cut(buf.String(), "Flags:\n")
+	block, _, _ = strings.Cut(block, "\n\n")
+	col := -1
+	for _, line := range strings.Split(block, "\n") {
+		name := strings.Fields(line[strings.Index(line, "--")+2:])[0]
+		usage := "help for rig"
+		if f := rootCmd.Flags().Lookup(name); f != nil {
+			usage = f.Usage
+		}
+		i := strings.Index(line, usage)
+		if i < 0 {
+			t.Fatalf("help line %q does not show the flag's usage %q", line, usage)
+		}
+		if col >= 0 && i != col {
+			t.Fatalf("flag usages are not aligned:\n%s", block)
+		}
+		col = i
+	}
+	for _, flag := range []string{"--allow-override", "--accessible"} {
+		if !strings.Contains(block, flag) {
+			t.Fatalf("root help is missing %s:\n%s", flag, block)
+		}
+	}
+}
@@ -3,20 +3,49 @@
 package cli
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"sort"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
 	"strings"
+	"syscall"
+	"time"
 
+	cfg "github.com/divijg19/rig/internal/config"
 	core "github.com/divijg19/rig/internal/rig"
 	"github.com/spf13/cobra"
 )
 
 func newRunLikeCommand(use string, short string) *cobra.Command {
 	var list bool
+	var porcelain string
+	var inputFlags []string
+	var output string
+	var continueOnError bool
+	var profile string
+	var heartbeat time.Duration
+	var dirs []string
+	var isolate bool
+	var failed bool
+	var hermetic bool
+	var listFilter, listGroupBy string
+	var listAll, listJSON bool
+	var timeout time.Duration
+	var jobs int
+	var force bool
 	cmd := &cobra.Command{
 		Use:   use,
 		Short: short,
 		Args: func(cmd *cobra.Command, args []string) error {
+			for _, f := range []string{"filter", "group-by", "all", "json"} {
+				if cmd.Flags().Changed(f) && !list {
+					return fmt.Errorf("--%s requires --list", f)
+				}
+			}
 			if list {
 				if cmd.ArgsLenAtDash() >= 0 {
 					return fmt.Errorf("usage: %s --list", cmd.CommandPath())
@@ -26,6 +55,12 @@ func newRunLikeCommand(use string, short string) *cobra.Command {
 				}
 				return nil
 			}
+			if failed {
+				if len(args) != 0 {
+					return fmt.Errorf("usage: %s --failed", cmd.CommandPath())
+				}
+				return nil
+			}
 			dash := cmd.ArgsLenAtDash()
 			if dash >= 0 {
 				if dash != 1 {
@@ -39,35 +74,22 @@ func newRunLikeCommand(use string, short string) *cobra.Command {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			usePorcelain, err := porcelainEnabled(cmd, porcelain, "json")
+			if err != nil {
+				return err
+			}
+			if usePorcelain && !list {
+				return errors.New("--porcelain requires --list")
+			}
 			if list {
-				conf, _, err := core.LoadConfig("")
+				conf, configPath, err := core.LoadConfig("")
 				if err != nil {
 					return err
 				}
-				names := make([]string, 0, len(conf.Tasks))
-				for name := range conf.Tasks {
-					names = append(names, name)
-				}
-				sort.Strings(names)
-				maxNameLen := 0
-				hasDescriptions := false
-				for _, name := range names {
-					if len(name) > maxNameLen {
-						maxNameLen = len(name)
-					}
-					if strings.TrimSpace(conf.Tasks[name].Description) != "" {
-						hasDescriptions = true
-					}
-				}
-				for _, name := range names {
-					desc := strings.TrimSpace(conf.Tasks[name].Description)
-					if hasDescriptions && desc != "" {
-						fmt.Printf("%-*s  %s\n", maxNameLen, name, desc)
-						continue
-					}
-					fmt.Println(name)
-				}
-				return nil
+				return printTaskList(os.Stdout, conf, configPath, taskListOptions{Filter: listFilter, GroupBy: listGroupBy, All: listAll, Porcelain: usePorcelain, JSON: listJSON})
+			}
+			if failed && len(dirs) > 0 {
+				return errors.New("--failed cannot be combined with --dir")
 			}
 			dash := cmd.ArgsLenAtDash()
 			passthrough := []string(nil)
@@ -75,19 +97,132 @@ func newRunLikeCommand(use string, short string) *cobra.Command {
 				passthrough = append([]string(nil), args[dash:]...)
 				args = args[:dash]
 			}
-			if len(args) != 1 {
+			if !failed && len(args) != 1 {
 				return fmt.Errorf("usage: %s <task> [-- args...]", cmd.CommandPath())
 			}
-			return core.Run("", args[0], passthrough)
+			inputs, err := core.ParseInputFlags(inputFlags)
+			if err != nil {
+				return err
+			}
+			if _, err := core.ParseOutputMode(output); err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("heartbeat") {
+				if heartbeat, err = heartbeatFromEnv(); err != nil {
+					return err
+				}
+			}
+			if jobs < 0 {
+				return fmt.Errorf("--jobs must be 0 (one per CPU) or more, got %d", jobs)
+			}
+			if jobs == 0 {
+				jobs = runtime.NumCPU()
+			}
+			opts := core.RunOptions{Inputs: inputs, Output: output, ContinueOnError: continueOnError, Profile: profile, Heartbeat: heartbeat, Dirs: dirs, Isolate: isolate, Hermetic: hermetic, Jobs: jobs, Force: force}
+			if isTTY(os.Stdin) {
+				opts.Prompt = promptTaskInput(bufio.NewReader(os.Stdin))
+			}
+			ctx, stop := runContext(timeout)
+			defer stop()
+			if failed {
+				return core.RunFailed(ctx, "", opts)
+			}
+			return core.RunWith(ctx, "", args[0], passthrough, opts)
 		},
 	}
 	cmd.Flags().BoolVar(&list, "list", false, "list available tasks and exit")
+	cmd.Flags().StringVar(&listFilter, "filter", "", "with --list, show only tasks whose name matches this glob (e.g. \"db:*\")")
+	cmd.Flags().StringVar(&listGroupBy, "group-by", "", "with --list, group tasks under headers: namespace (the name before ':')")
+	cmd.Flags().BoolVar(&listAll, "all", false, "with --list, include tasks marked internal = true")
+	cmd.Flags().BoolVar(&listJSON, "json", false, "with --list, print tasks as JSON with their last run from .rig/history.json")
+	addPorcelainFlag(cmd, &porcelain)
+	cmd.Flags().StringArrayVar(&inputFlags, "input", nil, "task input as name=value (repeatable)")
+	cmd.Flags().StringVar(&output, "output", core.OutputFull, "dependency task output: errors-only|prefixed|full")
+	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "keep running independent tasks after a failure and report all failures at the end")
+	cmd.Flags().StringVar(&profile, "profile", "", "apply env, tags, and flags from rig.toml [profile.<name>] to every task")
+	cmd.Flags().DurationVar(&heartbeat, "heartbeat", 0, "print a still-running line to stderr when a task is silent this long, e.g. 1m (default $RIG_HEARTBEAT)")
+	cmd.Flags().StringArrayVarP(&dirs, "dir", "C", nil, "run the task in this directory instead of its cwd (repeatable, globs allowed)")
+	_ = cmd.MarkFlagDirname("dir")
+	cmd.Flags().BoolVar(&isolate, "isolate", false, "run each task in a temp copy of its declared sources and copy back only its declared outputs")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 1, "run up to N task commands at once, running independent depends_on branches concurrently (0: one per CPU)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "cancel the run (interrupting running commands) after this long, e.g. 10m")
+	cmd.Flags().BoolVar(&hermetic, "hermetic", false, "pass tasks only the environment variables in [ci].env_allowlist, as under CI")
+	cmd.Flags().BoolVar(&force, "force", false, "run tasks with sources and outputs even when they are up to date")
+	cmd.Flags().BoolVar(&failed, "failed", false, "rerun only the tasks (and directories) that failed in the last run, skipping those that succeeded")
+	cmd.ValidArgsFunction = completeTaskNames
+	_ = cmd.RegisterFlagCompletionFunc("profile", completeProfileNames)
+	_ = cmd.RegisterFlagCompletionFunc("group-by", cobra.FixedCompletions([]string{"namespace"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("input", completeTaskInputs)
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(
+		[]string{core.OutputErrorsOnly, core.OutputPrefixed, core.OutputFull}, cobra.ShellCompDirectiveNoFileComp))
 	return cmd
 }
 
+// heartbeatFromEnv reads the --heartbeat default from RIG_HEARTBEAT, so CI
+// can enable it once for every `rig run`.
+func heartbeatFromEnv() (time.Duration, error) {
+	v := strings.TrimSpace(os.Getenv("RIG_HEARTBEAT"))
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid RIG_HEARTBEAT %q: %w", v, err)
+	}
+	return d, nil
+}
+
+// promptTaskInput asks for a task input on the terminal; an empty answer
+// keeps the declared default.
+func promptTaskInput(r *bufio.Reader) func(cfg.TaskInput) (string, error) {
+	return func(in cfg.TaskInput) (string, error) {
+		prompt := in.Prompt
+		if prompt == "" {
+			prompt = in.Name + "?"
+		}
+		if in.Default != "" {
+			prompt += fmt.Sprintf(" [%s]", in.Default)
+		}
+		fmt.Fprintf(os.Stderr, "%s ", prompt)
+		line, err := r.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+}
+
 // runCmd represents the v0.2 `rig run <task>` command.
 var runCmd = newRunLikeCommand("run", "Run a named task from rig.toml")
 
 func init() {
 	rootCmd.AddCommand(runCmd)
 }
+
+// runContext returns the context a `rig run` executes under: canceled on
+// SIGINT or SIGTERM, and after timeout when it is positive. Cancellation
+// interrupts running commands and keeps further tasks from starting.
+func runContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-sigCh:
+			cancel(fmt.Errorf("received %s", sig))
+		case <-ctx.Done():
+		}
+	}()
+	stop := func() {
+		signal.Stop(sigCh)
+		cancel(nil)
+	}
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancelTimeout := context.WithTimeoutCause(ctx, timeout, fmt.Errorf("--timeout %s exceeded", timeout))
+	return ctx, func() {
+		cancelTimeout()
+		stop()
+	}
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+	core "github.com/divijg19/rig/internal/rig"
+)
+
+// taskListOptions are the `rig run --list` flags.
+type taskListOptions struct {
+	Filter    string // glob on the task name, e.g. "db:*"
+	GroupBy   string // "" or "namespace"
+	All       bool   // include internal = true tasks
+	Porcelain bool
+	JSON      bool
+}
+
+// taskListEntry is one task in `rig run --list --json`. The last* fields
+// come from .rig/history.json and are omitted for tasks that never ran.
+type taskListEntry struct {
+	Name         string     `json:"name"`
+	Description  string     `json:"description,omitempty"`
+	Namespace    string     `json:"namespace,omitempty"`
+	Internal     bool       `json:"internal,omitempty"`
+	DependsOn    []string   `json:"dependsOn,omitempty"`
+	Cacheable    bool       `json:"cacheable"`
+	LastRun      *time.Time `json:"lastRun,omitempty"`
+	LastDuration *int64     `json:"lastDuration,omitempty"` // milliseconds
+	LastExitCode *int       `json:"lastExitCode,omitempty"`
+}
+
+// taskNamespace is the part of a task name before its first ':' ("db" for
+// "db:migrate"); tasks without one have no namespace.
+func taskNamespace(name string) string {
+	ns, _, ok := strings.Cut(name, ":")
+	if !ok {
+		return ""
+	}
+	return ns
+}
+
+// listedTasks returns the sorted names --list shows.
+func listedTasks(conf *cfg.Config, o taskListOptions) ([]string, error) {
+	if o.Filter != "" {
+		if _, err := path.Match(o.Filter, ""); err != nil {
+			return nil, fmt.Errorf("--filter %q: %w", o.Filter, err)
+		}
+	}
+	names := make([]string, 0, len(conf.Tasks))
+	for name, t := range conf.Tasks {
+		if t.Internal && !o.All {
+			continue
+		}
+		if o.Filter != "" {
+			if ok, _ := path.Match(o.Filter, name); !ok {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// printTaskList writes `rig run --list`: one task per line, descriptions
+// aligned in a column, and with --group-by namespace a header per namespace
+// (tasks without one come first).
+func printTaskList(w *os.File, conf *cfg.Config, configPath string, o taskListOptions) error {
+	switch o.GroupBy {
+	case "", "namespace":
+	default:
+		return fmt.Errorf("--group-by %q: only \"namespace\" is supported", o.GroupBy)
+	}
+	if o.JSON && o.GroupBy != "" {
+		return errors.New("--group-by cannot be combined with --json (each task carries its namespace)")
+	}
+	names, err := listedTasks(conf, o)
+	if err != nil {
+		return err
+	}
+	if o.JSON {
+		last := core.LastRuns(configPath)
+		entries := make([]taskListEntry, 0, len(names))
+		for _, name := range names {
+			t := conf.Tasks[name]
+			e := taskListEntry{
+				Name:        name,
+				Description: strings.TrimSpace(t.Description),
+				Namespace:   taskNamespace(name),
+				Internal:    t.Internal,
+				DependsOn:   t.DependsOn,
+				Cacheable:   core.TaskCacheable(t),
+			}
+			if r, ok := last[name]; ok {
+				ms := r.Duration.Milliseconds()
+				e.LastRun, e.LastDuration, e.LastExitCode = &r.At, &ms, &r.ExitCode
+			}
+			entries = append(entries, e)
+		}
+		b, err := stdjson.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+	if o.Porcelain {
+		// v1: task <name> <description>
+		for _, name := range names {
+			porcelainLine(w, "task", name, strings.TrimSpace(conf.Tasks[name].Description))
+		}
+		return nil
+	}
+
+	groupBy := o.GroupBy
+	if groupBy == "" && slices.ContainsFunc(names, func(n string) bool { return conf.Tasks[n].Namespaced }) {
+		// Tasks declared in namespace tables ([tasks.db.migrate]) are listed
+		// by namespace without asking.
+		groupBy = "namespace"
+	}
+	indent := ""
+	groups := [][]string{names}
+	if groupBy == "namespace" {
+		indent = "  "
+		byNS := map[string][]string{}
+		var order []string
+		for _, name := range names {
+			ns := taskNamespace(name)
+			if _, ok := byNS[ns]; !ok {
+				order = append(order, ns)
+			}
+			byNS[ns] = append(byNS[ns], name)
+		}
+		sort.Strings(order) // "" sorts first
+		groups = groups[:0]
+		for _, ns := range order {
+			groups = append(groups, byNS[ns])
+		}
+	}
+
+	maxNameLen := 0
+	for _, name := range names {
+		maxNameLen = max(maxNameLen, len(name))
+	}
+	out := newStyledWriter(w)
+	for i, group := range groups {
+		if groupBy == "namespace" {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			header := taskNamespace(group[0]) + ":"
+			if header == ":" {
+				header = "(no namespace):"
+			}
+			out.linef(ansiBoldCyan, "%s", header)
+		}
+		for _, name := range group {
+			desc := strings.TrimSpace(conf.Tasks[name].Description)
+			if desc == "" {
+				fmt.Fprintf(w, "%s%s\n", indent, name)
+				continue
+			}
+			fmt.Fprintf(w, "%s%s  %s\n", indent, out.paint(ansiBoldCyan, fmt.Sprintf("%-*s", maxNameLen, name)), desc)
+		}
+	}
+	return nil
+}
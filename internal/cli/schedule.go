@@ -0,0 +1,101 @@
+// internal/cli/schedule.go
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var scheduleList bool
+
+// scheduleCmd runs [schedules] entries in the foreground.
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Run tasks on the cron schedules in [schedules]",
+	Long: `Run tasks on the cron-like schedules declared in [schedules] until interrupted.
+
+Each entry maps a task name to a five-field cron expression, a macro such as
+@hourly or @daily, or "@every <duration>". Runs are logged to stdout and
+appended to .rig/logs/schedule.log. A task that is still running when it is
+due again is skipped, not overlapped.`,
+	Args: cobra.NoArgs,
+	Example: `
+  rig schedule
+  rig schedule --list
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, confPath, err := loadConfigOrFail()
+		if err != nil {
+			return err
+		}
+		entries, err := core.LoadSchedules(conf)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return fmt.Errorf("no [schedules] defined in %s", confPath)
+		}
+
+		now := time.Now()
+		if scheduleList {
+			for _, e := range entries {
+				next := "never"
+				if t := e.Schedule.Next(now); !t.IsZero() {
+					next = t.Format(time.RFC3339)
+				}
+				fmt.Printf("%-20s  %-20s  next %s\n", e.Task, e.Expr, next)
+			}
+			return nil
+		}
+
+		logDir := core.TaskLogDir(confPath)
+		if err := os.MkdirAll(logDir, 0o755); err != nil {
+			return err
+		}
+		logFile, err := os.OpenFile(filepath.Join(logDir, "schedule.log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		defer logFile.Close()
+
+		var logMu sync.Mutex
+		logf := func(format string, args ...any) {
+			line := fmt.Sprintf("%s %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+			logMu.Lock()
+			defer logMu.Unlock()
+			_, _ = io.WriteString(os.Stdout, line)
+			_, _ = io.WriteString(logFile, line)
+		}
+
+		stdout := newStyledWriter(os.Stdout)
+		stdout.linef(ansiBoldCyan, "⏱  scheduler started (%d task(s), Ctrl+C to stop)", len(entries))
+		for _, e := range entries {
+			if t := e.Schedule.Next(now); !t.IsZero() {
+				fmt.Printf("  %s  %s  next %s\n", e.Task, e.Expr, t.Format(time.RFC3339))
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		core.RunScheduler(ctx, entries, func(task string) error {
+			return core.RunWith(ctx, filepath.Dir(confPath), task, nil, core.RunOptions{})
+		}, logf)
+		return nil
+	},
+}
+
+func init() {
+	scheduleCmd.Flags().BoolVar(&scheduleList, "list", false, "list schedules and their next run time, then exit")
+	rootCmd.AddCommand(scheduleCmd)
+}
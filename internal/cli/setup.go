@@ -39,25 +39,31 @@ var setupCmd = &cobra.Command{
 		tools := mergeTools(conf.Tools, extraTools)
 		tools = stripGoToolchain(tools) // Go is a toolchain, not a rig-managed installable tool.
 		if len(tools) == 0 {
-			fmt.Printf("ℹ️  No [tools] specified in %s or provided via .txt\n", path)
+			statusf(os.Stdout, "ℹ️  No [tools] specified in %s or provided via .txt", path)
 			return nil
 		}
 		if setupCheck {
-			fmt.Printf("🔍 Checking pinned tools from %s\n", path)
+			statusf(os.Stdout, "🔍 Checking pinned tools from %s", path)
 		} else {
-			fmt.Printf("🔧 Setting up tools from %s\n", path)
+			statusf(os.Stdout, "🔧 Setting up tools from %s", path)
 		}
 
 		if setupCheck {
-			return checkToolsSync(mergeTools(conf.Tools, extraTools), path)
+			return checkToolsSync(mergeTools(conf.Tools, extraTools), path, formatTable)
 		}
 
+		release, err := lockProject(path, "setting up tools")
+		if err != nil {
+			return err
+		}
+		defer release()
+
 		// Ensure local bin dir exists and prepare env with GOBIN and PATH
 		binDir := localBinDirFor(path)
 		if err := os.MkdirAll(binDir, 0o755); err != nil {
 			return fmt.Errorf("create local bin dir: %w", err)
 		}
-		env := envWithLocalBin(path, nil, true)
+		env := envWithLocalBin(path, core.GoCacheEnv(conf, path), true)
 
 		// Resolve and install deterministically.
 		lockedTools, err := core.ResolveLockedTools(tools, filepath.Dir(path), env)
@@ -72,8 +78,11 @@ var setupCmd = &cobra.Command{
 			}
 			_, resolvedVer := core.SplitResolved(lt.Resolved)
 			id := core.ResolveToolIdentity(toolName)
-			installWithVer := id.InstallPath + "@" + resolvedVer
-			if err := execCommandSilentEnv("go", []string{"install", installWithVer}, env); err != nil {
+			if core.IsOCITool(lt) {
+				if err := core.InstallOCITool(path, lt); err != nil {
+					return fmt.Errorf("install %s: %w", lt.Requested, err)
+				}
+			} else if err := execCommandSilentEnv("go", []string{"install", id.InstallPath + "@" + resolvedVer}, env); err != nil {
 				return fmt.Errorf("install %s: %w", lt.Requested, err)
 			}
 			bin := strings.TrimSpace(lt.Bin)
@@ -85,10 +94,10 @@ var setupCmd = &cobra.Command{
 				return fmt.Errorf("compute sha256 for %s: %w", bin, herr)
 			}
 			lockedTools[i].SHA256 = sum
-			fmt.Printf("✅ %s %s installed\n", bin, resolvedVer)
+			statusf(os.Stdout, "✅ %s %s installed", bin, resolvedVer)
 		}
 
-		rigLock := core.Lockfile{Schema: core.LockSchema0, Toolchain: nil, Tools: lockedTools}
+		rigLock := core.Lockfile{Schema: core.LockSchema0, Meta: core.NewLockMeta(version, conf), Toolchain: nil, Tools: lockedTools}
 		rigLockPath := rigLockPathFor(path)
 		if err := core.WriteLockfile(rigLockPath, rigLock); err != nil {
 			return fmt.Errorf("write rig.lock: %w", err)
@@ -104,6 +113,7 @@ var setupCmd = &cobra.Command{
 
 func init() {
 	setupCmd.Flags().BoolVar(&setupCheck, "check", false, "verify installed tool versions against rig.toml (no install)")
+	setupCmd.Flags().BoolVar(&projectLockWait, "wait", false, "wait for another rig process that is syncing this project instead of failing")
 	rootCmd.AddCommand(setupCmd)
 }
 
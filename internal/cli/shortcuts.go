@@ -36,20 +36,22 @@ var lsToolsCmd = &cobra.Command{
 }
 
 var pathCmd = &cobra.Command{
-	Use:    "path <name>",
-	Short:  "Print absolute path of a managed tool",
-	Args:   cobra.ExactArgs(1),
-	Hidden: true,
+	Use:               "path <name>",
+	Short:             "Print absolute path of a managed tool",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeToolNames,
+	Hidden:            true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return toolsPathCmd.RunE(toolsPathCmd, args)
 	},
 }
 
 var whyCmd = &cobra.Command{
-	Use:    "why <name>",
-	Short:  "Explain tool provenance",
-	Args:   cobra.ExactArgs(1),
-	Hidden: true,
+	Use:               "why <name>",
+	Short:             "Explain tool provenance",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeToolNames,
+	Hidden:            true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return toolsWhyCmd.RunE(toolsWhyCmd, args)
 	},
@@ -58,10 +60,22 @@ var whyCmd = &cobra.Command{
 func init() {
 	// Mirror relevant flags so they affect the same underlying variables
 	syncCmd.Flags().BoolVar(&toolsCheck, "check", false, "verify tools are in sync without installing")
-	syncCmd.Flags().BoolVar(&toolsCheckJSON, "json", false, "use with --check to print machine-readable JSON summary")
+	syncCmd.Flags().BoolVar(&projectLockWait, "wait", false, "wait for another rig process that is syncing this project instead of failing")
+	syncCmd.Flags().BoolVar(&toolsCheckJSON, "json", false, "print machine-readable JSON (the diff with --check, the plan with --dry-run, per-tool timings otherwise)")
 	syncCmd.Flags().BoolVar(&toolsOffline, "offline", false, "do not download modules (sets GOPROXY=off, GOSUMDB=off)")
+	syncCmd.Flags().BoolVar(&toolsDryRun, "dry-run", false, "print what sync would install/upgrade/rebuild and how rig.lock would change, without writing")
+	syncCmd.Flags().BoolVar(&toolsPrune, "prune", false, "remove binaries from .rig/bin that are not in rig.lock after syncing")
+	syncCmd.Flags().BoolVarP(&toolsYes, "yes", "y", false, "do not ask for confirmation before pruning")
+	syncCmd.Flags().BoolVar(&toolsFromLock, "from-lock", false, "install exactly the versions in rig.lock without re-resolving rig.toml")
+	syncCmd.Flags().BoolVar(&toolsInsecure, "insecure", false, "accept tool modules whose checksums cannot be verified against the checksum database")
+	syncCmd.Flags().StringVar(&toolsGo, "go", "", "repair a Go toolchain mismatch first: ask|pin (use the installed go)|install (download tools.go's version)")
+	syncCmd.Flags().Lookup("go").NoOptDefVal = goRepairAsk
 
 	outdatedCmd.Flags().BoolVar(&outdatedJSON, "json", false, "print machine-readable JSON status")
+	outdatedCmd.Flags().BoolVar(&outdatedReleases, "releases", false, "also check for Go patch releases and rig updates (contacts go.dev and GitHub; --json prints {\"items\": [...]})")
+	outdatedCmd.Flags().BoolVar(&toolsOffline, "offline", false, "with --releases, use cached Go and rig release data instead of fetching it")
+	lsToolsCmd.Flags().BoolVar(&lsJSON, "json", false, "print machine-readable JSON")
+	lsToolsCmd.Flags().StringVar(&lsStatus, "status", "", "only list tools with these statuses (comma-separated: ok|missing|mismatch|stale)")
 
 	rootCmd.AddCommand(syncCmd)
 	rootCmd.AddCommand(outdatedCmd)
@@ -2,20 +2,66 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	core "github.com/divijg19/rig/internal/rig"
 	"github.com/spf13/cobra"
 )
 
+var (
+	statusQuiet     bool
+	statusPorcelain string
+	statusAll       bool
+)
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show rig status (read-only)",
-	Args:  cobra.NoArgs,
+	Long: `Show rig status (read-only).
+
+With --quiet nothing is printed; the exit code is 0 when rig.lock matches
+rig.toml and tools are installed, 1 when out of sync, and 2 on error.
+
+With --porcelain the same facts print as stable tab-separated records (see
+docs/CLI.md).
+
+With --all, every project under [dir] (default: the current directory) that
+has a rig.toml gets one status line, e.g. to audit a directory of service
+repositories or a workspace root.`,
+	Example: `
+  rig status
+  rig status --all ~/src/services
+  rig status --all --quiet || echo "some projects need 'rig sync'"
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if statusAll {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.NoArgs(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		usePorcelain, err := porcelainEnabled(cmd, statusPorcelain, "quiet")
+		if err != nil {
+			return err
+		}
+		if statusAll {
+			return statusAllProjects(cmd, args, usePorcelain)
+		}
 		rep, err := core.Status("")
+		if statusQuiet {
+			return quietExit(cmd, rep.HasLock && rep.LockMatchesConfig && rep.ToolsOK, err)
+		}
 		if err != nil {
 			return err
 		}
+		if usePorcelain {
+			printStatusPorcelain(os.Stdout, rep)
+			return nil
+		}
 		fmt.Printf("config: %s\n", rep.ConfigPath)
 		if !rep.HasLock {
 			fmt.Printf("lock: %s (missing)\n", rep.LockPath)
@@ -40,6 +86,138 @@ var statusCmd = &cobra.Command{
 	},
 }
 
+// printStatusPorcelain prints rep as porcelain v1 records:
+//
+//	config <path>
+//	lock <path> <present|missing>
+//	tools <ok|out-of-sync> <missing> <mismatched> <extras>   (lock present)
+//	go <requested> <locked> <have> <status>                  (go pinned)
+func printStatusPorcelain(w io.Writer, rep core.StatusReport) {
+	porcelainLine(w, "config", rep.ConfigPath)
+	if !rep.HasLock {
+		porcelainLine(w, "lock", rep.LockPath, "missing")
+		return
+	}
+	porcelainLine(w, "lock", rep.LockPath, "present")
+	state := "ok"
+	if !rep.LockMatchesConfig || !rep.ToolsOK {
+		state = "out-of-sync"
+	}
+	porcelainLine(w, "tools", state, strconv.Itoa(rep.Missing), strconv.Itoa(rep.Mismatched), strconv.Itoa(rep.Extras))
+	if rep.Go != nil {
+		porcelainLine(w, "go", rep.Go.Requested, rep.Go.Locked, rep.Go.Have, rep.Go.Status)
+	}
+}
+
+// statusAllProjects implements `rig status --all [dir]`.
+func statusAllProjects(cmd *cobra.Command, args []string, usePorcelain bool) error {
+	root := "."
+	if len(args) == 1 {
+		root = args[0]
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	projects, err := core.StatusAll(root)
+	allOK := true
+	for _, p := range projects {
+		allOK = allOK && p.OK()
+	}
+	if statusQuiet {
+		return quietExit(cmd, allOK, err)
+	}
+	if err != nil {
+		return err
+	}
+	if usePorcelain {
+		for _, p := range projects {
+			printProjectStatusPorcelain(os.Stdout, p)
+		}
+		return nil
+	}
+	if len(projects) == 0 {
+		statusf(os.Stdout, "ℹ️  No rig.toml found under %s", root)
+		return nil
+	}
+	printProjectStatuses(newStyledWriter(os.Stdout), projects)
+	return nil
+}
+
+// projectLockState is "fresh" when rig.lock matches rig.toml, "stale" when it
+// does not, and "missing" without one.
+func projectLockState(p core.ProjectStatus) string {
+	switch {
+	case !p.HasLock:
+		return "missing"
+	case !p.LockMatchesConfig:
+		return "stale"
+	}
+	return "fresh"
+}
+
+// printProjectStatuses prints one aligned line per project and a summary.
+func printProjectStatuses(out *styledWriter, projects []core.ProjectStatus) {
+	tools := make([]string, len(projects))
+	goStates := make([]string, len(projects))
+	dirWidth, toolsWidth := 0, 0
+	for i, p := range projects {
+		tools[i], goStates[i] = "-", "-"
+		if p.HasLock && p.LockMatchesConfig {
+			tools[i] = "ok"
+			if p.Missing+p.Mismatched > 0 {
+				tools[i] = fmt.Sprintf("%d missing, %d mismatched", p.Missing, p.Mismatched)
+			}
+			if g := p.Go; g != nil {
+				goStates[i] = strings.TrimSpace(g.Status + " " + g.Have)
+			}
+		}
+		dirWidth = max(dirWidth, len(p.Dir))
+		toolsWidth = max(toolsWidth, len(tools[i]))
+	}
+	ok := 0
+	for i, p := range projects {
+		if p.Error != "" {
+			// Parse errors carry a multi-line excerpt; the first line locates it.
+			msg, _, _ := strings.Cut(p.Error, "\n")
+			out.linef(ansiRed, "❌ %-*s  error: %s", dirWidth, p.Dir, msg)
+			continue
+		}
+		line := fmt.Sprintf("%-*s  lock %-7s  tools %-*s  go %s", dirWidth, p.Dir, projectLockState(p), toolsWidth, tools[i], goStates[i])
+		if p.OK() {
+			ok++
+			out.linef(ansiGreen, "✅ %s", line)
+		} else {
+			out.linef(ansiYellow, "⚠️  %s", line)
+		}
+	}
+	fmt.Fprintf(out.w, "%d project(s): %d in sync, %d need attention\n", len(projects), ok, len(projects)-ok)
+}
+
+// printProjectStatusPorcelain prints one porcelain v1 record for `status --all`:
+//
+//	project <dir> <ok|out-of-sync|error> <fresh|stale|missing> <missing> <mismatched> <go-status> <error>
+func printProjectStatusPorcelain(w io.Writer, p core.ProjectStatus) {
+	state := "ok"
+	switch {
+	case p.Error != "":
+		state = "error"
+	case !p.OK():
+		state = "out-of-sync"
+	}
+	lock, goStatus := "", ""
+	if p.Error == "" {
+		lock = projectLockState(p)
+	}
+	if p.Go != nil {
+		goStatus = p.Go.Status
+	}
+	porcelainLine(w, "project", p.Dir, state, lock, strconv.Itoa(p.Missing), strconv.Itoa(p.Mismatched), goStatus, p.Error)
+}
+
 func init() {
+	statusCmd.Flags().BoolVar(&statusAll, "all", false, "show a one-line status for every project with a rig.toml under [dir]")
+	statusCmd.Flags().BoolVarP(&statusQuiet, "quiet", "q", false, "print nothing; report through the exit code (0 ok, 1 out of sync, 2 error)")
+	addPorcelainFlag(statusCmd, &statusPorcelain)
 	rootCmd.AddCommand(statusCmd)
 }
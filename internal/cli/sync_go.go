@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	core "github.com/divijg19/rig/internal/rig"
+)
+
+// `rig sync --go` modes.
+const (
+	goRepairAsk     = "ask"
+	goRepairPin     = "pin"
+	goRepairInstall = "install"
+)
+
+// repairGoToolchain implements `rig sync --go`: when the installed go, tools.go
+// and rig.lock disagree it either pins tools.go to the installed go or has
+// the go command install the requested toolchain, asking which when mode is
+// "ask". The sync that follows then rewrites rig.lock.
+func repairGoToolchain(mode string) error {
+	switch mode {
+	case goRepairAsk, goRepairPin, goRepairInstall:
+	default:
+		return fmt.Errorf("invalid --go %q (expected ask|pin|install)", mode)
+	}
+	conf, path, err := loadConfigOrFail()
+	if err != nil {
+		return err
+	}
+	goReq := strings.TrimSpace(conf.Tools["go"])
+	if goReq == "" {
+		return errors.New("--go: rig.toml does not declare tools.go")
+	}
+	st, err := core.InspectGoToolchain(path, goReq)
+	if err != nil {
+		return err
+	}
+	out := newStyledWriter(os.Stderr)
+	switch {
+	case st.InSync():
+		out.linef(ansiGreen, "✅ Go toolchain in sync (go%s)", st.Have)
+		return nil
+	case st.Satisfied():
+		out.linef(ansiYellow, "🔧 rig.lock pins go %s; recording the installed go%s", orDash(st.Locked), st.Have)
+		return nil
+	}
+	out.linef(ansiYellow, "⚠️  Go toolchain mismatch: tools.go wants go%s, installed go is go%s (rig.lock: %s)", st.Requested, st.Have, orDash(st.Locked))
+
+	if mode == goRepairAsk {
+		if !isTTY(os.Stdin) {
+			return errors.New("choose a fix with --go=pin (use the installed go) or --go=install (download the requested go)")
+		}
+		if mode, err = askGoRepair(st); err != nil {
+			return err
+		}
+	}
+	switch mode {
+	case goRepairPin:
+		if err := core.SetConfigGoVersion(path, st.Have); err != nil {
+			return err
+		}
+		out.linef(ansiGreen, "📌 tools.go = %q in %s", st.Have, path)
+	case goRepairInstall:
+		if !st.CanInstall() {
+			return fmt.Errorf("go%s is not newer than the installed go%s; the go command only switches to newer toolchains (install go%s, or use --go=pin)", st.Requested, st.Have, st.Requested)
+		}
+		out.linef(ansiBoldCyan, "⬇️  Installing go%s via the toolchain directive in go.mod", st.Requested)
+		if _, err := core.InstallGoToolchain(path, st.Requested); err != nil {
+			return err
+		}
+		out.linef(ansiGreen, "✅ go%s is now used in this project", st.Requested)
+	}
+	return nil
+}
+
+// askGoRepair offers the fixes that apply to st and returns the chosen mode.
+func askGoRepair(st core.GoToolchainState) (string, error) {
+	choices := []string{goRepairPin}
+	fmt.Fprintf(os.Stderr, "  1) use the installed go%s (set tools.go = %q and update rig.lock)\n", st.Have, st.Have)
+	if st.CanInstall() {
+		choices = append(choices, goRepairInstall)
+		fmt.Fprintf(os.Stderr, "  2) install go%s (record `toolchain go%s` in go.mod; the go command downloads it)\n", st.Requested, st.Requested)
+	}
+	fmt.Fprintf(os.Stderr, "Choose [1-%d, anything else aborts]: ", len(choices))
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.TrimSpace(line) {
+	case "1":
+		return choices[0], nil
+	case "2":
+		if len(choices) > 1 {
+			return choices[1], nil
+		}
+	}
+	return "", errors.New("aborted; Go toolchain left unchanged")
+}
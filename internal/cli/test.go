@@ -0,0 +1,60 @@
+// internal/cli/test.go
+
+package cli
+
+import (
+	"os"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	testProfile string
+	testTags    []string
+	testDir     string
+	testDryRun  bool
+)
+
+// testCmd implements `rig test` with optional profiles.
+var testCmd = &cobra.Command{
+	Use:   "test [packages] [-- go test flags]",
+	Short: "Run go test using optional profiles from rig.toml",
+	Long:  "Compose and run 'go test' using tags, flags, and env from rig.toml profiles (the same [profile.<name>] used by 'rig build'). Packages default to ./... .",
+	Example: `
+	rig test
+	rig test --profile race
+	rig test ./internal/... -- -run TestParse -count=1
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, path, err := loadConfigOrFail()
+		if err != nil {
+			return err
+		}
+		prof, err := core.LookupProfile(conf, path, testProfile)
+		if err != nil {
+			return err
+		}
+
+		cmdline, env := core.ComposeTestCommand(prof, core.BuildOverrides{Tags: testTags}, args)
+		env = envWithLocalBin(path, append(core.GoCacheEnv(conf, path), env...), false)
+
+		if testDryRun {
+			newStyledWriter(os.Stdout).linef(ansiYellow, "🧪 Dry run: would execute -> %s", cmdline)
+			return nil
+		}
+
+		newStyledWriter(os.Stdout).linef(ansiBoldCyan, "🧪 Testing (profile=%q) using config %s", testProfile, path)
+		cmd.SilenceUsage = true
+		return core.ExecuteShell(cmdline, core.ExecOptions{Dir: testDir, Env: env})
+	},
+}
+
+func init() {
+	testCmd.Flags().StringVar(&testProfile, "profile", "", "profile from rig.toml [profile.<name>]")
+	_ = testCmd.RegisterFlagCompletionFunc("profile", completeProfileNames)
+	testCmd.Flags().StringSliceVarP(&testTags, "tags", "t", nil, "comma-separated build tags (overrides profile)")
+	testCmd.Flags().StringVarP(&testDir, "dir", "C", "", "working directory for go test")
+	testCmd.Flags().BoolVarP(&testDryRun, "dry-run", "n", false, "print the go test command without executing")
+	rootCmd.AddCommand(testCmd)
+}
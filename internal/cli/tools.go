@@ -3,46 +3,136 @@
 package cli
 
 import (
+	"bufio"
 	stdjson "encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"text/tabwriter"
 
+	cfg "github.com/divijg19/rig/internal/config"
 	core "github.com/divijg19/rig/internal/rig"
 	"github.com/spf13/cobra"
 )
 
 var (
-	toolsCheck     bool
-	outdatedJSON   bool
-	toolsCheckJSON bool
-	toolsOffline   bool
+	toolsCheck       bool
+	outdatedJSON     bool
+	outdatedReleases bool
+	toolsCheckJSON   bool
+	toolsFormat      string
+	toolsOffline     bool
+	toolsDryRun      bool
+	toolsPrune       bool
+	toolsYes         bool
+	toolsInsecure    bool
+	toolsFromLock    bool
+	toolsGo          string
+	searchJSON       bool
+	searchLimit      int
+	lsJSON           bool
+	doctorJSON       bool
+	lsStatus         string
+	lsPorcelain      string
 )
 
 var toolsLsCmd = &cobra.Command{
 	Use:   "ls",
 	Short: "List managed tools",
+	Long:  "List tools from rig.lock with their requested and resolved versions, install status (ok, missing, mismatch, stale), and binary path.",
 	Args:  cobra.NoArgs,
+	Example: `
+  rig tools ls
+  rig tools ls --status missing,mismatch
+  rig tools ls --json | jq -r '.[].path'
+`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		want, err := parseToolStatusFilter(lsStatus)
+		if err != nil {
+			return err
+		}
+		usePorcelain, err := porcelainEnabled(cmd, lsPorcelain, "json")
+		if err != nil {
+			return err
+		}
 		items, err := core.ToolsLS("")
 		if err != nil {
 			return err
 		}
+		if len(want) > 0 {
+			filtered := items[:0]
+			for _, it := range items {
+				if _, ok := want[it.Status]; ok {
+					filtered = append(filtered, it)
+				}
+			}
+			items = filtered
+		}
+
+		if lsJSON {
+			if items == nil {
+				items = []core.ManagedToolInfo{}
+			}
+			b, err := stdjson.MarshalIndent(items, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+		if usePorcelain {
+			// v1: tool <name> <requested> <resolved> <status> <path>
+			for _, it := range items {
+				porcelainLine(os.Stdout, "tool", it.Name, it.Requested, it.Resolved, string(it.Status), it.Path)
+			}
+			return nil
+		}
+		if len(items) == 0 {
+			if len(want) > 0 {
+				statusf(os.Stdout, "ℹ️  No tools with status %s", lsStatus)
+			} else {
+				statusf(os.Stdout, "ℹ️  No managed tools in rig.lock")
+			}
+			return nil
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tREQUESTED\tRESOLVED\tSTATUS\tPATH")
 		for _, it := range items {
-			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", it.Name, it.Requested, it.Resolved, it.Path, string(it.Status))
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", it.Name, it.Requested, it.Resolved, it.Status, it.Path)
 		}
-		return nil
+		return tw.Flush()
 	},
 }
 
+// parseToolStatusFilter parses a comma-separated --status list.
+func parseToolStatusFilter(s string) (map[core.ToolState]struct{}, error) {
+	want := map[core.ToolState]struct{}{}
+	for _, part := range strings.Split(s, ",") {
+		st := core.ToolState(strings.ToLower(strings.TrimSpace(part)))
+		switch st {
+		case "":
+			continue
+		case core.ToolOK, core.ToolMissing, core.ToolMismatch, core.ToolStale:
+			want[st] = struct{}{}
+		default:
+			return nil, fmt.Errorf("invalid --status %q (expected ok|missing|mismatch|stale)", part)
+		}
+	}
+	return want, nil
+}
+
 var toolsPathCmd = &cobra.Command{
-	Use:   "path <name>",
-	Short: "Print absolute path of a managed tool",
-	Args:  cobra.ExactArgs(1),
+	Use:               "path <name>",
+	Short:             "Print absolute path of a managed tool",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeToolNames,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		p, err := core.ToolPath("", args[0])
 		if err != nil {
@@ -54,9 +144,10 @@ var toolsPathCmd = &cobra.Command{
 }
 
 var toolsWhyCmd = &cobra.Command{
-	Use:   "why <name>",
-	Short: "Explain tool provenance",
-	Args:  cobra.ExactArgs(1),
+	Use:               "why <name>",
+	Short:             "Explain tool provenance",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeToolNames,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		info, err := core.ToolWhy("", args[0])
 		if err != nil {
@@ -74,7 +165,20 @@ var toolsWhyCmd = &cobra.Command{
 var toolsDoctorCmd = &cobra.Command{
 	Use:   "doctor [name]",
 	Short: "Diagnose managed tools",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Diagnose managed tools: whether each binary in .rig/bin exists, is
+executable, and matches the sha256 in rig.lock, plus an advisory version probe.
+Without a name, files in .rig/bin that no tool owns are reported as extra.
+
+Each problem comes with the exact command that repairs it (rig sync, chmod +x,
+or rig tools prune --yes). --json prints the reports for CI to attach when a
+preflight fails.`,
+	Example: `
+  rig tools doctor
+  rig tools doctor golangci-lint
+  rig tools doctor --json | jq -r '.[] | select(.remedy) | .remedy'
+`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeToolNames,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := ""
 		if len(args) == 1 {
@@ -84,6 +188,14 @@ var toolsDoctorCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if doctorJSON {
+			b, err := stdjson.MarshalIndent(reports, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
 		for _, r := range reports {
 			fmt.Printf("name: %s\n", r.Name)
 			fmt.Printf("path: %s\n", r.Path)
@@ -98,6 +210,16 @@ var toolsDoctorCmd = &cobra.Command{
 			if strings.TrimSpace(r.Error) != "" {
 				fmt.Printf("error: %s\n", r.Error)
 			}
+			fmt.Printf("version_probe: %s\n", r.Probe.Status)
+			if r.Probe.Version != "" {
+				fmt.Printf("version_reported: %s\n", r.Probe.Version)
+			}
+			if r.Probe.Error != "" {
+				fmt.Printf("version_probe_error: %s\n", r.Probe.Error)
+			}
+			if r.Remedy != "" {
+				fmt.Printf("remedy: %s\n", r.Remedy)
+			}
 		}
 		return nil
 	},
@@ -116,7 +238,22 @@ var toolsCheckCmd = &cobra.Command{
 	Use:     "check",
 	Aliases: []string{"status"},
 	Short:   "Verify tools are in sync without installing",
+	Long: `Verify tools are in sync without installing.
+
+--format table (the default) prints one aligned row per tool with the version
+rig.toml wants, the version rig.lock pins, and the installed version, colored by
+status. --format json prints a stable summary (same as --json), and --format gha
+prints GitHub Actions ::error/::warning annotations for each problem.`,
+	Example: `
+  rig tools check
+  rig tools check --format json | jq .summary
+  rig tools check --format gha
+`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := parseCheckFormat(toolsFormat, toolsCheckJSON)
+		if err != nil {
+			return err
+		}
 		conf, path, err := loadConfigOrFail()
 		if err != nil {
 			return err
@@ -131,7 +268,7 @@ var toolsCheckCmd = &cobra.Command{
 			tools = mergeTools(tools, extra)
 		}
 		if len(tools) == 0 {
-			if toolsCheckJSON {
+			if format == formatJSON {
 				// Emit an empty diff JSON for CI (mirrors sync --check --json)
 				payload := struct {
 					Status  []core.ToolStatusRow `json:"status"`
@@ -149,10 +286,12 @@ var toolsCheckCmd = &cobra.Command{
 				fmt.Println(string(b))
 				return nil
 			}
-			fmt.Printf("ℹ️  No [tools] specified in %s or provided via .txt\n", path)
+			if format == formatTable {
+				statusf(os.Stdout, "ℹ️  No [tools] specified in %s or provided via .txt", path)
+			}
 			return nil
 		}
-		return checkToolsSync(tools, path)
+		return checkToolsSync(tools, path, format)
 	},
 }
 
@@ -166,13 +305,35 @@ var toolsSyncCmd = &cobra.Command{
 	rig tools sync
 	rig tools sync --check
 	rig tools sync --check --json | jq .
+	rig tools sync --json | jq '.tools | sort_by(-.total_ms)'
+	rig tools sync --dry-run
+	rig tools sync --prune --yes
+	rig tools sync --from-lock --offline
+	rig tools sync --go=pin
 	rig tools sync tools.txt
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Validate flag combinations early for better UX
-		if toolsCheckJSON && !toolsCheck {
-			return fmt.Errorf("--json is only valid with --check")
+		if toolsDryRun && toolsCheck {
+			return fmt.Errorf("--dry-run and --check are mutually exclusive")
 		}
+		if toolsPrune && toolsCheck {
+			return fmt.Errorf("--prune cannot be used with --check")
+		}
+		if toolsFromLock && (toolsCheck || len(args) > 0) {
+			return fmt.Errorf("--from-lock cannot be combined with --check or tool files")
+		}
+		if toolsGo != "" {
+			if toolsCheck || toolsDryRun || toolsFromLock {
+				return fmt.Errorf("--go cannot be combined with --check, --dry-run, or --from-lock")
+			}
+			if err := repairGoToolchain(toolsGo); err != nil {
+				return err
+			}
+		}
+		// Sync refetches remote includes and re-pins them; --check and
+		// --from-lock keep the pins in rig.lock.
+		cfg.RefreshRemoteIncludes = !toolsCheck && !toolsFromLock
 		conf, path, err := loadConfigOrFail()
 		if err != nil {
 			return err
@@ -186,6 +347,9 @@ var toolsSyncCmd = &cobra.Command{
 
 		// Merge conf.Tools and extraTools
 		tools := mergeTools(conf.Tools, extraTools)
+		if drifts, err := core.GoModToolDrifts(tools, path); err == nil {
+			printGoModToolDrifts(newStyledWriter(os.Stderr), drifts)
+		}
 		goReqRaw := tools["go"]
 		toolsNoGo := stripGoToolchain(tools)
 
@@ -208,114 +372,104 @@ var toolsSyncCmd = &cobra.Command{
 				fmt.Println(string(b))
 				return nil
 			}
-			fmt.Printf("ℹ️  No [tools] specified in %s or provided via .txt\n", path)
+			if toolsCheckJSON && !toolsDryRun {
+				return printSyncTimings(nil, core.SyncTimingReport{Tools: []core.ToolSyncTiming{}}, true)
+			}
+			statusf(os.Stdout, "ℹ️  No [tools] specified in %s or provided via .txt", path)
 			return nil
 		}
 
 		if toolsCheck {
-			return checkToolsSync(tools, path)
+			format := formatTable
+			if toolsCheckJSON {
+				format = formatJSON
+			}
+			return checkToolsSync(tools, path, format)
 		}
 
-		fmt.Printf("🔧 Syncing tools from %s\n", path)
+		env := envWithLocalBin(path, append(core.GoCacheEnv(conf, path), toolsOfflineEnv(toolsOffline)...), true)
+		// With --json, progress goes to stderr so stdout is just the timings.
+		stdout := newStyledWriter(os.Stdout)
+		if toolsCheckJSON && !toolsDryRun {
+			stdout = newStyledWriter(os.Stderr)
+		}
+		timings := core.NewSyncTimings()
+		printGoEnvConflicts(newStyledWriter(os.Stderr), core.GoInstallEnvConflicts(localBinDirFor(path), os.Getenv))
 
-		// Validate Go toolchain requirement (tools.go) if present.
 		var toolchain *core.ToolchainLock
-		if strings.TrimSpace(goReqRaw) != "" {
-			normReq, err := core.NormalizeGoToolchainRequested(goReqRaw)
+		var lockedTools []core.LockedTool
+		if toolsFromLock {
+			if !toolsDryRun {
+				stdout.linef(ansiBoldCyan, "🔧 Syncing tools from %s", rigLockPathFor(path))
+			}
+			lockedTools, toolchain, err = lockedToolsFromLock(path)
 			if err != nil {
 				return err
 			}
-			detected, err := core.DetectGoToolchainVersion(filepath.Dir(path), nil)
+		} else {
+			if !toolsDryRun {
+				stdout.linef(ansiBoldCyan, "🔧 Syncing tools from %s", path)
+			}
+			lockedTools, toolchain, err = resolveToolsForSync(path, goReqRaw, toolsNoGo, env, timings)
 			if err != nil {
 				return err
 			}
-			if normReq != "latest" && strings.TrimSpace(detected) != strings.TrimSpace(normReq) {
-				return fmt.Errorf("go toolchain mismatch: have %q, want %q", detected, normReq)
-			}
-			toolchain = &core.ToolchainLock{Go: &core.GoToolchainLock{Kind: "go-toolchain", Requested: normReq, Detected: detected}}
 		}
 
-		// Ensure local bin dir exists and prepare env with GOBIN and PATH
-		binDir := localBinDirFor(path)
-		if err := os.MkdirAll(binDir, 0o755); err != nil {
-			return fmt.Errorf("create local bin dir: %w", err)
+		// Sums already in rig.lock vouch for modules outside the checksum database.
+		known := map[string]string{}
+		prevLock, _ := core.ReadLockfile(rigLockPathFor(path))
+		for _, lt := range prevLock.Tools {
+			known[lt.Resolved] = lt.Checksum
 		}
-		env := envWithLocalBin(path, toolsOfflineEnv(toolsOffline), true)
-
-		// Resolve tools into a deterministic rig.lock representation.
-		// This enables offline installs/checks and ensures sync is reproducible.
-		lockedTools, err := core.ResolveLockedTools(toolsNoGo, filepath.Dir(path), env)
-		if err != nil {
+		if toolsFromLock {
+			// The pins are installed as locked, even when rig.toml moved on.
+			if err := core.LockMatchesManifest(prevLock, conf); err != nil {
+				newStyledWriter(os.Stderr).linef(ansiYellow, "⚠️  %v", err)
+			}
+		}
+		if err := core.VerifyToolSumsTimed(lockedTools, known, filepath.Dir(path), env, toolsInsecure, timings); err != nil {
 			return err
 		}
 
-		// Concurrent installs with deterministic reporting
-		sort.Slice(lockedTools, func(i, j int) bool {
-			return lockedTools[i].Requested < lockedTools[j].Requested
-		})
-
-		type result struct {
-			name, bin, ver string
-			err            error
-		}
-		results := make([]result, len(lockedTools))
-		// Concurrency: up to NumCPU, but no more than the number of tools
-		conc := max(1, min(len(lockedTools), runtime.NumCPU()))
-		sem := make(chan struct{}, conc)
-		var wg sync.WaitGroup
-		for i, lt := range lockedTools {
-			i, lt := i, lt
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				sem <- struct{}{}
-				defer func() { <-sem }()
-				toolName, _, perr := core.ParseRequested(lt.Requested)
-				if perr != nil {
-					results[i] = result{name: lt.Requested, err: perr}
-					return
-				}
-				_, resolvedVer := core.SplitResolved(lt.Resolved)
-				id := core.ResolveToolIdentity(toolName)
-				installWithVer := id.InstallPath + "@" + resolvedVer
-				err := execCommandSilentEnv("go", []string{"install", installWithVer}, env)
-				bin := lt.Bin
-				if strings.TrimSpace(bin) == "" {
-					bin = id.Bin
-				}
-				results[i] = result{name: lt.Requested, bin: bin, ver: resolvedVer, err: err}
-			}()
-		}
-		wg.Wait()
-		for _, r := range results {
-			if r.err != nil {
-				return fmt.Errorf("install %s: %w", r.name, r.err)
+		if toolsDryRun {
+			var meta *core.LockMeta // --from-lock keeps rig.lock's provenance
+			if !toolsFromLock {
+				meta = core.NewLockMeta(version, conf)
 			}
-			fmt.Printf("✅ %s %s installed\n", r.bin, r.ver)
-		}
-
-		// Compute and record binary integrity after successful installs.
-		for i := range lockedTools {
-			lt := lockedTools[i]
-			toolName, _, perr := core.ParseRequested(lt.Requested)
-			if perr != nil {
-				return perr
+			plan, err := core.PlanSync(path, lockedTools, toolchain, meta)
+			if err != nil {
+				return err
 			}
-			bin := strings.TrimSpace(lt.Bin)
-			if bin == "" {
-				bin = core.ResolveToolIdentity(toolName).Bin
+			plan.Includes = core.PlanIncludes(prevLock.Includes, conf.RemoteIncludes)
+			if len(plan.Includes) > 0 {
+				plan.LockChanged = true
 			}
-			binPath := core.ToolBinPath(path, bin)
-			sum, herr := core.ComputeFileSHA256(binPath)
-			if herr != nil {
-				return fmt.Errorf("compute sha256 for %s: %w", bin, herr)
+			if toolsPrune {
+				plan.Prune, err = core.BinExtras(path, core.Lockfile{Tools: lockedTools})
+				if err != nil {
+					return err
+				}
 			}
-			lockedTools[i].SHA256 = sum
+			return printSyncPlan(plan, toolsCheckJSON)
+		}
+
+		release, err := lockProject(path, "syncing")
+		if err != nil {
+			return err
+		}
+		defer release()
+		if err := installLockedTools(path, lockedTools, env, stdout, timings); err != nil {
+			return err
 		}
 
 		// Only write lock files after successful installs.
 		// This prevents partial or misleading lockfile updates.
-		rigLock := core.Lockfile{Schema: core.LockSchema0, Toolchain: toolchain, Tools: lockedTools}
+		rigLock := core.Lockfile{Schema: core.LockSchema0, Meta: core.NewLockMeta(version, conf), Toolchain: toolchain, Includes: conf.RemoteIncludes, Tools: lockedTools}
+		if toolsFromLock {
+			// The pins came from rig.lock, not rig.toml: keep its provenance.
+			rigLock.Meta = prevLock.Meta
+		}
 		rigLockPath := rigLockPathFor(path)
 		if err := core.WriteLockfile(rigLockPath, rigLock); err != nil {
 			return fmt.Errorf("write rig.lock: %w", err)
@@ -328,20 +482,351 @@ var toolsSyncCmd = &cobra.Command{
 			return fmt.Errorf("write manifest lock: %w", err)
 		}
 
-		fmt.Printf("🔒 Tools synced (rig.lock: %s, manifest: %s)\n", rigLockPath, manifestPath)
+		stdout.linef(ansiGreen, "🔒 Tools synced (rig.lock: %s, manifest: %s)", rigLockPath, manifestPath)
+		if err := printSyncTimings(stdout, timings.Report(), toolsCheckJSON); err != nil {
+			return err
+		}
+		if strings.TrimSpace(conf.Lock.Signature) != "" {
+			if err := core.VerifyLockSignature(path, conf.Lock); err != nil {
+				stdout.linef(ansiYellow, "🔏 rig.lock signature no longer verifies; re-sign with 'rig lock sign'")
+			}
+		}
+		if toolsPrune {
+			return pruneToolBins(path, rigLock, toolsYes)
+		}
 		return nil
 	},
 }
 
+// printSyncTimings shows how long each tool spent resolving, downloading,
+// compiling, and hashing, so the tools that dominate setup time stand out.
+func printSyncTimings(out *styledWriter, rep core.SyncTimingReport, asJSON bool) error {
+	if asJSON {
+		b, err := stdjson.MarshalIndent(rep, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	if len(rep.Tools) == 0 {
+		return nil
+	}
+	width := len("tool")
+	for _, t := range rep.Tools {
+		width = max(width, len(t.Tool))
+	}
+	out.linef(ansiBoldCyan, "⏱️  Sync took %s", planDuration(rep.TotalMS))
+	out.linef(ansiDim, "  %-*s  %8s  %8s  %8s  %8s  %8s", width, "tool", "resolve", "download", "compile", "hash", "total")
+	for _, t := range rep.Tools {
+		fmt.Fprintf(out.w, "  %-*s  %8s  %8s  %8s  %8s  %8s\n", width, t.Tool,
+			syncPhaseDuration(t.ResolveMS), syncPhaseDuration(t.DownloadMS), syncPhaseDuration(t.CompileMS), syncPhaseDuration(t.HashMS), planDuration(t.TotalMS))
+	}
+	return nil
+}
+
+// syncPhaseDuration shows a phase the sync skipped (e.g. resolution under
+// --from-lock) as "-".
+func syncPhaseDuration(ms int64) string {
+	if ms == 0 {
+		return "-"
+	}
+	return planDuration(ms)
+}
+
+// printGoEnvConflicts explains which of the user's Go environment variables
+// rig overrides for tool installs.
+func printGoEnvConflicts(out *styledWriter, conflicts []core.GoEnvConflict) {
+	for _, c := range conflicts {
+		out.linef(ansiYellow, "⚠️  %s", c)
+	}
+}
+
+// installLockedTools runs `go install` for each locked tool into .rig/bin and
+// records the resulting binary checksum and builder Go version in place.
+// Compile and hash times go to timings, which may be nil.
+func installLockedTools(configPath string, lockedTools []core.LockedTool, env []string, out *styledWriter, timings *core.SyncTimings) error {
+	// Ensure local bin dir exists (GOBIN for go install)
+	binDir := localBinDirFor(configPath)
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return fmt.Errorf("create local bin dir: %w", err)
+	}
+
+	// Concurrent installs with deterministic reporting
+	sort.Slice(lockedTools, func(i, j int) bool {
+		return lockedTools[i].Requested < lockedTools[j].Requested
+	})
+
+	type result struct {
+		name, bin, ver string
+		err            error
+	}
+	results := make([]result, len(lockedTools))
+	// Concurrency: up to NumCPU, but no more than the number of tools
+	conc := max(1, min(len(lockedTools), runtime.NumCPU()))
+	sem := make(chan struct{}, conc)
+	var wg sync.WaitGroup
+	for i, lt := range lockedTools {
+		i, lt := i, lt
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			toolName, _, perr := core.ParseRequested(lt.Requested)
+			if perr != nil {
+				results[i] = result{name: lt.Requested, err: perr}
+				return
+			}
+			_, resolvedVer := core.SplitResolved(lt.Resolved)
+			id := core.ResolveToolIdentity(toolName)
+			bin := lt.Bin
+			if strings.TrimSpace(bin) == "" {
+				bin = id.Bin
+			}
+			if core.IsOCITool(lt) {
+				_, tag, _ := core.ParseRequested(lt.Requested)
+				done := timings.Start(toolName, core.SyncDownload)
+				err := core.InstallOCITool(configPath, lt)
+				done()
+				results[i] = result{name: lt.Requested, bin: bin, ver: tag, err: err}
+				return
+			}
+			installWithVer := id.InstallPath + "@" + resolvedVer
+			done := timings.Start(toolName, core.SyncCompile)
+			err := execCommandSilentEnv("go", []string{"install", installWithVer}, env)
+			done()
+			results[i] = result{name: lt.Requested, bin: bin, ver: resolvedVer, err: err}
+		}()
+	}
+	wg.Wait()
+	for _, r := range results {
+		if r.err != nil {
+			return fmt.Errorf("install %s: %w", r.name, r.err)
+		}
+		out.linef(ansiGreen, "✅ %s %s installed", r.bin, r.ver)
+	}
+
+	// Compute and record binary integrity after successful installs.
+	for i := range lockedTools {
+		lt := lockedTools[i]
+		toolName, _, perr := core.ParseRequested(lt.Requested)
+		if perr != nil {
+			return perr
+		}
+		bin := strings.TrimSpace(lt.Bin)
+		if bin == "" {
+			bin = core.ResolveToolIdentity(toolName).Bin
+		}
+		binPath := core.ToolBinPath(configPath, bin)
+		done := timings.Start(toolName, core.SyncHash)
+		sum, herr := core.ComputeFileSHA256(binPath)
+		if herr != nil {
+			return fmt.Errorf("compute sha256 for %s: %w", bin, herr)
+		}
+		lockedTools[i].SHA256 = sum
+		// Record the builder so check can flag tools left behind by a toolchain bump.
+		if gv, gerr := core.ToolBuildGoVersion(binPath); gerr == nil {
+			lockedTools[i].Go = gv
+		}
+		done()
+	}
+	return nil
+}
+
+// resolveToolsForSync validates the Go toolchain requirement (tools.go) if
+// present and resolves [tools] into a deterministic rig.lock representation.
+// This enables offline installs/checks and ensures sync is reproducible.
+func resolveToolsForSync(configPath, goReqRaw string, tools map[string]string, env []string, timings *core.SyncTimings) ([]core.LockedTool, *core.ToolchainLock, error) {
+	var toolchain *core.ToolchainLock
+	if strings.TrimSpace(goReqRaw) != "" {
+		normReq, err := core.NormalizeGoToolchainRequested(goReqRaw)
+		if err != nil {
+			return nil, nil, err
+		}
+		detected, err := core.DetectGoToolchainVersion(filepath.Dir(configPath), nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if normReq != "latest" && strings.TrimSpace(detected) != strings.TrimSpace(normReq) {
+			return nil, nil, fmt.Errorf("go toolchain mismatch: have %q, want %q (run 'rig sync --go' to fix)", detected, normReq)
+		}
+		toolchain = &core.ToolchainLock{Go: &core.GoToolchainLock{Kind: "go-toolchain", Requested: normReq, Detected: detected}}
+	}
+	lockedTools, err := core.ResolveLockedToolsTimed(tools, filepath.Dir(configPath), env, timings)
+	if err != nil {
+		return nil, nil, err
+	}
+	return lockedTools, toolchain, nil
+}
+
+// lockedToolsFromLock returns the tools recorded in rig.lock for `sync
+// --from-lock`. Nothing is re-resolved; binary checksums and builders are
+// recomputed after install.
+func lockedToolsFromLock(configPath string) ([]core.LockedTool, *core.ToolchainLock, error) {
+	lockPath := rigLockPathFor(configPath)
+	lock, err := core.ReadLockfile(lockPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rig.lock missing or unreadable (%s); --from-lock needs an existing lock: %w", lockPath, err)
+	}
+	if lock.Toolchain != nil && lock.Toolchain.Go != nil {
+		detected, err := core.DetectGoToolchainVersion(filepath.Dir(configPath), nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if want := strings.TrimSpace(lock.Toolchain.Go.Detected); strings.TrimSpace(detected) != want {
+			return nil, nil, fmt.Errorf("go toolchain mismatch: have %q, rig.lock has %q", detected, want)
+		}
+	}
+	tools := make([]core.LockedTool, len(lock.Tools))
+	for i, lt := range lock.Tools {
+		lt.SHA256, lt.Go = "", ""
+		tools[i] = lt
+	}
+	return tools, lock.Toolchain, nil
+}
+
+// toolsSearchCmd suggests [tools] keys for a tool name.
+var toolsSearchCmd = &cobra.Command{
+	Use:   "search <name>",
+	Short: "Suggest module paths for a tool name",
+	Long:  "Search [tool-aliases], built-in short names, and the pkg.go.dev package index for installable paths matching <name>.",
+	Args:  cobra.ExactArgs(1),
+	Example: `
+	rig tools search reflex
+	rig tools search sqlc --json
+	rig tools search lint --offline
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, _, err := loadConfigOptional(); err != nil && !errors.Is(err, cfg.ErrConfigNotFound) {
+			return err
+		}
+		results, serr := core.SearchTools(args[0], core.ToolSearchOptions{Offline: toolsOffline, Limit: searchLimit})
+		if searchJSON {
+			if results == nil {
+				results = []core.ToolSearchResult{}
+			}
+			b, err := stdjson.MarshalIndent(results, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return serr
+		}
+		if serr != nil {
+			statusf(os.Stderr, "⚠️  package search failed: %v", serr)
+		}
+		if len(results) == 0 {
+			if serr != nil {
+				return serr
+			}
+			return fmt.Errorf("no tools found for %q", args[0])
+		}
+		for _, r := range results {
+			key := r.InstallPath
+			if r.Source != core.SearchSourceIndex {
+				key = r.Name
+			}
+			fmt.Printf("  %-30s %s (%s)\n", key, r.InstallPath, r.Source)
+		}
+		first := results[0]
+		if first.Source != core.SearchSourceIndex {
+			fmt.Printf("\nAdd to rig.toml:\n  [tools]\n  %s = \"latest\"\n", first.Name)
+			return nil
+		}
+		// Package paths below the module root need an alias with module/install.
+		fmt.Printf("\nAdd to rig.toml (or map a short name in [tool-aliases] when the package is not the module root):\n  [tools]\n  %s = \"latest\"\n", strconv.Quote(first.InstallPath))
+		return nil
+	},
+}
+
+// toolsPruneCmd removes binaries from .rig/bin that are not recorded in rig.lock.
+var toolsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove binaries from .rig/bin that are not in rig.lock",
+	Args:  cobra.NoArgs,
+	Example: `
+	rig tools prune --dry-run
+	rig tools prune --yes
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, path, err := loadConfigOrFail()
+		if err != nil {
+			return err
+		}
+		lockPath := rigLockPathFor(path)
+		lock, err := core.ReadLockfile(lockPath)
+		if err != nil {
+			return fmt.Errorf("rig.lock missing or unreadable (%s); run 'rig tools sync' to generate it", lockPath)
+		}
+		if toolsDryRun {
+			extras, err := core.BinExtras(path, lock)
+			if err != nil {
+				return err
+			}
+			if len(extras) == 0 {
+				statusf(os.Stdout, "✅ .rig/bin matches rig.lock; nothing to prune")
+				return nil
+			}
+			for _, name := range extras {
+				fmt.Printf("  would remove %s\n", name)
+			}
+			return nil
+		}
+		return pruneToolBins(path, lock, toolsYes)
+	},
+}
+
+// pruneToolBins deletes .rig/bin entries not in lock. Without yes it asks for
+// confirmation on a terminal and refuses otherwise.
+func pruneToolBins(configPath string, lock core.Lockfile, yes bool) error {
+	extras, err := core.BinExtras(configPath, lock)
+	if err != nil {
+		return err
+	}
+	if len(extras) == 0 {
+		statusf(os.Stdout, "✅ .rig/bin matches rig.lock; nothing to prune")
+		return nil
+	}
+	statusf(os.Stdout, "🧹 Not in rig.lock: %s", strings.Join(extras, ", "))
+	if !yes {
+		if !isTTY(os.Stdin) {
+			return fmt.Errorf("refusing to prune %d file(s) without confirmation; pass --yes", len(extras))
+		}
+		if !confirmPrompt(os.Stdout, fmt.Sprintf("Remove %d file(s) from .rig/bin?", len(extras))) {
+			fmt.Println("Prune cancelled")
+			return nil
+		}
+	}
+	if err := core.PruneBins(configPath, extras); err != nil {
+		return err
+	}
+	statusf(os.Stdout, "🧹 Pruned %d file(s) from .rig/bin", len(extras))
+	return nil
+}
+
+// confirmPrompt asks a yes/no question on w and reads the answer from stdin.
+func confirmPrompt(w io.Writer, question string) bool {
+	fmt.Fprintf(w, "%s [y/N] ", question)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
 // toolsOutdatedCmd reports tools that are missing or have a version mismatch without making changes.
 var toolsOutdatedCmd = &cobra.Command{
 	Use:     "outdated",
 	Short:   "Show missing or mismatched tools",
-	Long:    "Checks installed tools in .rig/bin against rig.toml versions and lists any that are missing or mismatched. Shortcut: 'rig outdated'.",
+	Long:    "Checks installed tools in .rig/bin against rig.toml versions and lists any that are missing or mismatched. With --releases it also reports when a newer Go patch release exists for the project's Go minor (or the minor is no longer supported), and when a newer rig is released; this contacts go.dev and GitHub. Only tool problems fail the command. Shortcut: 'rig outdated'.",
 	Aliases: []string{"o"},
 	Example: `
 	rig tools outdated
 	rig tools outdated --json | jq .
+	rig tools outdated --releases --json | jq '.items[] | select(.severity != "none")'
+	rig tools outdated --releases --offline
 	rig tools outdated tools.txt
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -355,19 +840,27 @@ var toolsOutdatedCmd = &cobra.Command{
 			return err
 		}
 		tools := mergeTools(conf.Tools, extraTools)
-		if len(tools) == 0 {
-			if outdatedJSON {
-				fmt.Println("[]")
-				return nil
-			}
-			fmt.Printf("ℹ️  No [tools] specified in %s or provided via .txt\n", path)
-			return nil
+		var rows []core.ToolStatusRow
+		if len(stripGoToolchain(tools)) > 0 {
+			rows, _, _ = collectToolStatus(tools, path)
+		}
+		rep := core.OutdatedReport{Items: outdatedToolItems(rows)}
+		if outdatedReleases {
+			rep = outdatedReport(tools, path, rows, toolsOffline)
 		}
+		issues := outdatedToolIssues(rep.Items)
 
 		if outdatedJSON {
-			rows, missing, mismatched := collectToolStatus(tools, path)
-			issues := missing + mismatched
-			b, err := stdjson.MarshalIndent(rows, "", "  ")
+			// Without --releases the JSON stays the tool status array it has
+			// always been; the {"items": [...]} report is opt-in.
+			var doc any = rows
+			if rows == nil {
+				doc = []core.ToolStatusRow{}
+			}
+			if outdatedReleases {
+				doc = rep
+			}
+			b, err := stdjson.MarshalIndent(doc, "", "  ")
 			if err != nil {
 				return err
 			}
@@ -379,33 +872,52 @@ var toolsOutdatedCmd = &cobra.Command{
 		}
 
 		// Human output branch
-		fmt.Printf("🔍 Checking tools status in %s:\n", path)
-		rows, missing, mismatched := collectToolStatus(tools, path)
-		issues := missing + mismatched
-		for _, r := range rows {
-			switch r.Status {
-			case "missing":
-				fmt.Printf("  ❌ %s not found (want %s)\n", r.Bin, r.Want)
-			case "mismatch":
-				fmt.Printf("  ❌ %s version mismatch (have %s, want %s)\n", r.Bin, r.Have, r.Want)
-			default:
-				fmt.Printf("  ✅ %s %s\n", r.Bin, r.Want)
-			}
+		stdout := newStyledWriter(os.Stdout)
+		if len(rows) == 0 {
+			statusf(os.Stdout, "ℹ️  No [tools] specified in %s or provided via .txt", path)
+		} else {
+			stdout.linef(ansiBoldCyan, "🔍 Checking tools status in %s:", path)
+			printToolTable(stdout, toolTableRows(rows))
 		}
+		printOutdatedReleases(stdout, rep.Items)
 		if issues > 0 {
 			return fmt.Errorf("%d tool(s) need update. Run 'rig tools sync'", issues)
 		}
-		fmt.Println("✅ All tools up to date")
+		if len(rows) > 0 {
+			stdout.linef(ansiGreen, "✅ All tools up to date")
+		}
 		return nil
 	},
 }
 
 func init() {
 	toolsSyncCmd.Flags().BoolVar(&toolsCheck, "check", false, "verify tools are in sync without installing")
-	toolsSyncCmd.Flags().BoolVar(&toolsCheckJSON, "json", false, "use with --check to print machine-readable JSON summary")
+	toolsSyncCmd.Flags().BoolVar(&projectLockWait, "wait", false, "wait for another rig process that is syncing this project instead of failing")
+	toolsSyncCmd.Flags().BoolVar(&toolsCheckJSON, "json", false, "print machine-readable JSON (the diff with --check, the plan with --dry-run, per-tool timings otherwise)")
 	toolsSyncCmd.Flags().BoolVar(&toolsOffline, "offline", false, "do not download modules (sets GOPROXY=off, GOSUMDB=off)")
-	toolsCheckCmd.Flags().BoolVar(&toolsCheckJSON, "json", false, "print machine-readable JSON summary")
+	toolsSyncCmd.Flags().BoolVar(&toolsDryRun, "dry-run", false, "print what sync would install/upgrade/rebuild and how rig.lock would change, without writing")
+	toolsSyncCmd.Flags().BoolVar(&toolsPrune, "prune", false, "remove binaries from .rig/bin that are not in rig.lock after syncing")
+	toolsSyncCmd.Flags().BoolVarP(&toolsYes, "yes", "y", false, "do not ask for confirmation before pruning")
+	toolsSyncCmd.Flags().BoolVar(&toolsFromLock, "from-lock", false, "install exactly the versions in rig.lock without re-resolving rig.toml")
+	toolsSyncCmd.Flags().BoolVar(&toolsInsecure, "insecure", false, "accept tool modules whose checksums cannot be verified against the checksum database")
+	toolsSyncCmd.Flags().StringVar(&toolsGo, "go", "", "repair a Go toolchain mismatch first: ask|pin (use the installed go)|install (download tools.go's version)")
+	toolsSyncCmd.Flags().Lookup("go").NoOptDefVal = goRepairAsk
+	toolsSearchCmd.Flags().BoolVar(&searchJSON, "json", false, "print machine-readable JSON results")
+	toolsLsCmd.Flags().BoolVar(&lsJSON, "json", false, "print machine-readable JSON")
+	toolsDoctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "print the reports, with a remedy command per problem, as JSON")
+	toolsLsCmd.Flags().StringVar(&lsStatus, "status", "", "only list tools with these statuses (comma-separated: ok|missing|mismatch|stale)")
+	addPorcelainFlag(toolsLsCmd, &lsPorcelain)
+	_ = toolsLsCmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions([]string{"ok", "missing", "mismatch", "stale"}, cobra.ShellCompDirectiveNoFileComp))
+	toolsSearchCmd.Flags().BoolVar(&toolsOffline, "offline", false, "only search [tool-aliases] and built-in short names")
+	toolsSearchCmd.Flags().IntVar(&searchLimit, "limit", 10, "maximum number of pkg.go.dev results")
+	toolsPruneCmd.Flags().BoolVar(&toolsDryRun, "dry-run", false, "list files that would be removed without deleting them")
+	toolsPruneCmd.Flags().BoolVarP(&toolsYes, "yes", "y", false, "do not ask for confirmation")
+	toolsCheckCmd.Flags().BoolVar(&toolsCheckJSON, "json", false, "print machine-readable JSON summary (same as --format json)")
+	toolsCheckCmd.Flags().StringVar(&toolsFormat, "format", formatTable, "output format: table, json, or gha (GitHub Actions annotations)")
+	_ = toolsCheckCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{formatTable, formatJSON, formatGHA}, cobra.ShellCompDirectiveNoFileComp))
 	toolsOutdatedCmd.Flags().BoolVar(&outdatedJSON, "json", false, "print machine-readable JSON status")
+	toolsOutdatedCmd.Flags().BoolVar(&outdatedReleases, "releases", false, "also check for Go patch releases and rig updates (contacts go.dev and GitHub; --json prints {\"items\": [...]})")
+	toolsOutdatedCmd.Flags().BoolVar(&toolsOffline, "offline", false, "with --releases, use cached Go and rig release data instead of fetching it")
 	toolsSetupCmd.Flags().BoolVar(&setupCheck, "check", false, "verify installed tool versions against rig.toml (no install)")
 
 	toolsCmd.AddCommand(toolsSyncCmd)
@@ -416,15 +928,99 @@ func init() {
 	toolsCmd.AddCommand(toolsPathCmd)
 	toolsCmd.AddCommand(toolsWhyCmd)
 	toolsCmd.AddCommand(toolsDoctorCmd)
+	toolsCmd.AddCommand(toolsPruneCmd)
+	toolsCmd.AddCommand(toolsSearchCmd)
 	rootCmd.AddCommand(toolsCmd)
 }
 
-// checkToolsSync verifies rig.lock is consistent with rig.toml, then checks installed binaries.
-func checkToolsSync(tools map[string]string, configPath string) error {
+// printSyncPlan renders a dry-run plan for `rig tools sync --dry-run`.
+func printSyncPlan(plan core.SyncPlan, asJSON bool) error {
+	if asJSON {
+		b, err := stdjson.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	statusf(os.Stdout, "🔍 Dry run: no files will be changed")
+	width := 0
+	for _, a := range plan.Actions {
+		width = max(width, len(a.Name))
+	}
+	for _, a := range plan.Actions {
+		var detail string
+		switch a.Action {
+		case core.SyncUpgrade:
+			detail = a.From + " -> " + a.To
+		case core.SyncRemove:
+			detail = a.From
+		default:
+			detail = a.To
+		}
+		if a.Reason != "" {
+			detail += " (" + a.Reason + ")"
+		}
+		fmt.Printf("  %-7s  %-*s  %s\n", a.Action, width, a.Name, detail)
+	}
+	for _, a := range plan.Includes {
+		detail := a.To
+		if a.From != "" {
+			detail = a.From + " -> " + a.To
+		}
+		if a.Action == core.SyncUnpin {
+			detail = a.From
+		}
+		fmt.Printf("  %-7s  %s  %s (%s)\n", a.Action, a.Name, detail, a.Reason)
+	}
+	for _, name := range plan.Prune {
+		fmt.Printf("  %-7s  %-*s  (not in rig.lock)\n", "prune", width, name)
+	}
+	if plan.ToolchainFrom != plan.ToolchainTo {
+		fmt.Printf("  go toolchain: %q -> %q\n", plan.ToolchainFrom, plan.ToolchainTo)
+	}
+	if plan.MetaTo != nil {
+		fmt.Printf("  [meta]: %s\n", lockMetaDiff(plan.MetaFrom, plan.MetaTo))
+	}
+	switch {
+	case plan.LockCreated:
+		fmt.Printf("rig.lock: would be created at %s\n", plan.LockPath)
+	case plan.LockChanged:
+		fmt.Printf("rig.lock: would change (%d tool change(s))\n", plan.Changes())
+	default:
+		fmt.Println("rig.lock: unchanged")
+	}
+	return nil
+}
+
+// lockMetaDiff describes the [meta] fields that change between from and to.
+func lockMetaDiff(from, to *core.LockMeta) string {
+	if from == nil {
+		from = &core.LockMeta{}
+	}
+	var parts []string
+	if from.RigVersion != to.RigVersion {
+		parts = append(parts, fmt.Sprintf("rig_version %q -> %q", from.RigVersion, to.RigVersion))
+	}
+	if from.ManifestSHA256 != to.ManifestSHA256 {
+		short := func(sum string) string { return sum[:min(len(sum), 12)] }
+		parts = append(parts, fmt.Sprintf("manifest_sha256 %q -> %q", short(from.ManifestSHA256), short(to.ManifestSHA256)))
+	}
+	if from.Generated != to.Generated {
+		parts = append(parts, fmt.Sprintf("generated %q -> %q", from.Generated, to.Generated))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// checkToolsSync verifies rig.lock is consistent with rig.toml, then checks
+// installed binaries, reporting in format (table, json, or gha).
+func checkToolsSync(tools map[string]string, configPath, format string) error {
+	const ghaTitle = "rig tools check"
 	lockPath := rigLockPathFor(configPath)
 	lock, err := core.ReadLockfile(lockPath)
 	if err != nil {
-		if toolsCheckJSON {
+		switch format {
+		case formatJSON:
 			payload := struct {
 				Status  []core.ToolStatusRow `json:"status"`
 				Summary struct {
@@ -441,11 +1037,14 @@ func checkToolsSync(tools map[string]string, configPath string) error {
 				return jerr
 			}
 			fmt.Println(string(b))
+		case formatGHA:
+			printGHAnnotations(os.Stdout, ghaTitle, nil, []string{"rig.lock missing or unreadable: " + err.Error()}, nil)
 		}
 		return fmt.Errorf("rig.lock missing or unreadable (%s); run 'rig tools sync' to generate it", lockPath)
 	}
 	if err := core.LockMatchesTools(lock, tools); err != nil {
-		if toolsCheckJSON {
+		switch format {
+		case formatJSON:
 			payload := struct {
 				Status  []core.ToolStatusRow `json:"status"`
 				Summary struct {
@@ -462,20 +1061,28 @@ func checkToolsSync(tools map[string]string, configPath string) error {
 				return jerr
 			}
 			fmt.Println(string(b))
+		case formatGHA:
+			printGHAnnotations(os.Stdout, ghaTitle, nil, []string{"rig.lock out of date: " + err.Error()}, nil)
 		}
 		return fmt.Errorf("rig.lock out of date; run 'rig tools sync' (%w)", err)
 	}
 
-	if !toolsCheckJSON {
-		fmt.Printf("🔍 Checking tools status in %s:\n", configPath)
+	stdout := newStyledWriter(os.Stdout)
+	if format == formatTable {
+		stdout.linef(ansiBoldCyan, "🔍 Checking tools status in %s:", configPath)
 	}
 	rows, missing, mismatched, extras, err := core.CheckInstalledTools(tools, lock, configPath)
 	if err != nil {
 		return err
 	}
+	table := toolTableRows(rows)
 	if goReqRaw := strings.TrimSpace(tools["go"]); goReqRaw != "" {
 		want, nerr := core.NormalizeGoToolchainRequested(goReqRaw)
 		have, herr := core.DetectGoToolchainVersion(filepath.Dir(configPath), nil)
+		locked := ""
+		if lock.Toolchain != nil && lock.Toolchain.Go != nil {
+			locked = strings.TrimSpace(lock.Toolchain.Go.Detected)
+		}
 		status := "ok"
 		if nerr != nil {
 			status = "mismatch"
@@ -485,24 +1092,25 @@ func checkToolsSync(tools map[string]string, configPath string) error {
 			status = "missing"
 			missing++
 			have = ""
-		} else if lock.Toolchain == nil || lock.Toolchain.Go == nil {
+		} else if locked == "" {
 			status = "missing"
 			missing++
-		} else if strings.TrimSpace(lock.Toolchain.Go.Detected) != strings.TrimSpace(have) {
+		} else if locked != strings.TrimSpace(have) {
 			status = "mismatch"
 			mismatched++
 		}
 		rows = append(rows, core.ToolStatusRow{Name: "go", Bin: "go", Want: want, Have: have, Status: status})
+		table = append(table, goTableRow(&core.GoStatusRow{Requested: goReqRaw, Locked: locked, Have: have, Status: status}))
 	}
+	var warnings []string
 	for _, name := range extras {
-		if !toolsCheckJSON {
-			fmt.Printf("  ⚠️  extra binary not in manifest: %s\n", name)
-		}
+		warnings = append(warnings, "extra binary not in manifest: "+name)
 	}
 	extra := len(extras)
 
 	issues := missing + mismatched + extra
-	if toolsCheckJSON {
+	switch format {
+	case formatJSON:
 		payload := struct {
 			Status  []core.ToolStatusRow `json:"status"`
 			Summary struct {
@@ -521,19 +1129,15 @@ func checkToolsSync(tools map[string]string, configPath string) error {
 			return jerr
 		}
 		fmt.Println(string(b))
-	} else {
-		for _, r := range rows {
-			switch r.Status {
-			case "missing":
-				fmt.Printf("  ❌ %s not found (want %s)\n", r.Bin, r.Want)
-			case "mismatch":
-				fmt.Printf("  ❌ %s version mismatch (have %s, want %s)\n", r.Bin, r.Have, r.Want)
-			default:
-				fmt.Printf("  ✅ %s %s\n", r.Bin, r.Want)
-			}
+	case formatGHA:
+		printGHAnnotations(os.Stdout, ghaTitle, table, nil, warnings)
+	default:
+		printToolTable(stdout, table)
+		for _, w := range warnings {
+			stdout.linef(ansiYellow, "  ⚠️  %s", w)
 		}
 		if issues == 0 {
-			fmt.Println("✅ All tools up to date")
+			stdout.linef(ansiGreen, "✅ All tools up to date")
 			return nil
 		}
 		fmt.Printf("\nSummary: %d missing, %d mismatched, %d extra\n", missing, mismatched, extra)
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+// toolsImportCmd copies go.mod tool directives into [tools].
+var toolsImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Add go.mod tool directives to [tools] in rig.toml",
+	Long: `Read the tool directives of the go.mod next to rig.toml (Go 1.24+) and pin
+each tool in [tools] at the version go.mod requires: tools [tools] lacks are
+added and tools pinned at another version are updated. The change is shown as
+a diff and confirmed first; the rest of rig.toml is left as it is.`,
+	Args: cobra.NoArgs,
+	Example: `
+	rig tools import --dry-run
+	rig tools import --yes && rig sync
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, path, err := loadConfigOrFail()
+		if err != nil {
+			return err
+		}
+		drifts, err := core.GoModToolDrifts(conf.Tools, path)
+		if err != nil {
+			return err
+		}
+		if len(drifts) == 0 {
+			statusf(os.Stdout, "✅ [tools] matches the go.mod tool directives; nothing to import")
+			return nil
+		}
+		set := map[string]string{}
+		for _, d := range drifts {
+			if d.GoMod == "" {
+				return fmt.Errorf("go.mod tool %s: no require directive gives its version (run 'go mod tidy')", d.Package)
+			}
+			set[d.Tool] = d.GoMod
+		}
+		old, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		updated := core.SetConfigTools(string(old), set)
+		name := getRelativePath(path)
+		printUnifiedDiff(newStyledWriter(os.Stdout), core.UnifiedDiff(name, name+" (new)", string(old), updated, 3))
+		if toolsDryRun {
+			return nil
+		}
+		if !toolsYes {
+			if !isTTY(os.Stdin) {
+				return fmt.Errorf("refusing to edit %s without confirmation; pass --yes", name)
+			}
+			ok, err := newPrompter(os.Stdin, os.Stdout).Confirm(fmt.Sprintf("import %d tool(s) into %s", len(set), name), false)
+			if err != nil && !errors.Is(err, errPromptAborted) {
+				return err
+			}
+			if !ok {
+				fmt.Println("Import cancelled")
+				return nil
+			}
+		}
+		if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+		newStyledWriter(os.Stdout).linef(ansiGreen, "📥 Imported %d tool(s) from go.mod; run 'rig sync' to install them", len(set))
+		return nil
+	},
+}
+
+func init() {
+	toolsImportCmd.Flags().BoolVar(&toolsDryRun, "dry-run", false, "show the change to rig.toml without writing it")
+	toolsImportCmd.Flags().BoolVarP(&toolsYes, "yes", "y", false, "do not ask for confirmation")
+	toolsCmd.AddCommand(toolsImportCmd)
+}
@@ -4,14 +4,13 @@ package cli
 
 import (
 	"bufio"
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	cfg "github.com/divijg19/rig/internal/config"
 	core "github.com/divijg19/rig/internal/rig"
 )
 
@@ -36,20 +35,7 @@ func manifestLockPath(configPath string) string {
 
 // computeToolsHash creates a hash of the tools configuration for lock file
 func computeToolsHash(tools map[string]string) string {
-	h := sha256.New()
-
-	// Sort keys for consistent hashing
-	var keys []string
-	for k := range tools {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	for _, k := range keys {
-		h.Write([]byte(k + "=" + tools[k] + "\n"))
-	}
-
-	return hex.EncodeToString(h.Sum(nil))
+	return core.ManifestHash(&cfg.Config{Tools: tools})
 }
 
 // parseToolsFiles reads one or more .txt files and returns a map of tool -> version
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
@@ -9,6 +10,7 @@ import (
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/divijg19/rig/internal/rig"
 )
@@ -90,6 +92,54 @@ func TestOutdatedJSONNoToolsPrintsEmptyArray(t *testing.T) {
 	}
 }
 
+func TestOutdatedReleasesJSONNoToolsPrintsEmptyItems(t *testing.T) {
+	dir := t.TempDir()
+	rigToml := "[project]\nname='tmp'\nversion='0.0.0'\n"
+	if err := os.WriteFile(filepath.Join(dir, "rig.toml"), []byte(rigToml), 0o644); err != nil {
+		t.Fatalf("write rig.toml: %v", err)
+	}
+	t.Setenv("RIG_CACHE_DIR", t.TempDir())
+	out, err := runRig(dir, "tools", "outdated", "--releases", "--json", "--offline")
+	if err != nil {
+		t.Fatalf("rig tools outdated --releases --json failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "\"items\": []") {
+		t.Fatalf("expected no items, got: %q", out)
+	}
+}
+
+func TestOutdatedReportsGoPatchRelease(t *testing.T) {
+	dir := t.TempDir()
+	rigToml := "[project]\nname='tmp'\nversion='0.0.0'\n[tools]\ngo='1.24.1'\n"
+	if err := os.WriteFile(filepath.Join(dir, "rig.toml"), []byte(rigToml), 0o644); err != nil {
+		t.Fatalf("write rig.toml: %v", err)
+	}
+	cache := t.TempDir()
+	state := fmt.Sprintf(`{"checked_at":%q,"stable":["1.25.1","1.24.7"]}`, time.Now().UTC().Format(time.RFC3339))
+	if err := os.WriteFile(filepath.Join(cache, "go-releases.json"), []byte(state), 0o644); err != nil {
+		t.Fatalf("write go-releases.json: %v", err)
+	}
+	t.Setenv("RIG_CACHE_DIR", cache)
+	out, err := runRig(dir, "outdated", "--releases", "--json", "--offline")
+	if err != nil {
+		t.Fatalf("rig outdated --releases --json failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{`"kind": "go"`, `"have": "1.24.1"`, `"want": "1.24.7"`, `"status": "patch-available"`, `"severity": "warning"`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %s in:\n%s", want, out)
+		}
+	}
+	out, err = runRig(dir, "outdated", "--releases", "--offline")
+	if err != nil || !strings.Contains(out, "go1.24.7 is available") {
+		t.Fatalf("expected a Go patch notice (err=%v): %s", err, out)
+	}
+	// Release checks are opt-in.
+	out, err = runRig(dir, "outdated")
+	if err != nil || strings.Contains(out, "go1.24.7") {
+		t.Fatalf("expected no Go release check without --releases (err=%v): %s", err, out)
+	}
+}
+
 func TestSyncCheckJSONWhenInSyncPrintsEmptySummary(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("test uses a shebang script as a fake tool binary")
@@ -131,6 +181,27 @@ func TestSyncCheckJSONWhenInSyncPrintsEmptySummary(t *testing.T) {
 	}
 }
 
+func TestPrintSyncTimings(t *testing.T) {
+	rep := rig.SyncTimingReport{
+		Tools: []rig.ToolSyncTiming{
+			{Tool: "golangci-lint", ResolveMS: 420, DownloadMS: 2100, CompileMS: 38200, HashMS: 95, TotalMS: 40815},
+			{Tool: "reflex", CompileMS: 3000, TotalMS: 3000},
+		},
+		TotalMS: 41300,
+	}
+	var buf bytes.Buffer
+	if err := printSyncTimings(&styledWriter{w: &buf}, rep, false); err != nil {
+		t.Fatal(err)
+	}
+	want := "⏱️  Sync took 41.3s\n" +
+		"  tool            resolve  download   compile      hash     total\n" +
+		"  golangci-lint     420ms      2.1s     38.2s      95ms     40.8s\n" +
+		"  reflex                -         -        3s         -        3s\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
 func TestSyncOfflineDoesNotWriteLockOnResolveFailure(t *testing.T) {
 	// This test is hermetic: GOPROXY=off ensures no network access and go list fails fast.
 	dir, err := os.MkdirTemp(projectRootForTest(), "rig-test-")
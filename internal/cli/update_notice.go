@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+// updateNoticeExempt lists commands that never print the new-release notice.
+var updateNoticeExempt = map[string]struct{}{
+	"__complete": {},
+	"completion": {},
+	"help":       {},
+	"upgrade":    {},
+	"version":    {},
+}
+
+// updateNoticeEnabled reports whether the opt-in release notice applies to cmd.
+func updateNoticeEnabled(cmd *cobra.Command) bool {
+	if uc := userConf.Notify.UpdateCheck; uc == nil || !*uc {
+		return false
+	}
+	if !core.IsReleaseVersion(version) || strings.TrimSpace(os.Getenv("RIG_LAUNCHED")) != "" || strings.TrimSpace(os.Getenv("CI")) != "" {
+		return false
+	}
+	if cmd == rootCmd || !isTTY(os.Stderr) {
+		return false
+	}
+	for c := cmd; c != nil && c != rootCmd; c = c.Parent() {
+		if _, ok := updateNoticeExempt[c.Name()]; ok {
+			return false
+		}
+	}
+	// Machine-readable and quiet output stay clean.
+	return !flagSet(cmd, "json") && !flagSet(cmd, "quiet")
+}
+
+// flagSet reports whether cmd has a boolean flag name that is true.
+func flagSet(cmd *cobra.Command, name string) bool {
+	f := cmd.Flags().Lookup(name)
+	return f != nil && f.Value.String() == "true"
+}
+
+func updateCheckOptions() core.UpdateCheckOptions {
+	interval := core.DefaultUpdateCheckInterval
+	if d, err := time.ParseDuration(strings.TrimSpace(userConf.Notify.Interval)); err == nil && d > 0 {
+		interval = d
+	}
+	return core.UpdateCheckOptions{CurrentVersion: version, Interval: interval}
+}
+
+// startUpdateCheck refreshes the cached latest release in the background when
+// the last check is older than the configured interval.
+func startUpdateCheck(cmd *cobra.Command) {
+	if !updateNoticeEnabled(cmd) {
+		return
+	}
+	opts := updateCheckOptions()
+	if !core.UpdateCheckDue(opts) {
+		return
+	}
+	go func() { _, _ = core.RefreshUpdateCheck(opts) }()
+}
+
+// printUpdateNotice prints a one-line notice when a newer release is known.
+// It never waits for an in-flight check; its result is used on a later run.
+func printUpdateNotice(cmd *cobra.Command) {
+	if !updateNoticeEnabled(cmd) {
+		return
+	}
+	latest, ok := core.AvailableUpdate(updateCheckOptions())
+	if !ok {
+		return
+	}
+	msg := fmt.Sprintf("rig %s available, run rig upgrade", latest)
+	if !userConf.Plain {
+		msg = core.StatusText("💡 " + msg)
+	}
+	fmt.Fprintln(os.Stderr, msg)
+}
+
+func init() {
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		printUpdateNotice(cmd)
+	}
+}
@@ -32,6 +32,7 @@ var upgradeCmd = &cobra.Command{
 		fmt.Printf("asset: %s\n", res.AssetName)
 		fmt.Printf("checksum: %s\n", res.ChecksumName)
 		fmt.Printf("path: %s\n", res.ExecutableOut)
+		fmt.Printf("verified: rig --version reports %s\n", res.Verified)
 		return nil
 	},
 }
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+
+	core "github.com/divijg19/rig/internal/rig"
+	"github.com/spf13/cobra"
+)
+
+var workspaceSyncCheck bool
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Manage the Go workspace (go.work) from [workspace]",
+	Long:  "Keep go.work in sync with [workspace].members in rig.toml.",
+}
+
+var workspaceSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Regenerate go.work from [workspace].members",
+	Args:  cobra.NoArgs,
+	Example: `
+  rig workspace sync
+  rig workspace sync --check
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		res, err := core.WorkspaceSync("", workspaceSyncCheck)
+		if err != nil {
+			return err
+		}
+		if workspaceSyncCheck {
+			if res.Changed {
+				return fmt.Errorf("%s is out of sync with [workspace].members; run 'rig workspace sync'", res.GoWorkPath)
+			}
+			fmt.Printf("go.work in sync (%d member(s))\n", len(res.Members))
+			return nil
+		}
+		if !res.Changed {
+			fmt.Printf("go.work already up to date (%d member(s))\n", len(res.Members))
+			return nil
+		}
+		fmt.Printf("wrote %s (%d member(s))\n", res.GoWorkPath, len(res.Members))
+		return nil
+	},
+}
+
+func init() {
+	workspaceSyncCmd.Flags().BoolVar(&workspaceSyncCheck, "check", false, "fail if go.work differs from [workspace].members without writing")
+	workspaceCmd.AddCommand(workspaceSyncCmd)
+	rootCmd.AddCommand(workspaceCmd)
+}
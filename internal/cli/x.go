@@ -31,6 +31,7 @@ var xCmd = &cobra.Command{
   rig x golangci-lint -- run
   rig x mockery -- --help
 `,
+	ValidArgsFunction: completeToolNames,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 1 {
 			return errors.New("usage: rig x <tool[@version]|module[@version]> [-- args]")
@@ -61,7 +62,7 @@ var xCmd = &cobra.Command{
 		lockPath := filepath.Join(filepath.Dir(configPath), "rig.lock")
 		lock, err := core.ReadLockfile(lockPath)
 		if err != nil {
-			return fmt.Errorf("rig.lock required (%s): %w", lockPath, err)
+			return core.WithCode(core.CodeLockMissing, fmt.Errorf("rig.lock required (%s): %w", lockPath, err))
 		}
 
 		binPath, ok, rerr := core.ResolveManagedToolExecutable(configPath, lock, target)
@@ -69,7 +70,7 @@ var xCmd = &cobra.Command{
 			return rerr
 		}
 		if !ok {
-			return fmt.Errorf("%s is not a managed tool (declare it in [tools] and run 'rig tools sync')", target)
+			return core.WithCode(core.CodeToolNotManaged, fmt.Errorf("%s is not a managed tool (declare it in [tools] and run 'rig tools sync')%s", target, core.DidYouMean(target, core.ToolNameCandidates(lock))))
 		}
 
 		// Verify binary integrity against rig.lock before executing.
@@ -97,7 +98,7 @@ var xCmd = &cobra.Command{
 			return fmt.Errorf("hash %s: %w", target, herr)
 		}
 		if have != want {
-			return fmt.Errorf("%s integrity mismatch (run 'rig tools sync')", target)
+			return core.WithCode(core.CodeToolSHAMismatch, fmt.Errorf("%s integrity mismatch (run 'rig tools sync')", target))
 		}
 
 		// Prepare env; tools are executed via absolute .rig/bin paths.
@@ -119,7 +120,7 @@ var xCmd = &cobra.Command{
 			if len(toolArgs) > 0 {
 				pretty = pretty + " " + strings.Join(toolArgs, " ")
 			}
-			fmt.Printf("🧪 Dry run: would execute -> %s\n", pretty)
+			statusf(os.Stdout, "🧪 Dry run: would execute -> %s", pretty)
 			return nil
 		}
 
@@ -4,8 +4,12 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -17,6 +21,13 @@ type Project struct {
 	Version string   `mapstructure:"version" toml:"version"`
 	Authors []string `mapstructure:"authors" toml:"authors"`
 	License string   `mapstructure:"license" toml:"license"`
+	// RigVersion constrains the rig binary allowed to operate on this project
+	// (e.g. ">=0.5, <0.6"). May also be declared as `rig = "..."` under [tools].
+	RigVersion string `mapstructure:"rig-version" toml:"rig-version"`
+	// StrictTools makes `rig run` and `rig check` fail when a task command
+	// runs an executable that is not go, a shell builtin, or declared in
+	// [tools] or [requires].
+	StrictTools bool `mapstructure:"strict-tools" toml:"strict-tools"`
 }
 
 // Task represents either a simple command string or a structured task configuration
@@ -29,6 +40,102 @@ type Task struct {
 	Env         map[string]string `mapstructure:"env" toml:"env,omitempty"`
 	Cwd         string            `mapstructure:"cwd" toml:"cwd,omitempty"`
 	DependsOn   []string          `mapstructure:"depends_on" toml:"depends_on,omitempty"`
+	// WatchMode selects how [tasks.dev] detects changes: "auto" (filesystem
+	// events via reflex) or "poll". PollInterval is a Go duration ("500ms").
+	WatchMode    string `mapstructure:"watch_mode" toml:"watch_mode,omitempty"`
+	PollInterval string `mapstructure:"poll_interval" toml:"poll_interval,omitempty"`
+	// Ignore adds gitignore-style patterns skipped by the watcher; Gitignore=false
+	// stops .gitignore from being honored (it is by default).
+	Ignore    []string `mapstructure:"ignore" toml:"ignore,omitempty"`
+	Gitignore *bool    `mapstructure:"gitignore" toml:"gitignore,omitempty"`
+	// EnvFile is a dotenv file loaded into the dev process and watched;
+	// EnvReload is "restart" (default) or a signal such as "SIGHUP".
+	EnvFile   string `mapstructure:"env_file" toml:"env_file,omitempty"`
+	EnvReload string `mapstructure:"env_reload" toml:"env_reload,omitempty"`
+	// MaxRestarts stops the dev loop after that many failed starts within
+	// RestartWindow (a Go duration); 0 disables the limit, nil uses the default.
+	MaxRestarts   *int   `mapstructure:"max_restarts" toml:"max_restarts,omitempty"`
+	RestartWindow string `mapstructure:"restart_window" toml:"restart_window,omitempty"`
+	// TestOnSave runs `go test` for the package of each changed .go file
+	// alongside the dev command.
+	TestOnSave bool `mapstructure:"test_on_save" toml:"test_on_save,omitempty"`
+	// Profile applies [profile.<name>] env and go flags to the [tasks.dev]
+	// command, its depends_on, and test_on_save runs.
+	Profile string `mapstructure:"profile" toml:"profile,omitempty"`
+	// Requires names [requires] entries that must be satisfied before the task runs.
+	Requires []string `mapstructure:"requires" toml:"requires,omitempty"`
+	// AllowFailure lets a run continue past this task failing; the failure is
+	// reported but does not fail the run.
+	AllowFailure bool `mapstructure:"allow_failure" toml:"allow_failure,omitempty"`
+	// Steps makes this a composite task: the named tasks run instead of a
+	// command, one after another or concurrently depending on Mode
+	// ("serial", the default, or "parallel").
+	Steps []string `mapstructure:"steps" toml:"steps,omitempty"`
+	Mode  string   `mapstructure:"mode" toml:"mode,omitempty"`
+	// Inputs are values prompted for (or passed via --input) and substituted
+	// into the command as {{name}}.
+	Inputs []TaskInput `mapstructure:"inputs" toml:"inputs,omitempty"`
+	// DefaultArgs are used as the extra arguments when none are passed after
+	// `--`. Extra arguments are appended, or spliced in place of a ${args}
+	// or {{args}} token in the command ({{arg0}}, {{arg1}}, ... for one each).
+	DefaultArgs []string `mapstructure:"default_args" toml:"default_args,omitempty"`
+	// Dirs runs the command once in each matching directory (globs allowed,
+	// relative to rig.toml) instead of in Cwd.
+	Dirs []string `mapstructure:"dirs" toml:"dirs,omitempty"`
+	// Triggers are files (globs allowed, relative to rig.toml); when another
+	// task in the same run changes one, this task joins the run.
+	Triggers []string `mapstructure:"triggers" toml:"triggers,omitempty"`
+	// Sources and Outputs are the files (globs allowed, relative to rig.toml)
+	// the task reads and writes. `rig run` skips a task whose sources and
+	// outputs are unchanged since its last successful run, and `--isolate`
+	// copies only Sources into its temporary workspace and only Outputs back.
+	Sources []string `mapstructure:"sources" toml:"sources,omitempty"`
+	Outputs []string `mapstructure:"outputs" toml:"outputs,omitempty"`
+	// MaxMemory ("512MiB", "2G", or bytes), CPULimit (CPUs, e.g. 1.5) and
+	// Nice (-20..19) bound the task's process tree while it runs.
+	MaxMemory string  `mapstructure:"max_memory" toml:"max_memory,omitempty"`
+	CPULimit  float64 `mapstructure:"cpu_limit" toml:"cpu_limit,omitempty"`
+	Nice      *int    `mapstructure:"nice" toml:"nice,omitempty"`
+	// Ports are published by `rig export compose` in docker's short syntax
+	// ("8080", "8080:80"); they do not affect `rig run`.
+	Ports []string `mapstructure:"ports" toml:"ports,omitempty"`
+	// OutputUmask ("022") and OutputOwner ("project" or "uid[:gid]")
+	// normalize the files matching Outputs after the task succeeds.
+	OutputUmask string `mapstructure:"output_umask" toml:"output_umask,omitempty"`
+	OutputOwner string `mapstructure:"output_owner" toml:"output_owner,omitempty"`
+	// Internal hides the task from `rig run --list` and completion (unless
+	// --all); it still runs by name and as a dependency.
+	Internal bool `mapstructure:"internal" toml:"internal,omitempty"`
+	// Serial makes the task run alone under `rig run --jobs`: no other task
+	// runs while its command does, and its depends_on run one at a time.
+	Serial bool `mapstructure:"serial" toml:"serial,omitempty"`
+	// StopSignal ("SIGINT", "SIGTERM", ...) is sent to stop the command, on
+	// cancellation or a dev restart; StopTimeout (a Go duration) is how long
+	// it then has to exit before it is killed.
+	StopSignal  string `mapstructure:"stop_signal" toml:"stop_signal,omitempty"`
+	StopTimeout string `mapstructure:"stop_timeout" toml:"stop_timeout,omitempty"`
+	// Timeout (a Go duration) stops the command and its child processes when
+	// it runs longer; Retries reruns a failed or timed-out command up to that
+	// many more times, with backoff.
+	Timeout string `mapstructure:"timeout" toml:"timeout,omitempty"`
+	Retries int    `mapstructure:"retries" toml:"retries,omitempty"`
+	// Namespaced is set for a task declared in a namespace table
+	// ([tasks.db.migrate] is the task "db:migrate"); `rig run --list` then
+	// groups tasks by namespace.
+	Namespaced bool `mapstructure:"-" toml:"-"`
+}
+
+// Composite task modes.
+const (
+	TaskModeSerial   = "serial"
+	TaskModeParallel = "parallel"
+)
+
+// TaskInput declares a named value a task needs at run time.
+type TaskInput struct {
+	Name    string `mapstructure:"name" toml:"name"`
+	Prompt  string `mapstructure:"prompt" toml:"prompt,omitempty"`
+	Default string `mapstructure:"default" toml:"default,omitempty"`
 }
 
 // UnmarshalTOML allows Task to be decoded from either a string (command) or a table.
@@ -44,6 +151,10 @@ func (t *Task) fromAny(v any) error {
 		t.Command = val
 		return nil
 	case map[string]any:
+		val, err := PlatformTask(val, runtime.GOOS)
+		if err != nil {
+			return err
+		}
 		// argv (takes precedence if present)
 		if arr, ok := val["argv"].([]any); ok {
 			argv, err := toStringSlice(arr)
@@ -93,6 +204,41 @@ func (t *Task) fromAny(v any) error {
 			}
 			t.Watch = watch
 		}
+		if wm, ok := val["watch_mode"].(string); ok {
+			t.WatchMode = wm
+		}
+		if pi, ok := val["poll_interval"].(string); ok {
+			t.PollInterval = pi
+		}
+		if ignRaw, ok := val["ignore"].([]any); ok {
+			ign, err := toStringSlice(ignRaw)
+			if err != nil {
+				return fmt.Errorf("ignore: %w", err)
+			}
+			t.Ignore = ign
+		}
+		if gi, ok := val["gitignore"].(bool); ok {
+			t.Gitignore = &gi
+		}
+		if ef, ok := val["env_file"].(string); ok {
+			t.EnvFile = ef
+		}
+		if er, ok := val["env_reload"].(string); ok {
+			t.EnvReload = er
+		}
+		if mr, ok := val["max_restarts"].(int64); ok {
+			n := int(mr)
+			t.MaxRestarts = &n
+		}
+		if rw, ok := val["restart_window"].(string); ok {
+			t.RestartWindow = rw
+		}
+		if tos, ok := val["test_on_save"].(bool); ok {
+			t.TestOnSave = tos
+		}
+		if p, ok := val["profile"].(string); ok {
+			t.Profile = strings.TrimSpace(p)
+		}
 		// cwd
 		if cwd, ok := val["cwd"].(string); ok {
 			t.Cwd = cwd
@@ -123,6 +269,123 @@ func (t *Task) fromAny(v any) error {
 				}
 			}
 		}
+		// requires
+		if reqRaw, ok := val["requires"].([]any); ok {
+			req, err := toStringSlice(reqRaw)
+			if err != nil {
+				return fmt.Errorf("requires: %w", err)
+			}
+			t.Requires = req
+		}
+		if af, ok := val["allow_failure"].(bool); ok {
+			t.AllowFailure = af
+		}
+		if stepsRaw, ok := val["steps"].([]any); ok {
+			steps, err := toStringSlice(stepsRaw)
+			if err != nil {
+				return fmt.Errorf("steps: %w", err)
+			}
+			t.Steps = steps
+		}
+		if m, ok := val["mode"].(string); ok {
+			t.Mode = m
+		}
+		if daRaw, ok := val["default_args"].([]any); ok {
+			da, err := toStringSlice(daRaw)
+			if err != nil {
+				return fmt.Errorf("default_args: %w", err)
+			}
+			t.DefaultArgs = da
+		}
+		if dirsRaw, ok := val["dirs"].([]any); ok {
+			dirs, err := toStringSlice(dirsRaw)
+			if err != nil {
+				return fmt.Errorf("dirs: %w", err)
+			}
+			t.Dirs = dirs
+		}
+		if trRaw, ok := val["triggers"].([]any); ok {
+			triggers, err := toStringSlice(trRaw)
+			if err != nil {
+				return fmt.Errorf("triggers: %w", err)
+			}
+			t.Triggers = triggers
+		}
+		if srcRaw, ok := val["sources"].([]any); ok {
+			sources, err := toStringSlice(srcRaw)
+			if err != nil {
+				return fmt.Errorf("sources: %w", err)
+			}
+			t.Sources = sources
+		}
+		if outRaw, ok := val["outputs"].([]any); ok {
+			outputs, err := toStringSlice(outRaw)
+			if err != nil {
+				return fmt.Errorf("outputs: %w", err)
+			}
+			t.Outputs = outputs
+		}
+		switch mm := val["max_memory"].(type) {
+		case string:
+			t.MaxMemory = strings.TrimSpace(mm)
+		case int64:
+			t.MaxMemory = strconv.FormatInt(mm, 10)
+		}
+		switch cl := val["cpu_limit"].(type) {
+		case float64:
+			t.CPULimit = cl
+		case int64:
+			t.CPULimit = float64(cl)
+		}
+		if n, ok := val["nice"].(int64); ok {
+			nice := int(n)
+			t.Nice = &nice
+		}
+		if um, ok := val["output_umask"].(string); ok {
+			t.OutputUmask = strings.TrimSpace(um)
+		}
+		if oo, ok := val["output_owner"].(string); ok {
+			t.OutputOwner = strings.TrimSpace(oo)
+		}
+		if b, ok := val["internal"].(bool); ok {
+			t.Internal = b
+		}
+		if b, ok := val["serial"].(bool); ok {
+			t.Serial = b
+		}
+		if ss, ok := val["stop_signal"].(string); ok {
+			t.StopSignal = strings.TrimSpace(ss)
+		}
+		if st, ok := val["stop_timeout"].(string); ok {
+			t.StopTimeout = strings.TrimSpace(st)
+		}
+		if to, ok := val["timeout"].(string); ok {
+			t.Timeout = strings.TrimSpace(to)
+		}
+		if n, ok := val["retries"].(int64); ok {
+			t.Retries = int(n)
+		}
+		if portsRaw, ok := val["ports"].([]any); ok {
+			ports, err := toStringSlice(portsRaw)
+			if err != nil {
+				return fmt.Errorf("ports: %w", err)
+			}
+			t.Ports = ports
+		}
+		// inputs
+		if inRaw, ok := val["inputs"].([]any); ok {
+			for _, it := range inRaw {
+				tbl, ok := it.(map[string]any)
+				if !ok {
+					return fmt.Errorf("inputs items must be tables, got %T", it)
+				}
+				var in TaskInput
+				in.Name, _ = tbl["name"].(string)
+				in.Prompt, _ = tbl["prompt"].(string)
+				in.Default, _ = tbl["default"].(string)
+				t.Inputs = append(t.Inputs, in)
+			}
+		}
 		return nil
 	case nil:
 		// treat as empty
@@ -141,18 +404,219 @@ func (m *TasksMap) UnmarshalTOML(v any) error {
 	if !ok {
 		return fmt.Errorf("tasks must be a table, got %T", v)
 	}
+	tbl, namespaced, err := FlattenTaskNamespaces(tbl)
+	if err != nil {
+		return err
+	}
 	out := make(TasksMap, len(tbl))
 	for name, raw := range tbl {
 		var t Task
 		if err := t.fromAny(raw); err != nil {
 			return fmt.Errorf("task %q: %w", name, err)
 		}
+		t.Namespaced = namespaced[name]
 		out[name] = t
 	}
 	*m = out
 	return nil
 }
 
+// FlattenTaskNamespaces expands namespace tables in [tasks]. A table whose
+// entries are all tables, none of them env or a platform variant, is a
+// namespace: [tasks.db.migrate] becomes the task "db:migrate", and namespaces
+// nest ([tasks.db.migrate.up] is "db:migrate:up"). It also returns which
+// names came from a namespace. A would-be namespace whose entries are all
+// misspelled platforms ([tasks.build.windowz]) is an error.
+func FlattenTaskNamespaces(tasks map[string]any) (map[string]any, map[string]bool, error) {
+	out := make(map[string]any, len(tasks))
+	namespaced := map[string]bool{}
+	var namespaces []string
+	for name, raw := range tasks {
+		if sub, ok := raw.(map[string]any); ok && isTaskNamespace(sub) {
+			if err := checkMisspelledPlatforms([]string{name}, sub); err != nil {
+				return nil, nil, err
+			}
+			namespaces = append(namespaces, name)
+			continue
+		}
+		out[name] = raw
+	}
+	slices.Sort(namespaces)
+	var walk func(path []string, tbl map[string]any) error
+	walk = func(path []string, tbl map[string]any) error {
+		for name, raw := range tbl {
+			p := append(slices.Clip(path), name)
+			if sub, ok := raw.(map[string]any); ok && isTaskNamespace(sub) {
+				if err := checkMisspelledPlatforms(p, sub); err != nil {
+					return err
+				}
+				if err := walk(p, sub); err != nil {
+					return err
+				}
+				continue
+			}
+			full := strings.Join(p, ":")
+			if _, dup := out[full]; dup {
+				return fmt.Errorf("task %q is defined twice: by name and as [tasks.%s]", full, strings.Join(p, "."))
+			}
+			out[full] = raw
+			namespaced[full] = true
+		}
+		return nil
+	}
+	for _, ns := range namespaces {
+		if err := walk([]string{ns}, tasks[ns].(map[string]any)); err != nil {
+			return nil, nil, err
+		}
+	}
+	return out, namespaced, nil
+}
+
+func isTaskNamespace(tbl map[string]any) bool {
+	if len(tbl) == 0 {
+		return false
+	}
+	for k, v := range tbl {
+		if _, ok := v.(map[string]any); !ok || k == "env" {
+			return false
+		}
+		if _, ok := taskPlatforms[k]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// checkMisspelledPlatforms rejects a namespace table at path whose entries
+// are all near misses of platform names: a task with only a mistyped
+// variant would otherwise become a namespaced task such as "build:windowz".
+func checkMisspelledPlatforms(path []string, tbl map[string]any) error {
+	names := slices.Sorted(maps.Keys(tbl))
+	var want string
+	for _, name := range names {
+		p, ok := misspelledPlatform(name)
+		if !ok {
+			return nil
+		}
+		if want == "" {
+			want = p
+		}
+	}
+	return fmt.Errorf("[tasks.%s.%s]: unknown platform %q; did you mean %q?", strings.Join(path, "."), names[0], names[0], want)
+}
+
+// misspelledPlatform returns the platform name is a near miss of: another
+// case, or one edit away (two for names of six letters or more). Platforms
+// shorter than four letters are too close to ordinary task names to guess.
+func misspelledPlatform(name string) (string, bool) {
+	lc := strings.ToLower(name)
+	best, bestDist := "", -1
+	for _, p := range slices.Sorted(maps.Keys(taskPlatforms)) {
+		if name == p || len(p) < 4 {
+			continue
+		}
+		limit := 1
+		if len(p) >= 6 {
+			limit = 2
+		}
+		if d := EditDistance(lc, p); d <= limit && (bestDist < 0 || d < bestDist) {
+			best, bestDist = p, d
+		}
+	}
+	return best, bestDist >= 0
+}
+
+// EditDistance returns the Levenshtein distance between a and b.
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// taskPlatforms are the subtables a task table may hold to override its
+// fields on one platform: a GOOS name, or "unix" for the GOOS values Go's
+// unix build constraint matches. The value reports whether the GOOS is unix.
+var taskPlatforms = map[string]bool{
+	"unix": false, "windows": false, "plan9": false, "js": false, "wasip1": false,
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+	"illumos": true, "ios": true, "linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+}
+
+// TaskVariants returns the platform variants ([tasks.<name>.<platform>]) a
+// task table holds, sorted.
+func TaskVariants(tbl map[string]any) []string {
+	var out []string
+	for k := range tbl {
+		if _, ok := taskPlatforms[k]; ok {
+			out = append(out, k)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// PlatformTask returns the task table as it applies on goos: the fields of
+// its "unix" variant (on a unix GOOS) and then of its goos variant replace
+// the task's own, except that env is merged and a command replaces steps
+// (and steps a command). The variant subtables themselves are dropped.
+func PlatformTask(tbl map[string]any, goos string) (map[string]any, error) {
+	out := make(map[string]any, len(tbl))
+	for k, v := range tbl {
+		if _, ok := taskPlatforms[k]; !ok {
+			out[k] = v
+		}
+	}
+	for i, p := range []string{"unix", goos} {
+		if i == 0 && !taskPlatforms[goos] {
+			continue
+		}
+		raw, ok := tbl[p]
+		if !ok {
+			continue
+		}
+		variant, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a table of task fields, got %T", p, raw)
+		}
+		if nested := TaskVariants(variant); len(nested) > 0 {
+			return nil, fmt.Errorf("%s: platform variants cannot be nested (found %s)", p, nested[0])
+		}
+		if _, ok := variant["command"]; ok {
+			delete(out, "steps")
+			delete(out, "mode")
+		}
+		if _, ok := variant["steps"]; ok {
+			delete(out, "command")
+		}
+		for k, v := range variant {
+			base, ok1 := out[k].(map[string]any)
+			over, ok2 := v.(map[string]any)
+			if k == "env" && ok1 && ok2 {
+				env := maps.Clone(base)
+				maps.Copy(env, over)
+				v = env
+			}
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
 // toStringSlice converts a []any to []string with validation.
 func toStringSlice(v []any) ([]string, error) {
 	out := make([]string, 0, len(v))
@@ -170,12 +634,202 @@ type Config struct {
 	Project Project           `mapstructure:"project" toml:"project"`
 	Tasks   TasksMap          `mapstructure:"tasks" toml:"tasks"`
 	Tools   map[string]string `mapstructure:"tools" toml:"tools"`
-	// Include allows splitting configuration across files.
-	// Paths are resolved relative to the main rig.toml directory. For monorepos,
-	// paths under .rig/ are also attempted if not found alongside the main file.
-	Includes []string `mapstructure:"include" toml:"include"`
+	// Include allows splitting configuration across files. Included files may
+	// include further files; paths are resolved relative to the including
+	// file's directory. For monorepos, paths under .rig/ are also attempted if
+	// not found alongside it. Entries of the form host/owner/repo//file.toml@ref
+	// are fetched remotely. Missing files are an error unless optional.
+	Includes []IncludeEntry `mapstructure:"include" toml:"include"`
 	// Profile-specific build settings (e.g., [profile.release])
 	Profiles map[string]BuildProfile `mapstructure:"profile" toml:"profile"`
+	// Workspace lists Go workspace members (mirrored into go.work by `rig workspace sync`).
+	Workspace Workspace `mapstructure:"workspace" toml:"workspace"`
+	// Requires declares system prerequisites (not installed by rig) and their
+	// version constraints, e.g. docker = ">=24", make = "*".
+	Requires map[string]string `mapstructure:"requires" toml:"requires"`
+	// Schedules maps task names to cron expressions run by `rig schedule`,
+	// e.g. codegen = "*/15 * * * *" or cache-refresh = "@every 10m".
+	Schedules map[string]string `mapstructure:"schedules" toml:"schedules"`
+	// ToolAliases adds project short names for [tools] keys ([tool-aliases]).
+	ToolAliases map[string]ToolAlias `mapstructure:"tool-aliases" toml:"tool-aliases"`
+	// ToolProbes tells `rig tools doctor` how to ask a tool for its version
+	// ([tool-probes.<name>]).
+	ToolProbes map[string]ToolProbe `mapstructure:"tool-probes" toml:"tool-probes"`
+	// Lock controls how rig.lock is trusted ([lock]). Only read from the base
+	// rig.toml, never from includes.
+	Lock LockPolicy `mapstructure:"lock" toml:"lock"`
+	// Cache points build caches at project locations ([cache]). Only read
+	// from the base rig.toml, never from includes.
+	Cache CacheConfig `mapstructure:"cache" toml:"cache"`
+	// Paths relocates .rig/bin and rig's project state ([paths]). Only read
+	// from the base rig.toml, never from includes.
+	Paths PathsConfig `mapstructure:"paths" toml:"paths"`
+	// CI restricts the environment tasks see under CI or `rig run
+	// --hermetic` ([ci]). Only read from the base rig.toml, never from
+	// includes.
+	CI CIConfig `mapstructure:"ci" toml:"ci"`
+	// Plugins are external binaries speaking rig's plugin protocol
+	// ([plugins.<name>]). Only read from the base rig.toml, never from
+	// includes.
+	Plugins map[string]Plugin `mapstructure:"plugins" toml:"plugins"`
+	// RemoteIncludes records the remote includes that were loaded and the
+	// content hash of each, for pinning in rig.lock. Set by the loaders.
+	RemoteIncludes []IncludePin `mapstructure:"-" toml:"-"`
+	// IncludeOverrides describes tasks, tools, and profiles redefined by a
+	// later config file under AllowIncludeOverride. Set by the loaders.
+	IncludeOverrides []IncludeConflict `mapstructure:"-" toml:"-"`
+}
+
+// LockPolicy requires a detached signature (rig.lock.sig) before check and run
+// trust rig.lock.
+type LockPolicy struct {
+	// Signature names the signer: "minisign" or "cosign". Empty disables the check.
+	Signature string `mapstructure:"signature" toml:"signature"`
+	// PublicKey is the verification key, relative to rig.toml.
+	PublicKey string `mapstructure:"public_key" toml:"public_key"`
+}
+
+// CacheConfig relocates caches used by rig-launched commands.
+type CacheConfig struct {
+	// Go is the GOCACHE directory, relative to rig.toml (e.g. ".rig/cache/go").
+	Go string `mapstructure:"go" toml:"go"`
+}
+
+// PathsConfig moves the directories rig writes inside the project, e.g. for
+// read-only checkouts. Relative paths resolve against rig.toml; the
+// RIG_PATHS_BIN and RIG_PATHS_CACHE environment variables take precedence.
+type PathsConfig struct {
+	// Bin holds the tools rig installs (default ".rig/bin").
+	Bin string `mapstructure:"bin" toml:"bin"`
+	// Cache holds run history, logs, and the project lock (default ".rig").
+	Cache string `mapstructure:"cache" toml:"cache"`
+}
+
+// CIConfig holds settings that apply when rig runs under CI.
+type CIConfig struct {
+	// EnvAllowlist names the variables tasks inherit from the environment
+	// under CI or --hermetic, e.g. ["HOME", "PATH", "GOMODCACHE"]. Entries
+	// may be patterns such as "GO*". Empty leaves the environment alone in
+	// CI.
+	EnvAllowlist []string `mapstructure:"env_allowlist" toml:"env_allowlist"`
+}
+
+// Plugin declares an external binary that rig starts on demand and talks to
+// over its stdin and stdout. Provides lists the hooks it implements: "cache"
+// (remote task output cache), "secrets" (secret:// env values), and
+// "notify" (run results).
+type Plugin struct {
+	// Command is the plugin executable and arguments; a relative path is
+	// resolved against rig.toml, a bare name against .rig/bin and PATH.
+	Command  string            `mapstructure:"command" toml:"command"`
+	Provides []string          `mapstructure:"provides" toml:"provides"`
+	Env      map[string]string `mapstructure:"env" toml:"env,omitempty"`
+}
+
+// Plugin hooks.
+const (
+	PluginCache   = "cache"
+	PluginSecrets = "secrets"
+	PluginNotify  = "notify"
+)
+
+// ToolAlias maps a short tool name to a Go module. Install defaults to Module
+// and Bin to the last element of Install.
+//
+// With OCI instead of Module, the tool is a prebuilt binary pulled from an OCI
+// registry repository (e.g. ghcr.io/acme/tool); its [tools] version is a tag.
+type ToolAlias struct {
+	Module  string `mapstructure:"module" toml:"module"`
+	Install string `mapstructure:"install" toml:"install,omitempty"`
+	Bin     string `mapstructure:"bin" toml:"bin,omitempty"`
+	OCI     string `mapstructure:"oci" toml:"oci,omitempty"`
+}
+
+// ToolProbe configures the version probe for a [tools] entry. VersionArgs
+// default to ["--version"]; VersionRegex picks the version out of the output
+// (its first group, if it has one). HashOnly skips running the tool.
+type ToolProbe struct {
+	VersionArgs  []string `mapstructure:"version_args" toml:"version_args,omitempty"`
+	VersionRegex string   `mapstructure:"version_regex" toml:"version_regex,omitempty"`
+	HashOnly     bool     `mapstructure:"hash_only" toml:"hash_only,omitempty"`
+}
+
+// ParseToolAliases decodes [tool-aliases]. Each value is either a module path
+// string or a table with module, install, and bin (or oci and bin).
+func ParseToolAliases(raw map[string]any) (map[string]ToolAlias, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]ToolAlias, len(raw))
+	for name, v := range raw {
+		if name == "go" || name == "rig" || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("tool-aliases: %q cannot be aliased", name)
+		}
+		var a ToolAlias
+		switch val := v.(type) {
+		case string:
+			a.Module = val
+		case map[string]any:
+			for k, fv := range val {
+				s, ok := fv.(string)
+				if !ok {
+					return nil, fmt.Errorf("tool-aliases.%s.%s must be a string, got %T", name, k, fv)
+				}
+				switch k {
+				case "module":
+					a.Module = s
+				case "install":
+					a.Install = s
+				case "bin":
+					a.Bin = s
+				case "oci":
+					a.OCI = strings.TrimPrefix(strings.TrimSpace(s), "oci://")
+				default:
+					return nil, fmt.Errorf("tool-aliases.%s: unsupported field %q (allowed: module, install, bin, oci)", name, k)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("tool-aliases.%s must be a string or table, got %T", name, v)
+		}
+		a.Module = strings.TrimSpace(a.Module)
+		a.Install = strings.TrimSpace(a.Install)
+		a.Bin = strings.TrimSpace(a.Bin)
+		if a.OCI != "" {
+			if a.Module != "" || a.Install != "" {
+				return nil, fmt.Errorf("tool-aliases.%s: oci cannot be combined with module or install", name)
+			}
+			out[name] = a
+			continue
+		}
+		if a.Module == "" {
+			return nil, fmt.Errorf("tool-aliases.%s: module (or oci) is required", name)
+		}
+		if a.Install != "" && a.Install != a.Module && !strings.HasPrefix(a.Install, a.Module+"/") {
+			return nil, fmt.Errorf("tool-aliases.%s: install %q is not inside module %q", name, a.Install, a.Module)
+		}
+		out[name] = a
+	}
+	return out, nil
+}
+
+// Workspace describes a multi-module Go workspace rooted at the rig.toml directory.
+type Workspace struct {
+	// Members are module directories relative to rig.toml (e.g., "./svc/api").
+	Members []string `mapstructure:"members" toml:"members"`
+}
+
+// LiftRigVersion moves a `rig = "..."` entry out of [tools] into
+// Project.RigVersion. rig is never installed as a managed tool; [project]
+// takes precedence when both are set.
+func LiftRigVersion(c *Config) {
+	v, ok := c.Tools["rig"]
+	if !ok {
+		return
+	}
+	delete(c.Tools, "rig")
+	if strings.TrimSpace(c.Project.RigVersion) == "" {
+		c.Project.RigVersion = strings.TrimSpace(v)
+	}
 }
 
 // BuildProfile captures optional build-time configuration that can be
@@ -0,0 +1,206 @@
+// internal/config/diagnostic.go
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// TaskError is a schema error in one [tasks] entry. Diagnose uses the task
+// name to point at its definition in the source file.
+type TaskError struct {
+	Task string
+	Err  error
+}
+
+func (e *TaskError) Error() string { return fmt.Sprintf("task %q: %v", e.Task, e.Err) }
+
+func (e *TaskError) Unwrap() error { return e.Err }
+
+// SourceError is a config load failure pinned to a position in a file.
+// Its message renders the offending line with a caret under the column:
+//
+//	rig.toml:3:9: array is incomplete
+//	  |
+//	3 | lint = [
+//	  |         ^
+type SourceError struct {
+	Path   string
+	Line   int // 1-based
+	Column int // 1-based byte column
+	Msg    string
+	Text   string // the offending source line
+	Err    error
+}
+
+func (e *SourceError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:%d:%d: %s", displayPath(e.Path), e.Line, e.Column, e.Msg)
+	if e.Text == "" {
+		return b.String()
+	}
+	num := strconv.Itoa(e.Line)
+	gutter := strings.Repeat(" ", len(num))
+	// Keep tabs so the caret lines up with the source as the terminal shows it.
+	pad := []rune{}
+	for i, r := range e.Text {
+		if i >= e.Column-1 {
+			break
+		}
+		if r == '\t' {
+			pad = append(pad, '\t')
+		} else {
+			pad = append(pad, ' ')
+		}
+	}
+	fmt.Fprintf(&b, "\n%s |\n%s | %s\n%s | %s^", gutter, num, e.Text, gutter, string(pad))
+	return b.String()
+}
+
+func (e *SourceError) Unwrap() error { return e.Err }
+
+// Diagnose attaches the file, line, column, and source snippet to an error
+// from decoding data (the contents of path). TOML syntax and type errors
+// carry their own position; task schema errors are located by task name and,
+// when the message names one, by field. Anything else is prefixed with path.
+func Diagnose(path string, data []byte, err error) error {
+	if err == nil {
+		return nil
+	}
+	var de *toml.DecodeError
+	if errors.As(err, &de) {
+		line, col := de.Position()
+		return newSourceError(path, data, line, col, strings.TrimPrefix(de.Error(), "toml: "), err)
+	}
+	var te *TaskError
+	if errors.As(err, &te) {
+		if line, col := locateTask(data, te.Task, te.Err.Error()); line > 0 {
+			return newSourceError(path, data, line, col, err.Error(), err)
+		}
+	}
+	return fmt.Errorf("%s: %w", displayPath(path), err)
+}
+
+func newSourceError(path string, data []byte, line, col int, msg string, err error) *SourceError {
+	lines := strings.Split(string(data), "\n")
+	text := ""
+	if line >= 1 && line <= len(lines) {
+		text = strings.TrimRight(lines[line-1], "\r")
+	}
+	return &SourceError{Path: path, Line: line, Column: max(col, 1), Msg: msg, Text: text, Err: err}
+}
+
+// displayPath shortens path relative to the working directory when it is
+// inside it.
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+var (
+	tableHeaderRe      = regexp.MustCompile(`^\s*\[\[?\s*([^\]]+?)\s*\]\]?`)
+	unsupportedFieldRe = regexp.MustCompile(`unsupported field "([^"]+)"`)
+	leadingFieldRe     = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_-]*)(?: must\b| items\b|:)`)
+)
+
+// locateTask returns the 1-based line and column of task's definition in
+// data, preferring the field named in msg when it can be found.
+func locateTask(data []byte, task, msg string) (int, int) {
+	field := ""
+	if m := unsupportedFieldRe.FindStringSubmatch(msg); m != nil {
+		field = m[1]
+	} else if m := leadingFieldRe.FindStringSubmatch(msg); m != nil {
+		field = m[1]
+	}
+
+	// A namespaced task ("db:migrate") may be [tasks.db.migrate], or
+	// migrate = {...} under [tasks.db].
+	tables := []string{"tasks." + task}
+	parent, leaf := "tasks", task
+	if nested := "tasks." + strings.ReplaceAll(task, ":", "."); nested != tables[0] {
+		tables = append(tables, nested)
+		if i := strings.LastIndex(nested, "."); i > len("tasks") {
+			parent, leaf = nested[:i], nested[i+1:]
+		}
+	}
+	isTaskTable := func(table string) bool { return slices.Contains(tables, table) }
+
+	taskLine, taskCol := 0, 0
+	table := ""
+	for i, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimRight(raw, "\r")
+		if m := tableHeaderRe.FindStringSubmatch(line); m != nil {
+			table = normalizeTOMLKey(m[1])
+			if isTaskTable(table) && taskLine == 0 {
+				taskLine, taskCol = i+1, strings.Index(line, "[")+1
+			}
+			continue
+		}
+		key, col := lineKey(line)
+		if key == "" {
+			continue
+		}
+		switch {
+		case (table == "tasks" && key == task) || (table == parent && key == leaf):
+			if taskLine == 0 {
+				taskLine, taskCol = i+1, col
+			}
+			if field != "" {
+				if fc := inlineFieldColumn(line, field); fc > 0 {
+					return i + 1, fc
+				}
+			}
+		case field != "" && key == field && slices.ContainsFunc(tables, func(t string) bool { return table == t || strings.HasPrefix(table, t+".") }):
+			return i + 1, col
+		}
+	}
+	return taskLine, taskCol
+}
+
+// lineKey returns the (unquoted) key assigned on line and its 1-based column.
+func lineKey(line string) (string, int) {
+	k, _, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", 0
+	}
+	trimmed := strings.TrimSpace(k)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return "", 0
+	}
+	return normalizeTOMLKey(trimmed), strings.Index(line, trimmed) + 1
+}
+
+// inlineFieldColumn finds `field =` inside an inline table on line.
+func inlineFieldColumn(line, field string) int {
+	re := regexp.MustCompile(`[{,]\s*("?)` + regexp.QuoteMeta(field) + `("?)\s*=`)
+	loc := re.FindStringIndex(line)
+	if loc == nil {
+		return 0
+	}
+	return loc[0] + 1 + len(line[loc[0]+1:]) - len(strings.TrimLeft(line[loc[0]+1:], " \t")) + 1
+}
+
+// normalizeTOMLKey strips quotes and spaces around dotted key parts.
+func normalizeTOMLKey(k string) string {
+	parts := strings.Split(k, ".")
+	for i, p := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(p), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
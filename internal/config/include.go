@@ -0,0 +1,219 @@
+// internal/config/include.go
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// IncludeEntry is one element of the include array: either a plain string
+// path or a table { path = "...", optional = true }. A missing include is an
+// error unless it is optional.
+type IncludeEntry struct {
+	Path     string `toml:"path"`
+	Optional bool   `toml:"optional"`
+}
+
+// ParseIncludeEntries converts a decoded include array into entries.
+func ParseIncludeEntries(raw []any) ([]IncludeEntry, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	out := make([]IncludeEntry, 0, len(raw))
+	for i, v := range raw {
+		switch val := v.(type) {
+		case string:
+			if p := strings.TrimSpace(val); p != "" {
+				out = append(out, IncludeEntry{Path: p})
+			}
+		case map[string]any:
+			var e IncludeEntry
+			for k, fv := range val {
+				switch k {
+				case "path":
+					s, ok := fv.(string)
+					if !ok {
+						return nil, fmt.Errorf("include[%d].path must be a string, got %T", i, fv)
+					}
+					e.Path = strings.TrimSpace(s)
+				case "optional":
+					b, ok := fv.(bool)
+					if !ok {
+						return nil, fmt.Errorf("include[%d].optional must be a boolean, got %T", i, fv)
+					}
+					e.Optional = b
+				default:
+					return nil, fmt.Errorf("include[%d]: unsupported field %q (allowed: path, optional)", i, k)
+				}
+			}
+			if e.Path == "" {
+				return nil, fmt.Errorf("include[%d]: path is required", i)
+			}
+			out = append(out, e)
+		default:
+			return nil, fmt.Errorf("include[%d] must be a string or table, got %T", i, v)
+		}
+	}
+	return out, nil
+}
+
+var includeLineRe = regexp.MustCompile(`(?m)^\s*include\s*=\s*\[[^\]]*\]`)
+
+// parseIncludeList extracts a top-level include array from TOML bytes even
+// when it appears after a table header (where TOML would scope it to that
+// table). Only single-line arrays are recognized.
+func parseIncludeList(b []byte) []IncludeEntry {
+	m := includeLineRe.Find(b)
+	if m == nil {
+		return nil
+	}
+	var doc struct {
+		Include []any `toml:"include"`
+	}
+	if err := toml.Unmarshal(m, &doc); err != nil {
+		return nil
+	}
+	entries, err := ParseIncludeEntries(doc.Include)
+	if err != nil {
+		return nil
+	}
+	return entries
+}
+
+// LoadedInclude is one include file decoded by WalkIncludes. Pin is set for
+// remote includes.
+type LoadedInclude struct {
+	Path   string
+	Config Config
+	Pin    *IncludePin
+}
+
+// WalkIncludes loads the includes of the config file at path (whose contents
+// are data), then the includes of those includes, depth-first. Each file's
+// includes resolve relative to that file. The result is in merge order: a
+// file comes before the files it includes, so included values win. decode
+// parses one file. An include that is already being loaded higher up the
+// chain is a cycle and fails; remote includes do not load further includes.
+func WalkIncludes(path string, data []byte, includes []IncludeEntry, decode func(path string, data []byte) (Config, error)) ([]LoadedInclude, error) {
+	w := includeWalker{pins: IncludePins(path), decode: decode}
+	if err := w.walk(filepath.Dir(path), data, includes, []string{path}); err != nil {
+		return nil, err
+	}
+	return w.loaded, nil
+}
+
+type includeWalker struct {
+	pins   map[string]string
+	decode func(path string, data []byte) (Config, error)
+	loaded []LoadedInclude
+}
+
+func (w *includeWalker) walk(baseDir string, data []byte, includes []IncludeEntry, stack []string) error {
+	if len(includes) == 0 {
+		includes = parseIncludeList(data)
+	}
+	for _, entry := range includes {
+		incData, incPath, pin, err := ReadInclude(baseDir, entry.Path, w.pins)
+		if err != nil {
+			return err
+		}
+		if incData == nil {
+			if entry.Optional {
+				continue
+			}
+			return fmt.Errorf("include %s not found (included from %s; use { path = %q, optional = true } if it may be absent)", displayPath(incPath), displayPath(stack[len(stack)-1]), entry.Path)
+		}
+		if slices.Contains(stack, incPath) {
+			chain := make([]string, 0, len(stack)+1)
+			for _, p := range append(stack, incPath) {
+				chain = append(chain, displayPath(p))
+			}
+			return fmt.Errorf("include cycle: %s", strings.Join(chain, " -> "))
+		}
+		inc, err := w.decode(incPath, incData)
+		if err != nil {
+			return err
+		}
+		w.loaded = append(w.loaded, LoadedInclude{Path: incPath, Config: inc, Pin: pin})
+		if pin != nil {
+			continue
+		}
+		if err := w.walk(filepath.Dir(incPath), incData, inc.Includes, append(stack[:len(stack):len(stack)], incPath)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AllowIncludeOverride downgrades duplicate task, tool, and profile
+// definitions across rig.toml and its includes from an error to a warning;
+// the later file wins. `--allow-override` sets it.
+var AllowIncludeOverride bool
+
+// IncludeConflict is a task, tool, or profile defined by two config files.
+// Second is merged after First and wins when overrides are allowed.
+type IncludeConflict struct {
+	Key    string // e.g. "tasks.lint", "tools.golangci-lint", "profile.release"
+	First  string
+	Second string
+}
+
+func (c IncludeConflict) String() string {
+	return fmt.Sprintf("%s is defined in both %s and %s", c.Key, displayPath(c.First), displayPath(c.Second))
+}
+
+// IncludeConflicts lists keys that base (loaded from path) and the included
+// files define more than once, in merge order. A file included twice does
+// not conflict with itself.
+func IncludeConflicts(path string, base Config, loaded []LoadedInclude) []IncludeConflict {
+	defined := map[string]string{}
+	var out []IncludeConflict
+	record := func(file string, c Config) {
+		var keys []string
+		for k := range c.Tasks {
+			keys = append(keys, "tasks."+k)
+		}
+		for k := range c.Tools {
+			keys = append(keys, "tools."+k)
+		}
+		for k := range c.Profiles {
+			keys = append(keys, "profile."+k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prev, ok := defined[k]; ok && prev != file {
+				out = append(out, IncludeConflict{Key: k, First: prev, Second: file})
+			}
+			defined[k] = file
+		}
+	}
+	record(path, base)
+	for _, li := range loaded {
+		record(li.Path, li.Config)
+	}
+	return out
+}
+
+// CheckIncludeConflicts fails when IncludeConflicts finds any, unless
+// AllowIncludeOverride is set; then it returns them for the caller to warn.
+func CheckIncludeConflicts(path string, base Config, loaded []LoadedInclude) ([]IncludeConflict, error) {
+	conflicts := IncludeConflicts(path, base, loaded)
+	if len(conflicts) == 0 || AllowIncludeOverride {
+		return conflicts, nil
+	}
+	var b strings.Builder
+	b.WriteString("conflicting definitions across config files (pass --allow-override to let the later file win):")
+	for _, c := range conflicts {
+		b.WriteString("\n  ")
+		b.WriteString(c.String())
+	}
+	return nil, errors.New(b.String())
+}
@@ -7,8 +7,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
-	"strings"
 
 	toml "github.com/pelletier/go-toml/v2"
 )
@@ -57,44 +55,36 @@ func Load(startDir string) (*Config, string, error) {
 	}
 	var raw rawConfig
 	if err := toml.Unmarshal(data, &raw); err != nil {
-		return nil, "", fmt.Errorf("unmarshal base config: %w", err)
+		return nil, "", Diagnose(path, data, err)
 	}
 	c, err := toTyped(raw)
 	if err != nil {
-		return nil, "", fmt.Errorf("convert base config: %w", err)
+		return nil, "", Diagnose(path, data, err)
 	}
 
-	// Resolve include paths relative to the base file (and support .rig/ fallbacks)
-	baseDir := filepath.Dir(path)
-	includes := c.Includes
-	if len(includes) == 0 {
-		includes = append(includes, parseIncludeList(data)...)
-	}
-	for _, rel := range includes {
-		incPath := rel
-		if !filepath.IsAbs(incPath) {
-			incPath = filepath.Join(baseDir, rel)
-		}
-		if _, err := os.Stat(incPath); err != nil {
-			alt := filepath.Join(baseDir, ".rig", rel)
-			if _, err2 := os.Stat(alt); err2 == nil {
-				incPath = alt
-			} else {
-				continue // skip missing include
-			}
-		}
-		incData, err := os.ReadFile(incPath)
-		if err != nil {
-			return nil, "", fmt.Errorf("read include %s: %w", incPath, err)
-		}
+	// Resolve include paths relative to the including file (and support .rig/ fallbacks)
+	loaded, err := WalkIncludes(path, data, c.Includes, func(incPath string, incData []byte) (Config, error) {
 		var rawInc rawConfig
 		if err := toml.Unmarshal(incData, &rawInc); err != nil {
-			return nil, "", fmt.Errorf("unmarshal include %s: %w", incPath, err)
+			return Config{}, Diagnose(incPath, incData, err)
 		}
 		inc, err := toTyped(rawInc)
 		if err != nil {
-			return nil, "", fmt.Errorf("convert include %s: %w", incPath, err)
+			return Config{}, Diagnose(incPath, incData, err)
+		}
+		return inc, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if c.IncludeOverrides, err = CheckIncludeConflicts(path, c, loaded); err != nil {
+		return nil, "", err
+	}
+	for _, li := range loaded {
+		if li.Pin != nil {
+			c.RemoteIncludes = append(c.RemoteIncludes, *li.Pin)
 		}
+		inc := li.Config
 		if inc.Tasks != nil {
 			if c.Tasks == nil {
 				c.Tasks = TasksMap{}
@@ -111,6 +101,38 @@ func Load(startDir string) (*Config, string, error) {
 				c.Tools[k] = v
 			}
 		}
+		if inc.Requires != nil {
+			if c.Requires == nil {
+				c.Requires = map[string]string{}
+			}
+			for k, v := range inc.Requires {
+				c.Requires[k] = v
+			}
+		}
+		if inc.Schedules != nil {
+			if c.Schedules == nil {
+				c.Schedules = map[string]string{}
+			}
+			for k, v := range inc.Schedules {
+				c.Schedules[k] = v
+			}
+		}
+		if inc.ToolAliases != nil {
+			if c.ToolAliases == nil {
+				c.ToolAliases = map[string]ToolAlias{}
+			}
+			for k, v := range inc.ToolAliases {
+				c.ToolAliases[k] = v
+			}
+		}
+		if inc.ToolProbes != nil {
+			if c.ToolProbes == nil {
+				c.ToolProbes = map[string]ToolProbe{}
+			}
+			for k, v := range inc.ToolProbes {
+				c.ToolProbes[k] = v
+			}
+		}
 		if inc.Profiles != nil {
 			if c.Profiles == nil {
 				c.Profiles = map[string]BuildProfile{}
@@ -123,59 +145,70 @@ func Load(startDir string) (*Config, string, error) {
 	if c.Tasks == nil {
 		c.Tasks = TasksMap{}
 	}
+	LiftRigVersion(&c)
 	return &c, path, nil
 }
 
 // rawConfig mirrors Config but allows [tasks] values to be untyped for flexible decoding.
 type rawConfig struct {
-	Project  Project                 `toml:"project"`
-	Tasks    map[string]any          `toml:"tasks"`
-	Tools    map[string]string       `toml:"tools"`
-	Includes []string                `toml:"include"`
-	Profiles map[string]BuildProfile `toml:"profile"`
+	Project   Project                 `toml:"project"`
+	Tasks     map[string]any          `toml:"tasks"`
+	Tools     map[string]string       `toml:"tools"`
+	Includes  []any                   `toml:"include"`
+	Profiles  map[string]BuildProfile `toml:"profile"`
+	Workspace Workspace               `toml:"workspace"`
+	Requires  map[string]string       `toml:"requires"`
+	Schedules map[string]string       `toml:"schedules"`
+	Aliases   map[string]any          `toml:"tool-aliases"`
+	Probes    map[string]ToolProbe    `toml:"tool-probes"`
+	Lock      LockPolicy              `toml:"lock"`
+	Cache     CacheConfig             `toml:"cache"`
+	Paths     PathsConfig             `toml:"paths"`
+	CI        CIConfig                `toml:"ci"`
+	Plugins   map[string]Plugin       `toml:"plugins"`
 }
 
 // toTyped converts rawConfig into the strongly-typed Config using Task.fromAny parsing.
 func toTyped(r rawConfig) (Config, error) {
 	c := Config{
-		Project:  r.Project,
-		Tools:    r.Tools,
-		Includes: r.Includes,
-		Profiles: r.Profiles,
+		Project:    r.Project,
+		Tools:      r.Tools,
+		Profiles:   r.Profiles,
+		Workspace:  r.Workspace,
+		Requires:   r.Requires,
+		Schedules:  r.Schedules,
+		Lock:       r.Lock,
+		Cache:      r.Cache,
+		Paths:      r.Paths,
+		CI:         r.CI,
+		Plugins:    r.Plugins,
+		ToolProbes: r.Probes,
+	}
+	includes, err := ParseIncludeEntries(r.Includes)
+	if err != nil {
+		return Config{}, err
 	}
+	c.Includes = includes
+	aliases, err := ParseToolAliases(r.Aliases)
+	if err != nil {
+		return Config{}, err
+	}
+	c.ToolAliases = aliases
 	if len(r.Tasks) > 0 {
-		tm := make(TasksMap, len(r.Tasks))
-		for name, raw := range r.Tasks {
+		tasks, namespaced, err := FlattenTaskNamespaces(r.Tasks)
+		if err != nil {
+			return Config{}, err
+		}
+		tm := make(TasksMap, len(tasks))
+		for name, raw := range tasks {
 			var t Task
 			if err := t.fromAny(raw); err != nil {
-				return Config{}, fmt.Errorf("task %q: %w", name, err)
+				return Config{}, &TaskError{Task: name, Err: err}
 			}
+			t.Namespaced = namespaced[name]
 			tm[name] = t
 		}
 		c.Tasks = tm
 	}
 	return c, nil
 }
-
-// parseIncludeList extracts a top-level include array as []string from TOML bytes.
-func parseIncludeList(b []byte) []string {
-	// Simple, lenient single-line parser: include = ["a.toml", "b.toml"]
-	// For multi-line arrays, extend as needed.
-	s := string(b)
-	re := regexp.MustCompile(`(?m)^\s*include\s*=\s*\[([^\]]*)\]`)
-	m := re.FindStringSubmatch(s)
-	if len(m) < 2 {
-		return nil
-	}
-	inner := m[1]
-	parts := strings.Split(inner, ",")
-	var out []string
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		p = strings.Trim(p, "\"")
-		if p != "" {
-			out = append(out, p)
-		}
-	}
-	return out
-}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	toml "github.com/pelletier/go-toml/v2"
@@ -37,7 +39,7 @@ build = "go build ."
 		t.Fatal(err)
 	}
 	incList := parseIncludeList(b)
-	if len(incList) != 1 || incList[0] != "extra.toml" {
+	if len(incList) != 1 || incList[0].Path != "extra.toml" {
 		t.Fatalf("include not parsed from base: %v", incList)
 	}
 	write(t, filepath.Join(dir, "extra.toml"), `
@@ -111,7 +113,219 @@ include = ["a.toml", "b.toml"]
 		t.Fatal(err)
 	}
 	inc := parseIncludeList(data)
-	if len(inc) != 2 || inc[0] != "a.toml" || inc[1] != "b.toml" {
+	if len(inc) != 2 || inc[0].Path != "a.toml" || inc[1].Path != "b.toml" {
 		t.Fatalf("expected includes parsed, got %#v", inc)
 	}
 }
+
+func TestLoadUserConfig(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "config.toml")
+	t.Setenv("RIG_USER_CONFIG", p)
+
+	uc, path, err := LoadUserConfig()
+	if err != nil || path != p || uc.Color != "" {
+		t.Fatalf("missing file: uc=%+v path=%q err=%v", uc, path, err)
+	}
+
+	write(t, p, `
+color = "never"
+plain = true
+
+[proxy]
+goproxy = "https://proxy.example.com"
+https_proxy = "http://127.0.0.1:3128"
+
+[init]
+template = "dev, ci"
+
+[tool-aliases]
+sqlc = "github.com/sqlc-dev/sqlc"
+`)
+	uc, _, err = LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig: %v", err)
+	}
+	if uc.Color != "never" || !uc.Plain || uc.ToolAliases["sqlc"].Module != "github.com/sqlc-dev/sqlc" {
+		t.Fatalf("unexpected config: %+v", uc)
+	}
+	if got := uc.Init.Presets(); len(got) != 2 || got[0] != "dev" || got[1] != "ci" {
+		t.Fatalf("presets = %v", got)
+	}
+	env := uc.Proxy.Env()
+	if len(env) != 2 || env[0] != "GOPROXY=https://proxy.example.com" || env[1] != "HTTPS_PROXY=http://127.0.0.1:3128" {
+		t.Fatalf("proxy env = %v", env)
+	}
+
+	for _, bad := range []string{"colour = \"never\"\n", "color = \"sometimes\"\n", "[init]\ntemplate = \"web\"\n"} {
+		write(t, p, bad)
+		if _, _, err := LoadUserConfig(); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestLoad_RemoteInclude(t *testing.T) {
+	t.Setenv("RIG_CACHE_DIR", t.TempDir())
+	body := "[tasks]\nlint = \"golangci-lint run\"\n"
+	fetches := 0
+	orig := fetchRemoteInclude
+	t.Cleanup(func() { fetchRemoteInclude = orig })
+	fetchRemoteInclude = func(r RemoteInclude) ([]byte, error) {
+		fetches++
+		if r.Repo != "github.com/acme/rig-presets" || r.Path != "go-service.toml" || r.Ref != "v1" {
+			t.Fatalf("unexpected remote include %+v", r)
+		}
+		return []byte(body), nil
+	}
+
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "rig.toml"), `include = ["github.com/acme/rig-presets//go-service.toml@v1"]
+
+[tasks]
+build = "go build ."
+`)
+	c, _, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, ok := c.Tasks["lint"]; !ok {
+		t.Fatalf("expected lint task from remote include, got %v", c.Tasks)
+	}
+	if len(c.RemoteIncludes) != 1 || c.RemoteIncludes[0].SHA256 != sha256Hex([]byte(body)) {
+		t.Fatalf("unexpected RemoteIncludes: %+v", c.RemoteIncludes)
+	}
+
+	// Cached: no second fetch.
+	if _, _, err := Load(dir); err != nil || fetches != 1 {
+		t.Fatalf("expected cached include (fetches=%d, err=%v)", fetches, err)
+	}
+
+	// A pin that does not match the upstream content is an error.
+	write(t, filepath.Join(dir, "rig.lock"), "schema = 0\n\n[[includes]]\nsource = \"github.com/acme/rig-presets//go-service.toml@v1\"\nsha256 = \"0000\"\n")
+	if _, _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "changed upstream") {
+		t.Fatalf("expected pin mismatch error, got %v", err)
+	}
+
+	// Refresh mode accepts new content so sync can re-pin it.
+	RefreshRemoteIncludes = true
+	t.Cleanup(func() { RefreshRemoteIncludes = false })
+	if _, _, err := Load(dir); err != nil {
+		t.Fatalf("Load with refresh: %v", err)
+	}
+
+	for _, bad := range []string{"github.com/acme/rig-presets//go-service.toml", "acme//x.toml@v1", "github.com/acme/p//../x.toml@v1"} {
+		if _, remote, err := ParseRemoteInclude(bad); !remote || err == nil {
+			t.Fatalf("ParseRemoteInclude(%q): expected error", bad)
+		}
+	}
+	if _, remote, _ := ParseRemoteInclude("rig.tasks.toml"); remote {
+		t.Fatalf("local include parsed as remote")
+	}
+}
+
+func TestLoad_IncludeErrorReportsPosition(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "rig.toml"), "include = [\"ci.toml\"]\n\n[tasks]\nbuild = \"go build .\"\n")
+	write(t, filepath.Join(dir, "ci.toml"), "[tasks]\n\tci = { steps = [1] }\n")
+
+	_, _, err := Load(dir)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	var se *SourceError
+	if !errors.As(err, &se) || filepath.Base(se.Path) != "ci.toml" || se.Line != 2 || se.Column != 9 {
+		t.Fatalf("expected ci.toml:2:9, got %#v (%v)", se, err)
+	}
+	want := "2 | \tci = { steps = [1] }\n  | \t       ^"
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected caret snippet %q, got:\n%v", want, err)
+	}
+}
+
+func TestLoad_NestedIncludesCyclesAndOptional(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "rig.toml"), `include = ["shared/tasks.toml", { path = "local.toml", optional = true }]
+
+[tasks]
+build = "go build ."
+`)
+	write(t, filepath.Join(dir, "shared", "tasks.toml"), `include = ["tools.toml"]
+
+[tasks]
+lint = "golangci-lint run"
+`)
+	write(t, filepath.Join(dir, "shared", "tools.toml"), `[tools]
+golangci-lint = "1.62.0"
+`)
+
+	c, _, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if c.Tools["golangci-lint"] != "1.62.0" {
+		t.Fatalf("expected nested include tools merged, got %#v", c.Tools)
+	}
+	if c.Tasks["lint"].Command != "golangci-lint run" {
+		t.Fatalf("expected nested include tasks merged, got %#v", c.Tasks)
+	}
+
+	write(t, filepath.Join(dir, "shared", "tools.toml"), `include = ["../rig.toml"]`)
+	if _, _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "include cycle:") || !strings.Contains(err.Error(), "tools.toml -> ") {
+		t.Fatalf("expected include cycle error, got %v", err)
+	}
+
+	write(t, filepath.Join(dir, "shared", "tools.toml"), `include = ["missing.toml"]`)
+	if _, _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "missing.toml not found") || !strings.Contains(err.Error(), "optional = true") {
+		t.Fatalf("expected missing include error, got %v", err)
+	}
+
+	write(t, filepath.Join(dir, "shared", "tools.toml"), `include = [{ path = "missing.toml", optional = true }]`)
+	if _, _, err := Load(dir); err != nil {
+		t.Fatalf("optional missing include should be skipped: %v", err)
+	}
+}
+
+func TestLoad_IncludeConflicts(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "rig.toml"), `include = ["a.toml", "b.toml"]
+
+[tasks]
+build = "go build ."
+`)
+	write(t, filepath.Join(dir, "a.toml"), `[tasks]
+lint = "go vet ./..."
+
+[profile.release]
+ldflags = "-s"
+`)
+	write(t, filepath.Join(dir, "b.toml"), `[tasks]
+lint = "golangci-lint run"
+
+[profile.release]
+ldflags = "-s -w"
+`)
+	t.Chdir(dir)
+
+	_, _, err := Load(dir)
+	if err == nil {
+		t.Fatal("expected conflict error")
+	}
+	for _, want := range []string{"--allow-override", "tasks.lint is defined in both a.toml and b.toml", "profile.release is defined in both a.toml and b.toml"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in error, got:\n%v", want, err)
+		}
+	}
+
+	AllowIncludeOverride = true
+	t.Cleanup(func() { AllowIncludeOverride = false })
+	c, _, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load with overrides allowed: %v", err)
+	}
+	if c.Tasks["lint"].Command != "golangci-lint run" || c.Profiles["release"].Ldflags != "-s -w" {
+		t.Fatalf("expected the later file to win, got %#v %#v", c.Tasks["lint"], c.Profiles["release"])
+	}
+	if len(c.IncludeOverrides) != 2 || c.IncludeOverrides[1].Key != "tasks.lint" {
+		t.Fatalf("expected overrides recorded, got %#v", c.IncludeOverrides)
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// RemoteInclude is an include entry that names a file in a versioned
+// repository: host/owner/repo//path/to/file.toml@ref.
+type RemoteInclude struct {
+	Source string
+	Repo   string
+	Path   string
+	Ref    string
+}
+
+// IncludePin records the content hash of a remote include in rig.lock.
+type IncludePin struct {
+	Source string `toml:"source" json:"source"`
+	SHA256 string `toml:"sha256" json:"sha256"`
+}
+
+// RefreshRemoteIncludes makes loaders refetch remote includes and accept new
+// content instead of enforcing rig.lock pins. `rig sync` sets it to re-pin.
+var RefreshRemoteIncludes bool
+
+// ParseRemoteInclude reports whether s is a remote include and parses it.
+// Local paths never contain "//".
+func ParseRemoteInclude(s string) (RemoteInclude, bool, error) {
+	s = strings.TrimSpace(s)
+	repo, rest, ok := strings.Cut(s, "//")
+	if !ok || filepath.IsAbs(s) || strings.Contains(repo, ":") {
+		return RemoteInclude{}, false, nil
+	}
+	r := RemoteInclude{Source: s, Repo: strings.Trim(repo, "/")}
+	i := strings.LastIndex(rest, "@")
+	if i < 0 || i == len(rest)-1 {
+		return RemoteInclude{}, true, fmt.Errorf("remote include %q: missing version (expected host/owner/repo//file.toml@ref)", s)
+	}
+	r.Path, r.Ref = strings.Trim(rest[:i], "/"), rest[i+1:]
+	parts := strings.Split(r.Repo, "/")
+	if len(parts) < 3 || !strings.Contains(parts[0], ".") || r.Path == "" {
+		return RemoteInclude{}, true, fmt.Errorf("remote include %q: expected host/owner/repo//file.toml@ref", s)
+	}
+	for _, el := range strings.Split(r.Path, "/") {
+		if el == ".." {
+			return RemoteInclude{}, true, fmt.Errorf("remote include %q: path must not contain ..", s)
+		}
+	}
+	return r, true, nil
+}
+
+// URL returns the raw-file download URL for the include.
+func (r RemoteInclude) URL() string {
+	host, repo, _ := strings.Cut(r.Repo, "/")
+	if host == "github.com" {
+		return "https://raw.githubusercontent.com/" + repo + "/" + r.Ref + "/" + r.Path
+	}
+	// GitLab, Gitea, and Forgejo serve raw files under /raw/<ref>/.
+	return "https://" + r.Repo + "/raw/" + r.Ref + "/" + r.Path
+}
+
+// RemoteIncludeCacheDir returns where fetched remote includes are cached.
+func RemoteIncludeCacheDir() (string, error) {
+	dir, err := UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "includes"), nil
+}
+
+// IncludePins reads the remote include pins from the rig.lock next to
+// configPath. A missing or unreadable lock yields no pins.
+func IncludePins(configPath string) map[string]string {
+	b, err := os.ReadFile(filepath.Join(filepath.Dir(configPath), "rig.lock"))
+	if err != nil {
+		return nil
+	}
+	var lock struct {
+		Includes []IncludePin `toml:"includes"`
+	}
+	if err := toml.Unmarshal(b, &lock); err != nil {
+		return nil
+	}
+	pins := make(map[string]string, len(lock.Includes))
+	for _, p := range lock.Includes {
+		pins[p.Source] = p.SHA256
+	}
+	return pins
+}
+
+// ReadInclude returns the contents of one include entry. Local paths resolve
+// relative to baseDir (then baseDir/.rig); a missing local include returns nil
+// data. Remote includes come from the cache or are fetched, and must match
+// their pin in pins when one exists; pin reports the hash that was used.
+func ReadInclude(baseDir, entry string, pins map[string]string) (data []byte, name string, pin *IncludePin, err error) {
+	r, remote, err := ParseRemoteInclude(entry)
+	if err != nil {
+		return nil, entry, nil, err
+	}
+	if remote {
+		data, sum, err := loadRemoteInclude(r, pins[r.Source])
+		if err != nil {
+			return nil, entry, nil, err
+		}
+		return data, entry, &IncludePin{Source: r.Source, SHA256: sum}, nil
+	}
+
+	incPath := entry
+	if !filepath.IsAbs(incPath) {
+		incPath = filepath.Join(baseDir, entry)
+	}
+	if _, err := os.Stat(incPath); err != nil {
+		alt := filepath.Join(baseDir, ".rig", entry)
+		if _, err2 := os.Stat(alt); err2 != nil {
+			return nil, incPath, nil, nil // skip missing include
+		}
+		incPath = alt
+	}
+	data, err = os.ReadFile(incPath)
+	if err != nil {
+		return nil, incPath, nil, fmt.Errorf("read include %s: %w", incPath, err)
+	}
+	return data, incPath, nil, nil
+}
+
+func loadRemoteInclude(r RemoteInclude, pin string) ([]byte, string, error) {
+	dir, err := RemoteIncludeCacheDir()
+	if err != nil {
+		return nil, "", err
+	}
+	key := sha256.Sum256([]byte(r.Source))
+	cachePath := filepath.Join(dir, hex.EncodeToString(key[:])+".toml")
+
+	if !RefreshRemoteIncludes {
+		if b, err := os.ReadFile(cachePath); err == nil {
+			if sum := sha256Hex(b); pin == "" || sum == pin {
+				return b, sum, nil
+			}
+		}
+	}
+	b, err := fetchRemoteInclude(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch remote include %s: %w", r.Source, err)
+	}
+	sum := sha256Hex(b)
+	if pin != "" && sum != pin && !RefreshRemoteIncludes {
+		return nil, "", fmt.Errorf("remote include %s changed upstream: sha256 %s, rig.lock pins %s (run 'rig sync' to re-pin)", r.Source, sum, pin)
+	}
+	if err := os.MkdirAll(dir, 0o755); err == nil {
+		tmp := cachePath + ".tmp"
+		if err := os.WriteFile(tmp, b, 0o644); err == nil {
+			_ = os.Rename(tmp, cachePath)
+		}
+	}
+	return b, sum, nil
+}
+
+var fetchRemoteInclude = httpFetchRemoteInclude
+
+func httpFetchRemoteInclude(r RemoteInclude) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(r.URL())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("request failed (%d) for %s", resp.StatusCode, r.URL())
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty response")
+	}
+	return b, nil
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// UserConfig holds user-level defaults read from ~/.config/rig/config.toml
+// (<UserConfigDir>/rig/config.toml). Project rig.toml settings and explicit
+// flags take precedence.
+type UserConfig struct {
+	// Color is the default --color mode: auto|always|never.
+	Color string `toml:"color" json:"color,omitempty"`
+	// Plain drops color and emoji from styled output.
+	Plain bool `toml:"plain" json:"plain,omitempty"`
+	// Accessible is the default for --accessible: screen-reader friendly
+	// output with status words instead of symbols, no color, and numbered
+	// prompts.
+	Accessible bool       `toml:"accessible" json:"accessible,omitempty"`
+	Proxy      UserProxy  `toml:"proxy" json:"proxy"`
+	Init       UserInit   `toml:"init" json:"init"`
+	Notify     UserNotify `toml:"notify" json:"notify"`
+	Guard      UserGuard  `toml:"guard" json:"guard"`
+	Lock       UserLock   `toml:"lock" json:"lock"`
+	// ToolAliases are layered below the project's [tool-aliases].
+	ToolAliases map[string]ToolAlias `toml:"-" json:"toolAliases,omitempty"`
+}
+
+// UserProxy sets module and network proxy variables for commands rig runs.
+// Variables already present in the environment win.
+type UserProxy struct {
+	GoProxy    string `toml:"goproxy" json:"goproxy,omitempty"`
+	GoPrivate  string `toml:"goprivate" json:"goprivate,omitempty"`
+	GoNoSumDB  string `toml:"gonosumdb" json:"gonosumdb,omitempty"`
+	GoSumDB    string `toml:"gosumdb" json:"gosumdb,omitempty"`
+	HTTPProxy  string `toml:"http_proxy" json:"http_proxy,omitempty"`
+	HTTPSProxy string `toml:"https_proxy" json:"https_proxy,omitempty"`
+	NoProxy    string `toml:"no_proxy" json:"no_proxy,omitempty"`
+}
+
+// Env returns the configured proxy settings as KEY=VALUE entries.
+func (p UserProxy) Env() []string {
+	var out []string
+	for _, kv := range [][2]string{
+		{"GOPROXY", p.GoProxy},
+		{"GOPRIVATE", p.GoPrivate},
+		{"GONOSUMDB", p.GoNoSumDB},
+		{"GOSUMDB", p.GoSumDB},
+		{"HTTP_PROXY", p.HTTPProxy},
+		{"HTTPS_PROXY", p.HTTPSProxy},
+		{"NO_PROXY", p.NoProxy},
+	} {
+		if v := strings.TrimSpace(kv[1]); v != "" {
+			out = append(out, kv[0]+"="+v)
+		}
+	}
+	return out
+}
+
+// UserInit holds defaults for `rig init`.
+type UserInit struct {
+	// Template is a comma-separated list of init presets: app, dev, ci, minimal, monorepo.
+	Template string `toml:"template" json:"template,omitempty"`
+	License  string `toml:"license" json:"license,omitempty"`
+}
+
+// Presets splits Template into its presets.
+func (i UserInit) Presets() []string {
+	var out []string
+	for _, p := range strings.Split(i.Template, ",") {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// UserNotify controls background notifications such as update checks.
+type UserNotify struct {
+	// UpdateCheck opts into the daily new-release notice (default false).
+	UpdateCheck *bool `toml:"update_check" json:"update_check,omitempty"`
+	// Interval is the minimum time between update checks, e.g. "24h".
+	Interval string `toml:"interval" json:"interval,omitempty"`
+}
+
+// UserGuard tunes the warnings rig prints before a command runs.
+type UserGuard struct {
+	// AllowRoot silences the warning for running as root in a project owned
+	// by another user.
+	AllowRoot bool `toml:"allow_root" json:"allow_root,omitempty"`
+	// MaxConfigDepth is how many directories above the working directory
+	// rig.toml may be before rig warns; 0 disables the warning. Unset means
+	// the default.
+	MaxConfigDepth *int `toml:"max_config_depth" json:"max_config_depth,omitempty"`
+}
+
+// UserLock pins rig.lock signature checking outside the repository, whose
+// own [lock] can be edited by anyone who can edit rig.lock.
+type UserLock struct {
+	// Signature requires a rig.lock signature from this signer in every
+	// project, even those without [lock].
+	Signature string `toml:"signature" json:"signature,omitempty"`
+	// PublicKey is the trusted verification key, used instead of the
+	// project's [lock].public_key. Relative paths resolve against the user
+	// config file.
+	PublicKey string `toml:"public_key" json:"public_key,omitempty"`
+}
+
+// UserCacheDir returns rig's user cache directory, <UserCacheDir>/rig, which
+// holds remote includes, cached rig versions, and release checks.
+// RIG_CACHE_DIR overrides it.
+func UserCacheDir() (string, error) {
+	if d := strings.TrimSpace(os.Getenv("RIG_CACHE_DIR")); d != "" {
+		return d, nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate user cache dir: %w", err)
+	}
+	return filepath.Join(base, "rig"), nil
+}
+
+// UserConfigPath returns the user config file. RIG_USER_CONFIG overrides the
+// default location.
+func UserConfigPath() (string, error) {
+	if p := strings.TrimSpace(os.Getenv("RIG_USER_CONFIG")); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rig", "config.toml"), nil
+}
+
+// LoadUserConfig reads the user config file. A missing file yields the zero
+// UserConfig; unknown keys are rejected so typos do not go unnoticed.
+func LoadUserConfig() (UserConfig, string, error) {
+	path, err := UserConfigPath()
+	if err != nil {
+		return UserConfig{}, "", nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return UserConfig{}, path, nil
+		}
+		return UserConfig{}, path, err
+	}
+	var raw struct {
+		UserConfig
+		Aliases map[string]any `toml:"tool-aliases"`
+	}
+	dec := toml.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&raw); err != nil {
+		var strict *toml.StrictMissingError
+		if errors.As(err, &strict) {
+			return UserConfig{}, path, fmt.Errorf("parse %s: unknown key(s):\n%s", path, strict.String())
+		}
+		return UserConfig{}, path, fmt.Errorf("parse %s: %w", path, err)
+	}
+	uc := raw.UserConfig
+	if uc.ToolAliases, err = ParseToolAliases(raw.Aliases); err != nil {
+		return UserConfig{}, path, fmt.Errorf("%s: %w", path, err)
+	}
+	switch strings.TrimSpace(uc.Color) {
+	case "", "auto", "always", "never":
+	default:
+		return UserConfig{}, path, fmt.Errorf("%s: invalid color %q (expected auto|always|never)", path, uc.Color)
+	}
+	for _, p := range uc.Init.Presets() {
+		switch p {
+		case "app", "dev", "ci", "minimal", "monorepo":
+		default:
+			return UserConfig{}, path, fmt.Errorf("%s: invalid init.template preset %q (expected app|dev|ci|minimal|monorepo)", path, p)
+		}
+	}
+	if iv := strings.TrimSpace(uc.Notify.Interval); iv != "" {
+		if d, err := time.ParseDuration(iv); err != nil || d <= 0 {
+			return UserConfig{}, path, fmt.Errorf("%s: invalid notify.interval %q (expected a positive duration like \"24h\")", path, uc.Notify.Interval)
+		}
+	}
+	if d := uc.Guard.MaxConfigDepth; d != nil && *d < 0 {
+		return UserConfig{}, path, fmt.Errorf("%s: invalid guard.max_config_depth %d (expected 0 or more)", path, *d)
+	}
+	return uc, path, nil
+}
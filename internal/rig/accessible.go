@@ -0,0 +1,56 @@
+package rig
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Accessible makes rig's human output screen-reader friendly: status
+// symbols become words (OK, FAIL, WARN, SKIP) and decorative symbols are
+// dropped. The CLI sets it from --accessible or the user config, and then
+// also turns off color and redrawn prompts.
+var Accessible bool
+
+// statusWords are the leading symbols that carry a status, and the word
+// accessible output uses instead.
+var statusWords = []struct{ symbol, word string }{
+	{"✅", "OK"},
+	{"✓", "OK"},
+	{"❌", "FAIL"},
+	{"✗", "FAIL"},
+	{"⚠️", "WARN"},
+	{"⏭️", "SKIP"},
+	{"ℹ️", "INFO"},
+	{"💡", "NOTE"},
+}
+
+// StatusText returns msg as accessible output prints it: a leading status
+// symbol becomes its word ("✗ build (1.2s)" -> "FAIL: build (1.2s)") and any
+// other leading symbol is dropped ("🔒 gen ran isolated" -> "gen ran
+// isolated"). Indentation is kept. Without Accessible msg is unchanged.
+func StatusText(msg string) string {
+	if !Accessible {
+		return msg
+	}
+	rest := strings.TrimLeft(msg, " ")
+	indent := msg[:len(msg)-len(rest)]
+	for _, s := range statusWords {
+		if r, ok := strings.CutPrefix(rest, s.symbol); ok {
+			return indent + s.word + ": " + strings.TrimLeft(r, " ️")
+		}
+	}
+	if r, _ := utf8.DecodeRuneInString(rest); r > unicode.MaxASCII && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		if _, after, ok := strings.Cut(rest, " "); ok {
+			return indent + strings.TrimLeft(after, " ")
+		}
+	}
+	return msg
+}
+
+// statusf prints one status line to w through StatusText.
+func statusf(w io.Writer, format string, args ...any) {
+	fmt.Fprintln(w, StatusText(fmt.Sprintf(format, args...)))
+}
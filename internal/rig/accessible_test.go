@@ -0,0 +1,30 @@
+package rig
+
+import "testing"
+
+func TestStatusText(t *testing.T) {
+	old := Accessible
+	t.Cleanup(func() { Accessible = old })
+
+	Accessible = false
+	if got := StatusText("✓ build (1.2s)"); got != "✓ build (1.2s)" {
+		t.Fatalf("StatusText without Accessible = %q", got)
+	}
+
+	Accessible = true
+	for in, want := range map[string]string{
+		"✓ build (1.2s)":                    "OK: build (1.2s)",
+		"✗ build (1.2s)":                    "FAIL: build (1.2s)",
+		"⚠️  max_memory=1G not enforced: x": "WARN: max_memory=1G not enforced: x",
+		"⏭️  deploy skipped (build failed)": "SKIP: deploy skipped (build failed)",
+		"🔒 gen ran isolated":                "gen ran isolated",
+		"♻️  gen restored from cache":       "gen restored from cache",
+		"  ✗ not a number":                  "  FAIL: not a number",
+		"building 3 tools":                  "building 3 tools",
+		"[gen] ✓ inside a line":             "[gen] ✓ inside a line",
+	} {
+		if got := StatusText(in); got != want {
+			t.Errorf("StatusText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,482 @@
+package rig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProjectScan is what `rig init --from` learned about an existing repository.
+// Paths are slash-separated and relative to the scanned directory.
+type ProjectScan struct {
+	Module    string
+	GoVersion string
+	// Mains are the main packages, e.g. "." or "./cmd/api".
+	Mains       []string
+	HasTests    bool
+	Dockerfiles []string
+	CIFiles     []string
+	// LinterConfigs maps a tool short name to the config file that implies it.
+	LinterConfigs map[string]string
+	// Tools maps [tools] keys to versions ("latest" when none was found).
+	Tools map[string]string
+}
+
+// GoMod holds the go.mod directives rig reads.
+type GoMod struct {
+	Module    string
+	Go        string
+	Toolchain string
+	Require   map[string]string
+	Tool      []string
+}
+
+// ParseGoMod reads the module, go, toolchain, require, and tool directives
+// from a go.mod file. Single-line and block forms are supported.
+func ParseGoMod(b []byte) (GoMod, error) {
+	m := GoMod{Require: map[string]string{}}
+	block := ""
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := sc.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			fields = append([]string{block}, fields...)
+		} else if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			if len(fields) != 2 {
+				return GoMod{}, fmt.Errorf("go.mod:%d: malformed module directive", lineNo)
+			}
+			m.Module = unquoteGoWork(fields[1])
+		case "go":
+			m.Go = fields[len(fields)-1]
+		case "toolchain":
+			m.Toolchain = fields[len(fields)-1]
+		case "require":
+			if len(fields) < 3 {
+				return GoMod{}, fmt.Errorf("go.mod:%d: malformed require directive", lineNo)
+			}
+			m.Require[unquoteGoWork(fields[1])] = fields[2]
+		case "tool":
+			if len(fields) != 2 {
+				return GoMod{}, fmt.Errorf("go.mod:%d: malformed tool directive", lineNo)
+			}
+			m.Tool = append(m.Tool, unquoteGoWork(fields[1]))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return GoMod{}, err
+	}
+	if block != "" {
+		return GoMod{}, fmt.Errorf("go.mod: unterminated %s block", block)
+	}
+	return m, nil
+}
+
+// Version returns the required version of the module providing pkg.
+func (m GoMod) Version(pkg string) string {
+	best := ""
+	for mod := range m.Require {
+		if (pkg == mod || strings.HasPrefix(pkg, mod+"/")) && len(mod) > len(best) {
+			best = mod
+		}
+	}
+	return m.Require[best]
+}
+
+// linterConfigs maps config files to the tools they configure.
+var linterConfigs = []struct{ file, tool string }{
+	{".golangci.yml", "golangci-lint"},
+	{".golangci.yaml", "golangci-lint"},
+	{".golangci.toml", "golangci-lint"},
+	{".golangci.json", "golangci-lint"},
+	{"staticcheck.conf", "staticcheck"},
+	{"revive.toml", "revive"},
+	{".revive.toml", "revive"},
+	{".air.toml", "air"},
+	{".mockery.yaml", "mockery"},
+	{".mockery.yml", "mockery"},
+}
+
+var ciConfigs = []string{".gitlab-ci.yml", ".circleci/config.yml", "azure-pipelines.yml", "Jenkinsfile", ".travis.yml"}
+
+// ScanProject inspects dir for main packages, tests, Dockerfiles, CI files,
+// linter configs, and tools (go.mod tool directives, tools.go imports, and
+// linters implied by their config files).
+func ScanProject(dir string) (ProjectScan, error) {
+	s := ProjectScan{LinterConfigs: map[string]string{}, Tools: map[string]string{}}
+	var mod GoMod
+	if b, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		if mod, err = ParseGoMod(b); err != nil {
+			return ProjectScan{}, err
+		}
+		s.Module = mod.Module
+		s.GoVersion = strings.TrimPrefix(firstNonEmptyString(mod.Toolchain, mod.Go), "go")
+	} else if !os.IsNotExist(err) {
+		return ProjectScan{}, fmt.Errorf("read go.mod: %w", err)
+	}
+
+	var toolImports []string
+	mains := map[string]bool{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		rel = filepath.ToSlash(rel)
+		name := d.Name()
+		if d.IsDir() {
+			if rel != "." && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if name == "Dockerfile" || strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile") {
+			s.Dockerfiles = append(s.Dockerfiles, rel)
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		if strings.HasSuffix(name, "_test.go") {
+			s.HasTests = true
+			return nil
+		}
+		f, perr := parser.ParseFile(token.NewFileSet(), p, nil, parser.ImportsOnly|parser.ParseComments)
+		if perr != nil {
+			return nil
+		}
+		if isToolsFile(f.Comments) {
+			for _, imp := range f.Imports {
+				if v, err := strconv.Unquote(imp.Path.Value); err == nil {
+					toolImports = append(toolImports, v)
+				}
+			}
+			return nil
+		}
+		if f.Name.Name == "main" {
+			pkg := path.Dir(rel)
+			if pkg != "." {
+				pkg = "./" + pkg
+			}
+			mains[pkg] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return ProjectScan{}, err
+	}
+	for pkg := range mains {
+		s.Mains = append(s.Mains, pkg)
+	}
+	sort.Strings(s.Mains)
+	sort.Strings(s.Dockerfiles)
+
+	if wf, _ := filepath.Glob(filepath.Join(dir, ".github", "workflows", "*.y*ml")); len(wf) > 0 {
+		for _, p := range wf {
+			rel, _ := filepath.Rel(dir, p)
+			s.CIFiles = append(s.CIFiles, filepath.ToSlash(rel))
+		}
+	}
+	for _, f := range ciConfigs {
+		if fileExists(filepath.Join(dir, filepath.FromSlash(f))) {
+			s.CIFiles = append(s.CIFiles, f)
+		}
+	}
+	sort.Strings(s.CIFiles)
+
+	for _, lc := range linterConfigs {
+		if _, seen := s.LinterConfigs[lc.tool]; !seen && fileExists(filepath.Join(dir, lc.file)) {
+			s.LinterConfigs[lc.tool] = lc.file
+		}
+	}
+
+	for _, pkg := range append(mod.Tool, toolImports...) {
+		s.Tools[toolKeyForInstallPath(pkg)] = NormalizeToolVersion(firstNonEmptyString(mod.Version(pkg), "latest"))
+	}
+	for tool := range s.LinterConfigs {
+		if _, ok := s.Tools[tool]; !ok {
+			s.Tools[tool] = firstNonEmptyString(installedToolVersion(ResolveToolIdentity(tool).Bin), "latest")
+		}
+	}
+	return s, nil
+}
+
+// isToolsFile reports whether a file is guarded by the conventional
+// `//go:build tools` constraint used to pin tool dependencies.
+func isToolsFile(groups []*ast.CommentGroup) bool {
+	for _, g := range groups {
+		for _, c := range g.List {
+			t := strings.TrimSpace(c.Text)
+			if t == "//go:build tools" || t == "// +build tools" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toolKeyForInstallPath prefers a known short name for an install path.
+func toolKeyForInstallPath(p string) string {
+	for name, id := range ToolShortNameMap {
+		if id.InstallPath == p {
+			return name
+		}
+	}
+	return p
+}
+
+// installedToolVersion reads the module version embedded in bin when it is
+// on PATH, or "" when unknown.
+func installedToolVersion(bin string) string {
+	exe, err := exec.LookPath(bin)
+	if err != nil {
+		return ""
+	}
+	out, err := execCapture("go", []string{"version", "-m", exe}, "", nil)
+	if err != nil {
+		return ""
+	}
+	for line := range strings.SplitSeq(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "mod" && fields[2] != "(devel)" {
+			return NormalizeToolVersion(fields[2])
+		}
+	}
+	return ""
+}
+
+func fileExists(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && !info.IsDir()
+}
+
+// mainBinName names the binary built from a main package.
+func mainBinName(pkg, project string) string {
+	if pkg == "." {
+		return project
+	}
+	return path.Base(pkg)
+}
+
+// Manifest renders a rig.toml proposal for the scanned project.
+func (s ProjectScan) Manifest(name, version, license string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[project]\nname = %q\nversion = %q\nlicense = %q\n", name, version, license)
+
+	b.WriteString("\n[tasks]\n")
+	var buildSteps []string
+	if len(s.Mains) == 1 {
+		fmt.Fprintf(&b, "build = \"go build -o bin/%s %s\"\n", mainBinName(s.Mains[0], name), s.Mains[0])
+		fmt.Fprintf(&b, "run = \"go run %s\"\n", s.Mains[0])
+	} else {
+		for _, pkg := range s.Mains {
+			bin := mainBinName(pkg, name)
+			fmt.Fprintf(&b, "%s = \"go build -o bin/%s %s\"\n", tomlKey("build-"+bin), bin, pkg)
+			buildSteps = append(buildSteps, "build-"+bin)
+		}
+		if len(buildSteps) > 0 {
+			fmt.Fprintf(&b, "build = { steps = [%s], mode = \"parallel\" }\n", quoteList(buildSteps))
+		} else {
+			b.WriteString("build = \"go build ./...\"\n")
+		}
+	}
+	ci := []string{}
+	if s.HasTests {
+		b.WriteString("test = \"go test ./...\"\n")
+		ci = append(ci, "test")
+	}
+	switch {
+	case s.LinterConfigs["golangci-lint"] != "":
+		b.WriteString("lint = \"golangci-lint run\"\n")
+	case s.LinterConfigs["staticcheck"] != "":
+		b.WriteString("lint = \"staticcheck ./...\"\n")
+	case s.LinterConfigs["revive"] != "":
+		fmt.Fprintf(&b, "lint = \"revive -config %s ./...\"\n", s.LinterConfigs["revive"])
+	default:
+		b.WriteString("lint = \"go vet ./...\"\n")
+	}
+	ci = append([]string{"lint"}, ci...)
+	ci = append(ci, "build")
+	for _, df := range s.Dockerfiles {
+		task := "docker-build"
+		if df != "Dockerfile" {
+			task += "-" + strings.NewReplacer("/", "-", ".", "-").Replace(strings.TrimSuffix(strings.TrimPrefix(df, "Dockerfile."), ".Dockerfile"))
+		}
+		fmt.Fprintf(&b, "%s = \"docker build -f %s -t %s .\"\n", tomlKey(task), df, name)
+	}
+	if len(s.CIFiles) > 0 {
+		fmt.Fprintf(&b, "ci = { steps = [%s], description = %q }\n", quoteList(ci), "mirrors "+strings.Join(s.CIFiles, ", "))
+	}
+
+	b.WriteString("\n[tools]\n")
+	goVersion := s.GoVersion
+	if goVersion != "" {
+		fmt.Fprintf(&b, "go = %q\n", goVersion)
+	}
+	tools := make([]string, 0, len(s.Tools))
+	for t := range s.Tools {
+		tools = append(tools, t)
+	}
+	sort.Strings(tools)
+	for _, t := range tools {
+		fmt.Fprintf(&b, "%s = %q\n", tomlKey(t), s.Tools[t])
+	}
+
+	if len(s.Dockerfiles) > 0 {
+		b.WriteString("\n[requires]\ndocker = \"*\"\n")
+	}
+	return b.String()
+}
+
+// tomlKey quotes k unless it is a valid bare TOML key.
+func tomlKey(k string) string {
+	for _, r := range k {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return strconv.Quote(k)
+		}
+	}
+	return k
+}
+
+func quoteList(items []string) string {
+	q := make([]string, len(items))
+	for i, it := range items {
+		q[i] = strconv.Quote(it)
+	}
+	return strings.Join(q, ", ")
+}
+
+// DiffLines returns a line diff from old to new: unchanged lines are
+// prefixed with "  ", removed lines with "- ", and added lines with "+ ".
+func DiffLines(old, new string) []string {
+	a := splitLines(old)
+	b := splitLines(new)
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var out []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		out = append(out, "+ "+b[j])
+	}
+	return out
+}
+
+// UnifiedDiff renders the change from old to new as a unified diff
+// (`diff -u` style) with context lines around each hunk, labelled oldName
+// and newName. It is empty when the contents are equal.
+func UnifiedDiff(oldName, newName, old, new string, context int) []string {
+	lines := DiffLines(old, new)
+	changed := make([]int, 0, len(lines))
+	for i, l := range lines {
+		if l[0] != ' ' {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	out := []string{"--- " + oldName, "+++ " + newName}
+	// oldAt[i] and newAt[i] are the 1-based line numbers before lines[i].
+	oldAt, newAt := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	oldAt[0], newAt[0] = 1, 1
+	for i, l := range lines {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+		if l[0] != '+' {
+			oldAt[i+1]++
+		}
+		if l[0] != '-' {
+			newAt[i+1]++
+		}
+	}
+	for k := 0; k < len(changed); {
+		start := max(changed[k]-context, 0)
+		end := changed[k]
+		for k < len(changed) && changed[k]-end <= 2*context {
+			end = changed[k]
+			k++
+		}
+		end = min(end+context+1, len(lines))
+		oldN, newN := oldAt[end]-oldAt[start], newAt[end]-newAt[start]
+		out = append(out, fmt.Sprintf("@@ -%s +%s @@", hunkRange(oldAt[start], oldN), hunkRange(newAt[start], newN)))
+		for _, l := range lines[start:end] {
+			out = append(out, l[:1]+l[2:])
+		}
+	}
+	return out
+}
+
+// hunkRange formats a unified diff range; an empty range names the line
+// before it, as diff does.
+func hunkRange(at, n int) string {
+	switch n {
+	case 0:
+		return fmt.Sprintf("%d,0", at-1)
+	case 1:
+		return fmt.Sprintf("%d", at)
+	}
+	return fmt.Sprintf("%d,%d", at, n)
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,107 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanProjectProposesManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "go.mod"), `module example.com/shop
+
+go 1.23
+
+toolchain go1.23.4
+
+require (
+	github.com/golangci/golangci-lint v1.62.0 // indirect
+	gotest.tools/gotestsum v1.12.0
+)
+
+tool gotest.tools/gotestsum
+`, 0o644)
+	writeTestFile(t, filepath.Join(dir, "cmd", "api", "main.go"), "package main\n\nfunc main() {}\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "cmd", "worker", "main.go"), "package main\n\nfunc main() {}\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "internal", "cart", "cart.go"), "package cart\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "internal", "cart", "cart_test.go"), "package cart\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "tools", "tools.go"), "//go:build tools\n\npackage tools\n\nimport _ \"github.com/golangci/golangci-lint/cmd/golangci-lint\"\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "vendor", "x", "main.go"), "package main\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "Dockerfile"), "FROM scratch\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, ".github", "workflows", "ci.yml"), "on: push\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, ".golangci.yml"), "linters: {}\n", 0o644)
+
+	s, err := ScanProject(dir)
+	if err != nil {
+		t.Fatalf("ScanProject: %v", err)
+	}
+	if want := []string{"./cmd/api", "./cmd/worker"}; !reflect.DeepEqual(s.Mains, want) {
+		t.Fatalf("mains = %v, want %v", s.Mains, want)
+	}
+	if !s.HasTests || s.GoVersion != "1.23.4" || s.Module != "example.com/shop" {
+		t.Fatalf("unexpected scan: %+v", s)
+	}
+	if !reflect.DeepEqual(s.Dockerfiles, []string{"Dockerfile"}) || !reflect.DeepEqual(s.CIFiles, []string{".github/workflows/ci.yml"}) {
+		t.Fatalf("unexpected files: docker=%v ci=%v", s.Dockerfiles, s.CIFiles)
+	}
+	if want := map[string]string{"golangci-lint": "1.62.0", "gotestsum": "1.12.0"}; !reflect.DeepEqual(s.Tools, want) {
+		t.Fatalf("tools = %v, want %v", s.Tools, want)
+	}
+
+	manifest := s.Manifest("shop", "0.1.0", "MIT")
+	for _, want := range []string{
+		`build-api = "go build -o bin/api ./cmd/api"`,
+		`build = { steps = ["build-api", "build-worker"], mode = "parallel" }`,
+		`test = "go test ./..."`,
+		`lint = "golangci-lint run"`,
+		`docker-build = "docker build -f Dockerfile -t shop ."`,
+		`ci = { steps = ["lint", "test", "build"], description = "mirrors .github/workflows/ci.yml" }`,
+		`go = "1.23.4"`,
+		`golangci-lint = "1.62.0"`,
+		"[requires]\ndocker = \"*\"",
+	} {
+		if !strings.Contains(manifest, want) {
+			t.Fatalf("manifest missing %q:\n%s", want, manifest)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "rig.toml"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	conf, _, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("proposed manifest does not load: %v\n%s", err, manifest)
+	}
+	if len(conf.Tasks["build"].Steps) != 2 {
+		t.Fatalf("expected composite build task, got %+v", conf.Tasks["build"])
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	got := DiffLines("a\nb\nc\n", "a\nc\nd\n")
+	want := []string{"  a", "- b", "  c", "+ d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiffLines = %q, want %q", got, want)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	new := "a\nb\nC\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	got := UnifiedDiff("rig.toml", "rig.toml (new)", old, new, 1)
+	want := []string{"--- rig.toml", "+++ rig.toml (new)", "@@ -2,3 +2,3 @@", " b", "-c", "+C", " d", "@@ -10 +10,2 @@", " j", "+k"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("UnifiedDiff =\n%q\nwant\n%q", got, want)
+	}
+	if got := UnifiedDiff("a", "b", old, new, 3); len(got) != 15 || got[2] != "@@ -1,6 +1,6 @@" || got[10] != "@@ -8,3 +8,4 @@" {
+		t.Fatalf("context 3: %q", got)
+	}
+	if got := UnifiedDiff("a", "b", "", "x\n", 3); got[2] != "@@ -0,0 +1 @@" {
+		t.Fatalf("new file hunk: %q", got)
+	}
+	if UnifiedDiff("a", "b", old, old, 3) != nil {
+		t.Fatalf("equal contents should have no diff")
+	}
+}
@@ -3,7 +3,11 @@
 package rig
 
 import (
+	"fmt"
+	"maps"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	cfg "github.com/divijg19/rig/internal/config"
@@ -17,11 +21,51 @@ type BuildOverrides struct {
 	Gcflags string
 }
 
+// LookupProfile returns [profile.<name>] from conf. An empty name selects the
+// zero profile (no extra flags). Conflicting settings in the profile (see
+// ProfileWarnings) are printed as warnings.
+func LookupProfile(conf *cfg.Config, configPath, name string) (cfg.BuildProfile, error) {
+	if name == "" {
+		return cfg.BuildProfile{}, nil
+	}
+	if conf.Profiles == nil {
+		return cfg.BuildProfile{}, WithCode(CodeProfileNotFound, fmt.Errorf("profile %q requested, but no [profile.*] defined in %s", name, configPath))
+	}
+	p, ok := conf.Profiles[name]
+	if !ok {
+		return cfg.BuildProfile{}, WithCode(CodeProfileNotFound, fmt.Errorf("profile %q not found in %s%s", name, configPath, DidYouMean(name, slices.Collect(maps.Keys(conf.Profiles)))))
+	}
+	for _, w := range ProfileWarnings(name, p) {
+		statusf(os.Stderr, "⚠️  %s", w)
+	}
+	return p, nil
+}
+
 // ComposeBuildCommand returns the go build command line and env based on the
 // provided profile and CLI overrides. The working directory handling is done by callers.
 func ComposeBuildCommand(prof cfg.BuildProfile, o BuildOverrides) (cmdline string, env []string) {
+	return composeGoCommand("build", prof, o, []string{"."})
+}
+
+// ComposeTestCommand is ComposeBuildCommand for `go test`: the profile's
+// tags, flags, and env apply, the output path does not, and args (packages
+// and go test flags) default to ./... .
+func ComposeTestCommand(prof cfg.BuildProfile, o BuildOverrides, args []string) (cmdline string, env []string) {
+	o.Output = ""
+	prof.Output = ""
+	if len(args) == 0 {
+		args = []string{"./..."}
+	}
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return composeGoCommand("test", prof, o, quoted)
+}
+
+func composeGoCommand(verb string, prof cfg.BuildProfile, o BuildOverrides, args []string) (cmdline string, env []string) {
 	var parts []string
-	parts = append(parts, "go", "build")
+	parts = append(parts, "go", verb)
 
 	// Merge flags (CLI overrides profile)
 	ldflags := firstNonEmpty(o.Ldflags, prof.Ldflags)
@@ -32,7 +76,7 @@ func ComposeBuildCommand(prof cfg.BuildProfile, o BuildOverrides) (cmdline strin
 	}
 	out := firstNonEmpty(o.Output, prof.Output)
 	if out != "" {
-		parts = append(parts, "-o", shellQuote(filepath.Clean(out)))
+		parts = append(parts, "-o", shellQuote(filepath.Clean(NormalizeTaskPath(out))))
 	}
 	if ldflags != "" {
 		parts = append(parts, "-ldflags", shellQuote(ldflags))
@@ -47,8 +91,7 @@ func ComposeBuildCommand(prof cfg.BuildProfile, o BuildOverrides) (cmdline strin
 		parts = append(parts, prof.Flags...)
 	}
 
-	// default package
-	parts = append(parts, ".")
+	parts = append(parts, args...)
 
 	// Env
 	if prof.Env != nil {
@@ -60,6 +103,48 @@ func ComposeBuildCommand(prof cfg.BuildProfile, o BuildOverrides) (cmdline strin
 	return strings.Join(parts, " "), env
 }
 
+// ProfileTaskEnv returns the environment `rig run --profile` adds to each
+// task (and `rig dev` to its command): the profile's env, RIG_PROFILE, and GOFLAGS extended (after base,
+// the inherited value) with the profile's tags and flags so go commands in
+// the task pick them up.
+func ProfileTaskEnv(name string, prof cfg.BuildProfile, base string) map[string]string {
+	env := make(map[string]string, len(prof.Env)+2)
+	for k, v := range prof.Env {
+		env[k] = v
+	}
+	env["RIG_PROFILE"] = name
+
+	var flags []string
+	if base = strings.TrimSpace(base); base != "" {
+		flags = append(flags, base)
+	}
+	if prof.Ldflags != "" {
+		flags = append(flags, goflagQuote("-ldflags="+prof.Ldflags))
+	}
+	if prof.Gcflags != "" {
+		flags = append(flags, goflagQuote("-gcflags="+prof.Gcflags))
+	}
+	if len(prof.Tags) > 0 {
+		flags = append(flags, "-tags="+strings.Join(prof.Tags, ","))
+	}
+	for _, f := range prof.Flags {
+		flags = append(flags, goflagQuote(f))
+	}
+	if len(flags) > 0 {
+		env["GOFLAGS"] = strings.Join(flags, " ")
+	}
+	return env
+}
+
+// goflagQuote quotes a GOFLAGS entry containing spaces; the go command
+// splits GOFLAGS on spaces but honors single quotes.
+func goflagQuote(s string) string {
+	if !strings.ContainsAny(s, " \t") {
+		return s
+	}
+	return "'" + s + "'"
+}
+
 func firstNonEmpty(a, b string) string {
 	if strings.TrimSpace(a) != "" {
 		return a
@@ -1,6 +1,7 @@
 package rig
 
 import (
+	"slices"
 	"strings"
 	"testing"
 
@@ -47,3 +48,56 @@ func TestComposeBuildCommand_ProfileAndOverrides(t *testing.T) {
 		t.Errorf("expected env from profile, got %v", env)
 	}
 }
+
+func TestComposeTestCommand_ProfileWithoutOutput(t *testing.T) {
+	prof := cfg.BuildProfile{Tags: []string{"integration"}, Flags: []string{"-race"}, Output: "bin/app"}
+	cmd, _ := ComposeTestCommand(prof, BuildOverrides{}, nil)
+	if cmd != `go test -tags "integration" -race "./..."` {
+		t.Fatalf("unexpected cmd: %s", cmd)
+	}
+	cmd, _ = ComposeTestCommand(prof, BuildOverrides{Tags: []string{"unit"}}, []string{"./pkg", "-run", "TestX"})
+	if cmd != `go test -tags "unit" -race "./pkg" "-run" "TestX"` {
+		t.Fatalf("unexpected cmd: %s", cmd)
+	}
+}
+
+func TestProfileTaskEnv(t *testing.T) {
+	prof := cfg.BuildProfile{
+		Ldflags: "-s -w",
+		Tags:    []string{"a", "b"},
+		Flags:   []string{"-race"},
+		Env:     map[string]string{"CGO_ENABLED": "1"},
+	}
+	env := ProfileTaskEnv("race", prof, "-mod=mod")
+	if got, want := env["GOFLAGS"], "-mod=mod '-ldflags=-s -w' -tags=a,b -race"; got != want {
+		t.Fatalf("GOFLAGS=%q, want %q", got, want)
+	}
+	if env["RIG_PROFILE"] != "race" || env["CGO_ENABLED"] != "1" {
+		t.Fatalf("unexpected env: %v", env)
+	}
+	if _, ok := ProfileTaskEnv("empty", cfg.BuildProfile{}, "")["GOFLAGS"]; ok {
+		t.Fatalf("expected no GOFLAGS for an empty profile")
+	}
+}
+
+func TestProfileWarnings(t *testing.T) {
+	if w := ProfileWarnings("release", cfg.BuildProfile{Tags: []string{"prod"}, Flags: []string{"-trimpath", "-count=1"}}); len(w) != 0 {
+		t.Fatalf("expected no warnings, got %q", w)
+	}
+	cases := []struct {
+		prof cfg.BuildProfile
+		want string
+	}{
+		{cfg.BuildProfile{Flags: []string{"-o", "bin/a", "-trimpath", "-o=bin/b"}}, "profile.p: flags sets -o 2 times; go uses the last one"},
+		{cfg.BuildProfile{Output: "bin/app", Flags: []string{"-o=bin/b"}}, "profile.p: output and flags both set -o; go uses the one in flags"},
+		{cfg.BuildProfile{Tags: []string{"prod", "netgo"}, Flags: []string{"-tags", "prod"}}, "profile.p: tag(s) prod set in both tags and flags"},
+		{cfg.BuildProfile{Tags: []string{"prod", "netgo"}, Flags: []string{"-tags", "prod"}}, "profile.p: -tags in flags replaces tags; netgo not applied"},
+		{cfg.BuildProfile{Flags: []string{"-race"}, Env: map[string]string{"GOOS": "windows", "GOARCH": "386"}}, "profile.p: -race is not supported when building for windows/386"},
+		{cfg.BuildProfile{Flags: []string{"-race"}, Env: map[string]string{"CGO_ENABLED": "0", "GOOS": "linux", "GOARCH": "amd64"}}, "profile.p: -race requires cgo, but env sets CGO_ENABLED=0"},
+	}
+	for _, c := range cases {
+		if w := ProfileWarnings("p", c.prof); !slices.Contains(w, c.want) {
+			t.Errorf("%+v: warnings %q, want %q", c.prof, w, c.want)
+		}
+	}
+}
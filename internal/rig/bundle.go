@@ -0,0 +1,337 @@
+package rig
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// BundleSchema is the bundle.json schema `rig bundle` writes.
+const BundleSchema = 1
+
+// bundleManifestName is the manifest at the root of every bundle.
+const bundleManifestName = "bundle.json"
+
+// Bundle file kinds.
+const (
+	BundleRig    = "rig"    // the rig binary
+	BundleConfig = "config" // rig.toml, rig.lock, rig.lock.sig
+	BundleTool   = "tool"   // a locked tool binary for .rig/bin
+)
+
+// BundleManifest is bundle.json: what a `rig bundle` archive holds and the
+// sha256 of every file, checked by `rig bundle install` before anything is
+// written.
+type BundleManifest struct {
+	Schema     int          `json:"schema"`
+	GOOS       string       `json:"goos"`
+	GOARCH     string       `json:"goarch"`
+	RigVersion string       `json:"rig_version"`
+	Files      []BundleFile `json:"files"`
+}
+
+// BundleFile is one file of a bundle; Path is slash-separated and relative
+// to the bundle root.
+type BundleFile struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	SHA256 string `json:"sha256"`
+	Data   []byte `json:"-"`
+}
+
+// BundleOptions configures BuildBundle.
+type BundleOptions struct {
+	GOOS, GOARCH string
+	RigVersion   string
+	// RigBinary is the rig executable for GOOS/GOARCH to include.
+	RigBinary string
+}
+
+// BuildBundle collects the files of a bundle for the project at configPath:
+// the rig binary, rig.toml and rig.lock (and rig.lock.sig), and every locked
+// tool. For the host platform tools come from .rig/bin and must match
+// rig.lock; for another platform go tools are cross-compiled at their locked
+// version and OCI tools are pulled for that platform, and the bundled
+// rig.lock records those binaries' sha256.
+func BuildBundle(configPath string, opts BundleOptions) (BundleManifest, error) {
+	m := BundleManifest{Schema: BundleSchema, GOOS: opts.GOOS, GOARCH: opts.GOARCH, RigVersion: opts.RigVersion}
+	lock, err := ReadRigLockForConfig(configPath)
+	if err != nil {
+		return BundleManifest{}, WithCode(CodeLockMissing, err)
+	}
+	native := opts.GOOS == runtime.GOOS && opts.GOARCH == runtime.GOARCH
+	exe := ""
+	if opts.GOOS == "windows" {
+		exe = ".exe"
+	}
+
+	rig, err := os.ReadFile(opts.RigBinary)
+	if err != nil {
+		return BundleManifest{}, fmt.Errorf("read rig binary: %w", err)
+	}
+	m.add("rig"+exe, BundleRig, rig)
+
+	var tmp string
+	if !native {
+		if tmp, err = os.MkdirTemp("", "rig-bundle-*"); err != nil {
+			return BundleManifest{}, err
+		}
+		defer os.RemoveAll(tmp)
+	}
+	for i, lt := range lock.Tools {
+		name, _, err := ParseRequested(lt.Requested)
+		if err != nil {
+			return BundleManifest{}, err
+		}
+		bin := strings.TrimSpace(lt.Bin)
+		if bin == "" {
+			bin = ResolveToolIdentity(name).Bin
+		}
+		bin = strings.TrimSuffix(bin, ".exe")
+		var data []byte
+		switch {
+		case native:
+			p := ToolBinPath(configPath, bin)
+			if data, err = os.ReadFile(p); err != nil {
+				return BundleManifest{}, WithCode(CodeToolNotInstalled, fmt.Errorf("tool %q is not installed in %s (run 'rig sync')", name, filepath.Dir(p)))
+			}
+			if verifySHA256(bin, data, lt.SHA256) != nil {
+				return BundleManifest{}, WithCode(CodeToolSHAMismatch, fmt.Errorf("tool %q checksum mismatch with rig.lock (run 'rig sync')", name))
+			}
+		case IsOCITool(lt):
+			repo, digest := SplitResolved(lt.Resolved)
+			r, err := parseOCIRepo(repo)
+			if err != nil {
+				return BundleManifest{}, err
+			}
+			if data, err = fetchOCIBinary(newOCIClient(), r, digest, bin, opts.GOOS, opts.GOARCH); err != nil {
+				return BundleManifest{}, fmt.Errorf("pull %s for %s/%s: %w", lt.Resolved, opts.GOOS, opts.GOARCH, err)
+			}
+		default:
+			if data, err = crossInstallTool(name, lt, bin+exe, opts.GOOS, opts.GOARCH, filepath.Join(tmp, name)); err != nil {
+				return BundleManifest{}, err
+			}
+		}
+		sum := sha256.Sum256(data)
+		lock.Tools[i].SHA256 = hex.EncodeToString(sum[:])
+		m.add("bin/"+bin+exe, BundleTool, data)
+	}
+
+	toml, err := os.ReadFile(configPath)
+	if err != nil {
+		return BundleManifest{}, err
+	}
+	m.add("rig.toml", BundleConfig, toml)
+	lockData, err := os.ReadFile(rigLockPathForConfig(configPath))
+	if err != nil {
+		return BundleManifest{}, err
+	}
+	if native {
+		m.add("rig.lock", BundleConfig, lockData)
+		if sig, err := os.ReadFile(filepath.Join(filepath.Dir(configPath), "rig.lock.sig")); err == nil {
+			m.add("rig.lock.sig", BundleConfig, sig)
+		}
+		return m, nil
+	}
+	// The sums differ from this machine's binaries, and re-pinning them
+	// invalidates a lock signature, so it is left out.
+	if lockData, err = MarshalLockfile(lock); err != nil {
+		return BundleManifest{}, err
+	}
+	m.add("rig.lock", BundleConfig, lockData)
+	return m, nil
+}
+
+func (m *BundleManifest) add(p, kind string, data []byte) {
+	sum := sha256.Sum256(data)
+	m.Files = append(m.Files, BundleFile{Path: p, Kind: kind, SHA256: hex.EncodeToString(sum[:]), Data: data})
+}
+
+// crossInstallTool builds a go tool at its locked version for goos/goarch.
+// go install refuses to put cross-compiled binaries in GOBIN, so it installs
+// into a scratch GOPATH that shares the module cache.
+func crossInstallTool(name string, lt LockedTool, file, goos, goarch, gopath string) ([]byte, error) {
+	modCache, err := execCapture("go", []string{"env", "GOMODCACHE"}, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("go env GOMODCACHE: %w", err)
+	}
+	_, version := SplitResolved(lt.Resolved)
+	env := append(GoInstallEnv("", os.Getenv), "GOPATH="+gopath, "GOMODCACHE="+modCache, "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED=0")
+	target := ResolveToolIdentity(name).InstallPath + "@" + version
+	if out, err := execCapture("go", []string{"install", target}, "", env); err != nil {
+		return nil, fmt.Errorf("build %s for %s/%s: %w: %s", target, goos, goarch, err, out)
+	}
+	return os.ReadFile(filepath.Join(gopath, "bin", goos+"_"+goarch, file))
+}
+
+// WriteBundle writes m and its files as a .tar.gz at dest, under a directory
+// named after the archive.
+func WriteBundle(dest string, m BundleManifest) error {
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	root := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(dest), ".tgz"), ".tar.gz")
+	now := time.Now()
+	write := func(name string, mode int64, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: root + "/" + name, Mode: mode, Size: int64(len(data)), ModTime: now, Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := write(bundleManifestName, 0o644, append(manifest, '\n')); err != nil {
+		return err
+	}
+	for _, f := range m.Files {
+		mode := int64(0o644)
+		if f.Kind != BundleConfig {
+			mode = 0o755
+		}
+		if err := write(f.Path, mode, f.Data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(dest, buf.Bytes(), 0o644)
+}
+
+// ReadBundle reads a bundle from a .tar.gz or an already unpacked directory
+// and checks every file against bundle.json.
+func ReadBundle(src string) (BundleManifest, error) {
+	files := map[string][]byte{}
+	if fi, err := os.Stat(src); err != nil {
+		return BundleManifest{}, err
+	} else if fi.IsDir() {
+		err := filepath.WalkDir(src, func(p string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, _ := filepath.Rel(src, p)
+			b, err := os.ReadFile(p)
+			files[filepath.ToSlash(rel)] = b
+			return err
+		})
+		if err != nil {
+			return BundleManifest{}, err
+		}
+	} else {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return BundleManifest{}, err
+		}
+		err = walkTarGz(data, 1, func(name string, read func() ([]byte, error)) (bool, error) {
+			b, err := read()
+			files[name] = b
+			return false, err
+		})
+		if err != nil {
+			return BundleManifest{}, fmt.Errorf("read bundle %s: %w", src, err)
+		}
+	}
+
+	raw, ok := files[bundleManifestName]
+	if !ok {
+		return BundleManifest{}, fmt.Errorf("%s is not a rig bundle (no %s)", src, bundleManifestName)
+	}
+	var m BundleManifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return BundleManifest{}, fmt.Errorf("parse %s: %w", bundleManifestName, err)
+	}
+	if m.Schema != BundleSchema {
+		return BundleManifest{}, fmt.Errorf("unsupported bundle schema %d (this rig reads %d)", m.Schema, BundleSchema)
+	}
+	for i, f := range m.Files {
+		if f.Path != path.Clean(f.Path) || path.IsAbs(f.Path) || strings.HasPrefix(f.Path, "../") {
+			return BundleManifest{}, fmt.Errorf("bundle file %q: invalid path", f.Path)
+		}
+		data, ok := files[f.Path]
+		if !ok {
+			return BundleManifest{}, fmt.Errorf("bundle is missing %s", f.Path)
+		}
+		if err := verifySHA256(f.Path, data, f.SHA256); err != nil {
+			return BundleManifest{}, err
+		}
+		m.Files[i].Data = data
+	}
+	return m, nil
+}
+
+// BundleInstallOptions configures InstallBundle.
+type BundleInstallOptions struct {
+	// Dir receives rig.toml and rig.lock; tools go to its tool bin directory.
+	Dir string
+	// RigPath is where the rig binary is written; empty means the tool bin
+	// directory.
+	RigPath string
+	// Force overwrites an existing rig.toml or rig.lock.
+	Force bool
+}
+
+// InstallBundle unpacks a verified bundle: rig.toml and rig.lock into
+// opts.Dir, tools into its tool bin directory, and rig to opts.RigPath. It
+// returns the rig binary's path.
+func InstallBundle(m BundleManifest, opts BundleInstallOptions) (string, error) {
+	if m.GOOS != runtime.GOOS || m.GOARCH != runtime.GOARCH {
+		return "", fmt.Errorf("bundle is for %s/%s, this machine is %s/%s", m.GOOS, m.GOARCH, runtime.GOOS, runtime.GOARCH)
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return "", err
+	}
+	configPath := filepath.Join(opts.Dir, "rig.toml")
+	for _, f := range m.Files {
+		if f.Kind != BundleConfig {
+			continue
+		}
+		dest := filepath.Join(opts.Dir, filepath.FromSlash(f.Path))
+		if _, err := os.Stat(dest); err == nil && !opts.Force {
+			return "", fmt.Errorf("%s already exists (use --force to overwrite)", dest)
+		}
+		if err := os.WriteFile(dest, f.Data, 0o644); err != nil {
+			return "", err
+		}
+	}
+	// BinDir reads [paths].bin, so tools go in after rig.toml is written.
+	binDir := BinDir(configPath)
+	rigPath := opts.RigPath
+	for _, f := range m.Files {
+		var dest string
+		switch f.Kind {
+		case BundleTool:
+			dest = filepath.Join(binDir, path.Base(f.Path))
+		case BundleRig:
+			if rigPath == "" {
+				rigPath = filepath.Join(binDir, path.Base(f.Path))
+			}
+			dest = rigPath
+		default:
+			continue
+		}
+		if err := writeToolBinary(dest, f.Data); err != nil {
+			return "", fmt.Errorf("install %s: %w", f.Path, err)
+		}
+	}
+	if rigPath == "" {
+		return "", errors.New("bundle has no rig binary")
+	}
+	return rigPath, nil
+}
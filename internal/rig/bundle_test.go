@@ -0,0 +1,96 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "rig.toml"), "[project]\nname = \"app\"\n\n[tools]\nmytool = \"example.com/mytool@v1.0.0\"\n", 0o644)
+	toolPath := ToolBinPath(filepath.Join(src, "rig.toml"), "mytool")
+	writeTestFile(t, toolPath, "tool-binary", 0o755)
+	sum, err := ComputeFileSHA256(toolPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := Lockfile{Schema: LockSchema0, Tools: []LockedTool{{Kind: "go-binary", Requested: "mytool@v1.0.0", Resolved: "example.com/mytool@v1.0.0", Module: "example.com/mytool", Bin: "mytool", SHA256: sum}}}
+	if err := WriteLockfile(filepath.Join(src, "rig.lock"), lock); err != nil {
+		t.Fatal(err)
+	}
+	rigBin := filepath.Join(src, "rig-exe")
+	writeTestFile(t, rigBin, "rig-binary", 0o755)
+
+	m, err := BuildBundle(filepath.Join(src, "rig.toml"), BundleOptions{GOOS: runtime.GOOS, GOARCH: runtime.GOARCH, RigVersion: "v1.2.3", RigBinary: rigBin})
+	if err != nil {
+		t.Fatalf("BuildBundle: %v", err)
+	}
+	archive := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if err := WriteBundle(archive, m); err != nil {
+		t.Fatalf("WriteBundle: %v", err)
+	}
+	read, err := ReadBundle(archive)
+	if err != nil {
+		t.Fatalf("ReadBundle: %v", err)
+	}
+	if read.RigVersion != "v1.2.3" || len(read.Files) != 4 {
+		t.Fatalf("unexpected manifest: %+v", read)
+	}
+
+	dst := t.TempDir()
+	rigPath, err := InstallBundle(read, BundleInstallOptions{Dir: dst})
+	if err != nil {
+		t.Fatalf("InstallBundle: %v", err)
+	}
+	if b, _ := os.ReadFile(rigPath); string(b) != "rig-binary" {
+		t.Fatalf("rig binary at %s = %q", rigPath, b)
+	}
+	if b, _ := os.ReadFile(ToolBinPath(filepath.Join(dst, "rig.toml"), "mytool")); string(b) != "tool-binary" {
+		t.Fatalf("tool binary = %q", b)
+	}
+	// The installed rig binary is neither pruned nor reported as orphaned.
+	dstLock, err := ReadLockfile(filepath.Join(dst, "rig.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extras, err := BinExtras(filepath.Join(dst, "rig.toml"), dstLock); err != nil || len(extras) != 0 {
+		t.Fatalf("BinExtras after install = %v, %v; want none", extras, err)
+	}
+	if stale, err := StaleBins(filepath.Join(dst, "rig.toml"), dstLock); err != nil || len(stale) != 0 {
+		t.Fatalf("StaleBins after install = %+v, %v; want none", stale, err)
+	}
+	if _, err := InstallBundle(read, BundleInstallOptions{Dir: dst}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected existing rig.toml to be kept, got %v", err)
+	}
+
+	// A tool that no longer matches rig.lock is not bundled.
+	writeTestFile(t, toolPath, "tampered", 0o755)
+	if _, err := BuildBundle(filepath.Join(src, "rig.toml"), BundleOptions{GOOS: runtime.GOOS, GOARCH: runtime.GOARCH, RigBinary: rigBin}); err == nil || CodeOf(err) != CodeToolSHAMismatch {
+		t.Fatalf("expected %s, got %v", CodeToolSHAMismatch, err)
+	}
+}
+
+func TestReadBundleRejectsTamperedFile(t *testing.T) {
+	m := BundleManifest{Schema: BundleSchema, GOOS: runtime.GOOS, GOARCH: runtime.GOARCH}
+	m.add("rig.toml", BundleConfig, []byte("[project]\n"))
+	dir := t.TempDir()
+	if err := WriteBundle(filepath.Join(dir, "b.tar.gz"), m); err != nil {
+		t.Fatal(err)
+	}
+	unpacked := filepath.Join(dir, "unpacked")
+	if err := os.MkdirAll(unpacked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{"rig.toml": "[tasks]\n", "bundle.json": `{"schema":1,"goos":"` + runtime.GOOS + `","goarch":"` + runtime.GOARCH + `","files":[{"path":"rig.toml","kind":"config","sha256":"` + m.Files[0].SHA256 + `"}]}`} {
+		writeTestFile(t, filepath.Join(unpacked, name), data, 0o644)
+	}
+	if _, err := ReadBundle(filepath.Join(dir, "b.tar.gz")); err != nil {
+		t.Fatalf("ReadBundle archive: %v", err)
+	}
+	if _, err := ReadBundle(unpacked); err == nil || !strings.Contains(err.Error(), "checksum mismatch for rig.toml") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package rig
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// GoCacheDir returns the absolute GOCACHE configured by [cache].go, or "" when
+// unset. Relative paths resolve against the directory holding rig.toml.
+func GoCacheDir(conf *cfg.Config, configPath string) string {
+	dir := strings.TrimSpace(conf.Cache.Go)
+	if dir == "" {
+		return ""
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(configPath), dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// GoCacheEnv returns the GOCACHE entry for commands rig launches, or nil when
+// [cache].go is unset (the inherited GOCACHE applies).
+func GoCacheEnv(conf *cfg.Config, configPath string) []string {
+	if dir := GoCacheDir(conf, configPath); dir != "" {
+		return []string{"GOCACHE=" + dir}
+	}
+	return nil
+}
+
+// EffectiveGoCache reports the GOCACHE the go command uses with env.
+func EffectiveGoCache(workDir string, env []string) (string, error) {
+	out, err := execCapture("go", []string{"env", "GOCACHE"}, workDir, env)
+	if err != nil {
+		return "", fmt.Errorf("go env GOCACHE failed: %w: %s", err, out)
+	}
+	return out, nil
+}
+
+// GoCacheUsage is the on-disk footprint of a Go build cache.
+type GoCacheUsage struct {
+	Bytes int64
+	Files int
+}
+
+// MeasureGoCache walks dir and totals its regular files. A missing directory
+// is empty, not an error.
+func MeasureGoCache(dir string) (GoCacheUsage, error) {
+	var u GoCacheUsage
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		u.Bytes += info.Size()
+		u.Files++
+		return nil
+	})
+	return u, err
+}
+
+// GoBuildCacheStats summarizes one `go build -x` run: of Packages built,
+// Compiled missed the cache and the rest were cache hits.
+type GoBuildCacheStats struct {
+	Packages int
+	Compiled []string
+}
+
+// Hits is the number of packages served from the cache.
+func (s GoBuildCacheStats) Hits() int {
+	if h := s.Packages - len(s.Compiled); h > 0 {
+		return h
+	}
+	return 0
+}
+
+var compilePkgRE = regexp.MustCompile(`(?:^|[\s/\\])compile(?:\.exe)?\s.*?\s-p\s+(\S+)`)
+
+// CompiledPackages extracts the import paths compiled in `go build -x`
+// output. Packages absent from the output were cache hits.
+func CompiledPackages(xOutput string) []string {
+	seen := map[string]bool{}
+	var pkgs []string
+	for line := range strings.SplitSeq(xOutput, "\n") {
+		m := compilePkgRE.FindStringSubmatch(line)
+		if m == nil || seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		pkgs = append(pkgs, m[1])
+	}
+	return pkgs
+}
+
+// AnalyzeGoBuildCache builds pattern (default ./...) with -x in workDir and
+// counts which of its dependency packages were compiled versus reused.
+func AnalyzeGoBuildCache(workDir string, env []string, pattern string) (GoBuildCacheStats, error) {
+	if pattern == "" {
+		pattern = "./..."
+	}
+	list, err := execCapture("go", []string{"list", "-deps", pattern}, workDir, env)
+	if err != nil {
+		return GoBuildCacheStats{}, fmt.Errorf("go list failed: %w: %s", err, list)
+	}
+	var st GoBuildCacheStats
+	for line := range strings.SplitSeq(list, "\n") {
+		// unsafe has no compile action; counting it would inflate hits.
+		if line = strings.TrimSpace(line); line != "" && line != "unsafe" {
+			st.Packages++
+		}
+	}
+	out, err := execCapture("go", []string{"build", "-x", pattern}, workDir, env)
+	if err != nil {
+		return GoBuildCacheStats{}, fmt.Errorf("go build %s failed: %w (run it directly for details)", pattern, err)
+	}
+	st.Compiled = CompiledPackages(out)
+	return st, nil
+}
+
+// CleanGoCache runs `go clean -cache` with env.
+func CleanGoCache(workDir string, env []string) error {
+	if out, err := execCapture("go", []string{"clean", "-cache"}, workDir, env); err != nil {
+		return fmt.Errorf("go clean -cache failed: %w: %s", err, out)
+	}
+	return nil
+}
+
+// FormatBytes renders n as a short human-readable size (e.g. "12.3 MB").
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,46 @@
+package rig
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+func TestGoCacheDirResolvesAgainstConfig(t *testing.T) {
+	conf := &cfg.Config{Cache: cfg.CacheConfig{Go: ".rig/cache/go"}}
+	got := GoCacheDir(conf, filepath.Join("proj", "rig.toml"))
+	if want := filepath.Join("proj", ".rig", "cache", "go"); got != want {
+		t.Fatalf("GoCacheDir=%q, want %q", got, want)
+	}
+	if env := GoCacheEnv(&cfg.Config{}, "rig.toml"); env != nil {
+		t.Fatalf("expected no GOCACHE when [cache].go is unset, got %v", env)
+	}
+}
+
+func TestCompiledPackages(t *testing.T) {
+	out := `WORK=/tmp/go-build1
+mkdir -p $WORK/b001/
+/usr/local/go/pkg/tool/linux_amd64/compile -o $WORK/b002/_pkg_.a -trimpath "$WORK/b002=>" -p internal/goarch -std -complete ./goarch.go
+cd /src/app
+/usr/local/go/pkg/tool/linux_amd64/compile -o $WORK/b001/_pkg_.a -trimpath "$WORK/b001=>" -p example.com/app/util -lang=go1.25 ./util.go
+/usr/local/go/pkg/tool/linux_amd64/link -o $WORK/b001/exe/a.out -importcfg $WORK/b001/importcfg.link
+`
+	got := CompiledPackages(out)
+	if want := []string{"internal/goarch", "example.com/app/util"}; !slices.Equal(got, want) {
+		t.Fatalf("CompiledPackages=%v, want %v", got, want)
+	}
+	st := GoBuildCacheStats{Packages: 10, Compiled: got}
+	if st.Hits() != 8 {
+		t.Fatalf("Hits=%d, want 8", st.Hits())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{512: "512 B", 2048: "2.0 KB", 5 << 20: "5.0 MB", 3 << 30: "3.0 GB"} {
+		if got := FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d)=%q, want %q", n, got, want)
+		}
+	}
+}
@@ -6,15 +6,23 @@ import (
 )
 
 type CheckReport struct {
-	ConfigPath string          `json:"configPath"`
-	LockPath   string          `json:"lockPath"`
-	OK         bool            `json:"ok"`
-	Error      string          `json:"error,omitempty"`
-	Missing    int             `json:"missing"`
-	Mismatched int             `json:"mismatched"`
-	Extras     []string        `json:"extras,omitempty"`
-	Tools      []ToolStatusRow `json:"tools"`
-	Go         *GoStatusRow    `json:"go,omitempty"`
+	ConfigPath string              `json:"configPath"`
+	LockPath   string              `json:"lockPath"`
+	OK         bool                `json:"ok"`
+	Error      string              `json:"error,omitempty"`
+	Code       ErrorCode           `json:"code,omitempty"`
+	Missing    int                 `json:"missing"`
+	Mismatched int                 `json:"mismatched"`
+	Extras     []string            `json:"extras,omitempty"`
+	StaleBins  []StaleBin          `json:"staleBins,omitempty"`
+	GoModTools []GoModToolDrift    `json:"goModTools,omitempty"`
+	Tools      []ToolStatusRow     `json:"tools"`
+	Go         *GoStatusRow        `json:"go,omitempty"`
+	Workspace  *WorkspaceStatus    `json:"workspace,omitempty"`
+	Requires   []RequirementStatus `json:"requires,omitempty"`
+	// Undeclared lists tools task commands run without declaring them;
+	// only checked with [project].strict-tools.
+	Undeclared []UndeclaredTool `json:"undeclaredTools,omitempty"`
 }
 
 func Check(startDir string) (CheckReport, error) {
@@ -29,9 +37,24 @@ func Check(startDir string) (CheckReport, error) {
 		rep := CheckReport{ConfigPath: confPath, LockPath: lockPath, OK: false, Tools: []ToolStatusRow{}}
 		if os.IsNotExist(err) {
 			rep.Error = "rig.lock not found: run 'rig sync' first"
+			rep.Code = CodeLockMissing
 			return rep, nil
 		}
 		rep.Error = err.Error()
+		rep.Code = CodeLockMissing
+		return rep, nil
+	}
+
+	if err := VerifyLockSignature(confPath, conf.Lock); err != nil {
+		rep := CheckReport{ConfigPath: confPath, LockPath: lockPath, OK: false, Tools: []ToolStatusRow{}}
+		rep.Error = err.Error()
+		rep.Code = CodeOf(err)
+		return rep, nil
+	}
+	if err := LockMatchesIncludes(lock, conf.RemoteIncludes); err != nil {
+		rep := CheckReport{ConfigPath: confPath, LockPath: lockPath, OK: false, Tools: []ToolStatusRow{}}
+		rep.Error = err.Error()
+		rep.Code = CodeOf(err)
 		return rep, nil
 	}
 
@@ -39,12 +62,57 @@ func Check(startDir string) (CheckReport, error) {
 	if err != nil {
 		rep := CheckReport{ConfigPath: confPath, LockPath: lockPath, OK: false, Tools: []ToolStatusRow{}}
 		rep.Error = err.Error()
+		rep.Code = CodeOf(err)
+		return rep, nil
+	}
+	if err := LockMatchesManifest(lock, conf); err != nil {
+		rep := CheckReport{ConfigPath: confPath, LockPath: lockPath, OK: false, Tools: []ToolStatusRow{}}
+		rep.Error = err.Error()
+		rep.Code = CodeOf(err)
+		return rep, nil
+	}
+
+	stale, err := StaleBins(confPath, lock)
+	if err != nil {
+		rep := CheckReport{ConfigPath: confPath, LockPath: lockPath, OK: false, Tools: []ToolStatusRow{}}
+		rep.Error = err.Error()
+		rep.Code = CodeOf(err)
+		return rep, nil
+	}
+
+	// go.mod tool directives are advisory: drift is reported but does not
+	// fail the check.
+	drifts, err := GoModToolDrifts(conf.Tools, confPath)
+	if err != nil {
+		rep := CheckReport{ConfigPath: confPath, LockPath: lockPath, OK: false, Tools: []ToolStatusRow{}}
+		rep.Error = err.Error()
+		rep.Code = CodeOf(err)
 		return rep, nil
 	}
 
 	goRow, goOK := checkGoAgainstLockIfRequired(conf.Tools, lock, confPath)
 
-	ok := missing == 0 && mismatched == 0 && goOK
+	ws, err := CheckWorkspace(conf, confPath)
+	if err != nil {
+		rep := CheckReport{ConfigPath: confPath, LockPath: lockPath, OK: false, Tools: []ToolStatusRow{}}
+		rep.Error = err.Error()
+		rep.Code = CodeOf(err)
+		return rep, nil
+	}
+	wsOK := ws == nil || ws.InSync
+
+	reqs := CheckRequirements(conf.Requires, nil)
+	reqOK := true
+	for _, r := range reqs {
+		reqOK = reqOK && r.OK()
+	}
+
+	var undeclared []UndeclaredTool
+	if conf.Project.StrictTools {
+		undeclared = UndeclaredTools(conf, nil)
+	}
+
+	ok := missing == 0 && mismatched == 0 && goOK && wsOK && reqOK && len(undeclared) == 0
 	return CheckReport{
 		ConfigPath: confPath,
 		LockPath:   lockPath,
@@ -52,8 +120,13 @@ func Check(startDir string) (CheckReport, error) {
 		Missing:    missing,
 		Mismatched: mismatched,
 		Extras:     extras,
+		StaleBins:  stale,
+		GoModTools: drifts,
 		Tools:      rows,
 		Go:         goRow,
+		Workspace:  ws,
+		Requires:   reqs,
+		Undeclared: undeclared,
 	}, nil
 }
 
@@ -0,0 +1,255 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// ComposeOptions configures ExportCompose.
+type ComposeOptions struct {
+	// Tasks names the tasks to export; composite tasks stand for their steps.
+	// Empty exports [tasks.dev] and every task that declares ports.
+	Tasks []string
+	// Image overrides the service image (default golang:<project Go version>).
+	Image string
+	// ProjectDir is the project root as seen from the compose file's
+	// directory (default ".").
+	ProjectDir string
+}
+
+// composeWorkdir is where the project is mounted inside each service.
+const composeWorkdir = "/app"
+
+var (
+	portRe        = regexp.MustCompile(`^(?:(?:[0-9.]+|\[[0-9a-fA-F:]+\]):)?(?:[0-9]{1,5}(?:-[0-9]{1,5})?:)?[0-9]{1,5}(?:-[0-9]{1,5})?(?:/(?:tcp|udp))?$`)
+	serviceNameRe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+// ValidatePort checks a ports entry in docker's short syntax: "8080",
+// "8080:80", "127.0.0.1:8080:80", optionally with a /tcp or /udp suffix.
+func ValidatePort(p string) error {
+	if !portRe.MatchString(strings.TrimSpace(p)) {
+		return fmt.Errorf("invalid port %q (want CONTAINER, HOST:CONTAINER, or IP:HOST:CONTAINER)", p)
+	}
+	return nil
+}
+
+// ComposeServices returns the tasks ExportCompose turns into services, in
+// order: the named tasks with composite tasks replaced by their steps, or
+// by default [tasks.dev] (when it has a command) and the tasks with ports.
+func ComposeServices(tasks cfg.TasksMap, names []string) ([]string, error) {
+	if len(names) == 0 {
+		if t, ok := tasks["dev"]; ok && strings.TrimSpace(t.Command) != "" {
+			names = append(names, "dev")
+		}
+		var withPorts []string
+		for name, t := range tasks {
+			if name != "dev" && len(t.Ports) > 0 {
+				withPorts = append(withPorts, name)
+			}
+		}
+		sort.Strings(withPorts)
+		names = append(names, withPorts...)
+		if len(names) == 0 {
+			return nil, errors.New("no service tasks: declare ports on the tasks to export, or name them")
+		}
+	}
+	var out []string
+	seen := map[string]bool{}
+	var visit func(name string, stack []string) error
+	visit = func(name string, stack []string) error {
+		t, ok := tasks[name]
+		if !ok {
+			return WithCode(CodeTaskNotFound, fmt.Errorf("task %q not found%s", name, DidYouMean(name, TaskNames(tasks))))
+		}
+		for _, s := range stack {
+			if s == name {
+				return fmt.Errorf("task %q: steps form a cycle", name)
+			}
+		}
+		if len(t.Steps) > 0 {
+			for _, step := range t.Steps {
+				if err := visit(step, append(stack, name)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// ExportCompose renders a docker-compose.yml with one service per exported
+// task: the project mounted at /app, the task's cwd as working_dir, its
+// command (inputs at their defaults, default_args applied), env, env_file,
+// ports, and depends_on entries that are services too.
+func ExportCompose(conf *cfg.Config, configPath string, opts ComposeOptions) (string, error) {
+	names, err := ComposeServices(conf.Tasks, opts.Tasks)
+	if err != nil {
+		return "", err
+	}
+	image := strings.TrimSpace(opts.Image)
+	if image == "" {
+		image = composeImage(conf.Tools, configPath)
+	}
+	projectDir := filepath.ToSlash(strings.TrimSpace(opts.ProjectDir))
+	if projectDir == "" {
+		projectDir = "."
+	}
+	services := map[string]string{}
+	for _, name := range names {
+		services[name] = composeServiceName(name)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by `rig export compose` from rig.toml. Edit the tasks there and\n")
+	b.WriteString("# re-run it rather than editing this file.\n")
+	b.WriteString("services:\n")
+	for _, name := range names {
+		t := conf.Tasks[name]
+		if len(t.Dirs) > 0 {
+			return "", fmt.Errorf("task %q runs in several directories (dirs); export one task per directory instead", name)
+		}
+		command, err := composeCommand(name, t)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "  %s:\n", services[name])
+		fmt.Fprintf(&b, "    image: %s\n", composeQuote(image))
+		fmt.Fprintf(&b, "    working_dir: %s\n", composeQuote(path.Join(composeWorkdir, filepath.ToSlash(NormalizeTaskPath(t.Cwd)))))
+		b.WriteString("    volumes:\n")
+		fmt.Fprintf(&b, "      - %s\n", composeQuote(projectDir+":"+composeWorkdir))
+		b.WriteString("    command:\n")
+		for _, a := range command {
+			fmt.Fprintf(&b, "      - %s\n", composeQuote(a))
+		}
+		if ef := strings.TrimSpace(t.EnvFile); ef != "" {
+			b.WriteString("    env_file:\n")
+			fmt.Fprintf(&b, "      - %s\n", composeQuote(path.Join(projectDir, filepath.ToSlash(NormalizeTaskPath(ef)))))
+		}
+		if len(t.Env) > 0 {
+			keys := make([]string, 0, len(t.Env))
+			for k := range t.Env {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			b.WriteString("    environment:\n")
+			for _, k := range keys {
+				fmt.Fprintf(&b, "      %s: %s\n", composeQuote(k), composeQuote(t.Env[k]))
+			}
+		}
+		if len(t.Ports) > 0 {
+			b.WriteString("    ports:\n")
+			for _, p := range t.Ports {
+				fmt.Fprintf(&b, "      - %s\n", composeQuote(strings.TrimSpace(p)))
+			}
+		}
+		var deps []string
+		for _, d := range t.DependsOn {
+			if svc, ok := services[d]; ok {
+				deps = append(deps, svc)
+			}
+		}
+		if len(deps) > 0 {
+			b.WriteString("    depends_on:\n")
+			for _, d := range deps {
+				fmt.Fprintf(&b, "      - %s\n", composeQuote(d))
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// composeImage picks golang:<version> from tools.go, then the go.mod go
+// directive, falling back to the floating golang image.
+func composeImage(tools map[string]string, configPath string) string {
+	v := strings.TrimPrefix(strings.TrimSpace(tools["go"]), "go")
+	if !IsReleaseVersion(v) {
+		v = ""
+		if b, err := os.ReadFile(filepath.Join(filepath.Dir(configPath), "go.mod")); err == nil {
+			if mod, err := ParseGoMod(b); err == nil {
+				v = mod.Go
+			}
+		}
+	}
+	if v == "" {
+		return "golang"
+	}
+	return "golang:" + v
+}
+
+// composeCommand is the service command for t. Arguments referencing
+// variables run through sh so the container's environment expands them,
+// as rig would expand them from the task's.
+func composeCommand(name string, t cfg.Task) ([]string, error) {
+	argv := t.Argv
+	if len(argv) == 0 {
+		var err error
+		if argv, err = parseCommand(t.Command); err != nil {
+			return nil, fmt.Errorf("task %q: %w", name, err)
+		}
+	}
+	values := map[string]string{}
+	for _, in := range t.Inputs {
+		if in.Default == "" {
+			return nil, fmt.Errorf("task %q: input %q has no default to export", name, in.Name)
+		}
+		values[in.Name] = in.Default
+	}
+	argv = spliceArgs(substituteInputs(argv, t, values), nil, t.DefaultArgs)
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("task %q: command is empty after argument substitution", name)
+	}
+	if !slices.ContainsFunc(argv, func(a string) bool { return strings.Contains(a, "$") }) {
+		return argv, nil
+	}
+	words := make([]string, len(argv))
+	for i, a := range argv {
+		words[i] = shellWord(a)
+	}
+	return []string{"sh", "-c", strings.Join(words, " ")}, nil
+}
+
+// shellWord quotes a for sh: variable references stay live inside double
+// quotes, and rig's $$ escape becomes a literal dollar.
+func shellWord(a string) string {
+	if a != "" && !strings.ContainsAny(a, " \t\n\"'\\`$|&;<>()*?[]#~!{}") {
+		return a
+	}
+	if !strings.Contains(a, "$") {
+		return "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", "$$", `\$`)
+	return `"` + r.Replace(a) + `"`
+}
+
+// composeServiceName maps a task name to a valid compose service name.
+func composeServiceName(task string) string {
+	return strings.Trim(serviceNameRe.ReplaceAllString(task, "-"), "-")
+}
+
+// composeQuote renders s as a YAML double-quoted scalar, doubling $ so
+// compose does not interpolate it from the host environment.
+func composeQuote(s string) string {
+	return strconv.Quote(strings.ReplaceAll(s, "$", "$$"))
+}
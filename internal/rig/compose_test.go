@@ -0,0 +1,114 @@
+package rig
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportCompose(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "rig.toml")
+	writeTestFile(t, configPath, `
+[tools]
+go = "1.25.1"
+
+[tasks]
+db = { command = "postgres -p 5432", ports = ["5432"] }
+api = { command = "go run ./cmd/api --addr :$PORT", cwd = "services/api", env = { PORT = "8080" }, depends_on = ["db", "generate"], ports = ["8080:8080"] }
+generate = "go generate ./..."
+seed = { command = "go run ./cmd/seed {{count}}", inputs = [{ name = "count", default = "10" }] }
+all = { steps = ["db", "api"], mode = "parallel" }
+
+[tasks.dev]
+command = "go run ./cmd/api"
+watch = ["**/*.go"]
+env_file = ".env"
+`, 0o644)
+	conf, _, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := ComposeServices(conf.Tasks, nil); err != nil || !reflect.DeepEqual(got, []string{"dev", "api", "db"}) {
+		t.Fatalf("default services = %v, %v", got, err)
+	}
+	if got, err := ComposeServices(conf.Tasks, []string{"all", "seed", "db"}); err != nil || !reflect.DeepEqual(got, []string{"db", "api", "seed"}) {
+		t.Fatalf("named services = %v, %v", got, err)
+	}
+	if _, err := ComposeServices(conf.Tasks, []string{"nope"}); err == nil {
+		t.Fatal("expected an error for an unknown task")
+	}
+
+	got, err := ExportCompose(conf, configPath, ComposeOptions{Tasks: []string{"all", "seed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Generated by `rig export compose` from rig.toml. Edit the tasks there and\n" +
+		"# re-run it rather than editing this file.\n" +
+		`services:
+  db:
+    image: "golang:1.25.1"
+    working_dir: "/app"
+    volumes:
+      - ".:/app"
+    command:
+      - "postgres"
+      - "-p"
+      - "5432"
+    ports:
+      - "5432"
+  api:
+    image: "golang:1.25.1"
+    working_dir: "/app/services/api"
+    volumes:
+      - ".:/app"
+    command:
+      - "sh"
+      - "-c"
+      - "go run ./cmd/api --addr \":$$PORT\""
+    environment:
+      "PORT": "8080"
+    ports:
+      - "8080:8080"
+    depends_on:
+      - "db"
+  seed:
+    image: "golang:1.25.1"
+    working_dir: "/app"
+    volumes:
+      - ".:/app"
+    command:
+      - "go"
+      - "run"
+      - "./cmd/seed"
+      - "10"
+`
+	if got != want {
+		t.Fatalf("compose =\n%s\nwant\n%s", got, want)
+	}
+
+	got, err = ExportCompose(conf, configPath, ComposeOptions{Tasks: []string{"dev"}, Image: "golang:1.25-alpine", ProjectDir: ".."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`image: "golang:1.25-alpine"`, `- "..:/app"`, "env_file:\n      - \"../.env\""} {
+		if !strings.Contains(got, s) {
+			t.Fatalf("expected %q in\n%s", s, got)
+		}
+	}
+}
+
+func TestValidatePort(t *testing.T) {
+	for _, p := range []string{"8080", "8080:80", "127.0.0.1:8080:80", "9000-9005:9000-9005", "53:53/udp", "[::1]:8080:80"} {
+		if err := ValidatePort(p); err != nil {
+			t.Errorf("%s: %v", p, err)
+		}
+	}
+	for _, p := range []string{"", "http", "80:", "1.2.3.4:80:80:80", "80/sctp"} {
+		if err := ValidatePort(p); err == nil {
+			t.Errorf("%s: expected an error", p)
+		}
+	}
+}
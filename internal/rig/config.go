@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
 
 	cfg "github.com/divijg19/rig/internal/config"
@@ -15,10 +17,19 @@ import (
 // LoadConfig loads rig.toml like config.Load, but enforces the strict task schema:
 //
 // - [tasks].<name> is either a string, or a table
-// - task tables may only contain: command, description, env, cwd, depends_on
-// - [tasks.dev] additionally supports: watch
+// - task tables may only contain: command, description, env, cwd, depends_on, requires, inputs, allow_failure, steps, mode, default_args, dirs, triggers, max_memory, cpu_limit, nice, sources, outputs
+// - a task table has either a command or steps (a composite task), not both
+// - [tasks.dev] supports: command, watch, watch_mode, poll_interval, ignore, gitignore, env_file, env_reload, env, cwd, depends_on, max_restarts, restart_window, test_on_save, profile, stop_signal, stop_timeout
 // - no other task fields are permitted
 func LoadConfig(startDir string) (*cfg.Config, string, error) {
+	conf, path, err := loadConfig(startDir)
+	if errors.Is(err, cfg.ErrConfigNotFound) {
+		return conf, path, WithCode(CodeConfigNotFound, err)
+	}
+	return conf, path, WithCode(CodeConfigInvalid, err)
+}
+
+func loadConfig(startDir string) (*cfg.Config, string, error) {
 	path, err := cfg.LocateConfig(startDir)
 	if err != nil {
 		return nil, "", err
@@ -31,38 +42,28 @@ func LoadConfig(startDir string) (*cfg.Config, string, error) {
 
 	base, err := parseConfigBytes(data)
 	if err != nil {
-		return nil, "", fmt.Errorf("unmarshal base config: %w", err)
+		return nil, "", cfg.Diagnose(path, data, err)
 	}
 
 	c := base
-	baseDir := filepath.Dir(path)
-
-	includes := c.Includes
-	if len(includes) == 0 {
-		includes = append(includes, parseIncludeList(data)...)
-	}
-
-	for _, rel := range includes {
-		incPath := rel
-		if !filepath.IsAbs(incPath) {
-			incPath = filepath.Join(baseDir, rel)
-		}
-		if _, err := os.Stat(incPath); err != nil {
-			alt := filepath.Join(baseDir, ".rig", rel)
-			if _, err2 := os.Stat(alt); err2 == nil {
-				incPath = alt
-			} else {
-				continue
-			}
-		}
-		incData, err := os.ReadFile(incPath)
-		if err != nil {
-			return nil, "", fmt.Errorf("read include %s: %w", incPath, err)
-		}
+	loaded, err := cfg.WalkIncludes(path, data, c.Includes, func(incPath string, incData []byte) (cfg.Config, error) {
 		inc, err := parseConfigBytes(incData)
 		if err != nil {
-			return nil, "", fmt.Errorf("unmarshal include %s: %w", incPath, err)
+			return cfg.Config{}, cfg.Diagnose(incPath, incData, err)
+		}
+		return inc, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if c.IncludeOverrides, err = cfg.CheckIncludeConflicts(path, c, loaded); err != nil {
+		return nil, "", err
+	}
+	for _, li := range loaded {
+		if li.Pin != nil {
+			c.RemoteIncludes = append(c.RemoteIncludes, *li.Pin)
 		}
+		inc := li.Config
 
 		if inc.Tasks != nil {
 			if c.Tasks == nil {
@@ -80,6 +81,38 @@ func LoadConfig(startDir string) (*cfg.Config, string, error) {
 				c.Tools[k] = v
 			}
 		}
+		if inc.Requires != nil {
+			if c.Requires == nil {
+				c.Requires = map[string]string{}
+			}
+			for k, v := range inc.Requires {
+				c.Requires[k] = v
+			}
+		}
+		if inc.Schedules != nil {
+			if c.Schedules == nil {
+				c.Schedules = map[string]string{}
+			}
+			for k, v := range inc.Schedules {
+				c.Schedules[k] = v
+			}
+		}
+		if inc.ToolAliases != nil {
+			if c.ToolAliases == nil {
+				c.ToolAliases = map[string]cfg.ToolAlias{}
+			}
+			for k, v := range inc.ToolAliases {
+				c.ToolAliases[k] = v
+			}
+		}
+		if inc.ToolProbes != nil {
+			if c.ToolProbes == nil {
+				c.ToolProbes = map[string]cfg.ToolProbe{}
+			}
+			for k, v := range inc.ToolProbes {
+				c.ToolProbes[k] = v
+			}
+		}
 		if inc.Profiles != nil {
 			if c.Profiles == nil {
 				c.Profiles = map[string]cfg.BuildProfile{}
@@ -93,15 +126,29 @@ func LoadConfig(startDir string) (*cfg.Config, string, error) {
 	if c.Tasks == nil {
 		c.Tasks = cfg.TasksMap{}
 	}
+	cfg.LiftRigVersion(&c)
+	if err := ApplyToolAliases(&c); err != nil {
+		return nil, "", err
+	}
 	return &c, path, nil
 }
 
 type rawConfig struct {
-	Project  cfg.Project                 `toml:"project"`
-	Tasks    map[string]any              `toml:"tasks"`
-	Tools    map[string]string           `toml:"tools"`
-	Includes []string                    `toml:"include"`
-	Profiles map[string]cfg.BuildProfile `toml:"profile"`
+	Project   cfg.Project                 `toml:"project"`
+	Tasks     map[string]any              `toml:"tasks"`
+	Tools     map[string]string           `toml:"tools"`
+	Includes  []any                       `toml:"include"`
+	Profiles  map[string]cfg.BuildProfile `toml:"profile"`
+	Workspace cfg.Workspace               `toml:"workspace"`
+	Requires  map[string]string           `toml:"requires"`
+	Schedules map[string]string           `toml:"schedules"`
+	Aliases   map[string]any              `toml:"tool-aliases"`
+	Probes    map[string]cfg.ToolProbe    `toml:"tool-probes"`
+	Lock      cfg.LockPolicy              `toml:"lock"`
+	Cache     cfg.CacheConfig             `toml:"cache"`
+	Paths     cfg.PathsConfig             `toml:"paths"`
+	CI        cfg.CIConfig                `toml:"ci"`
+	Plugins   map[string]cfg.Plugin       `toml:"plugins"`
 }
 
 func parseConfigBytes(b []byte) (cfg.Config, error) {
@@ -110,11 +157,38 @@ func parseConfigBytes(b []byte) (cfg.Config, error) {
 		return cfg.Config{}, err
 	}
 	c := cfg.Config{
-		Project:  raw.Project,
-		Tools:    raw.Tools,
-		Includes: raw.Includes,
-		Profiles: raw.Profiles,
+		Project:    raw.Project,
+		Tools:      raw.Tools,
+		Profiles:   raw.Profiles,
+		Workspace:  raw.Workspace,
+		Requires:   raw.Requires,
+		Schedules:  raw.Schedules,
+		Lock:       raw.Lock,
+		Cache:      raw.Cache,
+		Paths:      raw.Paths,
+		CI:         raw.CI,
+		Plugins:    raw.Plugins,
+		ToolProbes: raw.Probes,
+	}
+	if err := validatePlugins(c.Plugins); err != nil {
+		return cfg.Config{}, err
+	}
+	if err := validateEnvAllowlist(c.CI.EnvAllowlist); err != nil {
+		return cfg.Config{}, err
+	}
+	if err := validateToolProbes(c.ToolProbes); err != nil {
+		return cfg.Config{}, err
 	}
+	includes, err := cfg.ParseIncludeEntries(raw.Includes)
+	if err != nil {
+		return cfg.Config{}, err
+	}
+	c.Includes = includes
+	aliases, err := cfg.ParseToolAliases(raw.Aliases)
+	if err != nil {
+		return cfg.Config{}, err
+	}
+	c.ToolAliases = aliases
 	if len(raw.Tasks) > 0 {
 		tasks, err := parseTasks(raw.Tasks)
 		if err != nil {
@@ -129,18 +203,52 @@ func parseConfigBytes(b []byte) (cfg.Config, error) {
 }
 
 func parseTasks(raw map[string]any) (cfg.TasksMap, error) {
+	raw, namespaced, err := cfg.FlattenTaskNamespaces(raw)
+	if err != nil {
+		return nil, err
+	}
 	out := make(cfg.TasksMap, len(raw))
 	for name, v := range raw {
 		t, err := parseTask(name, v)
 		if err != nil {
-			return nil, fmt.Errorf("task %q: %w", name, err)
+			return nil, &cfg.TaskError{Task: name, Err: err}
 		}
+		t.Namespaced = namespaced[name]
 		out[name] = t
 	}
 	return out, nil
 }
 
+// parseTask parses a task as it applies on this platform. Every platform
+// variant ([tasks.<name>.windows], ...) is validated, not only the one used.
 func parseTask(name string, v any) (cfg.Task, error) {
+	tbl, ok := v.(map[string]any)
+	variants := cfg.TaskVariants(tbl)
+	if !ok || len(variants) == 0 {
+		return parseTaskValue(name, v)
+	}
+	for _, p := range variants {
+		resolved, err := cfg.PlatformTask(tbl, p)
+		if err != nil {
+			return cfg.Task{}, err
+		}
+		if _, err := parseTaskValue(name, resolved); err != nil {
+			return cfg.Task{}, fmt.Errorf("%w (in [tasks.%s.%s])", err, name, p)
+		}
+	}
+	resolved, err := cfg.PlatformTask(tbl, runtime.GOOS)
+	if err != nil {
+		return cfg.Task{}, err
+	}
+	_, hasCmd := resolved["command"]
+	_, hasSteps := resolved["steps"]
+	if !hasCmd && !hasSteps && name != "dev" {
+		return cfg.Task{}, fmt.Errorf("no command for %s (variants: %s); add one to [tasks.%s] or [tasks.%s.%s]", runtime.GOOS, strings.Join(variants, ", "), name, name, runtime.GOOS)
+	}
+	return parseTaskValue(name, resolved)
+}
+
+func parseTaskValue(name string, v any) (cfg.Task, error) {
 	switch val := v.(type) {
 	case string:
 		cmd := strings.TrimSpace(val)
@@ -149,17 +257,36 @@ func parseTask(name string, v any) (cfg.Task, error) {
 		}
 		return cfg.Task{Command: cmd}, nil
 	case map[string]any:
-		// v0.3: [tasks.dev] is a strict schema: only { command, watch }.
+		// v0.3: [tasks.dev] is a strict schema: { command, watch, watch_mode,
+		// poll_interval, ignore, gitignore, env_file, env_reload, env, cwd,
+		// depends_on, max_restarts, restart_window, test_on_save, profile, ports,
+		// stop_signal, stop_timeout }.
 		// We intentionally defer "non-empty" validation to the dev runtime so
 		// that dev UX error strings remain stable.
 		if name == "dev" {
 			allowed := map[string]struct{}{
-				"command": {},
-				"watch":   {},
+				"command":        {},
+				"watch":          {},
+				"watch_mode":     {},
+				"poll_interval":  {},
+				"ignore":         {},
+				"gitignore":      {},
+				"env_file":       {},
+				"env_reload":     {},
+				"env":            {},
+				"cwd":            {},
+				"depends_on":     {},
+				"max_restarts":   {},
+				"restart_window": {},
+				"test_on_save":   {},
+				"profile":        {},
+				"ports":          {},
+				"stop_signal":    {},
+				"stop_timeout":   {},
 			}
 			for k := range val {
 				if _, ok := allowed[k]; !ok {
-					return cfg.Task{}, fmt.Errorf("unsupported field %q (allowed: command, watch)", k)
+					return cfg.Task{}, fmt.Errorf("unsupported field %q (allowed: command, watch, watch_mode, poll_interval, ignore, gitignore, env_file, env_reload, env, cwd, depends_on, max_restarts, restart_window, test_on_save, profile, ports, stop_signal, stop_timeout)", k)
 				}
 			}
 
@@ -188,33 +315,161 @@ func parseTask(name string, v any) (cfg.Task, error) {
 				}
 			}
 
-			return cfg.Task{Command: cmd, Watch: watch}, nil
+			t := cfg.Task{Command: cmd, Watch: watch}
+			var err error
+			if t.Env, t.Cwd, t.DependsOn, err = parseTaskEnvCwdDeps(val); err != nil {
+				return cfg.Task{}, err
+			}
+			if raw, ok := val["watch_mode"]; ok {
+				s, ok := raw.(string)
+				if !ok {
+					return cfg.Task{}, fmt.Errorf("watch_mode must be a string, got %T", raw)
+				}
+				if _, err := ParseWatchMode(s); err != nil {
+					return cfg.Task{}, err
+				}
+				t.WatchMode = strings.TrimSpace(s)
+			}
+			if raw, ok := val["poll_interval"]; ok {
+				s, ok := raw.(string)
+				if !ok {
+					return cfg.Task{}, fmt.Errorf("poll_interval must be a string, got %T", raw)
+				}
+				if _, err := ParsePollInterval(s); err != nil {
+					return cfg.Task{}, err
+				}
+				t.PollInterval = strings.TrimSpace(s)
+			}
+			if raw, ok := val["ignore"]; ok {
+				arr, ok := raw.([]any)
+				if !ok {
+					return cfg.Task{}, fmt.Errorf("ignore must be an array of strings, got %T", raw)
+				}
+				for _, it := range arr {
+					s, ok := it.(string)
+					if !ok {
+						return cfg.Task{}, fmt.Errorf("ignore items must be strings, got %T", it)
+					}
+					t.Ignore = append(t.Ignore, strings.TrimSpace(s))
+				}
+			}
+			if raw, ok := val["gitignore"]; ok {
+				b, ok := raw.(bool)
+				if !ok {
+					return cfg.Task{}, fmt.Errorf("gitignore must be a boolean, got %T", raw)
+				}
+				t.Gitignore = &b
+			}
+			for _, f := range []struct {
+				key string
+				dst *string
+			}{{"env_file", &t.EnvFile}, {"env_reload", &t.EnvReload}} {
+				raw, ok := val[f.key]
+				if !ok {
+					continue
+				}
+				s, ok := raw.(string)
+				if !ok {
+					return cfg.Task{}, fmt.Errorf("%s must be a string, got %T", f.key, raw)
+				}
+				*f.dst = strings.TrimSpace(s)
+			}
+			if raw, ok := val["max_restarts"]; ok {
+				n, ok := raw.(int64)
+				if !ok || n < 0 {
+					return cfg.Task{}, fmt.Errorf("max_restarts must be a non-negative integer, got %v", raw)
+				}
+				mr := int(n)
+				t.MaxRestarts = &mr
+			}
+			if raw, ok := val["restart_window"]; ok {
+				s, ok := raw.(string)
+				if !ok {
+					return cfg.Task{}, fmt.Errorf("restart_window must be a string, got %T", raw)
+				}
+				if _, err := ParseRestartWindow(s); err != nil {
+					return cfg.Task{}, err
+				}
+				t.RestartWindow = strings.TrimSpace(s)
+			}
+			if raw, ok := val["test_on_save"]; ok {
+				b, ok := raw.(bool)
+				if !ok {
+					return cfg.Task{}, fmt.Errorf("test_on_save must be a boolean, got %T", raw)
+				}
+				t.TestOnSave = b
+			}
+			if raw, ok := val["profile"]; ok {
+				s, ok := raw.(string)
+				if !ok {
+					return cfg.Task{}, fmt.Errorf("profile must be a string, got %T", raw)
+				}
+				t.Profile = strings.TrimSpace(s)
+			}
+			if err := parseTaskStop(val, &t); err != nil {
+				return cfg.Task{}, err
+			}
+			if t.Ports, err = parseTaskPorts(val); err != nil {
+				return cfg.Task{}, err
+			}
+			return t, nil
 		}
 
 		allowed := map[string]struct{}{
-			"command":     {},
-			"description": {},
-			"env":         {},
-			"cwd":         {},
-			"depends_on":  {},
+			"command":       {},
+			"description":   {},
+			"env":           {},
+			"cwd":           {},
+			"depends_on":    {},
+			"requires":      {},
+			"inputs":        {},
+			"allow_failure": {},
+			"steps":         {},
+			"mode":          {},
+			"default_args":  {},
+			"dirs":          {},
+			"triggers":      {},
+			"max_memory":    {},
+			"cpu_limit":     {},
+			"nice":          {},
+			"sources":       {},
+			"outputs":       {},
+			"ports":         {},
+			"output_umask":  {},
+			"output_owner":  {},
+			"internal":      {},
+			"serial":        {},
+			"stop_signal":   {},
+			"stop_timeout":  {},
+			"timeout":       {},
+			"retries":       {},
 		}
 		for k := range val {
 			if _, ok := allowed[k]; !ok {
-				return cfg.Task{}, fmt.Errorf("unsupported field %q (allowed: command, description, env, cwd, depends_on)", k)
+				return cfg.Task{}, fmt.Errorf("unsupported field %q (allowed: command, description, env, cwd, depends_on, requires, inputs, allow_failure, steps, mode, default_args, dirs, triggers, max_memory, cpu_limit, nice, sources, outputs, ports, output_umask, output_owner, internal, serial, stop_signal, stop_timeout, timeout, retries)", k)
 			}
 		}
 
-		cmdRaw, ok := val["command"]
-		if !ok {
-			return cfg.Task{}, errors.New("missing required field \"command\"")
-		}
-		cmd, ok := cmdRaw.(string)
-		if !ok {
-			return cfg.Task{}, fmt.Errorf("command must be a string, got %T", cmdRaw)
+		steps, mode, err := parseTaskSteps(val)
+		if err != nil {
+			return cfg.Task{}, err
 		}
-		cmd = strings.TrimSpace(cmd)
-		if cmd == "" {
-			return cfg.Task{}, errors.New("command must be non-empty")
+
+		cmd := ""
+		cmdRaw, hasCmd := val["command"]
+		switch {
+		case hasCmd && steps != nil:
+			return cfg.Task{}, errors.New("command and steps are mutually exclusive")
+		case hasCmd:
+			s, ok := cmdRaw.(string)
+			if !ok {
+				return cfg.Task{}, fmt.Errorf("command must be a string, got %T", cmdRaw)
+			}
+			if cmd = strings.TrimSpace(s); cmd == "" {
+				return cfg.Task{}, errors.New("command must be non-empty")
+			}
+		case steps == nil:
+			return cfg.Task{}, errors.New("missing required field \"command\" (or \"steps\")")
 		}
 
 		desc := ""
@@ -226,72 +481,411 @@ func parseTask(name string, v any) (cfg.Task, error) {
 			desc = strings.TrimSpace(s)
 		}
 
-		var env map[string]string
-		if envRaw, ok := val["env"]; ok {
-			tbl, ok := envRaw.(map[string]any)
+		env, cwd, deps, err := parseTaskEnvCwdDeps(val)
+		if err != nil {
+			return cfg.Task{}, err
+		}
+
+		var requires []string
+		if reqRaw, ok := val["requires"]; ok {
+			arr, ok := reqRaw.([]any)
 			if !ok {
-				return cfg.Task{}, fmt.Errorf("env must be a table, got %T", envRaw)
+				return cfg.Task{}, fmt.Errorf("requires must be an array of strings, got %T", reqRaw)
 			}
-			env = make(map[string]string, len(tbl))
-			for k, v := range tbl {
-				s, ok := v.(string)
+			for _, it := range arr {
+				s, ok := it.(string)
 				if !ok {
-					return cfg.Task{}, fmt.Errorf("env %q must be a string, got %T", k, v)
+					return cfg.Task{}, fmt.Errorf("requires items must be strings, got %T", it)
+				}
+				if s = strings.TrimSpace(s); s != "" {
+					requires = append(requires, s)
 				}
-				env[k] = s
 			}
 		}
 
-		cwd := ""
-		if cwdRaw, ok := val["cwd"]; ok {
-			s, ok := cwdRaw.(string)
+		var inputs []cfg.TaskInput
+		if inRaw, ok := val["inputs"]; ok {
+			var err error
+			if inputs, err = parseTaskInputs(inRaw); err != nil {
+				return cfg.Task{}, err
+			}
+		}
+
+		allowFailure := false
+		if raw, ok := val["allow_failure"]; ok {
+			b, ok := raw.(bool)
 			if !ok {
-				return cfg.Task{}, fmt.Errorf("cwd must be a string, got %T", cwdRaw)
+				return cfg.Task{}, fmt.Errorf("allow_failure must be a boolean, got %T", raw)
 			}
-			cwd = strings.TrimSpace(s)
+			allowFailure = b
 		}
 
-		depsRaw, hasDeps := val["depends_on"], false
-		if _, ok := val["depends_on"]; ok {
-			hasDeps = true
+		var defaultArgs []string
+		if raw, ok := val["default_args"]; ok {
+			if steps != nil {
+				return cfg.Task{}, errors.New("default_args requires a command (composite tasks take no arguments)")
+			}
+			arr, ok := raw.([]any)
+			if !ok {
+				return cfg.Task{}, fmt.Errorf("default_args must be an array of strings, got %T", raw)
+			}
+			for _, it := range arr {
+				s, ok := it.(string)
+				if !ok {
+					return cfg.Task{}, fmt.Errorf("default_args items must be strings, got %T", it)
+				}
+				defaultArgs = append(defaultArgs, s)
+			}
 		}
-		var deps []string
-		if hasDeps {
-			arr, ok := depsRaw.([]any)
+
+		var dirs []string
+		if raw, ok := val["dirs"]; ok {
+			switch {
+			case steps != nil:
+				return cfg.Task{}, errors.New("dirs requires a command (composite tasks run other tasks)")
+			case cwd != "":
+				return cfg.Task{}, errors.New("dirs and cwd are mutually exclusive")
+			}
+			arr, ok := raw.([]any)
 			if !ok {
-				return cfg.Task{}, fmt.Errorf("depends_on must be an array of strings, got %T", depsRaw)
+				return cfg.Task{}, fmt.Errorf("dirs must be an array of strings, got %T", raw)
 			}
 			for _, it := range arr {
 				s, ok := it.(string)
 				if !ok {
-					return cfg.Task{}, fmt.Errorf("depends_on items must be strings, got %T", it)
+					return cfg.Task{}, fmt.Errorf("dirs items must be strings, got %T", it)
+				}
+				if s = strings.TrimSpace(s); s != "" {
+					dirs = append(dirs, s)
 				}
-				deps = append(deps, s)
 			}
 		}
 
-		return cfg.Task{Command: cmd, Description: desc, Env: env, Cwd: cwd, DependsOn: deps}, nil
+		triggers, err := parseTaskGlobs(val, "triggers")
+		if err != nil {
+			return cfg.Task{}, err
+		}
+		sources, err := parseTaskGlobs(val, "sources")
+		if err != nil {
+			return cfg.Task{}, err
+		}
+		outputs, err := parseTaskGlobs(val, "outputs")
+		if err != nil {
+			return cfg.Task{}, err
+		}
+		if steps != nil && (sources != nil || outputs != nil) {
+			return cfg.Task{}, errors.New("sources and outputs require a command (composite tasks run other tasks)")
+		}
+
+		t := cfg.Task{Command: cmd, Description: desc, Env: env, Cwd: cwd, DependsOn: deps, Requires: requires, Inputs: inputs, AllowFailure: allowFailure, Steps: steps, Mode: mode, DefaultArgs: defaultArgs, Dirs: dirs, Triggers: triggers, Sources: sources, Outputs: outputs}
+		if err := parseTaskLimits(val, &t); err != nil {
+			return cfg.Task{}, err
+		}
+		if t.Ports, err = parseTaskPorts(val); err != nil {
+			return cfg.Task{}, err
+		}
+		if steps != nil && t.Ports != nil {
+			return cfg.Task{}, errors.New("ports require a command (composite tasks run other tasks)")
+		}
+		for _, f := range []struct {
+			key string
+			dst *string
+		}{{"output_umask", &t.OutputUmask}, {"output_owner", &t.OutputOwner}} {
+			raw, ok := val[f.key]
+			if !ok {
+				continue
+			}
+			s, ok := raw.(string)
+			if !ok {
+				return cfg.Task{}, fmt.Errorf("%s must be a string, got %T", f.key, raw)
+			}
+			*f.dst = strings.TrimSpace(s)
+		}
+		if _, err := TaskOutputPolicy(t); err != nil {
+			return cfg.Task{}, err
+		}
+		if raw, ok := val["internal"]; ok {
+			b, ok := raw.(bool)
+			if !ok {
+				return cfg.Task{}, fmt.Errorf("internal must be a boolean, got %T", raw)
+			}
+			t.Internal = b
+		}
+		if raw, ok := val["serial"]; ok {
+			b, ok := raw.(bool)
+			if !ok {
+				return cfg.Task{}, fmt.Errorf("serial must be a boolean, got %T", raw)
+			}
+			t.Serial = b
+		}
+		if err := parseTaskStop(val, &t); err != nil {
+			return cfg.Task{}, err
+		}
+		if raw, ok := val["timeout"]; ok {
+			s, ok := raw.(string)
+			if !ok {
+				return cfg.Task{}, fmt.Errorf("timeout must be a string, got %T", raw)
+			}
+			if _, err := ParseTaskTimeout(s); err != nil {
+				return cfg.Task{}, err
+			}
+			t.Timeout = strings.TrimSpace(s)
+		}
+		if raw, ok := val["retries"]; ok {
+			n, ok := raw.(int64)
+			if !ok || n < 0 {
+				return cfg.Task{}, fmt.Errorf("retries must be a non-negative integer, got %v", raw)
+			}
+			t.Retries = int(n)
+		}
+		if steps != nil && (t.Timeout != "" || t.Retries > 0) {
+			return cfg.Task{}, errors.New("timeout and retries require a command (composite tasks run other tasks)")
+		}
+		return t, nil
 	default:
 		return cfg.Task{}, fmt.Errorf("task must be string or table, got %T", v)
 	}
 }
 
-func parseIncludeList(b []byte) []string {
-	s := string(b)
-	re := regexp.MustCompile(`(?m)^\s*include\s*=\s*\[([^\]]*)\]`)
-	m := re.FindStringSubmatch(s)
-	if len(m) < 2 {
-		return nil
+// parseTaskStop reads stop_signal and stop_timeout, shared by [tasks.dev]
+// and regular task tables.
+func parseTaskStop(val map[string]any, t *cfg.Task) error {
+	for _, f := range []struct {
+		key string
+		dst *string
+	}{{"stop_signal", &t.StopSignal}, {"stop_timeout", &t.StopTimeout}} {
+		raw, ok := val[f.key]
+		if !ok {
+			continue
+		}
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a string, got %T", f.key, raw)
+		}
+		*f.dst = strings.TrimSpace(s)
+	}
+	_, err := TaskStopPolicy(*t, StopPolicy{})
+	return err
+}
+
+// parseTaskEnvCwdDeps reads the env, cwd, and depends_on fields shared by
+// [tasks.dev] and regular task tables.
+func parseTaskEnvCwdDeps(val map[string]any) (env map[string]string, cwd string, deps []string, err error) {
+	if envRaw, ok := val["env"]; ok {
+		tbl, ok := envRaw.(map[string]any)
+		if !ok {
+			return nil, "", nil, fmt.Errorf("env must be a table, got %T", envRaw)
+		}
+		env = make(map[string]string, len(tbl))
+		for k, v := range tbl {
+			s, ok := v.(string)
+			if !ok {
+				return nil, "", nil, fmt.Errorf("env %q must be a string, got %T", k, v)
+			}
+			env[k] = s
+		}
+	}
+
+	if cwdRaw, ok := val["cwd"]; ok {
+		s, ok := cwdRaw.(string)
+		if !ok {
+			return nil, "", nil, fmt.Errorf("cwd must be a string, got %T", cwdRaw)
+		}
+		cwd = strings.TrimSpace(s)
+	}
+
+	depsRaw, hasDeps := val["depends_on"], false
+	if _, ok := val["depends_on"]; ok {
+		hasDeps = true
+	}
+	if hasDeps {
+		arr, ok := depsRaw.([]any)
+		if !ok {
+			return nil, "", nil, fmt.Errorf("depends_on must be an array of strings, got %T", depsRaw)
+		}
+		for _, it := range arr {
+			s, ok := it.(string)
+			if !ok {
+				return nil, "", nil, fmt.Errorf("depends_on items must be strings, got %T", it)
+			}
+			deps = append(deps, s)
+		}
+	}
+	return env, cwd, deps, nil
+}
+
+// parseTaskGlobs reads an array of file patterns (relative to rig.toml),
+// dropping blank entries and rejecting malformed globs.
+func parseTaskGlobs(val map[string]any, key string) ([]string, error) {
+	raw, ok := val[key]
+	if !ok {
+		return nil, nil
+	}
+	arr, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings, got %T", key, raw)
 	}
-	inner := m[1]
-	parts := strings.Split(inner, ",")
 	var out []string
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		p = strings.Trim(p, "\"")
-		if p != "" {
-			out = append(out, p)
+	for _, it := range arr {
+		s, ok := it.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s items must be strings, got %T", key, it)
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if _, err := filepath.Match(s, ""); err != nil {
+			return nil, fmt.Errorf("%s pattern %q: %w", key, s, err)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// parseTaskPorts reads the ports `rig export compose` publishes for a task.
+func parseTaskPorts(val map[string]any) ([]string, error) {
+	raw, ok := val["ports"]
+	if !ok {
+		return nil, nil
+	}
+	arr, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("ports must be an array of strings, got %T", raw)
+	}
+	ports := make([]string, 0, len(arr))
+	for _, it := range arr {
+		s, ok := it.(string)
+		if !ok {
+			return nil, fmt.Errorf("ports items must be strings, got %T", it)
 		}
+		if err := ValidatePort(s); err != nil {
+			return nil, err
+		}
+		ports = append(ports, strings.TrimSpace(s))
+	}
+	return ports, nil
+}
+
+// parseTaskLimits reads max_memory (a size string or byte count), cpu_limit
+// and nice into t and validates them.
+func parseTaskLimits(val map[string]any, t *cfg.Task) error {
+	_, hasMem := val["max_memory"]
+	_, hasCPU := val["cpu_limit"]
+	_, hasNice := val["nice"]
+	if (hasMem || hasCPU || hasNice) && t.Steps != nil {
+		return errors.New("max_memory, cpu_limit and nice require a command (composite tasks run other tasks)")
 	}
-	return out
+	switch raw := val["max_memory"].(type) {
+	case nil:
+	case string:
+		t.MaxMemory = strings.TrimSpace(raw)
+	case int64:
+		t.MaxMemory = strconv.FormatInt(raw, 10)
+	default:
+		return fmt.Errorf("max_memory must be a size string or an integer, got %T", raw)
+	}
+	switch raw := val["cpu_limit"].(type) {
+	case nil:
+	case float64:
+		t.CPULimit = raw
+	case int64:
+		t.CPULimit = float64(raw)
+	default:
+		return fmt.Errorf("cpu_limit must be a number, got %T", raw)
+	}
+	if hasCPU && t.CPULimit <= 0 {
+		return fmt.Errorf("cpu_limit must be a positive number of CPUs, got %v", t.CPULimit)
+	}
+	switch raw := val["nice"].(type) {
+	case nil:
+	case int64:
+		n := int(raw)
+		t.Nice = &n
+	default:
+		return fmt.Errorf("nice must be an integer, got %T", raw)
+	}
+	_, err := TaskLimits(*t)
+	return err
+}
+
+// parseTaskSteps reads the steps and mode fields of a composite task. mode is
+// only meaningful alongside steps.
+func parseTaskSteps(val map[string]any) (steps []string, mode string, err error) {
+	if raw, ok := val["steps"]; ok {
+		arr, ok := raw.([]any)
+		if !ok {
+			return nil, "", fmt.Errorf("steps must be an array of strings, got %T", raw)
+		}
+		if len(arr) == 0 {
+			return nil, "", errors.New("steps must be non-empty")
+		}
+		for _, it := range arr {
+			s, ok := it.(string)
+			if !ok || strings.TrimSpace(s) == "" {
+				return nil, "", fmt.Errorf("steps items must be non-empty strings, got %v", it)
+			}
+			steps = append(steps, strings.TrimSpace(s))
+		}
+	}
+	if raw, ok := val["mode"]; ok {
+		s, ok := raw.(string)
+		if !ok {
+			return nil, "", fmt.Errorf("mode must be a string, got %T", raw)
+		}
+		if steps == nil {
+			return nil, "", errors.New("mode requires steps")
+		}
+		mode = strings.TrimSpace(s)
+		if mode != cfg.TaskModeSerial && mode != cfg.TaskModeParallel {
+			return nil, "", fmt.Errorf("invalid mode %q (expected serial|parallel)", s)
+		}
+	}
+	return steps, mode, nil
+}
+
+var inputNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func parseTaskInputs(v any) ([]cfg.TaskInput, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("inputs must be an array of tables, got %T", v)
+	}
+	seen := make(map[string]struct{}, len(arr))
+	out := make([]cfg.TaskInput, 0, len(arr))
+	for _, it := range arr {
+		tbl, ok := it.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("inputs items must be tables, got %T", it)
+		}
+		var in cfg.TaskInput
+		for k, raw := range tbl {
+			s, ok := raw.(string)
+			if !ok {
+				return nil, fmt.Errorf("inputs.%s must be a string, got %T", k, raw)
+			}
+			switch k {
+			case "name":
+				in.Name = strings.TrimSpace(s)
+			case "prompt":
+				in.Prompt = strings.TrimSpace(s)
+			case "default":
+				in.Default = s
+			default:
+				return nil, fmt.Errorf("unsupported input field %q (allowed: name, prompt, default)", k)
+			}
+		}
+		if !inputNameRe.MatchString(in.Name) {
+			return nil, fmt.Errorf("input name %q must match [A-Za-z_][A-Za-z0-9_]*", in.Name)
+		}
+		if argTemplateRe.MatchString("{{" + in.Name + "}}") {
+			return nil, fmt.Errorf("input name %q is reserved for passthrough arguments ({{args}}, {{arg0}}, ...)", in.Name)
+		}
+		if _, dup := seen[in.Name]; dup {
+			return nil, fmt.Errorf("duplicate input %q", in.Name)
+		}
+		seen[in.Name] = struct{}{}
+		out = append(out, in)
+	}
+	return out, nil
 }
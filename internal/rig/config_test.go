@@ -3,8 +3,12 @@ package rig
 import (
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
+
+	cfg "github.com/divijg19/rig/internal/config"
 )
 
 func TestLoadConfig_AllowsTaskDescription(t *testing.T) {
@@ -52,3 +56,241 @@ bad = { command = "echo hi", no_such_field = "nope" }
 		t.Fatalf("expected unsupported field error, got: %v", err)
 	}
 }
+
+func TestLoadConfig_ErrorsPointAtSource(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name, config, want string
+	}{
+		{"inline field", "[tasks]\nbad = { command = \"echo hi\", no_such_field = \"nope\" }\n", "rig.toml:2:30: task \"bad\": unsupported field \"no_such_field\""},
+		{"table field", "[tasks]\nok = \"echo\"\n\n[tasks.dev]\ncommand = \"go run .\"\nwatch = \"*.go\"\n", "rig.toml:6:1: task \"dev\": watch must be an array"},
+		{"syntax", "[tasks]\nlint = [\n", "rig.toml:2:8:"},
+		{"type", "[tools]\ngo = 5\n", "rig.toml:2:6: cannot decode TOML integer"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := os.WriteFile(filepath.Join(dir, "rig.toml"), []byte(c.config), 0o644); err != nil {
+				t.Fatalf("write rig.toml: %v", err)
+			}
+			_, _, err := LoadConfig(dir)
+			if err == nil || !strings.Contains(err.Error(), c.want) || !strings.Contains(err.Error(), "^") {
+				t.Fatalf("expected %q with a caret, got: %v", c.want, err)
+			}
+			if CodeOf(err) != CodeConfigInvalid {
+				t.Fatalf("expected %s, got %s", CodeConfigInvalid, CodeOf(err))
+			}
+		})
+	}
+}
+
+func TestLoadConfig_DevAllowsEnvCwdDependsOn(t *testing.T) {
+	dir := t.TempDir()
+	config := `
+[tasks]
+generate = "go generate ./..."
+
+[tasks.dev]
+command = "go run ."
+watch = ["**/*.go"]
+cwd = "cmd/api"
+depends_on = ["generate"]
+
+[tasks.dev.env]
+APP_ENV = "dev"
+`
+	if err := os.WriteFile(filepath.Join(dir, "rig.toml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write rig.toml: %v", err)
+	}
+
+	conf, _, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	dev := conf.Tasks["dev"]
+	if dev.Cwd != "cmd/api" || dev.Env["APP_ENV"] != "dev" || strings.Join(dev.DependsOn, ",") != "generate" {
+		t.Fatalf("unexpected dev task: %+v", dev)
+	}
+	deps, err := TaskDependencies(conf.Tasks, "dev")
+	if err != nil || strings.Join(deps, ",") != "generate" {
+		t.Fatalf("TaskDependencies = %v, %v", deps, err)
+	}
+}
+
+func TestLoadConfig_CompositeSteps(t *testing.T) {
+	cases := map[string]string{
+		`ci = { steps = ["a"], mode = "parallel" }`:  "",
+		`ci = { steps = ["a"], command = "true" }`:   "mutually exclusive",
+		`ci = { steps = ["a"], mode = "fanout" }`:    "invalid mode",
+		`ci = { steps = [] }`:                        "steps must be non-empty",
+		`ci = { command = "true", mode = "serial" }`: "mode requires steps",
+		`ci = { description = "nothing to run" }`:    "missing required field",
+	}
+	for task, want := range cases {
+		dir := t.TempDir()
+		config := "[tasks]\na = \"true\"\n" + task + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "rig.toml"), []byte(config), 0o644); err != nil {
+			t.Fatalf("write rig.toml: %v", err)
+		}
+		conf, _, err := LoadConfig(dir)
+		if want == "" {
+			if err != nil {
+				t.Fatalf("%s: LoadConfig: %v", task, err)
+			}
+			if got := conf.Tasks["ci"]; got.Mode != "parallel" || strings.Join(got.Steps, ",") != "a" {
+				t.Fatalf("%s: got steps=%v mode=%q", task, got.Steps, got.Mode)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected error containing %q, got %v", task, want, err)
+		}
+	}
+}
+
+func TestLoadConfig_NestedIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), "include = [\".rig/ci.toml\"]\n\n[tasks]\nbuild = \"go build .\"\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, ".rig", "ci.toml"), "include = [\"lint.toml\"]\n\n[tasks]\nci = { steps = [\"build\", \"lint\"] }\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, ".rig", "lint.toml"), "[tasks]\nlint = \"go vet ./...\"\n", 0o644)
+
+	conf, _, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	for _, name := range []string{"build", "ci", "lint"} {
+		if _, ok := conf.Tasks[name]; !ok {
+			t.Fatalf("expected task %q from nested includes, got %v", name, TaskNames(conf.Tasks))
+		}
+	}
+
+	writeTestFile(t, filepath.Join(dir, ".rig", "lint.toml"), "include = [\"ci.toml\"]\n", 0o644)
+	_, _, err = LoadConfig(dir)
+	if err == nil || !strings.Contains(err.Error(), "include cycle:") || CodeOf(err) != CodeConfigInvalid {
+		t.Fatalf("expected include cycle error, got %v", err)
+	}
+}
+
+func TestLoadConfig_PlatformVariants(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks.build]
+command = "make"
+env = { A = "1", B = "1" }
+
+[tasks.build.unix]
+command = "make unix"
+
+[tasks.build.windows]
+command = "nmake"
+env = { B = "2" }
+
+[tasks.ci]
+steps = ["build"]
+
+[tasks.ci.`+runtime.GOOS+`]
+command = "ci.sh"
+`, 0o644)
+	conf, _, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	build := conf.Tasks["build"]
+	wantCmd, wantB := "make unix", "1"
+	if runtime.GOOS == "windows" {
+		wantCmd, wantB = "nmake", "2"
+	}
+	if build.Command != wantCmd || build.Env["A"] != "1" || build.Env["B"] != wantB {
+		t.Fatalf("build on %s: command=%q env=%v", runtime.GOOS, build.Command, build.Env)
+	}
+	if ci := conf.Tasks["ci"]; ci.Command != "ci.sh" || ci.Steps != nil {
+		t.Fatalf("ci: a command variant should replace steps, got command=%q steps=%v", ci.Command, ci.Steps)
+	}
+
+	// A task defined only as [tasks.x.unix] runs on unix and has no command elsewhere.
+	unixOnly := map[string]any{"unix": map[string]any{"command": "make"}}
+	for goos, want := range map[string]any{"linux": "make", "darwin": "make", "windows": nil} {
+		if got, err := cfg.PlatformTask(unixOnly, goos); err != nil || got["command"] != want {
+			t.Fatalf("unix-only task on %s: %v, %v; want command %v", goos, got, err, want)
+		}
+	}
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), "[tasks.x.unix]\ncommand = \"make\"\n", 0o644)
+	conf, _, err = LoadConfig(dir)
+	if runtime.GOOS == "windows" {
+		if err == nil || !strings.Contains(err.Error(), "no command for windows (variants: unix)") {
+			t.Fatalf("expected a unix-only task to fail on windows, got %v", err)
+		}
+	} else if err != nil || conf.Tasks["x"].Command != "make" {
+		t.Fatalf("unix-only task on %s: %v", runtime.GOOS, err)
+	}
+
+	for body, want := range map[string]string{
+		// A variant for another platform is still validated.
+		"[tasks.build]\ncommand = \"make\"\n[tasks.build.plan9]\ncomand = \"mk\"\n":                                       `unsupported field "comand"`,
+		"[tasks.build.plan9]\ncommand = \"mk\"\n":                                                                         "no command for " + runtime.GOOS + " (variants: plan9); add one to [tasks.build] or [tasks.build." + runtime.GOOS + "]",
+		"[tasks.build]\ncommand = \"make\"\nplan9 = \"mk\"\n":                                                             "plan9 must be a table of task fields",
+		"[tasks.build]\ncommand = \"make\"\n[tasks.build.unix]\ncommand = \"make\"\n[tasks.build.unix.linux]\nnice = 1\n": "platform variants cannot be nested",
+	} {
+		writeTestFile(t, filepath.Join(dir, "rig.toml"), body, 0o644)
+		_, _, err := LoadConfig(dir)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestLoadConfig_TaskNamespaces(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks]
+build = "go build ./..."
+
+[tasks.db.migrate]
+command = "migrate up"
+depends_on = ["build"]
+
+[tasks.db]
+seed = { command = "seed", depends_on = ["db:migrate"] }
+
+[tasks.gen.proto.go]
+command = "buf generate"
+
+[tasks.clean.unix]
+command = "rm -rf bin"
+
+# "unit" is close to "unix", but e2e is not a platform: a namespace.
+[tasks.test.unit]
+command = "go test ./..."
+
+[tasks.test.e2e]
+command = "go test -tags e2e ./..."
+`, 0o644)
+	conf, _, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	names := TaskNames(conf.Tasks)
+	slices.Sort(names)
+	if got := strings.Join(names, ","); got != "build,clean,db:migrate,db:seed,gen:proto:go,test:e2e,test:unit" {
+		t.Fatalf("tasks = %s", got)
+	}
+	if !conf.Tasks["db:migrate"].Namespaced || conf.Tasks["build"].Namespaced || conf.Tasks["clean"].Namespaced {
+		t.Fatalf("Namespaced should be set only for tasks in namespace tables")
+	}
+	if got := conf.Tasks["db:seed"].DependsOn; len(got) != 1 || got[0] != "db:migrate" {
+		t.Fatalf("db:seed depends_on = %v", got)
+	}
+
+	for body, want := range map[string]string{
+		"[tasks]\n\"db:migrate\" = \"a\"\n[tasks.db.migrate]\ncommand = \"b\"\n":     `task "db:migrate" is defined twice: by name and as [tasks.db.migrate]`,
+		"[tasks.db.migrate]\ncomand = \"b\"\n":                                       `rig.toml:2:1: task "db:migrate": unsupported field "comand"`,
+		"[tasks.build.windowz]\ncommand = \"b\"\n":                                   `[tasks.build.windowz]: unknown platform "windowz"; did you mean "windows"?`,
+		"[tasks.gen.proto.Linux]\ncommand = \"b\"\n":                                 `[tasks.gen.proto.Linux]: unknown platform "Linux"; did you mean "linux"?`,
+		"[tasks.build.unx]\ncommand = \"b\"\n[tasks.build.darwn]\ncommand = \"c\"\n": `[tasks.build.darwn]: unknown platform "darwn"; did you mean "darwin"?`,
+	} {
+		writeTestFile(t, filepath.Join(dir, "rig.toml"), body, 0o644)
+		_, _, err := LoadConfig(dir)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
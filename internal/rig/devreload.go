@@ -0,0 +1,101 @@
+package rig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// devReloadPath is where a running `rig dev` records the address of its
+// reload endpoint: .rig/dev.addr.
+func devReloadPath(configPath string) string {
+	return filepath.Join(CacheDir(configPath), "dev.addr")
+}
+
+// DevReloadHandler accepts POST /reload and calls reload, which should
+// restart the dev process the way Ctrl+R does. Requests carrying an Origin
+// header are refused so web pages cannot trigger restarts.
+func DevReloadHandler(reload func()) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/reload" {
+			http.NotFound(w, req)
+			return
+		}
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if req.Header.Get("Origin") != "" {
+			http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+			return
+		}
+		reload()
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = fmt.Fprintln(w, "reload requested")
+	})
+}
+
+// ServeDevReload serves DevReloadHandler on a free loopback port and records
+// the address in .rig/dev.addr for RequestDevReload. stop shuts the endpoint
+// down and removes the file.
+func ServeDevReload(configPath string, reload func()) (stop func(), err error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	path := devReloadPath(configPath)
+	addr := ln.Addr().String()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(addr+"\n"), 0o644); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := serveHTTP(ctx, ln, DevReloadHandler(reload))
+	return func() {
+		cancel()
+		<-done
+		// Another `rig dev` may have taken over the file since.
+		if b, err := os.ReadFile(path); err == nil && strings.TrimSpace(string(b)) == addr {
+			_ = os.Remove(path)
+		}
+	}, nil
+}
+
+// RequestDevReload asks the `rig dev` running for configPath to restart its
+// process, through the address in .rig/dev.addr.
+func RequestDevReload(configPath string) error {
+	path := devReloadPath(configPath)
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("rig dev is not running for %s", configPath)
+	}
+	if err != nil {
+		return err
+	}
+	addr := strings.TrimSpace(string(b))
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post("http://"+addr+"/reload", "text/plain", nil)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return fmt.Errorf("rig dev is not running for %s (stale %s)", configPath, path)
+		}
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("reload request to %s failed: %s", addr, resp.Status)
+	}
+	return nil
+}
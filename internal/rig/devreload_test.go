@@ -0,0 +1,60 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDevReloadHandler(t *testing.T) {
+	reloads := 0
+	h := DevReloadHandler(func() { reloads++ })
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/reload", nil))
+	if rec.Code != http.StatusAccepted || reloads != 1 {
+		t.Fatalf("POST /reload code=%d reloads=%d", rec.Code, reloads)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reload", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET /reload code=%d, want 405", rec.Code)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/reload", nil)
+	req.Header.Set("Origin", "https://example.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || reloads != 1 {
+		t.Fatalf("cross-origin POST code=%d reloads=%d", rec.Code, reloads)
+	}
+}
+
+func TestRequestDevReload(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "rig.toml")
+	writeTestFile(t, configPath, "[project]\nname = \"demo\"\n", 0o644)
+
+	if err := RequestDevReload(configPath); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Fatalf("expected not-running error, got %v", err)
+	}
+	reloads := make(chan struct{}, 1)
+	stop, err := ServeDevReload(configPath, func() { reloads <- struct{}{} })
+	if err != nil {
+		t.Fatalf("ServeDevReload: %v", err)
+	}
+	if err := RequestDevReload(configPath); err != nil {
+		t.Fatalf("RequestDevReload: %v", err)
+	}
+	select {
+	case <-reloads:
+	default:
+		t.Fatalf("reload was not requested")
+	}
+	stop()
+	if _, err := os.Stat(filepath.Join(dir, ".rig", "dev.addr")); !os.IsNotExist(err) {
+		t.Fatalf("dev.addr should be removed by stop, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package rig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dev process states reported by DevStatus.
+const (
+	DevStateStarting   = "starting"
+	DevStateRunning    = "running"
+	DevStateRestarting = "restarting"
+	DevStateExited     = "exited"
+	DevStateCrashed    = "crashed"
+	DevStateStopped    = "stopped"
+)
+
+// devStatusLogLines is how many output lines a status snapshot includes per
+// process.
+const devStatusLogLines = 50
+
+// DevStatus tracks the processes of `rig dev` for its status endpoint. A nil
+// *DevStatus ignores every update, so callers need not check whether the
+// endpoint is enabled.
+type DevStatus struct {
+	mu        sync.Mutex
+	started   time.Time
+	processes []*devProcess
+}
+
+type devProcess struct {
+	info DevProcessStatus
+	logs TailBuffer
+}
+
+// DevStatusReport is the JSON body of GET /status.
+type DevStatusReport struct {
+	StartedAt time.Time          `json:"started_at"`
+	Processes []DevProcessStatus `json:"processes"`
+}
+
+// DevProcessStatus is one supervised process. Logs holds the last lines of
+// its combined stdout and stderr.
+type DevProcessStatus struct {
+	Name       string    `json:"name"`
+	Command    string    `json:"command"`
+	State      string    `json:"state"`
+	PID        int       `json:"pid,omitempty"`
+	Restarts   int       `json:"restarts"`
+	StartedAt  time.Time `json:"started_at,omitzero"`
+	LastReason string    `json:"last_restart_reason,omitempty"`
+	LastExit   string    `json:"last_exit,omitempty"`
+	// LastTest is the most recent test-on-save result, e.g. "ok ./api".
+	LastTest string   `json:"last_test,omitempty"`
+	Logs     []string `json:"logs"`
+}
+
+// NewDevStatus returns an empty tracker; Add registers processes.
+func NewDevStatus() *DevStatus {
+	return &DevStatus{started: time.Now().UTC()}
+}
+
+// Add registers a supervised process in the starting state.
+func (s *DevStatus) Add(name, command string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processes = append(s.processes, &devProcess{
+		info: DevProcessStatus{Name: name, Command: command, State: DevStateStarting},
+		logs: TailBuffer{Max: 16 << 10},
+	})
+}
+
+func (s *DevStatus) update(name string, fn func(p *DevProcessStatus)) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.processes {
+		if p.info.Name == name {
+			fn(&p.info)
+		}
+	}
+}
+
+// Started records that name is running as pid.
+func (s *DevStatus) Started(name string, pid int) {
+	s.update(name, func(p *DevProcessStatus) {
+		p.State, p.PID, p.StartedAt = DevStateRunning, pid, time.Now().UTC()
+	})
+}
+
+// Restarting records a restart of name and why ("change", "reload", ...).
+func (s *DevStatus) Restarting(name, reason string) {
+	s.update(name, func(p *DevProcessStatus) {
+		p.State, p.PID, p.LastReason = DevStateRestarting, 0, reason
+		p.Restarts++
+	})
+}
+
+// Exited records that name exited on its own with err (nil for success).
+func (s *DevStatus) Exited(name string, err error) {
+	s.update(name, func(p *DevProcessStatus) {
+		p.State, p.PID, p.LastExit = DevStateExited, 0, "exit status 0"
+		if err != nil {
+			p.LastExit = err.Error()
+		}
+	})
+}
+
+// SetState sets the state of name, e.g. DevStateCrashed or DevStateStopped.
+func (s *DevStatus) SetState(name, state string) {
+	s.update(name, func(p *DevProcessStatus) {
+		p.State = state
+		if state != DevStateRunning {
+			p.PID = 0
+		}
+	})
+}
+
+// TestResult records a test-on-save result for name.
+func (s *DevStatus) TestResult(name, result string) {
+	s.update(name, func(p *DevProcessStatus) { p.LastTest = result })
+}
+
+// Logs returns the buffer keeping the tail of name's output for the report,
+// or nil when s is nil or name is unknown.
+func (s *DevStatus) Logs(name string) *TailBuffer {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.processes {
+		if p.info.Name == name {
+			return &p.logs
+		}
+	}
+	return nil
+}
+
+// Report snapshots every process.
+func (s *DevStatus) Report() DevStatusReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rep := DevStatusReport{StartedAt: s.started, Processes: []DevProcessStatus{}}
+	for _, p := range s.processes {
+		info := p.info
+		info.Logs = []string{}
+		if tail := strings.TrimRight(tailLines(p.logs.String(), devStatusLogLines), "\n"); tail != "" {
+			info.Logs = strings.Split(tail, "\n")
+		}
+		rep.Processes = append(rep.Processes, info)
+	}
+	return rep
+}
+
+// ServeHTTP serves the report as JSON at GET /status.
+func (s *DevStatus) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/status" {
+		http.NotFound(w, req)
+		return
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(s.Report())
+}
+
+// ServeDevStatus listens on addr (e.g. "127.0.0.1:7777"; port 0 picks one)
+// and serves s until ctx is done. A non-nil reload is also served as
+// POST /reload (see DevReloadHandler). It returns the address it listens on.
+func ServeDevStatus(ctx context.Context, addr string, s *DevStatus, reload func()) (net.Addr, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	var h http.Handler = s
+	if reload != nil {
+		mux := http.NewServeMux()
+		mux.Handle("/reload", DevReloadHandler(reload))
+		mux.Handle("/", s)
+		h = mux
+	}
+	serveHTTP(ctx, ln, h)
+	return ln.Addr(), nil
+}
+
+// serveHTTP serves h on ln until ctx is done. The returned channel is closed
+// once the server has shut down.
+func serveHTTP(ctx context.Context, ln net.Listener, h http.Handler) <-chan struct{} {
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 5 * time.Second}
+	done := make(chan struct{})
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			_ = ln.Close()
+		}
+	}()
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	return done
+}
@@ -0,0 +1,55 @@
+package rig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDevStatusReport(t *testing.T) {
+	var none *DevStatus
+	none.Started("dev", 1) // a nil tracker ignores updates
+	if none.Logs("dev") != nil {
+		t.Fatalf("nil tracker must have no log buffer")
+	}
+
+	s := NewDevStatus()
+	s.Add("dev", "go run .")
+	for i := range 60 {
+		fmt.Fprintf(s.Logs("dev"), "line %d\n", i)
+	}
+	s.Started("dev", 42)
+	s.Restarting("dev", "change")
+	s.Exited("dev", errors.New("exit status 2"))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("code=%d content-type=%q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var rep DevStatusReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil {
+		t.Fatal(err)
+	}
+	p := rep.Processes[0]
+	if p.State != DevStateExited || p.PID != 0 || p.Restarts != 1 || p.LastReason != "change" || p.LastExit != "exit status 2" {
+		t.Fatalf("unexpected process status: %+v", p)
+	}
+	if len(p.Logs) != devStatusLogLines || p.Logs[0] != "line 10" || p.Logs[len(p.Logs)-1] != "line 59" {
+		t.Fatalf("logs should be the last %d lines, got %d: %q...", devStatusLogLines, len(p.Logs), p.Logs[0])
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("GET / code=%d, want 404", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/status", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST /status code=%d, want 405", rec.Code)
+	}
+}
@@ -0,0 +1,112 @@
+package rig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// expandTaskDirs resolves dir patterns against base (the working directory
+// when empty) into absolute directories, in pattern order with glob matches
+// sorted and duplicates dropped. A pattern that matches no directory fails.
+func expandTaskDirs(base string, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	if base == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		base = wd
+	}
+	var out []string
+	seen := map[string]bool{}
+	for _, pat := range patterns {
+		p := NormalizeTaskPath(strings.TrimSpace(pat))
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(base, p)
+		}
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("dirs pattern %q: %w", pat, err)
+		}
+		n := 0
+		for _, m := range matches {
+			if st, err := os.Stat(m); err != nil || !st.IsDir() {
+				continue
+			}
+			n++
+			if abs, err := filepath.Abs(m); err == nil && !seen[abs] {
+				seen[abs] = true
+				out = append(out, abs)
+			}
+		}
+		if n == 0 {
+			return nil, fmt.Errorf("dirs pattern %q matched no directories", pat)
+		}
+	}
+	return out, nil
+}
+
+// runTaskInDirs runs argv once in each of dirs, one after another, prefixing
+// output with the directory and printing a ✓/✗ line for each. Every
+// directory runs even after a failure; the error lists the ones that failed.
+// A canceled ctx stops the loop.
+func runTaskInDirs(ctx context.Context, confPath string, lock Lockfile, name string, argv, dirs []string, eo ExecOptions) error {
+	stdout, stderr := eo.Stdout, eo.Stderr
+	if stdout == nil {
+		stdout, stderr = os.Stdout, os.Stderr
+	}
+	base := filepath.Dir(confPath)
+	var failed []string
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		label := dirLabel(base, dir)
+		o := eo
+		o.Dir = dir
+		o.Stdout = newPrefixWriter(stdout, "["+label+"] ")
+		o.Stderr = newPrefixWriter(stderr, "["+label+"] ")
+		start := time.Now()
+		exe, err := resolveTaskExecutable(confPath, lock, argv[0], dir, eo.Env)
+		if err == nil {
+			err = ExecuteContext(ctx, exe, argv[1:], o)
+		}
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			statusf(stderr, "✗ %s in %s (%s): %v", name, label, elapsed, err)
+			failed = append(failed, label)
+			continue
+		}
+		statusf(stdout, "✓ %s in %s (%s)", name, label, elapsed)
+	}
+	if len(failed) > 0 {
+		return &dirsError{failed: failed, total: len(dirs)}
+	}
+	return nil
+}
+
+// dirsError reports the directories (labels relative to rig.toml) a task
+// failed in.
+type dirsError struct {
+	failed []string
+	total  int
+}
+
+func (e *dirsError) Error() string {
+	return fmt.Sprintf("%d of %d directories failed: %s", len(e.failed), e.total, strings.Join(e.failed, ", "))
+}
+
+// dirLabel shows dir relative to the project root when it is inside it.
+func dirLabel(base, dir string) string {
+	rel, err := filepath.Rel(base, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return dir
+	}
+	return filepath.ToSlash(rel)
+}
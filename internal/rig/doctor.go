@@ -21,10 +21,16 @@ type DoctorReport struct {
 	BinDir       string
 	BinDirExists bool
 	BinWritable  bool
+	CacheDir     string
 
 	ExecutablePath     string
 	ExecutableWritable bool
 
+	Requires  []RequirementStatus
+	StaleBins []StaleBin
+	// GoEnv lists GOBIN, GOFLAGS, and GOWORK settings that tool installs override.
+	GoEnv []GoEnvConflict
+
 	Errors []string
 }
 
@@ -48,6 +54,8 @@ func Doctor(startDir string, currentVersion string, executablePath string) (Doct
 	rep.BinDir = localBinDirForConfig(confPath)
 	rep.BinDirExists = dirExists(rep.BinDir)
 	rep.BinWritable = isDirWritable(rep.BinDir)
+	rep.CacheDir = CacheDir(confPath)
+	rep.GoEnv = GoInstallEnvConflicts(rep.BinDir, os.Getenv)
 
 	lockPath := rigLockPathForConfig(confPath)
 	rep.LockPath = lockPath
@@ -68,6 +76,21 @@ func Doctor(startDir string, currentVersion string, executablePath string) (Doct
 		} else {
 			rep.GoMatchesLock = true
 		}
+		if stale, err := StaleBins(confPath, lock); err == nil && len(stale) > 0 {
+			rep.StaleBins = stale
+			rep.Errors = append(rep.Errors, fmt.Sprintf("%d stale or orphaned binaries in .rig/bin; run 'rig sync --prune'", len(stale)))
+		}
+	}
+
+	rep.Requires = CheckRequirements(conf.Requires, nil)
+	for _, r := range rep.Requires {
+		if !r.OK() {
+			msg := fmt.Sprintf("requires %s (%s): %s", r.Name, r.Constraint, r.Error)
+			if r.Hint != "" {
+				msg += "; hint: " + r.Hint
+			}
+			rep.Errors = append(rep.Errors, msg)
+		}
 	}
 
 	rep.ExecutablePath = executablePath
@@ -0,0 +1,231 @@
+package rig
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DownloadOptions says how DownloadVerified checks and unpacks a download.
+// It is shared by `rig upgrade`, the rig version launcher, and OCI tools, so
+// there is one extraction path for release archives and tool binaries.
+type DownloadOptions struct {
+	// SHA256 is the expected hex digest of the downloaded file. Required.
+	SHA256 string
+	// MinisignKey, when set, is a minisign public key file; the signature is
+	// fetched from <url>.minisig and checked with minisign.
+	MinisignKey string
+	// Archive is "tar.gz", "zip", or "" for a bare binary (see ArchiveFormat).
+	Archive string
+	// StripComponents drops that many leading path elements from archive
+	// entries, like tar --strip-components.
+	StripComponents int
+	// BinaryPath is the archive entry to extract after stripping, e.g.
+	// "bin/tool". A bare name also matches a file of that name at any depth
+	// when it is the only one. Empty requires an archive of exactly one file.
+	BinaryPath string
+}
+
+// downloadMaxBinary bounds a binary read out of an archive.
+const downloadMaxBinary = 512 << 20
+
+// ArchiveFormat infers DownloadOptions.Archive from a file name.
+func ArchiveFormat(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	}
+	return ""
+}
+
+// DownloadVerified fetches url, checks its sha256 (and minisign signature,
+// if configured) before looking inside, and returns the selected binary.
+func DownloadVerified(client HTTPClient, url string, opts DownloadOptions) ([]byte, error) {
+	if strings.TrimSpace(opts.SHA256) == "" {
+		return nil, fmt.Errorf("download %s: no sha256 to verify against", url)
+	}
+	data, err := httpGet(client, url, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySHA256(path.Base(url), data, opts.SHA256); err != nil {
+		return nil, err
+	}
+	if opts.MinisignKey != "" {
+		sig, err := httpGet(client, url+".minisig", "")
+		if err != nil {
+			return nil, fmt.Errorf("minisign signature: %w", err)
+		}
+		if err := verifyMinisign(data, sig, opts.MinisignKey); err != nil {
+			return nil, fmt.Errorf("%s: %w", path.Base(url), err)
+		}
+	}
+	return ExtractBinary(data, opts)
+}
+
+// verifySHA256 checks data against a hex digest.
+func verifySHA256(name string, data []byte, want string) error {
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), strings.TrimSpace(want)) {
+		return fmt.Errorf("checksum mismatch for %s", name)
+	}
+	return nil
+}
+
+// verifyMinisign checks a detached minisign signature for data with the
+// minisign binary, as `rig lock verify` does for rig.lock.
+func verifyMinisign(data, sig []byte, pubKey string) error {
+	dir, err := os.MkdirTemp("", "rig-download-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	file, sigFile := filepath.Join(dir, "download"), filepath.Join(dir, "download.minisig")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(sigFile, sig, 0o600); err != nil {
+		return err
+	}
+	if out, err := runSigner(SignerMinisign, []string{"-V", "-q", "-p", pubKey, "-m", file, "-x", sigFile}, false); err != nil {
+		if out != "" {
+			return fmt.Errorf("minisign verification failed: %s", out)
+		}
+		return fmt.Errorf("minisign verification failed: %w", err)
+	}
+	return nil
+}
+
+// ExtractBinary returns the binary opts selects from data: data itself
+// when opts.Archive is empty, else one file of the tar.gz or zip archive.
+func ExtractBinary(data []byte, opts DownloadOptions) ([]byte, error) {
+	if opts.Archive == "" {
+		return data, nil
+	}
+	var files, byBase []string
+	var exact, base []byte
+	visit := func(name string, read func() ([]byte, error)) (stop bool, err error) {
+		files = append(files, name)
+		switch {
+		case opts.BinaryPath == "" && len(files) == 1, name == opts.BinaryPath:
+			exact, err = read()
+			// An exact match wins; without BinaryPath keep counting files.
+			return opts.BinaryPath != "", err
+		case opts.BinaryPath != "" && !strings.Contains(opts.BinaryPath, "/") && path.Base(name) == opts.BinaryPath:
+			byBase = append(byBase, name)
+			if len(byBase) == 1 {
+				base, err = read()
+			}
+		}
+		return false, err
+	}
+	var err error
+	switch opts.Archive {
+	case "tar.gz":
+		err = walkTarGz(data, opts.StripComponents, visit)
+	case "zip":
+		err = walkZip(data, opts.StripComponents, visit)
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q (expected tar.gz or zip)", opts.Archive)
+	}
+	switch {
+	case err != nil:
+		return nil, err
+	case opts.BinaryPath == "" && len(files) != 1:
+		return nil, fmt.Errorf("archive must contain exactly one file, got %d; set a binary path", len(files))
+	case exact != nil:
+		return exact, nil
+	case len(byBase) > 1:
+		return nil, fmt.Errorf("archive has several %s: %s", opts.BinaryPath, strings.Join(byBase, ", "))
+	case len(byBase) == 1:
+		return base, nil
+	}
+	return nil, fmt.Errorf("archive has no %s (files: %s)", opts.BinaryPath, strings.Join(files, ", "))
+}
+
+// stripPath removes n leading elements from an archive path; ok is false
+// when nothing is left.
+func stripPath(name string, n int) (string, bool) {
+	name = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+	parts := strings.Split(name, "/")
+	if name == "" || n >= len(parts) {
+		return "", false
+	}
+	return strings.Join(parts[n:], "/"), true
+}
+
+// archiveVisit is called for each regular file in an archive, with its
+// stripped path; read returns its contents. Returning stop ends the walk.
+type archiveVisit func(name string, read func() ([]byte, error)) (stop bool, err error)
+
+func walkTarGz(data []byte, strip int, visit archiveVisit) error {
+	g, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+	tr := tar.NewReader(g)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, ok := stripPath(h.Name, strip)
+		if !ok {
+			continue
+		}
+		stop, err := visit(name, func() ([]byte, error) {
+			return io.ReadAll(io.LimitReader(tr, downloadMaxBinary))
+		})
+		if err != nil || stop {
+			return err
+		}
+	}
+}
+
+func walkZip(data []byte, strip int, visit archiveVisit) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		name, ok := stripPath(f.Name, strip)
+		if !ok {
+			continue
+		}
+		stop, err := visit(name, func() ([]byte, error) {
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(io.LimitReader(rc, downloadMaxBinary))
+		})
+		if err != nil || stop {
+			return err
+		}
+	}
+	return nil
+}
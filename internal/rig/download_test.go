@@ -0,0 +1,97 @@
+package rig
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func makeTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+func TestExtractBinary(t *testing.T) {
+	tgz := makeTarGz(t, map[string]string{
+		"tool-1.2.0/README.md": "readme",
+		"tool-1.2.0/bin/tool":  "tool-bin",
+		"tool-1.2.0/lib/x.so":  "lib",
+	})
+	var zbuf bytes.Buffer
+	zw := zip.NewWriter(&zbuf)
+	f, _ := zw.Create("dist/tool.exe")
+	_, _ = f.Write([]byte("zip-bin"))
+	_ = zw.Close()
+
+	cases := []struct {
+		name    string
+		data    []byte
+		opts    DownloadOptions
+		want    string
+		wantErr string
+	}{
+		{"strip and path", tgz, DownloadOptions{Archive: "tar.gz", StripComponents: 1, BinaryPath: "bin/tool"}, "tool-bin", ""},
+		{"bare name at any depth", tgz, DownloadOptions{Archive: "tar.gz", BinaryPath: "tool"}, "tool-bin", ""},
+		{"unstripped path misses", tgz, DownloadOptions{Archive: "tar.gz", BinaryPath: "bin/tool"}, "", "archive has no bin/tool"},
+		{"several files need a path", tgz, DownloadOptions{Archive: "tar.gz"}, "", "exactly one file, got 3"},
+		{"zip", zbuf.Bytes(), DownloadOptions{Archive: "zip", StripComponents: 1}, "zip-bin", ""},
+		{"bare binary", []byte("raw"), DownloadOptions{}, "raw", ""},
+		{"unknown format", tgz, DownloadOptions{Archive: "rar"}, "", "unsupported archive format"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ExtractBinary(tc.data, tc.opts)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || string(got) != tc.want {
+				t.Fatalf("got %q, %v; want %q", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestDownloadVerified(t *testing.T) {
+	asset := makeTarGz(t, map[string]string{"tool_linux/tool": "tool-bin"})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(asset)
+	}))
+	defer ts.Close()
+	sum := sha256.Sum256(asset)
+	opts := DownloadOptions{SHA256: hex.EncodeToString(sum[:]), Archive: ArchiveFormat("tool.tgz"), StripComponents: 1, BinaryPath: "tool"}
+
+	got, err := DownloadVerified(http.DefaultClient, ts.URL+"/tool.tgz", opts)
+	if err != nil || string(got) != "tool-bin" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+
+	opts.SHA256 = strings.Repeat("0", 64)
+	if _, err := DownloadVerified(http.DefaultClient, ts.URL+"/tool.tgz", opts); err == nil || !strings.Contains(err.Error(), "checksum mismatch for tool.tgz") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	opts.SHA256 = ""
+	if _, err := DownloadVerified(http.DefaultClient, ts.URL+"/tool.tgz", opts); err == nil || !strings.Contains(err.Error(), "no sha256") {
+		t.Fatalf("expected missing sha256 error, got %v", err)
+	}
+}
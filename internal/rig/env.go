@@ -8,9 +8,11 @@ import (
 	"strings"
 )
 
-func buildEnv(configPath string, taskEnv map[string]string) []string {
+// buildEnv layers taskEnv over environ (normally os.Environ(); see
+// runEnviron) with the project bin directory first on PATH.
+func buildEnv(configPath string, environ []string, taskEnv map[string]string) []string {
 	base := map[string]string{}
-	for _, kv := range os.Environ() {
+	for _, kv := range environ {
 		k, v, ok := strings.Cut(kv, "=")
 		if !ok {
 			continue
@@ -18,41 +20,8 @@ func buildEnv(configPath string, taskEnv map[string]string) []string {
 		base[k] = v
 	}
 
-	localBin := localBinDirForConfig(configPath)
-
-	basePath := base["PATH"]
-	parts := []string{}
-	if basePath != "" {
-		parts = strings.Split(basePath, string(os.PathListSeparator))
-	}
-
-	seen := map[string]struct{}{}
-	dedup := make([]string, 0, len(parts)+1)
-	keyLocal := localBin
-	if runtime.GOOS == "windows" {
-		keyLocal = strings.ToLower(filepath.Clean(localBin))
-	} else {
-		keyLocal = filepath.Clean(localBin)
-	}
-	seen[keyLocal] = struct{}{}
-	dedup = append(dedup, localBin)
-	for _, p := range parts {
-		if p == "" {
-			continue
-		}
-		key := p
-		if runtime.GOOS == "windows" {
-			key = strings.ToLower(filepath.Clean(p))
-		} else {
-			key = filepath.Clean(p)
-		}
-		if _, ok := seen[key]; ok {
-			continue
-		}
-		seen[key] = struct{}{}
-		dedup = append(dedup, p)
-	}
-	base["PATH"] = strings.Join(dedup, string(os.PathListSeparator))
+	dirs, _ := taskPathDirs(localBinDirForConfig(configPath), base["PATH"])
+	base["PATH"] = strings.Join(dirs, string(os.PathListSeparator))
 
 	for k, v := range taskEnv {
 		base[k] = v
@@ -70,3 +39,184 @@ func buildEnv(configPath string, taskEnv map[string]string) []string {
 	}
 	return env
 }
+
+// expandCommand expands environment references in a task command string
+// before it is split into arguments, the way a shell would: $VAR and ${VAR},
+// plus %VAR% on Windows, except inside single quotes or after a backslash.
+// Values are escaped for the split, so they never add arguments. $$ is a
+// literal $, an unset variable stays as written, and the ${args} placeholder
+// is left for spliceArgs.
+func expandCommand(command string, env []string, goos string) string {
+	return expandEnvRefs(command, envLookup(env, goos), goos == "windows", true)
+}
+
+// expandArgv expands environment references in each token of an argv that
+// is already split (default_args), as expandCommand does, without quoting.
+func expandArgv(argv, env []string, goos string) []string {
+	lookup := envLookup(env, goos)
+	out := make([]string, len(argv))
+	for i, a := range argv {
+		out[i] = expandEnvRefs(a, lookup, goos == "windows", false)
+	}
+	return out
+}
+
+// envLookup resolves variables from KEY=VALUE entries (the task's merged
+// environment), ignoring case on Windows.
+func envLookup(env []string, goos string) func(string) (string, bool) {
+	windows := goos == "windows"
+	vars := make(map[string]string, len(env))
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			if windows {
+				k = strings.ToUpper(k)
+			}
+			vars[k] = v
+		}
+	}
+	return func(name string) (string, bool) {
+		if windows {
+			name = strings.ToUpper(name)
+		}
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+// expandEnvRefs expands the references in s. With command set, s is an
+// unsplit command: single-quoted text and backslash escapes stay literal,
+// and each value is backslash-escaped so shlex keeps it one literal word.
+func expandEnvRefs(s string, lookup func(string) (string, bool), windows, command bool) string {
+	if !strings.ContainsAny(s, "$%") {
+		return s
+	}
+	var b strings.Builder
+	single, double := false, false
+	writeValue := func(v string) {
+		if !command {
+			b.WriteString(v)
+			return
+		}
+		if v == "" && !double {
+			b.WriteString("''")
+		}
+		for _, r := range v {
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		}
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		if command {
+			switch {
+			case single:
+				single = c != '\''
+				b.WriteByte(c)
+				i++
+				continue
+			case c == '\\' && i+1 < len(s):
+				b.WriteString(s[i : i+2])
+				i += 2
+				continue
+			case c == '\'' && !double:
+				single = true
+			case c == '"':
+				double = !double
+			}
+		}
+		switch {
+		case c == '$' && i+1 < len(s) && s[i+1] == '$':
+			b.WriteByte('$')
+			i += 2
+		case c == '$' && strings.HasPrefix(s[i:], argsPlaceholder):
+			b.WriteString(argsPlaceholder)
+			i += len(argsPlaceholder)
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 || !isEnvName(s[i+2:i+2+end]) {
+				b.WriteByte('$')
+				i++
+				continue
+			}
+			if v, ok := lookup(s[i+2 : i+2+end]); ok {
+				writeValue(v)
+			} else {
+				b.WriteString(s[i : i+end+3])
+			}
+			i += end + 3
+		case c == '$':
+			n := envNameLen(s[i+1:])
+			if n == 0 {
+				b.WriteByte('$')
+				i++
+				continue
+			}
+			if v, ok := lookup(s[i+1 : i+1+n]); ok {
+				writeValue(v)
+			} else {
+				b.WriteString(s[i : i+1+n])
+			}
+			i += n + 1
+		case c == '%' && windows:
+			end := strings.IndexByte(s[i+1:], '%')
+			if end > 0 && isEnvName(s[i+1:i+1+end]) {
+				if v, ok := lookup(s[i+1 : i+1+end]); ok {
+					writeValue(v)
+					i += end + 2
+					continue
+				}
+			}
+			b.WriteByte('%')
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// envNameLen returns the length of the variable name at the start of s.
+func envNameLen(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || i > 0 && c >= '0' && c <= '9' {
+			continue
+		}
+		return i
+	}
+	return len(s)
+}
+
+func isEnvName(s string) bool {
+	return s != "" && envNameLen(s) == len(s)
+}
+
+// taskPathDirs returns the PATH directories rig gives commands: localBin
+// first, then basePath without empty entries and repeats (compared cleaned,
+// and case-insensitively on Windows). dropped lists the repeats left out.
+func taskPathDirs(localBin, basePath string) (dirs, dropped []string) {
+	key := func(p string) string {
+		if runtime.GOOS == "windows" {
+			return strings.ToLower(filepath.Clean(p))
+		}
+		return filepath.Clean(p)
+	}
+	seen := map[string]struct{}{key(localBin): {}}
+	dirs = []string{localBin}
+	if basePath == "" {
+		return dirs, nil
+	}
+	for _, p := range strings.Split(basePath, string(os.PathListSeparator)) {
+		if p == "" {
+			continue
+		}
+		if _, ok := seen[key(p)]; ok {
+			dropped = append(dropped, p)
+			continue
+		}
+		seen[key(p)] = struct{}{}
+		dirs = append(dirs, p)
+	}
+	return dirs, dropped
+}
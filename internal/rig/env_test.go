@@ -0,0 +1,86 @@
+package rig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestExpandArgv(t *testing.T) {
+	env := []string{"HOME=/home/me", "NAME=api server", "Path=C:\\bin"}
+	got := expandArgv([]string{
+		"$HOME/bin",
+		"--name=${NAME}",
+		"${args}",
+		"cost:$$5",
+		"^Test$",
+		"${UNSET}x$UNSET",
+		"${not valid}",
+		"%Path%",
+	}, env, "linux")
+	want := []string{
+		"/home/me/bin",
+		"--name=api server",
+		"${args}",
+		"cost:$5",
+		"^Test$",
+		"${UNSET}x$UNSET",
+		"${not valid}",
+		"%Path%",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expandArgv=%q, want %q", got, want)
+	}
+
+	got = expandArgv([]string{"%PATH%\\tool", "%UNSET%", "100%", "$path"}, env, "windows")
+	want = []string{"C:\\bin\\tool", "%UNSET%", "100%", "C:\\bin"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("windows expandArgv=%q, want %q", got, want)
+	}
+}
+
+func TestExpandCommandHonorsQuoting(t *testing.T) {
+	env := []string{"HOME=/home/me", "NAME=api server", "EMPTY=", "QUOTE=it's"}
+	for _, c := range []struct {
+		command string
+		want    []string
+	}{
+		{`sh -c 'for f in a b; do echo $f; done'`, []string{"sh", "-c", "for f in a b; do echo $f; done"}},
+		{`awk '{print $NF}' $HOME/x`, []string{"awk", "{print $NF}", "/home/me/x"}},
+		{`echo "$NAME" $NAME`, []string{"echo", "api server", "api server"}},
+		{`echo $QUOTE "${QUOTE}"`, []string{"echo", "it's", "it's"}},
+		{`echo \$HOME $$HOME $UNSET`, []string{"echo", "$HOME", "$HOME", "$UNSET"}},
+		{`echo $EMPTY x"$EMPTY"`, []string{"echo", "", "x"}},
+		{`go test ${args}`, []string{"go", "test", "${args}"}},
+	} {
+		got, err := parseCommand(expandCommand(c.command, env, "linux"))
+		if err != nil {
+			t.Fatalf("%s: %v", c.command, err)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%s: got %q, want %q", c.command, got, c.want)
+		}
+	}
+}
+
+func TestRunLeavesSingleQuotedShellVariables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "rig.toml"), `
+[tasks.loop]
+command = "sh -c 'for f in a b; do echo $f >> out; done'"
+`, 0o644)
+	writeTestFile(t, filepath.Join(root, "rig.lock"), "schema = 0\n", 0o644)
+
+	if err := RunWith(context.Background(), root, "loop", nil, RunOptions{}); err != nil {
+		t.Fatalf("RunWith: %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(root, "out")); string(b) != "a\nb\n" {
+		t.Fatalf("single-quoted $f was expanded by rig: %q", b)
+	}
+}
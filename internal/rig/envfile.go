@@ -0,0 +1,94 @@
+package rig
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReadEnvFile reads a dotenv file (see ParseEnvFile).
+func ReadEnvFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	env, err := ParseEnvFile(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return env, nil
+}
+
+// ParseEnvFile parses KEY=VALUE lines. Blank lines and # comments are skipped,
+// a leading "export " is allowed, and values may be single-quoted (literal) or
+// double-quoted (with Go-style escapes). Unquoted values drop trailing " #" comments.
+func ParseEnvFile(b []byte) (map[string]string, error) {
+	out := map[string]string{}
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	n := 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		k, v, ok := strings.Cut(line, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" || strings.ContainsAny(k, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		v = strings.TrimSpace(v)
+		switch {
+		case len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'':
+			v = v[1 : len(v)-1]
+		case len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"':
+			uq, err := strconv.Unquote(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value for %s", n, k)
+			}
+			v = uq
+		default:
+			if i := strings.Index(v, " #"); i >= 0 {
+				v = strings.TrimSpace(v[:i])
+			}
+		}
+		out[k] = v
+	}
+	return out, sc.Err()
+}
+
+// WatchFile polls path every interval and sends on changed (non-blocking)
+// when its modification time or size changes, or it appears or disappears.
+func WatchFile(ctx context.Context, path string, interval time.Duration, changed chan<- struct{}) error {
+	stamp := func() (fileStamp, bool) {
+		st, err := os.Stat(path)
+		if err != nil {
+			return fileStamp{}, false
+		}
+		return fileStamp{mod: st.ModTime(), size: st.Size()}, true
+	}
+	prev, prevOK := stamp()
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+		cur, ok := stamp()
+		if ok != prevOK || !cur.mod.Equal(prev.mod) || cur.size != prev.size {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+		prev, prevOK = cur, ok
+	}
+}
@@ -0,0 +1,57 @@
+package rig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	got, err := ParseEnvFile([]byte(`
+# comment
+PORT=8080
+export DB_URL="postgres://localhost/app?sslmode=disable"
+GREETING='hello # not a comment'
+TOKEN=abc # trailing comment
+MULTI="a\nb"
+`))
+	if err != nil {
+		t.Fatalf("ParseEnvFile: %v", err)
+	}
+	want := map[string]string{
+		"PORT":     "8080",
+		"DB_URL":   "postgres://localhost/app?sslmode=disable",
+		"GREETING": "hello # not a comment",
+		"TOKEN":    "abc",
+		"MULTI":    "a\nb",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("env=%v, want %v", got, want)
+	}
+	if _, err := ParseEnvFile([]byte("not an assignment\n")); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestWatchFileDetectsChange(t *testing.T) {
+	p := filepath.Join(t.TempDir(), ".env")
+	writeTestFile(t, p, "A=1\n", 0o644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	go func() { _ = WatchFile(ctx, p, 20*time.Millisecond, changed) }()
+
+	time.Sleep(60 * time.Millisecond)
+	if err := os.WriteFile(p, []byte("A=22\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected change to be detected")
+	}
+}
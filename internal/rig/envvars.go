@@ -0,0 +1,52 @@
+package rig
+
+// EnvVarDoc documents one environment variable rig reads or sets, for
+// `rig env --describe`. Add an entry here whenever rig starts reading or
+// setting a variable.
+type EnvVarDoc struct {
+	Name string `json:"name"`
+	// Reads is true when the variable changes what rig does.
+	Reads bool `json:"reads"`
+	// Sets names what rig sets the variable for, "" when it only reads it.
+	Sets    string `json:"sets,omitempty"`
+	Summary string `json:"summary"`
+}
+
+// EnvVarDocs lists every variable, rig's own first.
+var EnvVarDocs = []EnvVarDoc{
+	{Name: "RIG_USER_CONFIG", Reads: true, Summary: "User config file, instead of <UserConfigDir>/rig/config.toml."},
+	{Name: "RIG_CACHE_DIR", Reads: true, Summary: "User cache directory for remote includes, cached rig versions, and update checks, instead of <UserCacheDir>/rig."},
+	{Name: EnvPathsBin, Reads: true, Summary: "Directory for managed tools; overrides [paths].bin (default .rig/bin)."},
+	{Name: EnvPathsCache, Reads: true, Summary: "Directory for per-project state; overrides [paths].cache (default .rig)."},
+	{Name: "RIG_TEMPLATES_DIR", Reads: true, Summary: "Template directory for `rig new` when --templates is not given."},
+	{Name: "RIG_HEARTBEAT", Reads: true, Summary: "Default for `rig run --heartbeat`, e.g. 1m."},
+	{Name: "RIG_LOCK_SIGNING_KEY", Reads: true, Summary: "Default private key for `rig lock sign`."},
+	{Name: "RIG_LOCK_SIGNATURE", Reads: true, Summary: "Signer (minisign or cosign) whose rig.lock signature every project must carry, even without [lock]; overrides lock.signature in the user config."},
+	{Name: "RIG_LOCK_PUBLIC_KEY", Reads: true, Summary: "Trusted public key for rig.lock signatures, used instead of [lock].public_key; overrides lock.public_key in the user config."},
+	{Name: "RIG_SKIP_VERSION_CHECK", Reads: true, Summary: "When set, commands skip the rig-version check."},
+	{Name: "RIG_AUTO_SWITCH", Reads: true, Summary: "1 or true makes rig download and run the rig-version release instead of failing the version check."},
+	{Name: "RIG_LAUNCHED", Reads: true, Sets: "releases started by RIG_AUTO_SWITCH", Summary: "Marks a rig started by the launcher, which skips the launcher, update notices, and unsafe-context warnings."},
+	{Name: "RIG_PROFILE", Sets: "tasks run with --profile and dev commands with a profile", Summary: "Name of the applied [profile.<name>]."},
+	{Name: "RIG_PLUGIN_COOKIE", Sets: "plugins", Summary: "Tells a rig-<name> plugin it was started by rig (value " + PluginCookie + ")."},
+	{Name: "RIG_PLUGIN_PROTOCOL", Sets: "plugins", Summary: "Plugin protocol version rig speaks."},
+	{Name: "PATH", Reads: true, Sets: "tasks, tools, and dev commands", Summary: "The tool bin directory is put first so managed tools win."},
+	{Name: "GOBIN", Reads: true, Sets: "tool installs", Summary: "Points go install at the tool bin directory; a different GOBIN is reported by rig doctor."},
+	{Name: "GOWORK", Reads: true, Sets: "tool installs", Summary: "Set to off so a go.work cannot change how tools resolve."},
+	{Name: "GOFLAGS", Reads: true, Sets: "tool installs and --profile runs", Summary: "-mod and -modfile are dropped for tool installs; profiles append their tags and flags."},
+	{Name: "GOCACHE", Sets: "go commands when [cache].go is set", Summary: "Go build cache directory from [cache].go."},
+	{Name: "GOPROXY", Sets: "offline syncs and [proxy] in the user config", Summary: "off for --offline; otherwise the user config [proxy] value unless already set."},
+	{Name: "GOSUMDB", Sets: "offline syncs and [proxy] in the user config", Summary: "off for --offline; otherwise the user config [proxy] value unless already set."},
+	{Name: "GOPRIVATE", Sets: "[proxy] in the user config", Summary: "From the user config [proxy] unless already set."},
+	{Name: "GONOSUMDB", Sets: "[proxy] in the user config", Summary: "From the user config [proxy] unless already set."},
+	{Name: "HTTP_PROXY", Sets: "[proxy] in the user config", Summary: "From the user config [proxy] unless already set."},
+	{Name: "HTTPS_PROXY", Sets: "[proxy] in the user config", Summary: "From the user config [proxy] unless already set."},
+	{Name: "NO_PROXY", Sets: "[proxy] in the user config", Summary: "From the user config [proxy] unless already set."},
+	{Name: "PATHEXT", Reads: true, Summary: "On Windows, the extensions tried when looking up a tool or task command."},
+	{Name: "DOCKER_CONFIG", Reads: true, Summary: "Directory holding config.json with registry credentials for OCI tools."},
+	{Name: "SOURCE_DATE_EPOCH", Reads: true, Summary: "Unix time recorded as [meta].generated in rig.lock, for reproducible locks."},
+	{Name: "CI", Reads: true, Summary: "When set, disables color in auto mode and update notices, and applies [ci].env_allowlist to task environments."},
+	{Name: "NO_COLOR", Reads: true, Summary: "Disables color unless --color always."},
+	{Name: "FORCE_COLOR", Reads: true, Summary: "Enables color even without a terminal, unless 0 or false."},
+	{Name: "CLICOLOR_FORCE", Reads: true, Summary: "Enables color even without a terminal, unless 0."},
+	{Name: "TERM", Reads: true, Summary: "dumb disables color in auto mode."},
+}
@@ -0,0 +1,41 @@
+package rig
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestEnvVarDocsCoverSource fails when non-test code reads a variable by
+// name that EnvVarDocs does not document.
+func TestEnvVarDocsCoverSource(t *testing.T) {
+	read := regexp.MustCompile(`os\.(?:Getenv|LookupEnv)\("([A-Z][A-Z0-9_]*)"\)`)
+	var missing []string
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range read.FindAllStringSubmatch(string(b), -1) {
+			name := m[1]
+			if slices.ContainsFunc(EnvVarDocs, func(d EnvVarDoc) bool { return d.Name == name }) || slices.Contains(missing, name) {
+				continue
+			}
+			missing = append(missing, name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) > 0 {
+		t.Fatalf("EnvVarDocs is missing %s", strings.Join(missing, ", "))
+	}
+}
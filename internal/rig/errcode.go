@@ -0,0 +1,217 @@
+package rig
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorCode is a stable identifier for a class of user-facing failure.
+// `rig explain <code>` prints its cause and resolution. Codes are never
+// reused or renumbered; retired codes stay documented.
+type ErrorCode string
+
+const (
+	CodeLockMissing      ErrorCode = "RIG001"
+	CodeConfigNotFound   ErrorCode = "RIG002"
+	CodeConfigInvalid    ErrorCode = "RIG003"
+	CodeLockOutOfDate    ErrorCode = "RIG004"
+	CodeToolsOutOfSync   ErrorCode = "RIG005"
+	CodeTaskNotFound     ErrorCode = "RIG006"
+	CodeTaskCycle        ErrorCode = "RIG007"
+	CodeTaskFailed       ErrorCode = "RIG008"
+	CodeGoToolchain      ErrorCode = "RIG009"
+	CodeRigVersion       ErrorCode = "RIG010"
+	CodeLockSignature    ErrorCode = "RIG011"
+	CodeProfileNotFound  ErrorCode = "RIG012"
+	CodeToolNotManaged   ErrorCode = "RIG013"
+	CodeToolSHAMismatch  ErrorCode = "RIG014"
+	CodeToolNotInstalled ErrorCode = "RIG015"
+	CodeModuleChecksum   ErrorCode = "RIG016"
+	CodeUpgradeVerify    ErrorCode = "RIG017"
+	CodeIncludeMismatch  ErrorCode = "RIG018"
+)
+
+// CodedError attaches an ErrorCode to err without changing its message.
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string { return e.Err.Error() }
+
+func (e *CodedError) Unwrap() error { return e.Err }
+
+// ErrorCode implements the interface CodeOf looks for.
+func (e *CodedError) ErrorCode() ErrorCode { return e.Code }
+
+// WithCode tags err with code. A nil err stays nil, and an error that already
+// carries a code keeps it: the innermost code is the most specific.
+func WithCode(code ErrorCode, err error) error {
+	if err == nil || CodeOf(err) != "" {
+		return err
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+// CodeOf returns the code carried anywhere in err's chain, or "".
+func CodeOf(err error) ErrorCode {
+	var c interface{ ErrorCode() ErrorCode }
+	if errors.As(err, &c) {
+		return c.ErrorCode()
+	}
+	return ""
+}
+
+// ErrorDoc documents one ErrorCode for `rig explain`.
+type ErrorDoc struct {
+	Code       ErrorCode `json:"code"`
+	Title      string    `json:"title"`
+	Cause      string    `json:"cause"`
+	Resolution []string  `json:"resolution"`
+	Related    []string  `json:"related,omitempty"`
+}
+
+// ErrorDocs lists every code in order.
+var ErrorDocs = []ErrorDoc{
+	{
+		Code:       CodeLockMissing,
+		Title:      "rig.lock missing or unreadable",
+		Cause:      "The command needs rig.lock next to rig.toml to know which tool versions and checksums to trust, and it does not exist or cannot be parsed.",
+		Resolution: []string{"Run 'rig sync' to resolve [tools] and write rig.lock.", "If rig.lock is committed, make sure it is checked out and not hand-edited into invalid TOML."},
+		Related:    []string{"rig sync", "rig check"},
+	},
+	{
+		Code:       CodeConfigNotFound,
+		Title:      "rig.toml not found",
+		Cause:      "No rig.toml exists in the current directory or any parent.",
+		Resolution: []string{"Run 'rig init' (or 'rig init --from .' for an existing project) to create one.", "Change into the project directory first."},
+		Related:    []string{"rig init"},
+	},
+	{
+		Code:       CodeConfigInvalid,
+		Title:      "rig.toml is invalid",
+		Cause:      "rig.toml or one of its includes is not valid TOML or uses unsupported fields or values.",
+		Resolution: []string{"Fix the field at the file, line, and column named in the error message.", "See docs/CONFIGURATION.md for the allowed fields of each section."},
+		Related:    []string{"rig config"},
+	},
+	{
+		Code:       CodeLockOutOfDate,
+		Title:      "rig.lock does not match rig.toml",
+		Cause:      "A tool was added, removed, or re-versioned in [tools] (or tools.go changed) since rig.lock was written.",
+		Resolution: []string{"Run 'rig sync' to update rig.lock and reinstall tools.", "Commit the updated rig.lock together with rig.toml."},
+		Related:    []string{"rig sync", "rig sync --dry-run", "rig check"},
+	},
+	{
+		Code:       CodeToolsOutOfSync,
+		Title:      "tools in .rig/bin are missing or out of date",
+		Cause:      "One or more binaries pinned by rig.lock are not installed in .rig/bin or were built at a different version.",
+		Resolution: []string{"Run 'rig sync' to install exactly what rig.lock pins.", "Use 'rig sync --prune' to also remove binaries rig.lock no longer lists."},
+		Related:    []string{"rig sync", "rig tools outdated", "rig check"},
+	},
+	{
+		Code:       CodeTaskNotFound,
+		Title:      "task not found",
+		Cause:      "The requested task, or a task named in depends_on or steps, is not defined in [tasks].",
+		Resolution: []string{"Check the spelling; rig suggests the nearest task names.", "List tasks with 'rig run --list'."},
+		Related:    []string{"rig run --list"},
+	},
+	{
+		Code:       CodeTaskCycle,
+		Title:      "task dependency cycle",
+		Cause:      "Tasks depend on each other in a loop through depends_on or steps, so no run order exists.",
+		Resolution: []string{"Remove one of the edges in the cycle printed in the error message."},
+		Related:    []string{"rig run --list"},
+	},
+	{
+		Code:       CodeTaskFailed,
+		Title:      "task failed",
+		Cause:      "A task command (or one of its dependencies) exited non-zero or could not be started.",
+		Resolution: []string{"Read the task output above the error.", "Use --continue-on-error to see every failing task, or allow_failure = true for tasks that may fail."},
+		Related:    []string{"rig run --output full", "rig run --continue-on-error"},
+	},
+	{
+		Code:       CodeGoToolchain,
+		Title:      "Go toolchain does not match rig.lock",
+		Cause:      "The go on PATH is not the version pinned by tools.go in rig.lock.",
+		Resolution: []string{"Run 'rig sync --go' to either pin tools.go to the installed go or install the requested version.", "Install the pinned Go version, or let GOTOOLCHAIN download it.", "Change tools.go in rig.toml and run 'rig sync' if the pin is out of date."},
+		Related:    []string{"rig doctor", "rig sync --go"},
+	},
+	{
+		Code:       CodeRigVersion,
+		Title:      "rig version not allowed by the project",
+		Cause:      "[project].rig-version in rig.toml does not allow the running rig binary.",
+		Resolution: []string{"Run 'rig upgrade' or install a rig release that satisfies the constraint.", "Set RIG_SKIP_VERSION_CHECK=1 to bypass the check temporarily."},
+		Related:    []string{"rig upgrade", "rig version"},
+	},
+	{
+		Code:       CodeLockSignature,
+		Title:      "rig.lock signature missing or invalid",
+		Cause:      "[lock].signature requires rig.lock.sig, and it is missing or does not verify against [lock].public_key.",
+		Resolution: []string{"Run 'rig lock sign' after reviewing rig.lock changes.", "Check that [lock].public_key matches the signing key."},
+		Related:    []string{"rig lock sign", "rig lock verify"},
+	},
+	{
+		Code:       CodeProfileNotFound,
+		Title:      "profile not found",
+		Cause:      "--profile names a [profile.<name>] table that rig.toml does not define.",
+		Resolution: []string{"Check the profile name or add [profile.<name>] to rig.toml."},
+		Related:    []string{"rig build --profile", "rig test --profile", "rig run --profile"},
+	},
+	{
+		Code:       CodeToolNotManaged,
+		Title:      "tool is not managed by rig",
+		Cause:      "The tool is not declared in [tools] or not recorded in rig.lock, so rig will not run it.",
+		Resolution: []string{"Add it to [tools] in rig.toml and run 'rig sync'.", "Check the spelling; rig suggests the nearest tool names."},
+		Related:    []string{"rig tools ls", "rig tools search"},
+	},
+	{
+		Code:       CodeToolSHAMismatch,
+		Title:      "tool binary sha256 does not match rig.lock",
+		Cause:      "A binary in .rig/bin differs from the one rig.lock recorded, e.g. it was rebuilt, replaced, or installed for another branch's rig.lock.",
+		Resolution: []string{"Run 'rig sync' to reinstall the locked binary.", "If you did not replace it yourself, inspect .rig/bin before running it."},
+		Related:    []string{"rig sync", "rig tools doctor", "rig check"},
+	},
+	{
+		Code:       CodeToolNotInstalled,
+		Title:      "tool not installed in .rig/bin",
+		Cause:      "rig.lock pins the tool, but its binary is missing from .rig/bin or is not executable.",
+		Resolution: []string{"Run 'rig sync'."},
+		Related:    []string{"rig sync", "rig tools doctor"},
+	},
+	{
+		Code:       CodeModuleChecksum,
+		Title:      "tool module checksum could not be verified",
+		Cause:      "A tool module's h1: checksum is not in the checksum database or differs from the one in rig.lock.",
+		Resolution: []string{"Check GOSUMDB, GONOSUMDB, and GOPRIVATE.", "For private modules you trust, run 'rig sync --insecure' once to record their checksum."},
+		Related:    []string{"rig sync --insecure"},
+	},
+	{
+		Code:       CodeUpgradeVerify,
+		Title:      "upgraded rig binary failed verification",
+		Cause:      "After replacing the rig binary, the new one did not run or reported an unexpected version, so the previous binary was restored.",
+		Resolution: []string{"Retry 'rig upgrade'; if it fails again, download the release asset manually.", "Check that the release asset matches your OS, architecture, and libc."},
+		Related:    []string{"rig upgrade", "rig version"},
+	},
+	{
+		Code:       CodeIncludeMismatch,
+		Title:      "remote include changed since rig.lock was written",
+		Cause:      "A remote include's content hash differs from the one pinned in rig.lock.",
+		Resolution: []string{"Review the upstream change, then run 'rig sync' to re-pin it."},
+		Related:    []string{"rig sync"},
+	},
+}
+
+// ExplainError returns the documentation for code. Lookup is
+// case-insensitive and accepts the bare number ("14" or "014").
+func ExplainError(code string) (ErrorDoc, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code != "" && code[0] >= '0' && code[0] <= '9' {
+		code = "RIG" + strings.Repeat("0", max(0, 3-len(code))) + code
+	}
+	for _, d := range ErrorDocs {
+		if string(d.Code) == code {
+			return d, true
+		}
+	}
+	return ErrorDoc{}, false
+}
@@ -0,0 +1,45 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWithCodeKeepsInnermostCode(t *testing.T) {
+	if WithCode(CodeTaskFailed, nil) != nil {
+		t.Fatal("WithCode(nil) should stay nil")
+	}
+	inner := WithCode(CodeToolSHAMismatch, errors.New("tool \"x\" checksum mismatch"))
+	outer := WithCode(CodeTaskFailed, fmt.Errorf("task \"lint\": %w", inner))
+	if got := CodeOf(outer); got != CodeToolSHAMismatch {
+		t.Fatalf("CodeOf = %q, want %q", got, CodeToolSHAMismatch)
+	}
+	if outer.Error() != "task \"lint\": tool \"x\" checksum mismatch" {
+		t.Fatalf("code must not change the message, got %q", outer.Error())
+	}
+	if got := CodeOf(&RigVersionMismatchError{}); got != CodeRigVersion {
+		t.Fatalf("CodeOf(RigVersionMismatchError) = %q", got)
+	}
+}
+
+func TestErrorDocsAreCompleteAndUnique(t *testing.T) {
+	seen := map[ErrorCode]bool{}
+	for i, d := range ErrorDocs {
+		if want := ErrorCode(fmt.Sprintf("RIG%03d", i+1)); d.Code != want {
+			t.Fatalf("ErrorDocs[%d] = %s, want %s (codes are listed in order without gaps)", i, d.Code, want)
+		}
+		if seen[d.Code] || d.Title == "" || d.Cause == "" || len(d.Resolution) == 0 {
+			t.Fatalf("incomplete or duplicate doc for %s: %+v", d.Code, d)
+		}
+		seen[d.Code] = true
+	}
+	for _, in := range []string{"RIG014", "rig014", "14", "014"} {
+		if d, ok := ExplainError(in); !ok || d.Code != CodeToolSHAMismatch {
+			t.Fatalf("ExplainError(%q) = %v, %v", in, d.Code, ok)
+		}
+	}
+	if _, ok := ExplainError("RIG999"); ok {
+		t.Fatal("unknown code should not resolve")
+	}
+}
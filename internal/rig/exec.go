@@ -31,7 +31,7 @@ func resolveCwd(configPath string, taskCwd string) (string, error) {
 	if strings.TrimSpace(taskCwd) == "" {
 		return filepath.Abs(baseDir)
 	}
-	cwd := taskCwd
+	cwd := NormalizeTaskPath(taskCwd)
 	if !filepath.IsAbs(cwd) {
 		cwd = filepath.Join(baseDir, cwd)
 	}
@@ -42,11 +42,9 @@ func resolveExecutable(cmd string, cwd string, env []string) (string, error) {
 	if cmd == "" {
 		return "", errors.New("empty executable")
 	}
-	if filepath.IsAbs(cmd) {
-		return cmd, nil
-	}
-	if strings.ContainsRune(cmd, os.PathSeparator) {
-		p := cmd
+	pathext := os.Getenv("PATHEXT")
+	if isPathLike(cmd) {
+		p := NormalizeTaskPath(cmd)
 		if !filepath.IsAbs(p) {
 			p = filepath.Join(cwd, p)
 		}
@@ -54,6 +52,12 @@ func resolveExecutable(cmd string, cwd string, env []string) (string, error) {
 		if err != nil {
 			return "", err
 		}
+		candidates := executableCandidates(abs, runtime.GOOS, pathext)
+		for _, c := range candidates[:len(candidates)-1] {
+			if ensureExecutable(c) == nil {
+				return c, nil
+			}
+		}
 		if err := ensureExecutable(abs); err != nil {
 			return "", err
 		}
@@ -72,10 +76,7 @@ func resolveExecutable(cmd string, cwd string, env []string) (string, error) {
 	}
 
 	dirs := strings.Split(pathVal, string(os.PathListSeparator))
-	candidates := []string{cmd}
-	if runtime.GOOS == "windows" && !strings.HasSuffix(strings.ToLower(cmd), ".exe") {
-		candidates = append([]string{cmd + ".exe"}, candidates...)
-	}
+	candidates := executableCandidates(cmd, runtime.GOOS, pathext)
 
 	for _, dir := range dirs {
 		if dir == "" {
@@ -96,6 +97,58 @@ func resolveExecutable(cmd string, cwd string, env []string) (string, error) {
 	return "", fmt.Errorf("executable %q not found on PATH", cmd)
 }
 
+// NormalizeTaskPath converts either separator in a path from rig.toml (task
+// cwd, build output) to the platform's, so "cmd/api" and `cmd\api` both work
+// on every OS.
+func NormalizeTaskPath(p string) string {
+	return filepath.FromSlash(strings.ReplaceAll(p, `\`, "/"))
+}
+
+// isPathLike reports whether a command names a file rather than something
+// to look up on PATH.
+func isPathLike(cmd string) bool {
+	return filepath.IsAbs(cmd) || strings.ContainsAny(cmd, `/\`)
+}
+
+// defaultPathExt is used when PATHEXT is unset on Windows.
+const defaultPathExt = ".COM;.EXE;.BAT;.CMD"
+
+// executableCandidates lists the file names tried for cmd, in order. On
+// Windows a name without a PATHEXT extension is tried with each extension
+// (so .cmd and .bat shims resolve) before the bare name.
+func executableCandidates(cmd, goos, pathext string) []string {
+	if goos != "windows" {
+		return []string{cmd}
+	}
+	exts := windowsExts(pathext)
+	for _, ext := range exts {
+		if strings.EqualFold(filepath.Ext(cmd), ext) {
+			return []string{cmd}
+		}
+	}
+	out := make([]string, 0, len(exts)+1)
+	for _, ext := range exts {
+		out = append(out, cmd+strings.ToLower(ext))
+	}
+	return append(out, cmd)
+}
+
+func windowsExts(pathext string) []string {
+	if strings.TrimSpace(pathext) == "" {
+		pathext = defaultPathExt
+	}
+	var exts []string
+	for _, e := range strings.Split(pathext, ";") {
+		if e = strings.TrimSpace(e); e != "" {
+			if !strings.HasPrefix(e, ".") {
+				e = "." + e
+			}
+			exts = append(exts, e)
+		}
+	}
+	return exts
+}
+
 func ensureExecutable(path string) error {
 	st, err := os.Stat(path)
 	if err != nil {
@@ -0,0 +1,237 @@
+package rig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+func TestExecutableCandidates(t *testing.T) {
+	if got := executableCandidates("golangci-lint", "linux", ""); !reflect.DeepEqual(got, []string{"golangci-lint"}) {
+		t.Fatalf("linux candidates=%v", got)
+	}
+	want := []string{"protoc-gen.com", "protoc-gen.exe", "protoc-gen.bat", "protoc-gen.cmd", "protoc-gen"}
+	if got := executableCandidates("protoc-gen", "windows", ""); !reflect.DeepEqual(got, want) {
+		t.Fatalf("windows candidates=%v, want %v", got, want)
+	}
+	if got := executableCandidates("gen", "windows", ".CMD;EXE"); !reflect.DeepEqual(got, []string{"gen.cmd", "gen.exe", "gen"}) {
+		t.Fatalf("PATHEXT candidates=%v", got)
+	}
+	if got := executableCandidates("gen.BAT", "windows", ""); !reflect.DeepEqual(got, []string{"gen.BAT"}) {
+		t.Fatalf("explicit extension candidates=%v", got)
+	}
+}
+
+func TestNormalizeTaskPath(t *testing.T) {
+	want := filepath.Join("cmd", "api", "bin")
+	for _, in := range []string{"cmd/api/bin", `cmd\api\bin`, `cmd/api\bin`} {
+		if got := NormalizeTaskPath(in); got != want {
+			t.Fatalf("NormalizeTaskPath(%q)=%q, want %q", in, got, want)
+		}
+	}
+
+	root := t.TempDir()
+	got, err := resolveCwd(filepath.Join(root, "rig.toml"), `services\api`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "services", "api"); got != want {
+		t.Fatalf("cwd=%q, want %q", got, want)
+	}
+}
+
+func TestSpliceArgsTemplates(t *testing.T) {
+	cases := []struct {
+		argv, extra, defaults, want []string
+	}{
+		{[]string{"go", "test", "{{args}}", "./..."}, []string{"-v", "-count=1"}, nil, []string{"go", "test", "-v", "-count=1", "./..."}},
+		{[]string{"go", "test", "{{ args }}", "./..."}, nil, []string{"-short"}, []string{"go", "test", "-short", "./..."}},
+		{[]string{"deploy", "--env={{arg0}}", "{{arg1}}"}, []string{"prod", "api"}, nil, []string{"deploy", "--env=prod", "api"}},
+		{[]string{"deploy", "{{arg0}}", "{{arg1}}"}, []string{"prod"}, nil, []string{"deploy", "prod"}},
+		{[]string{"echo", "-label={{args}}"}, []string{"a", "b"}, nil, []string{"echo", "-label=a b"}},
+		{[]string{"go", "build"}, []string{"-v"}, nil, []string{"go", "build", "-v"}},
+		{[]string{"go", "test", "${args}", "./..."}, []string{"-v"}, nil, []string{"go", "test", "-v", "./..."}},
+	}
+	for _, c := range cases {
+		if got := spliceArgs(c.argv, c.extra, c.defaults); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("spliceArgs(%q, %q, %q) = %q, want %q", c.argv, c.extra, c.defaults, got, c.want)
+		}
+	}
+}
+
+func TestPlatformShellFallsBackToPowerShell(t *testing.T) {
+	orig := shellLookPath
+	t.Cleanup(func() { shellLookPath = orig })
+	onPath := map[string]bool{}
+	shellLookPath = func(name string) (string, error) {
+		if onPath[name] {
+			return "/usr/bin/" + name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	if name, args, _ := platformShell("windows", "echo hi"); name != "cmd" || !reflect.DeepEqual(args, []string{"/c", "echo hi"}) {
+		t.Fatalf("windows shell=%s %v", name, args)
+	}
+
+	onPath["pwsh"] = true
+	name, args, err := platformShell("linux", "echo hi")
+	if err != nil || name != "pwsh" || args[len(args)-1] != "echo hi" {
+		t.Fatalf("fallback shell=%s %v err=%v", name, args, err)
+	}
+
+	onPath["sh"] = true
+	if name, _, _ := platformShell("linux", "echo hi"); name != "sh" {
+		t.Fatalf("shell=%s, want sh when available", name)
+	}
+
+	onPath = map[string]bool{}
+	if _, _, err := platformShell("linux", "echo hi"); err == nil {
+		t.Fatalf("expected an error when no shell is available")
+	}
+}
+
+func TestWindowsCmdShimTask(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("Windows .cmd shim resolution")
+	}
+	root := t.TempDir()
+	shimDir := filepath.Join(root, "shims")
+	writeTestFile(t, filepath.Join(shimDir, "greet.cmd"), "@echo off\r\necho hello %1> \"%~dp0out.txt\"\r\n", 0o755)
+	writeTestFile(t, filepath.Join(root, ".rig", "bin", "lint.cmd"), "@echo off\r\nexit /b 3\r\n", 0o755)
+	writeTestFile(t, filepath.Join(root, "rig.toml"), `
+[tasks]
+greet = "greet world"
+`, 0o644)
+	t.Setenv("PATH", shimDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	if err := RunWith(context.Background(), root, "greet", nil, RunOptions{}); err != nil {
+		t.Fatalf("greet: %v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(shimDir, "out.txt")); err != nil || string(b) != "hello world\r\n" {
+		t.Fatalf("shim output=%q err=%v", b, err)
+	}
+
+	lock := Lockfile{Tools: []LockedTool{{Requested: "lint@v1.0.0", Bin: "lint"}}}
+	p, ok, err := ResolveManagedToolExecutable(filepath.Join(root, "rig.toml"), lock, "lint")
+	if err != nil || !ok || filepath.Base(p) != "lint.cmd" {
+		t.Fatalf("managed shim=%q ok=%v err=%v", p, ok, err)
+	}
+	var exitErr *exec.ExitError
+	if err := Execute(p, nil, ExecOptions{}); !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("shim exit err=%v", err)
+	}
+}
+
+func TestRunWithCanceledContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "rig.toml"), `
+[tasks]
+slow = "sleep 30"
+after = { command = "touch after.txt", depends_on = ["slow"] }
+`, 0o644)
+	writeTestFile(t, filepath.Join(root, "rig.lock"), "schema = 0\n", 0o644)
+
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 200*time.Millisecond, errors.New("deadline for test"))
+	defer cancel()
+	start := time.Now()
+	err := RunWith(ctx, root, "after", nil, RunOptions{})
+	if err == nil || !strings.Contains(err.Error(), "run canceled: deadline for test") {
+		t.Fatalf("expected a canceled run, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("the running command was not interrupted (took %s)", elapsed)
+	}
+	if _, err := os.Stat(filepath.Join(root, "after.txt")); err == nil {
+		t.Fatal("a task started after the run was canceled")
+	}
+}
+
+func TestRunWithCanceledContextHonorsStopPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	for _, c := range []struct {
+		timeout string
+		drained bool
+	}{{"5s", true}, {"100ms", false}} {
+		root := t.TempDir()
+		writeTestFile(t, filepath.Join(root, "rig.toml"), `
+[tasks.serve]
+command = "sh -c 'trap \"sleep 0.5; touch drained; exit 0\" TERM; touch started; while :; do sleep 0.05; done'"
+stop_signal = "SIGTERM"
+stop_timeout = "`+c.timeout+`"
+`, 0o644)
+		writeTestFile(t, filepath.Join(root, "rig.lock"), "schema = 0\n", 0o644)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			for range 100 {
+				if _, err := os.Stat(filepath.Join(root, "started")); err == nil {
+					break
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+			cancel()
+		}()
+		_ = RunWith(ctx, root, "serve", nil, RunOptions{})
+		cancel()
+		_, err := os.Stat(filepath.Join(root, "drained"))
+		if drained := err == nil; drained != c.drained {
+			t.Fatalf("stop_timeout %s: drained=%v, want %v", c.timeout, drained, c.drained)
+		}
+	}
+}
+
+func TestTaskStopPolicy(t *testing.T) {
+	p, err := TaskStopPolicy(cfg.Task{StopSignal: "term", StopTimeout: "10s"}, DefaultTaskStop)
+	if err != nil || p.Signal != DefaultDevStop.Signal || p.Timeout != 10*time.Second {
+		t.Fatalf("policy = %+v, err = %v", p, err)
+	}
+	if p, _ := TaskStopPolicy(cfg.Task{}, DefaultDevStop); p != DefaultDevStop {
+		t.Fatalf("empty fields should keep the default, got %+v", p)
+	}
+	for _, bad := range []cfg.Task{{StopSignal: "SIGUSR9"}, {StopTimeout: "soon"}, {StopTimeout: "0s"}} {
+		if _, err := TaskStopPolicy(bad, DefaultTaskStop); err == nil {
+			t.Fatalf("expected an error for %v", bad)
+		}
+	}
+}
+
+func TestRunWithJobsRunsDependenciesConcurrently(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	root := t.TempDir()
+	// a and b each wait for the other to start, so they only finish when
+	// they run at the same time. check must never overlap with either.
+	writeTestFile(t, filepath.Join(root, "rig.toml"), `
+[tasks]
+a = "sh -c 'touch a.started; while [ ! -f b.started ]; do sleep 0.05; done; sleep 0.3; touch a.done'"
+b = "sh -c 'touch b.started; while [ ! -f a.started ]; do sleep 0.05; done; sleep 0.3; touch b.done'"
+check = { command = "sh -c 'test -f a.done -o ! -f a.started && test -f b.done -o ! -f b.started'", serial = true }
+ci = { command = "touch ci.done", depends_on = ["a", "b", "check"] }
+`, 0o644)
+	writeTestFile(t, filepath.Join(root, "rig.lock"), "schema = 0\n", 0o644)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	if err := RunWith(ctx, root, "ci", nil, RunOptions{Jobs: 4, Output: OutputErrorsOnly}); err != nil {
+		t.Fatalf("RunWith --jobs 4: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "ci.done")); err != nil {
+		t.Fatal("ci did not run after its dependencies")
+	}
+}
@@ -3,9 +3,13 @@
 package rig
 
 import (
+	"context"
+	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"runtime"
+	"time"
 )
 
 // ExecOptions describes how a task should be executed.
@@ -17,64 +21,141 @@ type ExecOptions struct {
 	// EnvExact, when true, uses Env as the full environment (no inheritance).
 	// When false (default), Env is appended to the current process environment.
 	EnvExact bool
+	// Stdout and Stderr replace the process streams when set.
+	Stdout io.Writer
+	Stderr io.Writer
+	// Limits bound the process tree's memory, CPU and priority (see runLimited).
+	Limits ResourceLimits
+	// Stop is how ExecuteContext stops the command when its context is done;
+	// zero fields fall back to DefaultTaskStop.
+	Stop StopPolicy
+	// KillTree runs the command in its own process group so that stopping it
+	// also stops every process it started (the whole tree is killed once the
+	// command has exited or Stop.Timeout passed). Not on Windows.
+	KillTree bool
 }
 
-// ExecuteShell runs a shell command string via the platform shell, streaming stdio.
-// On Windows: cmd /c <command>; on others: sh -c <command>.
-func ExecuteShell(command string, opts ExecOptions) error {
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("cmd", "/c", command)
-	} else {
-		cmd = exec.Command("sh", "-c", command)
+// stdio wires the command to the process streams or opts' overrides.
+func stdio(cmd *exec.Cmd, opts ExecOptions) {
+	cmd.Stdout, cmd.Stderr, cmd.Stdin = os.Stdout, os.Stderr, os.Stdin
+	if opts.Stdout != nil {
+		cmd.Stdout = opts.Stdout
 	}
-	if opts.Dir != "" {
-		cmd.Dir = opts.Dir
+	if opts.Stderr != nil {
+		cmd.Stderr = opts.Stderr
 	}
-	if opts.EnvExact {
-		cmd.Env = opts.Env
-	} else if len(opts.Env) > 0 {
-		cmd.Env = append(os.Environ(), opts.Env...)
+}
+
+// shellLookPath is swappable for tests.
+var shellLookPath = exec.LookPath
+
+// ShellCommand returns the program and arguments that run command through
+// the platform shell: cmd /c on Windows; elsewhere sh -c, or PowerShell
+// (pwsh, then powershell) when sh is not on PATH.
+func ShellCommand(command string) (string, []string, error) {
+	return platformShell(runtime.GOOS, command)
+}
+
+func platformShell(goos, command string) (string, []string, error) {
+	if goos == "windows" {
+		return "cmd", []string{"/c", command}, nil
 	}
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	return cmd.Run()
+	if _, err := shellLookPath("sh"); err == nil {
+		return "sh", []string{"-c", command}, nil
+	}
+	if name, ok := powerShell(); ok {
+		return name, powerShellArgs(command), nil
+	}
+	return "", nil, errors.New("no shell found: install sh or PowerShell (pwsh) and make sure it is on PATH")
+}
+
+// powerShell returns the first of pwsh (PowerShell 7+) and powershell
+// (Windows PowerShell) on PATH.
+func powerShell() (string, bool) {
+	for _, name := range []string{"pwsh", "powershell"} {
+		if _, err := shellLookPath(name); err == nil {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func powerShellArgs(command string) []string {
+	return []string{"-NoLogo", "-NoProfile", "-NonInteractive", "-Command", command}
+}
+
+// ExecuteShell runs a shell command string via the platform shell (see
+// ShellCommand), streaming stdio.
+func ExecuteShell(command string, opts ExecOptions) error {
+	name, args, err := ShellCommand(command)
+	if err != nil {
+		return err
+	}
+	return Execute(name, args, opts)
 }
 
-// ExecuteShellWith selects a specific shell by name: "sh", "bash", "pwsh", "cmd".
-// Falls back to platform default if unknown.
+// ExecuteShellWith selects a specific shell by name: "sh", "bash", "pwsh",
+// "powershell", "cmd". "pwsh" falls back to Windows PowerShell when pwsh is
+// not installed. Unknown names use the platform default.
 func ExecuteShellWith(shell, command string, opts ExecOptions) error {
-	var cmd *exec.Cmd
 	switch shell {
 	case "bash":
-		cmd = exec.Command("bash", "-lc", command)
+		return Execute("bash", []string{"-lc", command}, opts)
 	case "sh":
-		cmd = exec.Command("sh", "-c", command)
+		return Execute("sh", []string{"-c", command}, opts)
 	case "pwsh":
-		cmd = exec.Command("pwsh", "-NoLogo", "-NoProfile", "-Command", command)
+		name := "pwsh"
+		if found, ok := powerShell(); ok {
+			name = found
+		}
+		return Execute(name, powerShellArgs(command), opts)
+	case "powershell":
+		return Execute("powershell", powerShellArgs(command), opts)
 	case "cmd":
-		cmd = exec.Command("cmd", "/c", command)
+		return Execute("cmd", []string{"/c", command}, opts)
 	default:
 		return ExecuteShell(command, opts)
 	}
-	if opts.Dir != "" {
-		cmd.Dir = opts.Dir
-	}
-	if opts.EnvExact {
-		cmd.Env = opts.Env
-	} else if len(opts.Env) > 0 {
-		cmd.Env = append(os.Environ(), opts.Env...)
-	}
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	return cmd.Run()
 }
 
+// execCancelGrace is how long a canceled command has to exit after it was
+// interrupted before it is killed, unless the task sets stop_timeout.
+const execCancelGrace = 5 * time.Second
+
 // Execute runs a binary with argv directly (no shell), streaming stdio.
 func Execute(name string, args []string, opts ExecOptions) error {
-	cmd := exec.Command(name, args...)
+	return ExecuteContext(context.Background(), name, args, opts)
+}
+
+// ExecuteContext is Execute bound to ctx: when ctx is done the command is
+// sent opts.Stop.Signal (killed on Windows), and killed if it has not exited
+// within opts.Stop.Timeout. Both default to DefaultTaskStop.
+func ExecuteContext(ctx context.Context, name string, args []string, opts ExecOptions) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if ctx.Done() != nil {
+		stop := opts.Stop
+		if stop.Signal == nil {
+			stop.Signal = DefaultTaskStop.Signal
+		}
+		if stop.Timeout <= 0 {
+			stop.Timeout = DefaultTaskStop.Timeout
+		}
+		cmd.Cancel = func() error {
+			switch {
+			case runtime.GOOS == "windows":
+				return cmd.Process.Kill()
+			case opts.KillTree:
+				p := cmd.Process
+				time.AfterFunc(stop.Timeout, func() { _ = signalProcessGroup(p, os.Kill) })
+				return signalProcessGroup(p, stop.Signal)
+			}
+			return cmd.Process.Signal(stop.Signal)
+		}
+		cmd.WaitDelay = stop.Timeout
+		if opts.KillTree {
+			setProcessGroup(cmd)
+		}
+	}
 	if opts.Dir != "" {
 		cmd.Dir = opts.Dir
 	}
@@ -83,8 +164,9 @@ func Execute(name string, args []string, opts ExecOptions) error {
 	} else if len(opts.Env) > 0 {
 		cmd.Env = append(os.Environ(), opts.Env...)
 	}
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+	stdio(cmd, opts)
+	if !opts.Limits.empty() {
+		return runLimited(cmd, opts.Limits)
+	}
 	return cmd.Run()
 }
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package rig
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwner returns the uid owning path.
+func fileOwner(path string) (int, bool) {
+	uid, _, ok := fileOwnership(path)
+	return uid, ok
+}
+
+// fileOwnership returns the uid and gid owning path.
+func fileOwnership(path string) (uid, gid int, ok bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(st.Uid), int(st.Gid), true
+}
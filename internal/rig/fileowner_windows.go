@@ -0,0 +1,9 @@
+//go:build windows
+
+package rig
+
+// fileOwner is not supported on Windows, where rig never runs as uid 0.
+func fileOwner(string) (int, bool) { return 0, false }
+
+// fileOwnership is not supported on Windows, which has no uid/gid owners.
+func fileOwnership(string) (uid, gid int, ok bool) { return 0, 0, false }
@@ -0,0 +1,188 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FmtOptions controls `rig fmt`.
+type FmtOptions struct {
+	// Check lists unformatted files instead of rewriting them.
+	Check bool
+	// Staged limits formatting to git-staged .go files.
+	Staged bool
+}
+
+// FmtResult describes a formatter run.
+type FmtResult struct {
+	Formatter   string
+	Files       int
+	Unformatted []string
+}
+
+// Fmt formats Go files under the project root.
+//
+// gofumpt is used when it is declared in [tools] (executed from .rig/bin like any
+// other managed tool); otherwise gofmt from the Go toolchain on PATH is used.
+// Paths in the result are relative to the rig.toml directory.
+func Fmt(startDir string, opts FmtOptions) (FmtResult, error) {
+	conf, confPath, err := LoadConfig(startDir)
+	if err != nil {
+		return FmtResult{}, err
+	}
+	root := filepath.Dir(confPath)
+
+	exe, name, err := resolveFormatter(conf.Tools, confPath)
+	if err != nil {
+		return FmtResult{}, err
+	}
+
+	var files []string
+	if opts.Staged {
+		files, err = stagedGoFiles(root)
+	} else {
+		files, err = collectGoFiles(root)
+	}
+	if err != nil {
+		return FmtResult{}, err
+	}
+	res := FmtResult{Formatter: name, Files: len(files)}
+	if len(files) == 0 {
+		return res, nil
+	}
+
+	flag := "-w"
+	if opts.Check {
+		flag = "-l"
+	}
+	for _, chunk := range chunkArgs(files, fmtArgBytes) {
+		out, err := execCapture(exe, append([]string{flag}, chunk...), root, nil)
+		if err != nil {
+			return res, fmt.Errorf("%s failed: %w: %s", name, err, out)
+		}
+		if opts.Check {
+			for _, line := range strings.Split(out, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					res.Unformatted = append(res.Unformatted, filepath.ToSlash(line))
+				}
+			}
+		}
+	}
+	sort.Strings(res.Unformatted)
+	return res, nil
+}
+
+// fmtArgBytes caps the file arguments of one formatter run, well under
+// Windows' 32K command line and the smallest Unix ARG_MAX.
+const fmtArgBytes = 16 << 10
+
+// chunkArgs splits args into runs whose lengths (plus a separator each) stay
+// within limit. An argument longer than limit gets a run of its own.
+func chunkArgs(args []string, limit int) [][]string {
+	var chunks [][]string
+	var cur []string
+	size := 0
+	for _, a := range args {
+		if len(cur) > 0 && size+len(a)+1 > limit {
+			chunks = append(chunks, cur)
+			cur, size = nil, 0
+		}
+		cur = append(cur, a)
+		size += len(a) + 1
+	}
+	if len(cur) > 0 {
+		chunks = append(chunks, cur)
+	}
+	return chunks
+}
+
+func resolveFormatter(tools map[string]string, configPath string) (exe string, name string, err error) {
+	if _, ok := tools["gofumpt"]; ok || hasToolModule(tools, "mvdan.cc/gofumpt") {
+		lock, err := ReadRigLockForConfig(configPath)
+		if err != nil {
+			return "", "", fmt.Errorf("gofumpt is pinned in [tools] but rig.lock is unavailable (run 'rig sync'): %w", err)
+		}
+		p, ok, err := ResolveManagedToolExecutable(configPath, lock, "gofumpt")
+		if err != nil {
+			return "", "", err
+		}
+		if !ok {
+			return "", "", errors.New("gofumpt is pinned in [tools] but missing from rig.lock (run 'rig sync')")
+		}
+		return p, "gofumpt", nil
+	}
+	p, err := resolveExecutable("gofmt", filepath.Dir(configPath), nil)
+	if err != nil {
+		return "", "", err
+	}
+	return p, "gofmt", nil
+}
+
+func hasToolModule(tools map[string]string, module string) bool {
+	for name := range tools {
+		if ResolveToolIdentity(name).Module == module {
+			return true
+		}
+	}
+	return false
+}
+
+// collectGoFiles walks root and returns .go files relative to root, skipping the
+// directories the go command itself ignores (vendor, testdata, dot and underscore dirs).
+func collectGoFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p == root {
+				return nil
+			}
+			base := d.Name()
+			if base == "vendor" || base == "testdata" || strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+		rel, rerr := filepath.Rel(root, p)
+		if rerr != nil {
+			return rerr
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func stagedGoFiles(root string) ([]string, error) {
+	out, err := execCapture("git", []string{"diff", "--cached", "--name-only", "--diff-filter=ACMR", "--relative", "--", "*.go"}, root, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list staged files: %w: %s", err, out)
+	}
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, line)); err != nil {
+			continue
+		}
+		files = append(files, filepath.FromSlash(line))
+	}
+	sort.Strings(files)
+	return files, nil
+}
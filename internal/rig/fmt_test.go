@@ -0,0 +1,76 @@
+package rig
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCollectGoFilesSkipsIgnoredDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{
+		"main.go",
+		"pkg/a.go",
+		"pkg/readme.md",
+		"vendor/x/x.go",
+		"testdata/t.go",
+		".rig/gen.go",
+		"_scratch/s.go",
+	} {
+		writeTestFile(t, filepath.Join(dir, p), "package x\n", 0o644)
+	}
+
+	got, err := collectGoFiles(dir)
+	if err != nil {
+		t.Fatalf("collectGoFiles: %v", err)
+	}
+	want := []string{"main.go", filepath.Join("pkg", "a.go")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("files=%v, want %v", got, want)
+	}
+}
+
+func TestFmtCheckListsUnformattedFiles(t *testing.T) {
+	if _, err := exec.LookPath("gofmt"); err != nil {
+		t.Skip("gofmt not on PATH")
+	}
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), "[project]\nname='tmp'\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "ok.go"), "package x\n\nfunc A() {}\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "bad", "bad.go"), "package bad\nfunc  B( ) {  }\n", 0o644)
+
+	res, err := Fmt(dir, FmtOptions{Check: true})
+	if err != nil {
+		t.Fatalf("Fmt: %v", err)
+	}
+	if res.Formatter != "gofmt" || res.Files != 2 {
+		t.Fatalf("formatter=%q files=%d", res.Formatter, res.Files)
+	}
+	if !reflect.DeepEqual(res.Unformatted, []string{"bad/bad.go"}) {
+		t.Fatalf("unformatted=%v", res.Unformatted)
+	}
+
+	if _, err := Fmt(dir, FmtOptions{}); err != nil {
+		t.Fatalf("Fmt write: %v", err)
+	}
+	res, err = Fmt(dir, FmtOptions{Check: true})
+	if err != nil {
+		t.Fatalf("Fmt recheck: %v", err)
+	}
+	if len(res.Unformatted) != 0 {
+		t.Fatalf("expected clean tree after fmt, got %v", res.Unformatted)
+	}
+}
+
+func TestChunkArgs(t *testing.T) {
+	args := []string{"a.go", "bb.go", "c.go", "a/very/long/path.go", "d.go"}
+	got := chunkArgs(args, 11)
+	want := [][]string{{"a.go", "bb.go"}, {"c.go"}, {"a/very/long/path.go"}, {"d.go"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("chunkArgs = %q, want %q", got, want)
+	}
+	if got := chunkArgs(nil, 11); got != nil {
+		t.Fatalf("chunkArgs(nil) = %q", got)
+	}
+}
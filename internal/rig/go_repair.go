@@ -0,0 +1,104 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// GoToolchainState compares tools.go with rig.lock and the go in the project.
+type GoToolchainState struct {
+	Requested string // tools.go, normalized (x.y.z or "latest")
+	Locked    string // rig.lock [toolchain.go].detected; "" when absent
+	Have      string // `go version` in the project directory
+}
+
+// InSync reports whether the installed go satisfies tools.go and matches rig.lock.
+func (s GoToolchainState) InSync() bool {
+	return (s.Requested == "latest" || s.Have == s.Requested) && s.Locked == s.Have
+}
+
+// Satisfied reports whether the installed go satisfies tools.go, so only
+// rig.lock needs rewriting.
+func (s GoToolchainState) Satisfied() bool {
+	return s.Requested == "latest" || s.Have == s.Requested
+}
+
+// CanInstall reports whether Go's toolchain switching can provide the
+// requested version: it only ever switches to a newer toolchain.
+func (s GoToolchainState) CanInstall() bool {
+	req, err1 := parseSemver(s.Requested)
+	have, err2 := parseSemver(s.Have)
+	return err1 == nil && err2 == nil && compareSemver(req, have) > 0
+}
+
+// InspectGoToolchain reads tools.go (goReqRaw), rig.lock and `go version`.
+func InspectGoToolchain(configPath, goReqRaw string) (GoToolchainState, error) {
+	req, err := NormalizeGoToolchainRequested(goReqRaw)
+	if err != nil {
+		return GoToolchainState{}, fmt.Errorf("tools.go: %w", err)
+	}
+	st := GoToolchainState{Requested: req}
+	if lock, err := ReadRigLockForConfig(configPath); err == nil && lock.Toolchain != nil && lock.Toolchain.Go != nil {
+		st.Locked = strings.TrimSpace(lock.Toolchain.Go.Detected)
+	}
+	if st.Have, err = DetectGoToolchainVersion(filepath.Dir(configPath), nil); err != nil {
+		return st, err
+	}
+	return st, nil
+}
+
+// goToolRE matches `go = "..."` in the [tools] table of rig.toml.
+var goToolRE = regexp.MustCompile(`^(\s*go\s*=\s*)("[^"]*"|'[^']*')(.*)$`)
+
+// SetConfigGoVersion rewrites `go = "..."` under [tools] in rig.toml,
+// leaving the rest of the file untouched. tools.go declared elsewhere (an
+// included file, an inline table) must be edited by hand.
+func SetConfigGoVersion(configPath, version string) error {
+	b, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	inTools := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inTools = trimmed == "[tools]"
+			continue
+		}
+		if !inTools {
+			continue
+		}
+		if m := goToolRE.FindStringSubmatch(strings.TrimRight(line, "\r\n")); m != nil {
+			eol := line[len(strings.TrimRight(line, "\r\n")):]
+			lines[i] = m[1] + `"` + version + `"` + m[3] + eol
+			return os.WriteFile(configPath, []byte(strings.Join(lines, "")), 0o644)
+		}
+	}
+	return fmt.Errorf("no go = \"...\" under [tools] in %s; change tools.go where it is declared", configPath)
+}
+
+// InstallGoToolchain records go<version> as the toolchain in the project's
+// go.mod and runs `go version` so the go command downloads and switches to
+// it (GOTOOLCHAIN=auto). It returns the version go now reports.
+func InstallGoToolchain(configPath, version string) (string, error) {
+	dir := filepath.Dir(configPath)
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+		return "", errors.New("no go.mod next to rig.toml to record the toolchain in")
+	}
+	if out, err := execCapture("go", []string{"mod", "edit", "-toolchain=go" + version}, dir, nil); err != nil {
+		return "", fmt.Errorf("go mod edit -toolchain: %w: %s", err, out)
+	}
+	have, err := DetectGoToolchainVersion(dir, nil)
+	if err != nil {
+		return "", err
+	}
+	if have != version {
+		return have, fmt.Errorf("go still reports %s after setting toolchain go%s (is GOTOOLCHAIN=local set?)", have, version)
+	}
+	return have, nil
+}
@@ -1,6 +1,7 @@
 package rig
 
 import (
+	"debug/buildinfo"
 	"fmt"
 	"path/filepath"
 	"regexp"
@@ -94,3 +95,18 @@ func checkGoAgainstLockIfRequired(tools map[string]string, lock Lockfile, config
 func CheckGoToolchainAgainstLock(tools map[string]string, lock Lockfile, configPath string) (*GoStatusRow, bool) {
 	return checkGoAgainstLockIfRequired(tools, lock, configPath)
 }
+
+// ToolBuildGoVersion reports the Go version (x.y.z) that built the binary at
+// path, read from its embedded build info.
+func ToolBuildGoVersion(path string) (string, error) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	v := strings.TrimPrefix(info.GoVersion, "go")
+	// Drop experiment suffixes such as "1.22.0 X:nocoverageredesign".
+	if i := strings.IndexAny(v, " \t"); i >= 0 {
+		v = v[:i]
+	}
+	return v, nil
+}
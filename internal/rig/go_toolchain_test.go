@@ -123,3 +123,109 @@ func TestCheckAndRunValidateGoToolchain(t *testing.T) {
 		t.Fatalf("expected Run failure due to go mismatch")
 	}
 }
+
+func TestToolBuildGoVersion(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skipf("no executable path: %v", err)
+	}
+	got, err := ToolBuildGoVersion(exe)
+	if err != nil {
+		t.Fatalf("ToolBuildGoVersion: %v", err)
+	}
+	if want := strings.TrimPrefix(strings.Fields(runtime.Version())[0], "go"); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if _, err := ToolBuildGoVersion(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("expected error for missing binary")
+	}
+}
+
+func TestCheckInstalledToolsFlagsStaleBuilder(t *testing.T) {
+	dir := setupToolsFixture(t)
+	confPath := filepath.Join(dir, "rig.toml")
+	lock, err := ReadLockfile(filepath.Join(dir, "rig.lock"))
+	if err != nil {
+		t.Fatalf("read lock: %v", err)
+	}
+	lock.Toolchain = &ToolchainLock{Go: &GoToolchainLock{Kind: "go-toolchain", Requested: "1.22.5", Detected: "1.22.5"}}
+	for i := range lock.Tools {
+		lock.Tools[i].Go = "1.22.5"
+		if lock.Tools[i].Bin == "mockery" {
+			lock.Tools[i].Go = "1.21.0"
+		}
+	}
+	tools := map[string]string{"go": "1.22.5", "mockery": "v2.46.0", "golangci-lint": "1.62.0"}
+
+	rows, missing, mismatched, _, err := CheckInstalledTools(tools, lock, confPath)
+	if err != nil {
+		t.Fatalf("CheckInstalledTools: %v", err)
+	}
+	if missing != 0 || mismatched != 1 {
+		t.Fatalf("missing=%d mismatched=%d, want 0/1", missing, mismatched)
+	}
+	for _, r := range rows {
+		want := string(ToolOK)
+		if r.Name == "mockery" {
+			want = string(ToolStale)
+		}
+		if r.Status != want {
+			t.Fatalf("%s status=%q, want %q", r.Name, r.Status, want)
+		}
+	}
+
+	// Locks written before builders were recorded are not flagged.
+	for i := range lock.Tools {
+		lock.Tools[i].Go = ""
+	}
+	if _, _, mismatched, _, _ := CheckInstalledTools(tools, lock, confPath); mismatched != 0 {
+		t.Fatalf("mismatched=%d without recorded builders", mismatched)
+	}
+
+	b, err := MarshalLockfile(Lockfile{Schema: LockSchema0, Tools: []LockedTool{{Kind: "go-binary", Requested: "x@v1", Resolved: "example.com/x@v1.0.0", Module: "example.com/x", SHA256: "ab", Go: "1.22.5"}}})
+	if err != nil || !strings.Contains(string(b), "go = \"1.22.5\"\n") {
+		t.Fatalf("marshal: %v\n%s", err, b)
+	}
+}
+
+func TestGoToolchainStateRepairs(t *testing.T) {
+	cases := []struct {
+		st                            GoToolchainState
+		inSync, satisfied, canInstall bool
+	}{
+		{GoToolchainState{Requested: "1.22.3", Locked: "1.22.3", Have: "1.22.3"}, true, true, false},
+		{GoToolchainState{Requested: "1.22.3", Locked: "1.22.1", Have: "1.22.3"}, false, true, false},
+		{GoToolchainState{Requested: "latest", Locked: "1.22.1", Have: "1.23.0"}, false, true, false},
+		{GoToolchainState{Requested: "1.23.0", Locked: "1.22.3", Have: "1.22.3"}, false, false, true},
+		{GoToolchainState{Requested: "1.21.0", Locked: "1.22.3", Have: "1.22.3"}, false, false, false},
+	}
+	for _, c := range cases {
+		if c.st.InSync() != c.inSync || c.st.Satisfied() != c.satisfied || c.st.CanInstall() != c.canInstall {
+			t.Fatalf("%+v: InSync=%v Satisfied=%v CanInstall=%v", c.st, c.st.InSync(), c.st.Satisfied(), c.st.CanInstall())
+		}
+	}
+}
+
+func TestSetConfigGoVersion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rig.toml")
+	in := "[project]\nname = \"x\"\n\n[cache]\ngo = \".rig/cache\"\n\n[tools]\ngolangci-lint = \"1.59.0\"\ngo = \"1.22.3\" # pinned\r\n"
+	if err := os.WriteFile(path, []byte(in), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetConfigGoVersion(path, "1.23.1"); err != nil {
+		t.Fatalf("SetConfigGoVersion: %v", err)
+	}
+	b, _ := os.ReadFile(path)
+	want := strings.Replace(in, `go = "1.22.3" # pinned`, `go = "1.23.1" # pinned`, 1)
+	if string(b) != want {
+		t.Fatalf("got:\n%q\nwant:\n%q", b, want)
+	}
+
+	if err := os.WriteFile(path, []byte("[tools]\ngofumpt = \"latest\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetConfigGoVersion(path, "1.23.1"); err == nil || !strings.Contains(err.Error(), "change tools.go where it is declared") {
+		t.Fatalf("expected error for missing tools.go, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package rig
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// GoEnvConflict is a Go environment variable whose value would break
+// installing tools into .rig/bin, and what rig does about it.
+type GoEnvConflict struct {
+	Var    string `json:"var"`
+	Value  string `json:"value"`
+	Detail string `json:"detail"`
+}
+
+func (c GoEnvConflict) String() string {
+	return fmt.Sprintf("%s=%s: %s", c.Var, c.Value, c.Detail)
+}
+
+// GoInstallEnvConflicts reports GOBIN, GOFLAGS, and GOWORK settings (read
+// with getenv) that GoInstallEnv overrides for `go install` into binDir.
+func GoInstallEnvConflicts(binDir string, getenv func(string) string) []GoEnvConflict {
+	var out []GoEnvConflict
+	if v := strings.TrimSpace(getenv("GOBIN")); v != "" && filepath.Clean(v) != filepath.Clean(binDir) {
+		out = append(out, GoEnvConflict{Var: "GOBIN", Value: v, Detail: "overridden; rig installs tools into " + binDir})
+	}
+	if v := strings.TrimSpace(getenv("GOFLAGS")); v != "" {
+		if _, dropped := installGoFlags(v); len(dropped) > 0 {
+			out = append(out, GoEnvConflict{Var: "GOFLAGS", Value: v, Detail: fmt.Sprintf("%s removed for tool installs; 'go install pkg@version' builds outside your module and rejects -mod and -modfile", strings.Join(dropped, " "))})
+		}
+	}
+	if v := strings.TrimSpace(getenv("GOWORK")); v != "" && v != "off" {
+		out = append(out, GoEnvConflict{Var: "GOWORK", Value: v, Detail: "overridden with GOWORK=off; tools resolve and install outside any go.work workspace"})
+	}
+	return out
+}
+
+// GoInstallEnv returns the entries rig appends to the environment of
+// `go install` and tool resolution so the user's GOBIN, GOFLAGS, and GOWORK
+// cannot redirect or break installs into binDir.
+func GoInstallEnv(binDir string, getenv func(string) string) []string {
+	env := []string{"GOBIN=" + binDir, "GOWORK=off"}
+	if v := strings.TrimSpace(getenv("GOFLAGS")); v != "" {
+		if kept, dropped := installGoFlags(v); len(dropped) > 0 {
+			env = append(env, "GOFLAGS="+strings.Join(kept, " "))
+		}
+	}
+	return env
+}
+
+// installGoFlags splits GOFLAGS into the flags safe for `go install
+// pkg@version` and the module-selection flags it rejects.
+func installGoFlags(goflags string) (kept, dropped []string) {
+	for _, f := range strings.Fields(goflags) {
+		name, _, _ := strings.Cut(strings.TrimLeft(f, "-"), "=")
+		if name == "mod" || name == "modfile" {
+			dropped = append(dropped, f)
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept, dropped
+}
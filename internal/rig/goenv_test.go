@@ -0,0 +1,38 @@
+package rig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoInstallEnvConflicts(t *testing.T) {
+	env := map[string]string{
+		"GOBIN":   "/home/me/go/bin",
+		"GOFLAGS": "-mod=vendor -trimpath",
+		"GOWORK":  "/src/go.work",
+	}
+	getenv := func(k string) string { return env[k] }
+
+	got := GoInstallEnvConflicts("/proj/.rig/bin", getenv)
+	var vars []string
+	for _, c := range got {
+		vars = append(vars, c.Var)
+	}
+	if !reflect.DeepEqual(vars, []string{"GOBIN", "GOFLAGS", "GOWORK"}) {
+		t.Fatalf("conflicts=%v", got)
+	}
+
+	wantEnv := []string{"GOBIN=/proj/.rig/bin", "GOWORK=off", "GOFLAGS=-trimpath"}
+	if e := GoInstallEnv("/proj/.rig/bin", getenv); !reflect.DeepEqual(e, wantEnv) {
+		t.Fatalf("env=%v, want %v", e, wantEnv)
+	}
+
+	// Settings that already agree with rig are not conflicts.
+	env = map[string]string{"GOBIN": "/proj/.rig/bin/", "GOFLAGS": "-trimpath", "GOWORK": "off"}
+	if got := GoInstallEnvConflicts("/proj/.rig/bin", getenv); len(got) != 0 {
+		t.Fatalf("unexpected conflicts: %v", got)
+	}
+	if e := GoInstallEnv("/proj/.rig/bin", getenv); !reflect.DeepEqual(e, []string{"GOBIN=/proj/.rig/bin", "GOWORK=off"}) {
+		t.Fatalf("env=%v", e)
+	}
+}
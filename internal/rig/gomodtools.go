@@ -0,0 +1,145 @@
+package rig
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GoModToolDrift is a go.mod tool directive (Go 1.24+) that [tools] does not
+// declare, or declares at a different version.
+type GoModToolDrift struct {
+	// Tool is the [tools] key for the package: the existing key, or a short
+	// name (else the package path) for a missing one.
+	Tool    string `json:"tool"`
+	Package string `json:"package"`
+	// GoMod is the version go.mod requires for the package's module.
+	GoMod string `json:"gomod"`
+	// Tools is the [tools] version, empty when the tool is missing.
+	Tools  string `json:"tools,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// GoModToolDrifts cross-checks the tool directives of the go.mod next to
+// rig.toml against tools. A [tools] entry matches a directive when its
+// install path is the directive's package. No go.mod, or one without tool
+// directives, yields nothing.
+func GoModToolDrifts(tools map[string]string, configPath string) ([]GoModToolDrift, error) {
+	b, err := os.ReadFile(filepath.Join(filepath.Dir(configPath), "go.mod"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read go.mod: %w", err)
+	}
+	mod, err := ParseGoMod(b)
+	if err != nil {
+		return nil, err
+	}
+	byPath := map[string]string{}
+	for k := range tools {
+		if k != "go" {
+			byPath[ResolveToolIdentity(k).InstallPath] = k
+		}
+	}
+	var drifts []GoModToolDrift
+	for _, pkg := range mod.Tool {
+		want := mod.Version(pkg)
+		key, ok := byPath[pkg]
+		if !ok {
+			drifts = append(drifts, GoModToolDrift{Tool: toolKeyForInstallPath(pkg), Package: pkg, GoMod: want, Reason: "in go.mod but not in [tools]"})
+			continue
+		}
+		have := strings.TrimSpace(tools[key])
+		if want == "" || NormalizeToolVersion(have) == NormalizeToolVersion(want) {
+			continue
+		}
+		drifts = append(drifts, GoModToolDrift{Tool: key, Package: pkg, GoMod: want, Tools: have, Reason: fmt.Sprintf("go.mod requires %s, [tools] has %s", want, have)})
+	}
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i].Tool < drifts[j].Tool })
+	return drifts, nil
+}
+
+// SetConfigTools returns rig.toml content with each key of set pinned to its
+// version under [tools]: existing entries are rewritten in place, new ones are
+// appended after the table's last entry (adding a [tools] table if there is
+// none). Other lines, comments included, are kept as they are.
+func SetConfigTools(content string, set map[string]string) string {
+	lines := strings.SplitAfter(content, "\n")
+	pending := maps.Clone(set)
+	inTools, found := false, false
+	insertAt := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inTools = trimmed == "[tools]"
+			if inTools {
+				found, insertAt = true, i+1
+			}
+			continue
+		}
+		if !inTools || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		insertAt = i + 1
+		left, right, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			continue
+		}
+		key := strings.Trim(strings.TrimSpace(left), `"'`)
+		if v, ok := pending[key]; ok {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			eol := line[len(strings.TrimRight(line, "\r\n")):]
+			lines[i] = indent + tomlKey(key) + " = " + strconv.Quote(v) + trailingComment(right) + eol
+			delete(pending, key)
+		}
+	}
+	keys := make([]string, 0, len(pending))
+	for k := range pending {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var add []string
+	for _, k := range keys {
+		add = append(add, tomlKey(k)+" = "+strconv.Quote(pending[k])+"\n")
+	}
+	if !found {
+		out := strings.Join(lines, "")
+		if len(add) == 0 {
+			return out
+		}
+		if out != "" && !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		if out != "" {
+			out += "\n"
+		}
+		return out + "[tools]\n" + strings.Join(add, "")
+	}
+	if insertAt > 0 && !strings.HasSuffix(lines[insertAt-1], "\n") {
+		lines[insertAt-1] += "\n"
+	}
+	out := append(append(lines[:insertAt:insertAt], add...), lines[insertAt:]...)
+	return strings.Join(out, "")
+}
+
+// trailingComment returns the " # ..." after a quoted TOML value, if any.
+func trailingComment(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return ""
+	}
+	end := strings.IndexByte(value[1:], value[0])
+	if end < 0 {
+		return ""
+	}
+	rest := strings.TrimSpace(value[end+2:])
+	if !strings.HasPrefix(rest, "#") {
+		return ""
+	}
+	return " " + rest
+}
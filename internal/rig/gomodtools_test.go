@@ -0,0 +1,75 @@
+package rig
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGoModToolDrifts(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "rig.toml")
+	if drifts, err := GoModToolDrifts(map[string]string{"golangci-lint": "1.59.1"}, configPath); err != nil || drifts != nil {
+		t.Fatalf("no go.mod: drifts=%v err=%v", drifts, err)
+	}
+	writeTestFile(t, filepath.Join(dir, "go.mod"), `module example.com/x
+
+go 1.24
+
+tool (
+	github.com/golangci/golangci-lint/cmd/golangci-lint
+	golang.org/x/tools/cmd/stringer
+	github.com/cespare/reflex
+)
+
+require (
+	github.com/cespare/reflex v0.3.1 // indirect
+	github.com/golangci/golangci-lint v1.59.1 // indirect
+	golang.org/x/tools v0.30.0 // indirect
+)
+`, 0o644)
+	tools := map[string]string{"go": "1.24.0", "golangci-lint": "1.58.0", "reflex": "0.3.1"}
+	drifts, err := GoModToolDrifts(tools, configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GoModToolDrift{
+		{Tool: "golang.org/x/tools/cmd/stringer", Package: "golang.org/x/tools/cmd/stringer", GoMod: "v0.30.0", Reason: "in go.mod but not in [tools]"},
+		{Tool: "golangci-lint", Package: "github.com/golangci/golangci-lint/cmd/golangci-lint", GoMod: "v1.59.1", Tools: "1.58.0", Reason: "go.mod requires v1.59.1, [tools] has 1.58.0"},
+	}
+	if !reflect.DeepEqual(drifts, want) {
+		t.Fatalf("drifts =\n%+v\nwant\n%+v", drifts, want)
+	}
+}
+
+func TestSetConfigTools(t *testing.T) {
+	cases := []struct {
+		name, in string
+		set      map[string]string
+		want     string
+	}{
+		{
+			name: "update in place and append",
+			in:   "[project]\nname = \"x\"\n\n[tools]\n# linters\ngolangci-lint = \"1.58.0\" # pinned for CI\n\n[tasks]\nb = \"go build\"\n",
+			set:  map[string]string{"golangci-lint": "v1.59.1", "golang.org/x/tools/cmd/stringer": "v0.30.0"},
+			want: "[project]\nname = \"x\"\n\n[tools]\n# linters\ngolangci-lint = \"v1.59.1\" # pinned for CI\n\"golang.org/x/tools/cmd/stringer\" = \"v0.30.0\"\n\n[tasks]\nb = \"go build\"\n",
+		},
+		{
+			name: "empty table",
+			in:   "[tools]\n[tasks]\nb = \"go build\"\n",
+			set:  map[string]string{"reflex": "v0.3.1"},
+			want: "[tools]\nreflex = \"v0.3.1\"\n[tasks]\nb = \"go build\"\n",
+		},
+		{
+			name: "no table",
+			in:   "[project]\nname = \"x\"",
+			set:  map[string]string{"reflex": "v0.3.1"},
+			want: "[project]\nname = \"x\"\n\n[tools]\nreflex = \"v0.3.1\"\n",
+		},
+	}
+	for _, c := range cases {
+		if got := SetConfigTools(c.in, c.set); got != c.want {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", c.name, got, c.want)
+		}
+	}
+}
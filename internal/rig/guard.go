@@ -0,0 +1,37 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxConfigDepth is how many directories above the working directory
+// rig.toml may be found before rig warns that it may be the wrong project.
+const DefaultMaxConfigDepth = 5
+
+var geteuid = os.Geteuid
+
+// ConfigDepth returns how many directories cwd is below the directory holding
+// configPath, or 0 when cwd is not inside it.
+func ConfigDepth(configPath, cwd string) int {
+	rel, err := filepath.Rel(filepath.Dir(configPath), cwd)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return 0
+	}
+	return len(strings.Split(rel, string(filepath.Separator)))
+}
+
+// RootInUserProject reports whether rig runs as root in a project directory
+// owned by another user, and that user's uid. Files rig writes there (tools in
+// .rig/bin, rig.lock, caches) would then be owned by root.
+func RootInUserProject(configPath string) (int, bool) {
+	if geteuid() != 0 {
+		return 0, false
+	}
+	uid, ok := fileOwner(filepath.Dir(configPath))
+	if !ok || uid == 0 {
+		return 0, false
+	}
+	return uid, true
+}
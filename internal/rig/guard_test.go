@@ -0,0 +1,52 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestConfigDepth(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "src", "app")
+	conf := filepath.Join(root, "rig.toml")
+	for _, tc := range []struct {
+		cwd  string
+		want int
+	}{
+		{root, 0},
+		{filepath.Join(root, "cmd"), 1},
+		{filepath.Join(root, "internal", "a", "b"), 3},
+		{filepath.Dir(root), 0},
+		{filepath.Join(string(filepath.Separator), "src", "other"), 0},
+		{filepath.Join(root, "..x"), 1},
+	} {
+		if got := ConfigDepth(conf, tc.cwd); got != tc.want {
+			t.Errorf("ConfigDepth(%q) = %d, want %d", tc.cwd, got, tc.want)
+		}
+	}
+}
+
+func TestRootInUserProject(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() != 0 {
+		t.Skip("needs root to chown the project directory")
+	}
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "rig.toml")
+	if _, ok := RootInUserProject(conf); ok {
+		t.Fatalf("root-owned project should not warn")
+	}
+	if err := os.Chown(dir, 1000, 1000); err != nil {
+		t.Skipf("chown: %v", err)
+	}
+	if uid, ok := RootInUserProject(conf); !ok || uid != 1000 {
+		t.Fatalf("RootInUserProject = %d, %v; want 1000, true", uid, ok)
+	}
+
+	orig := geteuid
+	geteuid = func() int { return 1000 }
+	t.Cleanup(func() { geteuid = orig })
+	if _, ok := RootInUserProject(conf); ok {
+		t.Fatalf("non-root user should not warn")
+	}
+}
@@ -0,0 +1,111 @@
+package rig
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// heartbeat prints a "still running" line while a task has produced no
+// output for interval, so CI systems that kill silent jobs (e.g. after 10
+// minutes without output) leave long quiet tasks alone.
+type heartbeat struct {
+	name     string
+	interval time.Duration
+	out      io.Writer
+	start    time.Time
+
+	mu       sync.Mutex
+	output   time.Time // last task output; zero until the task writes
+	beat     time.Time // last heartbeat line
+	stop     chan struct{}
+	finished chan struct{}
+}
+
+// startHeartbeat begins watching task name; Stop ends it. A zero interval
+// returns nil, and every method is a no-op on nil.
+func startHeartbeat(name string, interval time.Duration, out io.Writer) *heartbeat {
+	if interval <= 0 {
+		return nil
+	}
+	h := &heartbeat{name: name, interval: interval, out: out, start: time.Now(), stop: make(chan struct{}), finished: make(chan struct{})}
+	go h.loop()
+	return h
+}
+
+func (h *heartbeat) loop() {
+	defer close(h.finished)
+	ticker := time.NewTicker(max(h.interval/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case now := <-ticker.C:
+			if line := h.due(now); line != "" {
+				fmt.Fprintln(h.out, StatusText(line))
+			}
+		}
+	}
+}
+
+// due returns the heartbeat line when the task has been quiet for interval
+// since its last output or the previous heartbeat.
+func (h *heartbeat) due(now time.Time) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	quiet := h.start
+	for _, t := range []time.Time{h.output, h.beat} {
+		if t.After(quiet) {
+			quiet = t
+		}
+	}
+	if now.Sub(quiet) < h.interval {
+		return ""
+	}
+	h.beat = now
+	last := "no output yet"
+	if !h.output.IsZero() {
+		last = "last output " + roundDuration(now.Sub(h.output)) + " ago"
+	}
+	return fmt.Sprintf("⏳ %s still running (%s) — %s", h.name, roundDuration(now.Sub(h.start)), last)
+}
+
+func roundDuration(d time.Duration) string {
+	if d >= time.Second {
+		d = d.Round(time.Second)
+	} else {
+		d = d.Round(time.Millisecond)
+	}
+	return d.String()
+}
+
+// Stop ends the heartbeat and waits for its goroutine.
+func (h *heartbeat) Stop() {
+	if h == nil {
+		return
+	}
+	close(h.stop)
+	<-h.finished
+}
+
+// writer returns w, counting each write as task output.
+func (h *heartbeat) writer(w io.Writer) io.Writer {
+	if h == nil {
+		return w
+	}
+	return heartbeatWriter{w: w, h: h}
+}
+
+type heartbeatWriter struct {
+	w io.Writer
+	h *heartbeat
+}
+
+func (hw heartbeatWriter) Write(b []byte) (int, error) {
+	hw.h.mu.Lock()
+	hw.h.output = time.Now()
+	hw.h.mu.Unlock()
+	return hw.w.Write(b)
+}
@@ -0,0 +1,95 @@
+package rig
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// defaultHermeticAllowlist is what `rig run --hermetic` passes through when
+// rig.toml has no [ci].env_allowlist.
+var defaultHermeticAllowlist = []string{"HOME", "PATH", "TMPDIR", "USER", "LANG", "TERM"}
+
+// hermeticEssentials are always passed through: PATH (rig puts its bin
+// directory first anyway) and, on Windows, what programs need to start.
+func hermeticEssentials(goos string) []string {
+	if goos == "windows" {
+		return []string{"PATH", "SYSTEMROOT", "COMSPEC", "PATHEXT", "TEMP", "TMP"}
+	}
+	return []string{"PATH"}
+}
+
+// RunningInCI reports whether rig runs under CI, going by the CI variable
+// that GitHub Actions, GitLab CI, CircleCI, and most other providers set.
+func RunningInCI() bool {
+	return strings.TrimSpace(os.Getenv("CI")) != ""
+}
+
+func validateEnvAllowlist(allow []string) error {
+	for _, pat := range allow {
+		if strings.TrimSpace(pat) == "" {
+			return fmt.Errorf("[ci].env_allowlist: empty entry")
+		}
+		if _, err := path.Match(pat, ""); err != nil {
+			return fmt.Errorf("[ci].env_allowlist: bad pattern %q", pat)
+		}
+	}
+	return nil
+}
+
+// FilterEnv keeps the KEY=VALUE entries of environ whose names match allow
+// (names or path.Match patterns such as "GO*") or are essential on goos, and
+// returns the names it dropped, sorted. Names compare case-insensitively on
+// Windows.
+func FilterEnv(environ, allow []string, goos string) (kept, dropped []string) {
+	allow = append(slices.Clone(allow), hermeticEssentials(goos)...)
+	fold := func(s string) string {
+		if goos == "windows" {
+			return strings.ToUpper(s)
+		}
+		return s
+	}
+	for _, kv := range environ {
+		k, _, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			continue
+		}
+		allowed := false
+		for _, pat := range allow {
+			if m, _ := path.Match(fold(pat), fold(k)); m {
+				allowed = true
+				break
+			}
+		}
+		if allowed {
+			kept = append(kept, kv)
+		} else {
+			dropped = append(dropped, k)
+		}
+	}
+	slices.Sort(dropped)
+	return kept, dropped
+}
+
+// runEnviron is the process environment tasks in a run inherit: all of it,
+// or under hermetic (--hermetic, or CI with [ci].env_allowlist set) only the
+// allowlisted variables. Tasks' own env, [cache], and profiles still apply
+// on top.
+func runEnviron(conf *cfg.Config, hermetic bool) []string {
+	allow := conf.CI.EnvAllowlist
+	if !hermetic && !(RunningInCI() && len(allow) > 0) {
+		return os.Environ()
+	}
+	source := "[ci].env_allowlist"
+	if len(allow) == 0 {
+		allow, source = defaultHermeticAllowlist, "default allowlist"
+	}
+	kept, dropped := FilterEnv(os.Environ(), allow, runtime.GOOS)
+	statusf(os.Stderr, "🔒 hermetic env: %d variable(s) passed, %d withheld (%s: %s)", len(kept), len(dropped), source, strings.Join(allow, ", "))
+	return kept
+}
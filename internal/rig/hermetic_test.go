@@ -0,0 +1,29 @@
+package rig
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilterEnv(t *testing.T) {
+	environ := []string{"HOME=/home/me", "PATH=/usr/bin", "GOFLAGS=-mod=mod", "GOPRIVATE=corp", "AWS_SECRET=x", "malformed"}
+	kept, dropped := FilterEnv(environ, []string{"HOME", "GO*"}, "linux")
+	if want := []string{"HOME=/home/me", "PATH=/usr/bin", "GOFLAGS=-mod=mod", "GOPRIVATE=corp"}; !slices.Equal(kept, want) {
+		t.Fatalf("kept=%q, want %q", kept, want)
+	}
+	if !slices.Equal(dropped, []string{"AWS_SECRET"}) {
+		t.Fatalf("dropped=%q", dropped)
+	}
+
+	kept, _ = FilterEnv([]string{"Path=C:\\bin", "SystemRoot=C:\\Windows", "home=x"}, []string{"HOME"}, "windows")
+	if len(kept) != 3 {
+		t.Fatalf("windows names should match case-insensitively and keep essentials, kept=%q", kept)
+	}
+
+	if err := validateEnvAllowlist([]string{"HOME", "GO*"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseConfigBytes([]byte("[ci]\nenv_allowlist = [\"GO[\"]\n")); err == nil {
+		t.Fatalf("expected a bad pattern error")
+	}
+}
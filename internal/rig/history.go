@@ -0,0 +1,123 @@
+package rig
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// taskHistoryKeep is how many runs of each task .rig/history.json remembers.
+const taskHistoryKeep = 10
+
+// taskTiming is one recorded run of a command task.
+type taskTiming struct {
+	At   time.Time `json:"at"`
+	MS   int64     `json:"ms"`
+	OK   bool      `json:"ok"`
+	Code int       `json:"code,omitempty"` // exit code of a failed run
+}
+
+// newTaskTiming records a run that started at start and ended with err.
+// A failure that is not a command's exit status has code 1.
+func newTaskTiming(start time.Time, err error) taskTiming {
+	t := taskTiming{At: start.UTC(), MS: time.Since(start).Milliseconds(), OK: err == nil}
+	if err != nil {
+		t.Code = 1
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && ee.ExitCode() > 0 {
+			t.Code = ee.ExitCode()
+		}
+	}
+	return t
+}
+
+func taskHistoryPath(configPath string) string {
+	return filepath.Join(CacheDir(configPath), "history.json")
+}
+
+// readTaskHistory returns the recorded runs per task, oldest first. A missing
+// or unreadable file is an empty history.
+func readTaskHistory(configPath string) map[string][]taskTiming {
+	h := map[string][]taskTiming{}
+	b, err := os.ReadFile(taskHistoryPath(configPath))
+	if err != nil {
+		return h
+	}
+	if json.Unmarshal(b, &h) != nil {
+		return map[string][]taskTiming{}
+	}
+	return h
+}
+
+// recordTaskHistory appends runs to .rig/history.json, keeping the last
+// taskHistoryKeep per task. History is best effort: errors are ignored.
+func recordTaskHistory(configPath string, runs map[string]taskTiming) {
+	if len(runs) == 0 {
+		return
+	}
+	h := readTaskHistory(configPath)
+	for name, t := range runs {
+		list := append(h[name], t)
+		if len(list) > taskHistoryKeep {
+			list = list[len(list)-taskHistoryKeep:]
+		}
+		h[name] = list
+	}
+	b, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return
+	}
+	path := taskHistoryPath(configPath)
+	if os.MkdirAll(filepath.Dir(path), 0o755) != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if os.WriteFile(tmp, append(b, '\n'), 0o644) == nil {
+		_ = os.Rename(tmp, path)
+	}
+}
+
+// LastRun is the most recent recorded run of a task.
+type LastRun struct {
+	At       time.Time
+	Duration time.Duration
+	ExitCode int
+}
+
+// LastRuns returns each task's most recent run recorded in
+// .rig/history.json. Failed runs recorded without an exit code report 1.
+func LastRuns(configPath string) map[string]LastRun {
+	out := map[string]LastRun{}
+	for name, runs := range readTaskHistory(configPath) {
+		if len(runs) == 0 {
+			continue
+		}
+		r := runs[len(runs)-1]
+		code := r.Code
+		if !r.OK && code == 0 {
+			code = 1
+		}
+		out[name] = LastRun{At: r.At, Duration: time.Duration(r.MS) * time.Millisecond, ExitCode: code}
+	}
+	return out
+}
+
+// estimateDuration is the median of a task's successful recorded runs and
+// how many there were; 0, 0 when it never succeeded.
+func estimateDuration(runs []taskTiming) (time.Duration, int) {
+	var ms []int64
+	for _, r := range runs {
+		if r.OK {
+			ms = append(ms, r.MS)
+		}
+	}
+	if len(ms) == 0 {
+		return 0, 0
+	}
+	slices.Sort(ms)
+	return time.Duration(ms[len(ms)/2]) * time.Millisecond, len(ms)
+}
@@ -0,0 +1,164 @@
+package rig
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreMatcher applies gitignore-style patterns to slash-separated paths
+// relative to a project root. Later patterns win; "!" re-includes.
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	pattern string
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// LoadIgnoreMatcher builds a matcher from root/.gitignore and
+// root/.git/info/exclude (when useGitignore is set) followed by extra patterns.
+func LoadIgnoreMatcher(root string, useGitignore bool, extra []string) *IgnoreMatcher {
+	m := &IgnoreMatcher{}
+	if useGitignore {
+		for _, p := range []string{filepath.Join(root, ".git", "info", "exclude"), filepath.Join(root, ".gitignore")} {
+			b, err := os.ReadFile(p)
+			if err != nil {
+				continue
+			}
+			sc := bufio.NewScanner(bytes.NewReader(b))
+			for sc.Scan() {
+				m.Add(sc.Text())
+			}
+		}
+	}
+	for _, p := range extra {
+		m.Add(p)
+	}
+	return m
+}
+
+// Add appends one gitignore line. Blank lines and comments are ignored.
+func (m *IgnoreMatcher) Add(line string) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+	r := ignoreRule{pattern: line}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return
+	}
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored && !strings.HasPrefix(line, "**") {
+		b.WriteString("(?:.*/)?")
+	}
+	b.WriteString(globToRegex(line))
+	if r.dirOnly {
+		// Match appends "/" to directories, so "dir/" only matches directories
+		// and paths beneath them.
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return
+	}
+	r.re = re
+	m.rules = append(m.rules, r)
+}
+
+// Match reports whether rel is ignored. Directories should be passed with
+// isDir set so that "dir/" patterns apply to them.
+func (m *IgnoreMatcher) Match(rel string, isDir bool) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+	rel = strings.TrimPrefix(filepath.ToSlash(rel), "./")
+	if isDir {
+		rel += "/"
+	}
+	ignored := false
+	for _, r := range m.rules {
+		if r.re.MatchString(rel) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// Regexps returns the non-negated patterns as regular expressions over
+// relative paths, for watchers that accept exclusion regexes (reflex -R).
+// Negations cannot be expressed this way and are dropped.
+func (m *IgnoreMatcher) Regexps() []string {
+	if m == nil {
+		return nil
+	}
+	var out []string
+	for _, r := range m.rules {
+		if !r.negate {
+			out = append(out, r.re.String())
+		}
+	}
+	return out
+}
+
+func globToRegex(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			j := strings.IndexByte(glob[i:], ']')
+			if j <= 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += j
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
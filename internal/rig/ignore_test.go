@@ -0,0 +1,48 @@
+package rig
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, ".gitignore"), `
+# build output
+dist/
+/bin
+*.log
+!keep.log
+node_modules
+docs/**/*.tmp
+`, 0o644)
+	m := LoadIgnoreMatcher(dir, true, []string{"generated/*.go"})
+
+	cases := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"dist", true, true},
+		{"dist/app.js", false, true},
+		{"web/dist/app.js", false, true},
+		{"dist", false, false},
+		{"bin/server", false, true},
+		{"cmd/bin/server", false, false},
+		{"server.log", false, true},
+		{"logs/keep.log", false, false},
+		{"web/node_modules/x/index.js", false, true},
+		{"docs/a/b/c.tmp", false, true},
+		{"generated/models.go", false, true},
+		{"main.go", false, false},
+	}
+	for _, c := range cases {
+		if got := m.Match(c.rel, c.isDir); got != c.want {
+			t.Fatalf("Match(%q, dir=%t)=%t, want %t", c.rel, c.isDir, got, c.want)
+		}
+	}
+
+	if LoadIgnoreMatcher(dir, false, nil).Match("dist/app.js", false) {
+		t.Fatalf("gitignore=false must not apply .gitignore")
+	}
+}
@@ -0,0 +1,138 @@
+package rig
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// RunOptions carries optional inputs for RunWith.
+type RunOptions struct {
+	// Inputs are values supplied up front (e.g. via --input name=value).
+	Inputs map[string]string
+	// Prompt asks for a missing input interactively. When nil, missing inputs
+	// fall back to their default or fail.
+	Prompt func(in cfg.TaskInput) (string, error)
+	// Output controls how dependency tasks print: OutputFull (default),
+	// OutputPrefixed, or OutputErrorsOnly. The requested task always streams.
+	Output string
+	// ContinueOnError runs every task whose dependencies succeeded and reports
+	// all failures at the end instead of stopping at the first.
+	ContinueOnError bool
+	// Profile names a [profile.<name>] whose env, tags, and flags apply to
+	// every task (see ProfileTaskEnv).
+	Profile string
+	// Heartbeat, when positive, prints a "still running" line to stderr for
+	// each task that has been silent that long (see heartbeat).
+	Heartbeat time.Duration
+	// Dirs (`rig run -C`) replaces the requested task's cwd or dirs: the
+	// command runs once in each matching directory, resolved against the
+	// working directory.
+	Dirs []string
+	// Isolate runs each command task in a temporary copy of its declared
+	// sources and copies only its declared outputs back (see runIsolated).
+	Isolate bool
+	// Hermetic passes tasks only the allowlisted environment variables
+	// ([ci].env_allowlist, or a small default), as under CI (see runEnviron).
+	Hermetic bool
+	// Jobs is how many task commands may run at once. Above 1, independent
+	// depends_on branches run concurrently; 0 or 1 runs them one at a time.
+	// It also caps mode = "parallel" steps.
+	Jobs int
+	// Force runs tasks with sources and outputs even when they are up to
+	// date (see taskUpToDate) or a cache plugin holds their outputs.
+	Force bool
+
+	// record, done, and onlyDirs serve `rig run --failed` (see RunFailed).
+	record   *lastRun
+	done     []string
+	onlyDirs map[string][]string
+}
+
+var inputPlaceholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// ParseInputFlags parses repeated name=value pairs.
+func ParseInputFlags(pairs []string) (map[string]string, error) {
+	out := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid --input %q (expected name=value)", p)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// resolveTaskInputs collects a value for every input declared by the planned
+// tasks, in plan order. Supplied inputs win, then the prompt, then the default.
+func resolveTaskInputs(tasks cfg.TasksMap, order []string, opts RunOptions) (map[string]string, error) {
+	values := make(map[string]string)
+	declared := make(map[string]struct{})
+	for _, name := range order {
+		for _, in := range tasks[name].Inputs {
+			declared[in.Name] = struct{}{}
+			if _, done := values[in.Name]; done {
+				continue
+			}
+			if v, ok := opts.Inputs[in.Name]; ok {
+				values[in.Name] = v
+				continue
+			}
+			v := ""
+			if opts.Prompt != nil {
+				var err error
+				if v, err = opts.Prompt(in); err != nil {
+					return nil, fmt.Errorf("task %q: input %q: %w", name, in.Name, err)
+				}
+			}
+			if v == "" {
+				v = in.Default
+			}
+			if v == "" {
+				return nil, fmt.Errorf("task %q: missing input %q (pass --input %s=VALUE)", name, in.Name, in.Name)
+			}
+			values[in.Name] = v
+		}
+	}
+
+	var unknown []string
+	for k := range opts.Inputs {
+		if _, ok := declared[k]; !ok {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown input(s): %s", strings.Join(unknown, ", "))
+	}
+	return values, nil
+}
+
+// substituteInputs replaces {{name}} in each argv element with the value of an
+// input declared by task. Other placeholders are left untouched.
+func substituteInputs(argv []string, task cfg.Task, values map[string]string) []string {
+	if len(task.Inputs) == 0 {
+		return argv
+	}
+	own := make(map[string]struct{}, len(task.Inputs))
+	for _, in := range task.Inputs {
+		own[in.Name] = struct{}{}
+	}
+	out := make([]string, len(argv))
+	for i, a := range argv {
+		out[i] = inputPlaceholderRe.ReplaceAllStringFunc(a, func(m string) string {
+			name := inputPlaceholderRe.FindStringSubmatch(m)[1]
+			if _, ok := own[name]; !ok {
+				return m
+			}
+			return values[name]
+		})
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+func TestResolveAndSubstituteTaskInputs(t *testing.T) {
+	tasks := cfg.TasksMap{
+		"gen": {Command: "echo", Inputs: []cfg.TaskInput{{Name: "dir", Default: "db"}}},
+		"migrate": {
+			Command:   "migrate create",
+			DependsOn: []string{"gen"},
+			Inputs:    []cfg.TaskInput{{Name: "migration_name", Prompt: "Migration name?"}},
+		},
+	}
+	order := []string{"gen", "migrate"}
+
+	var prompted []string
+	vals, err := resolveTaskInputs(tasks, order, RunOptions{
+		Inputs: map[string]string{"dir": "sql"},
+		Prompt: func(in cfg.TaskInput) (string, error) {
+			prompted = append(prompted, in.Name)
+			return "add users", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("resolveTaskInputs: %v", err)
+	}
+	if !reflect.DeepEqual(prompted, []string{"migration_name"}) {
+		t.Fatalf("prompted=%v", prompted)
+	}
+	argv := substituteInputs([]string{"migrate", "-dir={{dir}}", "{{ migration_name }}", "{{other}}"}, tasks["migrate"], vals)
+	want := []string{"migrate", "-dir={{dir}}", "add users", "{{other}}"}
+	if !reflect.DeepEqual(argv, want) {
+		t.Fatalf("argv=%q, want %q", argv, want)
+	}
+
+	if _, err := resolveTaskInputs(tasks, order, RunOptions{}); err == nil || !strings.Contains(err.Error(), "--input migration_name=VALUE") {
+		t.Fatalf("expected missing input error, got %v", err)
+	}
+	if _, err := resolveTaskInputs(tasks, order, RunOptions{Inputs: map[string]string{"migration_name": "x", "typo": "y"}}); err == nil {
+		t.Fatalf("expected unknown input error")
+	}
+}
+
+func TestLoadConfigTaskInputs(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks.migrate]
+command = "migrate create {{migration_name}}"
+inputs = [{ name = "migration_name", prompt = "Migration name?" }]
+`, 0o644)
+	conf, _, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	want := []cfg.TaskInput{{Name: "migration_name", Prompt: "Migration name?"}}
+	if !reflect.DeepEqual(conf.Tasks["migrate"].Inputs, want) {
+		t.Fatalf("inputs=%+v", conf.Tasks["migrate"].Inputs)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "rig.toml"), []byte("[tasks.x]\ncommand = \"echo\"\ninputs = [{ name = \"bad-name\" }]\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, _, err := LoadConfig(dir); err == nil {
+		t.Fatalf("expected invalid input name error")
+	}
+}
@@ -0,0 +1,248 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// isolatedWorkspace is a temporary copy of the files a task declares in
+// sources, standing in for the project root during `rig run --isolate`. A
+// task that reads an undeclared file fails there instead of passing by luck.
+type isolatedWorkspace struct {
+	base   string          // directory holding rig.toml
+	dir    string          // temporary root mirroring base
+	copied map[string]bool // slash paths, relative to base, copied in
+}
+
+// newIsolatedWorkspace copies t's sources into a fresh temporary directory.
+func newIsolatedWorkspace(confPath string, t cfg.Task) (*isolatedWorkspace, error) {
+	if len(t.Sources) == 0 {
+		return nil, errors.New("--isolate needs the task's sources (files it reads, e.g. sources = [\"go.mod\", \"go.sum\", \"cmd\", \"internal\"])")
+	}
+	base, err := filepath.Abs(filepath.Dir(confPath))
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "rig-isolate-")
+	if err != nil {
+		return nil, fmt.Errorf("create isolated workspace: %w", err)
+	}
+	w := &isolatedWorkspace{base: base, dir: dir, copied: map[string]bool{}}
+	for _, pat := range t.Sources {
+		matches, err := globUnder(base, pat)
+		if err == nil {
+			for _, m := range matches {
+				if err = w.copyIn(m); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, fmt.Errorf("sources %q: %w", pat, err)
+		}
+	}
+	return w, nil
+}
+
+// globUnder expands pattern relative to base and rejects matches outside it.
+func globUnder(base, pattern string) ([]string, error) {
+	p := NormalizeTaskPath(pattern)
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(base, p)
+	}
+	matches, err := filepath.Glob(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		if _, err := relUnder(base, m); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// relUnder returns path relative to base, or an error when it lies outside.
+func relUnder(base, path string) (string, error) {
+	rel, err := filepath.Rel(base, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the project", path)
+	}
+	return rel, nil
+}
+
+// copyIn copies a file or directory tree (skipping .git and .rig) from the
+// project into the workspace.
+func (w *isolatedWorkspace) copyIn(src string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && p != src && (d.Name() == ".git" || d.Name() == ".rig") {
+			return filepath.SkipDir
+		}
+		rel, err := relUnder(w.base, p)
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			w.copied[filepath.ToSlash(rel)] = true
+		}
+		return copyEntry(p, filepath.Join(w.dir, rel), d)
+	})
+}
+
+// path maps an absolute path in the project to its place in the workspace,
+// creating it as a directory (a task's cwd need not be a declared source).
+func (w *isolatedWorkspace) path(real string) (string, error) {
+	rel, err := relUnder(w.base, real)
+	if err != nil {
+		return "", err
+	}
+	p := filepath.Join(w.dir, rel)
+	return p, os.MkdirAll(p, 0o755)
+}
+
+// collect copies the files matching outputs back into the project and
+// returns how many it copied, plus the files the task created that neither
+// sources nor outputs declare.
+func (w *isolatedWorkspace) collect(outputs []string) (int, []string, error) {
+	declared := map[string]bool{}
+	n := 0
+	for _, pat := range outputs {
+		matches, err := globUnder(w.dir, pat)
+		if err != nil {
+			return n, nil, fmt.Errorf("outputs %q: %w", pat, err)
+		}
+		for _, m := range matches {
+			err := filepath.WalkDir(m, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				rel, _ := filepath.Rel(w.dir, p)
+				if !d.IsDir() {
+					declared[filepath.ToSlash(rel)] = true
+					n++
+				}
+				return copyEntry(p, filepath.Join(w.base, rel), d)
+			})
+			if err != nil {
+				return n, nil, fmt.Errorf("copy outputs back: %w", err)
+			}
+		}
+	}
+	var undeclared []string
+	_ = filepath.WalkDir(w.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(w.dir, p)
+		if rel = filepath.ToSlash(rel); !w.copied[rel] && !declared[rel] {
+			undeclared = append(undeclared, rel)
+		}
+		return nil
+	})
+	return n, undeclared, nil
+}
+
+func (w *isolatedWorkspace) remove() {
+	_ = os.RemoveAll(w.dir)
+}
+
+// copyEntry copies one directory entry (a directory, symlink, or regular
+// file with its mode) to dst.
+func copyEntry(src, dst string, d fs.DirEntry) error {
+	switch {
+	case d.IsDir():
+		return os.MkdirAll(dst, 0o755)
+	case d.Type()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		_ = os.Remove(dst)
+		return os.Symlink(target, dst)
+	}
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// runIsolated runs a command task in an isolated workspace: its cwd and
+// dirs are mapped into the workspace, outputs are copied back on success,
+// and on failure the workspace is kept for inspection.
+func (r *taskRunner) runIsolated(name string, t cfg.Task, dirs []string, run func(t cfg.Task, dirs []string) error) error {
+	w, err := newIsolatedWorkspace(r.confPath, t)
+	if err != nil {
+		return fmt.Errorf("task %q: %w", name, err)
+	}
+	if len(dirs) == 0 {
+		cwd, err := resolveCwd(r.confPath, t.Cwd)
+		if err == nil {
+			t.Cwd, err = w.path(cwd)
+		}
+		if err != nil {
+			w.remove()
+			return fmt.Errorf("task %q: cwd: %w", name, err)
+		}
+	}
+	mapped := make([]string, len(dirs))
+	for i, d := range dirs {
+		if mapped[i], err = w.path(d); err != nil {
+			w.remove()
+			return fmt.Errorf("task %q: dirs: %w", name, err)
+		}
+	}
+	if err := run(t, mapped); err != nil {
+		statusf(os.Stderr, "🔒 %s: isolated workspace kept at %s", name, w.dir)
+		return err
+	}
+	n, undeclared, err := w.collect(t.Outputs)
+	if err != nil {
+		statusf(os.Stderr, "🔒 %s: isolated workspace kept at %s", name, w.dir)
+		return fmt.Errorf("task %q: %w", name, err)
+	}
+	w.remove()
+	statusf(os.Stderr, "🔒 %s ran isolated (%d source file(s) in, %d output file(s) back)", name, len(w.copied), n)
+	if len(undeclared) > 0 {
+		const show = 5
+		list := undeclared
+		if len(list) > show {
+			list = list[:show]
+		}
+		more := ""
+		if len(undeclared) > show {
+			more = fmt.Sprintf(" and %d more", len(undeclared)-show)
+		}
+		statusf(os.Stderr, "⚠️  %s wrote files not in outputs (discarded): %s%s", name, strings.Join(list, ", "), more)
+	}
+	return nil
+}
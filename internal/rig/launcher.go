@@ -0,0 +1,178 @@
+package rig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+const defaultReleasesURL = "https://api.github.com/repos/divijg19/rig/releases?per_page=100"
+
+// LaunchOptions configures fetching a pinned rig version into the user cache.
+type LaunchOptions struct {
+	// Constraint is the project's rig-version requirement.
+	Constraint string
+	// CacheDir overrides the version cache root (default: <user cache>/rig/versions).
+	CacheDir    string
+	GOOS        string
+	GOARCH      string
+	ReleasesURL string
+	Client      HTTPClient
+}
+
+// LaunchTarget is a cached rig binary satisfying a project's constraint.
+type LaunchTarget struct {
+	Version    string
+	Path       string
+	Downloaded bool
+}
+
+// RigVersionCacheDir returns the root directory for cached rig versions.
+func RigVersionCacheDir() (string, error) {
+	dir, err := cfg.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "versions"), nil
+}
+
+// EnsureRigVersion returns a cached rig binary for the newest release that
+// satisfies opts.Constraint, downloading and checksum-verifying it on first use.
+func EnsureRigVersion(opts LaunchOptions) (LaunchTarget, error) {
+	c, err := ParseVersionConstraint(opts.Constraint)
+	if err != nil {
+		return LaunchTarget{}, err
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	if strings.TrimSpace(opts.ReleasesURL) == "" {
+		opts.ReleasesURL = defaultReleasesURL
+	}
+	if strings.TrimSpace(opts.GOOS) == "" {
+		opts.GOOS = runtime.GOOS
+	}
+	if strings.TrimSpace(opts.GOARCH) == "" {
+		opts.GOARCH = runtime.GOARCH
+	}
+	if strings.TrimSpace(opts.CacheDir) == "" {
+		opts.CacheDir, err = RigVersionCacheDir()
+		if err != nil {
+			return LaunchTarget{}, err
+		}
+	}
+	binaryName := "rig"
+	if opts.GOOS == "windows" {
+		binaryName = "rig.exe"
+	}
+
+	// An exact pin that is already cached needs no network access.
+	if exact, ok := c.exactVersion(); ok {
+		for _, tag := range []string{"v" + exact, exact} {
+			p := filepath.Join(opts.CacheDir, tag, binaryName)
+			if ensureExecutable(p) == nil {
+				return LaunchTarget{Version: tag, Path: p}, nil
+			}
+		}
+	}
+
+	releases, err := fetchReleases(opts.Client, opts.ReleasesURL)
+	if err != nil {
+		return LaunchTarget{}, err
+	}
+	rel, ok := selectRelease(releases, c)
+	if !ok {
+		return LaunchTarget{}, fmt.Errorf("no rig release satisfies %q", opts.Constraint)
+	}
+	tag := strings.TrimSpace(rel.TagName)
+	dest := filepath.Join(opts.CacheDir, tag, binaryName)
+	if ensureExecutable(dest) == nil {
+		return LaunchTarget{Version: tag, Path: dest}, nil
+	}
+
+	assetName, checksumName, err := selectAsset(rel, opts.GOOS, opts.GOARCH)
+	if err != nil {
+		return LaunchTarget{}, fmt.Errorf("release %s: %w", tag, err)
+	}
+	assetURL, _ := findAssetURL(rel, assetName)
+	checksumURL, ok := findAssetURL(rel, checksumName)
+	if !ok {
+		return LaunchTarget{}, fmt.Errorf("release %s has no checksum %s", tag, checksumName)
+	}
+	binaryData, err := fetchReleaseBinary(opts.Client, assetURL, checksumURL, assetName, binaryName)
+	if err != nil {
+		return LaunchTarget{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return LaunchTarget{}, err
+	}
+	// replaceExecutableAtomically expects an existing destination on Windows.
+	if err := os.WriteFile(dest, nil, 0o755); err != nil {
+		return LaunchTarget{}, err
+	}
+	if err := replaceExecutableAtomically(dest, binaryData); err != nil {
+		_ = os.Remove(dest)
+		return LaunchTarget{}, err
+	}
+	return LaunchTarget{Version: tag, Path: dest, Downloaded: true}, nil
+}
+
+func (c VersionConstraint) exactVersion() (string, bool) {
+	if len(c.terms) != 1 || c.terms[0].op != "=" {
+		return "", false
+	}
+	v := c.terms[0].v
+	s := fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+	if v.pre != "" {
+		s += "-" + v.pre
+	}
+	return s, true
+}
+
+func fetchReleases(client HTTPClient, url string) ([]githubLatestRelease, error) {
+	body, err := fetchBytes(client, url)
+	if err != nil {
+		return nil, err
+	}
+	var rels []githubLatestRelease
+	if err := json.Unmarshal(body, &rels); err != nil {
+		return nil, fmt.Errorf("parse releases: %w", err)
+	}
+	if len(rels) == 0 {
+		return nil, errors.New("no releases found")
+	}
+	return rels, nil
+}
+
+// selectRelease picks the highest release allowed by c. Prereleases are only
+// considered when the constraint pins them exactly.
+func selectRelease(rels []githubLatestRelease, c VersionConstraint) (githubLatestRelease, bool) {
+	exact, isExact := c.exactVersion()
+	var best githubLatestRelease
+	var bestV semver
+	found := false
+	for _, r := range rels {
+		v, err := parseSemver(r.TagName)
+		if err != nil {
+			continue
+		}
+		if v.pre != "" && !(isExact && strings.TrimPrefix(strings.TrimSpace(r.TagName), "v") == exact) {
+			continue
+		}
+		ok, err := c.Allows(r.TagName)
+		if err != nil || !ok {
+			continue
+		}
+		if !found || compareSemver(v, bestV) > 0 {
+			best, bestV, found = r, v, true
+		}
+	}
+	return best, found
+}
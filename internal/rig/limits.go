@@ -0,0 +1,115 @@
+// internal/rig/limits.go
+
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// ResourceLimits bound a task's process tree: MaxMemory in bytes, CPUs as a
+// fraction of whole CPUs, and Nice as a scheduling priority. Zero values
+// (and a nil Nice) mean no limit.
+type ResourceLimits struct {
+	MaxMemory int64
+	CPUs      float64
+	Nice      *int
+}
+
+func (l ResourceLimits) empty() bool {
+	return l.MaxMemory == 0 && l.CPUs == 0 && l.Nice == nil
+}
+
+// String describes the limits, e.g. "max_memory=512MiB cpu_limit=1.5".
+func (l ResourceLimits) String() string {
+	var parts []string
+	if l.MaxMemory > 0 {
+		parts = append(parts, "max_memory="+formatByteSize(l.MaxMemory))
+	}
+	if l.CPUs > 0 {
+		parts = append(parts, "cpu_limit="+strconv.FormatFloat(l.CPUs, 'f', -1, 64))
+	}
+	if l.Nice != nil {
+		parts = append(parts, "nice="+strconv.Itoa(*l.Nice))
+	}
+	return strings.Join(parts, " ")
+}
+
+// TaskLimits validates and converts a task's max_memory, cpu_limit and nice.
+func TaskLimits(t cfg.Task) (ResourceLimits, error) {
+	var l ResourceLimits
+	if t.MaxMemory != "" {
+		n, err := parseByteSize(t.MaxMemory)
+		if err != nil {
+			return l, fmt.Errorf("max_memory: %w", err)
+		}
+		l.MaxMemory = n
+	}
+	if t.CPULimit < 0 || math.IsNaN(t.CPULimit) || math.IsInf(t.CPULimit, 0) {
+		return l, fmt.Errorf("cpu_limit must be a positive number of CPUs, got %v", t.CPULimit)
+	}
+	l.CPUs = t.CPULimit
+	if t.Nice != nil {
+		if *t.Nice < -20 || *t.Nice > 19 {
+			return l, fmt.Errorf("nice must be between -20 and 19, got %d", *t.Nice)
+		}
+		n := *t.Nice
+		l.Nice = &n
+	}
+	return l, nil
+}
+
+var byteUnits = []struct {
+	suffix string
+	mult   int64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30}, {"TIB", 1 << 40},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40},
+	{"B", 1},
+}
+
+// parseByteSize parses "512MiB", "2G", "1.5GB" or a plain byte count. Bare
+// K/M/G/T are binary units, matching docker and systemd.
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	num, mult := strings.ToUpper(s), int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(num, u.suffix) {
+			num, mult = strings.TrimSpace(num[:len(num)-len(u.suffix)]), u.mult
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f <= 0 || math.IsInf(f, 0) || f*float64(mult) >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 512MiB, 2G, or a byte count)", s)
+	}
+	return int64(f * float64(mult)), nil
+}
+
+func formatByteSize(n int64) string {
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if n >= u.mult && n%u.mult == 0 {
+			return strconv.FormatInt(n/u.mult, 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// errLimitsUnsupported marks limits this platform or environment cannot
+// enforce; the task then runs without them after a warning.
+var errLimitsUnsupported = errors.New("resource limits unsupported")
+
+// warnLimits reports limits that could not be applied.
+func warnLimits(l ResourceLimits, err error) {
+	statusf(os.Stderr, "⚠️  %s not enforced: %v", l, err)
+}
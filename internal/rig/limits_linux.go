@@ -0,0 +1,273 @@
+//go:build linux
+
+package rig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// cgroupRoot is where the cgroup v2 hierarchy is mounted; swappable for tests.
+var cgroupRoot = "/sys/fs/cgroup"
+
+// runLimited runs cmd in its own cgroup v2 (memory.max, cpu.max) when rig's
+// cgroup allows creating one, otherwise in a transient systemd scope. nice is
+// applied at fork so it covers the whole process tree.
+func runLimited(cmd *exec.Cmd, l ResourceLimits) error {
+	if l.MaxMemory > 0 || l.CPUs > 0 {
+		cg, err := newTaskCgroup(l)
+		switch {
+		case err == nil:
+			defer cg.remove()
+			if cmd.SysProcAttr == nil {
+				cmd.SysProcAttr = &syscall.SysProcAttr{}
+			}
+			cmd.SysProcAttr.UseCgroupFD, cmd.SysProcAttr.CgroupFD = true, cg.fd
+		case systemdScopeAvailable():
+			wrapSystemdScope(cmd, l)
+		default:
+			warnLimits(ResourceLimits{MaxMemory: l.MaxMemory, CPUs: l.CPUs}, fmt.Errorf("%w: %v (and systemd-run is unavailable)", errLimitsUnsupported, err))
+		}
+	}
+	if err := startNiced(cmd, l.Nice); err != nil {
+		return err
+	}
+	return cmd.Wait()
+}
+
+// startNiced starts cmd with the given niceness. Linux niceness is per thread
+// and inherited across fork, so it is set on a locked thread that is never
+// unlocked: the runtime discards the thread when the goroutine exits.
+func startNiced(cmd *exec.Cmd, nice *int) error {
+	if nice == nil {
+		return cmd.Start()
+	}
+	errc := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		if err := unix.Setpriority(unix.PRIO_PROCESS, unix.Gettid(), *nice); err != nil {
+			warnLimits(ResourceLimits{Nice: nice}, err)
+		}
+		errc <- cmd.Start()
+	}()
+	return <-errc
+}
+
+type taskCgroup struct {
+	dir string
+	fd  int
+}
+
+// newTaskCgroup creates a child of rig's cgroup (see taskCgroupParent) with
+// the limits set.
+// This needs a writable cgroup v2 hierarchy with the memory and cpu
+// controllers delegated, as in containers running as root or systemd
+// delegated units.
+func newTaskCgroup(l ResourceLimits) (*taskCgroup, error) {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return nil, errors.New("cgroup v2 is not mounted")
+	}
+	var want []string
+	if l.MaxMemory > 0 {
+		want = append(want, "memory")
+	}
+	if l.CPUs > 0 {
+		want = append(want, "cpu")
+	}
+	parent, err := taskCgroupParent(want)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(parent, "rig-task-")
+	if err != nil {
+		return nil, fmt.Errorf("create cgroup: %w", err)
+	}
+	cg := &taskCgroup{dir: dir, fd: -1}
+	if l.MaxMemory > 0 {
+		if err := cg.write("memory.max", strconv.FormatInt(l.MaxMemory, 10)); err != nil {
+			cg.remove()
+			return nil, err
+		}
+		_ = cg.write("memory.swap.max", "0")
+	}
+	if l.CPUs > 0 {
+		const period = 100000
+		if err := cg.write("cpu.max", fmt.Sprintf("%d %d", int64(l.CPUs*period), period)); err != nil {
+			cg.remove()
+			return nil, err
+		}
+	}
+	fd, err := unix.Open(dir, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		cg.remove()
+		return nil, fmt.Errorf("open cgroup: %w", err)
+	}
+	cg.fd = fd
+	return cg, nil
+}
+
+func (cg *taskCgroup) write(file, value string) error {
+	if err := os.WriteFile(filepath.Join(cg.dir, file), []byte(value), 0o644); err != nil {
+		return fmt.Errorf("set %s: %w", file, err)
+	}
+	return nil
+}
+
+// remove deletes the cgroup; it stays behind while background processes the
+// task left running are still in it.
+func (cg *taskCgroup) remove() {
+	if cg.fd >= 0 {
+		_ = unix.Close(cg.fd)
+	}
+	_ = os.Remove(cg.dir)
+}
+
+// ownCgroup returns rig's cgroup v2 path from /proc/self/cgroup.
+func ownCgroup() (string, error) {
+	f, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if p, ok := strings.CutPrefix(sc.Text(), "0::"); ok {
+			return p, nil
+		}
+	}
+	return "", errors.New("no cgroup v2 entry in /proc/self/cgroup")
+}
+
+var (
+	taskParentMu  sync.Mutex
+	taskParentDir string // set once rig has moved itself into a leaf
+)
+
+// taskCgroupParent returns the cgroup task cgroups are created in, with the
+// want controllers enabled for its children. That is rig's own cgroup, but
+// cgroup v2 lets only a cgroup without processes of its own enable
+// controllers for children ("no internal processes"), so when rig is alone in
+// it, rig first moves itself into a leaf child named "rig". The root cgroup
+// is exempt from the rule.
+func taskCgroupParent(want []string) (string, error) {
+	taskParentMu.Lock()
+	defer taskParentMu.Unlock()
+	if taskParentDir != "" {
+		return taskParentDir, enableControllers(taskParentDir, want)
+	}
+	self, err := ownCgroup()
+	if err != nil {
+		return "", err
+	}
+	parent := filepath.Join(cgroupRoot, self)
+	if err := enableControllers(parent, want); err == nil || self == "/" {
+		return parent, err
+	}
+	if err := moveSelfToLeaf(parent); err != nil {
+		return "", err
+	}
+	taskParentDir = parent
+	return parent, enableControllers(parent, want)
+}
+
+// moveSelfToLeaf moves rig into parent/rig, leaving parent without processes.
+// It refuses when other processes share the cgroup: moving rig alone would
+// not free it, and moving them is not rig's call.
+func moveSelfToLeaf(parent string) error {
+	b, err := os.ReadFile(filepath.Join(parent, "cgroup.procs"))
+	if err != nil {
+		return fmt.Errorf("read cgroup.procs: %w", err)
+	}
+	self := strconv.Itoa(os.Getpid())
+	for _, pid := range strings.Fields(string(b)) {
+		if pid != self {
+			return errors.New("cgroup controllers not delegated: rig's cgroup has other processes")
+		}
+	}
+	leaf := filepath.Join(parent, "rig")
+	if err := os.Mkdir(leaf, 0o755); err != nil && !os.IsExist(err) {
+		return fmt.Errorf("create cgroup: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(leaf, "cgroup.procs"), []byte(self), 0o644); err != nil {
+		return fmt.Errorf("move rig into %s: %w", leaf, err)
+	}
+	return nil
+}
+
+// enableControllers makes sure parent delegates the controllers to its
+// children.
+func enableControllers(parent string, want []string) error {
+	ctl := filepath.Join(parent, "cgroup.subtree_control")
+	has := func() []string {
+		b, _ := os.ReadFile(ctl)
+		enabled := strings.Fields(string(b))
+		var missing []string
+		for _, c := range want {
+			if !slices.Contains(enabled, c) {
+				missing = append(missing, c)
+			}
+		}
+		return missing
+	}
+	missing := has()
+	if len(missing) == 0 {
+		return nil
+	}
+	for _, c := range missing {
+		_ = os.WriteFile(ctl, []byte("+"+c), 0o644)
+	}
+	if missing = has(); len(missing) > 0 {
+		return fmt.Errorf("cgroup controllers not delegated: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+var (
+	systemdScopeOnce sync.Once
+	systemdScopeArgs []string
+)
+
+// systemdScopeAvailable reports whether `systemd-run --scope` works here
+// (as the user manager unless rig runs as root).
+func systemdScopeAvailable() bool {
+	systemdScopeOnce.Do(func() {
+		args := []string{"--scope", "--quiet", "--collect"}
+		if os.Geteuid() != 0 {
+			args = append([]string{"--user"}, args...)
+		}
+		if exec.Command("systemd-run", append(args, "true")...).Run() == nil {
+			systemdScopeArgs = args
+		}
+	})
+	return systemdScopeArgs != nil
+}
+
+// wrapSystemdScope rewrites cmd to run under systemd-run in a transient
+// scope with MemoryMax and CPUQuota set. systemd-run execs the command
+// in place, so the pid and stdio are unchanged.
+func wrapSystemdScope(cmd *exec.Cmd, l ResourceLimits) {
+	args := append([]string{"systemd-run"}, systemdScopeArgs...)
+	if l.MaxMemory > 0 {
+		args = append(args, "-p", "MemoryMax="+strconv.FormatInt(l.MaxMemory, 10), "-p", "MemorySwapMax=0")
+	}
+	if l.CPUs > 0 {
+		args = append(args, "-p", fmt.Sprintf("CPUQuota=%d%%", int64(l.CPUs*100)))
+	}
+	args = append(args, "--", cmd.Path)
+	cmd.Args = append(args, cmd.Args[1:]...)
+	if p, err := exec.LookPath("systemd-run"); err == nil {
+		cmd.Path = p
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !linux && !windows
+
+package rig
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+// runLimited applies nice to cmd once started; memory and CPU limits need
+// cgroups (Linux) or Job Objects (Windows) and are skipped with a warning.
+func runLimited(cmd *exec.Cmd, l ResourceLimits) error {
+	if l.MaxMemory > 0 || l.CPUs > 0 {
+		warnLimits(ResourceLimits{MaxMemory: l.MaxMemory, CPUs: l.CPUs}, fmt.Errorf("%w on %s", errLimitsUnsupported, runtime.GOOS))
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if l.Nice != nil {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, cmd.Process.Pid, *l.Nice); err != nil {
+			warnLimits(ResourceLimits{Nice: l.Nice}, err)
+		}
+	}
+	return cmd.Wait()
+}
@@ -0,0 +1,66 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]int64{
+		"512MiB": 512 << 20,
+		"2G":     2 << 30,
+		"1.5GB":  1500000000,
+		"64k":    64 << 10,
+		"4096":   4096,
+		"100 B":  100,
+	}
+	for in, want := range cases {
+		got, err := parseByteSize(in)
+		if err != nil || got != want {
+			t.Fatalf("parseByteSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "lots", "-1G", "0", "12PB"} {
+		if _, err := parseByteSize(in); err == nil {
+			t.Fatalf("parseByteSize(%q): expected error", in)
+		}
+	}
+	if got := formatByteSize(512 << 20); got != "512MiB" {
+		t.Fatalf("formatByteSize = %q", got)
+	}
+}
+
+func TestLoadConfig_TaskLimits(t *testing.T) {
+	cases := map[string]string{
+		`t = { command = "true", max_memory = "1GiB", cpu_limit = 1.5, nice = 10 }`:     "",
+		`t = { command = "true", max_memory = 1073741824, cpu_limit = 1.5, nice = 10 }`: "",
+		`t = { command = "true", max_memory = "a lot" }`:                                "max_memory: invalid size",
+		`t = { command = "true", cpu_limit = 0 }`:                                       "cpu_limit must be a positive",
+		`t = { command = "true", cpu_limit = "2" }`:                                     "cpu_limit must be a number",
+		`t = { command = "true", nice = 40 }`:                                           "nice must be between -20 and 19",
+		`t = { steps = ["a"], nice = 5 }`:                                               "require a command",
+	}
+	for task, want := range cases {
+		dir := t.TempDir()
+		config := "[tasks]\na = \"true\"\n" + task + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "rig.toml"), []byte(config), 0o644); err != nil {
+			t.Fatalf("write rig.toml: %v", err)
+		}
+		conf, _, err := LoadConfig(dir)
+		if want == "" {
+			if err != nil {
+				t.Fatalf("%s: LoadConfig: %v", task, err)
+			}
+			l, err := TaskLimits(conf.Tasks["t"])
+			if err != nil || l.String() != "max_memory=1GiB cpu_limit=1.5 nice=10" {
+				t.Fatalf("%s: limits = %q, %v", task, l, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected error containing %q, got %v", task, want, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+//go:build windows
+
+package rig
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// jobObjectCPURateControl mirrors JOBOBJECT_CPU_RATE_CONTROL_INFORMATION with
+// CpuRate in hundredths of a percent of the whole machine.
+type jobObjectCPURateControl struct {
+	ControlFlags uint32
+	CPURate      uint32
+}
+
+const (
+	jobObjectCPURateControlEnable  = 0x1
+	jobObjectCPURateControlHardCap = 0x4
+)
+
+// runLimited runs cmd in a Job Object carrying the memory and CPU limits;
+// processes the task spawns join the job too. nice maps to a priority class.
+func runLimited(cmd *exec.Cmd, l ResourceLimits) error {
+	job, err := newTaskJob(l)
+	if err != nil {
+		warnLimits(ResourceLimits{MaxMemory: l.MaxMemory, CPUs: l.CPUs}, err)
+	} else {
+		defer windows.CloseHandle(job)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if job != 0 || l.Nice != nil {
+		if err := limitProcess(cmd.Process.Pid, job, l.Nice); err != nil {
+			warnLimits(l, err)
+		}
+	}
+	return cmd.Wait()
+}
+
+func newTaskJob(l ResourceLimits) (windows.Handle, error) {
+	if l.MaxMemory == 0 && l.CPUs == 0 {
+		return 0, nil
+	}
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("create job object: %w", err)
+	}
+	if l.MaxMemory > 0 {
+		info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{JobMemoryLimit: uintptr(l.MaxMemory)}
+		info.BasicLimitInformation.LimitFlags = windows.JOB_OBJECT_LIMIT_JOB_MEMORY
+		if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation, uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+			windows.CloseHandle(job)
+			return 0, fmt.Errorf("set job memory limit: %w", err)
+		}
+	}
+	if l.CPUs > 0 {
+		rate := uint32(min(l.CPUs/float64(runtime.NumCPU()), 1) * 10000)
+		info := jobObjectCPURateControl{ControlFlags: jobObjectCPURateControlEnable | jobObjectCPURateControlHardCap, CPURate: max(rate, 1)}
+		if _, err := windows.SetInformationJobObject(job, windows.JobObjectCpuRateControlInformation, uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+			windows.CloseHandle(job)
+			return 0, fmt.Errorf("set job CPU rate: %w", err)
+		}
+	}
+	return job, nil
+}
+
+// limitProcess assigns the started process to job and sets its priority.
+func limitProcess(pid int, job windows.Handle, nice *int) error {
+	h, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE|windows.PROCESS_SET_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(h)
+	if job != 0 {
+		if err := windows.AssignProcessToJobObject(job, h); err != nil {
+			return fmt.Errorf("assign job object: %w", err)
+		}
+	}
+	if nice != nil {
+		if err := windows.SetPriorityClass(h, priorityClass(*nice)); err != nil {
+			return fmt.Errorf("set priority class: %w", err)
+		}
+	}
+	return nil
+}
+
+// priorityClass maps a Unix nice value to the nearest Windows priority class.
+func priorityClass(nice int) uint32 {
+	switch {
+	case nice >= 15:
+		return windows.IDLE_PRIORITY_CLASS
+	case nice > 0:
+		return windows.BELOW_NORMAL_PRIORITY_CLASS
+	case nice <= -15:
+		return windows.HIGH_PRIORITY_CLASS
+	case nice < 0:
+		return windows.ABOVE_NORMAL_PRIORITY_CLASS
+	default:
+		return windows.NORMAL_PRIORITY_CLASS
+	}
+}
@@ -2,13 +2,18 @@ package rig
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	cfg "github.com/divijg19/rig/internal/config"
 	"github.com/pelletier/go-toml/v2"
 )
 
@@ -21,7 +26,7 @@ const LockSchema0 = 0
 // - tools are sorted lexicographically by Requested
 // - fields are written in a fixed order
 // - module and url are mutually exclusive
-// - checksum is optional (go module checksum, not used for tool verification)
+// - checksum is the module h1: sum, verified against the checksum database by sync
 // - sha256 is required (binary integrity for .rig/bin)
 //
 // Notes:
@@ -41,6 +46,7 @@ const LockSchema0 = 0
 //	bin = "golangci-lint"
 //	checksum = "h1:..." # optional
 //	sha256 = "..."      # required
+//	go = "1.22.0"       # optional: Go version that built the binary
 //
 // (No comments are generated in the lock file.)
 type LockedTool struct {
@@ -53,6 +59,7 @@ type LockedTool struct {
 	URL      string `toml:"url,omitempty"`
 	Checksum string `toml:"checksum,omitempty"`
 	SHA256   string `toml:"sha256,omitempty"`
+	Go       string `toml:"go,omitempty"`
 }
 
 // GoToolchainLock captures the Go toolchain requirement for this repo.
@@ -73,10 +80,96 @@ type ToolchainLock struct {
 	Go *GoToolchainLock `toml:"go,omitempty"`
 }
 
+// LockMeta records how rig.lock was generated: the rig version, when (UTC,
+// whole seconds; SOURCE_DATE_EPOCH when set), and the sha256 of the rig.toml
+// inputs it was resolved from (see ManifestHash), so `rig check` notices a
+// rig.toml edit the lock predates even when the pinned tools still happen to
+// match.
+//
+//	[meta]
+//	rig_version = "v0.9.0"
+//	generated = "2026-10-16T09:30:00Z"
+//	manifest_sha256 = "..."
+type LockMeta struct {
+	RigVersion     string `toml:"rig_version" json:"rigVersion"`
+	Generated      string `toml:"generated" json:"generated"`
+	ManifestSHA256 string `toml:"manifest_sha256" json:"manifestSha256"`
+}
+
+// NewLockMeta stamps a lock about to be written by rigVersion from conf.
+func NewLockMeta(rigVersion string, conf *cfg.Config) *LockMeta {
+	now := time.Now()
+	if v := strings.TrimSpace(os.Getenv("SOURCE_DATE_EPOCH")); v != "" {
+		if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+			now = time.Unix(sec, 0)
+		}
+	}
+	return &LockMeta{
+		RigVersion:     rigVersion,
+		Generated:      now.UTC().Format(time.RFC3339),
+		ManifestSHA256: ManifestHash(conf),
+	}
+}
+
+// ManifestHash hashes the rig.toml tables rig.lock is resolved from, [tools]
+// (with the go toolchain pin) and [tool-aliases], independent of key order.
+// Keys and values are quoted, so no two distinct tables hash alike.
+func ManifestHash(conf *cfg.Config) string {
+	h := sha256.New()
+	for _, k := range sortedKeys(conf.Tools) {
+		fmt.Fprintf(h, "tool %q %q\n", k, conf.Tools[k])
+	}
+	aliases := make([]string, 0, len(conf.ToolAliases))
+	for k := range conf.ToolAliases {
+		aliases = append(aliases, k)
+	}
+	sort.Strings(aliases)
+	for _, k := range aliases {
+		a := conf.ToolAliases[k]
+		fmt.Fprintf(h, "alias %q %q %q %q %q\n", k, a.Module, a.Install, a.Bin, a.OCI)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LockMatchesManifest fails when rig.lock was generated from different
+// rig.toml inputs than conf's. A lock without [meta] passes.
+func LockMatchesManifest(lock Lockfile, conf *cfg.Config) error {
+	m := lock.Meta
+	if m == nil || strings.TrimSpace(m.ManifestSHA256) == "" || m.ManifestSHA256 == ManifestHash(conf) {
+		return nil
+	}
+	return WithCode(CodeLockOutOfDate, fmt.Errorf("rig.lock predates the current [tools] or [tool-aliases] in rig.toml (generated %s by rig %s); run 'rig sync'", m.Generated, m.RigVersion))
+}
+
+// keepGenerated returns l with prev's [meta] generated time when that is
+// all that differs, so re-syncing an unchanged project leaves rig.lock as is.
+func keepGenerated(prev, l Lockfile) Lockfile {
+	if l.Meta == nil || prev.Meta == nil {
+		return l
+	}
+	meta := *l.Meta
+	meta.Generated = prev.Meta.Generated
+	same := l
+	same.Meta = &meta
+	a, err1 := MarshalLockfile(prev)
+	b, err2 := MarshalLockfile(same)
+	if err1 == nil && err2 == nil && bytes.Equal(a, b) {
+		return same
+	}
+	return l
+}
+
+// Lockfile is rig.lock. Includes pins remote includes by content hash:
+//
+//	[[includes]]
+//	source = "github.com/acme/rig-presets//go-service.toml@v1"
+//	sha256 = "..."
 type Lockfile struct {
-	Schema    int            `toml:"schema"`
-	Toolchain *ToolchainLock `toml:"toolchain,omitempty"`
-	Tools     []LockedTool   `toml:"tools"`
+	Schema    int              `toml:"schema"`
+	Meta      *LockMeta        `toml:"meta,omitempty"`
+	Toolchain *ToolchainLock   `toml:"toolchain,omitempty"`
+	Includes  []cfg.IncludePin `toml:"includes,omitempty"`
+	Tools     []LockedTool     `toml:"tools"`
 }
 
 func (t LockedTool) validate() error {
@@ -117,6 +210,11 @@ func ValidateLockfile(l Lockfile) error {
 			return errors.New("toolchain.go.detected is required")
 		}
 	}
+	for i, inc := range l.Includes {
+		if strings.TrimSpace(inc.Source) == "" || strings.TrimSpace(inc.SHA256) == "" {
+			return fmt.Errorf("includes[%d]: source and sha256 are required", i)
+		}
+	}
 	for i, t := range l.Tools {
 		if err := t.validate(); err != nil {
 			return fmt.Errorf("tools[%d]: %w", i, err)
@@ -156,6 +254,14 @@ func MarshalLockfile(l Lockfile) ([]byte, error) {
 	var buf bytes.Buffer
 	buf.WriteString("schema = 0\n")
 
+	if l.Meta != nil {
+		buf.WriteString("\n")
+		buf.WriteString("[meta]\n")
+		writeTOMLKV(&buf, "rig_version", l.Meta.RigVersion)
+		writeTOMLKV(&buf, "generated", l.Meta.Generated)
+		writeTOMLKV(&buf, "manifest_sha256", l.Meta.ManifestSHA256)
+	}
+
 	if l.Toolchain != nil && l.Toolchain.Go != nil {
 		buf.WriteString("\n")
 		buf.WriteString("[toolchain.go]\n")
@@ -164,6 +270,15 @@ func MarshalLockfile(l Lockfile) ([]byte, error) {
 		writeTOMLKV(&buf, "detected", l.Toolchain.Go.Detected)
 	}
 
+	includes := append([]cfg.IncludePin(nil), l.Includes...)
+	sort.Slice(includes, func(i, j int) bool { return includes[i].Source < includes[j].Source })
+	for _, inc := range includes {
+		buf.WriteString("\n")
+		buf.WriteString("[[includes]]\n")
+		writeTOMLKV(&buf, "source", inc.Source)
+		writeTOMLKV(&buf, "sha256", inc.SHA256)
+	}
+
 	if len(tools) > 0 {
 		buf.WriteString("\n")
 	}
@@ -187,6 +302,9 @@ func MarshalLockfile(l Lockfile) ([]byte, error) {
 		if t.SHA256 != "" {
 			writeTOMLKV(&buf, "sha256", t.SHA256)
 		}
+		if t.Go != "" {
+			writeTOMLKV(&buf, "go", t.Go)
+		}
 		if i != len(tools)-1 {
 			buf.WriteString("\n")
 		}
@@ -217,9 +335,44 @@ func tomlQuote(s string) string {
 	return "\"" + repl.Replace(s) + "\""
 }
 
+// LockMatchesIncludes verifies that rig.lock pins exactly the remote includes
+// the config loaded, with the same content hashes.
+func LockMatchesIncludes(lock Lockfile, loaded []cfg.IncludePin) error {
+	return WithCode(CodeIncludeMismatch, lockMatchesIncludes(lock, loaded))
+}
+
+func lockMatchesIncludes(lock Lockfile, loaded []cfg.IncludePin) error {
+	pinned := make(map[string]string, len(lock.Includes))
+	for _, p := range lock.Includes {
+		pinned[p.Source] = p.SHA256
+	}
+	seen := map[string]struct{}{}
+	for _, inc := range loaded {
+		seen[inc.Source] = struct{}{}
+		sum, ok := pinned[inc.Source]
+		if !ok {
+			return fmt.Errorf("rig.lock does not pin remote include %q (run 'rig sync')", inc.Source)
+		}
+		if sum != inc.SHA256 {
+			return fmt.Errorf("remote include %q sha256 mismatch: rig.lock has %s, loaded %s", inc.Source, sum, inc.SHA256)
+		}
+	}
+	for _, p := range lock.Includes {
+		if _, ok := seen[p.Source]; !ok {
+			return fmt.Errorf("rig.lock pins remote include %q not present in rig.toml", p.Source)
+		}
+	}
+	return nil
+}
+
 // WriteLockfile writes rig.lock to path by fully overwriting it.
 // The write is atomic (write to a temp file in the same directory then rename).
+// When only [meta].generated would change, the existing timestamp is kept so
+// re-syncing an unchanged project leaves rig.lock byte-for-byte the same.
 func WriteLockfile(path string, l Lockfile) error {
+	if prev, err := ReadLockfile(path); err == nil {
+		l = keepGenerated(prev, l)
+	}
 	b, err := MarshalLockfile(l)
 	if err != nil {
 		return err
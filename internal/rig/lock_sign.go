@@ -0,0 +1,162 @@
+package rig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// Supported rig.lock signers.
+const (
+	SignerMinisign = "minisign"
+	SignerCosign   = "cosign"
+)
+
+// LockSignaturePath returns rig.lock.sig next to rig.toml.
+func LockSignaturePath(configPath string) string {
+	return rigLockPathForConfig(configPath) + ".sig"
+}
+
+func validSigner(signer string) error {
+	switch signer {
+	case SignerMinisign, SignerCosign:
+		return nil
+	}
+	return fmt.Errorf("unsupported signer %q (expected %s or %s)", signer, SignerMinisign, SignerCosign)
+}
+
+// SignLockfile writes a detached signature for rig.lock to rig.lock.sig using
+// signer and the private key at key. For minisign an empty key selects
+// minisign's default key; the signer may prompt for a passphrase.
+func SignLockfile(configPath, signer, key string) (string, error) {
+	if err := validSigner(signer); err != nil {
+		return "", err
+	}
+	lockPath := rigLockPathForConfig(configPath)
+	if _, err := ReadLockfile(lockPath); err != nil {
+		return "", fmt.Errorf("rig.lock: %w", err)
+	}
+	sigPath := LockSignaturePath(configPath)
+	var args []string
+	switch signer {
+	case SignerMinisign:
+		args = []string{"-S", "-m", lockPath, "-x", sigPath}
+		if key != "" {
+			args = append(args, "-s", key)
+		}
+	case SignerCosign:
+		if key == "" {
+			return "", errors.New("cosign signing needs --key")
+		}
+		args = []string{"sign-blob", "--yes", "--tlog-upload=false", "--key", key, "--output-signature", sigPath, lockPath}
+	}
+	if _, err := runSigner(signer, args, true); err != nil {
+		return "", fmt.Errorf("%s sign failed: %w", signer, err)
+	}
+	return sigPath, nil
+}
+
+// UserLock is the [lock] table from the user config. The CLI sets it once
+// the user config is loaded, with public_key already resolved against that
+// file; a config that fails to load leaves it empty.
+var UserLock cfg.UserLock
+
+// VerifyLockSignature enforces the project's [lock] policy, with the user's
+// trust settings on top (see EffectiveLockPolicy): when a signer is
+// configured, rig.lock.sig must exist and verify against the public key.
+func VerifyLockSignature(configPath string, policy cfg.LockPolicy) error {
+	policy, err := EffectiveLockPolicy(policy)
+	if err != nil {
+		return WithCode(CodeLockSignature, err)
+	}
+	return WithCode(CodeLockSignature, verifyLockSignature(configPath, policy))
+}
+
+// EffectiveLockPolicy layers RIG_LOCK_SIGNATURE and RIG_LOCK_PUBLIC_KEY, else
+// UserLock, over the project's [lock]. rig.toml travels with rig.lock, so
+// whoever can rewrite the lock can also drop [lock] or swap its key; only a
+// signer and key pinned outside the repository hold against that. A pinned
+// signer requires a signature even without [lock], and a pinned key replaces
+// [lock].public_key.
+func EffectiveLockPolicy(project cfg.LockPolicy) (cfg.LockPolicy, error) {
+	policy := project
+	if signer := firstNonEmptyString(os.Getenv("RIG_LOCK_SIGNATURE"), UserLock.Signature); signer != "" {
+		policy.Signature = signer
+	}
+	key := strings.TrimSpace(os.Getenv("RIG_LOCK_PUBLIC_KEY"))
+	if key != "" {
+		var err error
+		if key, err = filepath.Abs(key); err != nil {
+			return cfg.LockPolicy{}, err
+		}
+	} else {
+		key = strings.TrimSpace(UserLock.PublicKey)
+	}
+	if key != "" {
+		if strings.TrimSpace(policy.Signature) == "" {
+			return cfg.LockPolicy{}, errors.New("a trusted rig.lock public key is pinned (RIG_LOCK_PUBLIC_KEY or lock.public_key in the user config) but no signer is set: set RIG_LOCK_SIGNATURE, lock.signature, or [lock].signature")
+		}
+		policy.PublicKey = key
+	}
+	return policy, nil
+}
+
+func verifyLockSignature(configPath string, policy cfg.LockPolicy) error {
+	signer := strings.TrimSpace(policy.Signature)
+	if signer == "" {
+		return nil
+	}
+	if err := validSigner(signer); err != nil {
+		return fmt.Errorf("[lock].signature: %w", err)
+	}
+	pub := strings.TrimSpace(policy.PublicKey)
+	if pub == "" {
+		return errors.New("[lock].public_key is required when [lock].signature is set")
+	}
+	if !filepath.IsAbs(pub) {
+		pub = filepath.Join(filepath.Dir(configPath), pub)
+	}
+	lockPath := rigLockPathForConfig(configPath)
+	sigPath := LockSignaturePath(configPath)
+	if _, err := os.Stat(sigPath); err != nil {
+		return fmt.Errorf("rig.lock signature required but %s is missing (run 'rig lock sign')", filepath.Base(sigPath))
+	}
+	var args []string
+	switch signer {
+	case SignerMinisign:
+		args = []string{"-V", "-q", "-p", pub, "-m", lockPath, "-x", sigPath}
+	case SignerCosign:
+		args = []string{"verify-blob", "--insecure-ignore-tlog=true", "--key", pub, "--signature", sigPath, lockPath}
+	}
+	if out, err := runSigner(signer, args, false); err != nil {
+		if out != "" {
+			return fmt.Errorf("rig.lock signature verification failed (%s): %s", signer, out)
+		}
+		return fmt.Errorf("rig.lock signature verification failed (%s): %w", signer, err)
+	}
+	return nil
+}
+
+// runSigner executes the signer binary. Interactive runs inherit the terminal
+// so the signer can prompt for a passphrase; otherwise output is captured.
+var runSigner = func(name string, args []string, interactive bool) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s not found on PATH", name)
+	}
+	cmd := exec.Command(name, args...)
+	if interactive {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return "", cmd.Run()
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return strings.TrimSpace(out.String()), err
+}
@@ -0,0 +1,120 @@
+package rig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+func TestLockSignatureSignAndVerify(t *testing.T) {
+	dir := setupToolsFixture(t)
+	configPath := filepath.Join(dir, "rig.toml")
+	policy := cfg.LockPolicy{Signature: SignerMinisign, PublicKey: "keys/rig.pub"}
+
+	var calls [][]string
+	valid := true
+	orig := runSigner
+	t.Cleanup(func() { runSigner = orig })
+	runSigner = func(name string, args []string, interactive bool) (string, error) {
+		calls = append(calls, append([]string{name}, args...))
+		if args[0] == "-S" {
+			writeTestFile(t, LockSignaturePath(configPath), "sig", 0o644)
+			return "", nil
+		}
+		if !valid {
+			return "Signature verification failed", errors.New("exit status 1")
+		}
+		return "", nil
+	}
+
+	if err := VerifyLockSignature(configPath, cfg.LockPolicy{}); err != nil {
+		t.Fatalf("no policy should not require a signature: %v", err)
+	}
+	if err := VerifyLockSignature(configPath, policy); err == nil || !strings.Contains(err.Error(), "rig lock sign") {
+		t.Fatalf("expected missing signature error, got %v", err)
+	}
+
+	sigPath, err := SignLockfile(configPath, SignerMinisign, "")
+	if err != nil {
+		t.Fatalf("SignLockfile: %v", err)
+	}
+	if sigPath != filepath.Join(dir, "rig.lock.sig") {
+		t.Fatalf("unexpected signature path %s", sigPath)
+	}
+	if err := VerifyLockSignature(configPath, policy); err != nil {
+		t.Fatalf("VerifyLockSignature: %v", err)
+	}
+	last := strings.Join(calls[len(calls)-1], " ")
+	if !strings.Contains(last, "-p "+filepath.Join(dir, "keys", "rig.pub")) {
+		t.Fatalf("public key not resolved relative to rig.toml: %s", last)
+	}
+
+	valid = false
+	if err := VerifyLockSignature(configPath, policy); err == nil || !strings.Contains(err.Error(), "Signature verification failed") {
+		t.Fatalf("expected verification failure, got %v", err)
+	}
+
+	if _, err := SignLockfile(configPath, SignerCosign, ""); err == nil {
+		t.Fatalf("expected cosign without key to fail")
+	}
+	if err := VerifyLockSignature(configPath, cfg.LockPolicy{Signature: "gpg", PublicKey: "k"}); err == nil {
+		t.Fatalf("expected unsupported signer error")
+	}
+
+	// A signer and key pinned outside the repository win over rig.toml.
+	valid = true
+	t.Setenv("RIG_LOCK_PUBLIC_KEY", filepath.Join(dir, "trusted.pub"))
+	if err := VerifyLockSignature(configPath, cfg.LockPolicy{}); err == nil || !strings.Contains(err.Error(), "no signer is set") {
+		t.Fatalf("expected a pinned key without a signer to fail, got %v", err)
+	}
+	t.Setenv("RIG_LOCK_SIGNATURE", SignerMinisign)
+	if err := VerifyLockSignature(configPath, cfg.LockPolicy{}); err != nil {
+		t.Fatalf("VerifyLockSignature with pinned policy: %v", err)
+	}
+	if last := strings.Join(calls[len(calls)-1], " "); !strings.Contains(last, "-p "+filepath.Join(dir, "trusted.pub")) {
+		t.Fatalf("pinned key not used: %s", last)
+	}
+	if err := VerifyLockSignature(configPath, policy); err != nil {
+		t.Fatalf("VerifyLockSignature: %v", err)
+	}
+	if last := strings.Join(calls[len(calls)-1], " "); !strings.Contains(last, "-p "+filepath.Join(dir, "trusted.pub")) {
+		t.Fatalf("[lock].public_key should not replace the pinned key: %s", last)
+	}
+	t.Setenv("RIG_LOCK_PUBLIC_KEY", "")
+	t.Setenv("RIG_LOCK_SIGNATURE", "")
+	UserLock = cfg.UserLock{Signature: SignerMinisign, PublicKey: filepath.Join(dir, "user.pub")}
+	t.Cleanup(func() { UserLock = cfg.UserLock{} })
+	if err := os.Remove(LockSignaturePath(configPath)); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyLockSignature(configPath, cfg.LockPolicy{}); err == nil || !strings.Contains(err.Error(), "rig lock sign") {
+		t.Fatalf("the user config should require a signature without [lock], got %v", err)
+	}
+	if _, err := SignLockfile(configPath, SignerMinisign, ""); err != nil {
+		t.Fatalf("SignLockfile: %v", err)
+	}
+	if err := VerifyLockSignature(configPath, cfg.LockPolicy{}); err != nil {
+		t.Fatalf("VerifyLockSignature with user policy: %v", err)
+	}
+	if last := strings.Join(calls[len(calls)-1], " "); !strings.Contains(last, "-p "+filepath.Join(dir, "user.pub")) {
+		t.Fatalf("user key not used: %s", last)
+	}
+	UserLock = cfg.UserLock{}
+
+	// check refuses an unsigned lock once [lock] requires a signature.
+	fresh := setupToolsFixture(t)
+	cp := filepath.Join(fresh, "rig.toml")
+	b, _ := os.ReadFile(cp)
+	writeTestFile(t, cp, string(b)+"\n[lock]\nsignature = \"minisign\"\npublic_key = \"rig.pub\"\n", 0o644)
+	rep, err := Check(fresh)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if rep.OK || !strings.Contains(rep.Error, "rig.lock.sig is missing") {
+		t.Fatalf("expected unsigned lock to fail check, got %+v", rep)
+	}
+}
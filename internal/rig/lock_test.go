@@ -4,6 +4,8 @@ import (
 	"os"
 	"strings"
 	"testing"
+
+	cfg "github.com/divijg19/rig/internal/config"
 )
 
 func TestMarshalLockfileDeterministicOrderingAndFields(t *testing.T) {
@@ -117,3 +119,83 @@ func TestReadLockfileRoundTrip(t *testing.T) {
 		t.Fatalf("unexpected parsed lock: %#v", parsed)
 	}
 }
+
+func TestLockfileIncludePins(t *testing.T) {
+	src := "github.com/acme/rig-presets//go-service.toml@v1"
+	l := Lockfile{Schema: LockSchema0, Includes: []cfg.IncludePin{{Source: src, SHA256: "abc"}}}
+	b, err := MarshalLockfile(l)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := "schema = 0\n\n[[includes]]\nsource = \"" + src + "\"\nsha256 = \"abc\"\n"
+	if string(b) != want {
+		t.Fatalf("unexpected lock:\n%s", b)
+	}
+	p := t.TempDir() + "/rig.lock"
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ReadLockfile(p)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if err := LockMatchesIncludes(parsed, []cfg.IncludePin{{Source: src, SHA256: "abc"}}); err != nil {
+		t.Fatalf("LockMatchesIncludes: %v", err)
+	}
+	if err := LockMatchesIncludes(parsed, nil); err == nil {
+		t.Fatalf("expected error for stale pin")
+	}
+	if err := LockMatchesIncludes(Lockfile{}, parsed.Includes); err == nil || !strings.Contains(err.Error(), "does not pin") {
+		t.Fatalf("expected unpinned include error, got %v", err)
+	}
+}
+
+func TestLockfileMeta(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	conf := &cfg.Config{Tools: map[string]string{"mockery": "2.46"}}
+	l := Lockfile{Schema: LockSchema0, Meta: NewLockMeta("v1.2.3", conf)}
+	b, err := MarshalLockfile(l)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := "schema = 0\n\n[meta]\nrig_version = \"v1.2.3\"\ngenerated = \"2023-11-14T22:13:20Z\"\nmanifest_sha256 = \"" + ManifestHash(conf) + "\"\n"
+	if string(b) != want {
+		t.Fatalf("unexpected lock:\n%s", b)
+	}
+
+	// A rewrite that only moves the timestamp keeps the file as it was.
+	p := t.TempDir() + "/rig.lock"
+	if err := WriteLockfile(p, l); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SOURCE_DATE_EPOCH", "1800000000")
+	if err := WriteLockfile(p, Lockfile{Schema: LockSchema0, Meta: NewLockMeta("v1.2.3", conf)}); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ReadLockfile(p)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if parsed.Meta == nil || parsed.Meta.Generated != "2023-11-14T22:13:20Z" {
+		t.Fatalf("expected the original timestamp, got %#v", parsed.Meta)
+	}
+
+	if err := LockMatchesManifest(parsed, conf); err != nil {
+		t.Fatalf("LockMatchesManifest: %v", err)
+	}
+	// 2.46 and 2.46.0 pin the same version, but rig.toml was edited.
+	err = LockMatchesManifest(parsed, &cfg.Config{Tools: map[string]string{"mockery": "2.46.0"}})
+	if err == nil || !strings.Contains(err.Error(), "rig.lock predates the current [tools] or [tool-aliases] in rig.toml (generated 2023-11-14T22:13:20Z by rig v1.2.3)") || CodeOf(err) != CodeLockOutOfDate {
+		t.Fatalf("expected a stale manifest error, got %v", err)
+	}
+	aliased := &cfg.Config{Tools: conf.Tools, ToolAliases: map[string]cfg.ToolAlias{"mockery": {Module: "example.com/mockery"}}}
+	if err := LockMatchesManifest(parsed, aliased); err == nil {
+		t.Fatal("a new [tool-aliases] entry should make the lock stale")
+	}
+	if err := LockMatchesManifest(Lockfile{Schema: LockSchema0}, conf); err != nil {
+		t.Fatalf("a lock without [meta] should pass, got %v", err)
+	}
+	if ManifestHash(&cfg.Config{Tools: map[string]string{"a=b": "c"}}) == ManifestHash(&cfg.Config{Tools: map[string]string{"a": "b=c"}}) {
+		t.Fatal("ManifestHash should not confuse a key containing = with its value")
+	}
+}
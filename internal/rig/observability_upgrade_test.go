@@ -146,6 +146,41 @@ func TestToolsDoctorShaMismatch(t *testing.T) {
 	}
 }
 
+func TestToolsDoctorRemedies(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("execute bits")
+	}
+	dir := setupToolsFixture(t)
+	binDir := filepath.Join(dir, ".rig", "bin")
+	if err := os.Chmod(filepath.Join(binDir, "mockery"), 0o644); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	writeTestFile(t, filepath.Join(binDir, "golangci-lint"), "#!/bin/sh\necho changed\n", 0o755)
+	writeTestFile(t, filepath.Join(binDir, "old-tool"), "#!/bin/sh\n", 0o755)
+
+	reports, err := ToolsDoctor(dir, "")
+	if err != nil {
+		t.Fatalf("ToolsDoctor: %v", err)
+	}
+	got := map[string]string{}
+	for _, r := range reports {
+		got[r.Name] = string(r.Status) + ": " + r.Remedy
+	}
+	want := map[string]string{
+		"golangci-lint": "mismatch: rig sync",
+		"mockery":       "missing: chmod +x " + filepath.Join(binDir, "mockery"),
+		"old-tool":      "extra: rig tools prune --yes",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("reports = %v, want %v", got, want)
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Fatalf("%s: got %q, want %q", name, got[name], w)
+		}
+	}
+}
+
 func TestDoctorNoLock(t *testing.T) {
 	dir := t.TempDir()
 	writeTestFile(t, filepath.Join(dir, "rig.toml"), "[project]\nname='x'\nversion='0.0.0'\n", 0o644)
@@ -292,8 +327,12 @@ func TestUpgradeMissingAsset(t *testing.T) {
 }
 
 func TestUpgradeHappyPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
 	assetName := "rig_linux_amd64.tar.gz"
-	asset := makeTarGzWithSingle("rig", []byte("newbin"))
+	newBin := "#!/bin/sh\necho 'rig 0.5.0'\n"
+	asset := makeTarGzWithSingle("rig", []byte(newBin))
 	sum := checksumLine(assetName, asset)
 
 	var baseURL string
@@ -327,7 +366,89 @@ func TestUpgradeHappyPath(t *testing.T) {
 	if err != nil {
 		t.Fatalf("read exe: %v", err)
 	}
-	if string(b) != "newbin" {
+	if res.Verified != "0.5.0" {
+		t.Fatalf("Verified=%q, want 0.5.0", res.Verified)
+	}
+	if string(b) != newBin {
 		t.Fatalf("unexpected upgraded content: %q", string(b))
 	}
 }
+
+func TestUpgradeRollsBackWhenVerificationFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	assetName := "rig_linux_amd64.tar.gz"
+	asset := makeTarGzWithSingle("rig", []byte("#!/bin/sh\necho 'rig 0.4.9'\n"))
+	sum := checksumLine(assetName, asset)
+
+	var baseURL string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			_, _ = w.Write([]byte(`{"tag_name":"v0.5.0","assets":[{"name":"` + assetName + `","browser_download_url":"` + baseURL + `/asset"},{"name":"` + assetName + `.sha256","browser_download_url":"` + baseURL + `/sum"}]}`))
+		case "/asset":
+			_, _ = w.Write(asset)
+		case "/sum":
+			_, _ = w.Write([]byte(sum))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	baseURL = ts.URL
+	defer ts.Close()
+
+	exeDir := t.TempDir()
+	exe := filepath.Join(exeDir, "rig")
+	writeTestFile(t, exe, "old", 0o755)
+
+	_, err := UpgradeSelf(UpgradeOptions{CurrentVersion: "v0.4.0", ExecutablePath: exe, LatestURL: ts.URL + "/latest", GOOS: "linux", GOARCH: "amd64"})
+	if err == nil || !strings.Contains(err.Error(), "restored previous binary") || !strings.Contains(err.Error(), `"0.4.9"`) {
+		t.Fatalf("expected verification failure with rollback, got: %v", err)
+	}
+	b, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatalf("read exe: %v", err)
+	}
+	if string(b) != "old" {
+		t.Fatalf("expected previous binary restored, got %q", string(b))
+	}
+}
+
+func TestAssetCandidates(t *testing.T) {
+	cases := []struct {
+		goos, goarch, goarm string
+		musl                bool
+		want                string
+	}{
+		{"linux", "amd64", "", false, "rig_linux_amd64.tar.gz"},
+		{"linux", "arm64", "", true, "rig_linux_arm64_musl.tar.gz,rig_linux_arm64.tar.gz"},
+		{"linux", "arm", "", false, "rig_linux_armv7.tar.gz,rig_linux_armv6.tar.gz,rig_linux_arm.tar.gz"},
+		{"linux", "arm", "6", false, "rig_linux_armv6.tar.gz,rig_linux_arm.tar.gz"},
+		{"freebsd", "amd64", "", false, "rig_freebsd_amd64.tar.gz"},
+		{"windows", "arm64", "", false, "rig_windows_arm64.zip"},
+	}
+	for _, tc := range cases {
+		got := strings.Join(assetCandidates(tc.goos, tc.goarch, tc.musl, tc.goarm), ",")
+		if got != tc.want {
+			t.Errorf("assetCandidates(%s/%s musl=%v goarm=%q)=%s, want %s", tc.goos, tc.goarch, tc.musl, tc.goarm, got, tc.want)
+		}
+	}
+}
+
+func TestUpgradeMissingAssetListsAvailable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name":"v0.5.0","assets":[{"name":"rig_linux_amd64.tar.gz"},{"name":"rig_linux_amd64.tar.gz.sha256"},{"name":"rig_darwin_arm64.tar.gz"}]}`))
+	}))
+	defer ts.Close()
+
+	exeDir := t.TempDir()
+	exe := filepath.Join(exeDir, "rig")
+	writeTestFile(t, exe, "old", 0o755)
+
+	_, err := UpgradeSelf(UpgradeOptions{CurrentVersion: "v0.4.0", ExecutablePath: exe, LatestURL: ts.URL, GOOS: "plan9", GOARCH: "amd64"})
+	want := "release asset not found for plan9/amd64 (tried rig_plan9_amd64.tar.gz); available: rig_linux_amd64.tar.gz, rig_darwin_arm64.tar.gz"
+	if err == nil || err.Error() != want {
+		t.Fatalf("expected %q, got: %v", want, err)
+	}
+}
@@ -0,0 +1,468 @@
+package rig
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OCIToolKind is the rig.lock kind of a tool binary pulled from an OCI
+// registry (pushed, for example, with `oras push`).
+//
+// rig.lock layout:
+//
+//	[[tools]]
+//	kind = "oci-binary"
+//	requested = "mytool@1.4.0"
+//	resolved = "ghcr.io/acme/mytool@sha256:…"   (manifest or index digest)
+//	url = "oci://ghcr.io/acme/mytool"
+//	bin = "mytool"
+const OCIToolKind = "oci-binary"
+
+// ociURLPrefix marks an OCI repository in [tool-aliases] oci and rig.lock url.
+const ociURLPrefix = "oci://"
+
+// IsOCITool reports whether lt is pulled from an OCI registry instead of
+// built with go install.
+func IsOCITool(lt LockedTool) bool {
+	return strings.TrimSpace(lt.Kind) == OCIToolKind
+}
+
+// ociMaxBlob bounds manifests and binaries read into memory.
+const ociMaxBlob = 512 << 20
+
+// Media types rig understands. Docker's manifest list and manifest are
+// accepted alongside the OCI ones.
+const (
+	ociIndexType          = "application/vnd.oci.image.index.v1+json"
+	ociManifestType       = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestList    = "application/vnd.docker.distribution.manifest.list.v2+json"
+	dockerManifestV2      = "application/vnd.docker.distribution.manifest.v2+json"
+	ociTitleAnnotation    = "org.opencontainers.image.title"
+	ociAcceptManifestsHdr = ociIndexType + ", " + ociManifestType + ", " + dockerManifestList + ", " + dockerManifestV2
+)
+
+// ociRepo is a repository in a registry, e.g. ghcr.io + acme/mytool.
+type ociRepo struct {
+	Registry   string
+	Repository string
+}
+
+func (r ociRepo) String() string { return r.Registry + "/" + r.Repository }
+
+// parseOCIRepo parses "ghcr.io/acme/mytool" (optionally prefixed oci://).
+// The registry host is required; tags and digests are not allowed.
+func parseOCIRepo(s string) (ociRepo, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), ociURLPrefix)
+	host, repo, ok := strings.Cut(s, "/")
+	if !ok || host == "" || repo == "" {
+		return ociRepo{}, fmt.Errorf("invalid OCI repository %q (expected registry/name, e.g. ghcr.io/acme/tool)", s)
+	}
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return ociRepo{}, fmt.Errorf("invalid OCI repository %q: %q is not a registry host", s, host)
+	}
+	if strings.ContainsAny(repo, "@:") || repo != strings.ToLower(repo) {
+		return ociRepo{}, fmt.Errorf("invalid OCI repository %q: use a lowercase name without tag or digest", s)
+	}
+	return ociRepo{Registry: host, Repository: repo}, nil
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+		Variant      string `json:"variant,omitempty"`
+	} `json:"platform,omitempty"`
+}
+
+// ociManifest decodes both image indexes (Manifests) and manifests (Layers).
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Manifests []ociDescriptor `json:"manifests"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// ociClient talks to the registry HTTP API (OCI distribution spec). It
+// answers bearer and basic auth challenges with credentials from the Docker
+// config (`docker login` / `oras login`), or anonymously.
+type ociClient struct {
+	client HTTPClient
+
+	mu     sync.Mutex
+	tokens map[string]string // registry -> Authorization header value
+}
+
+var newOCIClient = func() *ociClient {
+	return &ociClient{client: &http.Client{Timeout: 5 * time.Minute}}
+}
+
+// baseURL uses plain HTTP only for loopback registries, as docker does.
+func (c *ociClient) baseURL(r ociRepo) string {
+	host := r.Registry
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" || net.ParseIP(host).IsLoopback() {
+		return "http://" + r.Registry
+	}
+	return "https://" + r.Registry
+}
+
+// get fetches /v2/<repo>/<kind>/<ref> and returns the body, retrying once
+// after an auth challenge.
+func (c *ociClient) get(r ociRepo, kind, ref, accept string) ([]byte, string, error) {
+	u := fmt.Sprintf("%s/v2/%s/%s/%s", c.baseURL(r), r.Repository, kind, ref)
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		c.mu.Lock()
+		auth := c.tokens[r.Registry]
+		c.mu.Unlock()
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, ociMaxBlob+1))
+		resp.Body.Close()
+		if err != nil {
+			return nil, "", err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			if err := c.authenticate(r, resp.Header.Get("WWW-Authenticate")); err != nil {
+				return nil, "", fmt.Errorf("%s: %w", r, err)
+			}
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, "", fmt.Errorf("GET %s: %s", u, resp.Status)
+		}
+		if len(body) > ociMaxBlob {
+			return nil, "", fmt.Errorf("GET %s: response larger than %d bytes", u, ociMaxBlob)
+		}
+		return body, resp.Header.Get("Content-Type"), nil
+	}
+}
+
+// authenticate answers a WWW-Authenticate challenge for r.
+func (c *ociClient) authenticate(r ociRepo, challenge string) error {
+	scheme, params := parseAuthChallenge(challenge)
+	user, pass := dockerCredentials(r.Registry)
+	var auth string
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if user == "" {
+			return errors.New("registry requires credentials (run 'docker login' or 'oras login')")
+		}
+		auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+	case "bearer":
+		realm := params["realm"]
+		if realm == "" {
+			return errors.New("bearer challenge without realm")
+		}
+		q := url.Values{}
+		if s := params["service"]; s != "" {
+			q.Set("service", s)
+		}
+		q.Set("scope", firstNonEmptyString(params["scope"], "repository:"+r.Repository+":pull"))
+		req, err := http.NewRequest(http.MethodGet, realm+"?"+q.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		if user != "" {
+			req.SetBasicAuth(user, pass)
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("token request failed: %s", resp.Status)
+		}
+		var tok struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tok); err != nil {
+			return fmt.Errorf("parse token response: %w", err)
+		}
+		t := firstNonEmptyString(tok.Token, tok.AccessToken)
+		if t == "" {
+			return errors.New("token response without token")
+		}
+		auth = "Bearer " + t
+	default:
+		return fmt.Errorf("unsupported auth challenge %q", challenge)
+	}
+	c.mu.Lock()
+	if c.tokens == nil {
+		c.tokens = map[string]string{}
+	}
+	c.tokens[r.Registry] = auth
+	c.mu.Unlock()
+	return nil
+}
+
+// parseAuthChallenge splits `Bearer realm="…",service="…"` into the scheme
+// and its parameters.
+func parseAuthChallenge(h string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(h), " ")
+	params := map[string]string{}
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		k, v, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		k = strings.TrimSpace(k)
+		if strings.HasPrefix(v, `"`) {
+			end := strings.Index(v[1:], `"`)
+			if end < 0 {
+				break
+			}
+			params[k] = v[1 : end+1]
+			rest = strings.TrimPrefix(strings.TrimSpace(v[end+2:]), ",")
+		} else {
+			val, next, _ := strings.Cut(v, ",")
+			params[k] = strings.TrimSpace(val)
+			rest = next
+		}
+		rest = strings.TrimSpace(rest)
+	}
+	return scheme, params
+}
+
+// dockerCredentials reads the registry's user and password from the Docker
+// config ($DOCKER_CONFIG/config.json or ~/.docker/config.json). Credential
+// helpers are not consulted.
+func dockerCredentials(registry string) (string, string) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", ""
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return "", ""
+	}
+	var conf struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if json.Unmarshal(b, &conf) != nil {
+		return "", ""
+	}
+	for key, a := range conf.Auths {
+		host := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://"), "/")
+		if host != registry {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(a.Auth)
+		if err != nil {
+			return "", ""
+		}
+		user, pass, _ := strings.Cut(string(raw), ":")
+		return user, pass
+	}
+	return "", ""
+}
+
+func ociDigest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// verifyOCIDigest checks content against a sha256: digest.
+func verifyOCIDigest(what string, b []byte, digest string) error {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return fmt.Errorf("%s: unsupported digest %q (only sha256)", what, digest)
+	}
+	if got := ociDigest(b); got != digest {
+		return fmt.Errorf("%s: digest mismatch (got %s, want %s)", what, got, digest)
+	}
+	return nil
+}
+
+// ResolveOCITool resolves tag in repo to the digest of its manifest (or
+// image index), which rig.lock pins.
+func ResolveOCITool(name, repo, tag, bin string) (LockedTool, error) {
+	r, err := parseOCIRepo(repo)
+	if err != nil {
+		return LockedTool{}, err
+	}
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return LockedTool{}, fmt.Errorf("tool %q: empty OCI tag", name)
+	}
+	body, _, err := newOCIClient().get(r, "manifests", tag, ociAcceptManifestsHdr)
+	if err != nil {
+		return LockedTool{}, fmt.Errorf("resolve %s:%s: %w", r, tag, err)
+	}
+	return LockedTool{
+		Kind:      OCIToolKind,
+		Requested: fmt.Sprintf("%s@%s", name, tag),
+		Resolved:  fmt.Sprintf("%s@%s", r, ociDigest(body)),
+		URL:       ociURLPrefix + r.String(),
+		Bin:       bin,
+	}, nil
+}
+
+// InstallOCITool downloads lt's binary for this platform from the registry
+// at its pinned digest into .rig/bin, verifying every manifest and blob
+// against its digest.
+func InstallOCITool(configPath string, lt LockedTool) error {
+	repo, digest := SplitResolved(lt.Resolved)
+	r, err := parseOCIRepo(repo)
+	if err != nil {
+		return err
+	}
+	bin := strings.TrimSpace(lt.Bin)
+	if bin == "" {
+		bin = path.Base(r.Repository)
+	}
+	data, err := fetchOCIBinary(newOCIClient(), r, digest, bin, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return fmt.Errorf("pull %s: %w", lt.Resolved, err)
+	}
+	return writeToolBinary(ToolBinPath(configPath, bin), data)
+}
+
+func fetchOCIBinary(c *ociClient, r ociRepo, digest, bin, goos, goarch string) ([]byte, error) {
+	m, err := fetchOCIManifest(c, r, digest)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Manifests) > 0 {
+		var picked *ociDescriptor
+		for i, d := range m.Manifests {
+			if d.Platform != nil && d.Platform.OS == goos && d.Platform.Architecture == goarch {
+				picked = &m.Manifests[i]
+				break
+			}
+		}
+		if picked == nil {
+			return nil, fmt.Errorf("no manifest for %s/%s in index %s", goos, goarch, digest)
+		}
+		if m, err = fetchOCIManifest(c, r, picked.Digest); err != nil {
+			return nil, err
+		}
+	}
+	layer, err := pickOCILayer(m.Layers, bin, goos, goarch)
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err := c.get(r, "blobs", layer.Digest, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyOCIDigest("blob", blob, layer.Digest); err != nil {
+		return nil, err
+	}
+	title := layer.Annotations[ociTitleAnnotation]
+	if strings.HasSuffix(layer.MediaType, "tar+gzip") || strings.HasSuffix(title, ".tar.gz") || strings.HasSuffix(title, ".tgz") {
+		want := bin
+		if goos == "windows" {
+			want += ".exe"
+		}
+		return ExtractBinary(blob, DownloadOptions{Archive: "tar.gz", BinaryPath: want})
+	}
+	return blob, nil
+}
+
+func fetchOCIManifest(c *ociClient, r ociRepo, digest string) (ociManifest, error) {
+	body, _, err := c.get(r, "manifests", digest, ociAcceptManifestsHdr)
+	if err != nil {
+		return ociManifest{}, err
+	}
+	if err := verifyOCIDigest("manifest", body, digest); err != nil {
+		return ociManifest{}, err
+	}
+	var m ociManifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return ociManifest{}, fmt.Errorf("parse manifest %s: %w", digest, err)
+	}
+	return m, nil
+}
+
+// pickOCILayer chooses the layer holding bin: the one titled bin (bin.exe on
+// Windows), else one whose title names this OS and architecture, else the
+// only layer.
+func pickOCILayer(layers []ociDescriptor, bin, goos, goarch string) (ociDescriptor, error) {
+	want := bin
+	if goos == "windows" {
+		want += ".exe"
+	}
+	for _, l := range layers {
+		if t := l.Annotations[ociTitleAnnotation]; t == bin || t == want {
+			return l, nil
+		}
+	}
+	for _, l := range layers {
+		t := strings.ToLower(l.Annotations[ociTitleAnnotation])
+		if strings.Contains(t, goos) && strings.Contains(t, goarch) {
+			return l, nil
+		}
+	}
+	if len(layers) == 1 {
+		return layers[0], nil
+	}
+	return ociDescriptor{}, fmt.Errorf("cannot tell which of %d layers is %s for %s/%s (title a layer %q)", len(layers), bin, goos, goarch, want)
+}
+
+// writeToolBinary replaces path with an executable holding data.
+func writeToolBinary(path string, data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty binary data")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".rig-tool-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		_ = os.Remove(path)
+	}
+	return os.Rename(tmp.Name(), path)
+}
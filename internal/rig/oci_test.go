@@ -0,0 +1,126 @@
+package rig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// fakeRegistry serves one tagged artifact as an image index holding a
+// manifest for this platform, behind a bearer token.
+func fakeRegistry(t *testing.T, binary []byte) (*httptest.Server, *[]byte) {
+	t.Helper()
+	blob := binary
+	manifest, _ := json.Marshal(ociManifest{
+		MediaType: ociManifestType,
+		Layers: []ociDescriptor{
+			{MediaType: "application/vnd.oci.image.layer.v1.tar", Digest: ociDigest([]byte("readme")), Annotations: map[string]string{ociTitleAnnotation: "README.md"}},
+			{MediaType: "application/octet-stream", Digest: ociDigest(blob), Size: int64(len(blob)), Annotations: map[string]string{ociTitleAnnotation: "mytool"}},
+		},
+	})
+	index := fmt.Sprintf(`{"mediaType":%q,"manifests":[{"mediaType":%q,"digest":%q,"platform":{"os":"plan9","architecture":"386"}},{"mediaType":%q,"digest":%q,"platform":{"os":%q,"architecture":%q}}]}`,
+		ociIndexType, ociManifestType, ociDigest([]byte("other")), ociManifestType, ociDigest(manifest), runtime.GOOS, runtime.GOARCH)
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:acme/mytool:pull" {
+				http.Error(w, "bad scope", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"token":"secret"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:acme/mytool:pull"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/acme/mytool/manifests/1.0.0", "/v2/acme/mytool/manifests/" + ociDigest([]byte(index)):
+			w.Header().Set("Content-Type", ociIndexType)
+			fmt.Fprint(w, index)
+		case "/v2/acme/mytool/manifests/" + ociDigest(manifest):
+			_, _ = w.Write(manifest)
+		case "/v2/acme/mytool/blobs/" + ociDigest(binary):
+			_, _ = w.Write(blob)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &blob
+}
+
+func TestOCIToolResolveAndInstall(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho mytool 1.0.0\n")
+	srv, blob := fakeRegistry(t, binary)
+	repo := strings.TrimPrefix(srv.URL, "http://") + "/acme/mytool"
+
+	UseToolAliases(map[string]cfg.ToolAlias{"mytool": {OCI: repo}})
+	t.Cleanup(func() { UseToolAliases() })
+
+	tools := map[string]string{"mytool": "1.0.0"}
+	locked, err := ResolveLockedTools(tools, t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	lt := locked[0]
+	if lt.Kind != OCIToolKind || lt.URL != "oci://"+repo || lt.Bin != "mytool" || !strings.HasPrefix(lt.Resolved, repo+"@sha256:") || lt.Requested != "mytool@1.0.0" {
+		t.Fatalf("unexpected lock entry: %+v", lt)
+	}
+
+	confPath := filepath.Join(t.TempDir(), "rig.toml")
+	if err := InstallOCITool(confPath, lt); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	got, err := os.ReadFile(ToolBinPath(confPath, "mytool"))
+	if err != nil || string(got) != string(binary) {
+		t.Fatalf("installed binary = %q, %v", got, err)
+	}
+
+	lt.SHA256 = "x"
+	lock := Lockfile{Schema: LockSchema0, Tools: []LockedTool{lt}}
+	if err := lockMatchesTools(lock, tools); err != nil {
+		t.Fatalf("lock should match: %v", err)
+	}
+	UseToolAliases()
+	if err := lockMatchesTools(lock, tools); err == nil || !strings.Contains(err.Error(), "no oci alias") {
+		t.Fatalf("expected alias mismatch, got %v", err)
+	}
+
+	*blob = []byte("tampered")
+	if err := InstallOCITool(confPath, lt); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("expected digest mismatch, got %v", err)
+	}
+}
+
+func TestParseOCIRepo(t *testing.T) {
+	for in, ok := range map[string]bool{
+		"ghcr.io/acme/tool":       true,
+		"oci://ghcr.io/acme/tool": true,
+		"localhost:5000/tool":     true,
+		"acme/tool":               false,
+		"ghcr.io/acme/tool:1.0":   false,
+		"ghcr.io":                 false,
+	} {
+		if _, err := parseOCIRepo(in); (err == nil) != ok {
+			t.Errorf("parseOCIRepo(%q) err = %v, want ok=%v", in, err, ok)
+		}
+	}
+}
+
+func TestParseAuthChallenge(t *testing.T) {
+	scheme, params := parseAuthChallenge(`Bearer realm="https://auth.example/token",service="registry.example",scope="repository:a/b:pull,push"`)
+	if scheme != "Bearer" || params["realm"] != "https://auth.example/token" || params["service"] != "registry.example" || params["scope"] != "repository:a/b:pull,push" {
+		t.Fatalf("scheme=%q params=%v", scheme, params)
+	}
+}
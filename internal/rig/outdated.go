@@ -0,0 +1,168 @@
+package rig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// Severity of an OutdatedItem.
+const (
+	SeverityError   = "error"   // a tool is missing or not the locked version
+	SeverityWarning = "warning" // a stale tool, or Go has a newer patch release or is no longer supported
+	SeverityInfo    = "info"    // a newer rig release exists
+	SeverityNone    = "none"
+)
+
+// OutdatedItem is one row of `rig outdated`: a [tools] entry, the Go
+// toolchain, or rig itself.
+type OutdatedItem struct {
+	Kind     string `json:"kind"` // tool | go | rig
+	Name     string `json:"name"`
+	Have     string `json:"have,omitempty"`
+	Want     string `json:"want,omitempty"`
+	Status   string `json:"status"`
+	Severity string `json:"severity"`
+}
+
+// OutdatedReport is the JSON document `rig outdated --releases --json` prints.
+type OutdatedReport struct {
+	Items []OutdatedItem `json:"items"`
+}
+
+const defaultGoReleasesURL = "https://go.dev/dl/?mode=json"
+
+// GoReleaseOptions configures the Go release lookup for `rig outdated`.
+type GoReleaseOptions struct {
+	Interval time.Duration
+	// StatePath overrides the cache file (default: <user cache>/rig/go-releases.json).
+	StatePath string
+	URL       string
+	Client    HTTPClient
+	Now       func() time.Time
+	// Offline uses the cached list only, however old.
+	Offline bool
+}
+
+// GoReleasesState caches the supported Go releases between runs.
+type GoReleasesState struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Stable    []string  `json:"stable"`
+}
+
+// GoStableReleases returns the currently supported Go releases (the latest
+// patch of each supported minor, e.g. "1.25.1"), from the cache when it is
+// younger than opts.Interval and from go.dev otherwise.
+func GoStableReleases(opts GoReleaseOptions) ([]string, error) {
+	path := strings.TrimSpace(opts.StatePath)
+	if path == "" {
+		dir, err := cfg.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "go-releases.json")
+	}
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultUpdateCheckInterval
+	}
+	var st GoReleasesState
+	if b, err := os.ReadFile(path); err == nil && json.Unmarshal(b, &st) == nil && len(st.Stable) > 0 {
+		if opts.Offline || now.Sub(st.CheckedAt) < interval {
+			return st.Stable, nil
+		}
+	}
+	if opts.Offline {
+		return nil, errors.New("no cached Go release list; run without --offline once")
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	url := strings.TrimSpace(opts.URL)
+	if url == "" {
+		url = defaultGoReleasesURL
+	}
+	body, err := fetchBytes(client, url)
+	if err != nil {
+		return nil, err
+	}
+	var releases []struct {
+		Version string `json:"version"`
+		Stable  bool   `json:"stable"`
+	}
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("parse Go releases: %w", err)
+	}
+	st = GoReleasesState{CheckedAt: now}
+	for _, r := range releases {
+		if r.Stable {
+			st.Stable = append(st.Stable, strings.TrimPrefix(r.Version, "go"))
+		}
+	}
+	if len(st.Stable) == 0 {
+		return nil, errors.New("go.dev lists no stable Go releases")
+	}
+	if b, err := json.MarshalIndent(st, "", "  "); err == nil && os.MkdirAll(filepath.Dir(path), 0o755) == nil {
+		_ = os.WriteFile(path, append(b, '\n'), 0o644)
+	}
+	return st.Stable, nil
+}
+
+// GoOutdatedItem compares version (e.g. "1.24.1") with the supported
+// releases: a newer patch of the same minor is "patch-available", a minor
+// that is no longer listed is "unsupported", and anything else is "ok".
+func GoOutdatedItem(version string, stable []string) (OutdatedItem, error) {
+	have, err := parseSemver(version)
+	if err != nil {
+		return OutdatedItem{}, fmt.Errorf("go version %q: %w", version, err)
+	}
+	item := OutdatedItem{Kind: "go", Name: "go", Have: version, Status: "ok", Severity: SeverityNone}
+	var newest, sameMinor string
+	var newestV, sameMinorV semver
+	for _, s := range stable {
+		v, err := parseSemver(s)
+		if err != nil {
+			continue
+		}
+		if newest == "" || compareSemver(v, newestV) > 0 {
+			newest, newestV = s, v
+		}
+		if v.major == have.major && v.minor == have.minor && (sameMinor == "" || compareSemver(v, sameMinorV) > 0) {
+			sameMinor, sameMinorV = s, v
+		}
+	}
+	switch {
+	case sameMinor == "":
+		if newest != "" && compareSemver(have, newestV) < 0 {
+			item.Want, item.Status, item.Severity = newest, "unsupported", SeverityWarning
+		}
+	case compareSemver(have, sameMinorV) < 0:
+		item.Want, item.Status, item.Severity = sameMinor, "patch-available", SeverityWarning
+	}
+	return item, nil
+}
+
+// ToolSeverity maps a tool status to the severity of its outdated item: a
+// stale tool is the locked version, just built with another Go.
+func ToolSeverity(status string) string {
+	switch ToolState(status) {
+	case ToolOK:
+		return SeverityNone
+	case ToolStale:
+		return SeverityWarning
+	}
+	return SeverityError
+}
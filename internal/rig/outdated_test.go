@@ -0,0 +1,77 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGoStableReleasesCaches(t *testing.T) {
+	hits := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = w.Write([]byte(`[{"version":"go1.25.1","stable":true},{"version":"go1.24.7","stable":true},{"version":"go1.26rc1","stable":false}]`))
+	}))
+	defer ts.Close()
+
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	opts := GoReleaseOptions{
+		Interval:  time.Hour,
+		StatePath: filepath.Join(t.TempDir(), "go-releases.json"),
+		URL:       ts.URL,
+		Client:    ts.Client(),
+		Now:       func() time.Time { return now },
+		Offline:   true,
+	}
+	if _, err := GoStableReleases(opts); err == nil {
+		t.Fatalf("expected an error offline without a cache")
+	}
+	opts.Offline = false
+	want := []string{"1.25.1", "1.24.7"}
+	for range 2 {
+		got, err := GoStableReleases(opts)
+		if err != nil || !slices.Equal(got, want) {
+			t.Fatalf("GoStableReleases = %v, %v; want %v", got, err, want)
+		}
+	}
+	if hits != 1 {
+		t.Fatalf("expected the second lookup to use the cache, got %d requests", hits)
+	}
+	now = now.Add(2 * time.Hour)
+	opts.Offline = true
+	if _, err := GoStableReleases(opts); err != nil || hits != 1 {
+		t.Fatalf("offline lookup should use the old cache: err=%v hits=%d", err, hits)
+	}
+	opts.Offline = false
+	if _, err := GoStableReleases(opts); err != nil || hits != 2 {
+		t.Fatalf("expected a refetch after the interval: err=%v hits=%d", err, hits)
+	}
+}
+
+func TestGoOutdatedItem(t *testing.T) {
+	stable := []string{"1.25.1", "1.24.7"}
+	cases := []struct {
+		have, status, want, severity string
+	}{
+		{"1.24.1", "patch-available", "1.24.7", SeverityWarning},
+		{"1.24", "patch-available", "1.24.7", SeverityWarning},
+		{"1.25.1", "ok", "", SeverityNone},
+		{"1.22.3", "unsupported", "1.25.1", SeverityWarning},
+		{"1.26.0", "ok", "", SeverityNone},
+	}
+	for _, c := range cases {
+		item, err := GoOutdatedItem(c.have, stable)
+		if err != nil {
+			t.Fatalf("%s: %v", c.have, err)
+		}
+		if item.Status != c.status || item.Want != c.want || item.Severity != c.severity {
+			t.Errorf("%s: got %+v, want status=%s want=%s severity=%s", c.have, item, c.status, c.want, c.severity)
+		}
+	}
+	if _, err := GoOutdatedItem("latest", stable); err == nil {
+		t.Fatalf("expected an error for a non-version")
+	}
+}
@@ -0,0 +1,64 @@
+package rig
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output modes for dependency tasks (`rig run --output`).
+const (
+	OutputFull       = "full"
+	OutputPrefixed   = "prefixed"
+	OutputErrorsOnly = "errors-only"
+)
+
+// ParseOutputMode validates an --output value ("" means full).
+func ParseOutputMode(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", OutputFull:
+		return OutputFull, nil
+	case OutputPrefixed:
+		return OutputPrefixed, nil
+	case OutputErrorsOnly:
+		return OutputErrorsOnly, nil
+	default:
+		return "", fmt.Errorf("invalid --output %q (expected errors-only|prefixed|full)", s)
+	}
+}
+
+// prefixWriter writes prefix at the start of every line.
+type prefixWriter struct {
+	w      io.Writer
+	prefix []byte
+	midLn  bool
+}
+
+func newPrefixWriter(w io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{w: w, prefix: []byte(prefix)}
+}
+
+// NewPrefixWriter returns a writer that starts every line written to w with
+// prefix, e.g. "[api] ".
+func NewPrefixWriter(w io.Writer, prefix string) io.Writer {
+	return newPrefixWriter(w, prefix)
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if !p.midLn {
+			out.Write(p.prefix)
+		}
+		out.Write(line)
+		p.midLn = line[len(line)-1] != '\n'
+	}
+	if _, err := p.w.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
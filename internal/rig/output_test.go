@@ -0,0 +1,31 @@
+package rig
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseOutputMode(t *testing.T) {
+	for in, want := range map[string]string{"": OutputFull, "full": OutputFull, "Prefixed": OutputPrefixed, "errors-only": OutputErrorsOnly} {
+		if got, err := ParseOutputMode(in); err != nil || got != want {
+			t.Fatalf("ParseOutputMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseOutputMode("quiet"); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+}
+
+func TestPrefixWriterSplitsLines(t *testing.T) {
+	var buf bytes.Buffer
+	w := newPrefixWriter(&buf, "[gen] ")
+	for _, chunk := range []string{"one\ntw", "o\n", "\nthree"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "[gen] one\n[gen] two\n[gen] \n[gen] three"
+	if got := buf.String(); got != want {
+		t.Fatalf("prefixed output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// OutputPolicy normalizes the files a task's outputs match once it succeeds,
+// e.g. when the task runs in a container as root but writes into the host
+// checkout.
+type OutputPolicy struct {
+	// Umask, when set, gives files 0666 &^ umask (0777 &^ umask if any
+	// execute bit was set) and directories 0777 &^ umask.
+	Umask *fs.FileMode
+	// Owner is "project" (the owner of the rig.toml directory) or
+	// "uid[:gid]"; empty leaves ownership alone.
+	Owner string
+}
+
+// IsZero reports whether the policy changes nothing.
+func (p OutputPolicy) IsZero() bool { return p.Umask == nil && p.Owner == "" }
+
+// String describes the policy, e.g. "umask 022, owner project".
+func (p OutputPolicy) String() string {
+	var parts []string
+	if p.Umask != nil {
+		parts = append(parts, fmt.Sprintf("umask %03o", uint32(*p.Umask)))
+	}
+	if p.Owner != "" {
+		parts = append(parts, "owner "+p.Owner)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// TaskOutputPolicy validates and converts a task's output_umask and
+// output_owner.
+func TaskOutputPolicy(t cfg.Task) (OutputPolicy, error) {
+	var p OutputPolicy
+	if s := strings.TrimSpace(t.OutputUmask); s != "" {
+		n, err := strconv.ParseUint(s, 8, 32)
+		if err != nil || n > 0o777 {
+			return p, fmt.Errorf("output_umask: %q is not an octal umask such as \"022\"", t.OutputUmask)
+		}
+		m := fs.FileMode(n)
+		p.Umask = &m
+	}
+	if s := strings.TrimSpace(t.OutputOwner); s != "" {
+		if _, _, err := parseOwner(s); s != "project" && err != nil {
+			return p, fmt.Errorf("output_owner: %w", err)
+		}
+		p.Owner = s
+	}
+	if !p.IsZero() && len(t.Outputs) == 0 {
+		return p, errors.New("output_umask and output_owner require outputs")
+	}
+	return p, nil
+}
+
+// parseOwner reads "uid" or "uid:gid"; a missing gid is -1 (unchanged).
+func parseOwner(s string) (uid, gid int, err error) {
+	u, g, hasGID := strings.Cut(s, ":")
+	gid = -1
+	if uid, err = strconv.Atoi(u); err != nil || uid < 0 {
+		return 0, 0, fmt.Errorf("%q is not \"project\", a uid, or uid:gid", s)
+	}
+	if hasGID {
+		if gid, err = strconv.Atoi(g); err != nil || gid < 0 {
+			return 0, 0, fmt.Errorf("%q is not \"project\", a uid, or uid:gid", s)
+		}
+	}
+	return uid, gid, nil
+}
+
+// applyOutputPolicy applies p to the files and directories matching outputs
+// (relative to base) and returns how many it changed. Symlinks are left alone.
+func applyOutputPolicy(base string, outputs []string, p OutputPolicy) (int, error) {
+	uid, gid := -1, -1
+	if p.Owner != "" {
+		if runtime.GOOS == "windows" {
+			return 0, errors.New("output_owner is not supported on Windows")
+		}
+		if p.Owner == "project" {
+			var ok bool
+			if uid, gid, ok = fileOwnership(base); !ok {
+				return 0, fmt.Errorf("output_owner: cannot read the owner of %s", base)
+			}
+		} else {
+			uid, gid, _ = parseOwner(p.Owner)
+		}
+	}
+	n := 0
+	for _, pat := range outputs {
+		matches, err := globUnder(base, pat)
+		if err != nil {
+			return n, fmt.Errorf("outputs %q: %w", pat, err)
+		}
+		for _, m := range matches {
+			err := filepath.WalkDir(m, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.Type()&fs.ModeSymlink != 0 {
+					return err
+				}
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				changed := false
+				if p.Umask != nil {
+					want := 0o666 &^ *p.Umask
+					if d.IsDir() || info.Mode().Perm()&0o111 != 0 {
+						want = 0o777 &^ *p.Umask
+					}
+					if info.Mode().Perm() != want {
+						if err := os.Chmod(path, want|(info.Mode()&(fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky))); err != nil {
+							return err
+						}
+						changed = true
+					}
+				}
+				if uid >= 0 {
+					if u, g, ok := fileOwnership(path); !ok || u != uid || (gid >= 0 && g != gid) {
+						if err := os.Lchown(path, uid, gid); err != nil {
+							return fmt.Errorf("output_owner: %w", err)
+						}
+						changed = true
+					}
+				}
+				if changed {
+					n++
+				}
+				return nil
+			})
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// normalizeOutputs applies name's output policy after it succeeded or its
+// outputs were restored from a cache. Failures are warnings: the task's
+// work is done either way.
+func (r *taskRunner) normalizeOutputs(name string, t cfg.Task) {
+	p, err := TaskOutputPolicy(t)
+	if err == nil && p.IsZero() {
+		return
+	}
+	n := 0
+	if err == nil {
+		var base string
+		if base, err = filepath.Abs(filepath.Dir(r.confPath)); err == nil {
+			n, err = applyOutputPolicy(base, t.Outputs, p)
+		}
+	}
+	if n > 0 {
+		statusf(os.Stderr, "🔒 %s: normalized %d output(s) (%s)", name, n, p)
+	}
+	if err != nil {
+		statusf(os.Stderr, "⚠️  %s: %v", name, err)
+	}
+}
@@ -0,0 +1,60 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+func TestTaskOutputPolicy(t *testing.T) {
+	p, err := TaskOutputPolicy(cfg.Task{Outputs: []string{"gen"}, OutputUmask: "022", OutputOwner: "1000:1000"})
+	if err != nil || p.Umask == nil || *p.Umask != 0o022 || p.String() != "umask 022, owner 1000:1000" {
+		t.Fatalf("policy = %+v (%s), %v", p, p, err)
+	}
+	for _, bad := range []cfg.Task{
+		{Outputs: []string{"gen"}, OutputUmask: "999"},
+		{Outputs: []string{"gen"}, OutputUmask: "1777"},
+		{Outputs: []string{"gen"}, OutputOwner: "root"},
+		{Outputs: []string{"gen"}, OutputOwner: "1000:x"},
+		{OutputUmask: "022"},
+	} {
+		if _, err := TaskOutputPolicy(bad); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
+func TestApplyOutputPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permissions")
+	}
+	base := t.TempDir()
+	writeTestFile(t, filepath.Join(base, "gen", "api.go"), "package gen\n", 0o600)
+	writeTestFile(t, filepath.Join(base, "gen", "run.sh"), "#!/bin/sh\n", 0o700)
+	writeTestFile(t, filepath.Join(base, "gen", "ok.txt"), "ok\n", 0o644)
+	if err := os.Chmod(filepath.Join(base, "gen"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	umask := os.FileMode(0o022)
+	p := OutputPolicy{Umask: &umask, Owner: strconv.Itoa(os.Getuid())}
+	n, err := applyOutputPolicy(base, []string{"gen"}, p)
+	if err != nil || n != 3 {
+		t.Fatalf("applyOutputPolicy = %d, %v; want 3 changed", n, err)
+	}
+	for rel, want := range map[string]os.FileMode{"gen": 0o755, "gen/api.go": 0o644, "gen/run.sh": 0o755, "gen/ok.txt": 0o644} {
+		info, err := os.Stat(filepath.Join(base, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("%s: mode %v, want %v", rel, info.Mode().Perm(), want)
+		}
+	}
+	if n, err := applyOutputPolicy(base, []string{"gen"}, p); err != nil || n != 0 {
+		t.Fatalf("second pass = %d, %v; want nothing to change", n, err)
+	}
+}
@@ -0,0 +1,100 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// PathReport is what `rig env path` prints: the PATH rig gives tasks, tools,
+// and dev commands, and where each tool name resolves on it.
+type PathReport struct {
+	Dirs []PathDir `json:"dirs"`
+	// Dropped are the repeated PATH entries rig leaves out.
+	Dropped []string   `json:"dropped,omitempty"`
+	Tools   []PathTool `json:"tools"`
+}
+
+// PathDir is one PATH directory in search order.
+type PathDir struct {
+	Dir string `json:"dir"`
+	// Source is "rig" for the tool bin directory and "environment" for the
+	// inherited PATH.
+	Source  string `json:"source"`
+	Missing bool   `json:"missing,omitempty"`
+}
+
+// PathTool is where a command name resolves on the PATH: Path is the first
+// match (empty when there is none) and Shadowed the later ones it hides.
+type PathTool struct {
+	Name string `json:"name"`
+	// Managed is true for tools pinned in [tools], which should resolve
+	// from the tool bin directory.
+	Managed  bool     `json:"managed"`
+	Path     string   `json:"path,omitempty"`
+	Shadowed []string `json:"shadowed,omitempty"`
+	// FromBin reports whether Path is in the tool bin directory.
+	FromBin bool `json:"fromBin"`
+}
+
+// InspectPath resolves the PATH rig builds from environ for configPath and
+// looks up go and every [tools] binary on it, sorted by name.
+func InspectPath(conf *cfg.Config, configPath string, environ []string) PathReport {
+	basePath := ""
+	for _, kv := range environ {
+		if v, ok := strings.CutPrefix(kv, "PATH="); ok {
+			basePath = v
+		}
+	}
+	bin := localBinDirForConfig(configPath)
+	dirs, dropped := taskPathDirs(bin, basePath)
+	rep := PathReport{Dropped: dropped}
+	for i, d := range dirs {
+		pd := PathDir{Dir: d, Source: "environment"}
+		if i == 0 {
+			pd.Source = "rig"
+		}
+		if info, err := os.Stat(d); err != nil || !info.IsDir() {
+			pd.Missing = true
+		}
+		rep.Dirs = append(rep.Dirs, pd)
+	}
+
+	managed := map[string]bool{}
+	_, tools := splitToolsAndGoRequirement(conf.Tools)
+	for name := range tools {
+		if b := ResolveToolIdentity(name).Bin; b != "" {
+			managed[b] = true
+		}
+	}
+	names := []string{"go"}
+	for b := range managed {
+		names = append(names, b)
+	}
+	sort.Strings(names)
+
+	pathext := os.Getenv("PATHEXT")
+	for _, name := range names {
+		pt := PathTool{Name: name, Managed: managed[name]}
+		for _, d := range dirs {
+			for _, c := range executableCandidates(name, runtime.GOOS, pathext) {
+				p := filepath.Join(d, c)
+				if ensureExecutable(p) != nil {
+					continue
+				}
+				if pt.Path == "" {
+					pt.Path, pt.FromBin = p, d == bin
+				} else {
+					pt.Shadowed = append(pt.Shadowed, p)
+				}
+				break
+			}
+		}
+		rep.Tools = append(rep.Tools, pt)
+	}
+	return rep
+}
@@ -0,0 +1,69 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Environment overrides for [paths]; they win over rig.toml.
+const (
+	EnvPathsBin   = "RIG_PATHS_BIN"
+	EnvPathsCache = "RIG_PATHS_CACHE"
+)
+
+// BinDir is where rig installs and runs managed tools: .rig/bin unless
+// moved by RIG_PATHS_BIN or [paths].bin.
+func BinDir(configPath string) string {
+	return resolveProjectPath(configPath, EnvPathsBin, func(p cfg.PathsConfig) string { return p.Bin }, filepath.Join(".rig", "bin"))
+}
+
+// CacheDir holds rig's per-project state (history.json, last-run.json, logs/,
+// the project lock): .rig unless moved by RIG_PATHS_CACHE or [paths].cache.
+func CacheDir(configPath string) string {
+	return resolveProjectPath(configPath, EnvPathsCache, func(p cfg.PathsConfig) string { return p.Cache }, ".rig")
+}
+
+func resolveProjectPath(configPath, env string, pick func(cfg.PathsConfig) string, def string) string {
+	root := filepath.Dir(configPath)
+	dir := strings.TrimSpace(os.Getenv(env))
+	if dir == "" {
+		dir = strings.TrimSpace(pick(readPathsConfig(configPath)))
+	}
+	if dir == "" {
+		dir = def
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// readPathsConfig reads [paths] from rig.toml alone: like [lock] and
+// [cache], includes cannot set it. The many callers that only have the
+// config path share this instead of a full load; a file that does not parse
+// yields the defaults and fails later in the real load.
+func readPathsConfig(configPath string) cfg.PathsConfig {
+	var raw struct {
+		Paths cfg.PathsConfig `toml:"paths"`
+	}
+	if b, err := os.ReadFile(configPath); err == nil {
+		_ = toml.Unmarshal(b, &raw)
+	}
+	return raw.Paths
+}
+
+// BinDirSource says what chose BinDir: RIG_PATHS_BIN, [paths].bin, or the
+// default.
+func BinDirSource(configPath string) string {
+	switch {
+	case strings.TrimSpace(os.Getenv(EnvPathsBin)) != "":
+		return EnvPathsBin
+	case strings.TrimSpace(readPathsConfig(configPath).Bin) != "":
+		return "[paths].bin"
+	}
+	return "default"
+}
@@ -0,0 +1,43 @@
+package rig
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectPathOverrides(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "rig.toml")
+	writeTestFile(t, conf, "[project]\nname = \"demo\"\n", 0o644)
+	if got, want := BinDir(conf), filepath.Join(dir, ".rig", "bin"); got != want {
+		t.Fatalf("default BinDir=%q, want %q", got, want)
+	}
+	if got, want := CacheDir(conf), filepath.Join(dir, ".rig"); got != want {
+		t.Fatalf("default CacheDir=%q, want %q", got, want)
+	}
+
+	cache := filepath.Join(t.TempDir(), "rig-cache")
+	writeTestFile(t, conf, "[project]\nname = \"demo\"\n\n[paths]\nbin = \".tooling/bin\"\ncache = \""+filepath.ToSlash(cache)+"\"\n", 0o644)
+	if got, want := ToolBinPath(conf, "mockery"), filepath.Join(dir, ".tooling", "bin", "mockery"); filepath.Dir(got) != filepath.Dir(want) {
+		t.Fatalf("ToolBinPath=%q, want under %q", got, filepath.Dir(want))
+	}
+	if got, want := taskHistoryPath(conf), filepath.Join(cache, "history.json"); got != want {
+		t.Fatalf("history path=%q, want %q", got, want)
+	}
+	if got, want := TaskLogPath(conf, "dev"), filepath.Join(cache, "logs", "dev.log"); got != want {
+		t.Fatalf("log path=%q, want %q", got, want)
+	}
+	parsed, err := parseConfigBytes([]byte("[paths]\nbin = \".tooling/bin\"\n"))
+	if err != nil || parsed.Paths.Bin != ".tooling/bin" {
+		t.Fatalf("parsed paths=%+v err=%v", parsed.Paths, err)
+	}
+
+	t.Setenv(EnvPathsBin, "/opt/tools")
+	t.Setenv(EnvPathsCache, "state")
+	if got := BinDir(conf); got != filepath.Clean("/opt/tools") {
+		t.Fatalf("env BinDir=%q", got)
+	}
+	if got, want := ProjectLockPath(conf), filepath.Join(dir, "state", "lock"); got != want {
+		t.Fatalf("env lock path=%q, want %q", got, want)
+	}
+}
@@ -0,0 +1,196 @@
+package rig
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// Plan is the read-only execution plan `rig plan <task>` prints: the tasks
+// `rig run <task>` would run, in order, with how each would run.
+type Plan struct {
+	Task  string     `json:"task"`
+	Steps []PlanStep `json:"steps"`
+	// EstimateMS sums the estimates of the command steps that are not cache
+	// hits; Unestimated lists those without history.
+	EstimateMS  int64    `json:"estimate_ms"`
+	Unestimated []string `json:"unestimated,omitempty"`
+}
+
+// PlanStep is one task of a Plan. Cwd and Dirs are relative to rig.toml;
+// Env holds the task's env over the run's (GOCACHE, profile), not the whole
+// process environment.
+type PlanStep struct {
+	Task         string            `json:"task"`
+	Kind         string            `json:"kind"` // command | steps
+	Command      string            `json:"command,omitempty"`
+	Steps        []string          `json:"steps,omitempty"`
+	Mode         string            `json:"mode,omitempty"`
+	DependsOn    []string          `json:"depends_on,omitempty"`
+	Cwd          string            `json:"cwd,omitempty"`
+	Dirs         []string          `json:"dirs,omitempty"`
+	Env          map[string]string `json:"env,omitempty"`
+	Requires     []string          `json:"requires,omitempty"`
+	AllowFailure bool              `json:"allow_failure,omitempty"`
+	// Cache is "hit" when every output is newer than every source, "miss"
+	// otherwise, and empty for tasks without sources and outputs.
+	Cache       string `json:"cache,omitempty"`
+	CacheReason string `json:"cache_reason,omitempty"`
+	// EstimateMS is the median of the last successful runs (Samples of them).
+	EstimateMS int64 `json:"estimate_ms,omitempty"`
+	Samples    int   `json:"samples,omitempty"`
+}
+
+// Plan step kinds.
+const (
+	PlanCommand = "command"
+	PlanSteps   = "steps"
+)
+
+// PlanTask resolves what `rig run <task>` would do without running anything.
+// profile applies like `rig run --profile`.
+func PlanTask(startDir, taskName, profile string) (Plan, error) {
+	conf, confPath, err := LoadConfig(startDir)
+	if err != nil {
+		return Plan{}, err
+	}
+	order, err := resolveTaskOrder(conf.Tasks, taskName)
+	if err != nil {
+		return Plan{}, err
+	}
+	baseEnv, err := runBaseEnv(conf, confPath, profile)
+	if err != nil {
+		return Plan{}, err
+	}
+	base := filepath.Dir(confPath)
+	history := readTaskHistory(confPath)
+
+	plan := Plan{Task: taskName, Steps: make([]PlanStep, 0, len(order))}
+	runs := map[string]bool{} // command tasks that will run (not cache hits)
+	for _, name := range order {
+		t := conf.Tasks[name]
+		st := PlanStep{Task: name, DependsOn: t.DependsOn, Requires: t.Requires, AllowFailure: t.AllowFailure}
+		if len(t.Steps) > 0 {
+			st.Kind, st.Steps, st.Mode = PlanSteps, t.Steps, t.Mode
+			if st.Mode == "" {
+				st.Mode = cfg.TaskModeSerial
+			}
+			plan.Steps = append(plan.Steps, st)
+			continue
+		}
+		st.Kind, st.Command, st.Env = PlanCommand, t.Command, taskEnvOver(baseEnv, t.Env)
+		if len(t.Dirs) > 0 {
+			dirs, err := expandTaskDirs(base, t.Dirs)
+			if err != nil {
+				return Plan{}, fmt.Errorf("task %q: %w", name, err)
+			}
+			for _, d := range dirs {
+				st.Dirs = append(st.Dirs, dirLabel(base, d))
+			}
+		} else {
+			cwd, err := resolveCwd(confPath, t.Cwd)
+			if err != nil {
+				return Plan{}, fmt.Errorf("task %q: resolve cwd: %w", name, err)
+			}
+			st.Cwd = dirLabel(base, cwd)
+		}
+		st.Cache, st.CacheReason = planCache(base, t, conf.Tasks, runs)
+		if st.Cache != "hit" {
+			runs[name] = true
+		}
+		if d, n := estimateDuration(history[name]); n > 0 {
+			st.EstimateMS, st.Samples = d.Milliseconds(), n
+		}
+		if st.Cache != "hit" {
+			if st.Samples > 0 {
+				plan.EstimateMS += st.EstimateMS
+			} else {
+				plan.Unestimated = append(plan.Unestimated, name)
+			}
+		}
+		plan.Steps = append(plan.Steps, st)
+	}
+	return plan, nil
+}
+
+// planCache judges a task with sources and outputs by file times: a hit
+// when its outputs exist and are newer than all its sources and none of its
+// dependencies will run.
+func planCache(base string, t cfg.Task, tasks cfg.TasksMap, runs map[string]bool) (string, string) {
+	if len(t.Sources) == 0 || len(t.Outputs) == 0 {
+		return "", ""
+	}
+	for _, dep := range t.DependsOn {
+		if runs[dep] || dependsOnRun(tasks, dep, runs) {
+			return "miss", fmt.Sprintf("depends on %s, which runs", dep)
+		}
+	}
+	newestSource, _, n, err := globTimes(base, t.Sources)
+	if err != nil {
+		return "miss", err.Error()
+	}
+	if n == 0 {
+		return "miss", "no source files"
+	}
+	_, oldestOutput, n, err := globTimes(base, t.Outputs)
+	if err != nil {
+		return "miss", err.Error()
+	}
+	if n == 0 {
+		return "miss", "outputs missing"
+	}
+	if newestSource.After(oldestOutput) {
+		return "miss", "sources changed since outputs were written"
+	}
+	return "hit", "outputs newer than sources"
+}
+
+// dependsOnRun reports whether a composite task's steps include a task that
+// runs.
+func dependsOnRun(tasks cfg.TasksMap, name string, runs map[string]bool) bool {
+	for _, s := range tasks[name].Steps {
+		if runs[s] || dependsOnRun(tasks, s, runs) {
+			return true
+		}
+	}
+	return false
+}
+
+// globTimes returns the newest and oldest modification times of the files
+// the patterns match (directories count the files beneath them) and how many
+// files there were.
+func globTimes(base string, patterns []string) (newest, oldest time.Time, n int, err error) {
+	for _, pat := range patterns {
+		matches, err := globUnder(base, pat)
+		if err != nil {
+			return newest, oldest, n, fmt.Errorf("%q: %w", pat, err)
+		}
+		for _, m := range matches {
+			err := filepath.WalkDir(m, func(_ string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				mt := info.ModTime()
+				if n == 0 || mt.After(newest) {
+					newest = mt
+				}
+				if n == 0 || mt.Before(oldest) {
+					oldest = mt
+				}
+				n++
+				return nil
+			})
+			if err != nil {
+				return newest, oldest, n, err
+			}
+		}
+	}
+	return newest, oldest, n, nil
+}
@@ -0,0 +1,82 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlanTask(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks]
+gen = { command = "gen", sources = ["api"], outputs = ["out"] }
+client = { command = "client", sources = ["out"], outputs = ["client"], depends_on = ["gen"] }
+lint = { command = "lint", cwd = "api", env = { A = "1" } }
+ci = { steps = ["client", "lint"] }
+`, 0o644)
+	writeTestFile(t, filepath.Join(dir, "api", "spec.yaml"), "spec", 0o644)
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "api", "spec.yaml"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "out", "spec.go"), "package out", 0o644)
+	recordTaskHistory(filepath.Join(dir, "rig.toml"), map[string]taskTiming{"lint": {MS: 1500, OK: true}})
+	recordTaskHistory(filepath.Join(dir, "rig.toml"), map[string]taskTiming{"lint": {MS: 900, OK: false}})
+
+	plan, err := PlanTask(dir, "ci", "")
+	if err != nil {
+		t.Fatalf("PlanTask: %v", err)
+	}
+	var names []string
+	steps := map[string]PlanStep{}
+	for _, st := range plan.Steps {
+		names = append(names, st.Task)
+		steps[st.Task] = st
+	}
+	if got := strings.Join(names, ","); got != "gen,client,lint,ci" {
+		t.Fatalf("order = %s", got)
+	}
+	if st := steps["gen"]; st.Cache != "hit" || st.Cwd != "." {
+		t.Fatalf("gen: %+v", st)
+	}
+	if st := steps["client"]; st.Cache != "miss" || st.CacheReason != "outputs missing" {
+		t.Fatalf("client: %+v", st)
+	}
+	if st := steps["lint"]; st.Cwd != "api" || st.Env["A"] != "1" || st.EstimateMS != 1500 || st.Samples != 1 {
+		t.Fatalf("lint: %+v", st)
+	}
+	if st := steps["ci"]; st.Kind != PlanSteps || st.Mode != "serial" {
+		t.Fatalf("ci: %+v", st)
+	}
+	if plan.EstimateMS != 1500 || strings.Join(plan.Unestimated, ",") != "client" {
+		t.Fatalf("estimate = %d, unestimated = %v", plan.EstimateMS, plan.Unestimated)
+	}
+
+	// A rewritten source invalidates gen and, through depends_on, client.
+	writeTestFile(t, filepath.Join(dir, "client", "c.go"), "package client", 0o644)
+	writeTestFile(t, filepath.Join(dir, "api", "spec.yaml"), "spec v2", 0o644)
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "api", "spec.yaml"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = PlanTask(dir, "client", "")
+	if err != nil {
+		t.Fatalf("PlanTask: %v", err)
+	}
+	if plan.Steps[0].Cache != "miss" || plan.Steps[1].CacheReason != "depends on gen, which runs" {
+		t.Fatalf("expected gen and client to miss: %+v", plan.Steps)
+	}
+}
+
+func TestEstimateDuration(t *testing.T) {
+	runs := []taskTiming{{MS: 300, OK: true}, {MS: 100, OK: true}, {MS: 9000, OK: false}, {MS: 200, OK: true}}
+	if d, n := estimateDuration(runs); d != 200*time.Millisecond || n != 3 {
+		t.Fatalf("estimateDuration = %v, %d", d, n)
+	}
+	if _, n := estimateDuration(nil); n != 0 {
+		t.Fatalf("expected no estimate without runs")
+	}
+}
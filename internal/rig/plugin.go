@@ -0,0 +1,373 @@
+package rig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// Plugin protocol, version 1.
+//
+// rig starts a plugin with RIG_PLUGIN_COOKIE=PluginCookie and
+// RIG_PLUGIN_PROTOCOL=1 in its environment (a binary started without the
+// cookie should explain that it is a rig plugin and exit). The plugin
+// answers with one handshake line on stdout,
+//
+//	rig-plugin 1 cache,secrets,notify
+//
+// naming the protocol version and the hooks it serves, and then serves
+// JSON-RPC 1.0 (Go's net/rpc/jsonrpc) on stdin and stdout: the methods
+// below, with the Args/Reply types as params and results. Stderr is shown
+// to the user, prefixed with the plugin name. rig closes stdin when done;
+// the plugin should then exit.
+//
+// Like Terraform providers the plugin is a separate process, but the wire
+// format is the standard library's so neither side needs gRPC.
+const (
+	PluginCookie          = "d4b1f0a2-rig-plugin"
+	PluginProtocolVersion = 1
+
+	pluginHandshakePrefix = "rig-plugin"
+	pluginStartTimeout    = 10 * time.Second
+)
+
+// Plugin methods.
+const (
+	PluginMethodCacheGet   = "Cache.Get"
+	PluginMethodCachePut   = "Cache.Put"
+	PluginMethodSecretsGet = "Secrets.Get"
+	PluginMethodNotifySend = "Notify.Send"
+)
+
+// CacheGetArgs asks a cache plugin for the outputs stored under Key.
+type CacheGetArgs struct {
+	Key  string `json:"key"`
+	Task string `json:"task"`
+}
+
+// CacheGetReply carries the outputs as a gzipped tar of paths relative to
+// rig.toml, when Found.
+type CacheGetReply struct {
+	Found   bool   `json:"found"`
+	Archive []byte `json:"archive,omitempty"`
+}
+
+// CachePutArgs stores a task's outputs (see CacheGetReply) under Key.
+type CachePutArgs struct {
+	Key     string `json:"key"`
+	Task    string `json:"task"`
+	Archive []byte `json:"archive"`
+}
+
+// SecretsGetArgs asks a secrets plugin for the value of Name.
+type SecretsGetArgs struct {
+	Name string `json:"name"`
+}
+
+// SecretsGetReply holds the secret value, when Found.
+type SecretsGetReply struct {
+	Found bool   `json:"found"`
+	Value string `json:"value,omitempty"`
+}
+
+// NotifyArgs reports a finished `rig run`: the requested task, whether the
+// run succeeded, and each command task that ran.
+type NotifyArgs struct {
+	Project    string             `json:"project,omitempty"`
+	Task       string             `json:"task"`
+	OK         bool               `json:"ok"`
+	Error      string             `json:"error,omitempty"`
+	DurationMS int64              `json:"duration_ms"`
+	Tasks      []NotifyTaskResult `json:"tasks,omitempty"`
+}
+
+// NotifyTaskResult is one command task of a NotifyArgs run.
+type NotifyTaskResult struct {
+	Task string `json:"task"`
+	OK   bool   `json:"ok"`
+	MS   int64  `json:"ms"`
+}
+
+// PluginEmpty is the reply of methods that return nothing.
+type PluginEmpty struct{}
+
+// validatePlugins checks [plugins] entries when rig.toml is loaded.
+func validatePlugins(plugins map[string]cfg.Plugin) error {
+	for name, p := range plugins {
+		if strings.TrimSpace(p.Command) == "" {
+			return fmt.Errorf("plugin %q: command is required", name)
+		}
+		if len(p.Provides) == 0 {
+			return fmt.Errorf("plugin %q: provides is required (%s, %s, or %s)", name, cfg.PluginCache, cfg.PluginSecrets, cfg.PluginNotify)
+		}
+		for _, hook := range p.Provides {
+			switch hook {
+			case cfg.PluginCache, cfg.PluginSecrets, cfg.PluginNotify:
+			default:
+				return fmt.Errorf("plugin %q: unknown hook %q in provides (want %s, %s, or %s)", name, hook, cfg.PluginCache, cfg.PluginSecrets, cfg.PluginNotify)
+			}
+		}
+	}
+	return nil
+}
+
+// pluginClient is a running plugin process.
+type pluginClient struct {
+	name string
+	cmd  *exec.Cmd
+	rpc  *rpc.Client
+}
+
+// startPlugin launches a plugin and completes the handshake.
+func startPlugin(confPath string, lock Lockfile, name string, p cfg.Plugin) (*pluginClient, error) {
+	argv, err := parseCommand(p.Command)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %q: %w", name, err)
+	}
+	base := filepath.Dir(confPath)
+	env := buildEnv(confPath, os.Environ(), p.Env)
+	exe, err := resolveTaskExecutable(confPath, lock, argv[0], base, env)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %q: %w", name, err)
+	}
+	cmd := exec.Command(exe, argv[1:]...)
+	cmd.Dir = base
+	cmd.Env = append(env, "RIG_PLUGIN_COOKIE="+PluginCookie, fmt.Sprintf("RIG_PLUGIN_PROTOCOL=%d", PluginProtocolVersion))
+	cmd.Stderr = newPrefixWriter(os.Stderr, "[plugin "+name+"] ")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("plugin %q: %w", name, err)
+	}
+	out := bufio.NewReader(stdout)
+	line := make(chan string, 1)
+	go func() {
+		s, _ := out.ReadString('\n')
+		line <- s
+	}()
+	var hello string
+	select {
+	case hello = <-line:
+	case <-time.After(pluginStartTimeout):
+	}
+	pc := &pluginClient{name: name, cmd: cmd}
+	provides, err := parsePluginHandshake(hello)
+	if err != nil {
+		_ = stdin.Close()
+		pc.stop()
+		return nil, fmt.Errorf("plugin %q: %w", name, err)
+	}
+	for _, hook := range p.Provides {
+		if !slices.Contains(provides, hook) {
+			_ = stdin.Close()
+			pc.stop()
+			return nil, fmt.Errorf("plugin %q: rig.toml says it provides %q but the plugin serves only %s", name, hook, strings.Join(provides, ", "))
+		}
+	}
+	pc.rpc = rpc.NewClientWithCodec(jsonrpc.NewClientCodec(pluginConn{Reader: out, WriteCloser: stdin}))
+	return pc, nil
+}
+
+// parsePluginHandshake reads "rig-plugin <version> <hooks>".
+func parsePluginHandshake(line string) ([]string, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[0] != pluginHandshakePrefix {
+		if strings.TrimSpace(line) == "" {
+			return nil, errors.New("no handshake (not a rig plugin, or it did not start)")
+		}
+		return nil, fmt.Errorf("bad handshake %q (want %q)", strings.TrimSpace(line), fmt.Sprintf("%s %d <hooks>", pluginHandshakePrefix, PluginProtocolVersion))
+	}
+	if fields[1] != fmt.Sprint(PluginProtocolVersion) {
+		return nil, fmt.Errorf("speaks plugin protocol %s; this rig speaks %d", fields[1], PluginProtocolVersion)
+	}
+	return strings.Split(fields[2], ","), nil
+}
+
+// pluginConn joins the plugin's stdout and stdin into one connection.
+type pluginConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+func (pc *pluginClient) call(method string, args, reply any) error {
+	if err := pc.rpc.Call(method, args, reply); err != nil {
+		return fmt.Errorf("plugin %q: %s: %w", pc.name, method, err)
+	}
+	return nil
+}
+
+// close ends the session; a plugin that does not exit on EOF is killed.
+func (pc *pluginClient) close() {
+	if pc.rpc != nil {
+		_ = pc.rpc.Close()
+	}
+	pc.stop()
+}
+
+func (pc *pluginClient) stop() {
+	done := make(chan struct{})
+	go func() {
+		_ = pc.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		_ = pc.cmd.Process.Kill()
+		<-done
+	}
+}
+
+// pluginSet starts the plugins of a run lazily, the first time one of their
+// hooks is needed, and stops them all at the end.
+type pluginSet struct {
+	confPath string
+	lock     Lockfile
+	plugins  map[string]cfg.Plugin
+
+	mu      sync.Mutex
+	running map[string]*pluginClient
+}
+
+func newPluginSet(confPath string, lock Lockfile, plugins map[string]cfg.Plugin) *pluginSet {
+	return &pluginSet{confPath: confPath, lock: lock, plugins: plugins, running: map[string]*pluginClient{}}
+}
+
+// has reports whether any plugin provides hook.
+func (ps *pluginSet) has(hook string) bool {
+	return len(ps.names(hook)) > 0
+}
+
+// names returns the plugins providing hook, in name order.
+func (ps *pluginSet) names(hook string) []string {
+	if ps == nil {
+		return nil
+	}
+	var out []string
+	for name, p := range ps.plugins {
+		if slices.Contains(p.Provides, hook) {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// each calls fn with every plugin providing hook, starting it if needed, and
+// stops at the first error.
+func (ps *pluginSet) each(hook string, fn func(*pluginClient) (bool, error)) error {
+	for _, name := range ps.names(hook) {
+		pc, err := ps.get(name)
+		if err != nil {
+			return err
+		}
+		stop, err := fn(pc)
+		if err != nil || stop {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ps *pluginSet) get(name string) (*pluginClient, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if pc, ok := ps.running[name]; ok {
+		return pc, nil
+	}
+	pc, err := startPlugin(ps.confPath, ps.lock, name, ps.plugins[name])
+	if err != nil {
+		return nil, err
+	}
+	ps.running[name] = pc
+	return pc, nil
+}
+
+func (ps *pluginSet) close() {
+	if ps == nil {
+		return
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for name, pc := range ps.running {
+		pc.close()
+		delete(ps.running, name)
+	}
+}
+
+// secretPrefix marks a task env value resolved by a secrets plugin, e.g.
+// DB_PASSWORD = "secret://db/password".
+const secretPrefix = "secret://"
+
+// resolveSecrets returns env with secret:// values replaced by the first
+// secrets plugin (in name order) that knows them. env is not modified.
+func (ps *pluginSet) resolveSecrets(env map[string]string) (map[string]string, error) {
+	var out map[string]string
+	for k, v := range env {
+		if !strings.HasPrefix(v, secretPrefix) {
+			continue
+		}
+		if out == nil {
+			out = maps.Clone(env)
+		}
+		name := strings.TrimPrefix(v, secretPrefix)
+		if !ps.has(cfg.PluginSecrets) {
+			return nil, fmt.Errorf("env %s: %s needs a [plugins] entry that provides %q", k, v, cfg.PluginSecrets)
+		}
+		found := false
+		err := ps.each(cfg.PluginSecrets, func(pc *pluginClient) (bool, error) {
+			var reply SecretsGetReply
+			if err := pc.call(PluginMethodSecretsGet, SecretsGetArgs{Name: name}, &reply); err != nil {
+				return false, err
+			}
+			if reply.Found {
+				out[k], found = reply.Value, true
+			}
+			return reply.Found, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", k, err)
+		}
+		if !found {
+			return nil, fmt.Errorf("env %s: secret %q not found by any secrets plugin", k, name)
+		}
+	}
+	if out == nil {
+		return env, nil
+	}
+	return out, nil
+}
+
+// notify sends the result of a run to every notify plugin. Failures are
+// warnings: a broken notification sink never fails the run.
+func (ps *pluginSet) notify(args NotifyArgs) {
+	err := ps.each(cfg.PluginNotify, func(pc *pluginClient) (bool, error) {
+		if err := pc.call(PluginMethodNotifySend, args, &PluginEmpty{}); err != nil {
+			statusf(os.Stderr, "⚠️  %v", err)
+		}
+		return false, nil
+	})
+	if err != nil {
+		statusf(os.Stderr, "⚠️  %v", err)
+	}
+}
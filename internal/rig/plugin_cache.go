@@ -0,0 +1,254 @@
+package rig
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// pluginCacheable reports whether a task's outputs can come from a cache
+// plugin: it declares sources and outputs and runs in one directory.
+func pluginCacheable(t cfg.Task, dirs []string) bool {
+	return len(t.Sources) > 0 && len(t.Outputs) > 0 && len(dirs) <= 1
+}
+
+// TaskCacheable reports whether a task can be served from a cache plugin
+// and judged up to date by `rig plan`: a command task declaring sources and
+// outputs that does not fan out over dirs.
+func TaskCacheable(t cfg.Task) bool {
+	return len(t.Steps) == 0 && len(t.Dirs) == 0 && pluginCacheable(t, nil)
+}
+
+// taskCacheKey hashes what determines a task's outputs: the argv it runs
+// (expanded, with inputs and arguments), the rig.lock pin of the tool that
+// argv resolves to, its env (secret:// references, not their values), cwd,
+// platform, and the names and contents of its source files.
+func (r *taskRunner) taskCacheKey(name string, t cfg.Task, extra, dirs []string, inputs map[string]string) (string, error) {
+	base, err := filepath.Abs(filepath.Dir(r.confPath))
+	if err != nil {
+		return "", err
+	}
+	argv, err := taskArgv(name, t, extra, inputs, buildEnv(r.confPath, r.environ, t.Env))
+	if err != nil {
+		return "", err
+	}
+	relDirs := make([]string, len(dirs))
+	for i, d := range dirs {
+		rel, err := relUnder(base, d)
+		if err != nil {
+			return "", err
+		}
+		relDirs[i] = filepath.ToSlash(rel)
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "rig-task-cache-v2\x00%s\x00%s/%s\x00%q\x00%q\x00%q\x00", name, runtime.GOOS, runtime.GOARCH, argv, t.Cwd, relDirs)
+	if argv[0] == "go" {
+		if tc := r.lock.Toolchain; tc != nil && tc.Go != nil {
+			fmt.Fprintf(h, "go %q %q\x00", tc.Go.Requested, tc.Go.Detected)
+		}
+	} else if lt, ok, err := lockedToolFor(r.lock, argv[0]); err != nil {
+		return "", err
+	} else if ok {
+		fmt.Fprintf(h, "tool %q %q\x00", lt.Resolved, lt.SHA256)
+	}
+	for _, k := range sortedKeys(t.Env) {
+		fmt.Fprintf(h, "env %s=%s\x00", k, t.Env[k])
+	}
+	for _, k := range sortedKeys(inputs) {
+		fmt.Fprintf(h, "input %s=%s\x00", k, inputs[k])
+	}
+	fmt.Fprintf(h, "outputs %q\x00", t.Outputs)
+	var files []string
+	for _, pat := range t.Sources {
+		matches, err := globUnder(base, pat)
+		if err != nil {
+			return "", fmt.Errorf("sources %q: %w", pat, err)
+		}
+		for _, m := range matches {
+			err := filepath.WalkDir(m, func(p string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				files = append(files, p)
+				return nil
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	sort.Strings(files)
+	files = slices.Compact(files)
+	for _, f := range files {
+		sum, err := ComputeFileSHA256(f)
+		if err != nil {
+			return "", err
+		}
+		rel, _ := filepath.Rel(base, f)
+		fmt.Fprintf(h, "source %s %s\x00", filepath.ToSlash(rel), sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// archiveOutputs packs the files matching outputs into a gzipped tar of
+// slash paths relative to base, and returns how many files it holds.
+func archiveOutputs(base string, outputs []string) ([]byte, int, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	n := 0
+	for _, pat := range outputs {
+		matches, err := globUnder(base, pat)
+		if err != nil {
+			return nil, 0, fmt.Errorf("outputs %q: %w", pat, err)
+		}
+		for _, m := range matches {
+			err := filepath.WalkDir(m, func(p string, d fs.DirEntry, err error) error {
+				if err != nil || !d.Type().IsRegular() {
+					return err
+				}
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				rel, err := relUnder(base, p)
+				if err != nil {
+					return err
+				}
+				hdr := &tar.Header{Name: filepath.ToSlash(rel), Mode: int64(info.Mode().Perm()), Size: info.Size(), ModTime: info.ModTime(), Typeflag: tar.TypeReg}
+				if err := tw.WriteHeader(hdr); err != nil {
+					return err
+				}
+				f, err := os.Open(p)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				if _, err := io.Copy(tw, f); err != nil {
+					return err
+				}
+				n++
+				return nil
+			})
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), n, nil
+}
+
+// restoreOutputs unpacks an archiveOutputs archive under base. Entries that
+// would land outside base are rejected.
+func restoreOutputs(base string, archive []byte) (int, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return 0, err
+	}
+	tr := tar.NewReader(zr)
+	n := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		dst := filepath.Join(base, filepath.FromSlash(hdr.Name))
+		if _, err := relUnder(base, dst); err != nil {
+			return n, err
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return n, err
+		}
+		f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fs.FileMode(hdr.Mode).Perm())
+		if err != nil {
+			return n, err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			_ = f.Close()
+			return n, err
+		}
+		if err := f.Close(); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// restoreFromPluginCache asks the cache plugins, in name order, for key and
+// restores the first hit. It reports whether the task's outputs were
+// restored.
+func (r *taskRunner) restoreFromPluginCache(name, key string) (bool, error) {
+	base, err := filepath.Abs(filepath.Dir(r.confPath))
+	if err != nil {
+		return false, err
+	}
+	hit := false
+	err = r.plugins.each(cfg.PluginCache, func(pc *pluginClient) (bool, error) {
+		var reply CacheGetReply
+		if err := pc.call(PluginMethodCacheGet, CacheGetArgs{Key: key, Task: name}, &reply); err != nil {
+			return false, err
+		}
+		if !reply.Found {
+			return false, nil
+		}
+		n, err := restoreOutputs(base, reply.Archive)
+		if err != nil {
+			return false, fmt.Errorf("plugin %q: restore outputs: %w", pc.name, err)
+		}
+		statusf(os.Stderr, "♻️  %s restored from cache (plugin %s, %d file(s))", name, pc.name, n)
+		hit = true
+		return true, nil
+	})
+	return hit, err
+}
+
+// storeInPluginCache uploads a task's outputs to every cache plugin.
+func (r *taskRunner) storeInPluginCache(name string, t cfg.Task, key string) error {
+	base, err := filepath.Abs(filepath.Dir(r.confPath))
+	if err != nil {
+		return err
+	}
+	archive, n, err := archiveOutputs(base, t.Outputs)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+	return r.plugins.each(cfg.PluginCache, func(pc *pluginClient) (bool, error) {
+		return false, pc.call(PluginMethodCachePut, CachePutArgs{Key: key, Task: name, Archive: archive}, &PluginEmpty{})
+	})
+}
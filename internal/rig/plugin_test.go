@@ -0,0 +1,176 @@
+package rig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestMain lets the test binary double as a plugin: with RIG_TEST_PLUGIN set
+// it serves the plugin protocol instead of running tests.
+func TestMain(m *testing.M) {
+	if os.Getenv("RIG_TEST_PLUGIN") != "" {
+		serveTestPlugin()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testPlugin keeps its cache and notifications as files in dir.
+type testPlugin struct{ dir string }
+
+func (p *testPlugin) Get(args CacheGetArgs, reply *CacheGetReply) error {
+	b, err := os.ReadFile(filepath.Join(p.dir, args.Key))
+	if err == nil {
+		reply.Found, reply.Archive = true, b
+	}
+	return nil
+}
+
+func (p *testPlugin) Put(args CachePutArgs, _ *PluginEmpty) error {
+	return os.WriteFile(filepath.Join(p.dir, args.Key), args.Archive, 0o644)
+}
+
+type testSecrets struct{}
+
+func (testSecrets) Get(args SecretsGetArgs, reply *SecretsGetReply) error {
+	if args.Name == "api/token" {
+		reply.Found, reply.Value = true, "s3cr3t"
+	}
+	return nil
+}
+
+type testNotify struct{ dir string }
+
+func (n testNotify) Send(args NotifyArgs, _ *PluginEmpty) error {
+	b, _ := json.Marshal(args)
+	f, err := os.OpenFile(filepath.Join(n.dir, "notify.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s\n", b)
+	return err
+}
+
+func serveTestPlugin() {
+	if os.Getenv("RIG_PLUGIN_COOKIE") != PluginCookie {
+		fmt.Fprintln(os.Stderr, "this is a rig plugin; run it via [plugins] in rig.toml")
+		os.Exit(1)
+	}
+	dir := os.Getenv("RIG_TEST_PLUGIN_DIR")
+	srv := rpc.NewServer()
+	_ = srv.RegisterName("Cache", &testPlugin{dir: dir})
+	_ = srv.RegisterName("Secrets", testSecrets{})
+	_ = srv.RegisterName("Notify", testNotify{dir: dir})
+	fmt.Printf("rig-plugin %d cache,secrets,notify\n", PluginProtocolVersion)
+	srv.ServeCodec(jsonrpc.NewServerCodec(struct {
+		io.Reader
+		io.WriteCloser
+	}{os.Stdin, os.Stdout}))
+}
+
+func TestPluginCacheSecretsAndNotify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	root := t.TempDir()
+	store := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "rig.toml"), fmt.Sprintf(`
+[tasks]
+gen = { command = "sh -c 'cat src.txt > out.txt; echo $TOKEN > token.txt; echo ran >> runs.log'", sources = ["src.txt"], outputs = ["out.txt"], env = { TOKEN = "secret://api/token" } }
+leak = { command = "true", env = { TOKEN = "secret://nope" } }
+
+[plugins.test]
+command = %q
+provides = ["cache", "secrets", "notify"]
+env = { RIG_TEST_PLUGIN = "1", RIG_TEST_PLUGIN_DIR = %q }
+`, os.Args[0], store), 0o644)
+	writeTestFile(t, filepath.Join(root, "rig.lock"), "schema = 0\n", 0o644)
+	writeTestFile(t, filepath.Join(root, "src.txt"), "generated\n", 0o644)
+
+	if err := RunWith(context.Background(), root, "gen", nil, RunOptions{}); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(root, "token.txt")); string(b) != "s3cr3t\n" {
+		t.Fatalf("secret not resolved, token.txt=%q", b)
+	}
+
+	if err := os.Remove(filepath.Join(root, "out.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunWith(context.Background(), root, "gen", nil, RunOptions{}); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(root, "out.txt")); string(b) != "generated\n" {
+		t.Fatalf("outputs not restored from cache, out.txt=%q", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(root, "runs.log")); string(b) != "ran\n" {
+		t.Fatalf("cache hit must not rerun the task, runs.log=%q", b)
+	}
+
+	writeTestFile(t, filepath.Join(root, "src.txt"), "changed\n", 0o644)
+	if err := RunWith(context.Background(), root, "gen", nil, RunOptions{}); err != nil {
+		t.Fatalf("third run: %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(root, "runs.log")); string(b) != "ran\nran\n" {
+		t.Fatalf("changed sources must miss the cache, runs.log=%q", b)
+	}
+
+	if err := RunWith(context.Background(), root, "leak", nil, RunOptions{}); err == nil || !strings.Contains(err.Error(), `secret "nope" not found`) {
+		t.Fatalf("expected unknown secret error, got %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(store, "notify.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("want 4 notifications, got:\n%s", b)
+	}
+	var first, last NotifyArgs
+	_ = json.Unmarshal([]byte(lines[0]), &first)
+	_ = json.Unmarshal([]byte(lines[3]), &last)
+	if first.Task != "gen" || !first.OK || len(first.Tasks) != 1 || first.Tasks[0].Task != "gen" {
+		t.Fatalf("first notification=%+v", first)
+	}
+	if last.Task != "leak" || last.OK || !strings.Contains(last.Error, "nope") {
+		t.Fatalf("last notification=%+v", last)
+	}
+}
+
+func TestValidatePlugins(t *testing.T) {
+	_, err := parseConfigBytes([]byte("[plugins.x]\ncommand = \"x\"\nprovides = [\"logs\"]\n"))
+	if err == nil || !strings.Contains(err.Error(), `unknown hook "logs"`) {
+		t.Fatalf("expected unknown hook error, got %v", err)
+	}
+	_, err = parseConfigBytes([]byte("[plugins.x]\nprovides = [\"cache\"]\n"))
+	if err == nil || !strings.Contains(err.Error(), "command is required") {
+		t.Fatalf("expected missing command error, got %v", err)
+	}
+}
+
+func TestParsePluginHandshake(t *testing.T) {
+	if hooks, err := parsePluginHandshake("rig-plugin 1 cache,notify\n"); err != nil || strings.Join(hooks, ",") != "cache,notify" {
+		t.Fatalf("hooks=%v err=%v", hooks, err)
+	}
+	for line, want := range map[string]string{
+		"":                     "no handshake",
+		"hello\n":              "bad handshake",
+		"rig-plugin 2 cache\n": "protocol 2",
+		"rig-plugin 1\n":       "bad handshake",
+	} {
+		if _, err := parsePluginHandshake(line); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%q: err=%v, want %q", line, err, want)
+		}
+	}
+}
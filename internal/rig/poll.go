@@ -0,0 +1,225 @@
+package rig
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Watch modes for [tasks.dev].watch_mode.
+const (
+	WatchModeAuto = "auto"
+	WatchModePoll = "poll"
+)
+
+// DefaultPollInterval is used when poll mode has no explicit interval.
+const DefaultPollInterval = time.Second
+
+// ParseWatchMode validates a watch_mode value ("" means auto).
+func ParseWatchMode(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", WatchModeAuto:
+		return WatchModeAuto, nil
+	case WatchModePoll:
+		return WatchModePoll, nil
+	default:
+		return "", fmt.Errorf("invalid watch_mode %q (expected auto|poll)", s)
+	}
+}
+
+// ParsePollInterval parses a Go duration such as "500ms" or "2s"
+// ("" means DefaultPollInterval). Intervals under 50ms are rejected.
+func ParsePollInterval(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return DefaultPollInterval, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid poll_interval %q: %w", s, err)
+	}
+	if d < 50*time.Millisecond {
+		return 0, fmt.Errorf("invalid poll_interval %q: must be at least 50ms", s)
+	}
+	return d, nil
+}
+
+// PollWatcher detects changes by periodically walking the tree and comparing
+// modification times and sizes. It works where filesystem events are not
+// delivered (NFS, Docker bind mounts, some VMs).
+type PollWatcher struct {
+	Root     string
+	Globs    []string
+	Interval time.Duration
+	// Ignore skips matching files and directories (typically .gitignore).
+	Ignore *IgnoreMatcher
+}
+
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+// Run blocks until ctx is done, sending on changed (non-blocking) whenever a
+// matching file is added, removed, or modified.
+func (w PollWatcher) Run(ctx context.Context, changed chan<- struct{}) error {
+	return w.poll(ctx, func([]string) bool {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+		return true
+	})
+}
+
+// RunFiles is like Run but sends the changed paths (relative to Root,
+// slash-separated, sorted). The send blocks, so changes made while the
+// receiver is busy are reported on the next poll.
+func (w PollWatcher) RunFiles(ctx context.Context, changed chan<- []string) error {
+	return w.poll(ctx, func(files []string) bool {
+		select {
+		case changed <- files:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
+
+// poll snapshots the tree every Interval and calls onChange with the changed
+// paths until ctx is done or onChange returns false.
+func (w PollWatcher) poll(ctx context.Context, onChange func([]string) bool) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	prev, err := w.Snapshot()
+	if err != nil {
+		return err
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+		cur, err := w.Snapshot()
+		if err != nil {
+			continue
+		}
+		if files := changedFiles(prev, cur); len(files) > 0 && !onChange(files) {
+			return nil
+		}
+		prev = cur
+	}
+}
+
+// Snapshot records the stamp of every file under Root matching Globs.
+// Hidden directories (including .git and .rig), vendor, node_modules, and
+// paths matched by Ignore are skipped.
+func (w PollWatcher) Snapshot() (map[string]fileStamp, error) {
+	out := map[string]fileStamp{}
+	err := filepath.WalkDir(w.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == w.Root {
+				return err
+			}
+			return nil
+		}
+		if p == w.Root {
+			return nil
+		}
+		rel, err := filepath.Rel(w.Root, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") || d.Name() == "vendor" || d.Name() == "node_modules" || w.Ignore.Match(rel, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if w.Ignore.Match(rel, false) || !MatchWatchGlobs(w.Globs, rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		out[rel] = fileStamp{mod: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return out, err
+}
+
+// changedFiles lists paths added, removed, or modified between a and b.
+func changedFiles(a, b map[string]fileStamp) []string {
+	var out []string
+	for k, v := range b {
+		if o, ok := a[k]; !ok || !o.mod.Equal(v.mod) || o.size != v.size {
+			out = append(out, k)
+		}
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// MatchWatchGlobs reports whether rel (slash-separated) matches any watch
+// entry. "." matches everything; "**" matches any number of path segments; a
+// pattern without wildcards also matches everything beneath it.
+func MatchWatchGlobs(globs []string, rel string) bool {
+	for _, g := range globs {
+		g = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(g)), "./")
+		if g == "" {
+			continue
+		}
+		if g == "." || g == "**" {
+			return true
+		}
+		if !strings.ContainsAny(g, "*?[") {
+			g = strings.TrimSuffix(g, "/")
+			if rel == g || strings.HasPrefix(rel, g+"/") {
+				return true
+			}
+			continue
+		}
+		if matchGlobSegments(strings.Split(g, "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlobSegments(pat, parts []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchGlobSegments(pat[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], parts[0]); !ok {
+			return false
+		}
+		pat, parts = pat[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
@@ -0,0 +1,77 @@
+package rig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMatchWatchGlobs(t *testing.T) {
+	cases := []struct {
+		globs []string
+		rel   string
+		want  bool
+	}{
+		{[]string{"**/*.go"}, "main.go", true},
+		{[]string{"**/*.go"}, "internal/x/y.go", true},
+		{[]string{"**/*.go"}, "README.md", false},
+		{[]string{"cmd/*.go"}, "cmd/main.go", true},
+		{[]string{"cmd/*.go"}, "cmd/sub/main.go", false},
+		{[]string{"web"}, "web/static/app.js", true},
+		{[]string{"."}, "anything/at/all", true},
+	}
+	for _, c := range cases {
+		if got := MatchWatchGlobs(c.globs, c.rel); got != c.want {
+			t.Fatalf("MatchWatchGlobs(%v, %q)=%t, want %t", c.globs, c.rel, got, c.want)
+		}
+	}
+}
+
+func TestPollWatcherDetectsChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "main.go"), "package main\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, ".git", "HEAD"), "ref\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, ".gitignore"), "gen/\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "gen", "zz_generated.go"), "package gen\n", 0o644)
+
+	w := PollWatcher{Root: dir, Globs: []string{"**/*.go"}, Interval: 50 * time.Millisecond, Ignore: LoadIgnoreMatcher(dir, true, nil)}
+	snap, err := w.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if len(snap) != 1 {
+		t.Fatalf("snapshot=%v", snap)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	go func() { _ = w.Run(ctx, changed) }()
+
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected change to be detected")
+	}
+}
+
+func TestParseWatchModeAndInterval(t *testing.T) {
+	if m, err := ParseWatchMode(""); err != nil || m != WatchModeAuto {
+		t.Fatalf("ParseWatchMode(\"\")=%q, %v", m, err)
+	}
+	if _, err := ParseWatchMode("inotify"); err == nil {
+		t.Fatalf("expected invalid watch_mode error")
+	}
+	if d, err := ParsePollInterval("250ms"); err != nil || d != 250*time.Millisecond {
+		t.Fatalf("ParsePollInterval=%v, %v", d, err)
+	}
+	if _, err := ParsePollInterval("1ms"); err == nil {
+		t.Fatalf("expected too-small interval error")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package rig
+
+import "os"
+
+// processAlive reports whether a process with pid exists. On Windows
+// FindProcess opens a handle and fails when there is no such process.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
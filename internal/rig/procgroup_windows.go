@@ -0,0 +1,16 @@
+//go:build windows
+
+package rig
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows.
+func setProcessGroup(*exec.Cmd) {}
+
+// signalProcessGroup kills p; Windows has no process groups to signal.
+func signalProcessGroup(p *os.Process, _ os.Signal) error {
+	return p.Kill()
+}
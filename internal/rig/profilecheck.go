@@ -0,0 +1,113 @@
+package rig
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// raceTargets are the GOOS/GOARCH pairs `go build -race` supports.
+var raceTargets = []string{
+	"darwin/amd64", "darwin/arm64", "freebsd/amd64", "linux/amd64", "linux/arm64",
+	"linux/loong64", "linux/ppc64le", "linux/s390x", "netbsd/amd64", "windows/amd64",
+}
+
+// profileFlag is one go flag from a profile's flags: "-o" with its value,
+// whether written "-o=bin/app" or as "-o", "bin/app".
+type profileFlag struct {
+	name  string
+	value string
+}
+
+// profileValueFlags are the go build flags that take a value, so that
+// "-o", "bin/app" in flags reads as one flag.
+var profileValueFlags = []string{"o", "tags", "ldflags", "gcflags", "asmflags", "mod", "modfile", "overlay", "pgo", "p", "pkgdir", "toolexec", "buildmode", "compiler", "installsuffix", "covermode", "coverpkg"}
+
+func parseProfileFlags(flags []string) []profileFlag {
+	var out []profileFlag
+	for i := 0; i < len(flags); i++ {
+		f := strings.TrimSpace(flags[i])
+		if !strings.HasPrefix(f, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(f, "-"), "=")
+		if !hasValue && slices.Contains(profileValueFlags, name) && i+1 < len(flags) {
+			i++
+			value = strings.TrimSpace(flags[i])
+		}
+		out = append(out, profileFlag{name: name, value: value})
+	}
+	return out
+}
+
+// ProfileWarnings reports settings in [profile.<name>] that conflict with
+// each other, so they surface before go is run: a flag given twice, a flag
+// in flags that replaces the profile's own field (output, tags, ldflags,
+// gcflags), and -race for a target it cannot build.
+func ProfileWarnings(name string, p cfg.BuildProfile) []string {
+	var warns []string
+	warn := func(format string, args ...any) {
+		warns = append(warns, fmt.Sprintf("profile.%s: ", name)+fmt.Sprintf(format, args...))
+	}
+	flags := parseProfileFlags(p.Flags)
+	counts := map[string]int{}
+	for _, f := range flags {
+		counts[f.name]++
+	}
+	for _, f := range flags {
+		if counts[f.name] > 1 {
+			warn("flags sets -%s %d times; go uses the last one", f.name, counts[f.name])
+			counts[f.name] = 0
+		}
+	}
+
+	fields := []struct{ flag, field, value string }{
+		{"o", "output", p.Output},
+		{"ldflags", "ldflags", p.Ldflags},
+		{"gcflags", "gcflags", p.Gcflags},
+	}
+	for _, fd := range fields {
+		if fd.value != "" && slices.ContainsFunc(flags, func(f profileFlag) bool { return f.name == fd.flag }) {
+			warn("%s and flags both set -%s; go uses the one in flags", fd.field, fd.flag)
+		}
+	}
+	if len(p.Tags) > 0 {
+		for _, f := range flags {
+			if f.name != "tags" {
+				continue
+			}
+			inFlags := strings.Split(f.value, ",")
+			var dup, dropped []string
+			for _, t := range p.Tags {
+				if slices.Contains(inFlags, t) {
+					dup = append(dup, t)
+				} else {
+					dropped = append(dropped, t)
+				}
+			}
+			if len(dup) > 0 {
+				warn("tag(s) %s set in both tags and flags", strings.Join(dup, ", "))
+			}
+			if len(dropped) > 0 {
+				warn("-tags in flags replaces tags; %s not applied", strings.Join(dropped, ", "))
+			}
+		}
+	}
+
+	if slices.ContainsFunc(flags, func(f profileFlag) bool { return f.name == "race" }) {
+		goos, goarch := firstNonEmpty(p.Env["GOOS"], runtime.GOOS), firstNonEmpty(p.Env["GOARCH"], runtime.GOARCH)
+		target := goos + "/" + goarch
+		switch {
+		case !slices.Contains(raceTargets, target):
+			warn("-race is not supported when building for %s", target)
+		case p.Env["CGO_ENABLED"] == "0":
+			warn("-race requires cgo, but env sets CGO_ENABLED=0")
+		case (goos != runtime.GOOS || goarch != runtime.GOARCH) && p.Env["CGO_ENABLED"] != "1":
+			warn("-race requires cgo, which go disables when cross-compiling to %s; set CGO_ENABLED=1 and CC in env", target)
+		}
+	}
+	return warns
+}
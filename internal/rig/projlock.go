@@ -0,0 +1,145 @@
+package rig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProjectLockHolder is the rig process recorded in .rig/lock.
+type ProjectLockHolder struct {
+	PID    int
+	Action string // e.g. "syncing"
+	Since  time.Time
+}
+
+// ProjectLockedError reports that another live rig process holds the
+// project lock.
+type ProjectLockedError struct {
+	Holder ProjectLockHolder
+}
+
+func (e *ProjectLockedError) Error() string {
+	return fmt.Sprintf("another rig process (PID %d) is %s since %s; wait for it to finish or pass --wait", e.Holder.PID, e.Holder.Action, e.Holder.Since.Format(time.Kitchen))
+}
+
+// projectLockPoll is how often a waiting process retries; swappable for tests.
+var projectLockPoll = 200 * time.Millisecond
+
+// ProjectLockPath is the advisory lock rig takes while it writes .rig/bin or
+// rig.lock for the project whose config is at configPath.
+func ProjectLockPath(configPath string) string {
+	return filepath.Join(CacheDir(configPath), "lock")
+}
+
+// AcquireProjectLock takes the project lock for action ("syncing", ...). When
+// another live rig process holds it, it fails with *ProjectLockedError, or
+// with wait set calls onWait once and retries until the lock is free. A lock
+// left behind by a process that no longer exists is taken over. The returned
+// release removes the lock.
+func AcquireProjectLock(configPath, action string, wait bool, onWait func(ProjectLockHolder)) (release func(), err error) {
+	path := ProjectLockPath(configPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	notified := false
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, werr := fmt.Fprintf(f, "%d\n%s\n%s\n", os.Getpid(), action, time.Now().UTC().Format(time.RFC3339))
+			if cerr := f.Close(); werr == nil {
+				werr = cerr
+			}
+			if werr != nil {
+				_ = os.Remove(path)
+				return nil, werr
+			}
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("create %s: %w", path, err)
+		}
+		holder, held, raw := projectLockState(path)
+		if !held {
+			// Stale: the holder exited without cleaning up. Only remove the
+			// file we inspected, not one another process just created.
+			if cur, err := os.ReadFile(path); err == nil && bytes.Equal(cur, raw) {
+				_ = os.Remove(path)
+			}
+			continue
+		}
+		if !wait {
+			return nil, &ProjectLockedError{Holder: holder}
+		}
+		if !notified && onWait != nil {
+			onWait(holder)
+			notified = true
+		}
+		time.Sleep(projectLockPoll)
+	}
+}
+
+// ProjectLockHeld reports the live process holding the project lock, if any.
+// A lock whose process has exited is not held.
+func ProjectLockHeld(configPath string) (ProjectLockHolder, bool) {
+	holder, held, _ := projectLockState(ProjectLockPath(configPath))
+	return holder, held
+}
+
+func projectLockState(path string) (ProjectLockHolder, bool, []byte) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return ProjectLockHolder{}, false, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ProjectLockHolder{}, false, nil
+	}
+	holder, ok := parseProjectLock(string(b))
+	if !ok {
+		// Being written right now, or garbage left by a crash.
+		return ProjectLockHolder{Action: "busy", Since: st.ModTime()}, time.Since(st.ModTime()) < 5*time.Second, b
+	}
+	if holder.PID != os.Getpid() && !processAlive(holder.PID) {
+		return holder, false, b
+	}
+	return holder, true, b
+}
+
+// WaitProjectUnlocked blocks until no live process holds the project lock,
+// calling onWait once if it has to wait.
+func WaitProjectUnlocked(configPath string, onWait func(ProjectLockHolder)) {
+	notified := false
+	for {
+		holder, held := ProjectLockHeld(configPath)
+		if !held {
+			return
+		}
+		if !notified && onWait != nil {
+			onWait(holder)
+			notified = true
+		}
+		time.Sleep(projectLockPoll)
+	}
+}
+
+func parseProjectLock(s string) (ProjectLockHolder, bool) {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) < 3 {
+		return ProjectLockHolder{}, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil || pid <= 0 {
+		return ProjectLockHolder{}, false
+	}
+	since, err := time.Parse(time.RFC3339, strings.TrimSpace(lines[2]))
+	if err != nil {
+		return ProjectLockHolder{}, false
+	}
+	return ProjectLockHolder{PID: pid, Action: strings.TrimSpace(lines[1]), Since: since.Local()}, true
+}
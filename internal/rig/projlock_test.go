@@ -0,0 +1,61 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProjectLock(t *testing.T) {
+	orig := projectLockPoll
+	projectLockPoll = 10 * time.Millisecond
+	t.Cleanup(func() { projectLockPoll = orig })
+	confPath := filepath.Join(t.TempDir(), "rig.toml")
+
+	release, err := AcquireProjectLock(confPath, "syncing", false, nil)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	holder, held := ProjectLockHeld(confPath)
+	if !held || holder.PID != os.Getpid() || holder.Action != "syncing" {
+		t.Fatalf("holder=%+v held=%v", holder, held)
+	}
+	var locked *ProjectLockedError
+	if _, err := AcquireProjectLock(confPath, "syncing", false, nil); !errors.As(err, &locked) || locked.Holder.PID != os.Getpid() {
+		t.Fatalf("expected ProjectLockedError, got %v", err)
+	}
+
+	// --wait blocks until the holder releases.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		release()
+	}()
+	waited := false
+	release2, err := AcquireProjectLock(confPath, "fixing tools", true, func(ProjectLockHolder) { waited = true })
+	if err != nil || !waited {
+		t.Fatalf("wait acquire: err=%v waited=%v", err, waited)
+	}
+	release2()
+	if _, held := ProjectLockHeld(confPath); held {
+		t.Fatalf("lock still held after release")
+	}
+
+	// A lock left by a process that has exited is taken over.
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("run child: %v", err)
+	}
+	stale := fmt.Sprintf("%d\nsyncing\n%s\n", cmd.Process.Pid, time.Now().UTC().Format(time.RFC3339))
+	if err := os.WriteFile(ProjectLockPath(confPath), []byte(stale), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	release3, err := AcquireProjectLock(confPath, "syncing", false, nil)
+	if err != nil {
+		t.Fatalf("expected stale lock takeover, got %v", err)
+	}
+	release3()
+}
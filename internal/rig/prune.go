@@ -0,0 +1,116 @@
+package rig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BinExtras lists files in .rig/bin that do not belong to any tool in lock,
+// sorted by name. File names are returned as they appear on disk. The rig
+// binary itself, which `rig bundle install` puts there, is never an extra.
+func BinExtras(configPath string, lock Lockfile) ([]string, error) {
+	keep := map[string]struct{}{BundleRig: {}}
+	for _, lt := range lock.Tools {
+		bin := strings.TrimSpace(lt.Bin)
+		if bin == "" {
+			name, _, err := ParseRequested(lt.Requested)
+			if err != nil {
+				return nil, err
+			}
+			bin = ResolveToolIdentity(name).Bin
+		}
+		keep[normalizeExeNameForMatch(bin)] = struct{}{}
+	}
+
+	entries, err := os.ReadDir(localBinDirForConfig(configPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var extras []string
+	for _, ent := range entries {
+		if ent.IsDir() {
+			continue
+		}
+		if _, ok := keep[normalizeExeNameForMatch(ent.Name())]; !ok {
+			extras = append(extras, ent.Name())
+		}
+	}
+	sort.Strings(extras)
+	return extras, nil
+}
+
+// PruneBins removes the named files (as returned by BinExtras) from .rig/bin.
+func PruneBins(configPath string, names []string) error {
+	binDir := localBinDirForConfig(configPath)
+	for _, name := range names {
+		if name != filepath.Base(name) || name == "." || name == ".." {
+			return fmt.Errorf("refusing to prune %q: not a file name", name)
+		}
+		if err := os.Remove(filepath.Join(binDir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("prune %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// StaleBin is a file in .rig/bin that rig.lock does not account for, with a
+// one-line reason.
+type StaleBin struct {
+	Bin    string `json:"bin"`
+	Reason string `json:"reason"`
+}
+
+// StaleBins compares .rig/bin against rig.lock. Files no locked tool claims
+// are orphaned; locked binaries whose sha256 differs are stale, and if they
+// predate rig.lock's last write they were most likely installed for an
+// earlier lock (e.g. on another branch). `rig sync --prune` fixes both.
+func StaleBins(configPath string, lock Lockfile) ([]StaleBin, error) {
+	var lockTime time.Time
+	if info, err := os.Stat(rigLockPathForConfig(configPath)); err == nil {
+		lockTime = info.ModTime()
+	}
+
+	var out []StaleBin
+	for _, lt := range lock.Tools {
+		bin := strings.TrimSpace(lt.Bin)
+		if bin == "" {
+			name, _, err := ParseRequested(lt.Requested)
+			if err != nil {
+				return nil, err
+			}
+			bin = ResolveToolIdentity(name).Bin
+		}
+		path := ToolBinPath(configPath, bin)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // missing binaries are reported by CheckInstalledTools
+		}
+		got, err := ComputeFileSHA256(path)
+		if err != nil || got == strings.TrimSpace(lt.SHA256) {
+			continue
+		}
+		reason := "sha256 differs from rig.lock; modified after rig.lock was written"
+		if !lockTime.IsZero() && info.ModTime().Before(lockTime) {
+			reason = fmt.Sprintf("sha256 differs from rig.lock and predates it (%s < %s); installed for an earlier rig.lock, e.g. on another branch",
+				info.ModTime().Format(time.DateTime), lockTime.Format(time.DateTime))
+		}
+		out = append(out, StaleBin{Bin: filepath.Base(path), Reason: reason})
+	}
+
+	extras, err := BinExtras(configPath, lock)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range extras {
+		out = append(out, StaleBin{Bin: name, Reason: "orphaned: no tool in rig.lock installs it (removed tool or another branch)"})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Bin < out[j].Bin })
+	return out, nil
+}
@@ -0,0 +1,96 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBinExtrasAndPrune(t *testing.T) {
+	dir := setupToolsFixture(t)
+	configPath := filepath.Join(dir, "rig.toml")
+	binDir := filepath.Join(dir, ".rig", "bin")
+	writeTestFile(t, filepath.Join(binDir, "stale-tool"), "#!/bin/sh\n", 0o755)
+	writeTestFile(t, filepath.Join(binDir, "another"), "#!/bin/sh\n", 0o755)
+	if err := os.MkdirAll(filepath.Join(binDir, "subdir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := ReadLockfile(filepath.Join(dir, "rig.lock"))
+	if err != nil {
+		t.Fatalf("read lock: %v", err)
+	}
+	extras, err := BinExtras(configPath, lock)
+	if err != nil {
+		t.Fatalf("BinExtras: %v", err)
+	}
+	if want := []string{"another", "stale-tool"}; !reflect.DeepEqual(extras, want) {
+		t.Fatalf("extras = %v, want %v", extras, want)
+	}
+
+	if err := PruneBins(configPath, extras); err != nil {
+		t.Fatalf("PruneBins: %v", err)
+	}
+	for _, name := range []string{"mockery", "golangci-lint", "subdir"} {
+		if _, err := os.Stat(filepath.Join(binDir, name)); err != nil {
+			t.Fatalf("%s should be kept: %v", name, err)
+		}
+	}
+	extras, err = BinExtras(configPath, lock)
+	if err != nil || len(extras) != 0 {
+		t.Fatalf("after prune extras = %v, err = %v", extras, err)
+	}
+
+	if err := PruneBins(configPath, []string{"../rig.toml"}); err == nil {
+		t.Fatal("expected error for path outside .rig/bin")
+	}
+}
+
+func TestStaleBins(t *testing.T) {
+	dir := setupToolsFixture(t)
+	configPath := filepath.Join(dir, "rig.toml")
+	binDir := filepath.Join(dir, ".rig", "bin")
+	lockPath := filepath.Join(dir, "rig.lock")
+	lock, err := ReadLockfile(lockPath)
+	if err != nil {
+		t.Fatalf("read lock: %v", err)
+	}
+
+	stale, err := StaleBins(configPath, lock)
+	if err != nil || len(stale) != 0 {
+		t.Fatalf("fresh fixture: stale = %v, err = %v", stale, err)
+	}
+
+	// mockery was installed for an older lock; golangci-lint was edited after
+	// the lock was written; old-tool belongs to no locked tool.
+	writeTestFile(t, filepath.Join(binDir, "mockery"), "#!/bin/sh\necho other\n", 0o755)
+	writeTestFile(t, filepath.Join(binDir, "golangci-lint"), "#!/bin/sh\necho edited\n", 0o755)
+	writeTestFile(t, filepath.Join(binDir, "old-tool"), "#!/bin/sh\n", 0o755)
+	now := time.Now()
+	if err := os.Chtimes(filepath.Join(binDir, "mockery"), now.Add(-time.Hour), now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(lockPath, now.Add(-time.Minute), now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, err = StaleBins(configPath, lock)
+	if err != nil {
+		t.Fatalf("StaleBins: %v", err)
+	}
+	var got []string
+	for _, s := range stale {
+		got = append(got, s.Bin)
+	}
+	if want := []string{"golangci-lint", "mockery", "old-tool"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("stale = %v, want %v", got, want)
+	}
+	for i, want := range []string{"modified after rig.lock", "predates it", "orphaned"} {
+		if !strings.Contains(stale[i].Reason, want) {
+			t.Errorf("%s reason = %q, want it to mention %q", stale[i].Bin, stale[i].Reason, want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package rig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ReleaseArtifact is one archive of a release: <name>_<os>_<arch>.tar.gz
+// (or .zip), the naming `rig upgrade` expects.
+type ReleaseArtifact struct {
+	File   string `json:"file"`
+	OS     string `json:"os"`
+	Arch   string `json:"arch"`
+	SHA256 string `json:"sha256"`
+}
+
+// ReleaseMeta describes a release for package manager manifests.
+type ReleaseMeta struct {
+	Name        string
+	Version     string // without a leading "v"
+	Description string
+	Homepage    string
+	License     string
+	// URL is the download location of the archives; "{version}" is replaced
+	// by Version and the archive name is appended, e.g.
+	// https://github.com/me/app/releases/download/v{version}.
+	URL       string
+	Artifacts []ReleaseArtifact
+}
+
+// releaseArches are the architectures release archives are built for.
+var releaseArches = []string{"amd64", "arm64", "386", "armv7", "armv6", "arm"}
+
+// ScanReleaseArtifacts finds name's release archives in dist. Each sum comes
+// from its <archive>.sha256 file, which must match the archive, or is
+// computed when there is none. Variants such as _musl builds are skipped.
+func ScanReleaseArtifacts(dist, name string) ([]ReleaseArtifact, error) {
+	entries, err := os.ReadDir(dist)
+	if err != nil {
+		return nil, err
+	}
+	var out []ReleaseArtifact
+	for _, e := range entries {
+		format := ArchiveFormat(e.Name())
+		if e.IsDir() || format == "" {
+			continue
+		}
+		base := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(e.Name(), ".zip"), ".tgz"), ".tar.gz")
+		rest, ok := strings.CutPrefix(base, name+"_")
+		if !ok {
+			continue
+		}
+		goos, arch, ok := strings.Cut(rest, "_")
+		if !ok || !slices.Contains(releaseArches, arch) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dist, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		a := ReleaseArtifact{File: e.Name(), OS: goos, Arch: arch, SHA256: hex.EncodeToString(sum[:])}
+		if b, err := os.ReadFile(filepath.Join(dist, e.Name()+".sha256")); err == nil {
+			want, err := parseChecksumFile(e.Name(), b)
+			if err != nil {
+				return nil, fmt.Errorf("%s.sha256: %w", e.Name(), err)
+			}
+			if err := verifySHA256(e.Name(), data, want); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, a)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no %s_<os>_<arch>.tar.gz or .zip archives in %s", name, dist)
+	}
+	return out, nil
+}
+
+func (m ReleaseMeta) url(a ReleaseArtifact) string {
+	return strings.TrimSuffix(strings.ReplaceAll(m.URL, "{version}", m.Version), "/") + "/" + a.File
+}
+
+func (m ReleaseMeta) artifact(goos, arch string) (ReleaseArtifact, bool) {
+	for _, a := range m.Artifacts {
+		if a.OS == goos && a.Arch == arch {
+			return a, true
+		}
+	}
+	return ReleaseArtifact{}, false
+}
+
+// HomebrewFormula renders a formula installing the macOS and Linux archives.
+func HomebrewFormula(m ReleaseMeta) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "class %s < Formula\n", formulaClass(m.Name))
+	if m.Description != "" {
+		fmt.Fprintf(&b, "  desc %q\n", m.Description)
+	}
+	if m.Homepage != "" {
+		fmt.Fprintf(&b, "  homepage %q\n", m.Homepage)
+	}
+	fmt.Fprintf(&b, "  version %q\n", m.Version)
+	if m.License != "" {
+		fmt.Fprintf(&b, "  license %q\n", m.License)
+	}
+	found := false
+	for _, goos := range []string{"darwin", "linux"} {
+		var blocks []string
+		for _, arch := range [][2]string{{"amd64", "on_intel"}, {"arm64", "on_arm"}} {
+			a, ok := m.artifact(goos, arch[0])
+			if !ok {
+				continue
+			}
+			blocks = append(blocks, fmt.Sprintf("    %s do\n      url %q\n      sha256 %q\n    end\n", arch[1], m.url(a), a.SHA256))
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		found = true
+		block := "on_macos"
+		if goos == "linux" {
+			block = "on_linux"
+		}
+		fmt.Fprintf(&b, "\n  %s do\n%s  end\n", block, strings.Join(blocks, "\n"))
+	}
+	if !found {
+		return "", errors.New("homebrew: no darwin or linux amd64/arm64 archives")
+	}
+	fmt.Fprintf(&b, "\n  def install\n    bin.install %q\n  end\n", m.Name)
+	fmt.Fprintf(&b, "\n  test do\n    system \"#{bin}/%s\", \"--version\"\n  end\nend\n", m.Name)
+	return b.String(), nil
+}
+
+// formulaClass turns "golangci-lint" into the class name "GolangciLint".
+func formulaClass(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ScoopManifest renders a Scoop manifest for the Windows archives.
+func ScoopManifest(m ReleaseMeta) ([]byte, error) {
+	type scoopArch struct {
+		URL  string `json:"url"`
+		Hash string `json:"hash"`
+	}
+	manifest := struct {
+		Version      string               `json:"version"`
+		Description  string               `json:"description,omitempty"`
+		Homepage     string               `json:"homepage,omitempty"`
+		License      string               `json:"license,omitempty"`
+		Architecture map[string]scoopArch `json:"architecture"`
+		Bin          string               `json:"bin"`
+	}{Version: m.Version, Description: m.Description, Homepage: m.Homepage, License: m.License, Architecture: map[string]scoopArch{}, Bin: m.Name + ".exe"}
+	for arch, key := range map[string]string{"amd64": "64bit", "386": "32bit", "arm64": "arm64"} {
+		if a, ok := m.artifact("windows", arch); ok {
+			manifest.Architecture[key] = scoopArch{URL: m.url(a), Hash: a.SHA256}
+		}
+	}
+	if len(manifest.Architecture) == 0 {
+		return nil, errors.New("scoop: no windows archives")
+	}
+	b, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// aurArches maps GOARCH names to Arch Linux ones.
+var aurArches = [][2]string{{"amd64", "x86_64"}, {"arm64", "aarch64"}, {"armv7", "armv7h"}, {"armv6", "armv6h"}, {"386", "i686"}}
+
+// AURPKGBUILD renders a PKGBUILD for a <name>-bin AUR package installing
+// the Linux archives.
+func AURPKGBUILD(m ReleaseMeta) (string, error) {
+	var arches, sources []string
+	for _, am := range aurArches {
+		a, ok := m.artifact("linux", am[0])
+		if !ok {
+			continue
+		}
+		arches = append(arches, "'"+am[1]+"'")
+		sources = append(sources, fmt.Sprintf("source_%s=(\"%s-%s-%s.%s::%s\")\nsha256sums_%s=('%s')\n", am[1], m.Name, m.Version, am[1], ArchiveFormat(a.File), m.url(a), am[1], a.SHA256))
+	}
+	if len(arches) == 0 {
+		return "", errors.New("aur: no linux archives")
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "pkgname=%s-bin\npkgver=%s\npkgrel=1\n", m.Name, strings.ReplaceAll(m.Version, "-", "_"))
+	if m.Description != "" {
+		fmt.Fprintf(&b, "pkgdesc=%q\n", m.Description)
+	}
+	fmt.Fprintf(&b, "arch=(%s)\n", strings.Join(arches, " "))
+	if m.Homepage != "" {
+		fmt.Fprintf(&b, "url=%q\n", m.Homepage)
+	}
+	if m.License != "" {
+		fmt.Fprintf(&b, "license=('%s')\n", m.License)
+	}
+	fmt.Fprintf(&b, "provides=('%s')\nconflicts=('%s')\n\n%s\n", m.Name, m.Name, strings.Join(sources, ""))
+	fmt.Fprintf(&b, "package() {\n  install -Dm755 \"$srcdir/%s\" \"$pkgdir/usr/bin/%s\"\n}\n", m.Name, m.Name)
+	return b.String(), nil
+}
@@ -0,0 +1,53 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReleaseManifests(t *testing.T) {
+	dist := t.TempDir()
+	for _, name := range []string{"app_linux_amd64.tar.gz", "app_darwin_arm64.tar.gz", "app_windows_amd64.zip", "app_linux_amd64_musl.tar.gz", "other_linux_amd64.tar.gz"} {
+		writeTestFile(t, filepath.Join(dist, name), name, 0o644)
+	}
+	writeTestFile(t, filepath.Join(dist, "app_linux_amd64.tar.gz.sha256"), checksumLine("app_linux_amd64.tar.gz", []byte("app_linux_amd64.tar.gz")), 0o644)
+
+	arts, err := ScanReleaseArtifacts(dist, "app")
+	if err != nil {
+		t.Fatalf("ScanReleaseArtifacts: %v", err)
+	}
+	if len(arts) != 3 {
+		t.Fatalf("expected 3 archives (musl and other projects skipped), got %+v", arts)
+	}
+	m := ReleaseMeta{Name: "my-app", Version: "1.2.0", License: "MIT", URL: "https://example.com/dl/v{version}/", Artifacts: arts}
+	for i := range m.Artifacts {
+		m.Artifacts[i].File = strings.Replace(m.Artifacts[i].File, "app_", "my-app_", 1)
+	}
+
+	rb, err := HomebrewFormula(m)
+	if err != nil {
+		t.Fatalf("HomebrewFormula: %v", err)
+	}
+	for _, want := range []string{"class MyApp < Formula", `url "https://example.com/dl/v1.2.0/my-app_darwin_arm64.tar.gz"`, "on_linux do\n    on_intel do", `bin.install "my-app"`} {
+		if !strings.Contains(rb, want) {
+			t.Fatalf("formula missing %q:\n%s", want, rb)
+		}
+	}
+	scoop, err := ScoopManifest(m)
+	if err != nil || !strings.Contains(string(scoop), `"64bit"`) || !strings.Contains(string(scoop), `"bin": "my-app.exe"`) {
+		t.Fatalf("scoop manifest: %v\n%s", err, scoop)
+	}
+	pkg, err := AURPKGBUILD(m)
+	if err != nil || !strings.Contains(pkg, "pkgname=my-app-bin") || !strings.Contains(pkg, "arch=('x86_64')") || !strings.Contains(pkg, "source_x86_64=(\"my-app-1.2.0-x86_64.tar.gz::https://example.com/dl/v1.2.0/my-app_linux_amd64.tar.gz\")") {
+		t.Fatalf("PKGBUILD: %v\n%s", err, pkg)
+	}
+
+	if err := os.WriteFile(filepath.Join(dist, "app_darwin_arm64.tar.gz.sha256"), []byte(strings.Repeat("0", 64)+"  app_darwin_arm64.tar.gz\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ScanReleaseArtifacts(dist, "app"); err == nil || !strings.Contains(err.Error(), "checksum mismatch for app_darwin_arm64.tar.gz") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package rig
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReportFile is one entry of a `rig doctor --report` bundle. Redactions
+// counts the values RedactSecrets replaced.
+type ReportFile struct {
+	Name       string
+	Data       []byte
+	Redactions int
+}
+
+// reportLogLines caps how much of each .rig/logs file goes into a report.
+const reportLogLines = 200
+
+// BuildReport collects the files of a diagnostic bundle: versions, the
+// doctor output, rig.toml and rig.lock, `go env`, and the tail of each
+// .rig/logs file. configPath may be empty when there is no rig.toml. Every
+// file passes through RedactSecrets.
+func BuildReport(configPath, rigVersion, doctorText string) []ReportFile {
+	var files []ReportFile
+	add := func(name, text string) {
+		red, n := RedactSecrets(text)
+		files = append(files, ReportFile{Name: name, Data: []byte(red), Redactions: n})
+	}
+
+	goVer, err := execCapture("go", []string{"version"}, "", nil)
+	if err != nil {
+		goVer = "unavailable: " + err.Error()
+	}
+	add("versions.txt", fmt.Sprintf("rig: %s\ngo: %s\nos: %s\narch: %s\nbuilt with: %s\ngenerated: %s\n",
+		rigVersion, strings.TrimSpace(goVer), runtime.GOOS, runtime.GOARCH, runtime.Version(), time.Now().UTC().Format(time.RFC3339)))
+	add("doctor.txt", doctorText)
+	if out, err := execCapture("go", []string{"env"}, "", nil); err == nil {
+		add("go-env.txt", out)
+	}
+	if configPath == "" {
+		return files
+	}
+	if b, err := os.ReadFile(configPath); err == nil {
+		add("rig.toml", string(b))
+	}
+	if b, err := os.ReadFile(rigLockPathForConfig(configPath)); err == nil {
+		add("rig.lock", string(b))
+	}
+	logDir := TaskLogDir(configPath)
+	entries, _ := os.ReadDir(logDir)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if b, err := os.ReadFile(filepath.Join(logDir, name)); err == nil {
+			add("logs/"+name, tailLines(string(b), reportLogLines))
+		}
+	}
+	return files
+}
+
+func tailLines(s string, n int) string {
+	lines := strings.SplitAfter(s, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= n {
+		return s
+	}
+	return strings.Join(lines[len(lines)-n:], "")
+}
+
+// WriteReportArchive writes files as a .tar.gz at path, under a directory
+// named after the archive.
+func WriteReportArchive(path string, files []ReportFile) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	root := strings.TrimSuffix(filepath.Base(path), ".tar.gz")
+	now := time.Now()
+	for _, f := range files {
+		hdr := &tar.Header{Name: root + "/" + f.Name, Mode: 0o644, Size: int64(len(f.Data)), ModTime: now, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.Data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
+
+var (
+	secretKeyRe = regexp.MustCompile(`(?i)(token|secret|passw(or)?d|pwd|api[_-]?key|private[_-]?key|access[_-]?key|signing[_-]?key|auth|credential|session|cookie)`)
+	// quotedAssignRe matches KEY = "value" (TOML, including inline tables).
+	quotedAssignRe = regexp.MustCompile(`([A-Za-z0-9_.\-]+)("?\s*=\s*)("[^"]*"|'[^']*')`)
+	// bareAssignRe matches KEY=value (env output, logs).
+	bareAssignRe  = regexp.MustCompile(`([A-Za-z0-9_]+)=([^\s"']+)`)
+	urlUserinfoRe = regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.\-]*://)[^/\s:@]+(:[^/\s@]*)?@`)
+)
+
+// RedactSecrets replaces values that look secret: assignments whose key
+// mentions a token, secret, password, key, or credential, and user:password
+// parts of URLs. It returns the redacted text and how many values it
+// replaced.
+func RedactSecrets(text string) (string, int) {
+	n := 0
+	text = quotedAssignRe.ReplaceAllStringFunc(text, func(m string) string {
+		sub := quotedAssignRe.FindStringSubmatch(m)
+		if !secretKeyRe.MatchString(sub[1]) {
+			return m
+		}
+		n++
+		return sub[1] + sub[2] + `"<redacted>"`
+	})
+	text = bareAssignRe.ReplaceAllStringFunc(text, func(m string) string {
+		sub := bareAssignRe.FindStringSubmatch(m)
+		if !secretKeyRe.MatchString(sub[1]) || sub[2] == "<redacted>" {
+			return m
+		}
+		n++
+		return sub[1] + "=<redacted>"
+	})
+	text = urlUserinfoRe.ReplaceAllStringFunc(text, func(m string) string {
+		n++
+		return urlUserinfoRe.FindStringSubmatch(m)[1] + "<redacted>@"
+	})
+	return text, n
+}
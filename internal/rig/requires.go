@@ -0,0 +1,217 @@
+package rig
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// RequirementStatus reports whether a [requires] system prerequisite is met.
+type RequirementStatus struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
+	Path       string `json:"path,omitempty"`
+	Version    string `json:"version,omitempty"`
+	Status     string `json:"status"` // ok | missing | mismatch | unknown
+	Error      string `json:"error,omitempty"`
+	Hint       string `json:"hint,omitempty"`
+}
+
+// OK reports whether the requirement is satisfied.
+func (r RequirementStatus) OK() bool { return r.Status == "ok" }
+
+// requirementVersionArgs lists probe arguments for programs whose version flag
+// is not `--version`.
+var requirementVersionArgs = map[string][]string{
+	"go":      {"version"},
+	"kubectl": {"version", "--client"},
+	"java":    {"-version"},
+}
+
+// requirementLookPath and requirementProbe are swappable for tests.
+var (
+	requirementLookPath = exec.LookPath
+	requirementProbe    = func(path string, args []string) (string, error) {
+		return execCapture(path, args, "", nil)
+	}
+)
+
+var versionInOutput = regexp.MustCompile(`\d+(?:\.\d+){0,2}(?:-[0-9A-Za-z.]+)?`)
+
+// requirementInstallHints maps a prerequisite to per-OS install hints.
+// The "" key is the fallback for any OS.
+var requirementInstallHints = map[string]map[string]string{
+	"docker": {
+		"darwin":  "brew install --cask docker (or install Docker Desktop)",
+		"linux":   "see https://docs.docker.com/engine/install/ or use your distro package (e.g. apt install docker.io)",
+		"windows": "winget install Docker.DockerDesktop",
+	},
+	"make": {
+		"darwin":  "xcode-select --install (or brew install make)",
+		"linux":   "install build essentials (e.g. apt install make)",
+		"windows": "winget install GnuWin32.Make (or choco install make)",
+	},
+	"node": {
+		"darwin":  "brew install node",
+		"linux":   "install Node.js from https://nodejs.org or via your package manager",
+		"windows": "winget install OpenJS.NodeJS",
+	},
+	"git": {
+		"darwin":  "xcode-select --install (or brew install git)",
+		"linux":   "install git via your package manager (e.g. apt install git)",
+		"windows": "winget install Git.Git",
+	},
+	"protoc": {
+		"darwin":  "brew install protobuf",
+		"linux":   "install protobuf-compiler via your package manager",
+		"windows": "winget install protobuf",
+	},
+}
+
+// RequirementInstallHint returns an actionable install hint for name on goos.
+func RequirementInstallHint(name string, goos string) string {
+	if hints, ok := requirementInstallHints[name]; ok {
+		if h := hints[goos]; h != "" {
+			return h
+		}
+		if h := hints[""]; h != "" {
+			return h
+		}
+	}
+	return fmt.Sprintf("install %s and make sure it is on PATH", name)
+}
+
+// CheckRequirements validates the named [requires] entries (all of them when
+// names is empty). Names not declared in [requires] are checked for presence only.
+func CheckRequirements(reqs map[string]string, names []string) []RequirementStatus {
+	if len(names) == 0 {
+		for n := range reqs {
+			names = append(names, n)
+		}
+	}
+	seen := make(map[string]struct{}, len(names))
+	var uniq []string
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		if n == "" {
+			continue
+		}
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		uniq = append(uniq, n)
+	}
+	sort.Strings(uniq)
+
+	out := make([]RequirementStatus, 0, len(uniq))
+	for _, n := range uniq {
+		out = append(out, checkRequirement(n, strings.TrimSpace(reqs[n])))
+	}
+	return out
+}
+
+func checkRequirement(name string, constraint string) RequirementStatus {
+	if constraint == "" {
+		constraint = "*"
+	}
+	row := RequirementStatus{Name: name, Constraint: constraint}
+	path, err := requirementLookPath(name)
+	if err != nil {
+		row.Status = "missing"
+		row.Error = fmt.Sprintf("%s not found in PATH", name)
+		row.Hint = RequirementInstallHint(name, runtime.GOOS)
+		return row
+	}
+	row.Path = path
+
+	c, err := ParseVersionConstraint(constraint)
+	if err != nil {
+		row.Status = "unknown"
+		row.Error = err.Error()
+		return row
+	}
+	if len(c.terms) == 0 {
+		row.Status = "ok"
+		return row
+	}
+
+	args, ok := requirementVersionArgs[name]
+	if !ok {
+		args = []string{"--version"}
+	}
+	out, err := requirementProbe(path, args)
+	ver := versionInOutput.FindString(out)
+	if ver == "" {
+		row.Status = "unknown"
+		if err != nil {
+			row.Error = fmt.Sprintf("%s %s: %v", name, strings.Join(args, " "), err)
+		} else {
+			row.Error = fmt.Sprintf("could not determine %s version", name)
+		}
+		return row
+	}
+	row.Version = ver
+	allowed, err := c.Allows(ver)
+	if err != nil {
+		row.Status = "unknown"
+		row.Error = err.Error()
+		return row
+	}
+	if !allowed {
+		row.Status = "mismatch"
+		row.Error = fmt.Sprintf("%s %s does not satisfy %q", name, ver, constraint)
+		row.Hint = "upgrade: " + RequirementInstallHint(name, runtime.GOOS)
+		return row
+	}
+	row.Status = "ok"
+	return row
+}
+
+// RequirementsError is returned when system prerequisites are not met.
+type RequirementsError struct {
+	Failed []RequirementStatus
+}
+
+func (e *RequirementsError) Error() string {
+	var b strings.Builder
+	b.WriteString("system prerequisites not met:")
+	for _, r := range e.Failed {
+		fmt.Fprintf(&b, "\n  - %s (%s): %s", r.Name, r.Constraint, r.Error)
+		if r.Hint != "" {
+			fmt.Fprintf(&b, "\n    hint: %s", r.Hint)
+		}
+	}
+	return b.String()
+}
+
+// requirementsForTasks collects the requires of every task in order.
+func requirementsForTasks(tasks cfg.TasksMap, order []string) []string {
+	var names []string
+	for _, n := range order {
+		names = append(names, tasks[n].Requires...)
+	}
+	return names
+}
+
+// ensureRequirements returns a *RequirementsError when any named requirement fails.
+func ensureRequirements(reqs map[string]string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	var failed []RequirementStatus
+	for _, r := range CheckRequirements(reqs, names) {
+		if !r.OK() {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) > 0 {
+		return &RequirementsError{Failed: failed}
+	}
+	return nil
+}
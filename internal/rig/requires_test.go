@@ -0,0 +1,84 @@
+package rig
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func stubRequirementProbes(t *testing.T, installed map[string]string) {
+	t.Helper()
+	origLook, origProbe := requirementLookPath, requirementProbe
+	t.Cleanup(func() { requirementLookPath, requirementProbe = origLook, origProbe })
+	requirementLookPath = func(name string) (string, error) {
+		if _, ok := installed[name]; !ok {
+			return "", exec.ErrNotFound
+		}
+		return "/usr/bin/" + name, nil
+	}
+	requirementProbe = func(path string, args []string) (string, error) {
+		return installed[filepath.Base(path)], nil
+	}
+}
+
+func TestCheckRequirements(t *testing.T) {
+	stubRequirementProbes(t, map[string]string{
+		"docker": "Docker version 23.0.1, build a5ee5b1",
+		"make":   "GNU Make 4.3",
+		"node":   "v20.11.1",
+	})
+	rows := CheckRequirements(map[string]string{
+		"docker": ">=24",
+		"make":   "*",
+		"node":   ">=20",
+		"protoc": ">=3",
+	}, nil)
+
+	got := map[string]string{}
+	for _, r := range rows {
+		got[r.Name] = r.Status
+	}
+	want := map[string]string{"docker": "mismatch", "make": "ok", "node": "ok", "protoc": "missing"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("statuses=%v, want %v", got, want)
+	}
+	if rows[0].Name != "docker" || rows[0].Version != "23.0.1" || rows[0].Hint == "" {
+		t.Fatalf("unexpected docker row: %+v", rows[0])
+	}
+	if rows[3].Hint == "" {
+		t.Fatalf("expected install hint for missing protoc: %+v", rows[3])
+	}
+}
+
+func TestRunChecksTaskRequirementsBeforeExecuting(t *testing.T) {
+	stubRequirementProbes(t, map[string]string{})
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), `
+[requires]
+docker = ">=24"
+
+[tasks]
+up = { command = "docker compose up", requires = ["docker"] }
+`, 0o644)
+	writeTestFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+
+	conf, _, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if !reflect.DeepEqual(conf.Tasks["up"].Requires, []string{"docker"}) {
+		t.Fatalf("requires=%v", conf.Tasks["up"].Requires)
+	}
+
+	err = Run(dir, "up", nil)
+	var re *RequirementsError
+	if !errors.As(err, &re) {
+		t.Fatalf("expected RequirementsError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "docker not found in PATH") || !strings.Contains(err.Error(), "hint:") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package rig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// lastRun is .rig/last-run.json: what the previous `rig run` left undone, so
+// `rig run --failed` can replay just that.
+type lastRun struct {
+	Task    string    `json:"task"`
+	Args    []string  `json:"args,omitempty"`
+	Profile string    `json:"profile,omitempty"`
+	At      time.Time `json:"at"`
+	// Failed lists the command tasks that failed; Pending adds the tasks that
+	// never ran or did not finish (skipped, aborted, composite tasks with a
+	// failed step), in run order.
+	Failed  []string `json:"failed,omitempty"`
+	Pending []string `json:"pending,omitempty"`
+	// Dirs holds, per failed dirs task, the directories (relative to rig.toml)
+	// it failed in.
+	Dirs map[string][]string `json:"dirs,omitempty"`
+}
+
+func lastRunPath(configPath string) string {
+	return filepath.Join(CacheDir(configPath), "last-run.json")
+}
+
+func readLastRun(configPath string) (*lastRun, error) {
+	b, err := os.ReadFile(lastRunPath(configPath))
+	if err != nil {
+		return nil, err
+	}
+	var lr lastRun
+	if err := json.Unmarshal(b, &lr); err != nil {
+		return nil, fmt.Errorf("%s: %w", lastRunPath(configPath), err)
+	}
+	return &lr, nil
+}
+
+// recordLastRun writes what this run left undone to .rig/last-run.json and
+// points at `rig run --failed` when something failed. Best effort.
+func (r *taskRunner) recordLastRun(order []string, lr *lastRun) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := *lr
+	out.At = time.Now().UTC()
+	out.Failed, out.Pending, out.Dirs = nil, nil, nil
+	for _, name := range order {
+		tr, started := r.runs[name]
+		finished := false
+		if started {
+			select {
+			case <-tr.done:
+				finished = tr.err == nil
+			default:
+			}
+		}
+		if finished {
+			continue
+		}
+		out.Pending = append(out.Pending, name)
+		if tt, ran := r.timings[name]; ran && !tt.OK {
+			out.Failed = append(out.Failed, name)
+		}
+		if dirs, ok := r.failedDirs[name]; ok {
+			if out.Dirs == nil {
+				out.Dirs = map[string][]string{}
+			}
+			out.Dirs[name] = dirs
+		}
+	}
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return
+	}
+	path := lastRunPath(r.confPath)
+	if os.MkdirAll(filepath.Dir(path), 0o755) != nil || os.WriteFile(path, append(b, '\n'), 0o644) != nil {
+		return
+	}
+	if len(out.Failed) > 0 {
+		statusf(os.Stderr, "↻ rerun only what failed with 'rig run --failed'")
+	}
+}
+
+// RunFailed replays the tasks the previous `rig run` left undone (see
+// lastRun): failed tasks, and tasks skipped or never reached because of
+// them. Tasks that succeeded count as done, so they are not run again even as
+// dependencies; a dirs task runs only in the directories that failed. The
+// previous passthrough arguments and profile apply unless opts sets a profile.
+func RunFailed(ctx context.Context, startDir string, opts RunOptions) error {
+	conf, confPath, lock, err := loadRunnable(startDir)
+	if err != nil {
+		return err
+	}
+	lr, err := readLastRun(confPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return errors.New("no previous run recorded; run a task with 'rig run <task>' first")
+	}
+	if err != nil {
+		return err
+	}
+	if len(lr.Pending) == 0 {
+		statusf(os.Stdout, "✅ nothing failed in the last run (rig run %s)", lr.Task)
+		return nil
+	}
+	order, err := resolveTaskOrder(conf.Tasks, lr.Task)
+	if err != nil {
+		return err
+	}
+	var targets, done []string
+	for _, name := range order {
+		if slices.Contains(lr.Pending, name) {
+			targets = append(targets, name)
+		} else {
+			done = append(done, name)
+		}
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("the tasks that failed last time (%s) are no longer part of %q", strings.Join(lr.Pending, ", "), lr.Task)
+	}
+	if len(lr.Dirs) > 0 {
+		base := filepath.Dir(confPath)
+		opts.onlyDirs = map[string][]string{}
+		for name, dirs := range lr.Dirs {
+			for _, d := range dirs {
+				if !filepath.IsAbs(d) {
+					d = filepath.Join(base, filepath.FromSlash(d))
+				}
+				opts.onlyDirs[name] = append(opts.onlyDirs[name], d)
+			}
+		}
+	}
+	if opts.Profile == "" {
+		opts.Profile = lr.Profile
+	}
+	var passthrough []string
+	if slices.Contains(targets, lr.Task) {
+		passthrough = lr.Args
+	}
+	statusf(os.Stderr, "↻ rerunning %s (from 'rig run %s'; %d task(s) already succeeded)", strings.Join(targets, ", "), lr.Task, len(done))
+	opts.done = done
+	opts.record = &lastRun{Task: lr.Task, Args: lr.Args, Profile: opts.Profile}
+	return runTaskOrder(ctx, conf, confPath, lock, targets, targets, passthrough, opts)
+}
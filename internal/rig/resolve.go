@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 )
 
 type goModuleInfo struct {
@@ -20,8 +21,16 @@ type goModuleInfo struct {
 // ResolveLockedTools resolves a [tools] map from rig.toml into LockedTool facts.
 //
 // It does not write any files.
-// It performs deterministic resolution using `go list -m -json <module>@<requested>`.
+// It performs deterministic resolution using `go list -m -json <module>@<requested>`,
+// looking modules up concurrently; the result is sorted by tool name. Tools
+// aliased to an OCI repository resolve their tag to a registry digest instead.
 func ResolveLockedTools(tools map[string]string, workDir string, env []string) ([]LockedTool, error) {
+	return ResolveLockedToolsTimed(tools, workDir, env, nil)
+}
+
+// ResolveLockedToolsTimed is ResolveLockedTools recording each lookup under
+// SyncResolve in timings.
+func ResolveLockedToolsTimed(tools map[string]string, workDir string, env []string, timings *SyncTimings) ([]LockedTool, error) {
 	if len(tools) == 0 {
 		return nil, nil
 	}
@@ -32,32 +41,61 @@ func ResolveLockedTools(tools map[string]string, workDir string, env []string) (
 	}
 	sort.Strings(keys)
 
-	locked := make([]LockedTool, 0, len(keys))
 	for _, name := range keys {
-		reqVer := strings.TrimSpace(tools[name])
-		if reqVer == "" {
+		if strings.TrimSpace(tools[name]) == "" {
 			return nil, fmt.Errorf("tool %q: empty version is not allowed (use an explicit version or \"latest\")", name)
 		}
-		normalized := EnsureSemverPrefixV(reqVer)
-		id := ResolveToolIdentity(name)
-		resolvedVer, sum, err := goListModuleVersion(id.Module, normalized, workDir, env)
-		if err != nil {
-			return nil, fmt.Errorf("resolve %s@%s: %w", id.Module, normalized, err)
-		}
+	}
 
-		lt := LockedTool{
-			Kind:      "go-binary",
-			Requested: fmt.Sprintf("%s@%s", name, reqVer),
-			Resolved:  fmt.Sprintf("%s@%s", id.Module, resolvedVer),
-			Module:    id.Module,
-			Bin:       id.Bin,
-			Checksum:  strings.TrimSpace(sum),
+	// Each lookup is a separate `go list -m`, usually waiting on the module
+	// proxy rather than the CPU, so run a bounded number concurrently.
+	locked := make([]LockedTool, len(keys))
+	errs := make([]error, len(keys))
+	conc := min(len(keys), maxConcurrentResolves)
+	sem := make(chan struct{}, conc)
+	var wg sync.WaitGroup
+	for i, name := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			defer timings.Start(name, SyncResolve)()
+			reqVer := strings.TrimSpace(tools[name])
+			id := ResolveToolIdentity(name)
+			if id.OCI != "" {
+				locked[i], errs[i] = ResolveOCITool(name, id.OCI, reqVer, id.Bin)
+				return
+			}
+			normalized := EnsureSemverPrefixV(reqVer)
+			resolvedVer, sum, err := goListModuleVersion(id.Module, normalized, workDir, env)
+			if err != nil {
+				errs[i] = fmt.Errorf("resolve %s@%s: %w", id.Module, normalized, err)
+				return
+			}
+			locked[i] = LockedTool{
+				Kind:      "go-binary",
+				Requested: fmt.Sprintf("%s@%s", name, reqVer),
+				Resolved:  fmt.Sprintf("%s@%s", id.Module, resolvedVer),
+				Module:    id.Module,
+				Bin:       id.Bin,
+				Checksum:  strings.TrimSpace(sum),
+			}
+		}()
+	}
+	wg.Wait()
+	// Report the first failure in name order so errors are deterministic.
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
-		locked = append(locked, lt)
 	}
 	return locked, nil
 }
 
+// maxConcurrentResolves bounds parallel `go list -m` lookups in ResolveLockedTools.
+const maxConcurrentResolves = 8
+
 var goListModuleVersion = resolveGoModuleVersion
 
 func resolveGoModuleVersion(module, version, workDir string, env []string) (resolvedVersion string, sum string, err error) {
@@ -1,8 +1,10 @@
 package rig
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestResolveLockedToolsUsesModuleRootForResolution(t *testing.T) {
@@ -49,3 +51,37 @@ func TestResolveToolIdentityMajorSuffixBin(t *testing.T) {
 		t.Fatalf("bin=%q", id.Bin)
 	}
 }
+
+func TestResolveLockedToolsResolvesConcurrentlyInNameOrder(t *testing.T) {
+	old := goListModuleVersion
+	t.Cleanup(func() { goListModuleVersion = old })
+
+	// Every lookup blocks until all have started, so a serial resolver would
+	// deadlock; the timeout turns that into a failure.
+	tools := map[string]string{"b": "1.0.0", "a": "2.0.0"}
+	started := make(chan struct{}, len(tools))
+	release := make(chan struct{})
+	go func() {
+		for range tools {
+			<-started
+		}
+		close(release)
+	}()
+	goListModuleVersion = func(module, version, workDir string, env []string) (string, string, error) {
+		started <- struct{}{}
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+			return "", "", errors.New("lookups did not run concurrently")
+		}
+		if module == "b" {
+			return "", "", errors.New("boom")
+		}
+		return version, "", nil
+	}
+
+	_, err := ResolveLockedTools(tools, "", nil)
+	if err == nil || err.Error() != "resolve b@v1.0.0: boom" {
+		t.Fatalf("expected b's failure, got %v", err)
+	}
+}
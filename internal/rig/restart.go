@@ -0,0 +1,90 @@
+package rig
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Crash-loop defaults for [tasks.dev].max_restarts and restart_window.
+const (
+	DefaultMaxRestarts   = 5
+	DefaultRestartWindow = 10 * time.Second
+)
+
+// ParseRestartWindow parses a Go duration such as "30s"
+// ("" means DefaultRestartWindow).
+func ParseRestartWindow(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return DefaultRestartWindow, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid restart_window %q: %w", s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid restart_window %q: must be positive", s)
+	}
+	return d, nil
+}
+
+// CrashTracker counts consecutive failed starts. Failures older than Window
+// no longer count; Max <= 0 disables the limit.
+type CrashTracker struct {
+	Max    int
+	Window time.Duration
+	fails  []time.Time
+}
+
+// Fail records a failed start at now and reports whether the limit is reached.
+func (c *CrashTracker) Fail(now time.Time) bool {
+	if c.Max <= 0 {
+		return false
+	}
+	kept := c.fails[:0]
+	for _, t := range c.fails {
+		if now.Sub(t) < c.Window {
+			kept = append(kept, t)
+		}
+	}
+	c.fails = append(kept, now)
+	return len(c.fails) >= c.Max
+}
+
+// Reset forgets earlier failures, e.g. after a change-triggered restart.
+func (c *CrashTracker) Reset() {
+	c.fails = c.fails[:0]
+}
+
+// TailBuffer is an io.Writer that keeps only the last Max bytes written.
+type TailBuffer struct {
+	Max int
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *TailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.Max; b.Max > 0 && over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the retained output.
+func (b *TailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
+
+// Reset discards the retained output.
+func (b *TailBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = b.buf[:0]
+}
@@ -0,0 +1,59 @@
+package rig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCrashTrackerWindow(t *testing.T) {
+	c := CrashTracker{Max: 3, Window: 10 * time.Second}
+	t0 := time.Unix(1000, 0)
+	if c.Fail(t0) || c.Fail(t0.Add(time.Second)) {
+		t.Fatal("limit reached too early")
+	}
+	// The first failure falls out of the window, so this is only the second.
+	if c.Fail(t0.Add(10500 * time.Millisecond)) {
+		t.Fatal("failures outside the window should not count")
+	}
+	if !c.Fail(t0.Add(10800 * time.Millisecond)) {
+		t.Fatal("expected limit after 3 failures within the window")
+	}
+	c.Reset()
+	if c.Fail(t0.Add(13 * time.Second)) {
+		t.Fatal("Reset should forget earlier failures")
+	}
+
+	off := CrashTracker{Max: 0, Window: time.Second}
+	for i := 0; i < 10; i++ {
+		if off.Fail(t0) {
+			t.Fatal("max_restarts = 0 should disable the limit")
+		}
+	}
+}
+
+func TestParseRestartWindow(t *testing.T) {
+	if d, err := ParseRestartWindow(""); err != nil || d != DefaultRestartWindow {
+		t.Fatalf("default = %v, %v", d, err)
+	}
+	if d, err := ParseRestartWindow("30s"); err != nil || d != 30*time.Second {
+		t.Fatalf("30s = %v, %v", d, err)
+	}
+	for _, bad := range []string{"soon", "0s", "-1s"} {
+		if _, err := ParseRestartWindow(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestTailBufferKeepsLastBytes(t *testing.T) {
+	b := TailBuffer{Max: 5}
+	_, _ = b.Write([]byte("abc"))
+	_, _ = b.Write([]byte("defg"))
+	if got := b.String(); got != "cdefg" {
+		t.Fatalf("tail = %q, want %q", got, "cdefg")
+	}
+	b.Reset()
+	if b.String() != "" {
+		t.Fatal("Reset should clear the buffer")
+	}
+}
@@ -0,0 +1,77 @@
+package rig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// retryBackoffBase is the wait before the first retry; each later retry
+// waits twice as long, up to retryBackoffMax. Swappable for tests.
+var (
+	retryBackoffBase = time.Second
+	retryBackoffMax  = 30 * time.Second
+)
+
+// ParseTaskTimeout parses a task timeout Go duration such as "90s". Empty
+// returns 0 (no timeout).
+func ParseTaskTimeout(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must be positive", s)
+	}
+	return d, nil
+}
+
+// retryBackoff is how long to wait before retry n (0 for the first).
+func retryBackoff(n int) time.Duration {
+	d := retryBackoffBase
+	for range n {
+		if d *= 2; d >= retryBackoffMax {
+			return retryBackoffMax
+		}
+	}
+	return d
+}
+
+// runAttempts runs attempt under t's timeout and reruns it after a failure
+// up to t.Retries times, waiting retryBackoff in between. A canceled run is
+// not retried.
+func (r *taskRunner) runAttempts(name string, t cfg.Task, attempt func(ctx context.Context) error) error {
+	timeout, err := ParseTaskTimeout(t.Timeout)
+	if err != nil {
+		return fmt.Errorf("task %q: %w", name, err)
+	}
+	for n := 0; ; n++ {
+		ctx, cancel := r.ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(r.ctx, timeout)
+		}
+		err := attempt(ctx)
+		if err != nil && r.ctx.Err() == nil && ctx.Err() != nil {
+			err = fmt.Errorf("task %q timed out after %s", name, timeout)
+		}
+		cancel()
+		if err == nil || r.ctx.Err() != nil || n >= t.Retries {
+			return err
+		}
+		wait := retryBackoff(n)
+		statusf(os.Stderr, "↻ %v; retrying in %s (attempt %d of %d)", err, wait, n+2, t.Retries+1)
+		select {
+		case <-time.After(wait):
+		case <-r.ctx.Done():
+			return err
+		}
+	}
+}
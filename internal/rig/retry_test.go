@@ -0,0 +1,77 @@
+package rig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunTaskTimeoutKillsProcessTree(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "rig.toml"), `
+[tasks.hang]
+command = "sh -c 'sh -c \"sleep 1; touch leaked\" & sleep 30'"
+timeout = "200ms"
+stop_timeout = "100ms"
+`, 0o644)
+	writeTestFile(t, filepath.Join(root, "rig.lock"), "schema = 0\n", 0o644)
+
+	start := time.Now()
+	err := RunWith(context.Background(), root, "hang", nil, RunOptions{})
+	if err == nil || !strings.Contains(err.Error(), `task "hang" timed out after 200ms`) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("the command was not stopped at its timeout (took %s)", elapsed)
+	}
+	time.Sleep(1200 * time.Millisecond)
+	if _, err := os.Stat(filepath.Join(root, "leaked")); err == nil {
+		t.Fatal("a child process outlived the task's timeout")
+	}
+}
+
+func TestRunTaskRetries(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	defer func(d time.Duration) { retryBackoffBase = d }(retryBackoffBase)
+	retryBackoffBase = 10 * time.Millisecond
+
+	for _, c := range []struct {
+		retries  int
+		attempts int
+		ok       bool
+	}{{2, 3, true}, {1, 2, false}} {
+		root := t.TempDir()
+		writeTestFile(t, filepath.Join(root, "rig.toml"), `
+[tasks.flaky]
+command = "sh -c 'echo x >> attempts; test $(wc -l < attempts) -ge 3'"
+retries = `+string(rune('0'+c.retries))+`
+`, 0o644)
+		writeTestFile(t, filepath.Join(root, "rig.lock"), "schema = 0\n", 0o644)
+
+		err := RunWith(context.Background(), root, "flaky", nil, RunOptions{})
+		if (err == nil) != c.ok {
+			t.Fatalf("retries=%d: err = %v, want ok=%v", c.retries, err, c.ok)
+		}
+		b, _ := os.ReadFile(filepath.Join(root, "attempts"))
+		if got := strings.Count(string(b), "x"); got != c.attempts {
+			t.Fatalf("retries=%d: ran %d times, want %d", c.retries, got, c.attempts)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	for n, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second} {
+		if got := retryBackoff(n); got != want {
+			t.Fatalf("retryBackoff(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
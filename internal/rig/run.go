@@ -1,112 +1,697 @@
 package rig
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	cfg "github.com/divijg19/rig/internal/config"
 )
 
 func Run(startDir string, taskName string, passthrough []string) error {
-	conf, confPath, err := LoadConfig(startDir)
+	return RunWith(context.Background(), startDir, taskName, passthrough, RunOptions{})
+}
+
+// RunWith is Run with task inputs supplied or prompted for via opts. When
+// ctx is done (Ctrl+C, a timeout, a stopping scheduler) running commands are
+// interrupted, no further task starts, and the run fails with the cause.
+func RunWith(ctx context.Context, startDir string, taskName string, passthrough []string, opts RunOptions) error {
+	conf, confPath, lock, err := loadRunnable(startDir)
+	if err != nil {
+		return err
+	}
+
+	task, ok := conf.Tasks[taskName]
+	if !ok {
+		return WithCode(CodeTaskNotFound, fmt.Errorf("task %q not found%s", taskName, DidYouMean(taskName, TaskNames(conf.Tasks))))
+	}
+	if task.Command == "" && len(task.Steps) == 0 {
+		return fmt.Errorf("task %q missing command", taskName)
+	}
+
+	order, err := resolveTaskOrder(conf.Tasks, taskName)
 	if err != nil {
 		return err
 	}
+	opts.record = &lastRun{Task: taskName, Args: passthrough, Profile: opts.Profile}
+	return runTaskOrder(ctx, conf, confPath, lock, order, []string{taskName}, passthrough, opts)
+}
+
+// loadRunnable loads rig.toml and rig.lock and checks that the tools and Go
+// toolchain match the lock, as every `rig run` requires.
+func loadRunnable(startDir string) (*cfg.Config, string, Lockfile, error) {
+	conf, confPath, err := LoadConfig(startDir)
+	if err != nil {
+		return nil, "", Lockfile{}, err
+	}
 
 	lock, err := ReadRigLockForConfig(confPath)
 	if err != nil {
-		return fmt.Errorf("rig.lock required: %w", err)
+		return nil, "", Lockfile{}, WithCode(CodeLockMissing, fmt.Errorf("rig.lock required: %w", err))
+	}
+	if err := VerifyLockSignature(confPath, conf.Lock); err != nil {
+		return nil, "", Lockfile{}, err
+	}
+	if err := LockMatchesManifest(lock, conf); err != nil {
+		// The installed tools still match rig.lock, so the run goes ahead.
+		statusf(os.Stderr, "⚠️  %v", err)
 	}
 
 	rows, missing, mismatched, extras, err := CheckInstalledTools(conf.Tools, lock, confPath)
 	if err != nil {
-		return err
+		return nil, "", Lockfile{}, err
 	}
 	if missing > 0 || mismatched > 0 {
-		return fmt.Errorf("tools are out of sync with rig.lock (missing=%d mismatched=%d extras=%d)", missing, mismatched, len(extras))
+		return nil, "", Lockfile{}, WithCode(CodeToolsOutOfSync, fmt.Errorf("tools are out of sync with rig.lock (missing=%d mismatched=%d extras=%d)", missing, mismatched, len(extras)))
 	}
 	_ = rows // reserved for future diagnostics
 
 	if goRow, ok := checkGoAgainstLockIfRequired(conf.Tools, lock, confPath); !ok {
 		if goRow != nil {
 			if goRow.Error != "" {
-				return fmt.Errorf("go toolchain check failed (%s): %s", goRow.Status, goRow.Error)
+				return nil, "", Lockfile{}, WithCode(CodeGoToolchain, fmt.Errorf("go toolchain check failed (%s): %s", goRow.Status, goRow.Error))
 			}
-			return fmt.Errorf("go toolchain check failed (%s): have %q, want %q", goRow.Status, goRow.Have, goRow.Locked)
+			return nil, "", Lockfile{}, WithCode(CodeGoToolchain, fmt.Errorf("go toolchain check failed (%s): have %q, want %q", goRow.Status, goRow.Have, goRow.Locked))
 		}
-		return fmt.Errorf("go toolchain check failed")
+		return nil, "", Lockfile{}, WithCode(CodeGoToolchain, fmt.Errorf("go toolchain check failed"))
 	}
+	return conf, confPath, lock, nil
+}
 
-	task, ok := conf.Tasks[taskName]
-	if !ok {
-		return fmt.Errorf("task %q not found", taskName)
+// TaskDependencies returns the depends_on (and steps) closure of taskName in
+// run order, excluding the task itself.
+func TaskDependencies(tasks cfg.TasksMap, taskName string) ([]string, error) {
+	order, err := resolveTaskOrder(tasks, taskName)
+	if err != nil {
+		return nil, err
 	}
-	if task.Command == "" {
-		return fmt.Errorf("task %q missing command", taskName)
+	return order[:len(order)-1], nil
+}
+
+// RunTasks runs the named tasks in the given order, each after its
+// dependencies. A task runs at most once, so passing a depends_on closure (as
+// `rig dev` does for [tasks.dev].depends_on) runs nothing twice.
+func RunTasks(ctx context.Context, conf *cfg.Config, confPath string, lock Lockfile, names []string, opts RunOptions) error {
+	if len(names) == 0 {
+		return nil
 	}
+	return runTaskOrder(ctx, conf, confPath, lock, names, names, nil, opts)
+}
 
-	order, err := resolveTaskOrder(conf.Tasks, taskName)
+// runTaskOrder checks requirements and inputs for every task in order, then
+// runs targets; passthrough args go to the last target.
+func runTaskOrder(ctx context.Context, conf *cfg.Config, confPath string, lock Lockfile, order, targets []string, passthrough []string, opts RunOptions) error {
+	if err := ensureRequirements(conf.Requires, requirementsForTasks(conf.Tasks, order)); err != nil {
+		return err
+	}
+	if conf.Project.StrictTools {
+		if err := ensurePinnedTools(conf, order); err != nil {
+			return err
+		}
+	}
+	inputs, err := resolveTaskInputs(conf.Tasks, order, opts)
+	if err != nil {
+		return err
+	}
+	mode, err := ParseOutputMode(opts.Output)
 	if err != nil {
 		return err
 	}
 
-	for i, name := range order {
-		t := conf.Tasks[name]
-		argv, err := parseCommand(t.Command)
-		if err != nil {
-			return fmt.Errorf("task %q: %w", name, err)
+	baseEnv, err := runBaseEnv(conf, confPath, opts.Profile)
+	if err != nil {
+		return err
+	}
+
+	root := targets[len(targets)-1]
+	if len(passthrough) > 0 && len(conf.Tasks[root].Steps) > 0 {
+		return fmt.Errorf("task %q has steps and cannot take extra arguments", root)
+	}
+	if len(opts.Dirs) > 0 && len(conf.Tasks[root].Steps) > 0 {
+		return fmt.Errorf("task %q has steps and cannot take --dir", root)
+	}
+	r := &taskRunner{
+		ctx:         ctx,
+		tasks:       conf.Tasks,
+		confPath:    confPath,
+		lock:        lock,
+		inputs:      inputs,
+		baseEnv:     baseEnv,
+		environ:     runEnviron(conf, opts.Hermetic),
+		opts:        opts,
+		root:        root,
+		passthrough: passthrough,
+		requires:    conf.Requires,
+		triggers:    newTriggerWatch(confPath, conf.Tasks),
+		planned:     order,
+		runs:        map[string]*taskRun{},
+		timings:     map[string]taskTiming{},
+		onlyDirs:    opts.onlyDirs,
+		failedDirs:  map[string][]string{},
+	}
+	if opts.Jobs > 1 {
+		r.slots = make(chan struct{}, opts.Jobs)
+	}
+	r.plugins = newPluginSet(confPath, lock, conf.Plugins)
+	defer r.plugins.close()
+	for _, name := range opts.done {
+		tr := &taskRun{done: make(chan struct{})}
+		close(tr.done)
+		r.runs[name] = tr
+	}
+	defer func() { recordTaskHistory(confPath, r.timings) }()
+	if opts.record != nil {
+		defer r.recordLastRun(order, opts.record)
+	}
+	start := time.Now()
+	err = r.runTargets(targets, mode)
+	if ctx.Err() != nil {
+		err = WithCode(CodeTaskFailed, fmt.Errorf("run canceled: %w", context.Cause(ctx)))
+	}
+	if r.plugins.has(cfg.PluginNotify) {
+		r.plugins.notify(r.notifyArgs(conf.Project.Name, root, start, err))
+	}
+	return err
+}
+
+// runTargets runs targets and the tasks they trigger, then reports the
+// failures collected under ContinueOnError.
+func (r *taskRunner) runTargets(targets []string, mode string) error {
+	for _, name := range targets {
+		if err := r.run(name, mode); err != nil && !errors.Is(err, errTaskFailed) {
+			return WithCode(CodeTaskFailed, err)
 		}
+	}
+	if err := r.runTriggered(mode); err != nil {
+		return WithCode(CodeTaskFailed, err)
+	}
 
-		// Passthrough applies only to the root task (last in order).
-		if i == len(order)-1 && len(passthrough) > 0 {
-			argv = append(argv, passthrough...)
+	if len(r.failures) > 0 {
+		msg := fmt.Sprintf("%d task(s) failed: %s", len(r.failures), strings.Join(r.failures, ", "))
+		if len(r.skipped) > 0 {
+			msg += fmt.Sprintf(" (skipped: %s)", strings.Join(r.skipped, ", "))
 		}
+		return WithCode(CodeTaskFailed, errors.New(msg))
+	}
+	return nil
+}
 
-		cwd, err := resolveCwd(confPath, t.Cwd)
+// notifyArgs describes the finished run for notify plugins.
+func (r *taskRunner) notifyArgs(project, root string, start time.Time, err error) NotifyArgs {
+	args := NotifyArgs{Project: project, Task: root, OK: err == nil, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		args.Error = err.Error()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range append(slices.Clone(r.planned), r.triggered...) {
+		if tt, ok := r.timings[name]; ok {
+			args.Tasks = append(args.Tasks, NotifyTaskResult{Task: name, OK: tt.OK, MS: tt.MS})
+		}
+	}
+	return args
+}
+
+// runBaseEnv is the env every task in a run starts from: GOCACHE from
+// [cache].go and the env of the selected profile.
+func runBaseEnv(conf *cfg.Config, confPath, profile string) (map[string]string, error) {
+	baseEnv := map[string]string{}
+	if dir := GoCacheDir(conf, confPath); dir != "" {
+		baseEnv["GOCACHE"] = dir
+	}
+	if profile != "" {
+		prof, err := LookupProfile(conf, confPath, profile)
 		if err != nil {
-			return fmt.Errorf("task %q: resolve cwd: %w", name, err)
+			return nil, err
 		}
+		maps.Copy(baseEnv, ProfileTaskEnv(profile, prof, os.Getenv("GOFLAGS")))
+	}
+	return baseEnv, nil
+}
+
+// taskEnvOver layers a task's own env over the run's base env.
+func taskEnvOver(base, env map[string]string) map[string]string {
+	if len(base) == 0 {
+		return env
+	}
+	out := maps.Clone(base)
+	maps.Copy(out, env)
+	return out
+}
+
+// errTaskFailed marks a failure already recorded by a taskRunner under
+// ContinueOnError; callers keep going instead of aborting the run.
+var errTaskFailed = errors.New("task failed")
+
+// taskRunner runs tasks after their depends_on and expands composite tasks
+// (steps), serially or in parallel. Each task runs at most once. With
+// opts.Jobs above 1 the depends_on of a task run concurrently, at most Jobs
+// commands at a time; a serial task's command runs alone.
+//
+// A failing task with allow_failure is reported and ignored. With
+// opts.ContinueOnError other failures are collected instead of stopping the
+// run, and tasks depending on a failed task are skipped.
+type taskRunner struct {
+	ctx         context.Context
+	tasks       cfg.TasksMap
+	confPath    string
+	lock        Lockfile
+	inputs      map[string]string
+	baseEnv     map[string]string
+	environ     []string
+	opts        RunOptions
+	root        string
+	passthrough []string
+	requires    map[string]string
+	triggers    *triggerWatch
+	planned     []string
 
-		env := buildEnv(confPath, t.Env)
+	mu        sync.Mutex
+	runs      map[string]*taskRun
+	failures  []string
+	skipped   []string
+	triggered []string
+	timings   map[string]taskTiming
+	// onlyDirs narrows dirs tasks to the directories that failed last time
+	// (`rig run --failed`); failedDirs collects this run's, for last-run.json.
+	onlyDirs   map[string][]string
+	failedDirs map[string][]string
+	// plugins serves the [plugins] hooks: remote cache, secret:// env
+	// values, and run notifications.
+	plugins *pluginSet
+	// slots bounds concurrent commands to opts.Jobs (nil: unbounded), and
+	// exclusive is held for writing while a serial task's command runs.
+	slots     chan struct{}
+	exclusive sync.RWMutex
+}
+
+type taskRun struct {
+	done chan struct{}
+	err  error
+}
 
-		exe := ""
-		// Managed tools are executed exclusively from .rig/bin (no PATH fallback).
-		// Explicit exception: `go` is resolved from PATH (toolchain), and is never installed by rig.
-		if argv[0] != "go" {
-			if p, ok, rerr := ResolveManagedToolExecutable(confPath, lock, argv[0]); rerr != nil {
-				return fmt.Errorf("task %q: %w", name, rerr)
-			} else if ok {
-				exe = p
+// run executes name once; later callers wait for and share its result.
+func (r *taskRunner) run(name, mode string) error {
+	r.mu.Lock()
+	if tr, ok := r.runs[name]; ok {
+		r.mu.Unlock()
+		<-tr.done
+		return tr.err
+	}
+	tr := &taskRun{done: make(chan struct{})}
+	r.runs[name] = tr
+	r.mu.Unlock()
+
+	if tr.err = r.ctx.Err(); tr.err == nil {
+		tr.err = r.runOne(name, mode)
+	}
+	close(tr.done)
+	return tr.err
+}
+
+func (r *taskRunner) runOne(name, mode string) error {
+	t := r.tasks[name]
+	failed, err := r.runAll(t.DependsOn, r.slots != nil && !t.Serial, mode)
+	if err != nil {
+		return err
+	}
+	if failed != "" {
+		statusf(os.Stderr, "⏭️  %s skipped (%s failed)", name, failed)
+		r.record(&r.skipped, name)
+		return errTaskFailed
+	}
+
+	if len(t.Steps) > 0 {
+		failed, err := r.runAll(t.Steps, t.Mode == cfg.TaskModeParallel, mode)
+		if err != nil {
+			return err
+		}
+		if failed != "" {
+			return errTaskFailed
+		}
+		return nil
+	}
+
+	t.Env = taskEnvOver(r.baseEnv, t.Env)
+
+	// Passthrough and --dir apply only to the requested task.
+	var extra []string
+	dirs, err := expandTaskDirs(filepath.Dir(r.confPath), t.Dirs)
+	if name == r.root {
+		extra = r.passthrough
+		if len(r.opts.Dirs) > 0 {
+			dirs, err = expandTaskDirs("", r.opts.Dirs)
+		}
+	}
+	if only, ok := r.onlyDirs[name]; ok {
+		dirs = only
+	}
+	if err != nil {
+		return fmt.Errorf("task %q: %w", name, err)
+	}
+	r.mu.Lock()
+	inputs := r.inputs
+	r.mu.Unlock()
+	cacheKey := ""
+	if pluginCacheable(t, dirs) {
+		if cacheKey, err = r.taskCacheKey(name, t, extra, dirs, inputs); err != nil {
+			statusf(os.Stderr, "⚠️  %s: cache key: %v", name, err)
+			cacheKey = ""
+		}
+	}
+	// --force runs the task anyway; its result still refreshes the caches.
+	if cacheKey != "" && !r.opts.Force {
+		if r.taskUpToDate(name, t.Outputs, cacheKey) {
+			statusf(os.Stderr, "⏭️  %s up to date, skipping", name)
+			return nil
+		}
+		if r.plugins.has(cfg.PluginCache) {
+			if hit, err := r.restoreFromPluginCache(name, cacheKey); err != nil {
+				statusf(os.Stderr, "⚠️  %s: %v", name, err)
+			} else if hit {
+				r.normalizeOutputs(name, t)
+				r.recordTaskCache(name, t.Outputs, cacheKey)
+				r.noteTriggers(name)
+				return nil
+			}
+		}
+	}
+	if t.Env, err = r.plugins.resolveSecrets(t.Env); err != nil {
+		return fmt.Errorf("task %q: %w", name, err)
+	}
+	release := r.acquire(t.Serial)
+	start := time.Now()
+	o := runTaskOptions{name: name, task: t, extra: extra, dirs: dirs, inputs: inputs, mode: mode, dep: name != r.root}
+	err = r.runAttempts(name, t, func(ctx context.Context) error {
+		if r.opts.Isolate {
+			return r.runIsolated(name, t, dirs, func(t cfg.Task, dirs []string) error {
+				o := o
+				o.task, o.dirs = t, dirs
+				return r.runTask(ctx, o)
+			})
+		}
+		return r.runTask(ctx, o)
+	})
+	release()
+	r.mu.Lock()
+	r.timings[name] = newTaskTiming(start, err)
+	var de *dirsError
+	if errors.As(err, &de) {
+		r.failedDirs[name] = de.failed
+	}
+	r.mu.Unlock()
+	switch {
+	case err == nil:
+		r.normalizeOutputs(name, t)
+		if cacheKey != "" {
+			r.recordTaskCache(name, t.Outputs, cacheKey)
+		}
+		if cacheKey != "" && r.plugins.has(cfg.PluginCache) {
+			if err := r.storeInPluginCache(name, t, cacheKey); err != nil {
+				statusf(os.Stderr, "⚠️  %s: %v", name, err)
+			}
+		}
+		r.noteTriggers(name)
+		return nil
+	case t.AllowFailure:
+		statusf(os.Stderr, "⚠️  %v (allow_failure)", err)
+		return nil
+	case r.opts.ContinueOnError:
+		statusf(os.Stderr, "❌ %v; continuing", err)
+		r.record(&r.failures, name)
+		return errTaskFailed
+	default:
+		return err
+	}
+}
+
+// runAll runs names one after another (stopping at the first hard error) or
+// concurrently. It returns the first hard error, or else the first name that
+// failed under ContinueOnError. Concurrent tasks in full output mode are
+// prefixed so their interleaved lines stay attributable.
+func (r *taskRunner) runAll(names []string, parallel bool, mode string) (string, error) {
+	errs := make([]error, len(names))
+	if parallel {
+		if mode == OutputFull {
+			mode = OutputPrefixed
+		}
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = r.run(name, mode)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i, name := range names {
+			if errs[i] = r.run(name, mode); errs[i] != nil && !errors.Is(errs[i], errTaskFailed) {
+				break
 			}
 		}
-		if exe == "" {
-			exe, err = resolveExecutable(argv[0], cwd, env)
-			if err != nil {
-				return fmt.Errorf("task %q: %w", name, err)
+	}
+
+	failed := ""
+	for i, err := range errs {
+		switch {
+		case err == nil:
+		case errors.Is(err, errTaskFailed):
+			if failed == "" {
+				failed = names[i]
 			}
+		default:
+			return "", err
+		}
+	}
+	return failed, nil
+}
+
+// acquire waits until a command may start: a free slot under --jobs, and no
+// serial task running (or, for a serial task, nothing else running). Only
+// commands hold these, never tasks waiting on dependencies, so they cannot
+// deadlock.
+func (r *taskRunner) acquire(serial bool) (release func()) {
+	if serial {
+		r.exclusive.Lock()
+		return r.exclusive.Unlock
+	}
+	r.exclusive.RLock()
+	if r.slots == nil {
+		return r.exclusive.RUnlock
+	}
+	r.slots <- struct{}{}
+	return func() {
+		<-r.slots
+		r.exclusive.RUnlock()
+	}
+}
+
+func (r *taskRunner) record(list *[]string, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*list = append(*list, name)
+}
+
+// runTaskOptions is one command task for runTask to execute.
+type runTaskOptions struct {
+	name   string
+	task   cfg.Task
+	extra  []string // passthrough arguments
+	dirs   []string
+	inputs map[string]string
+	mode   string // output mode, honored only when dep is set
+	dep    bool   // a dependency rather than the requested task
+}
+
+// runTask executes one task. Dependency tasks honor the output mode; the
+// requested task always streams. With more than one of dirs the command runs
+// in each (see runTaskInDirs); otherwise in dirs[0] or the task's cwd.
+func (r *taskRunner) runTask(ctx context.Context, o runTaskOptions) error {
+	name, t, dirs := o.name, o.task, o.dirs
+	env := buildEnv(r.confPath, r.environ, t.Env)
+	argv, err := taskArgv(name, t, o.extra, o.inputs, env)
+	if err != nil {
+		return err
+	}
+
+	if len(dirs) == 0 {
+		cwd, err := resolveCwd(r.confPath, t.Cwd)
+		if err != nil {
+			return fmt.Errorf("task %q: resolve cwd: %w", name, err)
 		}
+		dirs = []string{cwd}
+	}
 
-		if err := Execute(exe, argv[1:], ExecOptions{Dir: cwd, Env: env, EnvExact: true}); err != nil {
-			return fmt.Errorf("task %q failed: %w", name, err)
+	exe := ""
+	if len(dirs) == 1 {
+		if exe, err = resolveTaskExecutable(r.confPath, r.lock, argv[0], dirs[0], env); err != nil {
+			return fmt.Errorf("task %q: %w", name, err)
 		}
 	}
 
+	limits, err := TaskLimits(t)
+	if err != nil {
+		return fmt.Errorf("task %q: %w", name, err)
+	}
+	stop, err := TaskStopPolicy(t, DefaultTaskStop)
+	if err != nil {
+		return fmt.Errorf("task %q: %w", name, err)
+	}
+	// A task with a timeout gets its own process group, so that expiry stops
+	// everything it started.
+	eo := ExecOptions{Dir: dirs[0], Env: env, EnvExact: true, Limits: limits, Stop: stop, KillTree: t.Timeout != ""}
+	var captured *bytes.Buffer
+	if o.dep {
+		switch o.mode {
+		case OutputPrefixed:
+			eo.Stdout = newPrefixWriter(os.Stdout, "["+name+"] ")
+			eo.Stderr = newPrefixWriter(os.Stderr, "["+name+"] ")
+		case OutputErrorsOnly:
+			captured = &bytes.Buffer{}
+			eo.Stdout, eo.Stderr = captured, captured
+		}
+	}
+	// Buffered errors-only output is invisible, so it does not count as activity.
+	hb := startHeartbeat(name, r.opts.Heartbeat, os.Stderr)
+	if hb != nil && captured == nil {
+		if eo.Stdout == nil {
+			eo.Stdout, eo.Stderr = os.Stdout, os.Stderr
+		}
+		eo.Stdout, eo.Stderr = hb.writer(eo.Stdout), hb.writer(eo.Stderr)
+	}
+	start := time.Now()
+	if exe != "" {
+		err = ExecuteContext(ctx, exe, argv[1:], eo)
+	} else {
+		err = runTaskInDirs(ctx, r.confPath, r.lock, name, argv, dirs, eo)
+	}
+	hb.Stop()
+	if captured != nil {
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			statusf(os.Stderr, "✗ %s (%s)", name, elapsed)
+			_, _ = os.Stderr.Write(captured.Bytes())
+		} else {
+			statusf(os.Stdout, "✓ %s (%s)", name, elapsed)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("task %q failed: %w", name, err)
+	}
 	return nil
 }
 
+// taskArgv is the argv a task runs: its command with env expanded, then
+// inputs substituted and the extra (or default) arguments spliced in.
+func taskArgv(name string, t cfg.Task, extra []string, inputs map[string]string, env []string) ([]string, error) {
+	// Expand before inputs and passthrough args so their values stay literal.
+	argv, err := parseCommand(expandCommand(t.Command, env, runtime.GOOS))
+	if err != nil {
+		return nil, fmt.Errorf("task %q: %w", name, err)
+	}
+	argv = substituteInputs(argv, t, inputs)
+	argv = spliceArgs(argv, extra, expandArgv(t.DefaultArgs, env, runtime.GOOS))
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("task %q: command is empty after argument substitution", name)
+	}
+	return argv, nil
+}
+
+// resolveTaskExecutable finds argv0 for a task running in cwd. Managed tools
+// are executed exclusively from .rig/bin (no PATH fallback). Explicit
+// exception: `go` is resolved from PATH (toolchain), and is never installed by rig.
+func resolveTaskExecutable(confPath string, lock Lockfile, argv0, cwd string, env []string) (string, error) {
+	if argv0 != "go" {
+		if p, ok, err := ResolveManagedToolExecutable(confPath, lock, argv0); err != nil {
+			return "", err
+		} else if ok {
+			return p, nil
+		}
+	}
+	return resolveExecutable(argv0, cwd, env)
+}
+
+// argsPlaceholder marks where extra arguments go in a task command.
+const argsPlaceholder = "${args}"
+
+// argTemplateRe matches the {{args}} and {{argN}} placeholders, spelled like
+// task inputs. {{args}} is the same as ${args}; {{arg0}} is the first argument.
+var argTemplateRe = regexp.MustCompile(`\{\{\s*(args|arg[0-9]+)\s*\}\}`)
+
+// spliceArgs adds the extra arguments (or defaults when there are none) to
+// argv. A token that is exactly ${args} or {{args}} is replaced by the
+// arguments, and one that is exactly {{argN}} by the Nth (or dropped when
+// there are fewer). Inside a larger token they are replaced by the
+// arguments joined with spaces, or the Nth one. Without a placeholder the
+// arguments are appended.
+func spliceArgs(argv, extra, defaults []string) []string {
+	args := extra
+	if len(args) == 0 {
+		args = defaults
+	}
+	out := make([]string, 0, len(argv)+len(args))
+	spliced := false
+	for _, a := range argv {
+		m := argTemplateRe.FindStringSubmatch(a)
+		switch {
+		case a == argsPlaceholder:
+			out = append(out, args...)
+			spliced = true
+		case m != nil && m[0] == a:
+			out = append(out, templateArgs(m[1], args)...)
+			spliced = true
+		case strings.Contains(a, argsPlaceholder) || m != nil:
+			a = strings.ReplaceAll(a, argsPlaceholder, strings.Join(args, " "))
+			out = append(out, argTemplateRe.ReplaceAllStringFunc(a, func(s string) string {
+				return strings.Join(templateArgs(argTemplateRe.FindStringSubmatch(s)[1], args), " ")
+			}))
+			spliced = true
+		default:
+			out = append(out, a)
+		}
+	}
+	if !spliced {
+		out = append(out, args...)
+	}
+	return out
+}
+
+// templateArgs returns the arguments a placeholder name stands for: all of
+// them for "args", the Nth for "argN".
+func templateArgs(name string, args []string) []string {
+	if name == "args" {
+		return args
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "arg"))
+	if err != nil || n >= len(args) {
+		return nil
+	}
+	return args[n : n+1]
+}
+
 func resolveTaskOrder(tasks cfg.TasksMap, root string) ([]string, error) {
 	adj := make(map[string][]string, len(tasks))
 	for name, t := range tasks {
-		if len(t.DependsOn) > 0 {
-			deps := make([]string, len(t.DependsOn))
-			copy(deps, t.DependsOn)
-			adj[name] = deps
-		} else {
-			adj[name] = nil
-		}
+		// Steps are ordered after depends_on; both must exist and be acyclic.
+		var deps []string
+		deps = append(deps, t.DependsOn...)
+		deps = append(deps, t.Steps...)
+		adj[name] = deps
 	}
 	if _, ok := adj[root]; !ok {
-		return nil, fmt.Errorf("task %q not found", root)
+		return nil, WithCode(CodeTaskNotFound, fmt.Errorf("task %q not found%s", root, DidYouMean(root, TaskNames(tasks))))
 	}
 
 	state := make(map[string]int, len(adj))
@@ -126,16 +711,20 @@ func resolveTaskOrder(tasks cfg.TasksMap, root string) ([]string, error) {
 				}
 			}
 			cycle := append(stack[idx:], u)
-			return fmt.Errorf("cycle detected: %v", cycle)
+			return WithCode(CodeTaskCycle, fmt.Errorf("cycle detected: %v", cycle))
 		}
 		if st == 2 {
 			return nil
 		}
 		state[u] = 1
 		stack = append(stack, u)
-		for _, v := range adj[u] {
+		for i, v := range adj[u] {
 			if _, ok := adj[v]; !ok {
-				return fmt.Errorf("task %q depends_on unknown task %q", u, v)
+				field := "depends_on"
+				if i >= len(tasks[u].DependsOn) {
+					field = "steps"
+				}
+				return WithCode(CodeTaskNotFound, fmt.Errorf("task %q %s unknown task %q%s", u, field, v, DidYouMean(v, TaskNames(tasks))))
 			}
 			if err := dfs(v); err != nil {
 				return err
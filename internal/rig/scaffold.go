@@ -0,0 +1,263 @@
+package rig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// ScaffoldOptions configures `rig new <template> <name>`.
+type ScaffoldOptions struct {
+	Template string
+	Name     string
+	// TemplatesDir overrides template lookup (e.g. a checkout of a shared
+	// template repo). Relative paths resolve against the rig.toml directory.
+	TemplatesDir string
+	Force        bool
+	DryRun       bool
+}
+
+// ScaffoldResult lists the files rendered by Scaffold, relative to the project root.
+type ScaffoldResult struct {
+	TemplateDir string
+	Root        string
+	Written     []string
+	Skipped     []string
+}
+
+// ScaffoldVars are available to templates (file contents and paths).
+type ScaffoldVars struct {
+	Name    string // as given
+	Pascal  string // UserProfile
+	Camel   string // userProfile
+	Snake   string // user_profile
+	Kebab   string // user-profile
+	Lower   string // userprofile (package-name friendly)
+	Project string // [project].name
+	Module  string // module path from go.mod, if any
+}
+
+// TemplateDirs returns the directories searched for templates, in order.
+func TemplateDirs(root string, override string) []string {
+	if o := strings.TrimSpace(override); o != "" {
+		if !filepath.IsAbs(o) {
+			o = filepath.Join(root, o)
+		}
+		return []string{o}
+	}
+	if env := strings.TrimSpace(os.Getenv("RIG_TEMPLATES_DIR")); env != "" {
+		return []string{env}
+	}
+	return []string{filepath.Join(root, ".rig", "templates"), filepath.Join(root, "templates")}
+}
+
+// ListTemplates returns the template names available to the project.
+func ListTemplates(startDir string, override string) ([]string, error) {
+	_, confPath, err := LoadConfig(startDir)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	var names []string
+	for _, d := range TemplateDirs(filepath.Dir(confPath), override) {
+		entries, err := os.ReadDir(d)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			if _, ok := seen[e.Name()]; ok {
+				continue
+			}
+			seen[e.Name()] = struct{}{}
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Scaffold renders every file in the named template directory into the project
+// root. File paths and contents are Go text/templates over ScaffoldVars; a
+// trailing ".tmpl" is stripped. Existing files are never overwritten unless
+// opts.Force is set.
+func Scaffold(startDir string, opts ScaffoldOptions) (ScaffoldResult, error) {
+	conf, confPath, err := LoadConfig(startDir)
+	if err != nil {
+		return ScaffoldResult{}, err
+	}
+	tmplName := strings.TrimSpace(opts.Template)
+	if tmplName == "" || strings.ContainsAny(tmplName, `/\`) || tmplName == "." || tmplName == ".." {
+		return ScaffoldResult{}, fmt.Errorf("invalid template name %q", opts.Template)
+	}
+	if strings.TrimSpace(opts.Name) == "" {
+		return ScaffoldResult{}, errors.New("name must be non-empty")
+	}
+
+	root := filepath.Dir(confPath)
+	res := ScaffoldResult{Root: root}
+	dirs := TemplateDirs(root, opts.TemplatesDir)
+	for _, d := range dirs {
+		if dirExists(filepath.Join(d, tmplName)) {
+			res.TemplateDir = filepath.Join(d, tmplName)
+			break
+		}
+	}
+	if res.TemplateDir == "" {
+		return ScaffoldResult{}, fmt.Errorf("template %q not found (searched: %s)", tmplName, strings.Join(dirs, ", "))
+	}
+
+	vars := newScaffoldVars(opts.Name, conf.Project.Name, root)
+	type rendered struct {
+		rel  string
+		data []byte
+		mode fs.FileMode
+	}
+	var files []rendered
+	err = filepath.WalkDir(res.TemplateDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		srcRel, err := filepath.Rel(res.TemplateDir, path)
+		if err != nil {
+			return err
+		}
+		relOut, err := renderScaffold(srcRel, filepath.ToSlash(srcRel), vars)
+		if err != nil {
+			return err
+		}
+		rel := filepath.Clean(filepath.FromSlash(strings.TrimSuffix(string(relOut), ".tmpl")))
+		if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s: rendered path %q escapes the project root", srcRel, rel)
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		data, err := renderScaffold(srcRel, string(src), vars)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, rendered{rel: rel, data: data, mode: info.Mode().Perm()})
+		return nil
+	})
+	if err != nil {
+		return ScaffoldResult{}, err
+	}
+	if len(files) == 0 {
+		return ScaffoldResult{}, fmt.Errorf("template %q has no files", tmplName)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].rel < files[j].rel })
+
+	for _, f := range files {
+		dest := filepath.Join(root, f.rel)
+		if _, err := os.Stat(dest); err == nil && !opts.Force {
+			res.Skipped = append(res.Skipped, f.rel)
+			continue
+		}
+		res.Written = append(res.Written, f.rel)
+		if opts.DryRun {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return res, err
+		}
+		if err := os.WriteFile(dest, f.data, f.mode); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func renderScaffold(name string, text string, vars ScaffoldVars) ([]byte, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, vars); err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func newScaffoldVars(name string, project string, root string) ScaffoldVars {
+	words := splitWords(name)
+	lower := make([]string, len(words))
+	for i, w := range words {
+		lower[i] = strings.ToLower(w)
+	}
+	var pascal strings.Builder
+	for _, w := range lower {
+		if w == "" {
+			continue
+		}
+		pascal.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	camel := pascal.String()
+	if camel != "" {
+		camel = strings.ToLower(camel[:1]) + camel[1:]
+	}
+	v := ScaffoldVars{
+		Name:    name,
+		Pascal:  pascal.String(),
+		Camel:   camel,
+		Snake:   strings.Join(lower, "_"),
+		Kebab:   strings.Join(lower, "-"),
+		Lower:   strings.Join(lower, ""),
+		Project: project,
+	}
+	if b, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "module ") {
+				v.Module = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+				break
+			}
+		}
+	}
+	return v
+}
+
+// splitWords breaks "userProfile", "user_profile", "user-profile", or
+// "User Profile" into ["user", "Profile"]-style words.
+func splitWords(s string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = cur[:0]
+		}
+	}
+	runes := []rune(strings.TrimSpace(s))
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ' || r == '.' || r == '/':
+			flush()
+		case unicode.IsUpper(r) && len(cur) > 0 && (unicode.IsLower(cur[len(cur)-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,60 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScaffoldRendersTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), "[project]\nname = \"shop\"\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/shop\n\ngo 1.22\n", 0o644)
+	tmpl := filepath.Join(dir, ".rig", "templates", "handler")
+	writeTestFile(t, filepath.Join(tmpl, "internal", "handlers", "{{.Snake}}.go.tmpl"), "package handlers\n\n// {{.Pascal}}Handler serves {{.Kebab}} in {{.Module}}.\ntype {{.Pascal}}Handler struct{}\n", 0o644)
+	writeTestFile(t, filepath.Join(tmpl, "internal", "handlers", "{{.Snake}}_test.go.tmpl"), "package handlers\n", 0o644)
+
+	names, err := ListTemplates(dir, "")
+	if err != nil || !reflect.DeepEqual(names, []string{"handler"}) {
+		t.Fatalf("ListTemplates=%v err=%v", names, err)
+	}
+
+	res, err := Scaffold(dir, ScaffoldOptions{Template: "handler", Name: "userProfile"})
+	if err != nil {
+		t.Fatalf("Scaffold: %v", err)
+	}
+	want := []string{
+		filepath.Join("internal", "handlers", "user_profile.go"),
+		filepath.Join("internal", "handlers", "user_profile_test.go"),
+	}
+	if !reflect.DeepEqual(res.Written, want) {
+		t.Fatalf("written=%v, want %v", res.Written, want)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, want[0]))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if got := string(b); got != "package handlers\n\n// UserProfileHandler serves user-profile in example.com/shop.\ntype UserProfileHandler struct{}\n" {
+		t.Fatalf("rendered=%q", got)
+	}
+
+	res, err = Scaffold(dir, ScaffoldOptions{Template: "handler", Name: "userProfile"})
+	if err != nil {
+		t.Fatalf("Scaffold (again): %v", err)
+	}
+	if len(res.Written) != 0 || len(res.Skipped) != 2 {
+		t.Fatalf("expected existing files to be skipped, got %+v", res)
+	}
+
+	if _, err := Scaffold(dir, ScaffoldOptions{Template: "missing", Name: "x"}); err == nil {
+		t.Fatalf("expected missing template error")
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	v := newScaffoldVars("HTTPServer", "", t.TempDir())
+	if v.Snake != "http_server" || v.Pascal != "HttpServer" || v.Camel != "httpServer" {
+		t.Fatalf("vars=%+v", v)
+	}
+}
@@ -0,0 +1,287 @@
+package rig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// Schedule computes the next run time after a given instant.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// everySchedule runs at a fixed interval ("@every 10m").
+type everySchedule struct{ d time.Duration }
+
+func (s everySchedule) Next(after time.Time) time.Time { return after.Add(s.d) }
+
+// cronSchedule is a standard five-field cron expression. Each field is a
+// bitset of allowed values.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar/dowStar record an unrestricted field; cron matches a day when
+	// either field matches if both are restricted.
+	domStar, dowStar bool
+}
+
+var scheduleMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses a cron expression ("*/15 * * * *", "0 9 * * 1-5"),
+// a macro (@hourly, @daily, ...), or "@every <duration>".
+func ParseSchedule(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid @every duration %q: %w", rest, err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("@every duration must be at least 1s, got %s", d)
+		}
+		return everySchedule{d: d}, nil
+	}
+	if m, ok := scheduleMacros[expr]; ok {
+		expr = m
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields (minute hour day-of-month month day-of-week), a macro like @hourly, or @every <duration>", expr)
+	}
+	var s cronSchedule
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid minute field: %w", err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid hour field: %w", err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid day-of-month field: %w", err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid month field: %w", err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid day-of-week field: %w", err)
+	}
+	// 7 is an alias for Sunday.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = fields[2] == "*"
+	s.dowStar = fields[4] == "*"
+	return s, nil
+}
+
+// parseCronField parses a comma-separated list of "*", "n", "a-b", each
+// optionally followed by "/step".
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+		start, end := lo, hi
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if start, err = cronValue(a, lo, hi); err != nil {
+				return 0, err
+			}
+			if end, err = cronValue(b, lo, hi); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := cronValue(rng, lo, hi)
+			if err != nil {
+				return 0, err
+			}
+			start = v
+			if !hasStep {
+				end = v
+			}
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, lo, hi int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < lo || v > hi {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, lo, hi)
+	}
+	return v, nil
+}
+
+// Next returns the first whole minute strictly after after that matches,
+// or the zero time if none exists within five years (e.g. "0 0 30 2 *").
+func (s cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// ScheduledTask is one [schedules] entry.
+type ScheduledTask struct {
+	Task     string
+	Expr     string
+	Schedule Schedule
+}
+
+// LoadSchedules validates [schedules] against [tasks] and returns the
+// entries sorted by task name.
+func LoadSchedules(conf *cfg.Config) ([]ScheduledTask, error) {
+	if conf == nil || len(conf.Schedules) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(conf.Schedules))
+	for name := range conf.Schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]ScheduledTask, 0, len(names))
+	for _, name := range names {
+		if _, ok := conf.Tasks[name]; !ok {
+			return nil, WithCode(CodeTaskNotFound, fmt.Errorf("schedules.%s: task %q not found%s", name, name, DidYouMean(name, TaskNames(conf.Tasks))))
+		}
+		expr := conf.Schedules[name]
+		sched, err := ParseSchedule(expr)
+		if err != nil {
+			return nil, WithCode(CodeConfigInvalid, fmt.Errorf("schedules.%s: %w", name, err))
+		}
+		out = append(out, ScheduledTask{Task: name, Expr: expr, Schedule: sched})
+	}
+	return out, nil
+}
+
+// RunScheduler runs each entry's task at its scheduled times until ctx is
+// done, then waits for running tasks to finish. A task still running when it
+// is due again is skipped rather than overlapped. logf receives one line per
+// start, finish, failure, or skip.
+func RunScheduler(ctx context.Context, entries []ScheduledTask, run func(task string) error, logf func(format string, args ...any)) {
+	if len(entries) == 0 {
+		return
+	}
+	next := make([]time.Time, len(entries))
+	now := time.Now()
+	for i, e := range entries {
+		next[i] = e.Schedule.Next(now)
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	running := map[string]bool{}
+	defer wg.Wait()
+
+	for {
+		earliest := -1
+		for i, t := range next {
+			if t.IsZero() {
+				continue
+			}
+			if earliest < 0 || t.Before(next[earliest]) {
+				earliest = i
+			}
+		}
+		if earliest < 0 {
+			return
+		}
+		timer := time.NewTimer(time.Until(next[earliest]))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		now := time.Now()
+		for i, e := range entries {
+			if next[i].IsZero() || next[i].After(now) {
+				continue
+			}
+			next[i] = e.Schedule.Next(now)
+			mu.Lock()
+			busy := running[e.Task]
+			if !busy {
+				running[e.Task] = true
+			}
+			mu.Unlock()
+			if busy {
+				logf("skip %s: previous run still in progress", e.Task)
+				continue
+			}
+			wg.Add(1)
+			go func(task string) {
+				defer wg.Done()
+				start := time.Now()
+				logf("start %s", task)
+				if err := run(task); err != nil {
+					logf("fail %s after %s: %v", task, time.Since(start).Round(time.Millisecond), err)
+				} else {
+					logf("done %s in %s", task, time.Since(start).Round(time.Millisecond))
+				}
+				mu.Lock()
+				delete(running, task)
+				mu.Unlock()
+			}(e.Task)
+		}
+	}
+}
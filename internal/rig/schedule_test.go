@@ -0,0 +1,91 @@
+package rig
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+func TestParseScheduleNext(t *testing.T) {
+	base := time.Date(2026, 3, 4, 10, 7, 30, 0, time.UTC) // Wednesday
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 4, 10, 15, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)},
+		{"30 2 1 * *", time.Date(2026, 4, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"5,10 10 * * *", time.Date(2026, 3, 4, 10, 10, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"@every 90s", base.Add(90 * time.Second)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, c := range cases {
+		s, err := ParseSchedule(c.expr)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q): %v", c.expr, err)
+		}
+		if got := s.Next(base); !got.Equal(c.want) {
+			t.Fatalf("%q: Next = %v, want %v", c.expr, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every soon", "@every 10ms"} {
+		if _, err := ParseSchedule(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestLoadSchedulesValidatesTasks(t *testing.T) {
+	conf := &cfg.Config{
+		Tasks:     cfg.TasksMap{"codegen": {Command: "echo gen"}},
+		Schedules: map[string]string{"codegn": "@hourly"},
+	}
+	_, err := LoadSchedules(conf)
+	if err == nil || !strings.Contains(err.Error(), `did you mean "codegen"?`) || CodeOf(err) != CodeTaskNotFound {
+		t.Fatalf("expected unknown task error with suggestion, got %v", err)
+	}
+
+	conf.Schedules = map[string]string{"codegen": "*/5 * * * *"}
+	entries, err := LoadSchedules(conf)
+	if err != nil || len(entries) != 1 || entries[0].Task != "codegen" {
+		t.Fatalf("unexpected entries %+v, err=%v", entries, err)
+	}
+}
+
+func TestRunSchedulerSkipsOverlappingRuns(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+
+	var mu sync.Mutex
+	var lines []string
+	runs := 0
+	entries := []ScheduledTask{{Task: "slow", Expr: "@every 20ms", Schedule: everySchedule{d: 20 * time.Millisecond}}}
+	RunScheduler(ctx, entries, func(task string) error {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		time.Sleep(70 * time.Millisecond)
+		return fmt.Errorf("boom")
+	}, func(format string, args ...any) {
+		mu.Lock()
+		lines = append(lines, fmt.Sprintf(format, args...))
+		mu.Unlock()
+	})
+
+	log := strings.Join(lines, "\n")
+	if runs == 0 || !strings.Contains(log, "start slow") || !strings.Contains(log, "fail slow") || !strings.Contains(log, "skip slow") {
+		t.Fatalf("unexpected scheduler log (runs=%d):\n%s", runs, log)
+	}
+	if strings.Count(log, "start slow") != strings.Count(log, "fail slow") {
+		t.Fatalf("scheduler returned before runs finished:\n%s", log)
+	}
+}
@@ -1,6 +1,14 @@
 package rig
 
-import "os"
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
 
 type StatusReport struct {
 	ConfigPath string `json:"configPath"`
@@ -51,3 +59,78 @@ func Status(startDir string) (StatusReport, error) {
 		Go:                goRow,
 	}, nil
 }
+
+// ProjectStatus is the status of one project found by StatusAll. Error is
+// set instead when its rig.toml or rig.lock cannot be read.
+type ProjectStatus struct {
+	// Dir is the project directory relative to the scanned root ("." for the
+	// root itself).
+	Dir string `json:"dir"`
+	StatusReport
+	Error string `json:"error,omitempty"`
+}
+
+// OK reports whether the project's lock matches rig.toml and its tools and
+// Go are in sync.
+func (p ProjectStatus) OK() bool {
+	return p.Error == "" && p.HasLock && p.LockMatchesConfig && p.ToolsOK
+}
+
+// FindProjects returns the directories under root that hold a rig.toml,
+// sorted. Hidden directories, vendor, node_modules, and testdata are not
+// searched.
+func FindProjects(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == "rig.toml" {
+			dirs = append(dirs, filepath.Dir(p))
+		}
+		return nil
+	})
+	sort.Strings(dirs)
+	return dirs, err
+}
+
+// StatusAll reports Status for every project under root, a few at a time
+// since each may run `go version`.
+func StatusAll(root string) ([]ProjectStatus, error) {
+	dirs, err := FindProjects(root)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ProjectStatus, len(dirs))
+	sem := make(chan struct{}, max(1, runtime.NumCPU()))
+	var wg sync.WaitGroup
+	for i, dir := range dirs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			rel, rerr := filepath.Rel(root, dir)
+			if rerr != nil {
+				rel = dir
+			}
+			ps := ProjectStatus{Dir: filepath.ToSlash(rel)}
+			rep, err := Status(dir)
+			if err != nil {
+				ps.Error = err.Error()
+			} else {
+				ps.StatusReport = rep
+			}
+			out[i] = ps
+		}()
+	}
+	wg.Wait()
+	return out, nil
+}
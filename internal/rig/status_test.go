@@ -0,0 +1,40 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatusAllFindsProjects(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"api", "web", ".cache/skip", "vendor/skip", "svc/worker"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, filepath.Join(root, dir, "rig.toml"), "[project]\nname = \"x\"\n", 0o644)
+	}
+	writeTestFile(t, filepath.Join(root, "api", "rig.lock"), "schema = 0\n", 0o644)
+	writeTestFile(t, filepath.Join(root, "web", "rig.toml"), "[project\n", 0o644)
+
+	projects, err := StatusAll(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dirs []string
+	for _, p := range projects {
+		dirs = append(dirs, p.Dir)
+	}
+	if len(dirs) != 3 || dirs[0] != "api" || dirs[1] != "svc/worker" || dirs[2] != "web" {
+		t.Fatalf("dirs = %v, want [api svc/worker web]", dirs)
+	}
+	if !projects[0].OK() {
+		t.Fatalf("api should be in sync: %+v", projects[0])
+	}
+	if projects[1].OK() || projects[1].HasLock {
+		t.Fatalf("svc/worker has no rig.lock: %+v", projects[1])
+	}
+	if projects[2].OK() || projects[2].Error == "" {
+		t.Fatalf("web should report its parse error: %+v", projects[2])
+	}
+}
@@ -0,0 +1,85 @@
+package rig
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// StopPolicy is how rig stops a running command: send Signal, then kill it
+// if it has not exited within Timeout. On Windows the command is killed
+// right away.
+type StopPolicy struct {
+	Signal  os.Signal
+	Timeout time.Duration
+}
+
+// Default stop policies: `rig run` interrupts a canceled task as Ctrl+C
+// would and gives it execCancelGrace; `rig dev` terminates its command
+// before a restart and kills it soon after.
+var (
+	DefaultTaskStop = StopPolicy{Signal: os.Interrupt, Timeout: execCancelGrace}
+	DefaultDevStop  = StopPolicy{Signal: syscall.SIGTERM, Timeout: 200 * time.Millisecond}
+)
+
+// stopSignals are the signals stop_signal accepts, without the SIG prefix.
+var stopSignals = map[string]os.Signal{
+	"INT":  os.Interrupt,
+	"TERM": syscall.SIGTERM,
+	"HUP":  syscall.SIGHUP,
+	"QUIT": syscall.SIGQUIT,
+}
+
+// ParseStopSignal parses a stop_signal value such as "SIGTERM" or "int".
+// Empty returns nil (use the default).
+func ParseStopSignal(s string) (os.Signal, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	if v == "" {
+		return nil, nil
+	}
+	if sig, ok := stopSignals[strings.TrimPrefix(v, "SIG")]; ok {
+		return sig, nil
+	}
+	return nil, fmt.Errorf("invalid stop_signal %q (expected SIGINT|SIGTERM|SIGHUP|SIGQUIT)", s)
+}
+
+// ParseStopTimeout parses a stop_timeout Go duration such as "10s". Empty
+// returns 0 (use the default).
+func ParseStopTimeout(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid stop_timeout %q: %w", s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid stop_timeout %q: must be positive", s)
+	}
+	return d, nil
+}
+
+// TaskStopPolicy is def with a task's stop_signal and stop_timeout applied.
+func TaskStopPolicy(t cfg.Task, def StopPolicy) (StopPolicy, error) {
+	p := def
+	sig, err := ParseStopSignal(t.StopSignal)
+	if err != nil {
+		return StopPolicy{}, err
+	}
+	if sig != nil {
+		p.Signal = sig
+	}
+	d, err := ParseStopTimeout(t.StopTimeout)
+	if err != nil {
+		return StopPolicy{}, err
+	}
+	if d > 0 {
+		p.Timeout = d
+	}
+	return p, nil
+}
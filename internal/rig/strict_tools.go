@@ -0,0 +1,247 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/shlex"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// UndeclaredTool is an executable a task command runs that rig does not pin.
+type UndeclaredTool struct {
+	Task string `json:"task"`
+	Tool string `json:"tool"`
+}
+
+func (u UndeclaredTool) String() string {
+	return fmt.Sprintf("task '%s' uses '%s' which is not pinned in [tools]", u.Task, u.Tool)
+}
+
+// strictAllowed are the executables [project].strict-tools accepts without
+// a declaration: the Go toolchain, rig itself, the shells that run `-c`
+// scripts, and shell builtins and keywords.
+var strictAllowed = []string{
+	"go", "gofmt", "rig",
+	"sh", "bash", "zsh", "dash", "ksh",
+	":", ".", "[", "[[", "alias", "break", "cd", "command", "continue", "echo", "eval", "exec",
+	"exit", "export", "false", "getopts", "hash", "kill", "local", "printf", "pwd", "read",
+	"readonly", "return", "set", "shift", "source", "test", "times", "trap", "true", "type",
+	"ulimit", "umask", "unalias", "unset", "wait",
+}
+
+// scriptShells are the shells whose `-c` script is scanned for commands.
+var scriptShells = []string{"sh", "bash", "zsh", "dash", "ksh"}
+
+// commandWrappers run the command that follows them (after assignments
+// and flags).
+var commandWrappers = []string{"env", "exec", "command", "time"}
+
+// UndeclaredTools scans the commands of the named tasks (every task when
+// names is empty) for executables that are not go, rig, a shell builtin, or
+// declared in [tools] (by name or binary), [tool-aliases], or [requires].
+// Path-like commands such as ./scripts/gen.sh are project files and are not
+// reported.
+func UndeclaredTools(conf *cfg.Config, names []string) []UndeclaredTool {
+	declared := map[string]bool{}
+	for _, name := range strictAllowed {
+		declared[name] = true
+	}
+	for name := range conf.Tools {
+		declared[name] = true
+		declared[ResolveToolIdentity(name).Bin] = true
+	}
+	for name := range conf.ToolAliases {
+		declared[name] = true
+	}
+	for name := range conf.Requires {
+		declared[name] = true
+	}
+	if len(names) == 0 {
+		for name := range conf.Tasks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var out []UndeclaredTool
+	seen := map[UndeclaredTool]bool{}
+	for _, name := range names {
+		t, ok := conf.Tasks[name]
+		if !ok {
+			continue
+		}
+		for _, exe := range commandExecutables(t.Command) {
+			u := UndeclaredTool{Task: name, Tool: exe}
+			if declared[exe] || seen[u] {
+				continue
+			}
+			seen[u] = true
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// ensurePinnedTools fails when a task in order runs an undeclared tool.
+func ensurePinnedTools(conf *cfg.Config, order []string) error {
+	var errs []error
+	for _, u := range UndeclaredTools(conf, order) {
+		errs = append(errs, errors.New(u.String()))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return WithCode(CodeToolNotManaged, fmt.Errorf("%w\n(strict-tools is set in [project]: declare them in [tools] or [requires])", errors.Join(errs...)))
+}
+
+// commandExecutables returns the executables a task command runs: its
+// first word, and the commands of a `sh -c` script.
+func commandExecutables(command string) []string {
+	if strings.TrimSpace(command) == "" {
+		return nil
+	}
+	argv, err := parseCommand(command)
+	if err != nil {
+		return nil
+	}
+	argv = unwrapCommand(argv)
+	if len(argv) == 0 {
+		return nil
+	}
+	exe := executableName(argv[0])
+	if exe == "" {
+		return nil
+	}
+	out := []string{exe}
+	if !slices.Contains(scriptShells, exe) {
+		return out
+	}
+	for i := 1; i < len(argv)-1; i++ {
+		a := argv[i]
+		switch {
+		case strings.HasPrefix(a, "--"):
+		case strings.HasPrefix(a, "-") && strings.Contains(a, "c"):
+			return append(out, scriptExecutables(argv[i+1])...)
+		case strings.HasPrefix(a, "-") || strings.HasPrefix(a, "+"):
+			if strings.HasSuffix(a, "o") {
+				i++ // -o pipefail
+			}
+		default:
+			return out // a script file
+		}
+	}
+	return out
+}
+
+// scriptExecutables returns the first word of every simple command in a
+// shell script, skipping keywords, assignments, and expansions.
+func scriptExecutables(script string) []string {
+	var out []string
+	for _, segment := range splitShellCommands(script) {
+		words, err := shlex.Split(segment)
+		if err != nil {
+			continue
+		}
+		for len(words) > 0 && slices.Contains([]string{"if", "then", "else", "elif", "do", "while", "until", "!", "{"}, words[0]) {
+			words = words[1:]
+		}
+		if len(words) == 0 || slices.Contains([]string{"for", "case", "in", "esac", "fi", "done", "}", "]]"}, words[0]) {
+			continue
+		}
+		words = unwrapCommand(words)
+		if len(words) == 0 {
+			continue
+		}
+		if exe := executableName(words[0]); exe != "" {
+			out = append(out, exe)
+		}
+	}
+	return out
+}
+
+// splitShellCommands splits a script at the operators that start a new
+// command (; & | newline ( ) and backquotes) outside quotes.
+func splitShellCommands(script string) []string {
+	var out []string
+	var b strings.Builder
+	var quote rune
+	escaped := false
+	flush := func() {
+		if s := strings.TrimSpace(b.String()); s != "" {
+			out = append(out, s)
+		}
+		b.Reset()
+	}
+	for _, r := range script {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case strings.ContainsRune(";&|\n()`", r):
+			flush()
+			continue
+		}
+		b.WriteRune(r)
+	}
+	flush()
+	return out
+}
+
+// unwrapCommand drops leading VAR=value assignments and wrappers such as
+// `env FOO=1` so argv starts at the command that actually runs.
+func unwrapCommand(argv []string) []string {
+	for len(argv) > 0 {
+		w := argv[0]
+		switch {
+		case isAssignment(w):
+			argv = argv[1:]
+		case slices.Contains(commandWrappers, w):
+			argv = argv[1:]
+			for len(argv) > 0 && (strings.HasPrefix(argv[0], "-") || isAssignment(argv[0])) {
+				argv = argv[1:]
+			}
+		default:
+			return argv
+		}
+	}
+	return argv
+}
+
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// executableName normalizes a command word to a tool name, or "" for words
+// that are not checked: expansions, argument placeholders, and paths.
+func executableName(word string) string {
+	if word == "" || strings.ContainsAny(word, "$`") || strings.Contains(word, "{{") || isPathLike(word) {
+		return ""
+	}
+	ext := filepath.Ext(word)
+	if strings.EqualFold(ext, ".exe") {
+		word = strings.TrimSuffix(word, ext)
+	}
+	return word
+}
@@ -0,0 +1,68 @@
+package rig
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandExecutables(t *testing.T) {
+	cases := []struct {
+		command string
+		want    []string
+	}{
+		{"protoc --go_out=. api.proto", []string{"protoc"}},
+		{"./scripts/gen.sh", nil},
+		{"env CGO_ENABLED=0 goreleaser build", []string{"goreleaser"}},
+		{`sh -c 'mkdir -p out && protoc -I api api/*.proto | tee log; echo "a; b"'`, []string{"sh", "mkdir", "protoc", "tee", "echo"}},
+		{`bash -euo pipefail -c 'for f in *.sql; do psql -f "$f"; done'`, []string{"bash", "psql"}},
+		{`sh -c 'V=$(git describe) go build -ldflags "-X main.v=$V"'`, []string{"sh", "git", "go"}},
+		{"sh scripts/ci.sh", []string{"sh"}},
+	}
+	for _, c := range cases {
+		if got := commandExecutables(c.command); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("commandExecutables(%q) = %q, want %q", c.command, got, c.want)
+		}
+	}
+}
+
+func TestRunStrictToolsRejectsUndeclaredTools(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), `
+[project]
+name = "demo"
+strict-tools = true
+
+[requires]
+docker = "*"
+
+[tasks]
+lint = "golangci-lint run"
+up = "docker compose up"
+gen = "sh -c 'protoc -I api api/*.proto && touch gen.done'"
+`, 0o644)
+	writeTestFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+
+	conf, _, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if got := UndeclaredTools(conf, []string{"lint"}); !reflect.DeepEqual(got, []UndeclaredTool{{Task: "lint", Tool: "golangci-lint"}}) {
+		t.Fatalf("UndeclaredTools(lint) = %v", got)
+	}
+	conf.Tools = map[string]string{"github.com/golangci/golangci-lint/cmd/golangci-lint": "1.59.0"}
+	got := UndeclaredTools(conf, nil)
+	want := []UndeclaredTool{{Task: "gen", Tool: "protoc"}, {Task: "gen", Tool: "touch"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("UndeclaredTools = %v, want %v", got, want)
+	}
+
+	err = Run(dir, "gen", nil)
+	if err == nil || !strings.Contains(err.Error(), "task 'gen' uses 'protoc' which is not pinned in [tools]") {
+		t.Fatalf("expected strict-tools failure, got %v", err)
+	}
+	if CodeOf(err) != CodeToolNotManaged {
+		t.Fatalf("code = %q, want %q", CodeOf(err), CodeToolNotManaged)
+	}
+}
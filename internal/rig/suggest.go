@@ -0,0 +1,100 @@
+package rig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// maxSuggestions caps how many near matches DidYouMean lists.
+const maxSuggestions = 3
+
+// Suggest returns the candidates closest to name, nearest first. A candidate
+// qualifies when its case-insensitive edit distance is at most a third of
+// name's length (minimum 2) or when one is a prefix of the other.
+func Suggest(name string, candidates []string) []string {
+	orig := strings.TrimSpace(name)
+	name = strings.ToLower(orig)
+	if name == "" {
+		return nil
+	}
+	limit := max(2, len(name)/3)
+	type match struct {
+		name string
+		dist int
+	}
+	seen := map[string]bool{}
+	var matches []match
+	for _, c := range candidates {
+		if c == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+		if c == orig {
+			continue
+		}
+		lc := strings.ToLower(c)
+		d := cfg.EditDistance(name, lc)
+		if d > limit && !strings.HasPrefix(lc, name) && !strings.HasPrefix(name, lc) {
+			continue
+		}
+		matches = append(matches, match{c, d})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].name < matches[j].name
+	})
+	out := make([]string, 0, maxSuggestions)
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		out = append(out, matches[i].name)
+	}
+	return out
+}
+
+// DidYouMean formats Suggest's matches as an error suffix, e.g.
+// `; did you mean "build"?`, or "" when nothing is close.
+func DidYouMean(name string, candidates []string) string {
+	s := Suggest(name, candidates)
+	if len(s) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(s))
+	for i, v := range s {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	if len(quoted) == 1 {
+		return "; did you mean " + quoted[0] + "?"
+	}
+	return "; did you mean " + strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1] + "?"
+}
+
+// TaskNames returns the names of tasks.
+func TaskNames(tasks cfg.TasksMap) []string {
+	names := make([]string, 0, len(tasks))
+	for name := range tasks {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ToolNameCandidates returns the names a tool can be referred to by: rig.lock
+// tool names and binaries, plus [tool-aliases] short names.
+func ToolNameCandidates(lock Lockfile) []string {
+	var names []string
+	for _, lt := range lock.Tools {
+		if name, _, err := ParseRequested(lt.Requested); err == nil {
+			names = append(names, name)
+		}
+		if bin := strings.TrimSpace(lt.Bin); bin != "" {
+			names = append(names, bin)
+		}
+	}
+	for alias := range toolAliases {
+		names = append(names, alias)
+	}
+	return names
+}
@@ -0,0 +1,37 @@
+package rig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"build", "build-api", "test", "lint", "golangci-lint"}
+	cases := []struct {
+		name string
+		want []string
+	}{
+		{"biuld", []string{"build"}},
+		{"tets", []string{"test"}},
+		{"Lint", []string{"lint"}},
+		{"golangci", []string{"golangci-lint"}},
+		{"deploy", []string{}},
+	}
+	for _, tc := range cases {
+		if got := Suggest(tc.name, candidates); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Suggest(%q) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	if got := DidYouMean("biuld", []string{"build", "test"}); got != `; did you mean "build"?` {
+		t.Fatalf("unexpected suffix %q", got)
+	}
+	if got := DidYouMean("tst", []string{"test", "tsx", "lint"}); got != `; did you mean "test" or "tsx"?` {
+		t.Fatalf("unexpected suffix %q", got)
+	}
+	if got := DidYouMean("deploy", []string{"build"}); got != "" {
+		t.Fatalf("expected no suggestion, got %q", got)
+	}
+}
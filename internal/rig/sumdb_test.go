@@ -0,0 +1,75 @@
+package rig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSumPolicyCovers(t *testing.T) {
+	cases := []struct {
+		policy SumPolicy
+		module string
+		want   bool
+	}{
+		{SumPolicy{SumDB: "sum.golang.org"}, "github.com/a/b", true},
+		{SumPolicy{SumDB: "off"}, "github.com/a/b", false},
+		{SumPolicy{SumDB: "sum.golang.org", GoPrivate: "github.com/corp"}, "github.com/corp/tool", false},
+		{SumPolicy{SumDB: "sum.golang.org", GoPrivate: "github.com/corp"}, "github.com/corpx/tool", true},
+		{SumPolicy{SumDB: "sum.golang.org", GoPrivate: "*.corp.example"}, "git.corp.example/x/y", false},
+		{SumPolicy{SumDB: "sum.golang.org", GoPrivate: "github.com/corp", NoSumDB: "example.com"}, "github.com/corp/tool", true},
+		{SumPolicy{SumDB: "sum.golang.org", NoSumDB: "example.com/a/b/c"}, "example.com/a/b", true},
+	}
+	for _, c := range cases {
+		if got := c.policy.Covers(c.module); got != c.want {
+			t.Errorf("%+v.Covers(%q) = %v, want %v", c.policy, c.module, got, c.want)
+		}
+	}
+}
+
+func TestVerifyToolSums(t *testing.T) {
+	oldPolicy, oldDownload := goSumPolicy, goModDownloadSum
+	t.Cleanup(func() { goSumPolicy, goModDownloadSum = oldPolicy, oldDownload })
+
+	goSumPolicy = func(string, []string) (SumPolicy, error) {
+		return SumPolicy{SumDB: "sum.golang.org", GoPrivate: "git.corp.example"}, nil
+	}
+	sums := map[string]string{
+		"github.com/cespare/reflex@v0.3.1":  "h1:reflex",
+		"git.corp.example/tools/gen@v1.0.0": "h1:gen",
+	}
+	var downloads []string
+	goModDownloadSum = func(module, version, workDir string, env []string) (string, error) {
+		downloads = append(downloads, module+"@"+version)
+		return sums[module+"@"+version], nil
+	}
+	public := LockedTool{Resolved: "github.com/cespare/reflex@v0.3.1", Checksum: "h1:fromlist"}
+	private := LockedTool{Resolved: "git.corp.example/tools/gen@v1.0.0"}
+
+	tools := []LockedTool{public}
+	if err := VerifyToolSums(tools, nil, "", nil, false); err != nil {
+		t.Fatalf("public: %v", err)
+	}
+	if tools[0].Checksum != "h1:reflex" {
+		t.Fatalf("checksum = %q", tools[0].Checksum)
+	}
+
+	err := VerifyToolSums([]LockedTool{private}, nil, "", nil, false)
+	if err == nil || !strings.Contains(err.Error(), "--insecure") {
+		t.Fatalf("expected rejection of unverifiable module, got %v", err)
+	}
+
+	downloads = nil
+	if err := VerifyToolSums([]LockedTool{private}, nil, "", nil, true); err != nil || len(downloads) != 0 {
+		t.Fatalf("insecure: err=%v downloads=%v", err, downloads)
+	}
+
+	// A sum already recorded in rig.lock vouches for private modules, and must match.
+	known := map[string]string{private.Resolved: "h1:gen"}
+	if err := VerifyToolSums([]LockedTool{private}, known, "", nil, false); err != nil {
+		t.Fatalf("known sum: %v", err)
+	}
+	known[private.Resolved] = "h1:other"
+	if err := VerifyToolSums([]LockedTool{private}, known, "", nil, true); err == nil {
+		t.Fatal("expected mismatch against rig.lock even with --insecure")
+	}
+}
@@ -0,0 +1,202 @@
+package rig
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// Sync plan actions.
+const (
+	SyncInstall = "install" // not in rig.lock (or no binary yet)
+	SyncUpgrade = "upgrade" // resolved version changes
+	SyncRebuild = "rebuild" // same version, binary missing or checksum differs
+	SyncKeep    = "keep"    // nothing to do
+	SyncRemove  = "remove"  // dropped from rig.lock (binary stays unless --prune)
+	SyncPin     = "pin"     // remote include pinned in rig.lock (new or changed)
+	SyncUnpin   = "unpin"   // remote include no longer used
+)
+
+// SyncAction describes what `rig sync` would do for one tool.
+type SyncAction struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Bin    string `json:"bin,omitempty"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SyncPlan is the result of PlanSync.
+type SyncPlan struct {
+	LockPath      string       `json:"lockPath"`
+	Actions       []SyncAction `json:"actions"`
+	ToolchainFrom string       `json:"toolchainFrom,omitempty"`
+	ToolchainTo   string       `json:"toolchainTo,omitempty"`
+	// MetaFrom and MetaTo are rig.lock's [meta] before and after, when it changes.
+	MetaFrom    *LockMeta `json:"metaFrom,omitempty"`
+	MetaTo      *LockMeta `json:"metaTo,omitempty"`
+	LockChanged bool      `json:"lockChanged"`
+	LockCreated bool      `json:"lockCreated"`
+	// Prune lists .rig/bin files that `--prune` would delete (set by the caller).
+	Prune []string `json:"prune,omitempty"`
+	// Includes lists remote include pin changes (set by the caller, see PlanIncludes).
+	Includes []SyncAction `json:"includes,omitempty"`
+}
+
+// Changes counts actions other than keep.
+func (p SyncPlan) Changes() int {
+	n := 0
+	for _, a := range p.Actions {
+		if a.Action != SyncKeep {
+			n++
+		}
+	}
+	return n
+}
+
+// PlanSync compares freshly resolved tools (and toolchain) against the current
+// rig.lock and .rig/bin without writing anything. meta is the [meta] sync
+// would write (see NewLockMeta); nil keeps the current one.
+func PlanSync(configPath string, resolved []LockedTool, toolchain *ToolchainLock, meta *LockMeta) (SyncPlan, error) {
+	lockPath := rigLockPathForConfig(configPath)
+	plan := SyncPlan{LockPath: lockPath}
+
+	old, err := ReadLockfile(lockPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return SyncPlan{}, err
+		}
+		plan.LockCreated = true
+		old = Lockfile{Schema: LockSchema0}
+	}
+
+	oldByName := map[string]LockedTool{}
+	for _, lt := range old.Tools {
+		if name, _, err := ParseRequested(lt.Requested); err == nil {
+			oldByName[name] = lt
+		}
+	}
+
+	// Include pins are planned separately (PlanIncludes).
+	next := Lockfile{Schema: LockSchema0, Meta: meta, Toolchain: toolchain, Includes: old.Includes}
+	if meta == nil {
+		next.Meta = old.Meta
+	}
+	seen := map[string]struct{}{}
+	for _, lt := range resolved {
+		name, _, err := ParseRequested(lt.Requested)
+		if err != nil {
+			return SyncPlan{}, err
+		}
+		seen[name] = struct{}{}
+		bin := strings.TrimSpace(lt.Bin)
+		if bin == "" {
+			bin = ResolveToolIdentity(name).Bin
+		}
+		_, toVer := SplitResolved(lt.Resolved)
+		a := SyncAction{Name: name, Bin: bin, To: toVer}
+
+		prev, had := oldByName[name]
+		binPath := ToolBinPath(configPath, bin)
+		sum, sumErr := ComputeFileSHA256(binPath)
+		switch {
+		case !had:
+			a.Action = SyncInstall
+			a.Reason = "not in rig.lock"
+		case prev.Resolved != lt.Resolved:
+			_, a.From = SplitResolved(prev.Resolved)
+			a.Action = SyncUpgrade
+		case sumErr != nil:
+			a.Action = SyncRebuild
+			a.Reason = "binary missing"
+		case prev.SHA256 != "" && !strings.EqualFold(prev.SHA256, sum):
+			a.Action = SyncRebuild
+			a.Reason = "checksum differs from rig.lock"
+		case prev.Go != "" && toolchain != nil && toolchain.Go != nil && prev.Go != toolchain.Go.Detected:
+			a.Action = SyncRebuild
+			a.Reason = "built with go" + prev.Go + ", toolchain is go" + toolchain.Go.Detected
+		default:
+			a.Action = SyncKeep
+			lt.SHA256 = prev.SHA256
+			lt.Go = prev.Go
+		}
+		plan.Actions = append(plan.Actions, a)
+		next.Tools = append(next.Tools, lt)
+	}
+	for name, lt := range oldByName {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		_, from := SplitResolved(lt.Resolved)
+		plan.Actions = append(plan.Actions, SyncAction{Name: name, Action: SyncRemove, Bin: lt.Bin, From: from, Reason: "no longer declared"})
+	}
+	sort.Slice(plan.Actions, func(i, j int) bool { return plan.Actions[i].Name < plan.Actions[j].Name })
+
+	if old.Toolchain != nil && old.Toolchain.Go != nil {
+		plan.ToolchainFrom = old.Toolchain.Go.Requested
+	}
+	if toolchain != nil && toolchain.Go != nil {
+		plan.ToolchainTo = toolchain.Go.Requested
+	}
+
+	next = keepGenerated(old, next)
+	if !sameLockMeta(old.Meta, next.Meta) {
+		plan.MetaFrom, plan.MetaTo = old.Meta, next.Meta
+	}
+
+	// Installed tools get fresh checksums; compare the rest byte-for-byte.
+	if plan.LockCreated || plan.Changes() > 0 {
+		plan.LockChanged = true
+	} else {
+		a, err1 := MarshalLockfile(old)
+		b, err2 := MarshalLockfile(next)
+		plan.LockChanged = err1 != nil || err2 != nil || !bytes.Equal(a, b)
+	}
+	return plan, nil
+}
+
+func sameLockMeta(a, b *LockMeta) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// PlanIncludes compares the remote include pins in rig.lock with the includes
+// the config loaded. Unchanged pins are omitted.
+func PlanIncludes(pinned, loaded []cfg.IncludePin) []SyncAction {
+	old := make(map[string]string, len(pinned))
+	for _, p := range pinned {
+		old[p.Source] = p.SHA256
+	}
+	var out []SyncAction
+	seen := map[string]struct{}{}
+	for _, inc := range loaded {
+		seen[inc.Source] = struct{}{}
+		prev, ok := old[inc.Source]
+		switch {
+		case !ok:
+			out = append(out, SyncAction{Name: inc.Source, Action: SyncPin, To: shortSum(inc.SHA256), Reason: "not in rig.lock"})
+		case prev != inc.SHA256:
+			out = append(out, SyncAction{Name: inc.Source, Action: SyncPin, From: shortSum(prev), To: shortSum(inc.SHA256), Reason: "content changed"})
+		}
+	}
+	for _, p := range pinned {
+		if _, ok := seen[p.Source]; !ok {
+			out = append(out, SyncAction{Name: p.Source, Action: SyncUnpin, From: shortSum(p.SHA256), Reason: "no longer included"})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func shortSum(sum string) string {
+	if len(sum) > 12 {
+		return sum[:12]
+	}
+	return sum
+}
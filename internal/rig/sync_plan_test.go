@@ -0,0 +1,130 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+func TestPlanSyncClassifiesChanges(t *testing.T) {
+	dir := setupToolsFixture(t)
+	confPath := filepath.Join(dir, "rig.toml")
+	lockBefore, err := os.ReadFile(filepath.Join(dir, "rig.lock"))
+	if err != nil {
+		t.Fatalf("read lock: %v", err)
+	}
+
+	// mockery unchanged, golangci-lint upgraded, reflex new; nothing else declared.
+	resolved := []LockedTool{
+		{Kind: "go-binary", Requested: "mockery@v2.46.0", Resolved: "github.com/vektra/mockery/v2@v2.46.0", Module: "github.com/vektra/mockery/v2", Bin: "mockery"},
+		{Kind: "go-binary", Requested: "golangci-lint@1.63.0", Resolved: "github.com/golangci/golangci-lint@v1.63.0", Module: "github.com/golangci/golangci-lint", Bin: "golangci-lint"},
+		{Kind: "go-binary", Requested: "reflex@latest", Resolved: "github.com/cespare/reflex@v0.3.1", Module: "github.com/cespare/reflex", Bin: "reflex"},
+	}
+	plan, err := PlanSync(confPath, resolved, nil, nil)
+	if err != nil {
+		t.Fatalf("PlanSync: %v", err)
+	}
+	got := map[string]string{}
+	for _, a := range plan.Actions {
+		got[a.Name] = a.Action
+	}
+	want := map[string]string{"golangci-lint": SyncUpgrade, "mockery": SyncKeep, "reflex": SyncInstall}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("actions=%v, want %v", got, want)
+	}
+	if plan.Actions[0].From != "v1.62.0" || plan.Actions[0].To != "v1.63.0" {
+		t.Fatalf("unexpected upgrade row: %+v", plan.Actions[0])
+	}
+	if !plan.LockChanged || plan.LockCreated {
+		t.Fatalf("expected lock change, got %+v", plan)
+	}
+
+	// Removing a binary yields a rebuild; an unchanged plan leaves rig.lock alone.
+	if err := os.Remove(filepath.Join(dir, ".rig", "bin", "mockery")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	plan, err = PlanSync(confPath, resolved[:1], nil, nil)
+	if err != nil {
+		t.Fatalf("PlanSync: %v", err)
+	}
+	if plan.Actions[0].Name != "golangci-lint" || plan.Actions[0].Action != SyncRemove || plan.Actions[1].Action != SyncRebuild {
+		t.Fatalf("unexpected actions: %+v", plan.Actions)
+	}
+
+	lockAfter, err := os.ReadFile(filepath.Join(dir, "rig.lock"))
+	if err != nil || string(lockAfter) != string(lockBefore) {
+		t.Fatalf("PlanSync must not modify rig.lock")
+	}
+}
+
+func TestPlanSyncUnchanged(t *testing.T) {
+	dir := setupToolsFixture(t)
+	lock, err := ReadLockfile(filepath.Join(dir, "rig.lock"))
+	if err != nil {
+		t.Fatalf("ReadLockfile: %v", err)
+	}
+	resolved := make([]LockedTool, len(lock.Tools))
+	for i, lt := range lock.Tools {
+		lt.SHA256 = ""
+		resolved[i] = lt
+	}
+	plan, err := PlanSync(filepath.Join(dir, "rig.toml"), resolved, nil, nil)
+	if err != nil {
+		t.Fatalf("PlanSync: %v", err)
+	}
+	if plan.Changes() != 0 || plan.LockChanged {
+		t.Fatalf("expected no changes, got %+v", plan)
+	}
+}
+
+func TestPlanSyncDiffsMeta(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	dir := setupToolsFixture(t)
+	lockPath := filepath.Join(dir, "rig.lock")
+	lock, err := ReadLockfile(lockPath)
+	if err != nil {
+		t.Fatalf("ReadLockfile: %v", err)
+	}
+	resolved := make([]LockedTool, len(lock.Tools))
+	for i, lt := range lock.Tools {
+		lt.SHA256 = ""
+		resolved[i] = lt
+	}
+	conf := &cfg.Config{Tools: map[string]string{"mockery": "2.46.0"}}
+
+	// A lock without [meta] gains one.
+	plan, err := PlanSync(filepath.Join(dir, "rig.toml"), resolved, nil, NewLockMeta("v1.2.3", conf))
+	if err != nil {
+		t.Fatalf("PlanSync: %v", err)
+	}
+	if plan.MetaFrom != nil || plan.MetaTo == nil || plan.MetaTo.RigVersion != "v1.2.3" || !plan.LockChanged || plan.Changes() != 0 {
+		t.Fatalf("expected a [meta]-only change, got %+v", plan)
+	}
+
+	// Only a newer timestamp: nothing to write.
+	lock.Meta = NewLockMeta("v1.2.3", conf)
+	if err := WriteLockfile(lockPath, lock); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SOURCE_DATE_EPOCH", "1800000000")
+	plan, err = PlanSync(filepath.Join(dir, "rig.toml"), resolved, nil, NewLockMeta("v1.2.3", conf))
+	if err != nil {
+		t.Fatalf("PlanSync: %v", err)
+	}
+	if plan.MetaTo != nil || plan.LockChanged {
+		t.Fatalf("expected no change, got %+v", plan)
+	}
+
+	// An edited [tools] table or another rig version shows up.
+	plan, err = PlanSync(filepath.Join(dir, "rig.toml"), resolved, nil, NewLockMeta("v1.3.0", &cfg.Config{Tools: map[string]string{"mockery": "2.46"}}))
+	if err != nil {
+		t.Fatalf("PlanSync: %v", err)
+	}
+	if plan.MetaFrom == nil || plan.MetaTo == nil || plan.MetaFrom.RigVersion != "v1.2.3" || plan.MetaTo.RigVersion != "v1.3.0" ||
+		plan.MetaFrom.ManifestSHA256 == plan.MetaTo.ManifestSHA256 || !plan.LockChanged {
+		t.Fatalf("expected a [meta] diff, got %+v", plan)
+	}
+}
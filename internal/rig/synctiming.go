@@ -0,0 +1,102 @@
+package rig
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SyncPhase is one step of installing a tool during `rig sync`.
+type SyncPhase int
+
+const (
+	// SyncResolve is the `go list -m` (or registry) lookup of the version.
+	SyncResolve SyncPhase = iota
+	// SyncDownload fetches and verifies the module (or pulls the OCI image).
+	SyncDownload
+	// SyncCompile is `go install` into the bin directory.
+	SyncCompile
+	// SyncHash checksums the installed binary and reads its builder.
+	SyncHash
+)
+
+// ToolSyncTiming is how long each phase took for one tool. Phases a sync
+// skipped (resolution under --from-lock, say) are zero.
+type ToolSyncTiming struct {
+	Tool       string `json:"tool"`
+	ResolveMS  int64  `json:"resolve_ms"`
+	DownloadMS int64  `json:"download_ms"`
+	CompileMS  int64  `json:"compile_ms"`
+	HashMS     int64  `json:"hash_ms"`
+	TotalMS    int64  `json:"total_ms"`
+}
+
+// SyncTimingReport is the timing summary `rig sync` prints. TotalMS is wall
+// time; tools install concurrently, so it is usually less than the sum of
+// the per-tool totals.
+type SyncTimingReport struct {
+	Tools   []ToolSyncTiming `json:"tools"`
+	TotalMS int64            `json:"total_ms"`
+}
+
+// SyncTimings collects per-tool phase durations from concurrent installs.
+// A nil *SyncTimings records nothing, so callers that do not report timings
+// pass nil.
+type SyncTimings struct {
+	mu    sync.Mutex
+	start time.Time
+	tools map[string]*ToolSyncTiming
+}
+
+// NewSyncTimings starts the wall clock for a sync.
+func NewSyncTimings() *SyncTimings {
+	return &SyncTimings{start: time.Now(), tools: map[string]*ToolSyncTiming{}}
+}
+
+// Start times phase for tool until the returned func is called.
+func (t *SyncTimings) Start(tool string, phase SyncPhase) func() {
+	if t == nil {
+		return func() {}
+	}
+	begin := time.Now()
+	return func() { t.Add(tool, phase, time.Since(begin)) }
+}
+
+// Add records d against phase for tool.
+func (t *SyncTimings) Add(tool string, phase SyncPhase, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	row := t.tools[tool]
+	if row == nil {
+		row = &ToolSyncTiming{Tool: tool}
+		t.tools[tool] = row
+	}
+	ms := d.Milliseconds()
+	switch phase {
+	case SyncResolve:
+		row.ResolveMS += ms
+	case SyncDownload:
+		row.DownloadMS += ms
+	case SyncCompile:
+		row.CompileMS += ms
+	case SyncHash:
+		row.HashMS += ms
+	}
+	row.TotalMS += ms
+}
+
+// Report returns the per-tool rows sorted by tool name, with the wall time
+// since NewSyncTimings.
+func (t *SyncTimings) Report() SyncTimingReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rep := SyncTimingReport{Tools: make([]ToolSyncTiming, 0, len(t.tools)), TotalMS: time.Since(t.start).Milliseconds()}
+	for _, row := range t.tools {
+		rep.Tools = append(rep.Tools, *row)
+	}
+	sort.Slice(rep.Tools, func(i, j int) bool { return rep.Tools[i].Tool < rep.Tools[j].Tool })
+	return rep
+}
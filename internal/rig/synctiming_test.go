@@ -0,0 +1,61 @@
+package rig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSyncTimingsReport(t *testing.T) {
+	timings := NewSyncTimings()
+	timings.Add("reflex", SyncCompile, 3*time.Second)
+	timings.Add("golangci-lint", SyncResolve, 200*time.Millisecond)
+	timings.Add("golangci-lint", SyncDownload, 2*time.Second)
+	timings.Add("golangci-lint", SyncCompile, 30*time.Second)
+	timings.Add("golangci-lint", SyncHash, 50*time.Millisecond)
+
+	rep := timings.Report()
+	if len(rep.Tools) != 2 || rep.Tools[0].Tool != "golangci-lint" || rep.Tools[1].Tool != "reflex" {
+		t.Fatalf("tools = %+v, want golangci-lint then reflex", rep.Tools)
+	}
+	want := ToolSyncTiming{Tool: "golangci-lint", ResolveMS: 200, DownloadMS: 2000, CompileMS: 30000, HashMS: 50, TotalMS: 32250}
+	if rep.Tools[0] != want {
+		t.Fatalf("golangci-lint = %+v, want %+v", rep.Tools[0], want)
+	}
+	if rep.TotalMS < 0 {
+		t.Fatalf("TotalMS = %d", rep.TotalMS)
+	}
+
+	var none *SyncTimings
+	none.Add("x", SyncHash, time.Second)
+	none.Start("x", SyncCompile)()
+}
+
+func TestSyncTimingsRecordsResolveAndDownload(t *testing.T) {
+	oldList, oldPolicy, oldDownload := goListModuleVersion, goSumPolicy, goModDownloadSum
+	t.Cleanup(func() { goListModuleVersion, goSumPolicy, goModDownloadSum = oldList, oldPolicy, oldDownload })
+	goListModuleVersion = func(module, version, workDir string, env []string) (string, string, error) {
+		time.Sleep(5 * time.Millisecond)
+		return "v0.3.1", "h1:sum", nil
+	}
+	goSumPolicy = func(string, []string) (SumPolicy, error) { return SumPolicy{SumDB: "sum.golang.org"}, nil }
+	goModDownloadSum = func(module, version, workDir string, env []string) (string, error) {
+		time.Sleep(5 * time.Millisecond)
+		return "h1:sum", nil
+	}
+
+	timings := NewSyncTimings()
+	locked, err := ResolveLockedToolsTimed(map[string]string{"reflex": "v0.3.1"}, "", nil, timings)
+	if err != nil {
+		t.Fatalf("ResolveLockedToolsTimed: %v", err)
+	}
+	if err := VerifyToolSumsTimed(locked, nil, "", nil, false, timings); err != nil {
+		t.Fatalf("VerifyToolSumsTimed: %v", err)
+	}
+	rep := timings.Report()
+	if len(rep.Tools) != 1 || rep.Tools[0].Tool != "reflex" {
+		t.Fatalf("tools = %+v", rep.Tools)
+	}
+	if row := rep.Tools[0]; row.ResolveMS < 5 || row.DownloadMS < 5 || row.CompileMS != 0 {
+		t.Fatalf("reflex = %+v, want resolve and download recorded", row)
+	}
+}
@@ -0,0 +1,114 @@
+package rig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// taskCacheEntry is what .rig/cache/tasks.json remembers about the last
+// successful run of a task: its cache key (see taskCacheKey) and a digest of
+// the outputs it left behind.
+type taskCacheEntry struct {
+	Key     string `json:"key"`
+	Outputs string `json:"outputs"`
+}
+
+func taskCachePath(configPath string) string {
+	return filepath.Join(CacheDir(configPath), "cache", "tasks.json")
+}
+
+// readTaskCache returns the recorded entries per task. A missing or
+// unreadable file is an empty cache.
+func readTaskCache(configPath string) map[string]taskCacheEntry {
+	c := map[string]taskCacheEntry{}
+	b, err := os.ReadFile(taskCachePath(configPath))
+	if err != nil {
+		return c
+	}
+	if json.Unmarshal(b, &c) != nil {
+		return map[string]taskCacheEntry{}
+	}
+	return c
+}
+
+// taskUpToDate reports whether name last succeeded with key and its outputs
+// are still exactly what that run left.
+func (r *taskRunner) taskUpToDate(name string, outputs []string, key string) bool {
+	r.mu.Lock()
+	e, ok := readTaskCache(r.confPath)[name]
+	r.mu.Unlock()
+	if !ok || e.Key != key {
+		return false
+	}
+	digest, n, err := outputsDigest(filepath.Dir(r.confPath), outputs)
+	return err == nil && n > 0 && digest == e.Outputs
+}
+
+// recordTaskCache remembers that name succeeded with key. The cache is best
+// effort: errors are ignored, and a task without output files is not
+// recorded.
+func (r *taskRunner) recordTaskCache(name string, outputs []string, key string) {
+	digest, n, err := outputsDigest(filepath.Dir(r.confPath), outputs)
+	if err != nil || n == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := readTaskCache(r.confPath)
+	c[name] = taskCacheEntry{Key: key, Outputs: digest}
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return
+	}
+	path := taskCachePath(r.confPath)
+	if os.MkdirAll(filepath.Dir(path), 0o755) != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if os.WriteFile(tmp, append(b, '\n'), 0o644) == nil {
+		_ = os.Rename(tmp, path)
+	}
+}
+
+// outputsDigest hashes the names and contents of the files the output
+// patterns match under base, and returns how many files there were.
+func outputsDigest(base string, outputs []string) (string, int, error) {
+	var files []string
+	for _, pat := range outputs {
+		matches, err := globUnder(base, pat)
+		if err != nil {
+			return "", 0, fmt.Errorf("outputs %q: %w", pat, err)
+		}
+		for _, m := range matches {
+			err := filepath.WalkDir(m, func(p string, d fs.DirEntry, err error) error {
+				if err != nil || !d.Type().IsRegular() {
+					return err
+				}
+				files = append(files, p)
+				return nil
+			})
+			if err != nil {
+				return "", 0, err
+			}
+		}
+	}
+	sort.Strings(files)
+	files = slices.Compact(files)
+	h := sha256.New()
+	for _, f := range files {
+		sum, err := ComputeFileSHA256(f)
+		if err != nil {
+			return "", 0, err
+		}
+		rel, _ := filepath.Rel(base, f)
+		fmt.Fprintf(h, "output %s %s\x00", filepath.ToSlash(rel), sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)), len(files), nil
+}
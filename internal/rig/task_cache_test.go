@@ -0,0 +1,97 @@
+package rig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+func TestRunSkipsUpToDateTask(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), `
+[tasks.gen]
+command = "sh -c 'cat api.proto > gen.txt; echo run >> runs.log'"
+sources = ["api.proto"]
+outputs = ["gen.txt"]
+`, 0o644)
+	writeTestFile(t, filepath.Join(dir, "rig.lock"), "schema = 0\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "api.proto"), "v1\n", 0o644)
+
+	runs := func() int {
+		t.Helper()
+		b, _ := os.ReadFile(filepath.Join(dir, "runs.log"))
+		n := 0
+		for _, c := range b {
+			if c == '\n' {
+				n++
+			}
+		}
+		return n
+	}
+	run := func(opts RunOptions) {
+		t.Helper()
+		if err := RunWith(context.Background(), dir, "gen", nil, opts); err != nil {
+			t.Fatalf("run gen: %v", err)
+		}
+	}
+
+	run(RunOptions{})
+	run(RunOptions{})
+	if got := runs(); got != 1 {
+		t.Fatalf("second run should be skipped as up to date, ran %d times", got)
+	}
+	writeTestFile(t, filepath.Join(dir, "api.proto"), "v2\n", 0o644)
+	run(RunOptions{})
+	if got := runs(); got != 2 {
+		t.Fatalf("a changed source should rerun the task, ran %d times", got)
+	}
+	writeTestFile(t, filepath.Join(dir, "gen.txt"), "edited\n", 0o644)
+	run(RunOptions{})
+	if got := runs(); got != 3 {
+		t.Fatalf("a changed output should rerun the task, ran %d times", got)
+	}
+	run(RunOptions{Force: true})
+	if got := runs(); got != 4 {
+		t.Fatalf("Force should rerun an up-to-date task, ran %d times", got)
+	}
+	run(RunOptions{})
+	if got := runs(); got != 4 {
+		t.Fatalf("run after Force should be up to date, ran %d times", got)
+	}
+}
+
+func TestTaskCacheKeyFollowsArgvAndLockedTool(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "api.proto"), "v1\n", 0o644)
+	task := cfg.Task{Command: "mockery --dir $PKG", Sources: []string{"api.proto"}, Outputs: []string{"mocks"}}
+	lock := Lockfile{Tools: []LockedTool{{Kind: "go-binary", Requested: "mockery@2.46.0", Resolved: "github.com/vektra/mockery/v2@v2.46.0", Bin: "mockery", SHA256: "aaa"}}}
+	key := func(lock Lockfile, environ ...string) string {
+		t.Helper()
+		r := &taskRunner{confPath: filepath.Join(dir, "rig.toml"), lock: lock, environ: environ}
+		k, err := r.taskCacheKey("mocks", task, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("taskCacheKey: %v", err)
+		}
+		return k
+	}
+
+	base := key(lock, "PKG=internal")
+	if key(lock, "PKG=internal") != base {
+		t.Fatal("the cache key should be stable")
+	}
+	if key(lock, "PKG=cmd") == base {
+		t.Fatal("a variable the command expands should change the cache key")
+	}
+	relocked := Lockfile{Tools: append([]LockedTool(nil), lock.Tools...)}
+	relocked.Tools[0].SHA256 = "bbb"
+	if key(relocked, "PKG=internal") == base {
+		t.Fatal("a different locked mockery should change the cache key")
+	}
+}
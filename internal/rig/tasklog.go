@@ -0,0 +1,260 @@
+package rig
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// taskLogMaxBytes is the size at which a task log is rotated to <task>.log.1
+// when it is next opened.
+const taskLogMaxBytes = 8 << 20
+
+// TaskLogDir is the logs directory under CacheDir (.rig/logs by default),
+// where `rig dev` and `rig schedule` keep their output for `rig logs`.
+func TaskLogDir(configPath string) string {
+	return filepath.Join(CacheDir(configPath), "logs")
+}
+
+// TaskLogPath is the log of one task (or of `rig schedule`).
+func TaskLogPath(configPath, task string) string {
+	return filepath.Join(TaskLogDir(configPath), task+".log")
+}
+
+// TaskLogNames lists the tasks with a log, sorted.
+func TaskLogNames(configPath string) []string {
+	entries, _ := os.ReadDir(TaskLogDir(configPath))
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".log"); ok && !e.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TaskLog appends lines to a task log, each stamped with the time and the
+// process that wrote it:
+//
+//	2026-01-02T15:04:05.123Z [dev#2] listening on :8080
+//
+// Processes are named by the writer: `rig dev` uses dev#<n> for the n-th
+// start of the command (dev#<n>:err for its stderr), test for test-on-save,
+// and rig for its own status lines. A nil *TaskLog discards everything.
+type TaskLog struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenTaskLog opens a task log for appending, rotating it first when it has
+// grown past taskLogMaxBytes.
+func OpenTaskLog(configPath, task string) (*TaskLog, error) {
+	path := TaskLogPath(configPath, task)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > taskLogMaxBytes {
+		_ = os.Rename(path, path+".1")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &TaskLog{f: f}, nil
+}
+
+// Writer returns an io.Writer that logs each complete line written to it as
+// process; FlushTaskLogWriter logs a trailing partial line.
+func (l *TaskLog) Writer(process string) io.Writer {
+	if l == nil {
+		return io.Discard
+	}
+	return &taskLogWriter{log: l, process: process}
+}
+
+// Printf logs one formatted line as process.
+func (l *TaskLog) Printf(process, format string, args ...any) {
+	if l == nil {
+		return
+	}
+	l.write(process, fmt.Sprintf(format, args...))
+}
+
+func (l *TaskLog) write(process, line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = fmt.Fprintf(l.f, "%s [%s] %s\n", time.Now().UTC().Format(time.RFC3339Nano), process, line)
+}
+
+// Close closes the log file.
+func (l *TaskLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+type taskLogWriter struct {
+	log     *TaskLog
+	process string
+	mu      sync.Mutex
+	partial []byte
+}
+
+func (w *taskLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.log.write(w.process, strings.TrimRight(string(w.partial[:i]), "\r"))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// FlushTaskLogWriter logs the partial line a TaskLog writer is holding, e.g.
+// after the process exits without a final newline.
+func FlushTaskLogWriter(w io.Writer) {
+	tw, ok := w.(*taskLogWriter)
+	if !ok {
+		return
+	}
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if len(tw.partial) > 0 {
+		tw.log.write(tw.process, string(tw.partial))
+		tw.partial = nil
+	}
+}
+
+// LogLine is one parsed log line. Process is empty for logs without process
+// tags, such as schedule.log.
+type LogLine struct {
+	At      time.Time
+	Process string
+	Text    string
+}
+
+// ParseLogLine parses "<RFC3339 time> [process] text" or "<time> text".
+func ParseLogLine(s string) (LogLine, bool) {
+	stamp, rest, ok := strings.Cut(s, " ")
+	if !ok {
+		return LogLine{}, false
+	}
+	at, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return LogLine{}, false
+	}
+	ll := LogLine{At: at, Text: rest}
+	if strings.HasPrefix(rest, "[") {
+		if proc, text, ok := strings.Cut(rest[1:], "] "); ok && !strings.Contains(proc, " ") {
+			ll.Process, ll.Text = proc, text
+		} else if proc, ok := strings.CutSuffix(rest[1:], "]"); ok && !strings.Contains(proc, " ") {
+			ll.Process, ll.Text = proc, ""
+		}
+	}
+	return ll, true
+}
+
+// ReadTaskLog returns the lines of a task's log (including the rotated
+// <task>.log.1) at or after since, keeping only the last tail lines when tail
+// > 0, and the size of the current log, from which FollowTaskLog continues.
+// Lines that do not parse continue the previous line's process and time.
+func ReadTaskLog(configPath, task string, since time.Time, tail int) ([]LogLine, int64, error) {
+	path := TaskLogPath(configPath, task)
+	var lines []LogLine
+	var size int64
+	for _, p := range []string{path + ".1", path} {
+		b, err := os.ReadFile(p)
+		if errors.Is(err, os.ErrNotExist) && p != path {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		size = int64(len(b))
+		sc := bufio.NewScanner(bytes.NewReader(b))
+		sc.Buffer(make([]byte, 64<<10), 1<<20)
+		for sc.Scan() {
+			lines = appendLogLine(lines, sc.Text())
+		}
+	}
+	if !since.IsZero() {
+		i := sort.Search(len(lines), func(i int) bool { return !lines[i].At.Before(since) })
+		lines = lines[i:]
+	}
+	if tail > 0 && len(lines) > tail {
+		lines = lines[len(lines)-tail:]
+	}
+	return lines, size, nil
+}
+
+func appendLogLine(lines []LogLine, s string) []LogLine {
+	if ll, ok := ParseLogLine(s); ok {
+		return append(lines, ll)
+	}
+	ll := LogLine{Text: s}
+	if n := len(lines); n > 0 {
+		ll.At, ll.Process = lines[n-1].At, lines[n-1].Process
+	}
+	return append(lines, ll)
+}
+
+// FollowTaskLog polls a task's log from offset and calls fn for every new
+// line until ctx is done. A log that shrank (rotated by a new `rig dev`) is
+// followed from its start.
+func FollowTaskLog(ctx context.Context, configPath, task string, offset int64, interval time.Duration, fn func(LogLine)) error {
+	path := TaskLogPath(configPath, task)
+	var partial []byte
+	var last []LogLine
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if info, err := os.Stat(path); err == nil {
+			if info.Size() < offset {
+				offset, partial = 0, nil
+			}
+			if info.Size() > offset {
+				f, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				b, err := io.ReadAll(io.NewSectionReader(f, offset, info.Size()-offset))
+				_ = f.Close()
+				if err != nil {
+					return err
+				}
+				offset += int64(len(b))
+				partial = append(partial, b...)
+				for {
+					i := bytes.IndexByte(partial, '\n')
+					if i < 0 {
+						break
+					}
+					last = appendLogLine(last, string(partial[:i]))[len(last):]
+					fn(last[0])
+					partial = partial[i+1:]
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package rig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTaskLogWriteAndRead(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "rig.toml")
+
+	var none *TaskLog
+	fmt.Fprintln(none.Writer("dev#1"), "dropped") // a nil log discards output
+	none.Printf("rig", "dropped")
+
+	l, err := OpenTaskLog(conf, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := l.Writer("dev#1")
+	fmt.Fprint(out, "listening on :8080\npart")
+	fmt.Fprint(out, "ial\r\nno newline")
+	FlushTaskLogWriter(out)
+	l.Printf("rig", "restarting (change)")
+	fmt.Fprintln(l.Writer("dev#2:err"), "panic: boom")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, size, err := ReadTaskLog(conf, "dev", time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ll := range lines {
+		got = append(got, ll.Process+"|"+ll.Text)
+	}
+	want := []string{"dev#1|listening on :8080", "dev#1|partial", "dev#1|no newline", "rig|restarting (change)", "dev#2:err|panic: boom"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if info, _ := os.Stat(TaskLogPath(conf, "dev")); info.Size() != size {
+		t.Fatalf("size=%d, want %d", size, info.Size())
+	}
+
+	lines, _, _ = ReadTaskLog(conf, "dev", time.Time{}, 2)
+	if len(lines) != 2 || lines[0].Text != "restarting (change)" {
+		t.Fatalf("tail 2: %+v", lines)
+	}
+	if names := TaskLogNames(conf); len(names) != 1 || names[0] != "dev" {
+		t.Fatalf("names=%v", names)
+	}
+	if _, _, err := ReadTaskLog(conf, "api", time.Time{}, 0); !os.IsNotExist(err) {
+		t.Fatalf("missing log err=%v", err)
+	}
+}
+
+func TestReadTaskLogSinceAndRotation(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "rig.toml")
+	path := TaskLogPath(conf, "dev")
+	writeTestFile(t, path+".1", "2026-01-01T10:00:00Z [dev#1] old\n", 0o644)
+	writeTestFile(t, path, "2026-01-01T11:00:00Z [dev#2] started\n  continued\n2026-01-01T12:00:00Z [rig] stopped\n", 0o644)
+
+	lines, _, err := ReadTaskLog(conf, "dev", time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 4 || lines[0].Text != "old" || lines[2].Process != "dev#2" || lines[2].Text != "  continued" {
+		t.Fatalf("lines=%+v", lines)
+	}
+
+	since := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+	lines, _, _ = ReadTaskLog(conf, "dev", since, 0)
+	if len(lines) != 3 || lines[0].Text != "started" {
+		t.Fatalf("since: %+v", lines)
+	}
+
+	ll, ok := ParseLogLine("2026-01-01T09:00:00+02:00 ci: ok (1.2s)")
+	if !ok || ll.Process != "" || ll.Text != "ci: ok (1.2s)" {
+		t.Fatalf("schedule line: %+v ok=%v", ll, ok)
+	}
+
+	writeTestFile(t, path, strings.Repeat("x", taskLogMaxBytes+1), 0o644)
+	l, err := OpenTaskLog(conf, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = l.Close()
+	if info, err := os.Stat(path + ".1"); err != nil || info.Size() != taskLogMaxBytes+1 {
+		t.Fatalf("log was not rotated: %v", err)
+	}
+}
+
+func TestFollowTaskLog(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "rig.toml")
+	l, err := OpenTaskLog(conf, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.Printf("dev#1", "before")
+	_, offset, err := ReadTaskLog(conf, "dev", time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var got []string
+	done := make(chan error, 1)
+	go func() {
+		done <- FollowTaskLog(ctx, conf, "dev", offset, 10*time.Millisecond, func(ll LogLine) {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, ll.Process+"|"+ll.Text)
+		})
+	}()
+	l.Printf("dev#2", "after restart")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "dev#2|after restart" {
+		t.Fatalf("followed %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+package rig
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"sort"
+	"strings"
+)
+
+// TestPackagesFor maps changed files (slash-separated, relative to the module
+// root) to `go test` patterns: ./dir/... for each directory holding a changed
+// .go file, or "." for the root. Other files are ignored.
+func TestPackagesFor(files []string) []string {
+	seen := map[string]struct{}{}
+	var out []string
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".go") {
+			continue
+		}
+		pkg := "."
+		if dir := path.Dir(f); dir != "." {
+			pkg = "./" + dir + "/..."
+		}
+		if _, ok := seen[pkg]; ok {
+			continue
+		}
+		seen[pkg] = struct{}{}
+		out = append(out, pkg)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// FailedTests returns the names from "--- FAIL: Name" lines in go test output,
+// in order of appearance.
+func FailedTests(out []byte) []string {
+	var names []string
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		rest, ok := strings.CutPrefix(line, "--- FAIL: ")
+		if !ok {
+			continue
+		}
+		if name, _, _ := strings.Cut(rest, " "); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,34 @@
+package rig
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTestPackagesFor(t *testing.T) {
+	got := TestPackagesFor([]string{"internal/x/a.go", "internal/x/a_test.go", "main.go", "README.md", "cmd/app/main.go"})
+	want := []string{".", "./cmd/app/...", "./internal/x/..."}
+	if !slices.Equal(got, want) {
+		t.Fatalf("TestPackagesFor = %v, want %v", got, want)
+	}
+	if got := TestPackagesFor([]string{"go.mod", "web/app.js"}); len(got) != 0 {
+		t.Fatalf("expected no packages for non-Go files, got %v", got)
+	}
+}
+
+func TestFailedTests(t *testing.T) {
+	out := []byte("=== RUN   TestA\n--- FAIL: TestA (0.00s)\n    --- FAIL: TestA/sub (0.00s)\n--- PASS: TestB (0.00s)\nFAIL\n")
+	if got, want := FailedTests(out), []string{"TestA", "TestA/sub"}; !slices.Equal(got, want) {
+		t.Fatalf("FailedTests = %v, want %v", got, want)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	a := map[string]fileStamp{"keep.go": {t0, 1}, "edit.go": {t0, 1}, "gone.go": {t0, 1}}
+	b := map[string]fileStamp{"keep.go": {t0, 1}, "edit.go": {t0, 2}, "new.go": {t0, 1}}
+	if got, want := changedFiles(a, b), []string{"edit.go", "gone.go", "new.go"}; !slices.Equal(got, want) {
+		t.Fatalf("changedFiles = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package rig
+
+import (
+	"path"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// toolAliases holds user and project [tool-aliases]. Entries take precedence
+// over ToolShortNameMap.
+var toolAliases = map[string]ToolIdentity{}
+
+// ApplyToolAliases installs the user aliases followed by c.ToolAliases, so
+// project entries win. It replaces any aliases applied before.
+func ApplyToolAliases(c *cfg.Config) error {
+	user, _, err := cfg.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	UseToolAliases(user.ToolAliases, c.ToolAliases)
+	return nil
+}
+
+// UseToolAliases replaces the alias registry; later layers win.
+func UseToolAliases(layers ...map[string]cfg.ToolAlias) {
+	m := map[string]ToolIdentity{}
+	for _, layer := range layers {
+		for name, a := range layer {
+			if a.OCI != "" {
+				m[strings.TrimSpace(name)] = ToolIdentity{OCI: a.OCI, Bin: firstNonEmptyString(a.Bin, path.Base(a.OCI))}
+				continue
+			}
+			install := a.Install
+			if install == "" {
+				install = a.Module
+			}
+			bin := a.Bin
+			if bin == "" {
+				bin = inferBinFromInstallPath(install)
+			}
+			m[strings.TrimSpace(name)] = ToolIdentity{Module: a.Module, InstallPath: install, Bin: bin}
+		}
+	}
+	toolAliases = m
+}
+
+// ToolAliases returns a copy of the active user and project aliases.
+func ToolAliases() map[string]ToolIdentity {
+	out := make(map[string]ToolIdentity, len(toolAliases))
+	for k, v := range toolAliases {
+		out[k] = v
+	}
+	return out
+}
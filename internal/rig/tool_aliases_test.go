@@ -0,0 +1,89 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestToolAliasesFromProjectAndUserConfig(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_CONFIG_HOME only drives os.UserConfigDir on Linux")
+	}
+	t.Cleanup(func() { UseToolAliases() })
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	writeTestFile(t, filepath.Join(home, "rig", "config.toml"), `
+[tool-aliases]
+sqlc = "github.com/sqlc-dev/sqlc"
+mytool = "example.com/user/mytool"
+`, 0o644)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), `
+[tool-aliases]
+mytool = { module = "example.com/team/mytool", install = "example.com/team/mytool/cmd/mt" }
+mockery = "github.com/vektra/mockery/v3"
+
+[tools]
+mytool = "v1.0.0"
+`, 0o644)
+
+	if _, _, err := LoadConfig(dir); err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	cases := map[string]ToolIdentity{
+		"sqlc":    {Module: "github.com/sqlc-dev/sqlc", InstallPath: "github.com/sqlc-dev/sqlc", Bin: "sqlc"},
+		"mytool":  {Module: "example.com/team/mytool", InstallPath: "example.com/team/mytool/cmd/mt", Bin: "mt"},
+		"mockery": {Module: "github.com/vektra/mockery/v3", InstallPath: "github.com/vektra/mockery/v3", Bin: "mockery"},
+		"reflex":  ToolShortNameMap["reflex"],
+	}
+	for name, want := range cases {
+		if got := ResolveToolIdentity(name); got != want {
+			t.Errorf("ResolveToolIdentity(%q) = %+v, want %+v", name, got, want)
+		}
+	}
+
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), "[tool-aliases]\nbad = { module = \"example.com/a\", install = \"example.com/b\" }\n", 0o644)
+	if _, _, err := LoadConfig(dir); err == nil || !strings.Contains(err.Error(), "not inside module") {
+		t.Fatalf("expected install/module error, got %v", err)
+	}
+}
+
+func TestSearchTools(t *testing.T) {
+	t.Cleanup(func() { UseToolAliases() })
+	UseToolAliases()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "reflex" {
+			t.Errorf("query = %q", r.URL.RawQuery)
+		}
+		w.Write([]byte(`<div class="SearchSnippet"><h2><a href="/github.com/cespare/reflex">reflex
+<span class="SearchSnippet-header-path">(github.com/cespare/reflex)</span></a></h2></div>
+<div class="SearchSnippet"><h2><a href="/github.com/acme/reflex/cmd/reflex">reflex
+<span class="SearchSnippet-header-path">(github.com/acme/reflex/cmd/reflex)</span></a></h2></div>`))
+	}))
+	defer srv.Close()
+
+	got, err := SearchTools("reflex", ToolSearchOptions{URL: srv.URL, Client: srv.Client()})
+	if err != nil {
+		t.Fatalf("SearchTools: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("results = %+v", got)
+	}
+	if got[0].Source != SearchSourceBuiltin || got[0].Name != "reflex" {
+		t.Fatalf("first result should be the built-in short name: %+v", got[0])
+	}
+	if got[1].Source != SearchSourceIndex || got[1].InstallPath != "github.com/acme/reflex/cmd/reflex" || got[1].Bin != "reflex" {
+		t.Fatalf("unexpected index result: %+v", got[1])
+	}
+
+	got, err = SearchTools("lint", ToolSearchOptions{Offline: true})
+	if err != nil || len(got) != 1 || got[0].Name != "golangci-lint" {
+		t.Fatalf("offline search = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,116 @@
+package rig
+
+import (
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Tool search sources.
+const (
+	SearchSourceAlias   = "alias"
+	SearchSourceBuiltin = "builtin"
+	SearchSourceIndex   = "pkg.go.dev"
+)
+
+const defaultToolSearchURL = "https://pkg.go.dev/search"
+
+// ToolSearchResult is one candidate for `rig tools search`.
+type ToolSearchResult struct {
+	Name        string `json:"name"`
+	Module      string `json:"module,omitempty"`
+	InstallPath string `json:"installPath"`
+	Bin         string `json:"bin"`
+	Source      string `json:"source"`
+}
+
+// ToolSearchOptions configures SearchTools.
+type ToolSearchOptions struct {
+	// Offline limits the search to aliases and built-in short names.
+	Offline bool
+	Limit   int
+	URL     string
+	Client  HTTPClient
+}
+
+// SearchTools suggests install paths for query: exact and partial matches
+// from [tool-aliases] and ToolShortNameMap first, then package search results
+// from pkg.go.dev. A failed remote search returns the local results with the
+// error.
+func SearchTools(query string, opts ToolSearchOptions) ([]ToolSearchResult, error) {
+	query = strings.TrimSpace(query)
+	out := searchLocalTools(query)
+	if opts.Offline {
+		return out, nil
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+	if opts.URL == "" {
+		opts.URL = defaultToolSearchURL
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	body, err := fetchBytes(opts.Client, opts.URL+"?m=package&q="+url.QueryEscape(query))
+	if err != nil {
+		return out, err
+	}
+	seen := map[string]struct{}{}
+	for _, r := range out {
+		seen[r.InstallPath] = struct{}{}
+	}
+	n := 0
+	for _, p := range parsePkgSearchResults(body) {
+		if n >= opts.Limit {
+			break
+		}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		out = append(out, ToolSearchResult{Name: query, InstallPath: p, Bin: inferBinFromInstallPath(p), Source: SearchSourceIndex})
+		n++
+	}
+	return out, nil
+}
+
+func searchLocalTools(query string) []ToolSearchResult {
+	q := strings.ToLower(query)
+	var exact, partial []ToolSearchResult
+	add := func(name string, id ToolIdentity, source string) {
+		r := ToolSearchResult{Name: name, Module: id.Module, InstallPath: id.InstallPath, Bin: id.Bin, Source: source}
+		switch l := strings.ToLower(name); {
+		case l == q:
+			exact = append(exact, r)
+		case q != "" && (strings.Contains(l, q) || strings.Contains(strings.ToLower(id.InstallPath), q)):
+			partial = append(partial, r)
+		}
+	}
+	for name, id := range toolAliases {
+		add(name, id, SearchSourceAlias)
+	}
+	for name, id := range ToolShortNameMap {
+		if _, ok := toolAliases[name]; ok {
+			continue
+		}
+		add(name, id, SearchSourceBuiltin)
+	}
+	sort.Slice(partial, func(i, j int) bool { return partial[i].Name < partial[j].Name })
+	return append(exact, partial...)
+}
+
+// pkg.go.dev renders each result as
+// <span class="SearchSnippet-header-path">(github.com/x/y)</span>.
+var pkgSearchPathRE = regexp.MustCompile(`SearchSnippet-header-path">\(([^)<\s]+)\)<`)
+
+func parsePkgSearchResults(body []byte) []string {
+	var out []string
+	for _, m := range pkgSearchPathRE.FindAllSubmatch(body, -1) {
+		out = append(out, html.UnescapeString(string(m[1])))
+	}
+	return out
+}
@@ -14,6 +14,9 @@ type ToolIdentity struct {
 	Module      string
 	InstallPath string
 	Bin         string
+	// OCI is the registry repository of a prebuilt binary ([tool-aliases]
+	// oci); Module and InstallPath are empty then.
+	OCI string
 }
 
 // ToolShortNameMap maps commonly used tool short names to their identities.
@@ -33,10 +36,14 @@ var ToolShortNameMap = map[string]ToolIdentity{
 
 // ResolveToolIdentity resolves a tool identifier (short name or module path) into a ToolIdentity.
 //
-// If the tool was specified by a known short name, the identity is explicit.
-// Otherwise, Module and InstallPath are assumed to be the provided value.
+// If the tool was specified by a known short name ([tool-aliases] first, then
+// ToolShortNameMap), the identity is explicit. Otherwise, Module and
+// InstallPath are assumed to be the provided value.
 func ResolveToolIdentity(name string) ToolIdentity {
 	name = strings.TrimSpace(name)
+	if id, ok := toolAliases[name]; ok {
+		return id
+	}
 	if id, ok := ToolShortNameMap[name]; ok {
 		return id
 	}
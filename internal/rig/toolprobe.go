@@ -0,0 +1,101 @@
+package rig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// Version probe outcomes reported by `rig tools doctor`. The probe is
+// advisory: a tool's status always comes from its sha256.
+const (
+	ProbeOK      = "ok"      // the tool reported the locked version
+	ProbeDiffers = "differs" // it reported another version
+	ProbeFailed  = "failed"  // it could not be run, or printed no version
+	ProbeSkipped = "skipped" // [tool-probes] hash_only, or the status is not ok
+)
+
+// toolProbeTimeout bounds a version probe, in case the tool does not know
+// the flag and starts doing real work instead.
+const toolProbeTimeout = 5 * time.Second
+
+// ToolProbeResult is the outcome of asking a tool for its version.
+type ToolProbeResult struct {
+	Status  string `json:"status"`
+	Command string `json:"command,omitempty"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// validateToolProbes checks [tool-probes] entries when rig.toml is loaded.
+func validateToolProbes(probes map[string]cfg.ToolProbe) error {
+	for name, p := range probes {
+		if p.HashOnly && (len(p.VersionArgs) > 0 || p.VersionRegex != "") {
+			return fmt.Errorf("tool-probes.%s: hash_only cannot be combined with version_args or version_regex", name)
+		}
+		if p.VersionRegex != "" {
+			if _, err := regexp.Compile(p.VersionRegex); err != nil {
+				return fmt.Errorf("tool-probes.%s: version_regex: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ProbeToolVersion runs binPath with the probe's version arguments and
+// compares the version it prints with want (the rig.lock version).
+func ProbeToolVersion(binPath string, probe cfg.ToolProbe, want string) ToolProbeResult {
+	if probe.HashOnly {
+		return ToolProbeResult{Status: ProbeSkipped}
+	}
+	args := probe.VersionArgs
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+	re := versionInOutput
+	if probe.VersionRegex != "" {
+		var err error
+		if re, err = regexp.Compile(probe.VersionRegex); err != nil {
+			return ToolProbeResult{Status: ProbeFailed, Error: err.Error()}
+		}
+	}
+	res := ToolProbeResult{Command: strings.Join(append([]string{binPath}, args...), " ")}
+	ctx, cancel := context.WithTimeout(context.Background(), toolProbeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, binPath, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	runErr := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		runErr = fmt.Errorf("no answer within %s", toolProbeTimeout)
+	}
+	m := re.FindStringSubmatch(out.String())
+	switch {
+	case len(m) > 1:
+		res.Version = strings.TrimSpace(m[1])
+	case len(m) == 1:
+		res.Version = strings.TrimSpace(m[0])
+	}
+	if res.Version == "" {
+		res.Status = ProbeFailed
+		if runErr != nil {
+			res.Error = runErr.Error()
+		} else {
+			res.Error = "no version in output; set [tool-probes] version_args and version_regex, or hash_only"
+		}
+		return res
+	}
+	res.Status = ProbeDiffers
+	if w := NormalizeToolVersion(want); w != "" && NormalizeToolVersion(res.Version) == w {
+		res.Status = ProbeOK
+	}
+	return res
+}
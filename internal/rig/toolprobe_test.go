@@ -0,0 +1,52 @@
+package rig
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+func TestProbeToolVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script based test")
+	}
+	dir := t.TempDir()
+	flag := filepath.Join(dir, "flagtool")
+	writeTestFile(t, flag, "#!/bin/sh\n[ \"$1\" = --version ] && echo \"flagtool has version 1.2.3 built with go1.25\" || exit 2\n", 0o755)
+	sub := filepath.Join(dir, "subtool")
+	writeTestFile(t, sub, "#!/bin/sh\n[ \"$1\" = version ] || { echo \"unknown flag $1\" >&2; exit 2; }\necho \"Build: 2024-01-01 Version: v0.9.1\"\n", 0o755)
+
+	cases := []struct {
+		name    string
+		bin     string
+		probe   cfg.ToolProbe
+		want    string
+		status  string
+		version string
+	}{
+		{"default flag", flag, cfg.ToolProbe{}, "v1.2.3", ProbeOK, "1.2.3"},
+		{"other version", flag, cfg.ToolProbe{}, "v1.3.0", ProbeDiffers, "1.2.3"},
+		{"no --version", sub, cfg.ToolProbe{}, "v0.9.1", ProbeFailed, ""},
+		{"version_args and regex", sub, cfg.ToolProbe{VersionArgs: []string{"version"}, VersionRegex: `Version: (\S+)`}, "v0.9.1", ProbeOK, "v0.9.1"},
+		{"hash_only", sub, cfg.ToolProbe{HashOnly: true}, "v0.9.1", ProbeSkipped, ""},
+	}
+	for _, c := range cases {
+		res := ProbeToolVersion(c.bin, c.probe, c.want)
+		if res.Status != c.status || res.Version != c.version {
+			t.Errorf("%s: got %+v, want status=%s version=%q", c.name, res, c.status, c.version)
+		}
+	}
+}
+
+func TestValidateToolProbes(t *testing.T) {
+	if err := validateToolProbes(map[string]cfg.ToolProbe{"buf": {VersionArgs: []string{"version"}, VersionRegex: `v(\d+\.\d+\.\d+)`}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []cfg.ToolProbe{{VersionRegex: "("}, {HashOnly: true, VersionArgs: []string{"version"}}} {
+		if err := validateToolProbes(map[string]cfg.ToolProbe{"buf": bad}); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
@@ -17,18 +17,27 @@ const (
 	ToolOK       ToolState = "ok"
 	ToolMissing  ToolState = "missing"
 	ToolMismatch ToolState = "mismatch"
+	// ToolStale means the binary matches rig.lock but was built with a Go
+	// version other than the pinned toolchain.
+	ToolStale ToolState = "stale"
+	// ToolExtra is a .rig/bin file no tool in rig.lock owns; only
+	// ToolsDoctor reports it.
+	ToolExtra ToolState = "extra"
 )
 
 // ToolStatusRow is a stable, machine-friendly representation of tool state.
 // It is used by v0.2 `rig check` (and by `rig run` preflight validation).
 //
-// Status values: ok | missing | mismatch
+// Status values: ok | missing | mismatch | stale
+//
+// Want is the version rig.lock pins; Requested is the rig.toml version.
 type ToolStatusRow struct {
-	Name   string `json:"name"`
-	Bin    string `json:"bin"`
-	Want   string `json:"want"`
-	Have   string `json:"have"`
-	Status string `json:"status"`
+	Name      string `json:"name"`
+	Bin       string `json:"bin"`
+	Requested string `json:"requested,omitempty"`
+	Want      string `json:"want"`
+	Have      string `json:"have"`
+	Status    string `json:"status"`
 }
 
 func rigLockPathForConfig(configPath string) string {
@@ -36,7 +45,7 @@ func rigLockPathForConfig(configPath string) string {
 }
 
 func localBinDirForConfig(configPath string) string {
-	return filepath.Join(filepath.Dir(configPath), ".rig", "bin")
+	return BinDir(configPath)
 }
 
 // Tool execution authority (v0.3 invariant):
@@ -58,46 +67,76 @@ func ToolBinPath(configPath string, bin string) string {
 
 func normalizeExeNameForMatch(name string) string {
 	name = strings.TrimSpace(name)
-	if runtime.GOOS == "windows" && strings.HasSuffix(strings.ToLower(name), ".exe") {
-		return strings.TrimSuffix(name, ".exe")
+	if runtime.GOOS == "windows" {
+		switch ext := filepath.Ext(name); strings.ToLower(ext) {
+		case ".exe", ".cmd", ".bat":
+			return strings.TrimSuffix(name, ext)
+		}
 	}
 	return name
 }
 
+// windowsToolShim returns a .cmd or .bat shim next to a missing .exe in
+// .rig/bin, for tools wrapped by a script instead of installed by go install.
+func windowsToolShim(binPath string) (string, bool) {
+	if runtime.GOOS != "windows" || ensureExecutable(binPath) == nil {
+		return "", false
+	}
+	base := strings.TrimSuffix(binPath, filepath.Ext(binPath))
+	for _, ext := range []string{".cmd", ".bat"} {
+		if ensureExecutable(base+ext) == nil {
+			return base + ext, true
+		}
+	}
+	return "", false
+}
+
 // ResolveManagedToolExecutable returns an absolute path in .rig/bin if argv0 refers to a
 // tool declared in rig.lock. If argv0 is not a managed tool binary, ok=false.
 //
 // This is the only supported way for rig to run managed tools in v0.3.
 func ResolveManagedToolExecutable(configPath string, lock Lockfile, argv0 string) (path string, ok bool, err error) {
-	argv0 = strings.TrimSpace(argv0)
-	if argv0 == "" {
-		return "", false, nil
+	lt, ok, err := lockedToolFor(lock, argv0)
+	if !ok || err != nil {
+		return "", false, err
 	}
-	// Only bare command names are eligible. Paths are treated as explicit user input.
-	if filepath.IsAbs(argv0) || strings.ContainsRune(argv0, os.PathSeparator) {
-		return "", false, nil
+	bin := lockedToolBin(lt)
+	binPath := ToolBinPath(configPath, bin)
+	if shim, ok := windowsToolShim(binPath); ok {
+		binPath = shim
+	}
+	if err := ensureExecutable(binPath); err != nil {
+		return "", true, WithCode(CodeToolNotInstalled, fmt.Errorf("%s not installed in .rig/bin (run 'rig sync'): %w", bin, err))
 	}
+	return binPath, true, nil
+}
 
+// lockedToolFor returns the rig.lock entry whose binary argv0 names.
+func lockedToolFor(lock Lockfile, argv0 string) (LockedTool, bool, error) {
+	argv0 = strings.TrimSpace(argv0)
+	// Only bare command names are eligible. Paths are treated as explicit user input.
+	if argv0 == "" || isPathLike(argv0) {
+		return LockedTool{}, false, nil
+	}
 	want := normalizeExeNameForMatch(argv0)
 	for _, lt := range lock.Tools {
-		toolName, _, perr := ParseRequested(lt.Requested)
-		if perr != nil {
-			return "", false, perr
-		}
-		bin := strings.TrimSpace(lt.Bin)
-		if bin == "" {
-			bin = ResolveToolIdentity(toolName).Bin
+		if _, _, err := ParseRequested(lt.Requested); err != nil {
+			return LockedTool{}, false, err
 		}
-		if normalizeExeNameForMatch(bin) != want {
-			continue
-		}
-		binPath := ToolBinPath(configPath, bin)
-		if err := ensureExecutable(binPath); err != nil {
-			return "", true, fmt.Errorf("%s not installed in .rig/bin (run 'rig sync'): %w", bin, err)
+		if normalizeExeNameForMatch(lockedToolBin(lt)) == want {
+			return lt, true, nil
 		}
-		return binPath, true, nil
 	}
-	return "", false, nil
+	return LockedTool{}, false, nil
+}
+
+// lockedToolBin is the binary name a rig.lock entry installs.
+func lockedToolBin(lt LockedTool) string {
+	if bin := strings.TrimSpace(lt.Bin); bin != "" {
+		return bin
+	}
+	toolName, _, _ := ParseRequested(lt.Requested)
+	return ResolveToolIdentity(toolName).Bin
 }
 
 func execCapture(name string, args []string, dir string, env []string) (string, error) {
@@ -177,6 +216,10 @@ func lockMatchesGoToolchain(lock Lockfile, tools map[string]string) error {
 // LockMatchesTools verifies that rig.lock is consistent with the [tools] map.
 // It is intentionally strict.
 func LockMatchesTools(lock Lockfile, tools map[string]string) error {
+	return WithCode(CodeLockOutOfDate, lockMatchesTools(lock, tools))
+}
+
+func lockMatchesTools(lock Lockfile, tools map[string]string) error {
 	if err := ValidateLockfile(lock); err != nil {
 		return err
 	}
@@ -214,6 +257,12 @@ func LockMatchesTools(lock Lockfile, tools map[string]string) error {
 			return fmt.Errorf("tool %q version mismatch: rig.toml wants %q, rig.lock has %q", name, wantVer, lockReqVer)
 		}
 		id := ResolveToolIdentity(name)
+		if id.OCI != "" || IsOCITool(lt) {
+			if err := lockMatchesOCITool(name, id, lt); err != nil {
+				return err
+			}
+			continue
+		}
 		if strings.TrimSpace(lt.Module) != strings.TrimSpace(id.Module) {
 			return fmt.Errorf("tool %q module mismatch: expected %q, rig.lock has %q", name, id.Module, lt.Module)
 		}
@@ -234,6 +283,28 @@ func LockMatchesTools(lock Lockfile, tools map[string]string) error {
 	return nil
 }
 
+// lockMatchesOCITool checks a rig.lock entry against an oci tool alias.
+func lockMatchesOCITool(name string, id ToolIdentity, lt LockedTool) error {
+	if id.OCI == "" {
+		return fmt.Errorf("tool %q: rig.lock pulls it from %s but rig.toml has no oci alias for it", name, lt.URL)
+	}
+	if !IsOCITool(lt) {
+		return fmt.Errorf("tool %q kind mismatch: expected %q, rig.lock has %q", name, OCIToolKind, lt.Kind)
+	}
+	want := ociURLPrefix + strings.TrimPrefix(id.OCI, ociURLPrefix)
+	if strings.TrimSpace(lt.URL) != want {
+		return fmt.Errorf("tool %q url mismatch: expected %q, rig.lock has %q", name, want, lt.URL)
+	}
+	repo, digest := SplitResolved(lt.Resolved)
+	if ociURLPrefix+repo != want || !strings.HasPrefix(digest, "sha256:") {
+		return fmt.Errorf("tool %q resolved mismatch: expected %s@sha256:..., rig.lock has %q", name, strings.TrimPrefix(want, ociURLPrefix), lt.Resolved)
+	}
+	if strings.TrimSpace(lt.Bin) != "" && strings.TrimSpace(lt.Bin) != id.Bin {
+		return fmt.Errorf("tool %q bin mismatch: expected %q, rig.lock has %q", name, id.Bin, lt.Bin)
+	}
+	return nil
+}
+
 // ReadRigLockForConfig reads rig.lock next to rig.toml.
 func ReadRigLockForConfig(configPath string) (Lockfile, error) {
 	return ReadLockfile(rigLockPathForConfig(configPath))
@@ -262,6 +333,11 @@ func CheckInstalledTools(tools map[string]string, lock Lockfile, configPath stri
 	}
 	sort.Strings(names)
 
+	pinnedGo := ""
+	if lock.Toolchain != nil && lock.Toolchain.Go != nil {
+		pinnedGo = strings.TrimSpace(lock.Toolchain.Go.Detected)
+	}
+
 	rows = make([]ToolStatusRow, 0, len(names))
 	declaredBins := map[string]struct{}{}
 	for _, name := range names {
@@ -290,9 +366,13 @@ func CheckInstalledTools(tools map[string]string, lock Lockfile, configPath stri
 			} else if expected == "" || got != expected {
 				status = ToolMismatch
 				mismatched++
+			} else if builtWith := strings.TrimSpace(lt.Go); builtWith != "" && pinnedGo != "" && builtWith != pinnedGo {
+				status = ToolStale
+				have = "go" + builtWith
+				mismatched++
 			}
 		}
-		rows = append(rows, ToolStatusRow{Name: name, Bin: bin, Want: want, Have: have, Status: string(status)})
+		rows = append(rows, ToolStatusRow{Name: name, Bin: bin, Requested: strings.TrimSpace(tools[name]), Want: want, Have: have, Status: string(status)})
 	}
 
 	binDir := localBinDirForConfig(configPath)
@@ -2,17 +2,18 @@ package rig
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 )
 
 type ManagedToolInfo struct {
-	Name      string
-	Requested string
-	Resolved  string
-	Path      string
-	Status    ToolState
+	Name      string    `json:"name"`
+	Requested string    `json:"requested"`
+	Resolved  string    `json:"resolved"`
+	Path      string    `json:"path"`
+	Status    ToolState `json:"status"`
 }
 
 type ToolWhyInfo struct {
@@ -24,17 +25,23 @@ type ToolWhyInfo struct {
 }
 
 type ToolDoctorReport struct {
-	Name         string
-	Path         string
-	Exists       bool
-	Executable   bool
-	SHAExpected  string
-	SHAActual    string
-	SHAMatch     bool
-	ResolvedPath string
-	ResolvedOK   bool
-	Status       ToolState
-	Error        string
+	Name         string    `json:"name"`
+	Path         string    `json:"path"`
+	Exists       bool      `json:"exists"`
+	Executable   bool      `json:"executable"`
+	SHAExpected  string    `json:"sha_expected"`
+	SHAActual    string    `json:"sha_actual"`
+	SHAMatch     bool      `json:"sha_match"`
+	ResolvedPath string    `json:"resolved_path"`
+	ResolvedOK   bool      `json:"resolved_ok"`
+	Status       ToolState `json:"status"`
+	Error        string    `json:"error,omitempty"`
+	// Probe is the advisory version probe, run only on a binary whose sha256
+	// matches rig.lock.
+	Probe ToolProbeResult `json:"version_probe"`
+	// Remedy is the exact command that repairs the problem, empty when the
+	// tool is ok.
+	Remedy string `json:"remedy,omitempty"`
 }
 
 func ToolsLS(startDir string) ([]ManagedToolInfo, error) {
@@ -102,14 +109,14 @@ func ToolPath(startDir, name string) (string, error) {
 	}
 	binPath := ToolBinPath(confPath, bin)
 	if err := ensureExecutable(binPath); err != nil {
-		return "", fmt.Errorf("tool %q not found in .rig/bin\nhint: run `rig sync`", name)
+		return "", WithCode(CodeToolNotInstalled, fmt.Errorf("tool %q not found in .rig/bin\nhint: run `rig sync`", name))
 	}
 	actual, err := ComputeFileSHA256(binPath)
 	if err != nil {
 		return "", err
 	}
 	if strings.TrimSpace(actual) != strings.TrimSpace(lt.SHA256) {
-		return "", fmt.Errorf("tool %q checksum mismatch\nhint: run `rig sync`", name)
+		return "", WithCode(CodeToolSHAMismatch, fmt.Errorf("tool %q checksum mismatch\nhint: run `rig sync`", name))
 	}
 	return binPath, nil
 }
@@ -162,7 +169,7 @@ func ToolsDoctor(startDir, name string) ([]ToolDoctorReport, error) {
 	sort.Strings(names)
 	if strings.TrimSpace(name) != "" {
 		if _, ok := conf.Tools[name]; !ok {
-			return nil, fmt.Errorf("tool %q not declared in rig.toml", name)
+			return nil, WithCode(CodeToolNotManaged, fmt.Errorf("tool %q not declared in rig.toml%s", name, DidYouMean(name, names)))
 		}
 		names = []string{name}
 	}
@@ -187,34 +194,69 @@ func ToolsDoctor(startDir, name string) ([]ToolDoctorReport, error) {
 			Status:       ToolOK,
 		}
 
-		if err := ensureExecutable(p); err != nil {
-			r.Exists = false
-			r.Executable = false
-			r.SHAMatch = false
+		st, err := os.Stat(p)
+		if err != nil || st.IsDir() {
 			r.Status = ToolMissing
-			r.Error = err.Error()
+			r.Error = firstNonEmptyString(errString(err), p+" is a directory")
+			r.Probe.Status = ProbeSkipped
+			r.Remedy = "rig sync"
 			reports = append(reports, r)
 			continue
 		}
 		r.Exists = true
-		r.Executable = true
+		r.Executable = ensureExecutable(p) == nil
 
 		sum, err := ComputeFileSHA256(p)
 		if err != nil {
 			r.Status = ToolMismatch
 			r.Error = err.Error()
+			r.Probe.Status = ProbeSkipped
+			r.Remedy = "rig sync"
 			reports = append(reports, r)
 			continue
 		}
 		r.SHAActual = sum
 		r.SHAMatch = strings.TrimSpace(sum) == strings.TrimSpace(lt.SHA256)
-		if !r.SHAMatch {
+		switch {
+		case !r.SHAMatch:
 			r.Status = ToolMismatch
 			r.Error = "sha256 mismatch"
+			r.Probe.Status = ProbeSkipped
+			r.Remedy = "rig sync"
+		case !r.Executable:
+			// The right binary, only missing its execute bit.
+			r.Status = ToolMissing
+			r.Error = p + " is not executable"
+			r.Probe.Status = ProbeSkipped
+			r.Remedy = "chmod +x " + quoteIfNeeded(p)
+		default:
+			_, want := SplitResolved(lt.Resolved)
+			r.Probe = ProbeToolVersion(p, conf.ToolProbes[toolName], want)
 		}
 		reports = append(reports, r)
 	}
 
+	if strings.TrimSpace(name) == "" {
+		extras, err := BinExtras(confPath, lock)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range extras {
+			p := filepath.Join(localBinDirForConfig(confPath), e)
+			reports = append(reports, ToolDoctorReport{
+				Name:         e,
+				Path:         p,
+				Exists:       true,
+				Executable:   ensureExecutable(p) == nil,
+				ResolvedPath: filepath.Clean(p),
+				Status:       ToolExtra,
+				Error:        "not in rig.lock",
+				Probe:        ToolProbeResult{Status: ProbeSkipped},
+				Remedy:       "rig tools prune --yes",
+			})
+		}
+	}
+
 	return reports, nil
 }
 
@@ -228,7 +270,22 @@ func findLockedToolByName(lock Lockfile, toolName string) (LockedTool, error) {
 			return lt, nil
 		}
 	}
-	return LockedTool{}, fmt.Errorf("tool %q not found in rig.lock", toolName)
+	return LockedTool{}, WithCode(CodeToolNotManaged, fmt.Errorf("tool %q not found in rig.lock%s", toolName, DidYouMean(toolName, ToolNameCandidates(lock))))
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// quoteIfNeeded quotes s for a shell when it has spaces or quotes.
+func quoteIfNeeded(s string) string {
+	if strings.ContainsAny(s, " \t'\"") {
+		return shellQuote(s)
+	}
+	return s
 }
 
 func firstNonEmptyString(a, b string) string {
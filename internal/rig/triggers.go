@@ -0,0 +1,191 @@
+package rig
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// triggerWatch tracks the files named by task triggers during one rig run.
+// scan reports the files written, created, or removed since the last scan.
+type triggerWatch struct {
+	base  string
+	tasks []string            // tasks with triggers, sorted
+	globs map[string][]string // task -> absolute trigger patterns
+
+	mu     sync.Mutex
+	stamps map[string]fileStamp
+}
+
+// newTriggerWatch snapshots the trigger files of tasks; nil when no task
+// declares triggers.
+func newTriggerWatch(confPath string, tasks cfg.TasksMap) *triggerWatch {
+	w := &triggerWatch{base: filepath.Dir(confPath), globs: map[string][]string{}}
+	for name, t := range tasks {
+		for _, p := range t.Triggers {
+			p = NormalizeTaskPath(p)
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(w.base, p)
+			}
+			w.globs[name] = append(w.globs[name], p)
+		}
+	}
+	if len(w.globs) == 0 {
+		return nil
+	}
+	for name := range w.globs {
+		w.tasks = append(w.tasks, name)
+	}
+	sort.Strings(w.tasks)
+	w.stamps = w.stat()
+	return w
+}
+
+func (w *triggerWatch) stat() map[string]fileStamp {
+	out := map[string]fileStamp{}
+	for _, patterns := range w.globs {
+		for _, p := range patterns {
+			matches, _ := filepath.Glob(p)
+			for _, m := range matches {
+				if st, err := os.Stat(m); err == nil && !st.IsDir() {
+					out[m] = fileStamp{mod: st.ModTime(), size: st.Size()}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// scan returns the trigger files that changed since the previous scan.
+func (w *triggerWatch) scan() map[string]bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	cur := w.stat()
+	changed := map[string]bool{}
+	for p, st := range cur {
+		if prev, ok := w.stamps[p]; !ok || prev != st {
+			changed[p] = true
+		}
+	}
+	for p := range w.stamps {
+		if _, ok := cur[p]; !ok {
+			changed[p] = true
+		}
+	}
+	w.stamps = cur
+	return changed
+}
+
+// match returns the first changed file (relative to rig.toml) that one of
+// task's trigger patterns matches.
+func (w *triggerWatch) match(task string, changed map[string]bool) (string, bool) {
+	files := make([]string, 0, len(changed))
+	for p := range changed {
+		files = append(files, p)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		for _, pat := range w.globs[task] {
+			if ok, _ := filepath.Match(pat, f); ok {
+				return dirLabel(w.base, f), true
+			}
+		}
+	}
+	return "", false
+}
+
+// noteTriggers queues, after task `after` succeeded, every task whose
+// triggers match a file that changed and that is not already part of the run
+// (planned, started, or queued).
+func (r *taskRunner) noteTriggers(after string) {
+	if r.triggers == nil {
+		return
+	}
+	changed := r.triggers.scan()
+	if len(changed) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range r.triggers.tasks {
+		if _, started := r.runs[name]; started || slices.Contains(r.planned, name) || slices.Contains(r.triggered, name) {
+			continue
+		}
+		if file, ok := r.triggers.match(name, changed); ok {
+			statusf(os.Stderr, "⚡ %s triggered: %s changed (after %s)", name, file, after)
+			r.triggered = append(r.triggered, name)
+		}
+	}
+}
+
+// runTriggered runs the queued triggered tasks once the plan has finished,
+// so a triggered task never waits on a task that is still running. Tasks
+// they trigger in turn join the queue.
+func (r *taskRunner) runTriggered(mode string) error {
+	for i := 0; ; i++ {
+		r.mu.Lock()
+		if i >= len(r.triggered) {
+			r.mu.Unlock()
+			return nil
+		}
+		name := r.triggered[i]
+		r.mu.Unlock()
+		if err := r.prepareTriggered(name); err != nil {
+			return err
+		}
+		if err := r.run(name, mode); err != nil && !errors.Is(err, errTaskFailed) {
+			return err
+		}
+	}
+}
+
+// prepareTriggered checks requirements and resolves inputs for a task that
+// joined the run through a trigger, as runTaskOrder does for the plan.
+func (r *taskRunner) prepareTriggered(name string) error {
+	order, err := resolveTaskOrder(r.tasks, name)
+	if err != nil {
+		return err
+	}
+	if err := ensureRequirements(r.requires, requirementsForTasks(r.tasks, order)); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	known := maps.Clone(r.inputs)
+	r.mu.Unlock()
+	declared := map[string]bool{}
+	for _, t := range order {
+		for _, in := range r.tasks[t].Inputs {
+			declared[in.Name] = true
+		}
+	}
+	supplied := map[string]string{}
+	for k, v := range known {
+		if declared[k] {
+			supplied[k] = v
+		}
+	}
+	for k, v := range r.opts.Inputs {
+		if declared[k] {
+			supplied[k] = v
+		}
+	}
+	values, err := resolveTaskInputs(r.tasks, order, RunOptions{Inputs: supplied, Prompt: r.opts.Prompt})
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	merged := maps.Clone(r.inputs)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	maps.Copy(merged, values)
+	r.inputs = merged
+	r.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,145 @@
+package rig
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// DefaultUpdateCheckInterval is the minimum time between release checks.
+const DefaultUpdateCheckInterval = 24 * time.Hour
+
+// UpdateCheckOptions configures the opt-in new-release notice.
+type UpdateCheckOptions struct {
+	CurrentVersion string
+	Interval       time.Duration
+	// StatePath overrides the state file (default: <user cache>/rig/update-check.json).
+	StatePath string
+	LatestURL string
+	Client    HTTPClient
+	Now       func() time.Time
+}
+
+// UpdateCheckState is the last release check, cached between runs.
+type UpdateCheckState struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+}
+
+// UpdateCheckStatePath returns the file that caches the last release check.
+func UpdateCheckStatePath() (string, error) {
+	dir, err := cfg.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "update-check.json"), nil
+}
+
+// AvailableUpdate returns the cached latest release when it is newer than
+// opts.CurrentVersion. It never touches the network.
+func AvailableUpdate(opts UpdateCheckOptions) (string, bool) {
+	cur, err := parseSemver(opts.CurrentVersion)
+	if err != nil {
+		return "", false
+	}
+	st, err := readUpdateCheckState(opts)
+	if err != nil {
+		return "", false
+	}
+	latest, err := parseSemver(st.Latest)
+	if err != nil || compareSemver(latest, cur) <= 0 {
+		return "", false
+	}
+	return st.Latest, true
+}
+
+// UpdateCheckDue reports whether the cached check is older than opts.Interval.
+func UpdateCheckDue(opts UpdateCheckOptions) bool {
+	st, err := readUpdateCheckState(opts)
+	if err != nil {
+		return true
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultUpdateCheckInterval
+	}
+	return updateCheckNow(opts).Sub(st.CheckedAt) >= interval
+}
+
+// RefreshUpdateCheck fetches the latest release and records it in the state
+// file. The attempt time is recorded even when the fetch fails so an offline
+// machine is not retried on every command.
+func RefreshUpdateCheck(opts UpdateCheckOptions) (UpdateCheckState, error) {
+	path, err := updateCheckStatePath(opts)
+	if err != nil {
+		return UpdateCheckState{}, err
+	}
+	prev, _ := readUpdateCheckState(opts)
+	st := UpdateCheckState{CheckedAt: updateCheckNow(opts), Latest: prev.Latest}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	url := strings.TrimSpace(opts.LatestURL)
+	if url == "" {
+		url = defaultLatestReleaseURL
+	}
+	rel, ferr := fetchLatestRelease(client, url)
+	if ferr == nil {
+		st.Latest = strings.TrimSpace(rel.TagName)
+	}
+
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return st, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return st, err
+	}
+	// Write then rename so a process exiting mid-write leaves no torn file.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return st, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return st, err
+	}
+	return st, ferr
+}
+
+func readUpdateCheckState(opts UpdateCheckOptions) (UpdateCheckState, error) {
+	path, err := updateCheckStatePath(opts)
+	if err != nil {
+		return UpdateCheckState{}, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return UpdateCheckState{}, err
+	}
+	var st UpdateCheckState
+	if err := json.Unmarshal(b, &st); err != nil {
+		return UpdateCheckState{}, errors.New("corrupt update check state")
+	}
+	return st, nil
+}
+
+func updateCheckStatePath(opts UpdateCheckOptions) (string, error) {
+	if p := strings.TrimSpace(opts.StatePath); p != "" {
+		return p, nil
+	}
+	return UpdateCheckStatePath()
+}
+
+func updateCheckNow(opts UpdateCheckOptions) time.Time {
+	if opts.Now != nil {
+		return opts.Now()
+	}
+	return time.Now()
+}
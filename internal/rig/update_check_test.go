@@ -0,0 +1,81 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUpdateCheckNotice(t *testing.T) {
+	hits := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = w.Write([]byte(`{"tag_name":"v0.6.0"}`))
+	}))
+	defer ts.Close()
+
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	opts := UpdateCheckOptions{
+		CurrentVersion: "v0.5.1",
+		Interval:       time.Hour,
+		StatePath:      filepath.Join(t.TempDir(), "update-check.json"),
+		LatestURL:      ts.URL,
+		Client:         ts.Client(),
+		Now:            func() time.Time { return now },
+	}
+
+	if _, ok := AvailableUpdate(opts); ok {
+		t.Fatalf("expected no notice before the first check")
+	}
+	if !UpdateCheckDue(opts) {
+		t.Fatalf("expected a check to be due without state")
+	}
+	if _, err := RefreshUpdateCheck(opts); err != nil {
+		t.Fatalf("RefreshUpdateCheck: %v", err)
+	}
+	if hits != 1 {
+		t.Fatalf("expected 1 request, got %d", hits)
+	}
+	latest, ok := AvailableUpdate(opts)
+	if !ok || latest != "v0.6.0" {
+		t.Fatalf("AvailableUpdate = %q, %v; want v0.6.0", latest, ok)
+	}
+	if UpdateCheckDue(opts) {
+		t.Fatalf("expected no check due right after refreshing")
+	}
+	now = now.Add(2 * time.Hour)
+	if !UpdateCheckDue(opts) {
+		t.Fatalf("expected a check due after the interval")
+	}
+
+	opts.CurrentVersion = "v0.6.0"
+	if _, ok := AvailableUpdate(opts); ok {
+		t.Fatalf("expected no notice when up to date")
+	}
+	opts.CurrentVersion = "dev"
+	if _, ok := AvailableUpdate(opts); ok {
+		t.Fatalf("expected no notice for dev builds")
+	}
+}
+
+func TestUpdateCheckRecordsFailedAttempt(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	opts := UpdateCheckOptions{
+		CurrentVersion: "v0.5.1",
+		StatePath:      filepath.Join(t.TempDir(), "update-check.json"),
+		LatestURL:      ts.URL,
+		Client:         ts.Client(),
+	}
+	if _, err := RefreshUpdateCheck(opts); err == nil {
+		t.Fatalf("expected fetch error")
+	}
+	if UpdateCheckDue(opts) {
+		t.Fatalf("expected a failed attempt to count as a check")
+	}
+}
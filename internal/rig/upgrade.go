@@ -1,21 +1,19 @@
 package rig
 
 import (
-	"archive/tar"
-	"archive/zip"
-	"bytes"
-	"compress/gzip"
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"runtime/debug"
 	"strings"
+	"time"
 )
 
 const defaultLatestReleaseURL = "https://api.github.com/repos/divijg19/rig/releases/latest"
@@ -31,6 +29,9 @@ type UpgradeOptions struct {
 	GOARCH         string
 	LatestURL      string
 	Client         HTTPClient
+	// Verify reports the version of the freshly installed binary at exe.
+	// Defaults to running `exe --version`.
+	Verify func(exe string) (string, error)
 }
 
 type UpgradeResult struct {
@@ -40,6 +41,8 @@ type UpgradeResult struct {
 	AssetName     string
 	ChecksumName  string
 	ExecutableOut string
+	// Verified is the version the new binary reported after installation.
+	Verified string
 }
 
 type githubLatestRelease struct {
@@ -80,43 +83,35 @@ func UpgradeSelf(opts UpgradeOptions) (UpgradeResult, error) {
 		return res, nil
 	}
 
-	assetName, checksumName, err := expectedAssetNames(opts.GOOS, opts.GOARCH)
+	assetName, checksumName, err := selectAsset(rel, opts.GOOS, opts.GOARCH)
 	if err != nil {
 		return UpgradeResult{}, err
 	}
 	res.AssetName = assetName
 	res.ChecksumName = checksumName
 
-	assetURL, ok := findAssetURL(rel, assetName)
-	if !ok {
-		return UpgradeResult{}, fmt.Errorf("release asset not found: %s", assetName)
-	}
+	assetURL, _ := findAssetURL(rel, assetName)
 	checksumURL, ok := findAssetURL(rel, checksumName)
 	if !ok {
 		return UpgradeResult{}, fmt.Errorf("release checksum not found: %s", checksumName)
 	}
 
-	assetData, err := fetchBytes(opts.Client, assetURL)
-	if err != nil {
-		return UpgradeResult{}, err
-	}
-	checksumData, err := fetchBytes(opts.Client, checksumURL)
-	if err != nil {
-		return UpgradeResult{}, err
-	}
-	if err := verifyChecksum(assetName, assetData, checksumData); err != nil {
-		return UpgradeResult{}, err
-	}
-
 	binaryName := "rig"
 	if opts.GOOS == "windows" {
 		binaryName = "rig.exe"
 	}
-	binaryData, err := extractSingleBinary(assetName, assetData, binaryName)
+	binaryData, err := fetchReleaseBinary(opts.Client, assetURL, checksumURL, assetName, binaryName)
 	if err != nil {
 		return UpgradeResult{}, err
 	}
 
+	// Keep the current binary so a new one that fails its health check can be
+	// rolled back.
+	previous, err := os.ReadFile(opts.ExecutablePath)
+	if err != nil {
+		return UpgradeResult{}, fmt.Errorf("read current binary: %w", err)
+	}
+
 	if err := replaceExecutableAtomically(opts.ExecutablePath, binaryData); err != nil {
 		if opts.GOOS == "windows" {
 			return UpgradeResult{}, fmt.Errorf("upgrade failed to replace running binary; close all rig processes and retry: %w", err)
@@ -124,10 +119,48 @@ func UpgradeSelf(opts UpgradeOptions) (UpgradeResult, error) {
 		return UpgradeResult{}, err
 	}
 
+	if opts.Verify == nil {
+		opts.Verify = reportedVersion
+	}
+	got, verr := opts.Verify(opts.ExecutablePath)
+	if verr == nil && !sameVersion(got, res.Latest) {
+		verr = fmt.Errorf("new binary reports version %q, want %q", got, res.Latest)
+	}
+	if verr != nil {
+		if err := replaceExecutableAtomically(opts.ExecutablePath, previous); err != nil {
+			return UpgradeResult{}, WithCode(CodeUpgradeVerify, fmt.Errorf("upgrade verification failed (%v) and restoring the previous binary failed: %w", verr, err))
+		}
+		return UpgradeResult{}, WithCode(CodeUpgradeVerify, fmt.Errorf("upgrade verification failed; restored previous binary: %w", verr))
+	}
+
 	res.ExecutableOut = opts.ExecutablePath
+	res.Verified = got
 	return res, nil
 }
 
+// reportedVersion runs `exe --version` and returns the version from its
+// first line ("rig v0.5.0").
+func reportedVersion(exe string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, exe, "--version").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s --version: %w: %s", exe, err, strings.TrimSpace(string(out)))
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	fields := strings.Fields(first)
+	if len(fields) < 2 || fields[0] != "rig" {
+		return "", fmt.Errorf("%s --version: unexpected output %q", exe, first)
+	}
+	return fields[1], nil
+}
+
+// sameVersion compares versions ignoring a leading "v" (release tags carry
+// one; the version baked into the binary may not).
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(strings.TrimSpace(a), "v") == strings.TrimPrefix(strings.TrimSpace(b), "v")
+}
+
 func fetchLatestRelease(client HTTPClient, url string) (githubLatestRelease, error) {
 	body, err := fetchBytes(client, url)
 	if err != nil {
@@ -144,11 +177,18 @@ func fetchLatestRelease(client HTTPClient, url string) (githubLatestRelease, err
 }
 
 func fetchBytes(client HTTPClient, url string) ([]byte, error) {
+	return httpGet(client, url, "application/vnd.github+json")
+}
+
+// httpGet fetches url, sending accept as the Accept header when set.
+func httpGet(client HTTPClient, url, accept string) ([]byte, error) {
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Accept", "application/vnd.github+json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -164,16 +204,83 @@ func fetchBytes(client HTTPClient, url string) ([]byte, error) {
 	return b, nil
 }
 
-func expectedAssetNames(goos, goarch string) (asset string, checksum string, err error) {
-	switch goos {
-	case "linux", "darwin":
-		asset = fmt.Sprintf("rig_%s_%s.tar.gz", goos, goarch)
-	case "windows":
-		asset = fmt.Sprintf("rig_windows_%s.zip", goarch)
-	default:
-		return "", "", fmt.Errorf("unsupported OS: %s", goos)
-	}
-	return asset, asset + ".sha256", nil
+// assetCandidates lists the release asset names that can run on goos/goarch,
+// most specific first: a _musl build on musl-based Linux, then the plain
+// build; for 32-bit ARM the armv7/armv6 builds the CPU supports (goarm is the
+// GOARM level this binary targets, "" if unknown), then a generic arm build.
+func assetCandidates(goos, goarch string, musl bool, goarm string) []string {
+	arches := []string{goarch}
+	if goarch == "arm" {
+		switch goarm {
+		case "", "7":
+			arches = []string{"armv7", "armv6", "arm"}
+		case "6":
+			arches = []string{"armv6", "arm"}
+		}
+	}
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	var names []string
+	for _, a := range arches {
+		base := fmt.Sprintf("rig_%s_%s", goos, a)
+		if goos == "linux" && musl {
+			names = append(names, base+"_musl"+ext)
+		}
+		names = append(names, base+ext)
+	}
+	return names
+}
+
+// selectAsset picks the first of assetCandidates published in rel, and the
+// name of its checksum file. When none is published the error lists what is.
+func selectAsset(rel githubLatestRelease, goos, goarch string) (asset string, checksum string, err error) {
+	musl := goos == "linux" && runtime.GOOS == "linux" && detectMusl()
+	goarm := ""
+	if goarch == "arm" && runtime.GOARCH == "arm" {
+		goarm = buildSetting("GOARM")
+	}
+	candidates := assetCandidates(goos, goarch, musl, goarm)
+	for _, name := range candidates {
+		if _, ok := findAssetURL(rel, name); ok {
+			return name, name + ".sha256", nil
+		}
+	}
+	var available []string
+	for _, a := range rel.Assets {
+		name := strings.TrimSpace(a.Name)
+		if strings.HasPrefix(name, "rig_") && !strings.HasSuffix(name, ".sha256") {
+			available = append(available, name)
+		}
+	}
+	msg := fmt.Sprintf("release asset not found for %s/%s (tried %s)", goos, goarch, strings.Join(candidates, ", "))
+	if len(available) == 0 {
+		return "", "", errors.New(msg + "; the release has no rig assets")
+	}
+	return "", "", fmt.Errorf("%s; available: %s", msg, strings.Join(available, ", "))
+}
+
+// detectMusl reports whether this Linux system uses musl libc (Alpine and
+// friends), judged by the presence of the musl dynamic loader.
+func detectMusl() bool {
+	matches, _ := filepath.Glob("/lib/ld-musl-*.so.1")
+	return len(matches) > 0
+}
+
+// buildSetting returns a setting (e.g. GOARM) recorded in this binary's
+// build info, or "".
+func buildSetting(key string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, s := range info.Settings {
+		if s.Key == key {
+			return strings.TrimSpace(s.Value)
+		}
+	}
+	return ""
 }
 
 func findAssetURL(rel githubLatestRelease, name string) (string, bool) {
@@ -185,96 +292,40 @@ func findAssetURL(rel githubLatestRelease, name string) (string, bool) {
 	return "", false
 }
 
-func verifyChecksum(assetName string, data []byte, checksumFile []byte) error {
+// fetchReleaseBinary downloads a release asset, verifies it against its
+// checksum file, and extracts binaryName from it.
+func fetchReleaseBinary(client HTTPClient, assetURL, checksumURL, assetName, binaryName string) ([]byte, error) {
+	checksumData, err := fetchBytes(client, checksumURL)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := parseChecksumFile(assetName, checksumData)
+	if err != nil {
+		return nil, err
+	}
+	format := ArchiveFormat(assetName)
+	if format == "" {
+		return nil, fmt.Errorf("unsupported asset format: %s", assetName)
+	}
+	return DownloadVerified(client, assetURL, DownloadOptions{SHA256: sum, Archive: format, BinaryPath: binaryName})
+}
+
+// parseChecksumFile returns the sha256 for assetName from a
+// "<sha256>  <file>" checksum file.
+func parseChecksumFile(assetName string, checksumFile []byte) (string, error) {
 	line := strings.TrimSpace(string(checksumFile))
 	if line == "" {
-		return errors.New("empty checksum file")
+		return "", errors.New("empty checksum file")
 	}
 	fields := strings.Fields(line)
 	if len(fields) < 2 {
-		return fmt.Errorf("invalid checksum format: %q", line)
+		return "", fmt.Errorf("invalid checksum format: %q", line)
 	}
-	expected := strings.TrimSpace(fields[0])
 	file := strings.TrimSpace(fields[len(fields)-1])
 	if file != assetName {
-		return fmt.Errorf("checksum filename mismatch: got %q want %q", file, assetName)
-	}
-	sum := sha256.Sum256(data)
-	actual := hex.EncodeToString(sum[:])
-	if !strings.EqualFold(actual, expected) {
-		return fmt.Errorf("checksum mismatch for %s", assetName)
-	}
-	return nil
-}
-
-func extractSingleBinary(assetName string, data []byte, wantName string) ([]byte, error) {
-	if strings.HasSuffix(assetName, ".tar.gz") {
-		g, err := gzip.NewReader(bytes.NewReader(data))
-		if err != nil {
-			return nil, err
-		}
-		defer g.Close()
-		tr := tar.NewReader(g)
-		count := 0
-		var out []byte
-		for {
-			h, err := tr.Next()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				return nil, err
-			}
-			if h.FileInfo().IsDir() {
-				continue
-			}
-			count++
-			if h.Name != wantName {
-				return nil, fmt.Errorf("archive entry %q invalid (want exactly %q)", h.Name, wantName)
-			}
-			b, rerr := io.ReadAll(tr)
-			if rerr != nil {
-				return nil, rerr
-			}
-			out = b
-		}
-		if count != 1 {
-			return nil, fmt.Errorf("archive must contain exactly one file (%q), got %d", wantName, count)
-		}
-		return out, nil
-	}
-	if strings.HasSuffix(assetName, ".zip") {
-		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
-		if err != nil {
-			return nil, err
-		}
-		files := 0
-		var out []byte
-		for _, f := range zr.File {
-			if f.FileInfo().IsDir() {
-				continue
-			}
-			files++
-			if f.Name != wantName {
-				return nil, fmt.Errorf("archive entry %q invalid (want exactly %q)", f.Name, wantName)
-			}
-			rc, rerr := f.Open()
-			if rerr != nil {
-				return nil, rerr
-			}
-			b, rerr := io.ReadAll(rc)
-			_ = rc.Close()
-			if rerr != nil {
-				return nil, rerr
-			}
-			out = b
-		}
-		if files != 1 {
-			return nil, fmt.Errorf("archive must contain exactly one file (%q), got %d", wantName, files)
-		}
-		return out, nil
+		return "", fmt.Errorf("checksum filename mismatch: got %q want %q", file, assetName)
 	}
-	return nil, fmt.Errorf("unsupported asset format: %s", assetName)
+	return strings.TrimSpace(fields[0]), nil
 }
 
 func replaceExecutableAtomically(path string, data []byte) error {
@@ -0,0 +1,193 @@
+package rig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// VersionConstraint is a conjunction of comparisons, e.g. ">=0.5, <0.6".
+//
+// Supported operators: =, ==, !=, >, >=, <, <=, ~ (same minor), ^ (same major,
+// or same minor for 0.x). "*" or an empty string matches any version. A bare
+// version means "=".
+type VersionConstraint struct {
+	raw   string
+	terms []versionTerm
+}
+
+type versionTerm struct {
+	op string
+	v  semver
+}
+
+type semver struct {
+	major, minor, patch int
+	pre                 string
+}
+
+// ParseVersionConstraint parses a comma-separated constraint expression.
+func ParseVersionConstraint(s string) (VersionConstraint, error) {
+	c := VersionConstraint{raw: strings.TrimSpace(s)}
+	if c.raw == "" || c.raw == "*" {
+		return c, nil
+	}
+	for _, part := range strings.Split(c.raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "*" {
+			continue
+		}
+		op := ""
+		for _, cand := range []string{">=", "<=", "==", "!=", ">", "<", "=", "~", "^"} {
+			if strings.HasPrefix(part, cand) {
+				op = cand
+				break
+			}
+		}
+		verStr := strings.TrimSpace(strings.TrimPrefix(part, op))
+		if op == "" || op == "==" {
+			op = "="
+		}
+		v, err := parseSemver(verStr)
+		if err != nil {
+			return VersionConstraint{}, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		c.terms = append(c.terms, versionTerm{op: op, v: v})
+	}
+	return c, nil
+}
+
+func (c VersionConstraint) String() string { return c.raw }
+
+// Allows reports whether version satisfies every term of the constraint.
+func (c VersionConstraint) Allows(version string) (bool, error) {
+	v, err := parseSemver(version)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range c.terms {
+		cmp := compareSemver(v, t.v)
+		ok := false
+		switch t.op {
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case "~":
+			ok = cmp >= 0 && v.major == t.v.major && v.minor == t.v.minor
+		case "^":
+			if t.v.major == 0 {
+				ok = cmp >= 0 && v.major == 0 && v.minor == t.v.minor
+			} else {
+				ok = cmp >= 0 && v.major == t.v.major
+			}
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// parseSemver accepts "1", "1.2", "1.2.3", optional leading "v", and an optional
+// "-prerelease" suffix. Build metadata ("+...") is ignored.
+func parseSemver(s string) (semver, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	var v semver
+	if i := strings.Index(s, "-"); i >= 0 {
+		v.pre = s[i+1:]
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if s == "" || len(parts) > 3 {
+		return semver{}, fmt.Errorf("invalid version %q", s)
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return semver{}, fmt.Errorf("invalid version %q", s)
+		}
+		nums[i] = n
+	}
+	v.major, v.minor, v.patch = nums[0], nums[1], nums[2]
+	return v, nil
+}
+
+func compareSemver(a, b semver) int {
+	for _, d := range [][2]int{{a.major, b.major}, {a.minor, b.minor}, {a.patch, b.patch}} {
+		if d[0] != d[1] {
+			if d[0] < d[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case a.pre == b.pre:
+		return 0
+	case a.pre == "":
+		return 1
+	case b.pre == "":
+		return -1
+	case a.pre < b.pre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// IsReleaseVersion reports whether v looks like a tagged release (not "dev" or empty).
+func IsReleaseVersion(v string) bool {
+	_, err := parseSemver(v)
+	return err == nil
+}
+
+// RigVersionMismatchError is returned when the running rig does not satisfy the
+// project's rig version requirement.
+type RigVersionMismatchError struct {
+	ConfigPath string
+	Required   string
+	Current    string
+}
+
+// ErrorCode reports CodeRigVersion for `rig explain`.
+func (e *RigVersionMismatchError) ErrorCode() ErrorCode { return CodeRigVersion }
+
+func (e *RigVersionMismatchError) Error() string {
+	return fmt.Sprintf("%s requires rig %q but this is rig %s; run 'rig upgrade' or install a matching version", e.ConfigPath, e.Required, e.Current)
+}
+
+// CheckRigVersionRequirement validates the running rig version against
+// [project].rig-version (or tools.rig). Development builds are not checked.
+func CheckRigVersionRequirement(conf *cfg.Config, configPath string, current string) error {
+	req := strings.TrimSpace(conf.Project.RigVersion)
+	if req == "" || !IsReleaseVersion(current) {
+		return nil
+	}
+	c, err := ParseVersionConstraint(req)
+	if err != nil {
+		return fmt.Errorf("%s: rig-version: %w", configPath, err)
+	}
+	ok, err := c.Allows(current)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &RigVersionMismatchError{ConfigPath: configPath, Required: req, Current: strings.TrimSpace(current)}
+	}
+	return nil
+}
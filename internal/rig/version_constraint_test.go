@@ -0,0 +1,136 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVersionConstraintAllows(t *testing.T) {
+	cases := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{">=0.5, <0.6", "0.5.0", true},
+		{">=0.5, <0.6", "v0.5.9", true},
+		{">=0.5, <0.6", "0.6.0", false},
+		{">=0.5, <0.6", "0.4.9", false},
+		{"0.5.1", "0.5.1", true},
+		{"=0.5.1", "0.5.2", false},
+		{"~0.5.1", "0.5.7", true},
+		{"~0.5.1", "0.6.0", false},
+		{"^1.2.0", "1.9.0", true},
+		{"^1.2.0", "2.0.0", false},
+		{"^0.5.0", "0.6.0", false},
+		{"*", "9.9.9", true},
+		{">=0.5.0", "0.5.0-rc1", false},
+		{"!=0.5.3", "0.5.3", false},
+	}
+	for _, c := range cases {
+		vc, err := ParseVersionConstraint(c.constraint)
+		if err != nil {
+			t.Fatalf("ParseVersionConstraint(%q): %v", c.constraint, err)
+		}
+		got, err := vc.Allows(c.version)
+		if err != nil {
+			t.Fatalf("Allows(%q): %v", c.version, err)
+		}
+		if got != c.want {
+			t.Fatalf("%q allows %q = %t, want %t", c.constraint, c.version, got, c.want)
+		}
+	}
+	if _, err := ParseVersionConstraint(">=zero"); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestRigVersionRequirementFromTools(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), "[tools]\nrig = \">=0.5, <0.6\"\nmockery = \"v2.46.0\"\n", 0o644)
+
+	conf, confPath, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if _, ok := conf.Tools["rig"]; ok {
+		t.Fatalf("tools.rig must not be treated as a managed tool")
+	}
+	if conf.Project.RigVersion != ">=0.5, <0.6" {
+		t.Fatalf("rig-version=%q", conf.Project.RigVersion)
+	}
+
+	if err := CheckRigVersionRequirement(conf, confPath, "0.5.2"); err != nil {
+		t.Fatalf("expected 0.5.2 to satisfy: %v", err)
+	}
+	if err := CheckRigVersionRequirement(conf, confPath, "dev"); err != nil {
+		t.Fatalf("dev builds must not be checked: %v", err)
+	}
+	err = CheckRigVersionRequirement(conf, confPath, "v0.4.0")
+	var mm *RigVersionMismatchError
+	if !errors.As(err, &mm) {
+		t.Fatalf("expected RigVersionMismatchError, got %v", err)
+	}
+}
+
+func TestEnsureRigVersionDownloadsAndCaches(t *testing.T) {
+	assetName := "rig_linux_amd64.tar.gz"
+	asset := makeTarGzWithSingle("rig", []byte("rig-0.5.2"))
+	sum := checksumLine(assetName, asset)
+
+	hits := 0
+	var baseURL string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		switch r.URL.Path {
+		case "/releases":
+			_, _ = w.Write([]byte(`[
+				{"tag_name":"v0.6.0","assets":[]},
+				{"tag_name":"v0.5.3-rc1","assets":[]},
+				{"tag_name":"v0.5.2","assets":[{"name":"` + assetName + `","browser_download_url":"` + baseURL + `/asset"},{"name":"` + assetName + `.sha256","browser_download_url":"` + baseURL + `/sum"}]},
+				{"tag_name":"v0.5.1","assets":[]}
+			]`))
+		case "/asset":
+			_, _ = w.Write(asset)
+		case "/sum":
+			_, _ = w.Write([]byte(sum))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	baseURL = ts.URL
+	defer ts.Close()
+
+	cache := t.TempDir()
+	opts := LaunchOptions{Constraint: ">=0.5, <0.6", CacheDir: cache, GOOS: "linux", GOARCH: "amd64", ReleasesURL: ts.URL + "/releases"}
+	target, err := EnsureRigVersion(opts)
+	if err != nil {
+		t.Fatalf("EnsureRigVersion: %v", err)
+	}
+	if target.Version != "v0.5.2" || !target.Downloaded {
+		t.Fatalf("unexpected target: %+v", target)
+	}
+	b, err := os.ReadFile(filepath.Join(cache, "v0.5.2", "rig"))
+	if err != nil || string(b) != "rig-0.5.2" {
+		t.Fatalf("cached binary=%q err=%v", b, err)
+	}
+
+	// An exact pin already in the cache must not touch the network.
+	hits = 0
+	opts.Constraint = "0.5.2"
+	target, err = EnsureRigVersion(opts)
+	if err != nil {
+		t.Fatalf("EnsureRigVersion (cached): %v", err)
+	}
+	if target.Downloaded || hits != 0 {
+		t.Fatalf("expected cache hit, got %+v with %d requests", target, hits)
+	}
+
+	opts.Constraint = ">=1.0"
+	if _, err := EnsureRigVersion(opts); err == nil {
+		t.Fatalf("expected no matching release error")
+	}
+}
@@ -0,0 +1,420 @@
+package rig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	cfg "github.com/divijg19/rig/internal/config"
+)
+
+// GoWork is the subset of a go.work file that rig understands.
+type GoWork struct {
+	Go        string
+	Toolchain string
+	Use       []string
+	// Other holds every other directive (replace, godebug, ...) verbatim,
+	// one entry per directive or block, so a rewrite keeps them.
+	Other []string
+}
+
+// WorkspaceStatus reports drift between [workspace].members and go.work.
+//
+// Members is the effective member list: [workspace].members when declared,
+// otherwise the use directives found in go.work.
+type WorkspaceStatus struct {
+	GoWorkPath string   `json:"goWorkPath"`
+	HasGoWork  bool     `json:"hasGoWork"`
+	Declared   bool     `json:"declared"`
+	Members    []string `json:"members"`
+	Missing    []string `json:"missing,omitempty"`
+	Extra      []string `json:"extra,omitempty"`
+	InSync     bool     `json:"inSync"`
+}
+
+// WorkspaceSyncResult describes a `rig workspace sync` run.
+type WorkspaceSyncResult struct {
+	GoWorkPath string
+	Members    []string
+	Changed    bool
+	Content    string
+}
+
+func goWorkPathForConfig(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "go.work")
+}
+
+// ParseGoWork reads the go, toolchain and use directives from a go.work file.
+// Both single-line and block forms of use are supported; comments are ignored.
+// Other directives are kept verbatim in Other.
+func ParseGoWork(b []byte) (GoWork, error) {
+	var w GoWork
+	inUse := false
+	var other []string // the unrecognized block being read, if any
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		raw := strings.TrimRight(sc.Text(), " \t\r")
+		line := raw
+		if other != nil {
+			other = append(other, raw)
+			if i := strings.Index(line, "//"); i >= 0 {
+				line = line[:i]
+			}
+			if strings.TrimSpace(line) == ")" {
+				w.Other = append(w.Other, strings.Join(other, "\n"))
+				other = nil
+			}
+			continue
+		}
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if inUse {
+			if line == ")" {
+				inUse = false
+				continue
+			}
+			w.Use = append(w.Use, unquoteGoWork(line))
+			continue
+		}
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "go":
+			if len(fields) != 2 {
+				return GoWork{}, fmt.Errorf("go.work:%d: malformed go directive", lineNo)
+			}
+			w.Go = fields[1]
+		case "toolchain":
+			if len(fields) != 2 {
+				return GoWork{}, fmt.Errorf("go.work:%d: malformed toolchain directive", lineNo)
+			}
+			w.Toolchain = fields[1]
+		case "use":
+			rest := strings.TrimSpace(strings.TrimPrefix(line, "use"))
+			if rest == "(" {
+				inUse = true
+				continue
+			}
+			if rest == "" {
+				return GoWork{}, fmt.Errorf("go.work:%d: malformed use directive", lineNo)
+			}
+			w.Use = append(w.Use, unquoteGoWork(rest))
+		default:
+			// replace, godebug, and future directives: rig does not interpret
+			// them, but RenderGoWork writes them back.
+			if strings.HasSuffix(line, "(") {
+				other = []string{raw}
+				continue
+			}
+			w.Other = append(w.Other, raw)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return GoWork{}, err
+	}
+	if inUse {
+		return GoWork{}, fmt.Errorf("go.work: unterminated use block")
+	}
+	if other != nil {
+		return GoWork{}, fmt.Errorf("go.work: unterminated %s block", strings.Fields(other[0])[0])
+	}
+	return w, nil
+}
+
+func unquoteGoWork(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '`') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// ReadGoWorkForConfig reads go.work next to rig.toml. ok=false when it does not exist.
+func ReadGoWorkForConfig(configPath string) (w GoWork, ok bool, err error) {
+	b, err := os.ReadFile(goWorkPathForConfig(configPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return GoWork{}, false, nil
+		}
+		return GoWork{}, false, err
+	}
+	w, err = ParseGoWork(b)
+	if err != nil {
+		return GoWork{}, true, err
+	}
+	return w, true, nil
+}
+
+// normalizeWorkspaceMember renders a member path the way `go work use` writes it:
+// slash-separated and explicitly relative ("./svc/api").
+func normalizeWorkspaceMember(m string) string {
+	m = strings.TrimSpace(filepath.ToSlash(m))
+	if m == "" {
+		return ""
+	}
+	if path.IsAbs(m) || filepath.IsAbs(m) {
+		return path.Clean(m)
+	}
+	m = path.Clean(m)
+	if m == "." || strings.HasPrefix(m, "../") {
+		return m
+	}
+	return "./" + m
+}
+
+func normalizeWorkspaceMembers(in []string) []string {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(in))
+	for _, m := range in {
+		n := normalizeWorkspaceMember(m)
+		if n == "" {
+			continue
+		}
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// WorkspaceMembers returns the effective workspace members for a project:
+// [workspace].members when declared, otherwise the use directives of go.work.
+func WorkspaceMembers(conf *cfg.Config, configPath string) ([]string, error) {
+	if len(conf.Workspace.Members) > 0 {
+		return normalizeWorkspaceMembers(conf.Workspace.Members), nil
+	}
+	w, ok, err := ReadGoWorkForConfig(configPath)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return normalizeWorkspaceMembers(w.Use), nil
+}
+
+// CheckWorkspace compares [workspace].members with go.work. It returns nil when the
+// project declares no workspace and has no go.work.
+func CheckWorkspace(conf *cfg.Config, configPath string) (*WorkspaceStatus, error) {
+	w, hasWork, err := ReadGoWorkForConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	declared := len(conf.Workspace.Members) > 0
+	if !declared && !hasWork {
+		return nil, nil
+	}
+	st := &WorkspaceStatus{GoWorkPath: goWorkPathForConfig(configPath), HasGoWork: hasWork, Declared: declared}
+	used := normalizeWorkspaceMembers(w.Use)
+	if !declared {
+		st.Members = used
+		st.InSync = true
+		return st, nil
+	}
+	st.Members = normalizeWorkspaceMembers(conf.Workspace.Members)
+	st.Missing, st.Extra = diffSorted(st.Members, used)
+	st.InSync = hasWork && len(st.Missing) == 0 && len(st.Extra) == 0
+	return st, nil
+}
+
+// diffSorted returns entries of want absent from have, and entries of have absent from want.
+func diffSorted(want, have []string) (missing, extra []string) {
+	h := make(map[string]struct{}, len(have))
+	for _, v := range have {
+		h[v] = struct{}{}
+	}
+	w := make(map[string]struct{}, len(want))
+	for _, v := range want {
+		w[v] = struct{}{}
+		if _, ok := h[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	for _, v := range have {
+		if _, ok := w[v]; !ok {
+			extra = append(extra, v)
+		}
+	}
+	return missing, extra
+}
+
+// RenderGoWork renders a deterministic go.work file.
+func RenderGoWork(w GoWork) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "go %s\n", w.Go)
+	if strings.TrimSpace(w.Toolchain) != "" {
+		fmt.Fprintf(&b, "\ntoolchain %s\n", w.Toolchain)
+	}
+	if len(w.Use) > 0 {
+		b.WriteString("\nuse (\n")
+		for _, u := range w.Use {
+			fmt.Fprintf(&b, "\t%s\n", u)
+		}
+		b.WriteString(")\n")
+	}
+	for _, d := range w.Other {
+		fmt.Fprintf(&b, "\n%s\n", d)
+	}
+	return b.String()
+}
+
+// WorkspaceSync regenerates go.work from [workspace].members.
+//
+// The go directive is preserved from an existing go.work; otherwise it is taken
+// from tools.go, falling back to the detected toolchain. With dryRun the file is
+// not written; Changed reports whether a write would occur.
+func WorkspaceSync(startDir string, dryRun bool) (WorkspaceSyncResult, error) {
+	conf, confPath, err := LoadConfig(startDir)
+	if err != nil {
+		return WorkspaceSyncResult{}, err
+	}
+	if len(conf.Workspace.Members) == 0 {
+		return WorkspaceSyncResult{}, fmt.Errorf("no [workspace].members declared in %s", confPath)
+	}
+	members := normalizeWorkspaceMembers(conf.Workspace.Members)
+	root := filepath.Dir(confPath)
+	for _, m := range members {
+		dir := m
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, filepath.FromSlash(m))
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+			return WorkspaceSyncResult{}, fmt.Errorf("workspace member %q has no go.mod", m)
+		}
+	}
+
+	existing, hasWork, err := ReadGoWorkForConfig(confPath)
+	if err != nil {
+		return WorkspaceSyncResult{}, err
+	}
+	next := GoWork{Go: existing.Go, Toolchain: existing.Toolchain, Use: members, Other: existing.Other}
+	if next.Go == "" {
+		if req := strings.TrimSpace(conf.Tools["go"]); req != "" && req != "latest" {
+			if v, nerr := NormalizeGoToolchainRequested(req); nerr == nil {
+				next.Go = v
+			}
+		}
+	}
+	if next.Go == "" {
+		v, derr := DetectGoToolchainVersion(root, nil)
+		if derr != nil {
+			return WorkspaceSyncResult{}, derr
+		}
+		next.Go = v
+	}
+
+	goWorkPath := goWorkPathForConfig(confPath)
+	content := RenderGoWork(next)
+	res := WorkspaceSyncResult{GoWorkPath: goWorkPath, Members: members, Content: content}
+	if hasWork {
+		cur, err := os.ReadFile(goWorkPath)
+		if err != nil {
+			return WorkspaceSyncResult{}, err
+		}
+		res.Changed = string(cur) != content
+	} else {
+		res.Changed = true
+	}
+	if dryRun || !res.Changed {
+		return res, nil
+	}
+	if err := os.WriteFile(goWorkPath, []byte(content), 0o644); err != nil {
+		return WorkspaceSyncResult{}, fmt.Errorf("write %s: %w", goWorkPath, err)
+	}
+	return res, nil
+}
+
+// DevMember is a workspace member with its own rig.toml, run by
+// `rig dev --members`.
+type DevMember struct {
+	// Name labels the member's output: its last path element ("api" for
+	// ./svc/api), or the whole path when two members share one.
+	Name string
+	Dir  string
+}
+
+// ResolveDevMembers maps names (member paths such as ./svc/api, their last
+// element, or "all") to workspace members. Every named member must have a
+// rig.toml of its own; "all" picks the members that do.
+func ResolveDevMembers(conf *cfg.Config, configPath string, names []string) ([]DevMember, error) {
+	members, err := WorkspaceMembers(conf, configPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no workspace members: declare [workspace].members or add a go.work")
+	}
+	root := filepath.Dir(configPath)
+	bases := map[string]int{}
+	for _, m := range members {
+		bases[path.Base(m)]++
+	}
+	label := func(m string) string {
+		if bases[path.Base(m)] > 1 {
+			return strings.TrimPrefix(m, "./")
+		}
+		return path.Base(m)
+	}
+	hasConfig := func(m string) bool {
+		info, err := os.Stat(filepath.Join(root, filepath.FromSlash(m), "rig.toml"))
+		return err == nil && !info.IsDir()
+	}
+
+	var picked []string
+	if len(names) == 1 && names[0] == "all" {
+		for _, m := range members {
+			if hasConfig(m) {
+				picked = append(picked, m)
+			}
+		}
+		if len(picked) == 0 {
+			return nil, fmt.Errorf("no workspace member has its own rig.toml with [tasks.dev]")
+		}
+	} else {
+		labels := make([]string, len(members))
+		for i, m := range members {
+			labels[i] = label(m)
+		}
+		for _, name := range names {
+			want := normalizeWorkspaceMember(name)
+			var match []string
+			for _, m := range members {
+				if m == want || label(m) == name || path.Base(m) == name {
+					match = append(match, m)
+				}
+			}
+			switch {
+			case len(match) == 0:
+				return nil, fmt.Errorf("unknown workspace member %q%s", name, DidYouMean(name, labels))
+			case len(match) > 1:
+				return nil, fmt.Errorf("workspace member %q is ambiguous (%s); use its path", name, strings.Join(match, ", "))
+			case !hasConfig(match[0]):
+				return nil, fmt.Errorf("workspace member %s has no rig.toml; add one with [tasks.dev]", match[0])
+			}
+			if !slices.Contains(picked, match[0]) {
+				picked = append(picked, match[0])
+			}
+		}
+	}
+	out := make([]DevMember, 0, len(picked))
+	for _, m := range picked {
+		dir, err := filepath.Abs(filepath.Join(root, filepath.FromSlash(m)))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, DevMember{Name: label(m), Dir: dir})
+	}
+	return out, nil
+}
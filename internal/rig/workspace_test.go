@@ -0,0 +1,168 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoWork(t *testing.T) {
+	in := `go 1.22.3
+
+toolchain go1.22.5
+
+// shared modules
+use ./tools
+use (
+	./svc/api // api
+	"./svc/worker"
+)
+
+replace example.com/x => ./x
+`
+	w, err := ParseGoWork([]byte(in))
+	if err != nil {
+		t.Fatalf("ParseGoWork: %v", err)
+	}
+	if w.Go != "1.22.3" || w.Toolchain != "go1.22.5" {
+		t.Fatalf("go=%q toolchain=%q", w.Go, w.Toolchain)
+	}
+	want := []string{"./tools", "./svc/api", "./svc/worker"}
+	if !reflect.DeepEqual(w.Use, want) {
+		t.Fatalf("use=%v, want %v", w.Use, want)
+	}
+
+	if _, err := ParseGoWork([]byte("go 1.22\nuse (\n\t./a\n")); err == nil {
+		t.Fatalf("expected unterminated use block error")
+	}
+}
+
+func TestWorkspaceSyncAndCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), `
+[tools]
+go = "1.22.3"
+
+[workspace]
+members = ["svc/worker", "./svc/api"]
+`, 0o644)
+	writeTestFile(t, filepath.Join(dir, "svc", "api", "go.mod"), "module example.com/api\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "svc", "worker", "go.mod"), "module example.com/worker\n", 0o644)
+
+	conf, confPath, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	st, err := CheckWorkspace(conf, confPath)
+	if err != nil {
+		t.Fatalf("CheckWorkspace: %v", err)
+	}
+	if st == nil || st.InSync || st.HasGoWork {
+		t.Fatalf("expected drift without go.work, got %+v", st)
+	}
+
+	res, err := WorkspaceSync(dir, false)
+	if err != nil {
+		t.Fatalf("WorkspaceSync: %v", err)
+	}
+	if !res.Changed {
+		t.Fatalf("expected go.work to be written")
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "go.work"))
+	if err != nil {
+		t.Fatalf("read go.work: %v", err)
+	}
+	want := "go 1.22.3\n\nuse (\n\t./svc/api\n\t./svc/worker\n)\n"
+	if string(b) != want {
+		t.Fatalf("go.work=\n%s\nwant:\n%s", b, want)
+	}
+
+	st, err = CheckWorkspace(conf, confPath)
+	if err != nil {
+		t.Fatalf("CheckWorkspace: %v", err)
+	}
+	if !st.InSync {
+		t.Fatalf("expected in sync after workspace sync, got %+v", st)
+	}
+
+	res, err = WorkspaceSync(dir, true)
+	if err != nil {
+		t.Fatalf("WorkspaceSync dry-run: %v", err)
+	}
+	if res.Changed {
+		t.Fatalf("expected no change on second sync")
+	}
+}
+
+func TestWorkspaceSyncRejectsMemberWithoutGoMod(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), "[workspace]\nmembers = [\"./missing\"]\n", 0o644)
+	if _, err := WorkspaceSync(dir, true); err == nil {
+		t.Fatalf("expected error for member without go.mod")
+	}
+}
+
+func TestGoWorkRoundTripKeepsOtherDirectives(t *testing.T) {
+	in := `go 1.22.3
+
+use ./a
+
+godebug default=go1.21
+
+replace (
+	// local fork
+	example.com/x => ./x
+	example.com/y v1.0.0 => example.com/y v1.0.1
+) // end
+
+replace example.com/z => ../z
+`
+	w, err := ParseGoWork([]byte(in))
+	if err != nil {
+		t.Fatalf("ParseGoWork: %v", err)
+	}
+	if len(w.Other) != 3 {
+		t.Fatalf("other=%q, want 3 directives", w.Other)
+	}
+	w.Use = append(w.Use, "./b")
+	out := RenderGoWork(w)
+	for _, want := range []string{
+		"godebug default=go1.21\n",
+		"replace (\n\t// local fork\n\texample.com/x => ./x\n\texample.com/y v1.0.0 => example.com/y v1.0.1\n) // end\n",
+		"replace example.com/z => ../z\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("rendered go.work lost %q:\n%s", want, out)
+		}
+	}
+	again, err := ParseGoWork([]byte(out))
+	if err != nil {
+		t.Fatalf("ParseGoWork(rendered): %v", err)
+	}
+	if !reflect.DeepEqual(again, w) {
+		t.Fatalf("round trip = %+v, want %+v", again, w)
+	}
+
+	if _, err := ParseGoWork([]byte("go 1.22\nreplace (\n\ta => ./a\n")); err == nil {
+		t.Fatalf("expected unterminated replace block error")
+	}
+}
+
+func TestWorkspaceSyncKeepsReplace(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rig.toml"), "[workspace]\nmembers = [\"./a\"]\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "a", "go.mod"), "module example.com/a\n\ngo 1.22\n", 0o644)
+	writeTestFile(t, filepath.Join(dir, "go.work"), "go 1.22\n\nreplace example.com/x => ./x\n", 0o644)
+	if _, err := WorkspaceSync(dir, false); err != nil {
+		t.Fatalf("WorkspaceSync: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "go.work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "replace example.com/x => ./x\n") || !strings.Contains(string(b), "./a") {
+		t.Fatalf("go.work after sync:\n%s", b)
+	}
+}